@@ -1,11 +1,13 @@
 package app
 
 import (
+	"claude-squad/audit"
 	"claude-squad/config"
 	"claude-squad/inspect"
 	"claude-squad/keys"
 	"claude-squad/log"
 	"claude-squad/session"
+	"claude-squad/session/git"
 	"claude-squad/session/zellij"
 	"claude-squad/ui"
 	"claude-squad/ui/layout"
@@ -16,18 +18,17 @@ import (
 	"os/exec"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-const GlobalInstanceLimit = 50
-
 // Run is the main entrypoint into the application.
-func Run(ctx context.Context, program string, autoYes bool) error {
+func Run(ctx context.Context, program string, autoYes bool, fastStart bool) error {
 	p := tea.NewProgram(
-		newHome(ctx, program, autoYes),
+		newHome(ctx, program, autoYes, fastStart),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(), // Mouse scroll
 	)
@@ -55,6 +56,45 @@ const (
 	stateRename
 	// stateModeSelect is the state when the user is selecting a session mode.
 	stateModeSelect
+	// stateRelayTarget is the state when the user is choosing a session to relay output to.
+	stateRelayTarget
+	// stateRelayPrompt is the state when the user is editing the relayed output before sending it.
+	stateRelayPrompt
+	// stateNotes is the state when the user is editing the tags and notes of an instance.
+	stateNotes
+	// stateSnippets is the state when the user is browsing the snippets of an instance.
+	stateSnippets
+	// stateRebase is the state when the user is planning an interactive rebase of a branch.
+	stateRebase
+	// stateBoard is the state when the board view of the squad is shown.
+	stateBoard
+	// stateAudit is the state when the audit log is shown.
+	stateAudit
+	// statePicker is the state when the user is finding an instance to go to.
+	statePicker
+	// stateStartup is the state when the progress of restoring the saved sessions is shown.
+	stateStartup
+	// stateRecovery is the state when the saved sessions could not be loaded.
+	stateRecovery
+	// stateDiffTarget is the state when the user is choosing what to diff against.
+	stateDiffTarget
+	// stateDiffRef is the state when the user is entering a ref to diff against.
+	stateDiffRef
+	// stateBulk is the state when the progress of pausing or resuming all instances is shown.
+	stateBulk
+	// stateInlineAttach is the state when keys are forwarded to the selected instance
+	// while its preview is shown.
+	stateInlineAttach
+	// stateReplyPrompt is the state when the user is typing a reply to the prompt an agent waits on.
+	stateReplyPrompt
+	// stateLoadPrompt is the state when the user is picking a file to load into the prompt.
+	stateLoadPrompt
+	// stateIssueSelect is the state when the user is choosing the GitHub issue a new instance works on.
+	stateIssueSelect
+	// stateAuxCommand is the state when the user is editing the auxiliary command of an instance.
+	stateAuxCommand
+	// stateKeyHelp is the state when the key reference is shown.
+	stateKeyHelp
 )
 
 type home struct {
@@ -86,6 +126,13 @@ type home struct {
 	// pendingSave indicates that a save is queued (for debouncing)
 	pendingSave bool
 
+	// pendingUndo is the last kill/archive, deferred until its undo window passes
+	pendingUndo *pendingUndo
+	// undoSeq identifies deferred actions so stale undo timers are ignored
+	undoSeq int
+	// confirmResult holds the message returned by a confirmed action until the overlay closes
+	confirmResult tea.Msg
+
 	// -- UI Components --
 
 	// list displays the list of instances
@@ -110,20 +157,102 @@ type home struct {
 	fileBrowserOverlay *overlay.FileBrowserOverlay
 	// modeSelectorOverlay displays the mode selector for choosing session type
 	modeSelectorOverlay *overlay.ModeSelectorOverlay
+	// selectionOverlay displays a list of choices, e.g. the relay target
+	selectionOverlay *overlay.SelectionOverlay
+	// notesOverlay edits the tags and notes of an instance
+	notesOverlay *overlay.NotesOverlay
+	// snippetsOverlay browses the output snippets captured from an instance
+	snippetsOverlay *overlay.SnippetsOverlay
+	// rebaseOverlay plans an interactive rebase of an instance's branch
+	rebaseOverlay *overlay.RebaseOverlay
+	// boardOverlay shows the squad as a board
+	boardOverlay *overlay.BoardOverlay
+	// auditOverlay shows the audit log of all instances
+	auditOverlay *overlay.AuditOverlay
+	// pickerOverlay finds an instance to go to
+	pickerOverlay *overlay.PickerOverlay
+	// helpOverlay is the key reference
+	helpOverlay *overlay.HelpOverlay
+	// filePickerOverlay picks a file to load into the prompt
+	filePickerOverlay *overlay.FilePickerOverlay
+	// startupOverlay tracks restoring the saved sessions, and is shown while
+	// m.state is stateStartup
+	startupOverlay *overlay.StartupOverlay
+	// inlineKeys receives the keys forwarded to the selected instance while
+	// m.state is stateInlineAttach
+	inlineKeys chan string
+	// lastMetadataTick is the wall clock time of the last metadata tick, to
+	// detect that the machine was asleep
+	lastMetadataTick time.Time
+	// restartStopped is set to restart the stopped programs of all instances on
+	// the next metadata tick, after waking up or restoring the sessions
+	restartStopped bool
+	// bulkOverlay tracks pausing or resuming all instances, and is shown while
+	// m.state is stateBulk
+	bulkOverlay *overlay.BulkOverlay
+	// recoveryOverlay offers ways to recover when the saved sessions could not be loaded
+	recoveryOverlay *overlay.RecoveryOverlay
 
 	// pendingInstancePath stores the selected path from the file browser
 	pendingInstancePath string
 	// pendingSessionType stores the selected session type from mode selector
 	pendingSessionType string
+	// modeSwitchInstance is the paused instance whose session type the mode
+	// selector switches, nil when it picks the type of a new instance
+	modeSwitchInstance *session.Instance
+	// pendingScratch is true when the file browser selection is for a scratch session
+	pendingScratch bool
+	// pendingRepoConfig holds the defaults of the repository the new instance is created in
+	pendingRepoConfig *config.RepoConfig
+	// pendingIssue is the GitHub issue the new instance is created for, if any
+	pendingIssue *git.Issue
+	// issues and issueRepoDir are the open issues offered for a new instance,
+	// and the repository they were listed in
+	issues       []git.Issue
+	issueRepoDir string
+
+	// jumpPending is true after the jump key was pressed, while digits are collected
+	jumpPending bool
+	// jumpDigits holds the digits typed after the jump key
+	jumpDigits string
+
+	// relayOutput is the prompt built from the relay source's output
+	relayOutput string
+	// relayTargets are the sessions offered in the relay target selection
+	relayTargets []*session.Instance
+	// relayTarget is the session the relayed output will be sent to
+	relayTarget *session.Instance
 
 	// -- Background Services --
 
 	// summarizer handles generating AI summaries for instances
-	summarizer *session.Summarizer
+	summarizer *session.SummaryUpdater
 
 	// metadataUpdateInProgress prevents overlapping async metadata updates
 	metadataUpdateInProgress bool
 
+	// -- Startup --
+
+	// startedAt is when startup began, to measure the time until first paint
+	startedAt time.Time
+	// fastStart defers restoring sessions until their instance is selected
+	fastStart bool
+	// pendingCleanup are instances killed during a previous run, cleaned up after first paint
+	pendingCleanup []*session.Instance
+	// restoring is the instance whose deferred session restore is in progress
+	restoring *session.Instance
+
+	// landing is true while the merge queue lands an instance
+	landing bool
+	// startingPending is true while the scheduler starts a pending instance
+	startingPending bool
+	// terminalTitle is the title last set on the terminal window
+	terminalTitle string
+	// pendingFinalizers register the repos of pending instances once started
+	pendingFinalizers map[*session.Instance]func()
+	// artifactUploads track the artifact uploads of the instances
+	artifactUploads map[*session.Instance]*artifactUpload
+
 	// -- Layout State --
 
 	// layoutConstraints holds the current computed layout constraints
@@ -135,7 +264,9 @@ type home struct {
 	termHeight int
 }
 
-func newHome(ctx context.Context, program string, autoYes bool) *home {
+func newHome(ctx context.Context, program string, autoYes bool, fastStart bool) *home {
+	startedAt := time.Now()
+
 	// Load application config
 	appConfig := config.LoadConfig()
 
@@ -148,12 +279,23 @@ func newHome(ctx context.Context, program string, autoYes bool) *home {
 		fmt.Printf("Failed to initialize storage: %v\n", err)
 		os.Exit(1)
 	}
+	// Sessions are restored after the first paint, see restoreAll
+	storage.SetDeferRestore(true)
+	session.SetPromptKeys(appConfig.PromptKeys)
+
+	diffPane := ui.NewDiffPane()
+	diffPane.SetRenderer(appConfig.DiffRenderer)
+	previewPane := ui.NewPreviewPane()
+	previewPane.SetHistoryLimits(appConfig.HistoryMaxLines, appConfig.HistoryMaxBytes)
+	previewPane.SetStaleBaseCommits(staleBaseCommits(appConfig))
+	checksPane := ui.NewChecksPane()
+	checksPane.SetCommand(appConfig.CheckCommand)
 
 	h := &home{
 		ctx:          ctx,
 		spinner:      spinner.New(spinner.WithSpinner(spinner.MiniDot)),
 		menu:         ui.NewMenu(),
-		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane()),
+		tabbedWindow: ui.NewTabbedWindow(previewPane, diffPane, ui.NewFilesPane(), checksPane),
 		errBox:       ui.NewErrBox(),
 		storage:      storage,
 		appConfig:    appConfig,
@@ -161,27 +303,58 @@ func newHome(ctx context.Context, program string, autoYes bool) *home {
 		autoYes:      autoYes,
 		state:        stateDefault,
 		appState:     appState,
-		summarizer:   session.NewSummarizer(),
+		summarizer:   session.NewSummaryUpdater(session.NewSummarizer(appConfig)),
+		startedAt:    startedAt,
+		fastStart:    fastStart,
+
+		pendingFinalizers: make(map[*session.Instance]func()),
 	}
 	h.list = ui.NewList(&h.spinner, autoYes)
+	h.list.SetIdentity(appConfig.CurrentIdentity())
+	h.list.SetTrashRetentionDays(appConfig.TrashRetentionDays)
 
 	// Load saved instances
+	loadStart := time.Now()
 	instances, err := storage.LoadInstances()
 	if err != nil {
-		fmt.Printf("Failed to load instances: %v\n", err)
-		os.Exit(1)
+		// Offer ways to recover instead of losing the TUI over one corrupt entry
+		log.ErrorLog.Printf("failed to load instances: %v", err)
+		h.recoveryOverlay = overlay.NewRecoveryOverlay(err, config.HasStateBackup())
+		h.state = stateRecovery
+		return h
+	}
+	log.InfoLog.Printf("loaded %d instance(s) in %s", len(instances), time.Since(loadStart).Round(time.Millisecond))
+	h.addLoadedInstances(instances)
+	// Only a state whose instances loaded is worth restoring later
+	if err := config.BackupState(); err != nil {
+		log.WarningLog.Printf("%v", err)
 	}
 
-	// Add loaded instances to the list
+	return h
+}
+
+// addLoadedInstances adds the instances loaded from storage to the list.
+func (m *home) addLoadedInstances(instances []*session.Instance) {
 	for _, instance := range instances {
-		// Call the finalizer immediately.
-		h.list.AddInstance(instance)()
-		if autoYes {
+		// Instances killed during a previous run still need their deferred
+		// cleanup. Until it's done they stay in the list, hidden, and stored.
+		if instance.Tombstoned() {
+			m.list.AddInstance(instance)()
+			m.pendingCleanup = append(m.pendingCleanup, instance)
+			continue
+		}
+		// Call the finalizer immediately, or once a pending instance is started
+		// or the session of an instance is restored.
+		finalize := m.list.AddInstance(instance)
+		if instance.Status == session.Pending || instance.RestorePending() {
+			m.pendingFinalizers[instance] = finalize
+		} else {
+			finalize()
+		}
+		if m.autoYes {
 			instance.AutoYes = true
 		}
 	}
-
-	return h
 }
 
 // updateHandleWindowSizeEvent sets the sizes of the components using the layout constraint system.
@@ -215,6 +388,21 @@ func (m *home) updateHandleWindowSizeEvent(msg tea.WindowSizeMsg) {
 	if m.textOverlay != nil {
 		m.textOverlay.SetWidth(overlayWidth)
 	}
+	if m.notesOverlay != nil {
+		m.notesOverlay.SetSize(overlayWidth, overlayHeight)
+	}
+	if m.helpOverlay != nil {
+		m.helpOverlay.SetSize(m.keyHelpSize())
+	}
+	if m.startupOverlay != nil {
+		m.startupOverlay.SetWidth(max(msg.Width*6/10, 60))
+	}
+	if m.bulkOverlay != nil {
+		m.bulkOverlay.SetWidth(max(msg.Width*6/10, 60))
+	}
+	if m.recoveryOverlay != nil {
+		m.recoveryOverlay.SetWidth(max(msg.Width*6/10, 70))
+	}
 	if m.fileBrowserOverlay != nil {
 		fbWidth, fbHeight := layout.ComputeOverlaySize(msg.Width, msg.Height, 70, 25)
 		m.fileBrowserOverlay.SetSize(fbWidth, fbHeight)
@@ -241,8 +429,9 @@ func (m *home) Init() tea.Cmd {
 			time.Sleep(100 * time.Millisecond)
 			return previewTickMsg{}
 		},
-		tickUpdateMetadataCmd,
-		tickUpdateSummaryCmd,
+		m.tickUpdateMetadataCmd(),
+		// The summarizer and background cleanup start after the first paint
+		startupCompleteCmd,
 	)
 }
 
@@ -250,15 +439,48 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case hideErrMsg:
 		m.errBox.Clear()
+	case hideInfoMsg:
+		// Only clear the notice if it hasn't been replaced since
+		if m.errBox.Info() == msg.info {
+			m.errBox.ClearInfo()
+		}
+	case promptSentMsg:
+		return m, m.handlePromptSent(msg)
+	case issuesLoadedMsg:
+		return m, m.handleIssuesLoaded(msg)
+	case landResultMsg:
+		return m, m.handleLandResult(msg)
+	case checkResultMsg:
+		return m, m.handleCheckResult(msg)
+	case reviewStartedMsg:
+		return m, m.handleReviewStarted(msg)
+	case startupCompleteMsg:
+		return m, m.handleStartupComplete()
+	case instanceRestoredMsg:
+		return m, m.handleInstanceRestored(msg)
+	case pauseAllMsg:
+		return m, m.pauseAll()
+	case bulkFinishedMsg:
+		return m, m.handleBulkFinished(msg)
+	case untrackedListedMsg:
+		return m, m.confirmKill(msg)
+	case killConfirmedMsg:
+		return m, m.killInstance(msg)
+	case relayCapturedMsg:
+		return m.handleRelayCaptured(msg)
+	case snippetsCapturedMsg:
+		return m, m.openSnippets(msg.instance)
+	case rebaseCommitsMsg:
+		return m, m.handleRebaseCommits(msg)
+	case statsUnpushedMsg:
+		return m, m.handleStatsUnpushed(msg)
+	case tombstoneCleanedMsg:
+		return m, m.handleTombstoneCleaned(msg)
 	case previewTickMsg:
 		cmd := m.instanceChanged()
-		return m, tea.Batch(
-			cmd,
-			func() tea.Msg {
-				time.Sleep(250 * time.Millisecond)
-				return previewTickMsg{}
-			},
-		)
+		return m, tea.Batch(cmd, m.previewTickCmd())
+	case profilerTickMsg:
+		return m, m.handleProfilerTick(msg)
 	case keyupMsg:
 		m.menu.ClearKeydown()
 		return m, nil
@@ -272,15 +494,21 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}()
 		return m, nil
 	case tickUpdateMetadataMessage:
-		// Prevent overlapping updates
-		if m.metadataUpdateInProgress {
-			return m, tickUpdateMetadataCmd
+		m.detectWake()
+		// Prevent overlapping updates, and syncing instances from disk before
+		// the saved ones could be loaded
+		if m.metadataUpdateInProgress || m.state == stateRecovery {
+			return m, m.tickUpdateMetadataCmd()
 		}
 		m.metadataUpdateInProgress = true
+		restartStopped := m.restartStopped
+		m.restartStopped = false
 
 		// Capture state for async operation
 		instances := m.list.GetInstances()
+		policy := m.pollPolicy()
 		storage := m.storage
+		forge := m.appConfig.Forge
 
 		// Check disk sync (fast operation, can do synchronously)
 		diskInstances, synced, err := storage.SyncFromDisk()
@@ -291,22 +519,42 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Run expensive operations asynchronously
 		return m, tea.Batch(
 			func() tea.Msg {
-				updateResults := session.ParallelUpdate(instances)
+				// Programs stopped while the machine was asleep or off are
+				// restarted first, so the update sees them running
+				var restarted []session.RestartResult
+				if restartStopped {
+					restarted = session.RestartStoppedPrograms(instances)
+				}
+				// Sessions whose zellij server restarted are attached again
+				session.ReconnectDisconnected(instances, time.Now())
+				updateResults := session.ParallelUpdate(policy.Due(instances, time.Now()))
 				// Background diff stats update - non-blocking, rate-limited
 				// (10s delay after activity, max once per 30s per instance)
 				session.BackgroundUpdateDiffStats(instances)
+				// Compare the branches with the default branch every few minutes
+				session.BackgroundCheckBases(instances, time.Now())
+				// Poll the CI checks of pushed branches until they finish
+				session.BackgroundCheckCI(instances, forge, time.Now())
 				// Background capture of Claude session IDs for instances that don't have one
 				session.BackgroundCaptureClaudeSessionIDs(instances)
+				// Pick up the verdicts of reviewers that finished reviewing
+				reviewed := session.UpdateReviewStatuses(instances)
 				return metadataUpdateResultMsg{
 					updateResults:  updateResults,
 					syncedFromDisk: synced,
 					diskInstances:  diskInstances,
+					reviewed:       reviewed,
+					restarted:      restarted,
 				}
 			},
-			tickUpdateMetadataCmd,
+			m.tickUpdateMetadataCmd(),
 		)
 	case metadataUpdateResultMsg:
 		m.metadataUpdateInProgress = false
+		// Sessions restarted or reconnected in the background lost their size
+		if err := m.list.SizeVisiblePreviews(); err != nil {
+			log.ErrorLog.Print(err)
+		}
 
 		// Handle disk sync results
 		if msg.syncedFromDisk && msg.diskInstances != nil {
@@ -316,23 +564,32 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Apply update results
+		var finished []*session.Instance
 		for _, result := range msg.updateResults {
 			if result.Instance == nil {
 				continue
 			}
+			result.Instance.SetPendingPrompt(result.PromptExcerpt)
 			if result.Updated {
 				result.Instance.SetStatus(session.Running)
 			} else {
 				if result.HasPrompt {
-					result.Instance.TapEnter()
+					// The process that polled a shared result answers it
+					if !result.Shared {
+						result.Instance.TapEnter()
+					}
 				} else {
+					if result.Instance.Status == session.Running {
+						finished = append(finished, result.Instance)
+					}
 					result.Instance.SetStatus(session.Ready)
 				}
 			}
 		}
 
 
-		return m, nil
+		return m, tea.Batch(m.handleReviewsUpdated(msg.reviewed), m.landNext(), m.uploadArtifacts(finished), m.startPending(),
+			m.handleRateLimits(msg.updateResults), m.updateTerminalTitle(), m.handleProgramsRestarted(msg.restarted))
 	case tickUpdateSummaryMessage:
 		// Update the next instance's summary asynchronously
 		instances := m.list.GetInstances()
@@ -356,13 +613,29 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.loadingOverlay.SetStatus(msg.status)
 		}
 		return m, nil
+	case editorRequestMsg:
+		return m, m.editExternally(msg.text, msg.pattern, msg.apply)
+	case editorResultMsg:
+		return m, m.handleEditorResult(msg)
+	case artifactUploadMsg:
+		return m, m.handleArtifactUpload(msg)
+	case startInstanceMsg:
+		return m, m.startNewInstance(msg.instance)
+	case pendingStartedMsg:
+		return m, m.handlePendingStarted(msg)
 	case loadingCompleteMsg:
-		m.loadingOverlay = nil
 		if msg.err != nil {
 			m.list.Kill()
+			// Keep the error in the overlay until it's dismissed, so it isn't missed
+			if m.loadingOverlay != nil {
+				log.ErrorLog.Printf("%v", msg.err)
+				m.loadingOverlay.SetError(msg.err)
+				return m, nil
+			}
 			m.state = stateDefault
 			return m, m.handleError(msg.err)
 		}
+		m.loadingOverlay = nil
 		// Instance started successfully - get last item from full list (not filtered)
 		allInstances := m.list.GetInstances()
 		instance := allInstances[len(allInstances)-1]
@@ -370,6 +643,7 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
 			return m, m.handleError(err)
 		}
+		audit.Record(audit.EventCreated, instance.Title, instance.Branch)
 		// Instance added successfully, call the finalizer.
 		m.newInstanceFinalizer()
 		if m.autoYes {
@@ -382,15 +656,22 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.state = statePrompt
 			m.menu.SetState(ui.StatePrompt)
 			// Initialize the text input overlay
-			m.textInputOverlay = overlay.NewTextInputOverlay("Enter prompt", "")
+			m.textInputOverlay = newInitialPromptOverlay(instance)
 			m.promptAfterName = false
 		} else {
 			m.menu.SetState(ui.StateDefault)
 			m.showHelpScreen(helpStart(instance), nil)
 		}
 
+		if err := instance.InstallError(); err != nil {
+			return m, tea.Batch(tea.WindowSize(), m.instanceChanged(), m.handleError(err))
+		}
 		return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
 	case tea.MouseMsg:
+		// Scrolling would stop the preview from following the inline attached instance
+		if m.state == stateInlineAttach {
+			return m, nil
+		}
 		// Handle mouse wheel events for scrolling the diff/preview pane
 		if msg.Action == tea.MouseActionPress {
 			if msg.Button == tea.MouseButtonWheelDown || msg.Button == tea.MouseButtonWheelUp {
@@ -401,9 +682,9 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				switch msg.Button {
 				case tea.MouseButtonWheelUp:
-					m.tabbedWindow.ScrollUp()
+					return m, m.tabbedWindow.ScrollUp()
 				case tea.MouseButtonWheelDown:
-					m.tabbedWindow.ScrollDown()
+					return m, m.tabbedWindow.ScrollDown()
 				}
 			}
 		}
@@ -419,6 +700,42 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case instanceChangedMsg:
 		// Handle instance changed after confirmation action
 		return m, m.instanceChanged()
+	case quarantineAcknowledgedMsg:
+		return m, tea.Batch(m.requestSave(), m.instanceChanged())
+	case rebaseDoneMsg:
+		return m, m.instanceChanged()
+	case baseMergedMsg:
+		return m, m.handleBaseMerged(msg)
+	case sessionClosedForSwitchMsg:
+		return m, m.handleSessionClosedForSwitch(msg)
+	case fixupDoneMsg:
+		return m, tea.Batch(m.showInfo(msg.String()), m.instanceChanged())
+	case staleInstancesMsg:
+		return m, m.handleStaleInstances(msg)
+	case staleInstancesPausedMsg:
+		return m, m.handleStaleInstancesPaused(msg)
+	case undoScheduledMsg:
+		return m, tea.Batch(m.undoTimer(msg.id), msg.cleanup, m.instanceChanged())
+	case undoExpiredMsg:
+		if m.pendingUndo == nil || m.pendingUndo.id != msg.id {
+			return m, nil
+		}
+		cleanup, err := m.commitPendingUndo()
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		return m, tea.Batch(cleanup, m.instanceChanged())
+	case ui.DiffRenderedMsg:
+		m.tabbedWindow.HandleDiffRendered(msg)
+		return m, nil
+	case ui.HistoryLoadedMsg:
+		m.tabbedWindow.HandleHistoryLoaded(msg)
+		return m, nil
+	case overlay.FileBrowserLoadedMsg:
+		if m.fileBrowserOverlay != nil {
+			m.fileBrowserOverlay.HandleLoaded(msg)
+		}
+		return m, nil
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -428,16 +745,26 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *home) handleQuit() (tea.Model, tea.Cmd) {
+	// Carry out any deferred action now; kills stay tombstoned and are cleaned up on next start
+	if m.pendingUndo != nil && !m.pendingUndo.deferOnQuit {
+		if _, err := m.commitPendingUndo(); err != nil {
+			log.ErrorLog.Printf("failed to commit pending action on quit: %v", err)
+		}
+	}
 	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
 		return m, m.handleError(err)
 	}
+	if m.terminalTitle != "" {
+		// Don't leave a stale summary in the title of the terminal
+		return m, tea.Sequence(tea.SetWindowTitle(""), tea.Quit)
+	}
 	return m, tea.Quit
 }
 
 // handleMenuHighlighting returns a command to highlight the pressed key in the menu.
 // This is purely visual - it briefly underlines the corresponding menu item.
 func (m *home) handleMenuHighlighting(msg tea.KeyMsg) tea.Cmd {
-	if m.state == statePrompt || m.state == stateHelp || m.state == stateConfirm {
+	if m.state == statePrompt || m.state == stateHelp || m.state == stateKeyHelp || m.state == stateConfirm {
 		return nil
 	}
 	// If it's in the global keymap, we should try to highlight it.
@@ -461,6 +788,11 @@ func (m *home) handleMenuHighlighting(msg tea.KeyMsg) tea.Cmd {
 }
 
 func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
+	// Every key is forwarded to the instance during inline attach
+	if m.state == stateInlineAttach {
+		return m.handleInlineAttachState(msg)
+	}
+
 	// Get the menu highlight command - this is batched with the action command later
 	highlightCmd := m.handleMenuHighlighting(msg)
 
@@ -473,21 +805,114 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		shouldClose := m.fileBrowserOverlay.HandleKeyPress(msg)
 		if shouldClose {
 			if m.fileBrowserOverlay.IsSubmitted() {
-				// User selected a directory, proceed to mode selection
 				selectedPath := m.fileBrowserOverlay.GetSelectedPath()
 				m.pendingInstancePath = selectedPath
+				if err := m.appState.SetLastRepoPath(selectedPath); err != nil {
+					log.WarningLog.Printf("Failed to save the last used path: %v", err)
+				}
+				if m.fileBrowserOverlay.IsScratch() {
+					// Scratch sessions always run locally, skip mode selection
+					m.fileBrowserOverlay = nil
+					m.pendingScratch = true
+					m.pendingSessionType = session.LocalSessionType()
+					return m.createInstanceWithPath(selectedPath)
+				}
+				// User selected a directory, proceed to mode selection
 				m.fileBrowserOverlay = nil
-				return m.showModeSelector()
+				return m.selectRepoDirectory(selectedPath)
 			} else if m.fileBrowserOverlay.IsCanceled() {
 				// User canceled, go back to default state
 				m.fileBrowserOverlay = nil
 				m.state = stateDefault
 				m.promptAfterName = false
+				return m, nil
 			}
 		}
+		return m, m.fileBrowserOverlay.LoadCmd()
+	}
+
+	if m.state == stateLoading && m.loadingOverlay != nil && m.loadingOverlay.HasError() {
+		m.loadingOverlay = nil
+		m.state = stateDefault
 		return m, nil
 	}
 
+	if m.state == stateRelayTarget {
+		return m.handleRelayTargetState(msg)
+	}
+
+	if m.state == stateRelayPrompt {
+		return m.handleRelayPromptState(msg)
+	}
+
+	if m.state == stateNotes {
+		return m.handleNotesState(msg)
+	}
+
+	if m.state == stateSnippets {
+		return m.handleSnippetsState(msg)
+	}
+
+	if m.state == stateRebase {
+		return m.handleRebaseState(msg)
+	}
+
+	if m.state == stateBoard {
+		return m.handleBoardState(msg)
+	}
+
+	if m.state == stateAudit {
+		return m.handleAuditState(msg)
+	}
+
+	if m.state == statePicker {
+		return m.handlePickerState(msg)
+	}
+
+	if m.state == stateKeyHelp {
+		return m.handleKeyHelpState(msg)
+	}
+
+	if m.state == stateLoadPrompt {
+		return m.handleLoadPromptState(msg)
+	}
+
+	if m.state == stateIssueSelect {
+		return m.handleIssueSelectState(msg)
+	}
+
+	if m.state == stateStartup {
+		return m.handleStartupState(msg)
+	}
+
+	if m.state == stateRecovery {
+		return m.handleRecoveryState(msg)
+	}
+
+	if m.state == stateBulk {
+		return m.handleBulkState(msg)
+	}
+
+	if m.state == stateDiffTarget {
+		return m.handleDiffTargetState(msg)
+	}
+
+	if m.state == stateDiffRef {
+		return m.handleDiffRefState(msg)
+	}
+
+	if m.state == stateReplyPrompt {
+		return m.handleReplyPromptState(msg)
+	}
+
+	if m.state == stateAuxCommand {
+		return m.handleAuxCommandState(msg)
+	}
+
+	if m.state == stateModeSelect && m.modeSwitchInstance != nil {
+		return m.handleModeSwitchState(msg)
+	}
+
 	if m.state == stateModeSelect {
 		// Handle mode selector key presses
 		shouldClose := m.modeSelectorOverlay.HandleKeyPress(msg)
@@ -501,6 +926,8 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 				// User canceled, go back to default state
 				m.modeSelectorOverlay = nil
 				m.pendingInstancePath = ""
+				m.pendingRepoConfig = nil
+				m.pendingIssue = nil
 				m.state = stateDefault
 				m.promptAfterName = false
 			}
@@ -529,21 +956,15 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		switch msg.Type {
 		// Start the instance (enable previews etc) and go back to the main menu state.
 		case tea.KeyEnter:
-			if len(instance.Title) == 0 {
-				return m, m.handleError(fmt.Errorf("title cannot be empty"))
+			if err := session.ValidateTitle(instance.Title, instance, allInstances); err != nil {
+				return m, m.handleError(err)
 			}
 
-			// Show loading overlay and start instance in background
-			m.loadingOverlay = overlay.NewLoadingOverlay("Creating Instance", &m.spinner)
-			m.loadingOverlay.SetWidth(50)
-			m.loadingOverlay.SetStatus("Initializing...")
-			m.state = stateLoading
-
-			// Start instance in a goroutine and send progress messages
-			return m, m.startInstanceAsync(instance)
+			// Install dependencies as configured, then start the instance in the background
+			return m, m.launchInstance(instance)
 		case tea.KeyRunes:
-			if len(instance.Title) >= 32 {
-				return m, m.handleError(fmt.Errorf("title cannot be longer than 32 characters"))
+			if utf8.RuneCountInString(instance.Title) >= session.TitleMaxLength {
+				return m, m.handleError(fmt.Errorf("title cannot be longer than %d characters", session.TitleMaxLength))
 			}
 			if err := instance.SetTitle(instance.Title + string(msg.Runes)); err != nil {
 				return m, m.handleError(err)
@@ -552,7 +973,9 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			if len(instance.Title) == 0 {
 				return m, nil
 			}
-			if err := instance.SetTitle(instance.Title[:len(instance.Title)-1]); err != nil {
+			// Remove the last character, not the last byte of it
+			runes := []rune(instance.Title)
+			if err := instance.SetTitle(string(runes[:len(runes)-1])); err != nil {
 				return m, m.handleError(err)
 			}
 		case tea.KeySpace:
@@ -577,6 +1000,13 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 	} else if m.state == statePrompt {
 		// Use the new TextInputOverlay component to handle all key events
 		shouldClose := m.textInputOverlay.HandleKeyPress(msg)
+		if m.textInputOverlay.EditorRequested {
+			return m, m.editTextInput()
+		}
+		if m.textInputOverlay.FileRequested {
+			m.textInputOverlay.FileRequested = false
+			return m.showPromptFilePicker()
+		}
 
 		// Check if the form was submitted or canceled
 		if shouldClose {
@@ -585,30 +1015,26 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			if selected == nil {
 				return m, nil
 			}
+			var sendCmd tea.Cmd
 			if m.textInputOverlay.IsSubmitted() {
-				if err := selected.SendPrompt(m.textInputOverlay.GetValue()); err != nil {
-					// TODO: we probably end up in a bad state here.
-					return m, m.handleError(err)
-				}
+				sendCmd = m.sendPrompt(selected, m.textInputOverlay.GetValue())
 			}
 
 			// Close the overlay and reset state
 			m.textInputOverlay = nil
 			m.state = stateDefault
-			return m, tea.Sequence(
-				tea.WindowSize(),
-				func() tea.Msg {
-					m.menu.SetState(ui.StateDefault)
-					m.showHelpScreen(helpStart(selected), nil)
-					return nil
-				},
-			)
+			m.menu.SetState(ui.StateDefault)
+			m.showHelpScreen(helpStart(selected), nil)
+			return m, tea.Batch(sendCmd, tea.WindowSize())
 		}
 
 		return m, nil
 	} else if m.state == stateRename {
 		// Use the TextInputOverlay component to handle key events for renaming
 		shouldClose := m.textInputOverlay.HandleKeyPress(msg)
+		if m.textInputOverlay.EditorRequested {
+			return m, m.editTextInput()
+		}
 
 		// Check if the form was submitted or canceled
 		if shouldClose {
@@ -621,17 +1047,18 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			}
 			if m.textInputOverlay.IsSubmitted() {
 				newTitle := m.textInputOverlay.GetValue()
-				if err := selected.Rename(newTitle); err != nil {
-					m.textInputOverlay = nil
+				m.textInputOverlay = nil
+				m.menu.SetState(ui.StateDefault)
+				if err := session.ValidateTitle(newTitle, selected, m.list.GetInstances()); err != nil {
 					m.state = stateDefault
-					m.menu.SetState(ui.StateDefault)
 					return m, m.handleError(err)
 				}
-				// Save the updated instance
-				if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
-					m.textInputOverlay = nil
-					m.state = stateDefault
-					m.menu.SetState(ui.StateDefault)
+				// Started instances can have their branch, worktree and session renamed too
+				if newTitle != selected.Title && selected.Started() {
+					return m, m.confirmRenameAll(selected, newTitle)
+				}
+				m.state = stateDefault
+				if err, ok := m.renameInstance(selected, newTitle, false).(error); ok {
 					return m, m.handleError(err)
 				}
 			}
@@ -658,6 +1085,11 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		if shouldClose {
 			m.state = stateDefault
 			m.confirmationOverlay = nil
+			// Deliver the confirmed action's result (error, instance change, ...) to Update
+			if result := m.confirmResult; result != nil {
+				m.confirmResult = nil
+				return m, func() tea.Msg { return result }
+			}
 			return m, nil
 		}
 		return m, nil
@@ -668,7 +1100,7 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 	// Always check for escape key first to ensure it doesn't get intercepted elsewhere
 	if msg.Type == tea.KeyEsc {
 		// If in preview tab and in scroll mode, exit scroll mode
-		if !m.tabbedWindow.IsInDiffTab() && m.tabbedWindow.IsPreviewInScrollMode() {
+		if m.tabbedWindow.IsInPreviewTab() && m.tabbedWindow.IsPreviewInScrollMode() {
 			// Use the selected instance from the list
 			selected := m.list.GetSelectedInstance()
 			err := m.tabbedWindow.ResetPreviewToNormalMode(selected)
@@ -684,28 +1116,36 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		return m.handleQuit()
 	}
 
+	if handled, cmd := m.handleJumpKey(msg); handled {
+		return m, cmd
+	}
+
+	if handled, cmd := m.handlePromptResponseKey(msg); handled {
+		return m, cmd
+	}
+
 	name, ok := keys.GlobalKeyStringsMap[msg.String()]
 	if !ok {
 		return m, nil
 	}
 
 	switch name {
+	case keys.KeyJump:
+		m.jumpPending = true
+		return m, nil
 	case keys.KeyHelp:
-		return m.showHelpScreen(helpTypeGeneral{}, nil)
+		return m.showKeyHelp()
 	case keys.KeyPrompt:
-		if m.list.NumInstances() >= GlobalInstanceLimit {
-			return m, m.handleError(
-				fmt.Errorf("you can't create more than %d instances", GlobalInstanceLimit))
-		}
 		m.promptAfterName = true
-		return m.showFileBrowser()
+		model, cmd := m.showFileBrowser()
+		return model, tea.Batch(cmd, m.warnInstanceLimit(1))
 	case keys.KeyNew:
-		if m.list.NumInstances() >= GlobalInstanceLimit {
-			return m, m.handleError(
-				fmt.Errorf("you can't create more than %d instances", GlobalInstanceLimit))
-		}
 		m.promptAfterName = false
-		return m.showFileBrowser()
+		model, cmd := m.showFileBrowser()
+		return model, tea.Batch(cmd, m.warnInstanceLimit(1))
+	case keys.KeyIssue:
+		model, cmd := m.showIssuePicker()
+		return model, tea.Batch(cmd, m.warnInstanceLimit(1))
 	case keys.KeyUp:
 		m.list.Up()
 		return m, tea.Batch(highlightCmd, m.instanceChanged())
@@ -713,11 +1153,9 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		m.list.Down()
 		return m, tea.Batch(highlightCmd, m.instanceChanged())
 	case keys.KeyShiftUp:
-		m.tabbedWindow.ScrollUp()
-		return m, tea.Batch(highlightCmd, m.instanceChanged())
+		return m, tea.Batch(highlightCmd, m.tabbedWindow.ScrollUp(), m.instanceChanged())
 	case keys.KeyShiftDown:
-		m.tabbedWindow.ScrollDown()
-		return m, tea.Batch(highlightCmd, m.instanceChanged())
+		return m, tea.Batch(highlightCmd, m.tabbedWindow.ScrollDown(), m.instanceChanged())
 	case keys.KeyMoveUp:
 		if m.list.MoveUp() {
 			// Schedule debounced save to avoid blocking on rapid key presses
@@ -740,7 +1178,7 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		return m, tea.Batch(highlightCmd, m.instanceChanged())
 	case keys.KeyArchive:
 		selected := m.list.GetSelectedInstance()
-		if selected == nil {
+		if selected == nil || selected.Scratch {
 			return m, nil
 		}
 
@@ -758,20 +1196,27 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			message := fmt.Sprintf("[!] Restore session '%s'?", selected.Title)
 			return m, m.confirmAction(message, restoreAction)
 		} else {
-			// In active view - archive the instance
+			// In active view - archive the instance. Pausing is deferred so it can be undone.
 			archiveAction := func() tea.Msg {
-				// Pause the instance first if it's running
-				if !selected.Paused() {
-					if err := selected.Pause(); err != nil {
-						return err
-					}
-				}
 				selected.Archived = true
-				if err := m.storage.ArchiveInstance(selected.Title); err != nil {
-					return err
-				}
 				m.list.RemoveSelectedFromView()
-				return instanceChangedMsg{}
+				return m.deferAction(&pendingUndo{
+					description: fmt.Sprintf("Archived '%s'", selected.Title),
+					undo: func() error {
+						selected.Archived = false
+						return nil
+					},
+					commit: func() error {
+						// Pause the instance first if it's running
+						if !selected.Paused() {
+							if err := selected.Pause(); err != nil {
+								selected.Archived = false
+								return err
+							}
+						}
+						return m.storage.ArchiveInstance(selected.Title)
+					},
+				})
 			}
 			message := fmt.Sprintf("[!] Archive session '%s'?", selected.Title)
 			return m, m.confirmAction(message, archiveAction)
@@ -779,73 +1224,127 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 	case keys.KeyTab:
 		m.tabbedWindow.Toggle()
 		m.menu.SetInDiffTab(m.tabbedWindow.IsInDiffTab())
+		m.menu.SetInFilesTab(m.tabbedWindow.IsInFilesTab())
 		return m, tea.Batch(highlightCmd, m.instanceChanged())
 	case keys.KeyKill:
 		selected := m.list.GetSelectedInstance()
 		if selected == nil {
 			return m, nil
 		}
-
-		// Create the kill action as a tea.Cmd
-		killAction := func() tea.Msg {
-			// Get worktree and check if branch is checked out
-			worktree, err := selected.GetGitWorktree()
-			if err != nil {
-				return err
-			}
-
-			// Only check if branch is checked out if worktree path exists
-			// Archived sessions may have had their worktree removed
-			if _, statErr := os.Stat(worktree.GetWorktreePath()); statErr == nil {
-				checkedOut, err := worktree.IsBranchCheckedOut()
-				if err != nil {
-					return err
-				}
-
-				if checkedOut {
-					return fmt.Errorf("instance %s is currently checked out", selected.Title)
-				}
-			}
-
-			// Delete from storage first
-			if err := m.storage.DeleteInstance(selected.Title); err != nil {
-				return err
-			}
-
-			// Then kill the instance
-			m.list.Kill()
-			return instanceChangedMsg{}
+		return m, m.listUntrackedForKill(selected)
+	case keys.KeyUndo:
+		return m, m.undoPendingAction()
+	case keys.KeyRelay:
+		return m.startRelay()
+	case keys.KeyNotes:
+		return m.showNotes()
+	case keys.KeySnippets:
+		return m.showSnippets()
+	case keys.KeyRebase:
+		return m.showRebase()
+	case keys.KeyFixup:
+		return m.createFixupCommits()
+	case keys.KeyStats:
+		return m.showStats()
+	case keys.KeyLand:
+		return m.toggleLand()
+	case keys.KeyMergeBase:
+		return m.mergeDefaultBranch()
+	case keys.KeySwitchMode:
+		return m.showModeSwitch()
+	case keys.KeyAuxView:
+		return m.toggleAuxView()
+	case keys.KeyAuxCommand:
+		return m.showAuxCommand()
+	case keys.KeyCheck:
+		return m.runCheck()
+	case keys.KeyBoard:
+		return m.showBoard()
+	case keys.KeyTimeline:
+		return m.showTimeline()
+	case keys.KeyAudit:
+		return m.showAudit()
+	case keys.KeyQuarantine:
+		return m.acknowledgeQuarantine()
+	case keys.KeyPicker:
+		return m.showPicker()
+	case keys.KeyProfiler:
+		return m.showProfiler()
+	case keys.KeyReview:
+		return m.startReview()
+	case keys.KeyTodos:
+		m.tabbedWindow.ToggleTodos()
+		return m, m.instanceChanged()
+	case keys.KeyOpenCI:
+		return m.openFailedCI()
+	case keys.KeyDiffTarget:
+		return m.showDiffTargets()
+	case keys.KeyOpenWorktree:
+		return m.openWorktree()
+	case keys.KeyEditFile:
+		if !m.tabbedWindow.IsInFilesTab() {
+			return m, nil
 		}
-
-		// Show confirmation modal
-		message := fmt.Sprintf("[!] Kill session '%s'?", selected.Title)
-		return m, m.confirmAction(message, killAction)
+		return m, m.editFile()
 	case keys.KeySubmit:
 		selected := m.list.GetSelectedInstance()
-		if selected == nil {
+		if selected == nil || selected.Scratch {
 			return m, nil
 		}
+		if err := selected.CheckPush(); err != nil {
+			return m, m.handleError(err)
+		}
 
-		// Create the push action as a tea.Cmd
-		pushAction := func() tea.Msg {
-			// Default commit message with timestamp
-			commitMsg := fmt.Sprintf("[claudesquad] update from '%s' on %s", selected.Title, time.Now().Format(time.RFC822))
+		// Default commit message with timestamp
+		commitMsg := fmt.Sprintf("[claudesquad] update from '%s' on %s", selected.Title, time.Now().Format(time.RFC822))
+		// Reference the issue so the pull request of the branch links it
+		if selected.Issue != nil {
+			commitMsg += "\n\nRefs " + selected.Issue.Reference()
+		}
+		push := func(commitMsg string) tea.Msg {
+			// The instance may have been quarantined while the message was edited
+			if err := selected.CheckPush(); err != nil {
+				return err
+			}
 			worktree, err := selected.GetGitWorktree()
 			if err != nil {
 				return err
 			}
-			if err = worktree.PushChanges(commitMsg, true); err != nil {
+			forge, err := git.DetectForge(worktree.GetRepoPath(), m.appConfig.Forge)
+			if err != nil {
 				return err
 			}
+			if err = worktree.PushChanges(forge, commitMsg, true); err != nil {
+				return err
+			}
+			selected.RecordPush()
 			return nil
 		}
+		pushAction := func() tea.Msg { return push(commitMsg) }
 
 		// Show confirmation modal
 		message := fmt.Sprintf("[!] Push changes from session '%s'?", selected.Title)
-		return m, m.confirmAction(message, pushAction)
+		cmd := m.confirmAction(message, pushAction)
+		// Edit the commit message in the external editor; saving it pushes
+		m.confirmationOverlay.EditKey = "e"
+		m.confirmationOverlay.OnEdit = func() {
+			m.state = stateDefault
+			m.confirmResult = editorRequestMsg{
+				text:    commitMsg + "\n" + commitMessageHelp,
+				pattern: "claude-squad-commit-*.txt",
+				apply: func(m *home, text string) tea.Cmd {
+					message := parseCommitMessage(text)
+					if message == "" {
+						return m.showInfo("Push aborted: empty commit message")
+					}
+					return func() tea.Msg { return push(message) }
+				},
+			}
+		}
+		return m, cmd
 	case keys.KeyCheckout:
 		selected := m.list.GetSelectedInstance()
-		if selected == nil {
+		if selected == nil || selected.Scratch {
 			return m, nil
 		}
 
@@ -857,9 +1356,36 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			m.instanceChanged()
 		})
 		return m, nil
+	case keys.KeyCheckoutRunning:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil || selected.Scratch || !selected.Started() || selected.Paused() {
+			return m, nil
+		}
+		rev, err := selected.CheckoutSnapshot()
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		worktree, err := selected.GetGitWorktree()
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		return m, m.showInfo(fmt.Sprintf("Checked out '%s' at %.7s in %s, detached; the session keeps running",
+			selected.Title, rev, worktree.GetRepoPath()))
+	case keys.KeyInlineAttach:
+		return m.startInlineAttach()
+	case keys.KeyPauseAll:
+		if m.showBulkProgress() {
+			return m, nil
+		}
+		return m.confirmPauseAll()
+	case keys.KeyResumeAll:
+		if m.showBulkProgress() {
+			return m, nil
+		}
+		return m.resumeAll()
 	case keys.KeyResume:
 		selected := m.list.GetSelectedInstance()
-		if selected == nil {
+		if selected == nil || selected.Scratch {
 			return m, nil
 		}
 		if err := selected.Resume(); err != nil {
@@ -872,25 +1398,12 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		if m.list.NumInstances() == 0 {
 			return m, nil
 		}
-		selected := m.list.GetSelectedInstance()
-		if selected == nil || selected.Paused() || !selected.SessionAlive() {
+		// In the files tab, enter opens the diff of the selected file
+		if m.tabbedWindow.IsInFilesTab() && m.tabbedWindow.SelectedFile() != "" {
+			m.tabbedWindow.ToggleFileDiff()
 			return m, nil
 		}
-		// Show help screen before attaching
-		m.showHelpScreen(helpTypeInstanceAttach{}, func() {
-			ch, err := m.list.Attach()
-			if err != nil {
-				m.handleError(err)
-				return
-			}
-			<-ch
-			m.state = stateDefault
-			// Save instances to persist LastOpenedAt
-			if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
-				m.handleError(err)
-			}
-		})
-		return m, nil
+		return m.attachSelected()
 	case keys.KeyRename:
 		selected := m.list.GetSelectedInstance()
 		if selected == nil {
@@ -912,20 +1425,47 @@ func (m *home) instanceChanged() tea.Cmd {
 	// selected may be nil
 	selected := m.list.GetSelectedInstance()
 
-	m.tabbedWindow.UpdateDiff(selected)
+	renderDiff := m.tabbedWindow.UpdateDiff(selected)
+	m.tabbedWindow.UpdateFiles(selected)
+	m.tabbedWindow.UpdateChecks(selected)
 	m.tabbedWindow.SetInstance(selected)
 	// Update menu with current instance
 	m.menu.SetInstance(selected)
 
 	// If there's no selected instance, we don't need to update the preview.
 	if err := m.tabbedWindow.UpdatePreview(selected); err != nil {
-		return m.handleError(err)
+		return tea.Batch(renderDiff, m.handleError(err))
 	}
-	return nil
+	// Sessions skipped by --fast-start are restored once selected
+	return tea.Batch(renderDiff, m.restoreSelected())
 }
 
 type keyupMsg struct{}
 
+// attachSelected attaches to the selected instance, after showing the help
+// screen about detaching.
+func (m *home) attachSelected() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || selected.Paused() || !selected.SessionAlive() {
+		return m, nil
+	}
+	// Show help screen before attaching
+	m.showHelpScreen(helpTypeInstanceAttach{}, func() {
+		ch, err := m.list.Attach()
+		if err != nil {
+			m.handleError(err)
+			return
+		}
+		<-ch
+		m.state = stateDefault
+		// Save instances to persist LastOpenedAt
+		if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+			m.handleError(err)
+		}
+	})
+	return m, nil
+}
+
 // keydownCallback clears the menu option highlighting after 500ms.
 func (m *home) keydownCallback(name keys.KeyName) tea.Cmd {
 	m.menu.Keydown(name)
@@ -942,6 +1482,11 @@ func (m *home) keydownCallback(name keys.KeyName) tea.Cmd {
 // hideErrMsg implements tea.Msg and clears the error text from the screen.
 type hideErrMsg struct{}
 
+// hideInfoMsg implements tea.Msg and clears the given notice from the screen.
+type hideInfoMsg struct {
+	info string
+}
+
 // previewTickMsg implements tea.Msg and triggers a preview update
 type previewTickMsg struct{}
 
@@ -960,6 +1505,9 @@ type metadataUpdateResultMsg struct {
 	updateResults  []session.UpdateResult
 	syncedFromDisk bool
 	diskInstances  []*session.Instance
+	reviewed       []*session.Instance
+	// restarted are the instances whose stopped program was restarted
+	restarted []session.RestartResult
 }
 
 type instanceChangedMsg struct{}
@@ -980,13 +1528,6 @@ type loadingCompleteMsg struct {
 	err error
 }
 
-// tickUpdateMetadataCmd is the callback to update the metadata of the instances every 5 seconds.
-// Note that we iterate over all instances and capture their output. It's an expensive operation.
-var tickUpdateMetadataCmd = func() tea.Msg {
-	time.Sleep(5 * time.Second)
-	return tickUpdateMetadataMessage{}
-}
-
 // tickUpdateSummaryCmd is the callback to update instance summaries. This is staggered across instances
 // so we don't overwhelm the system with Claude CLI calls.
 var tickUpdateSummaryCmd = func() tea.Msg {
@@ -1009,6 +1550,19 @@ func (m *home) handleError(err error) tea.Cmd {
 	}
 }
 
+// showInfo shows a notice in the error box and returns a callback tea.Cmd that clears it after 3 seconds.
+func (m *home) showInfo(info string) tea.Cmd {
+	m.errBox.SetInfo(info)
+	return func() tea.Msg {
+		select {
+		case <-m.ctx.Done():
+		case <-time.After(3 * time.Second):
+		}
+
+		return hideInfoMsg{info: info}
+	}
+}
+
 // requestSave schedules a debounced save operation.
 // If a save is already pending, this does nothing (the pending save will include all changes).
 func (m *home) requestSave() tea.Cmd {
@@ -1050,14 +1604,9 @@ func (m *home) confirmAction(message string, action tea.Cmd) tea.Cmd {
 	// Set callbacks for confirmation and cancellation
 	m.confirmationOverlay.OnConfirm = func() {
 		m.state = stateDefault
-		// Execute the action if it exists
+		// Execute the action if it exists. Its result is delivered once the overlay closes.
 		if action != nil {
-			if msg := action(); msg != nil {
-				// Handle error messages from the action
-				if err, ok := msg.(error); ok {
-					m.handleError(err)
-				}
-			}
+			m.confirmResult = action()
 		}
 	}
 
@@ -1068,25 +1617,57 @@ func (m *home) confirmAction(message string, action tea.Cmd) tea.Cmd {
 	return nil
 }
 
-// showFileBrowser displays the file browser overlay for selecting a directory
-func (m *home) showFileBrowser() (tea.Model, tea.Cmd) {
-	// Get current working directory as default starting point
-	cwd, err := os.Getwd()
-	if err != nil {
-		cwd = "~"
+// untrackedReport summarizes the untracked files that will be deleted (or moved back to
+// the main checkout) when an instance is killed.
+func untrackedReport(entries []git.UntrackedEntry, rescuePatterns []string) string {
+	if len(entries) == 0 {
+		return ""
 	}
 
-	fb, err := overlay.NewFileBrowserOverlay(cwd)
+	const maxShown = 5
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Untracked files (%s):", git.FormatSize(total)))
+	for i, entry := range entries {
+		if i == maxShown {
+			b.WriteString(fmt.Sprintf("\n  ... and %d more", len(entries)-maxShown))
+			break
+		}
+		note := git.FormatSize(entry.Size)
+		if entry.Ignored {
+			note += ", ignored"
+		}
+		action := "delete"
+		if git.MatchesAnyPattern(entry.Path, rescuePatterns) {
+			action = "keep"
+		}
+		b.WriteString(fmt.Sprintf("\n  %s %s (%s)", action, entry.Path, note))
+	}
+	return b.String()
+}
+
+// showFileBrowser displays the file browser overlay for selecting a directory
+func (m *home) showFileBrowser() (tea.Model, tea.Cmd) {
+	fb, err := overlay.NewFileBrowserOverlay(m.fileBrowserStartPath())
 	if err != nil {
 		return m, m.handleError(fmt.Errorf("failed to open file browser: %w", err))
 	}
 
 	// Set size based on current window dimensions
 	fb.SetSize(70, 25)
+	fb.SetSpinner(&m.spinner)
+	if m.appConfig.FileBrowserIgnore != nil {
+		fb.SetIgnore(m.appConfig.FileBrowserIgnore)
+	}
+	m.setFileBrowserShortcuts(fb)
 	m.fileBrowserOverlay = fb
 	m.state = stateFileBrowser
 
-	return m, nil
+	return m, fb.LoadCmd()
 }
 
 // showModeSelector displays the mode selector overlay for choosing session type
@@ -1099,18 +1680,31 @@ func (m *home) showModeSelector() (tea.Model, tea.Cmd) {
 
 // createInstanceWithPath creates a new instance with the given path and enters the name input state
 func (m *home) createInstanceWithPath(path string) (tea.Model, tea.Cmd) {
+	issue := m.pendingIssue
+	m.pendingIssue = nil
+	// The overlay that led here is closed, so a failure goes back to the list
+	failed := func(err error) (tea.Model, tea.Cmd) {
+		m.pendingInstancePath = ""
+		m.pendingSessionType = ""
+		m.pendingScratch = false
+		m.pendingRepoConfig = nil
+		m.state = stateDefault
+		m.promptAfterName = false
+		return m, m.handleError(err)
+	}
+
 	// Determine Docker repo URL for clone mode
 	var dockerRepoURL string
 	if m.pendingSessionType == config.SessionTypeDockerClone {
 		// For clone mode, we need to get the remote URL from the git repo
 		repoURL, err := getGitRemoteURL(path)
 		if err != nil {
-			return m, m.handleError(fmt.Errorf("failed to get git remote URL: %w", err))
+			return failed(fmt.Errorf("failed to get git remote URL: %w", err))
 		}
 		dockerRepoURL = repoURL
 	}
 
-	instance, err := session.NewInstance(session.InstanceOptions{
+	opts := session.InstanceOptions{
 		Title:           "",
 		Path:            path,
 		Program:         m.program,
@@ -1118,9 +1712,25 @@ func (m *home) createInstanceWithPath(path string) (tea.Model, tea.Cmd) {
 		SessionType:     m.pendingSessionType,
 		DockerBaseImage: m.appConfig.DockerBaseImage,
 		DockerRepoURL:   dockerRepoURL,
-	})
+		Scratch:         m.pendingScratch,
+		Owner:           m.appConfig.CurrentIdentity(),
+		ContextFile:     m.appConfig.ContextFile,
+		ContextTemplate: m.appConfig.ContextTemplate,
+		Env:             m.appConfig.Env,
+	}
+	if issue != nil {
+		opts.Title = session.IssueTitle(issue)
+		opts.Prompt = session.IssuePrompt(issue)
+	}
+	opts.ApplyRepoConfig(m.pendingRepoConfig)
+	instance, err := session.NewInstance(opts)
 	if err != nil {
-		return m, m.handleError(err)
+		return failed(err)
+	}
+	if issue != nil {
+		// The prompt prefills the prompt overlay once the instance is named
+		instance.Issue = session.NewIssueLink(issue)
+		instance.Prompt = opts.Prompt
 	}
 
 	m.newInstanceFinalizer = m.list.AddInstance(instance)
@@ -1133,6 +1743,8 @@ func (m *home) createInstanceWithPath(path string) (tea.Model, tea.Cmd) {
 	// Clear pending state
 	m.pendingInstancePath = ""
 	m.pendingSessionType = ""
+	m.pendingScratch = false
+	m.pendingRepoConfig = nil
 
 	return m, nil
 }
@@ -1167,11 +1779,8 @@ func (m *home) handleImportOrphanedSessions() (tea.Model, tea.Cmd) {
 		return m, m.handleError(fmt.Errorf("no orphaned sessions found"))
 	}
 
-	// Check instance limit
-	if m.list.NumInstances()+len(orphans) > GlobalInstanceLimit {
-		return m, m.handleError(fmt.Errorf("importing %d sessions would exceed the limit of %d instances",
-			len(orphans), GlobalInstanceLimit))
-	}
+	// Importing past the instance limit is allowed, with a warning
+	limitWarning := instanceLimitWarning(m.list.NumInstances(), len(orphans), m.instanceLimit())
 
 	// Import each orphaned session
 	importedCount := 0
@@ -1216,6 +1825,11 @@ func (m *home) handleImportOrphanedSessions() (tea.Model, tea.Cmd) {
 		return m, m.handleError(fmt.Errorf("imported %d session(s), %d failed", importedCount, len(importErrors)))
 	}
 
+	if limitWarning != "" {
+		return m, tea.Batch(tea.WindowSize(), m.instanceChanged(),
+			m.showInfo(fmt.Sprintf("Imported %d orphaned session(s). %s", importedCount, limitWarning)))
+	}
+
 	// Show success message via error box (it's just a message display)
 	m.errBox.SetError(fmt.Errorf("imported %d orphaned session(s)", importedCount))
 	return m, tea.Batch(
@@ -1247,6 +1861,46 @@ func (s state) String() string {
 		return "file_browser"
 	case stateRename:
 		return "rename"
+	case stateModeSelect:
+		return "mode_select"
+	case stateRelayTarget:
+		return "relay_target"
+	case stateRelayPrompt:
+		return "relay_prompt"
+	case stateNotes:
+		return "notes"
+	case stateSnippets:
+		return "snippets"
+	case stateRebase:
+		return "rebase"
+	case stateBoard:
+		return "board"
+	case stateAudit:
+		return "audit"
+	case statePicker:
+		return "picker"
+	case stateLoadPrompt:
+		return "load_prompt"
+	case stateIssueSelect:
+		return "issue_select"
+	case stateStartup:
+		return "startup"
+	case stateRecovery:
+		return "recovery"
+	case stateDiffTarget:
+		return "diff_target"
+	case stateDiffRef:
+		return "diff_ref"
+	case stateBulk:
+		return "bulk"
+	case stateInlineAttach:
+		return "inline_attach"
+	case stateReplyPrompt:
+		return "reply_prompt"
+	case stateAuxCommand:
+		return "aux_command"
+	case stateKeyHelp:
+		return "key_help"
 	default:
 		return "unknown"
 	}
@@ -1266,7 +1920,7 @@ func (m *home) generateSnapshot() *inspect.Snapshot {
 	overlayType := ""
 	hasOverlay := false
 	switch m.state {
-	case statePrompt, stateRename:
+	case statePrompt, stateRename, stateRelayPrompt, stateDiffRef, stateReplyPrompt, stateAuxCommand:
 		overlayType = "text_input"
 		hasOverlay = true
 	case stateHelp:
@@ -1281,6 +1935,39 @@ func (m *home) generateSnapshot() *inspect.Snapshot {
 	case stateFileBrowser:
 		overlayType = "file_browser"
 		hasOverlay = true
+	case stateModeSelect, stateRelayTarget, stateDiffTarget, stateIssueSelect:
+		overlayType = "selection"
+		hasOverlay = true
+	case stateNotes:
+		overlayType = "notes"
+		hasOverlay = true
+	case stateSnippets:
+		overlayType = "snippets"
+		hasOverlay = true
+	case stateRebase:
+		overlayType = "rebase"
+		hasOverlay = true
+	case stateBoard:
+		overlayType = "board"
+		hasOverlay = true
+	case stateAudit:
+		overlayType = "audit"
+		hasOverlay = true
+	case statePicker, stateLoadPrompt:
+		overlayType = "picker"
+		hasOverlay = true
+	case stateKeyHelp:
+		overlayType = "key_help"
+		hasOverlay = true
+	case stateStartup:
+		overlayType = "startup"
+		hasOverlay = true
+	case stateRecovery:
+		overlayType = "recovery"
+		hasOverlay = true
+	case stateBulk:
+		overlayType = "bulk"
+		hasOverlay = true
 	}
 
 	// Build component tree
@@ -1409,7 +2096,7 @@ func (m *home) View() string {
 		errBoxView,
 	)
 
-	if m.state == statePrompt || m.state == stateRename {
+	if m.state == statePrompt || m.state == stateRename || m.state == stateRelayPrompt || m.state == stateDiffRef || m.state == stateReplyPrompt || m.state == stateAuxCommand {
 		if m.textInputOverlay == nil {
 			log.ErrorLog.Printf("text input overlay is nil")
 		}
@@ -1439,6 +2126,66 @@ func (m *home) View() string {
 			log.ErrorLog.Printf("mode selector overlay is nil")
 		}
 		return overlay.PlaceOverlay(0, 0, m.modeSelectorOverlay.Render(), mainView, true, true)
+	} else if m.state == stateRelayTarget || m.state == stateDiffTarget || m.state == stateIssueSelect {
+		if m.selectionOverlay == nil {
+			log.ErrorLog.Printf("selection overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.selectionOverlay.Render(), mainView, true, true)
+	} else if m.state == stateNotes {
+		if m.notesOverlay == nil {
+			log.ErrorLog.Printf("notes overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.notesOverlay.Render(), mainView, true, true)
+	} else if m.state == stateSnippets {
+		if m.snippetsOverlay == nil {
+			log.ErrorLog.Printf("snippets overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.snippetsOverlay.Render(), mainView, true, true)
+	} else if m.state == stateRebase {
+		if m.rebaseOverlay == nil {
+			log.ErrorLog.Printf("rebase overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.rebaseOverlay.Render(), mainView, true, true)
+	} else if m.state == stateBoard {
+		if m.boardOverlay == nil {
+			log.ErrorLog.Printf("board overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.boardOverlay.Render(), mainView, true, true)
+	} else if m.state == stateAudit {
+		if m.auditOverlay == nil {
+			log.ErrorLog.Printf("audit overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.auditOverlay.Render(), mainView, true, true)
+	} else if m.state == statePicker {
+		if m.pickerOverlay == nil {
+			log.ErrorLog.Printf("picker overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.pickerOverlay.Render(), mainView, true, true)
+	} else if m.state == stateKeyHelp {
+		if m.helpOverlay == nil {
+			log.ErrorLog.Printf("help overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.helpOverlay.Render(), mainView, true, true)
+	} else if m.state == stateLoadPrompt {
+		if m.filePickerOverlay == nil {
+			log.ErrorLog.Printf("file picker overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.filePickerOverlay.Render(), mainView, true, true)
+	} else if m.state == stateStartup {
+		if m.startupOverlay == nil {
+			log.ErrorLog.Printf("startup overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.startupOverlay.Render(), mainView, true, true)
+	} else if m.state == stateRecovery {
+		if m.recoveryOverlay == nil {
+			log.ErrorLog.Printf("recovery overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.recoveryOverlay.Render(), mainView, true, true)
+	} else if m.state == stateBulk {
+		if m.bulkOverlay == nil {
+			log.ErrorLog.Printf("bulk overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.bulkOverlay.Render(), mainView, true, true)
 	}
 
 	return mainView
@@ -2,14 +2,19 @@ package app
 
 import (
 	"claude-squad/config"
+	"claude-squad/keys"
 	"claude-squad/log"
 	"claude-squad/session"
+	"claude-squad/session/git"
 	"claude-squad/ui"
 	"claude-squad/ui/overlay"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -269,6 +274,47 @@ func TestConfirmationFlowSimulation(t *testing.T) {
 	assert.Contains(t, rendered, "Kill session 'test-session'?")
 }
 
+// TestKillListsUntrackedInBackground tests that the kill is confirmed once the
+// untracked files have been listed by a command, unless something else was opened
+func TestKillListsUntrackedInBackground(t *testing.T) {
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	list := ui.NewList(&spinner, false)
+	instance, err := session.NewInstance(session.InstanceOptions{
+		Title:   "test-session",
+		Path:    t.TempDir(),
+		Program: "claude",
+	})
+	require.NoError(t, err)
+	_ = list.AddInstance(instance)
+	list.SetSelectedInstance(0)
+
+	h := &home{
+		ctx:       context.Background(),
+		state:     stateDefault,
+		appConfig: config.DefaultConfig(),
+		list:      list,
+		menu:      ui.NewMenu(),
+	}
+
+	_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("D")})
+	require.NotNil(t, cmd)
+	assert.Equal(t, stateDefault, h.state, "nothing is shown until the files are listed")
+
+	msg, ok := cmd().(untrackedListedMsg)
+	require.True(t, ok)
+	assert.Same(t, instance, msg.instance)
+
+	h.state = stateHelp
+	h.confirmKill(msg)
+	assert.Nil(t, h.confirmationOverlay, "the kill isn't confirmed over another overlay")
+
+	h.state = stateDefault
+	h.confirmKill(msg)
+	assert.Equal(t, stateConfirm, h.state)
+	require.NotNil(t, h.confirmationOverlay)
+	assert.Contains(t, h.confirmationOverlay.Render(), "Kill session 'test-session'?")
+}
+
 // TestConfirmActionWithDifferentTypes tests that confirmAction works with different action types
 func TestConfirmActionWithDifferentTypes(t *testing.T) {
 	h := &home{
@@ -464,3 +510,539 @@ func TestConfirmationModalVisualAppearance(t *testing.T) {
 	// Test that the danger indicator is preserved
 	assert.Contains(t, rendered, "[!")
 }
+
+// TestDeferredActionUndo tests that kill/archive actions are deferred until the undo window passes
+func TestDeferredActionUndo(t *testing.T) {
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	h := &home{
+		ctx:          context.Background(),
+		state:        stateDefault,
+		appConfig:    config.DefaultConfig(),
+		list:         ui.NewList(&spinner, false),
+		menu:         ui.NewMenu(),
+		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane(), ui.NewFilesPane(), ui.NewChecksPane()),
+		errBox:       ui.NewErrBox(),
+	}
+
+	t.Run("undo reverts without committing", func(t *testing.T) {
+		committed, undone := false, false
+		msg := h.deferAction(&pendingUndo{
+			description: "Killed 'test'",
+			undo:        func() error { undone = true; return nil },
+			commit:      func() error { committed = true; return nil },
+		})
+
+		scheduled, ok := msg.(undoScheduledMsg)
+		require.True(t, ok, "Expected undoScheduledMsg but got %T", msg)
+		assert.Equal(t, h.pendingUndo.id, scheduled.id)
+		assert.Contains(t, h.errBox.GetMessage(), "press u to undo")
+
+		h.undoPendingAction()
+		assert.True(t, undone)
+		assert.False(t, committed)
+		assert.Nil(t, h.pendingUndo)
+		assert.Empty(t, h.errBox.GetMessage())
+	})
+
+	t.Run("expiry commits the pending action", func(t *testing.T) {
+		committed := false
+		msg := h.deferAction(&pendingUndo{
+			description: "Archived 'test'",
+			undo:        func() error { return nil },
+			commit:      func() error { committed = true; return nil },
+		})
+
+		h.Update(undoExpiredMsg{id: msg.(undoScheduledMsg).id})
+		assert.True(t, committed)
+		assert.Nil(t, h.pendingUndo)
+	})
+
+	t.Run("new action commits the previous one and stale timers are ignored", func(t *testing.T) {
+		firstCommitted, secondCommitted := false, false
+		first := h.deferAction(&pendingUndo{
+			description: "Killed 'first'",
+			undo:        func() error { return nil },
+			commit:      func() error { firstCommitted = true; return nil },
+		})
+		h.deferAction(&pendingUndo{
+			description: "Killed 'second'",
+			undo:        func() error { return nil },
+			commit:      func() error { secondCommitted = true; return nil },
+		})
+		assert.True(t, firstCommitted)
+
+		h.Update(undoExpiredMsg{id: first.(undoScheduledMsg).id})
+		assert.False(t, secondCommitted)
+		assert.NotNil(t, h.pendingUndo)
+	})
+}
+
+func TestJumpToInstanceByNumber(t *testing.T) {
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	h := &home{
+		ctx:          context.Background(),
+		state:        stateDefault,
+		appConfig:    config.DefaultConfig(),
+		list:         ui.NewList(&spinner, false),
+		menu:         ui.NewMenu(),
+		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane(), ui.NewFilesPane(), ui.NewChecksPane()),
+		errBox:       ui.NewErrBox(),
+	}
+	for i := 1; i <= 12; i++ {
+		h.list.AddInstance(&session.Instance{Title: fmt.Sprintf("instance-%d", i)})
+	}
+
+	press := func(key string) {
+		var msg tea.KeyMsg
+		if key == "enter" {
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		} else {
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		h.handleKeyPress(msg)
+	}
+
+	press("3")
+	assert.Equal(t, "instance-3", h.list.GetSelectedInstance().Title)
+
+	press("g")
+	press("1")
+	press("1")
+	assert.Equal(t, "instance-11", h.list.GetSelectedInstance().Title)
+
+	// Enter jumps before the second digit is typed
+	press("g")
+	press("5")
+	press("enter")
+	assert.Equal(t, "instance-5", h.list.GetSelectedInstance().Title)
+	assert.False(t, h.jumpPending)
+
+	// Numbers beyond the list are ignored
+	press("g")
+	press("4")
+	press("2")
+	assert.Equal(t, "instance-5", h.list.GetSelectedInstance().Title)
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+	assert.Equal(t, []string{"code", "--wait"}, editorCommand())
+
+	// $VISUAL takes precedence
+	t.Setenv("VISUAL", "nvim")
+	assert.Equal(t, []string{"nvim"}, editorCommand())
+}
+
+func TestParseCommitMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"unchanged", "update\n" + commitMessageHelp, "update"},
+		{"multiple lines", "fix login\r\n\r\nbody\r\n# comment\r\n", "fix login\n\nbody"},
+		{"only comments", commitMessageHelp, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseCommitMessage(tt.text))
+		})
+	}
+}
+
+// TestStartupRestoreProgress tests the startup overlay while saved sessions are restored
+func TestStartupRestoreProgress(t *testing.T) {
+	first := &session.Instance{Title: "first"}
+	second := &session.Instance{Title: "second"}
+
+	t.Run("closes once all sessions are restored", func(t *testing.T) {
+		h := &home{state: stateStartup, startupOverlay: overlay.NewStartupOverlay(2)}
+
+		h.handleStartupRestored(instanceRestoredMsg{instance: first, startup: true})
+		assert.Equal(t, stateStartup, h.state)
+		assert.False(t, h.startupOverlay.Done())
+
+		h.handleStartupRestored(instanceRestoredMsg{instance: second, startup: true})
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.startupOverlay)
+	})
+
+	t.Run("reports failures after being closed", func(t *testing.T) {
+		h := &home{state: stateStartup, startupOverlay: overlay.NewStartupOverlay(2)}
+
+		// Closing the overlay keeps restoring in the background
+		h.handleStartupState(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Equal(t, stateDefault, h.state)
+		require.NotNil(t, h.startupOverlay)
+
+		h.handleStartupRestored(instanceRestoredMsg{instance: first, startup: true, err: fmt.Errorf("no such session")})
+		assert.Equal(t, stateDefault, h.state)
+		h.handleStartupRestored(instanceRestoredMsg{instance: second, startup: true})
+		assert.Equal(t, stateStartup, h.state)
+		assert.Equal(t, 1, h.startupOverlay.Failed())
+
+		h.handleStartupState(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Equal(t, stateDefault, h.state)
+		assert.Nil(t, h.startupOverlay)
+	})
+}
+
+func TestRecentRepos(t *testing.T) {
+	now := time.Now()
+	instances := []*session.Instance{
+		{Title: "old", Path: "/repos/a", CreatedAt: now.Add(-3 * time.Hour)},
+		{Title: "scratch", Path: "/tmp/notes", Scratch: true, CreatedAt: now},
+		{Title: "new", Path: "/repos/b", CreatedAt: now.Add(-time.Hour)},
+		{Title: "newer", Path: "/repos/a", CreatedAt: now.Add(-time.Minute)},
+	}
+	assert.Equal(t, []string{"/repos/a", "/repos/b"}, recentRepos(instances))
+}
+
+func TestOpenCommandLine(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"code {path}", []string{"code", "/work/my tree"}},
+		{"idea", []string{"idea", "/work/my tree"}},
+		{"code --new-window {path}", []string{"code", "--new-window", "/work/my tree"}},
+		{"emacsclient -n --eval (dired\"{path}\")", []string{"emacsclient", "-n", "--eval", "(dired\"/work/my tree\")"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, openCommandLine(tt.command, "/work/my tree"), tt.command)
+	}
+}
+
+func TestWorktreeActionsWithoutWorktree(t *testing.T) {
+	// Docker-clone sessions have no local worktree
+	instance := &session.Instance{Title: "clone", Status: session.Running}
+	instance.SetSession(&fakeMultiplexer{started: true})
+	instance.MarkAsStartedForTesting()
+	h := newTestHome(instance)
+	cfg := *h.appConfig
+	cfg.OpenCommand = "true {path}"
+	h.appConfig = &cfg
+
+	h.openWorktree()
+	assert.Contains(t, h.errBox.GetMessage(), "no local worktree to open")
+
+	h.showRebase()
+	assert.Contains(t, h.errBox.GetMessage(), "no local worktree to rebase")
+
+	_, cmd := h.createFixupCommits()
+	require.NotNil(t, cmd)
+	err, ok := cmd().(error)
+	require.True(t, ok)
+	assert.ErrorContains(t, err, "no local worktree to create fixups in")
+}
+
+func TestDetectWake(t *testing.T) {
+	tests := []struct {
+		name    string
+		since   time.Duration
+		restart bool
+	}{
+		{"first tick", 0, false},
+		{"regular tick", 5 * time.Second, false},
+		{"slow tick", 30 * time.Second, false},
+		{"after sleep", 2 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		m := &home{appConfig: config.DefaultConfig()}
+		if tt.since > 0 {
+			m.lastMetadataTick = time.Now().Add(-tt.since)
+		}
+		m.detectWake()
+		assert.Equal(t, tt.restart, m.restartStopped, tt.name)
+		assert.False(t, m.lastMetadataTick.IsZero(), tt.name)
+	}
+}
+
+func TestInlineKeySequence(t *testing.T) {
+	tests := []struct {
+		key  tea.KeyMsg
+		want string
+	}{
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}, "y"},
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("é"), Alt: true}, "\x1bé"},
+		{tea.KeyMsg{Type: tea.KeyEnter}, "\r"},
+		{tea.KeyMsg{Type: tea.KeyEsc}, "\x1b"},
+		{tea.KeyMsg{Type: tea.KeyCtrlC}, "\x03"},
+		{tea.KeyMsg{Type: tea.KeyBackspace}, "\x7f"},
+		{tea.KeyMsg{Type: tea.KeySpace}, " "},
+		{tea.KeyMsg{Type: tea.KeyUp}, "\x1b[A"},
+		{tea.KeyMsg{Type: tea.KeyShiftTab}, "\x1b[Z"},
+		{tea.KeyMsg{Type: tea.KeyF5}, ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, inlineKeySequence(tt.key), tt.key.String())
+	}
+}
+
+func TestInstanceLimitWarning(t *testing.T) {
+	assert.Empty(t, instanceLimitWarning(49, 1, 50))
+	assert.Contains(t, instanceLimitWarning(50, 1, 50), "51 instances is above the limit of 50")
+	assert.Contains(t, instanceLimitWarning(40, 20, 50), "60 instances")
+	// A limit of 0 never warns
+	assert.Empty(t, instanceLimitWarning(500, 1, 0))
+}
+
+func TestLoadPromptFromFile(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "docs", "tasks"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "node_modules", "pkg"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "docs", "tasks", "login.md"), []byte("Fix the login form\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "node_modules", "pkg", "README.md"), []byte("dependency\n"), 0644))
+	assert.Equal(t, []string{filepath.Join("docs", "tasks", "login.md")}, findPromptFiles(root))
+
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	h := &home{
+		ctx:          context.Background(),
+		state:        statePrompt,
+		appConfig:    config.DefaultConfig(),
+		list:         ui.NewList(&spinner, false),
+		menu:         ui.NewMenu(),
+		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane(), ui.NewFilesPane(), ui.NewChecksPane()),
+		errBox:       ui.NewErrBox(),
+	}
+	h.list.AddInstance(&session.Instance{Title: "login", Path: root})
+	h.textInputOverlay = overlay.NewTextInputOverlay("Enter prompt", "")
+	h.textInputOverlay.FileLoadEnabled = true
+
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlL})
+	require.Equal(t, stateLoadPrompt, h.state)
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("login")})
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+
+	assert.Equal(t, statePrompt, h.state)
+	assert.Nil(t, h.filePickerOverlay)
+	assert.Equal(t, "Fix the login form", h.textInputOverlay.GetValue())
+}
+
+func TestCreateInstanceFromIssue(t *testing.T) {
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	h := &home{
+		ctx:          context.Background(),
+		state:        stateDefault,
+		appConfig:    config.DefaultConfig(),
+		list:         ui.NewList(&spinner, false),
+		menu:         ui.NewMenu(),
+		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane(), ui.NewFilesPane(), ui.NewChecksPane()),
+		errBox:       ui.NewErrBox(),
+	}
+	dir := t.TempDir()
+	h.handleIssuesLoaded(issuesLoadedMsg{dir: dir, issues: []git.Issue{
+		{Number: 3, Title: "Slow startup", URL: "https://github.com/acme/api/issues/3"},
+		{Number: 42, Title: "Fix the login form", Body: "It rejects valid emails.", URL: "https://github.com/acme/api/issues/42"},
+	}})
+	require.Equal(t, stateIssueSelect, h.state)
+
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	require.Equal(t, stateModeSelect, h.state)
+	require.NotNil(t, h.pendingIssue)
+	assert.True(t, h.promptAfterName)
+
+	h.pendingSessionType = session.LocalSessionType()
+	h.createInstanceWithPath(h.pendingInstancePath)
+	require.Equal(t, stateNew, h.state)
+	assert.Nil(t, h.pendingIssue)
+
+	instance := h.list.GetSelectedInstance()
+	require.NotNil(t, instance)
+	assert.Equal(t, "42-fix-the-login-form", instance.Title)
+	assert.Equal(t, dir, instance.Path)
+	require.NotNil(t, instance.Issue)
+	assert.Equal(t, "acme/api#42", instance.Issue.Reference())
+
+	prompt := newInitialPromptOverlay(instance)
+	assert.Contains(t, prompt.GetValue(), "It rejects valid emails.")
+	assert.Empty(t, instance.Prompt)
+}
+
+func TestCreateInstanceFailureGoesBackToList(t *testing.T) {
+	h := newTestHome()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, config.RepoConfigFileName), []byte("session_type: docker-clone\n"), 0644))
+	h.state = stateFileBrowser
+	h.promptAfterName = true
+
+	// The directory has no origin to clone from
+	h.selectRepoDirectory(dir)
+	assert.Equal(t, stateDefault, h.state)
+	assert.False(t, h.promptAfterName)
+	assert.Empty(t, h.pendingInstancePath)
+	assert.Empty(t, h.pendingSessionType)
+	assert.Nil(t, h.pendingRepoConfig)
+	assert.Contains(t, h.errBox.GetMessage(), "failed to get git remote URL")
+	h.View()
+}
+
+func TestKeyHelp(t *testing.T) {
+	for _, entry := range keys.Help {
+		assert.NotEmpty(t, entry.Key(), "key %d in the help has no binding", entry.Name)
+	}
+
+	h := newTestHome(fakeInstances()...)
+	h.appState = &memoryState{}
+	h.termWidth, h.termHeight = 120, 40
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	require.Equal(t, stateKeyHelp, h.state)
+	assert.True(t, h.appState.HelpScreenSeen(config.HelpScreenGeneral))
+
+	// The sessions come first outside the diff tab
+	sections := helpSections(keys.HelpContextList)
+	assert.Equal(t, keys.HelpContextList.String(), sections[0].Title)
+	sections = helpSections(keys.HelpContextDiff)
+	assert.Equal(t, keys.HelpContextDiff.String(), sections[0].Title)
+	assert.Len(t, sections, len(keys.HelpContexts))
+
+	// Searching matches keys and descriptions
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("/")},
+		{Type: tea.KeyRunes, Runes: []rune("detach")},
+	} {
+		h.handleKeyPress(key)
+	}
+	assert.True(t, h.helpOverlay.Searching())
+	assert.Equal(t, []overlay.HelpKey{
+		{Key: "W", Description: "Check out the changes detached in your repo, keep the session running"},
+		{Key: "ctrl+q", Description: "Detach from the session, or stop typing into the preview"},
+	}, h.helpOverlay.Matches())
+	assert.Contains(t, h.helpOverlay.Render(), "Attached")
+	assert.NotContains(t, h.helpOverlay.Render(), "Prompt")
+
+	// Enter keeps the filter, esc clears it and a second esc closes the help
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, h.helpOverlay.Searching())
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Len(t, h.helpOverlay.Matches(), len(keys.Help))
+	h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, stateDefault, h.state)
+	assert.Nil(t, h.helpOverlay)
+}
+
+// TestTombstoneCleanup tests that an instance killed during a previous run is
+// only removed from storage once its cleanup succeeded
+func TestTombstoneCleanup(t *testing.T) {
+	deletedAt := time.Now()
+	killed := &session.Instance{Title: "killed", Status: session.Paused, DeletedAt: &deletedAt}
+	data, err := json.Marshal([]session.InstanceData{killed.ToInstanceData()})
+	require.NoError(t, err)
+	state := &memoryState{instances: data}
+	storage, err := session.NewStorage(state)
+	require.NoError(t, err)
+
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	h := &home{
+		ctx:       context.Background(),
+		state:     stateDefault,
+		appConfig: config.DefaultConfig(),
+		list:      ui.NewList(&spinner, false),
+		menu:      ui.NewMenu(),
+		errBox:    ui.NewErrBox(),
+		storage:   storage,
+	}
+	h.list.AddInstance(killed)()
+	stored := func() []string {
+		var data []session.InstanceData
+		require.NoError(t, json.Unmarshal(state.GetInstances(), &data))
+		var titles []string
+		for _, d := range data {
+			titles = append(titles, d.Title)
+		}
+		return titles
+	}
+
+	h.handleTombstoneCleaned(tombstoneCleanedMsg{instance: killed, err: fmt.Errorf("worktree is busy")})
+	assert.Equal(t, []string{"killed"}, stored(), "a failed cleanup is kept for the next start")
+	assert.Contains(t, h.list.GetInstances(), killed)
+
+	h.handleTombstoneCleaned(tombstoneCleanedMsg{instance: killed})
+	assert.Empty(t, stored())
+	assert.NotContains(t, h.list.GetInstances(), killed)
+}
+
+// TestKillDeletesFromStorageAfterCleanup tests that a killed instance stays in
+// storage until the cleanup run once the undo window has passed succeeded
+func TestKillDeletesFromStorageAfterCleanup(t *testing.T) {
+	instance := &session.Instance{Title: "doomed", Status: session.Paused}
+	data, err := json.Marshal([]session.InstanceData{instance.ToInstanceData()})
+	require.NoError(t, err)
+	state := &memoryState{instances: data}
+	storage, err := session.NewStorage(state)
+	require.NoError(t, err)
+
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	h := &home{
+		ctx:       context.Background(),
+		state:     stateDefault,
+		appConfig: config.DefaultConfig(),
+		list:      ui.NewList(&spinner, false),
+		menu:      ui.NewMenu(),
+		errBox:    ui.NewErrBox(),
+		storage:   storage,
+	}
+	h.list.AddInstance(instance)()
+	stored := func() []session.InstanceData {
+		var data []session.InstanceData
+		require.NoError(t, json.Unmarshal(state.GetInstances(), &data))
+		return data
+	}
+
+	cmd := h.killInstance(killConfirmedMsg{instance: instance})
+	require.NotNil(t, cmd)
+	_, ok := cmd().(undoScheduledMsg)
+	require.True(t, ok)
+	require.Len(t, stored(), 1)
+	assert.NotNil(t, stored()[0].DeletedAt, "the kill is only tombstoned while it can be undone")
+
+	cleanup, err := h.commitPendingUndo()
+	require.NoError(t, err)
+	require.NotNil(t, cleanup)
+	assert.Len(t, stored(), 1, "nothing is deleted before the cleanup ran")
+
+	msg, ok := cleanup().(tombstoneCleanedMsg)
+	require.True(t, ok)
+	assert.Same(t, instance, msg.instance)
+	h.handleTombstoneCleaned(msg)
+	assert.Empty(t, stored())
+	assert.NotContains(t, h.list.GetInstances(), instance)
+}
+
+func TestUploadArtifactsSkipsUnchangedAndInFlight(t *testing.T) {
+	countFile := filepath.Join(t.TempDir(), "uploads")
+	t.Setenv("COUNT_FILE", countFile)
+	cfg := config.DefaultConfig()
+	cfg.ArtifactUploadCommand = `echo "$CS_INSTANCE" >> "$COUNT_FILE"`
+	h := &home{ctx: context.Background(), state: stateDefault, appConfig: cfg}
+	instance := &session.Instance{Title: "login", Summary: "Fixed the redirect"}
+	uploads := func() int {
+		content, _ := os.ReadFile(countFile)
+		return len(content) / len("login\n")
+	}
+	// upload runs the upload of the instance and handles its outcome
+	upload := func(cmd tea.Cmd) {
+		require.NotNil(t, cmd)
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			require.Len(t, batch, 1)
+			msg = batch[0]()
+		}
+		h.handleArtifactUpload(msg.(artifactUploadMsg))
+	}
+
+	first := h.uploadArtifacts([]*session.Instance{instance})
+	assert.Nil(t, h.uploadArtifacts([]*session.Instance{instance}), "no second upload while one runs")
+	upload(first)
+	assert.Equal(t, 1, uploads())
+
+	upload(h.uploadArtifacts([]*session.Instance{instance}))
+	assert.Equal(t, 1, uploads(), "unchanged artifacts are not uploaded again")
+
+	instance.Summary = "Fixed the redirect and added a test"
+	upload(h.uploadArtifacts([]*session.Instance{instance}))
+	assert.Equal(t, 2, uploads())
+}
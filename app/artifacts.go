@@ -0,0 +1,104 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// artifactUploadMsg is sent when the artifacts of an instance have been uploaded.
+// skipped is true if they were unchanged since the last upload.
+type artifactUploadMsg struct {
+	instance *session.Instance
+	title    string
+	hash     uint64
+	skipped  bool
+	err      error
+}
+
+// artifactUpload tracks the uploads of the artifacts of an instance.
+type artifactUpload struct {
+	// inFlight is true while an upload runs
+	inFlight bool
+	// uploaded is the hash of the artifacts last uploaded
+	uploaded uint64
+}
+
+// artifactsHash returns a hash of the content of the artifacts, leaving out
+// when they were collected.
+func artifactsHash(artifacts *session.Artifacts) uint64 {
+	h := fnv.New64a()
+	for _, part := range []string{artifacts.Branch, artifacts.Summary, artifacts.Diff, artifacts.Transcript} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// uploadArtifacts uploads the transcript, diff and summary of the instances
+// that finished working in the background, if an upload destination is
+// configured.
+func (m *home) uploadArtifacts(finished []*session.Instance) tea.Cmd {
+	if len(finished) == 0 {
+		return nil
+	}
+	cfg := m.appConfig
+	if strings.TrimSpace(cfg.ArtifactUploadCommand) == "" && strings.TrimSpace(cfg.ArtifactUploadURL) == "" {
+		return nil
+	}
+
+	if m.artifactUploads == nil {
+		m.artifactUploads = make(map[*session.Instance]*artifactUpload)
+	}
+	now := time.Now()
+	var cmds []tea.Cmd
+	for _, instance := range finished {
+		upload := m.artifactUploads[instance]
+		if upload == nil {
+			upload = &artifactUpload{}
+			m.artifactUploads[instance] = upload
+		}
+		// An instance finishing again while its upload runs is uploaded the next time
+		if upload.inFlight {
+			continue
+		}
+		upload.inFlight = true
+		instance, uploaded := instance, upload.uploaded
+		cmds = append(cmds, func() tea.Msg {
+			artifacts, err := instance.CollectArtifacts(now)
+			if err != nil {
+				return artifactUploadMsg{instance: instance, title: instance.Title, err: err}
+			}
+			hash := artifactsHash(artifacts)
+			if hash == uploaded {
+				return artifactUploadMsg{instance: instance, title: instance.Title, hash: hash, skipped: true}
+			}
+			err = session.UploadArtifacts(cfg, artifacts)
+			return artifactUploadMsg{instance: instance, title: instance.Title, hash: hash, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleArtifactUpload records an upload and reports it if it failed.
+func (m *home) handleArtifactUpload(msg artifactUploadMsg) tea.Cmd {
+	if upload := m.artifactUploads[msg.instance]; upload != nil {
+		upload.inFlight = false
+		if msg.err == nil {
+			upload.uploaded = msg.hash
+		}
+	}
+	if msg.skipped {
+		return nil
+	}
+	if msg.err != nil {
+		return m.handleError(fmt.Errorf("failed to upload artifacts of '%s': %w", msg.title, msg.err))
+	}
+	log.InfoLog.Printf("uploaded artifacts of %s", msg.title)
+	return nil
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"claude-squad/audit"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// showAudit opens the audit log, newest events first.
+func (m *home) showAudit() (tea.Model, tea.Cmd) {
+	events, err := audit.Read()
+	if err != nil {
+		return m, m.handleError(err)
+	}
+	slices.Reverse(events)
+
+	m.auditOverlay = overlay.NewAuditOverlay(events)
+	m.auditOverlay.SetSize(max(m.termWidth*9/10, 60), max(m.termHeight*8/10, 15))
+	m.state = stateAudit
+	return m, nil
+}
+
+// handleAuditState handles key presses while the audit log is shown.
+func (m *home) handleAuditState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	shouldClose := m.auditOverlay.HandleKeyPress(msg)
+
+	if m.auditOverlay.ExportRequested {
+		m.auditOverlay.ExportRequested = false
+		// Export oldest first, in the order of the log
+		events := slices.Clone(m.auditOverlay.Events())
+		slices.Reverse(events)
+		path, err := audit.Export(events)
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		m.auditOverlay.SetStatus(fmt.Sprintf("Exported to %s", path))
+	}
+
+	if shouldClose {
+		m.auditOverlay = nil
+		m.state = stateDefault
+	}
+	return m, nil
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui"
+	"claude-squad/ui/overlay"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toggleAuxView switches the preview between the agent and the auxiliary
+// command of the selected instance.
+func (m *home) toggleAuxView() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || !selected.Started() {
+		return m, nil
+	}
+	if selected.AuxCommand == "" {
+		return m, m.handleError(fmt.Errorf("'%s' has no auxiliary command, press V to set one", selected.Title))
+	}
+	m.tabbedWindow.ToggleAux()
+	return m, m.instanceChanged()
+}
+
+// showAuxCommand asks for the auxiliary command of the selected instance.
+func (m *home) showAuxCommand() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || !selected.Started() {
+		return m, nil
+	}
+	m.state = stateAuxCommand
+	m.menu.SetState(ui.StateRename)
+	m.textInputOverlay = overlay.NewTextInputOverlay("Command to run next to the agent, empty to stop it", selected.AuxCommand)
+	return m, nil
+}
+
+// handleAuxCommandState handles key presses while editing the auxiliary
+// command. Submitting it unchanged restarts the command.
+func (m *home) handleAuxCommandState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.textInputOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+	submitted := m.textInputOverlay.IsSubmitted()
+	command := m.textInputOverlay.GetValue()
+	m.textInputOverlay = nil
+	m.state = stateDefault
+	m.menu.SetState(ui.StateDefault)
+
+	selected := m.list.GetSelectedInstance()
+	if !submitted || selected == nil {
+		return m, tea.WindowSize()
+	}
+	// The instance is changed here rather than in a command, since the rest of
+	// the TUI reads its command and panes meanwhile
+	selected.SetAuxCommand(command)
+	return m, tea.Batch(m.auxCommandSet(selected), tea.WindowSize())
+}
+
+// auxCommandSet reports the outcome of setting the auxiliary command of the
+// instance.
+func (m *home) auxCommandSet(instance *session.Instance) tea.Cmd {
+	if err := instance.AuxError(); err != nil {
+		return tea.Batch(m.handleError(fmt.Errorf("failed to start %s: %w", instance.AuxCommand, err)), m.requestSave())
+	}
+	info := fmt.Sprintf("Stopped the auxiliary command of '%s'", instance.Title)
+	if instance.AuxCommand != "" {
+		info = fmt.Sprintf("Set the auxiliary command of '%s' to %s, press O to see its output", instance.Title, instance.AuxCommand)
+	}
+	return tea.Batch(m.showInfo(info), m.requestSave(), m.instanceChanged())
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"claude-squad/config"
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultStaleBaseCommits is how many commits of the default branch a branch
+// may lack before the preview warns, when stale_base_commits is not configured.
+const defaultStaleBaseCommits = 50
+
+// baseMergedMsg is sent when the default branch was merged into an instance's
+// branch.
+type baseMergedMsg struct {
+	instance *session.Instance
+	err      error
+}
+
+// staleBaseCommits returns how many commits of the default branch a branch may
+// lack before the preview warns, 0 if it never warns.
+func staleBaseCommits(cfg *config.Config) int {
+	switch commits := cfg.StaleBaseCommits; {
+	case commits < 0:
+		return 0
+	case commits == 0:
+		return defaultStaleBaseCommits
+	default:
+		return commits
+	}
+}
+
+// mergeDefaultBranch asks to merge the default branch into the selected
+// instance's branch, so that its agent works against current code.
+func (m *home) mergeDefaultBranch() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || selected.Scratch || !selected.Started() {
+		return m, nil
+	}
+	if selected.Paused() {
+		return m, m.handleError(fmt.Errorf("resume '%s' before merging the default branch into it", selected.Title))
+	}
+
+	target := "the default branch"
+	if branch, err := selected.DefaultBranch(); err == nil {
+		target = branch
+	}
+	message := fmt.Sprintf("[!] Merge %s into the branch of '%s'?", target, selected.Title)
+	if base := selected.BaseStatus(); base != nil && base.Behind > 0 {
+		message = fmt.Sprintf("[!] Merge %d commits of %s into the branch of '%s'?", base.Behind, target, selected.Title)
+	}
+	return m, m.confirmAction(message, func() tea.Msg {
+		return baseMergedMsg{instance: selected, err: selected.MergeDefaultBranch()}
+	})
+}
+
+// handleBaseMerged reports the outcome of merging the default branch.
+func (m *home) handleBaseMerged(msg baseMergedMsg) tea.Cmd {
+	if errors.Is(msg.err, git.ErrDirtyWorktree) {
+		return m.handleError(fmt.Errorf("'%s' has uncommitted changes, commit them before merging the default branch", msg.instance.Title))
+	}
+	if msg.err != nil {
+		return m.handleError(fmt.Errorf("failed to merge the default branch into '%s': %s", msg.instance.Title, firstErrorLine(msg.err.Error())))
+	}
+	return tea.Batch(m.showInfo(fmt.Sprintf("Merged the default branch into '%s'", msg.instance.Title)), m.instanceChanged())
+}
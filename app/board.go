@@ -0,0 +1,42 @@
+package app
+
+import (
+	"claude-squad/ui/overlay"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// showBoard opens the board view of the squad.
+func (m *home) showBoard() (tea.Model, tea.Cmd) {
+	m.boardOverlay = overlay.NewBoardOverlay(m.list.GetInstances())
+	m.boardOverlay.SetSize(max(m.termWidth*9/10, 60), max(m.termHeight*8/10, 15))
+	m.state = stateBoard
+	return m, nil
+}
+
+// handleBoardState handles key presses on the board. Moved cards are saved, and
+// the card picked with enter is selected in the list if it is shown there.
+func (m *home) handleBoardState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.boardOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+
+	boardOverlay := m.boardOverlay
+	m.boardOverlay = nil
+	m.state = stateDefault
+
+	var cmds []tea.Cmd
+	if boardOverlay.Moved {
+		cmds = append(cmds, m.requestSave())
+	}
+	if selected := boardOverlay.Selected; selected != nil {
+		for i, instance := range m.list.GetVisibleInstances() {
+			if instance == selected {
+				m.list.SetSelectedInstance(i)
+				break
+			}
+		}
+	}
+	cmds = append(cmds, m.instanceChanged())
+	return m, tea.Batch(cmds...)
+}
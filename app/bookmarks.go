@@ -0,0 +1,59 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"os"
+	"sort"
+)
+
+// fileBrowserStartPath returns the directory the file browser opens in: the one
+// picked last time if it still exists, otherwise the working directory.
+func (m *home) fileBrowserStartPath() string {
+	if path := m.appState.GetLastRepoPath(); path != "" {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path
+		}
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "~"
+	}
+	return cwd
+}
+
+// setFileBrowserShortcuts shows the bookmarks and the repos of earlier instances
+// in the file browser, and saves the bookmarks when they change.
+func (m *home) setFileBrowserShortcuts(fb *overlay.FileBrowserOverlay) {
+	fb.SetShortcuts(m.appState.GetBookmarks(), recentRepos(m.list.GetInstances()))
+	fb.OnBookmarksChanged = func(bookmarks []string) {
+		if err := m.appState.SetBookmarks(bookmarks); err != nil {
+			log.WarningLog.Printf("Failed to save bookmarks: %v", err)
+		}
+	}
+}
+
+// recentRepos returns the repos instances were created in, those of the newest
+// instances first. Scratch instances don't run in a repo and are left out.
+func recentRepos(instances []*session.Instance) []string {
+	sorted := make([]*session.Instance, 0, len(instances))
+	for _, instance := range instances {
+		if !instance.Scratch && instance.Path != "" {
+			sorted = append(sorted, instance)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	var repos []string
+	seen := make(map[string]bool)
+	for _, instance := range sorted {
+		if !seen[instance.Path] {
+			seen[instance.Path] = true
+			repos = append(repos, instance.Path)
+		}
+	}
+	return repos
+}
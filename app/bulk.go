@@ -0,0 +1,140 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pauseAllMsg is sent when pausing all instances has been confirmed.
+type pauseAllMsg struct{}
+
+// bulkFinishedMsg is sent when a bulk operation has finished for one instance.
+type bulkFinishedMsg struct {
+	instance *session.Instance
+	err      error
+}
+
+// confirmPauseAll asks before pausing every running instance.
+func (m *home) confirmPauseAll() (tea.Model, tea.Cmd) {
+	running := m.bulkPausable()
+	if len(running) == 0 {
+		return m, m.showInfo("No running sessions to pause")
+	}
+	message := fmt.Sprintf("[!] Pause %d running sessions? Their changes are committed and worktrees removed.", len(running))
+	return m, m.confirmAction(message, func() tea.Msg { return pauseAllMsg{} })
+}
+
+// bulkPausable returns the instances pause all pauses: the started instances
+// with a worktree that aren't paused yet.
+func (m *home) bulkPausable() []*session.Instance {
+	var instances []*session.Instance
+	for _, instance := range m.list.GetInstances() {
+		if instance.Started() && !instance.Paused() && !instance.Scratch && !instance.Archived && !instance.Tombstoned() {
+			instances = append(instances, instance)
+		}
+	}
+	return instances
+}
+
+// pauseAll pauses every running instance in the background.
+func (m *home) pauseAll() tea.Cmd {
+	return m.runBulk("Pausing", m.bulkPausable(), (*session.Instance).Pause)
+}
+
+// resumeAll resumes every paused instance that isn't archived in the background.
+func (m *home) resumeAll() (tea.Model, tea.Cmd) {
+	var paused []*session.Instance
+	for _, instance := range m.list.GetInstances() {
+		if instance.Paused() && !instance.Archived && !instance.Tombstoned() {
+			paused = append(paused, instance)
+		}
+	}
+	if len(paused) == 0 {
+		return m, m.showInfo("No paused sessions to resume")
+	}
+	return m, m.runBulk("Resuming", paused, (*session.Instance).Resume)
+}
+
+// runBulk runs the operation, described by action like "Pausing", on the
+// instances and shows the progress. Instances are handled one at a time, since
+// the operations change the worktrees of shared repositories.
+func (m *home) runBulk(action string, instances []*session.Instance, operation func(*session.Instance) error) tea.Cmd {
+	if len(instances) == 0 {
+		return nil
+	}
+	if m.bulkOverlay != nil && !m.bulkOverlay.Done() {
+		return m.showInfo("Sessions are still being paused or resumed, try again when they are done")
+	}
+	m.bulkOverlay = overlay.NewBulkOverlay(action, len(instances))
+	m.bulkOverlay.SetWidth(max(m.termWidth*6/10, 60))
+	if m.state == stateDefault {
+		m.state = stateBulk
+	}
+
+	log.InfoLog.Printf("%s %d sessions", action, len(instances))
+	slot := make(chan struct{}, 1)
+	cmds := make([]tea.Cmd, 0, len(instances))
+	for _, instance := range instances {
+		cmds = append(cmds, func() tea.Msg {
+			slot <- struct{}{}
+			defer func() { <-slot }()
+			return bulkFinishedMsg{instance: instance, err: operation(instance)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleBulkFinished records the progress of a bulk operation. Once done, the
+// results stay in the overlay until it is closed, or are summarized if it was
+// closed already.
+func (m *home) handleBulkFinished(msg bulkFinishedMsg) tea.Cmd {
+	if msg.err != nil {
+		log.ErrorLog.Printf("%s: %v", msg.instance.Title, msg.err)
+	}
+	cmds := []tea.Cmd{m.requestSave(), m.instanceChanged()}
+	if m.bulkOverlay == nil {
+		return tea.Batch(cmds...)
+	}
+	m.bulkOverlay.Finished(msg.instance.Title, msg.err)
+	if !m.bulkOverlay.Done() {
+		return tea.Batch(cmds...)
+	}
+
+	// Resumed sessions need the size of the preview
+	cmds = append(cmds, tea.WindowSize())
+	if m.state != stateBulk {
+		if failed := m.bulkOverlay.Failed(); failed > 0 {
+			cmds = append(cmds, m.handleError(fmt.Errorf("%d session(s) failed, see the log for why", failed)))
+		} else {
+			cmds = append(cmds, m.showInfo("All sessions done"))
+		}
+		m.bulkOverlay = nil
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleBulkState handles key presses while the bulk overlay is shown. Closing
+// it while the operation is running continues in the background.
+func (m *home) handleBulkState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.bulkOverlay.HandleKeyPress(msg) {
+		if m.bulkOverlay.Done() {
+			m.bulkOverlay = nil
+		}
+		m.state = stateDefault
+	}
+	return m, nil
+}
+
+// showBulkProgress shows the overlay of a bulk operation that is still running,
+// and reports whether there is one.
+func (m *home) showBulkProgress() bool {
+	if m.bulkOverlay == nil || m.bulkOverlay.Done() {
+		return false
+	}
+	m.state = stateBulk
+	return true
+}
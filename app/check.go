@@ -0,0 +1,52 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/ui"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// checkResultMsg is sent when the check command has finished for an instance.
+type checkResultMsg struct {
+	instance *session.Instance
+	result   *session.CheckResult
+}
+
+// runCheck runs the configured check command in the selected instance's
+// worktree in the background.
+func (m *home) runCheck() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+	command := m.appConfig.CheckCommand
+	if strings.TrimSpace(command) == "" {
+		return m, m.handleError(fmt.Errorf("no check command configured, set check_command in the config"))
+	}
+	if err := selected.StartCheck(); err != nil {
+		return m, m.handleError(err)
+	}
+
+	log.InfoLog.Printf("running check %q for %s", command, selected.Title)
+	run := func() tea.Msg {
+		return checkResultMsg{instance: selected, result: selected.RunCheck(command)}
+	}
+	return m, tea.Batch(run, m.showInfo(fmt.Sprintf("Running %s for '%s'", command, selected.Title)), m.instanceChanged())
+}
+
+// handleCheckResult records the result of a check and reports it.
+func (m *home) handleCheckResult(msg checkResultMsg) tea.Cmd {
+	msg.instance.FinishCheck(msg.result)
+	duration := ui.FormatCheckDuration(msg.result.Duration)
+	var reportCmd tea.Cmd
+	if msg.result.Passed {
+		reportCmd = m.showInfo(fmt.Sprintf("Check passed for '%s' in %s", msg.instance.Title, duration))
+	} else {
+		reportCmd = m.handleError(fmt.Errorf("check failed for '%s' after %s, see the Checks tab", msg.instance.Title, duration))
+	}
+	return tea.Batch(reportCmd, m.requestSave(), m.instanceChanged())
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"claude-squad/session/git"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openFailedCI opens the failed CI run of the selected instance's branch in the
+// browser.
+func (m *home) openFailedCI() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+	status := selected.CIStatus()
+	if status == nil || status.State != git.CIFailed || status.FailedURL == "" {
+		return m, m.showInfo(fmt.Sprintf("No failed CI run for '%s'", selected.Title))
+	}
+	target := status.FailedURL
+	return m, func() tea.Msg {
+		if err := git.OpenURL(target); err != nil {
+			return fmt.Errorf("failed to open %s: %w", target, err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"claude-squad/ui"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Choices of what the diff tab compares the worktree with, in the order shown
+const (
+	diffTargetBase = iota
+	diffTargetDefaultBranch
+	diffTargetLastCommit
+	diffTargetRef
+)
+
+var diffTargetNames = []string{
+	diffTargetBase:          "Base commit",
+	diffTargetDefaultBranch: "Tip of the default branch",
+	diffTargetLastCommit:    "Last commit (uncommitted changes only)",
+	diffTargetRef:           "Other ref...",
+}
+
+// showDiffTargets asks what the diff of the selected instance should be
+// computed against. It only applies in the diff tab.
+func (m *home) showDiffTargets() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if !m.tabbedWindow.IsInDiffTab() || selected == nil || !selected.Started() || selected.Scratch {
+		return m, nil
+	}
+	m.selectionOverlay = overlay.NewSelectionOverlay(fmt.Sprintf("Diff '%s' against", selected.Title), diffTargetNames)
+	m.state = stateDiffTarget
+	return m, nil
+}
+
+// handleDiffTargetState handles key presses while choosing what to diff against.
+func (m *home) handleDiffTargetState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.selectionOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+	idx := m.selectionOverlay.GetSelectedIndex()
+	m.selectionOverlay = nil
+	m.state = stateDefault
+
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+	switch idx {
+	case diffTargetBase:
+		return m, m.setDiffRef("")
+	case diffTargetDefaultBranch:
+		branch, err := selected.DefaultBranch()
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		return m, m.setDiffRef(branch)
+	case diffTargetLastCommit:
+		return m, m.setDiffRef("HEAD")
+	case diffTargetRef:
+		m.state = stateDiffRef
+		m.menu.SetState(ui.StateRename)
+		m.textInputOverlay = overlay.NewTextInputOverlay("Diff against branch, tag or commit", selected.DiffRef())
+		return m, nil
+	}
+	return m, nil
+}
+
+// handleDiffRefState handles key presses while entering the ref to diff against.
+func (m *home) handleDiffRefState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.textInputOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+	submitted := m.textInputOverlay.IsSubmitted()
+	ref := strings.TrimSpace(m.textInputOverlay.GetValue())
+	m.textInputOverlay = nil
+	m.state = stateDefault
+	m.menu.SetState(ui.StateDefault)
+
+	var cmd tea.Cmd
+	if submitted {
+		cmd = m.setDiffRef(ref)
+	}
+	return m, tea.Batch(cmd, tea.WindowSize())
+}
+
+// setDiffRef diffs the selected instance against the ref, or its base commit
+// if the ref is empty.
+func (m *home) setDiffRef(ref string) tea.Cmd {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return nil
+	}
+	if err := selected.SetDiffRef(ref); err != nil {
+		return m.handleError(err)
+	}
+	if ref == "" {
+		ref = "the base commit"
+	}
+	return tea.Batch(m.showInfo(fmt.Sprintf("Diffing '%s' against %s", selected.Title, ref)), m.instanceChanged())
+}
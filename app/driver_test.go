@@ -0,0 +1,491 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/testing/harness"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The driver runs the home model like the Bubble Tea runtime does, but from the
+// test: it feeds key presses and window sizes to Update, runs the commands it
+// returns in the background and feeds their messages back in. Sessions run in
+// fake multiplexers, git in a throwaway repository and storage in memory, so
+// whole flows can be tested without zellij or the user's config.
+
+// driverTimeout bounds how long the driver waits for a condition.
+const driverTimeout = 10 * time.Second
+
+// driverSettleTime is how long no message has to arrive for the model to count
+// as idle. Timers like the one clearing a notice take longer and are left running.
+const driverSettleTime = 50 * time.Millisecond
+
+var (
+	// windowSizeQuery and sequence are the types of the messages of
+	// tea.WindowSize and tea.Sequence, which the runtime handles itself.
+	windowSizeQuery = reflect.TypeOf(tea.WindowSize()())
+	sequence        = reflect.TypeOf(tea.Sequence()())
+)
+
+// driver runs a home model in a test.
+type driver struct {
+	t *testing.T
+	*harness.Harness
+	h *home
+	// repo is the repository new instances are created in
+	repo  string
+	state *memoryState
+
+	ctx  context.Context
+	msgs chan tea.Msg
+	// sessions are the fake sessions given to the instances
+	sessions map[*session.Instance]*fakeMultiplexer
+}
+
+// newDriver returns a driver running an empty home in a terminal of width x
+// height, with HOME pointed at a temporary directory.
+func newDriver(t *testing.T, width, height int) *driver {
+	t.Helper()
+	repo := newTestRepo(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	// Timers still running return right away once the context is done
+	t.Cleanup(cancel)
+
+	state := &memoryState{lastRepoPath: repo}
+	storage, err := session.NewStorage(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newTestHome()
+	h.ctx = ctx
+	h.program = "claude"
+	h.storage = storage
+	h.appState = state
+
+	d := &driver{
+		t:        t,
+		h:        h,
+		repo:     repo,
+		state:    state,
+		ctx:      ctx,
+		msgs:     make(chan tea.Msg, 64),
+		sessions: make(map[*session.Instance]*fakeMultiplexer),
+	}
+	d.Harness = harness.New(t, h, width, height)
+	return d
+}
+
+// newTestRepo creates a repository with one commit. HOME is pointed at a
+// temporary directory so worktrees and config stay out of the user's.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, output)
+		}
+	}
+	return repo
+}
+
+// SendMsg feeds the message to the model and runs the command it returns.
+func (d *driver) SendMsg(msg tea.Msg) tea.Cmd {
+	d.t.Helper()
+	d.attachSessions()
+	cmd := d.Harness.SendMsg(msg)
+	d.run(cmd)
+	return cmd
+}
+
+// Type presses a key for each rune of the text, like typing it.
+func (d *driver) Type(text string) {
+	d.t.Helper()
+	for _, r := range text {
+		if r == ' ' {
+			d.SendMsg(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{r}})
+			continue
+		}
+		d.SendMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	d.Settle()
+}
+
+// Press presses the key, which is either a rune, possibly held with alt like
+// alt+y, or a special key like enter, and waits for the model to settle.
+func (d *driver) Press(key string) {
+	d.t.Helper()
+	keyTypes := map[string]tea.KeyType{
+		"enter":     tea.KeyEnter,
+		"esc":       tea.KeyEsc,
+		"tab":       tea.KeyTab,
+		"up":        tea.KeyUp,
+		"down":      tea.KeyDown,
+		"left":      tea.KeyLeft,
+		"right":     tea.KeyRight,
+		"backspace": tea.KeyBackspace,
+		"ctrl+c":    tea.KeyCtrlC,
+	}
+	if keyType, ok := keyTypes[key]; ok {
+		d.SendMsg(tea.KeyMsg{Type: keyType})
+	} else if r, ok := strings.CutPrefix(key, "alt+"); ok {
+		d.SendMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(r), Alt: true})
+	} else {
+		d.SendMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+	d.Settle()
+}
+
+// run runs the command in the background like the runtime does, delivering
+// its messages to the driver.
+func (d *driver) run(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	go func() {
+		d.deliver(cmd())
+	}()
+}
+
+// deliver passes the message of a command to the driver, running batches and
+// sequences of commands.
+func (d *driver) deliver(msg tea.Msg) {
+	switch {
+	case msg == nil:
+		return
+	case reflect.TypeOf(msg) == sequence:
+		for _, cmd := range reflect.ValueOf(msg).Convert(reflect.TypeOf([]tea.Cmd(nil))).Interface().([]tea.Cmd) {
+			if cmd != nil {
+				d.deliver(cmd())
+			}
+		}
+		return
+	case reflect.TypeOf(msg) == windowSizeQuery:
+		msg = tea.WindowSizeMsg{Width: d.Width(), Height: d.Height()}
+	}
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, cmd := range batch {
+			d.run(cmd)
+		}
+		return
+	}
+	select {
+	case d.msgs <- msg:
+	case <-d.ctx.Done():
+	}
+}
+
+// Settle feeds the messages of finished commands to the model until none
+// arrive for a while.
+func (d *driver) Settle() {
+	d.t.Helper()
+	for {
+		select {
+		case msg := <-d.msgs:
+			d.SendMsg(msg)
+		case <-time.After(driverSettleTime):
+			return
+		}
+	}
+}
+
+// WaitFor feeds messages to the model until the condition holds, failing the
+// test if it doesn't within driverTimeout.
+func (d *driver) WaitFor(what string, condition func() bool) {
+	d.t.Helper()
+	deadline := time.After(driverTimeout)
+	for !condition() {
+		select {
+		case msg := <-d.msgs:
+			d.SendMsg(msg)
+		case <-time.After(driverSettleTime):
+		case <-deadline:
+			d.t.Fatalf("timed out waiting for %s, the screen shows:\n%s", what, d.View())
+		}
+	}
+}
+
+// attachSessions gives instances that haven't been started a fake session, so
+// starting them doesn't run a multiplexer.
+func (d *driver) attachSessions() {
+	if d.h.list == nil {
+		return
+	}
+	for _, instance := range d.h.list.GetInstances() {
+		if _, ok := d.sessions[instance]; ok || instance.Started() {
+			continue
+		}
+		fake := &fakeMultiplexer{program: instance.Program}
+		instance.SetSession(fake)
+		d.sessions[instance] = fake
+	}
+}
+
+// Session returns the fake session of the instance with the title.
+func (d *driver) Session(title string) *fakeMultiplexer {
+	d.t.Helper()
+	for instance, fake := range d.sessions {
+		if instance.Title == title {
+			return fake
+		}
+	}
+	d.t.Fatalf("no session for %q", title)
+	return nil
+}
+
+// Instance returns the instance with the title.
+func (d *driver) Instance(title string) *session.Instance {
+	d.t.Helper()
+	for _, instance := range d.h.list.GetInstances() {
+		if instance.Title == title {
+			return instance
+		}
+	}
+	d.t.Fatalf("no instance %q", title)
+	return nil
+}
+
+// Stored returns the instances as last saved to storage.
+func (d *driver) Stored() []session.InstanceData {
+	d.t.Helper()
+	raw := d.state.GetInstances()
+	if len(raw) == 0 {
+		return nil
+	}
+	var data []session.InstanceData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		d.t.Fatalf("storage holds invalid instances: %v", err)
+	}
+	return data
+}
+
+// StoredInstance returns the stored data of the instance with the title, or
+// nil if it isn't stored.
+func (d *driver) StoredInstance(title string) *session.InstanceData {
+	d.t.Helper()
+	for _, data := range d.Stored() {
+		if data.Title == title {
+			return &data
+		}
+	}
+	return nil
+}
+
+// memoryState keeps the instances and the app state in memory.
+type memoryState struct {
+	mu           sync.Mutex
+	instances    json.RawMessage
+	helpSeen     []string
+	bookmarks    []string
+	lastRepoPath string
+}
+
+func (s *memoryState) SaveInstances(instancesJSON json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.instances = instancesJSON
+	return nil
+}
+
+func (s *memoryState) GetInstances() json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.instances
+}
+
+func (s *memoryState) DeleteAllInstances() error {
+	return s.SaveInstances(json.RawMessage("[]"))
+}
+
+func (s *memoryState) LoadError() error {
+	return nil
+}
+
+func (s *memoryState) HelpScreenSeen(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Contains(s.helpSeen, name)
+}
+
+func (s *memoryState) SetHelpScreenSeen(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.helpSeen, name) {
+		s.helpSeen = append(s.helpSeen, name)
+	}
+	return nil
+}
+
+func (s *memoryState) GetBookmarks() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bookmarks
+}
+
+func (s *memoryState) SetBookmarks(bookmarks []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bookmarks = bookmarks
+	return nil
+}
+
+func (s *memoryState) GetLastRepoPath() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastRepoPath
+}
+
+func (s *memoryState) SetLastRepoPath(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRepoPath = path
+	return nil
+}
+
+// fakeMultiplexer is a session that shows what was typed into it.
+type fakeMultiplexer struct {
+	mu      sync.Mutex
+	program string
+	workDir string
+	started bool
+	closed  bool
+	screen  strings.Builder
+	// sent are the keys sent to the session, and enters how often enter was tapped
+	sent   []string
+	enters int
+}
+
+var _ session.Multiplexer = (*fakeMultiplexer)(nil)
+
+func (f *fakeMultiplexer) Start(workDir string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.workDir = workDir
+	f.started = true
+	return nil
+}
+
+func (f *fakeMultiplexer) Restore() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started = true
+	return nil
+}
+
+func (f *fakeMultiplexer) Attach() (chan struct{}, error) {
+	detached := make(chan struct{})
+	close(detached)
+	return detached, nil
+}
+
+func (f *fakeMultiplexer) Detach() {}
+
+func (f *fakeMultiplexer) DetachSafely() error { return nil }
+
+func (f *fakeMultiplexer) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeMultiplexer) SendKeys(keys string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, keys)
+	f.screen.WriteString(keys)
+	return nil
+}
+
+func (f *fakeMultiplexer) TapEnter() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.enters++
+	f.screen.WriteString("\n")
+	return nil
+}
+
+func (f *fakeMultiplexer) TapDAndEnter() error {
+	if err := f.SendKeys("D"); err != nil {
+		return err
+	}
+	return f.TapEnter()
+}
+
+func (f *fakeMultiplexer) CapturePaneContent() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.screen.String(), nil
+}
+
+func (f *fakeMultiplexer) CapturePaneContentWithOptions(start, end string) (string, error) {
+	return f.CapturePaneContent()
+}
+
+func (f *fakeMultiplexer) CaptureHistoryTail(maxLines, maxBytes int) (string, bool, error) {
+	content, err := f.CapturePaneContent()
+	return content, false, err
+}
+
+func (f *fakeMultiplexer) LoadScreen(screen string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.screen.Reset()
+	f.screen.WriteString(screen)
+}
+
+func (f *fakeMultiplexer) HasUpdated() (bool, bool) { return false, false }
+
+func (f *fakeMultiplexer) DoesSessionExist() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.started && !f.closed
+}
+
+func (f *fakeMultiplexer) SetDetachedSize(width, height int) error { return nil }
+
+func (f *fakeMultiplexer) GetProgram() string { return f.program }
+
+func (f *fakeMultiplexer) IsProgramRunning() (bool, error) { return true, nil }
+
+func (f *fakeMultiplexer) RestartProgram(args string) error { return nil }
+
+func (f *fakeMultiplexer) StartPane(name, command, workDir string) error { return nil }
+
+func (f *fakeMultiplexer) CapturePane(name string) (string, error) { return "", nil }
+
+func (f *fakeMultiplexer) PaneRunning(name string) bool { return false }
+
+func (f *fakeMultiplexer) ClosePane(name string) error { return nil }
+
+// Sent returns the keys sent to the session.
+func (f *fakeMultiplexer) Sent() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.sent...)
+}
+
+// Enters returns how often enter was tapped in the session.
+func (f *fakeMultiplexer) Enters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.enters
+}
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"claude-squad/session"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestE2ECreatePromptArchive(t *testing.T) {
+	d := newDriver(t, 120, 40)
+
+	// New instance with a prompt: pick the repository and the mode, then name it
+	d.Press("N")
+	require.Equal(t, stateFileBrowser, d.h.state)
+	d.Press("enter")
+	require.Equal(t, stateModeSelect, d.h.state)
+	d.Press("enter")
+	require.Equal(t, stateNew, d.h.state)
+	d.Type("fix-login")
+	d.Press("enter")
+	d.WaitFor("the instance to start", func() bool { return d.h.state == statePrompt })
+
+	instance := d.Instance("fix-login")
+	require.True(t, instance.Started())
+	worktree, err := instance.GetGitWorktree()
+	require.NoError(t, err)
+	assert.DirExists(t, worktree.GetWorktreePath())
+	assert.Equal(t, worktree.GetWorktreePath(), d.Session("fix-login").workDir)
+	require.NotNil(t, d.StoredInstance("fix-login"), "the new instance is saved")
+
+	// The prompt is typed into the session
+	d.Type("fix the login form")
+	d.Press("tab") // to the submit button
+	d.Press("enter")
+	d.WaitFor("the prompt to be submitted", func() bool {
+		return d.Session("fix-login").Enters() > 0
+	})
+	assert.Equal(t, []string{"fix the login form"}, d.Session("fix-login").Sent())
+	d.Press("esc") // the help screen shown after the first start
+	require.Equal(t, stateDefault, d.h.state)
+
+	// Archiving waits for the undo window before pausing the instance
+	d.Press("A")
+	require.Equal(t, stateConfirm, d.h.state)
+	d.Press("y")
+	require.NotNil(t, d.h.pendingUndo)
+	assert.False(t, d.StoredInstance("fix-login").Archived)
+
+	d.SendMsg(undoExpiredMsg{id: d.h.pendingUndo.id})
+	d.Settle()
+	stored := d.StoredInstance("fix-login")
+	require.NotNil(t, stored)
+	assert.True(t, stored.Archived)
+	assert.True(t, instance.Paused())
+	_, err = os.Stat(worktree.GetWorktreePath())
+	assert.True(t, os.IsNotExist(err), "pausing removes the worktree")
+}
+
+func TestE2EScratchCreateRestoreKill(t *testing.T) {
+	d := newDriver(t, 120, 40)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep me"), 0644))
+	require.NoError(t, d.state.SetLastRepoPath(dir))
+
+	// A scratch session runs in the directory itself, without a branch or worktree
+	d.Press("n")
+	require.Equal(t, stateFileBrowser, d.h.state)
+	d.Press("s")
+	require.Equal(t, stateNew, d.h.state)
+	d.Type("scratchpad")
+	d.Press("enter")
+	d.WaitFor("the instance to start", func() bool { return d.h.state == stateHelp })
+	d.Press("esc") // the help screen shown after the first start
+
+	instance := d.Instance("scratchpad")
+	require.True(t, instance.Started())
+	assert.True(t, instance.Scratch)
+	assert.Empty(t, instance.Branch)
+	worktree, err := instance.GetGitWorktree()
+	require.NoError(t, err)
+	assert.Nil(t, worktree)
+	assert.Equal(t, dir, d.Session("scratchpad").workDir)
+	stored := d.StoredInstance("scratchpad")
+	require.NotNil(t, stored)
+	assert.True(t, stored.Scratch)
+	assert.Empty(t, stored.Worktree.WorktreePath)
+
+	// Restoring it on the next start doesn't look for a worktree either
+	restored, err := session.FromInstanceDataDeferred(*stored)
+	require.NoError(t, err)
+	restored.SetSession(&fakeMultiplexer{program: restored.Program})
+	require.NoError(t, restored.Restore())
+	worktree, err = restored.GetGitWorktree()
+	require.NoError(t, err)
+	assert.Nil(t, worktree)
+
+	// Killing it removes the session but leaves the directory alone
+	d.Press("D")
+	require.Equal(t, stateConfirm, d.h.state)
+	d.Press("y")
+	require.NotNil(t, d.h.pendingUndo)
+	d.SendMsg(undoExpiredMsg{id: d.h.pendingUndo.id})
+	d.WaitFor("the instance to be deleted", func() bool { return d.StoredInstance("scratchpad") == nil })
+	assert.True(t, d.Session("scratchpad").closed)
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+}
+
+func TestE2ERebaseCommitsOnlyOnSubmit(t *testing.T) {
+	d := newDriver(t, 120, 40)
+
+	d.Press("n")
+	d.Press("enter")
+	d.Press("enter")
+	d.Type("tidy-up")
+	d.Press("enter")
+	d.WaitFor("the instance to start", func() bool { return d.h.state == stateHelp })
+	d.Press("esc")
+
+	worktree, err := d.Instance("tidy-up").GetGitWorktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(worktree.GetWorktreePath(), "notes.txt"), []byte("wip"), 0644))
+
+	// Opening and canceling the assistant leaves the changes uncommitted
+	d.Press("b")
+	d.WaitFor("the rebase assistant", func() bool { return d.h.state == stateRebase })
+	assert.Contains(t, d.View(), uncommittedSubject)
+	d.Press("esc")
+	dirty, err := worktree.IsDirty()
+	require.NoError(t, err)
+	assert.True(t, dirty, "nothing is committed until the rebase is submitted")
+
+	// Submitting commits them before rebasing
+	d.Press("b")
+	d.WaitFor("the rebase assistant", func() bool { return d.h.state == stateRebase })
+	d.Press("enter")
+	d.WaitFor("the changes to be committed", func() bool {
+		dirty, err := worktree.IsDirty()
+		return err == nil && !dirty
+	})
+	commits, err := worktree.BranchCommits()
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	assert.Contains(t, commits[0].Subject, "update from 'tidy-up'")
+}
+
+func TestE2EPromptResponseKeys(t *testing.T) {
+	d := newDriver(t, 120, 40)
+	require.NoError(t, d.state.SetLastRepoPath(t.TempDir()))
+	d.Press("n")
+	d.Press("s")
+	d.Type("asker")
+	d.Press("enter")
+	d.WaitFor("the instance to start", func() bool { return d.h.state == stateHelp })
+	d.Press("esc") // the help screen shown after the first start
+
+	instance := d.Instance("asker")
+	instance.SetPendingPrompt("Allow edit of main.go?")
+
+	// The keys of the list keep their meaning while a prompt is pending
+	d.Press("n")
+	require.Equal(t, stateFileBrowser, d.h.state)
+	d.Press("esc")
+	require.Equal(t, stateDefault, d.h.state)
+	assert.Equal(t, "Allow edit of main.go?", instance.PendingPrompt())
+
+	d.Press("alt+n")
+	assert.Empty(t, instance.PendingPrompt())
+	assert.Equal(t, []string{"\x1b"}, d.Session("asker").Sent())
+}
+
+func TestE2EAuxCommandSetOnUIThread(t *testing.T) {
+	d := newDriver(t, 120, 40)
+	require.NoError(t, d.state.SetLastRepoPath(t.TempDir()))
+	d.Press("n")
+	d.Press("s")
+	d.Type("server")
+	d.Press("enter")
+	d.WaitFor("the instance to start", func() bool { return d.h.state == stateHelp })
+	d.Press("esc")
+
+	d.Press("V")
+	require.Equal(t, stateAuxCommand, d.h.state)
+	d.Type("npm run dev")
+	d.Press("tab")
+	d.Press("enter")
+	// The command is set by the key press itself, not by a command running later
+	assert.Equal(t, "npm run dev", d.Instance("server").AuxCommand)
+	d.WaitFor("the command to be saved", func() bool {
+		stored := d.StoredInstance("server")
+		return stored != nil && stored.AuxCommand == "npm run dev"
+	})
+}
@@ -0,0 +1,193 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
+)
+
+// editorApply applies the text saved in the external editor. It runs in
+// Update, so it may change the model.
+type editorApply func(m *home, text string) tea.Cmd
+
+// editorRequestMsg asks Update to open the text in the external editor. It
+// lets callbacks that can't return commands, like the confirmation overlay's,
+// open the editor.
+type editorRequestMsg struct {
+	text    string
+	pattern string
+	apply   editorApply
+}
+
+// editorResultMsg is sent when the external editor exits.
+type editorResultMsg struct {
+	text  string
+	apply editorApply
+	err   error
+}
+
+// editorCommand returns the command line of the user's editor: $VISUAL, then
+// $EDITOR, then a default for the platform.
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// editExternally suspends the TUI and opens the text in the external editor,
+// in a temporary file named after pattern (see os.CreateTemp). The saved text
+// is passed to apply; nothing is applied if the editor fails.
+func (m *home) editExternally(text, pattern string, apply editorApply) tea.Cmd {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return m.handleError(fmt.Errorf("failed to create file for the editor: %w", err))
+	}
+	path := file.Name()
+	_, err = file.WriteString(text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return m.handleError(fmt.Errorf("failed to write file for the editor: %w", err))
+	}
+
+	editor := editorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	return tea.Exec(&editorProcess{Cmd: cmd}, func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return editorResultMsg{err: fmt.Errorf("editor %s failed: %w", editor[0], err)}
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return editorResultMsg{err: fmt.Errorf("failed to read file from the editor: %w", err)}
+		}
+		return editorResultMsg{text: string(content), apply: apply}
+	})
+}
+
+// handleEditorResult applies the text saved in the external editor.
+func (m *home) handleEditorResult(msg editorResultMsg) tea.Cmd {
+	if msg.err != nil {
+		return tea.Batch(tea.WindowSize(), m.handleError(msg.err))
+	}
+	return tea.Batch(tea.WindowSize(), msg.apply(m, msg.text))
+}
+
+// editorProcess runs the editor while the TUI is suspended. If the editor
+// fails, for example because it crashed, it may have left the terminal in raw
+// mode or with changed settings, so the terminal is put back the way it was
+// before the editor started.
+type editorProcess struct {
+	*exec.Cmd
+}
+
+func (e *editorProcess) SetStdin(r io.Reader) {
+	if e.Stdin == nil {
+		e.Stdin = r
+	}
+}
+
+func (e *editorProcess) SetStdout(w io.Writer) {
+	if e.Stdout == nil {
+		e.Stdout = w
+	}
+}
+
+func (e *editorProcess) SetStderr(w io.Writer) {
+	if e.Stderr == nil {
+		e.Stderr = w
+	}
+}
+
+func (e *editorProcess) Run() error {
+	fd := int(os.Stdin.Fd())
+	state, stateErr := term.GetState(fd)
+
+	err := e.Cmd.Run()
+	if err != nil {
+		if stateErr == nil {
+			_ = term.Restore(fd, state)
+		}
+		// Leave the editor's alternate screen and soft reset the terminal's
+		// modes, such as a hidden cursor or mouse reporting
+		if e.Stdout != nil {
+			fmt.Fprint(e.Stdout, "\x1b[?1049l\x1b[!p")
+		}
+	}
+	return err
+}
+
+// editTextInput opens the text of the text input overlay, such as a prompt, in
+// the external editor.
+func (m *home) editTextInput() tea.Cmd {
+	textInput := m.textInputOverlay
+	textInput.EditorRequested = false
+	return m.editExternally(textInput.GetValue(), "claude-squad-*.md", func(m *home, text string) tea.Cmd {
+		// The overlay may have been closed in the meantime
+		if m.textInputOverlay == textInput {
+			textInput.SetValue(strings.TrimRight(text, "\r\n"))
+		}
+		return nil
+	})
+}
+
+// commitMessageHelp follows the commit message in the editor, like git does.
+const commitMessageHelp = `
+# Enter the commit message for the push. Lines starting with '#' are ignored,
+# and an empty message aborts the push.
+`
+
+// parseCommitMessage returns the commit message saved in the editor without
+// comment lines and surrounding blank lines.
+func parseCommitMessage(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// editFile suspends the TUI and opens the file selected in the files tab in
+// the external editor. The file is edited in place in the worktree.
+func (m *home) editFile() tea.Cmd {
+	selected := m.list.GetSelectedInstance()
+	file := m.tabbedWindow.SelectedFile()
+	if selected == nil || !selected.Started() || selected.Scratch || file == "" {
+		return nil
+	}
+	worktree, err := selected.GetGitWorktree()
+	if err != nil {
+		return m.handleError(err)
+	}
+	path := filepath.Join(worktree.GetWorktreePath(), filepath.FromSlash(file))
+
+	editor := editorCommand()
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Dir = worktree.GetWorktreePath()
+	return tea.Exec(&editorProcess{Cmd: cmd}, func(err error) tea.Msg {
+		if err != nil {
+			return editorResultMsg{err: fmt.Errorf("editor %s failed: %w", editor[0], err)}
+		}
+		return editorResultMsg{apply: func(m *home, _ string) tea.Cmd {
+			return m.instanceChanged()
+		}}
+	})
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"claude-squad/config"
+	"claude-squad/session"
+	"claude-squad/testing/harness"
+	"claude-squad/testing/snapshot"
+	"claude-squad/ui"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+)
+
+// goldenSizes are the terminal sizes the views are rendered at, covering each
+// layout mode and the thresholds of the degradation system.
+var goldenSizes = []harness.TerminalSize{
+	{Name: "60x20", Width: 60, Height: 20},   // below the minimum, the size warning
+	{Name: "80x24", Width: 80, Height: 24},   // the minimum size, single line menu and simplified tabs
+	{Name: "90x36", Width: 90, Height: 36},   // no timers, list summaries still shown
+	{Name: "100x30", Width: 100, Height: 30}, // compact
+	{Name: "120x40", Width: 120, Height: 40}, // standard
+	{Name: "160x50", Width: 160, Height: 50}, // full
+}
+
+// testConfig is the default config, made once as it looks up the claude
+// command through the shell.
+var testConfig = sync.OnceValue(config.DefaultConfig)
+
+// newTestHome returns a home with the given instances, without storage or
+// sessions. It gets its size from the harness.
+func newTestHome(instances ...*session.Instance) *home {
+	h := &home{
+		ctx:          context.Background(),
+		state:        stateDefault,
+		appConfig:    testConfig(),
+		spinner:      spinner.New(spinner.WithSpinner(spinner.MiniDot)),
+		menu:         ui.NewMenu(),
+		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane(), ui.NewFilesPane(), ui.NewChecksPane()),
+		errBox:       ui.NewErrBox(),
+
+		pendingFinalizers: make(map[*session.Instance]func()),
+	}
+	h.list = ui.NewList(&h.spinner, false)
+	for _, instance := range instances {
+		h.list.AddInstance(instance)()
+	}
+	h.instanceChanged()
+	return h
+}
+
+// fakeInstances returns instances in a few states whose rendering doesn't
+// depend on the clock or a running session.
+func fakeInstances() []*session.Instance {
+	created := time.Now().Add(-3*time.Hour - 30*time.Minute)
+	instance := func(title, branch string, status session.Status) *session.Instance {
+		return &session.Instance{
+			Title:     title,
+			Path:      "/home/dev/api",
+			Branch:    branch,
+			Status:    status,
+			Program:   "claude",
+			CreatedAt: created,
+			UpdatedAt: created,
+		}
+	}
+	paused := instance("fix-login", "dev/fix-login", session.Paused)
+	paused.Tags = []string{"auth"}
+	return []*session.Instance{
+		paused,
+		instance("add-metrics", "dev/add-metrics", session.Ready),
+		instance("upgrade-deps", "dev/upgrade-deps", session.Pending),
+	}
+}
+
+// TestViewGolden renders the views at each size and compares them with the
+// files in testdata/golden. Run it with -update to rewrite them.
+func TestViewGolden(t *testing.T) {
+	views := []struct {
+		name      string
+		instances func() []*session.Instance
+		setup     func(h *home)
+	}{
+		{"empty", func() []*session.Instance { return nil }, nil},
+		{"instances", fakeInstances, nil},
+		{"help", fakeInstances, func(h *home) {
+			h.appState = &memoryState{}
+			h.showKeyHelp()
+		}},
+	}
+	for _, view := range views {
+		t.Run(view.name, func(t *testing.T) {
+			harness.RunWithSizes(t, goldenSizes, func(t *testing.T, size harness.TerminalSize) {
+				h := newTestHome(view.instances()...)
+				if view.setup != nil {
+					view.setup(h)
+				}
+				th := harness.New(t, h, size.Width, size.Height)
+				rendered := th.View()
+				// A view that doesn't fit scrolls the terminal, whatever the golden file says
+				assert.LessOrEqual(t, lipgloss.Height(rendered), size.Height, "the view is taller than the terminal")
+				assert.LessOrEqual(t, lipgloss.Width(rendered), size.Width, "the view is wider than the terminal")
+				snapshot.New(t).Assert(fmt.Sprintf("%s_%s", view.name, size.Name), rendered)
+			})
+		})
+	}
+}
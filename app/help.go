@@ -2,26 +2,28 @@ package app
 
 import (
 	"claude-squad/config"
+	"claude-squad/keys"
 	"claude-squad/log"
 	"claude-squad/session"
 	"claude-squad/ui"
+	"claude-squad/ui/layout"
 	"claude-squad/ui/overlay"
 	"fmt"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
 type helpText interface {
 	// toContent returns the help UI content.
 	toContent() string
-	// mask returns the bit mask for this help text. These are used to track which help screens
-	// have been seen in the config and app state.
-	mask() uint32
+	// name returns the name this help screen is tracked as seen under in the
+	// app state.
+	name() string
 }
 
-type helpTypeGeneral struct{}
-
 type helpTypeInstanceStart struct {
 	instance *session.Instance
 }
@@ -34,32 +36,38 @@ func helpStart(instance *session.Instance) helpText {
 	return helpTypeInstanceStart{instance: instance}
 }
 
-func (h helpTypeGeneral) toContent() string {
-	content := lipgloss.JoinVertical(lipgloss.Left,
-		titleStyle.Render("Claude Squad"),
-		"",
-		"A terminal UI that manages multiple Claude Code (and other local agents) in separate workspaces.",
-		"",
-		headerStyle.Render("Managing:"),
-		keyStyle.Render("n")+descStyle.Render("         - Create a new session"),
-		keyStyle.Render("N")+descStyle.Render("         - Create a new session with a prompt"),
-		keyStyle.Render("i")+descStyle.Render("         - Import orphaned Zellij sessions"),
-		keyStyle.Render("D")+descStyle.Render("         - Kill (delete) the selected session"),
-		keyStyle.Render("↑/j, ↓/k")+descStyle.Render("  - Navigate between sessions"),
-		keyStyle.Render("↵/o")+descStyle.Render("       - Attach to the selected session"),
-		keyStyle.Render("ctrl-q")+descStyle.Render("    - Detach from session"),
-		"",
-		headerStyle.Render("Handoff:"),
-		keyStyle.Render("p")+descStyle.Render("         - Commit and push branch to github"),
-		keyStyle.Render("c")+descStyle.Render("         - Checkout: commit changes and pause session"),
-		keyStyle.Render("r")+descStyle.Render("         - Resume a paused session"),
-		"",
-		headerStyle.Render("Other:"),
-		keyStyle.Render("tab")+descStyle.Render("       - Switch between preview and diff tabs"),
-		keyStyle.Render("shift-↓/↑")+descStyle.Render(" - Scroll in diff view"),
-		keyStyle.Render("q")+descStyle.Render("         - Quit the application"),
-	)
-	return content
+// helpSections returns the keys of the keymap grouped by the context they
+// apply in. The context the user is in comes first.
+func helpSections(current keys.HelpContext) []overlay.HelpSection {
+	contexts := []keys.HelpContext{current}
+	for _, context := range keys.HelpContexts {
+		if context != current {
+			contexts = append(contexts, context)
+		}
+	}
+
+	sections := make([]overlay.HelpSection, 0, len(contexts))
+	for _, context := range contexts {
+		section := overlay.HelpSection{Title: context.String()}
+		for _, entry := range keys.Help {
+			if entry.Context == context {
+				section.Keys = append(section.Keys, overlay.HelpKey{Key: entry.Key(), Description: entry.Description})
+			}
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// keyLine renders the keys bound to name, padded to width, and what they do.
+func keyLine(name keys.KeyName, width int, description string) string {
+	key := helpKey(name)
+	return keyStyle.Render(key) + descStyle.Render(strings.Repeat(" ", max(width-runewidth.StringWidth(key), 0))+" - "+description)
+}
+
+// helpKey returns how the help shows the keys bound to name.
+func helpKey(name keys.KeyName) string {
+	return keys.GlobalkeyBindings[name].Help().Key
 }
 
 func (h helpTypeInstanceStart) toContent() string {
@@ -72,6 +80,9 @@ func (h helpTypeInstanceStart) toContent() string {
 	case config.SessionTypeDockerClone:
 		envDesc = fmt.Sprintf("• %s running in Docker container (cloned repo)",
 			lipgloss.NewStyle().Bold(true).Render(h.instance.Program))
+	case config.SessionTypeNative:
+		envDesc = fmt.Sprintf("• %s running in a terminal managed by claude-squad",
+			lipgloss.NewStyle().Bold(true).Render(h.instance.Program))
 	default:
 		envDesc = fmt.Sprintf("• %s running in background Zellij session",
 			lipgloss.NewStyle().Bold(true).Render(h.instance.Program))
@@ -95,13 +106,13 @@ func (h helpTypeInstanceStart) toContent() string {
 		descStyle.Render(envDesc),
 		"",
 		headerStyle.Render("Managing:"),
-		keyStyle.Render("↵/o")+descStyle.Render("   - Attach to the session to interact with it directly"),
-		keyStyle.Render("tab")+descStyle.Render("   - Switch preview panes to view session diff"),
-		keyStyle.Render("D")+descStyle.Render("     - Kill (delete) the selected session"),
+		keyLine(keys.KeyEnter, 5, "Attach to the session to interact with it directly"),
+		keyLine(keys.KeyTab, 5, "Switch preview panes to view session diff"),
+		keyLine(keys.KeyKill, 5, "Kill (delete) the selected session"),
 		"",
 		headerStyle.Render("Handoff:"),
-		keyStyle.Render("c")+descStyle.Render("     - Checkout this instance's branch"),
-		keyStyle.Render("p")+descStyle.Render("     - Push branch to GitHub to create a PR"),
+		keyLine(keys.KeyCheckout, 5, "Checkout this instance's branch"),
+		keyLine(keys.KeySubmit, 5, "Push branch to GitHub to create a PR"),
 	)
 	return content
 }
@@ -110,7 +121,7 @@ func (h helpTypeInstanceAttach) toContent() string {
 	content := lipgloss.JoinVertical(lipgloss.Left,
 		titleStyle.Render("Attaching to Instance"),
 		"",
-		descStyle.Render("To detach from a session, press ")+keyStyle.Render("ctrl-q"),
+		descStyle.Render("To detach from a session, press ")+keyStyle.Render(helpKey(keys.KeyDetach)),
 	)
 	return content
 }
@@ -124,23 +135,23 @@ func (h helpTypeInstanceCheckout) toContent() string {
 		"Feel free to make changes to the branch and commit them. When resuming, the session will continue from where you left off.",
 		"",
 		headerStyle.Render("Commands:"),
-		keyStyle.Render("c")+descStyle.Render(" - Checkout: commit changes locally and pause session"),
-		keyStyle.Render("r")+descStyle.Render(" - Resume a paused session"),
+		keyLine(keys.KeyCheckout, 1, "Checkout: commit changes locally and pause session"),
+		keyLine(keys.KeyCheckoutRunning, 1, "Check out the changes detached in your repository and keep the session running"),
+		keyLine(keys.KeyResume, 1, "Resume a paused session"),
 	)
 	return content
 }
-func (h helpTypeGeneral) mask() uint32 {
-	return 1
-}
 
-func (h helpTypeInstanceStart) mask() uint32 {
-	return 1 << 1
+func (h helpTypeInstanceStart) name() string {
+	return config.HelpScreenInstanceStart
 }
-func (h helpTypeInstanceAttach) mask() uint32 {
-	return 1 << 2
+
+func (h helpTypeInstanceAttach) name() string {
+	return config.HelpScreenAttach
 }
-func (h helpTypeInstanceCheckout) mask() uint32 {
-	return 1 << 3
+
+func (h helpTypeInstanceCheckout) name() string {
+	return config.HelpScreenCheckout
 }
 
 var (
@@ -152,27 +163,13 @@ var (
 
 // showHelpScreen displays the help screen overlay if it hasn't been shown before
 func (m *home) showHelpScreen(helpType helpText, onDismiss func()) (tea.Model, tea.Cmd) {
-	// Get the flag for this help type
-	var alwaysShow bool
-	switch helpType.(type) {
-	case helpTypeGeneral:
-		alwaysShow = true
-	}
-
-	flag := helpType.mask()
-
-	// Check if this help screen has been seen before
-	// Only show if we're showing the general help screen or the corresponding flag is not set
-	// in the seen bitmask.
-	if alwaysShow || (m.appState.GetHelpScreensSeen()&flag) == 0 {
-		// Mark this help screen as seen and save state
-		if err := m.appState.SetHelpScreensSeen(m.appState.GetHelpScreensSeen() | flag); err != nil {
+	name := helpType.name()
+	if !m.appState.HelpScreenSeen(name) {
+		if err := m.appState.SetHelpScreenSeen(name); err != nil {
 			log.WarningLog.Printf("Failed to save help screen state: %v", err)
 		}
 
-		content := helpType.toContent()
-
-		m.textOverlay = overlay.NewTextOverlay(content)
+		m.textOverlay = overlay.NewTextOverlay(helpType.toContent())
 		m.textOverlay.OnDismiss = onDismiss
 		m.state = stateHelp
 		return m, nil
@@ -185,19 +182,50 @@ func (m *home) showHelpScreen(helpType helpText, onDismiss func()) (tea.Model, t
 	return m, nil
 }
 
+// showKeyHelp opens the key reference, with the keys of the tab the user is
+// in first.
+func (m *home) showKeyHelp() (tea.Model, tea.Cmd) {
+	current := keys.HelpContextList
+	if m.tabbedWindow.IsInDiffTab() || m.tabbedWindow.IsInFilesTab() {
+		current = keys.HelpContextDiff
+	}
+	if err := m.appState.SetHelpScreenSeen(config.HelpScreenGeneral); err != nil {
+		log.WarningLog.Printf("Failed to save help screen state: %v", err)
+	}
+
+	m.helpOverlay = overlay.NewHelpOverlay("Claude Squad",
+		"A terminal UI that manages multiple Claude Code (and other local agents) in separate workspaces.",
+		helpSections(current))
+	m.helpOverlay.SetSize(m.keyHelpSize())
+	m.state = stateKeyHelp
+	return m, nil
+}
+
+// keyHelpSize returns the size of the key reference, which takes as much of
+// the terminal as the overlays may.
+func (m *home) keyHelpSize() (int, int) {
+	return layout.ComputeOverlaySize(m.termWidth, m.termHeight, 90, m.termHeight)
+}
+
+// handleKeyHelpState handles key events while the key reference is shown.
+func (m *home) handleKeyHelpState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.helpOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+	m.helpOverlay = nil
+	m.state = stateDefault
+	m.menu.SetState(ui.StateDefault)
+	return m, tea.WindowSize()
+}
+
 // handleHelpState handles key events when in help state
 func (m *home) handleHelpState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Any key press will close the help overlay
 	shouldClose := m.textOverlay.HandleKeyPress(msg)
 	if shouldClose {
 		m.state = stateDefault
-		return m, tea.Sequence(
-			tea.WindowSize(),
-			func() tea.Msg {
-				m.menu.SetState(ui.StateDefault)
-				return nil
-			},
-		)
+		m.menu.SetState(ui.StateDefault)
+		return m, tea.WindowSize()
 	}
 
 	return m, nil
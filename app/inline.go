@@ -0,0 +1,127 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/ui"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// inlineKeyBuffer is how many keystrokes may wait to be sent to the session
+// during inline attach before more are dropped.
+const inlineKeyBuffer = 256
+
+// inlineKeySequences are the terminal input sequences of the special keys
+// forwarded during inline attach. Control keys, including enter, tab, escape
+// and backspace, are forwarded as their control character.
+var inlineKeySequences = map[tea.KeyType]string{
+	tea.KeySpace:    " ",
+	tea.KeyShiftTab: "\x1b[Z",
+	tea.KeyUp:       "\x1b[A",
+	tea.KeyDown:     "\x1b[B",
+	tea.KeyRight:    "\x1b[C",
+	tea.KeyLeft:     "\x1b[D",
+	tea.KeyHome:     "\x1b[H",
+	tea.KeyEnd:      "\x1b[F",
+	tea.KeyPgUp:     "\x1b[5~",
+	tea.KeyPgDown:   "\x1b[6~",
+	tea.KeyDelete:   "\x1b[3~",
+	tea.KeyInsert:   "\x1b[2~",
+}
+
+// inlineKeySequence returns what the key writes to a terminal, or an empty
+// string for keys that aren't forwarded.
+func inlineKeySequence(msg tea.KeyMsg) string {
+	var sequence string
+	switch {
+	case msg.Type == tea.KeyRunes:
+		sequence = string(msg.Runes)
+	case msg.Type >= 0 && msg.Type < 32 || msg.Type == tea.KeyBackspace:
+		sequence = string(rune(msg.Type))
+	default:
+		sequence = inlineKeySequences[msg.Type]
+	}
+	if msg.Alt && sequence != "" {
+		sequence = "\x1b" + sequence
+	}
+	return sequence
+}
+
+// startInlineAttach forwards the keys typed in the TUI to the selected
+// instance, while its preview keeps rendering in the preview pane, until
+// ctrl+q is pressed.
+func (m *home) startInlineAttach() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || !selected.Started() || selected.Paused() {
+		return m, nil
+	}
+	if err := m.tabbedWindow.ResetPreviewToNormalMode(selected); err != nil {
+		return m, m.handleError(err)
+	}
+	m.tabbedWindow.SelectTab(ui.PreviewTab)
+	m.tabbedWindow.SetInlineAttach(true)
+	m.menu.SetInDiffTab(false)
+	m.menu.SetInFilesTab(false)
+
+	keys := make(chan string, inlineKeyBuffer)
+	m.inlineKeys = keys
+	m.state = stateInlineAttach
+	go sendInlineKeys(selected, keys)
+	return m, tea.Batch(m.showInfo(fmt.Sprintf("Typing into '%s', ctrl+q to stop", selected.Title)), m.instanceChanged())
+}
+
+// sendInlineKeys sends the keys to the instance in order until the channel is
+// closed. Keys typed while the last ones were being sent are sent together.
+func sendInlineKeys(instance *session.Instance, keys chan string) {
+	for sequence := range keys {
+		var pending strings.Builder
+		pending.WriteString(sequence)
+	drain:
+		for {
+			select {
+			case more, ok := <-keys:
+				if !ok {
+					break drain
+				}
+				pending.WriteString(more)
+			default:
+				break drain
+			}
+		}
+		if err := instance.SendKeys(pending.String()); err != nil {
+			log.WarningLog.Printf("failed to send keys to %s: %v", instance.Title, err)
+		}
+	}
+}
+
+// stopInlineAttach stops forwarding keys to the instance.
+func (m *home) stopInlineAttach() {
+	if m.inlineKeys != nil {
+		close(m.inlineKeys)
+		m.inlineKeys = nil
+	}
+	m.tabbedWindow.SetInlineAttach(false)
+	m.state = stateDefault
+}
+
+// handleInlineAttachState forwards key presses to the instance during inline
+// attach.
+func (m *home) handleInlineAttachState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Type == tea.KeyCtrlQ {
+		m.stopInlineAttach()
+		return m, m.instanceChanged()
+	}
+	sequence := inlineKeySequence(msg)
+	if sequence == "" {
+		return m, nil
+	}
+	select {
+	case m.inlineKeys <- sequence:
+	default:
+		log.WarningLog.Printf("dropped keys typed faster than they could be sent: %q", sequence)
+	}
+	return m, nil
+}
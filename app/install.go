@@ -0,0 +1,74 @@
+package app
+
+import (
+	"claude-squad/config"
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startInstanceMsg starts a new instance once its dependency install has been
+// confirmed or declined.
+type startInstanceMsg struct {
+	instance *session.Instance
+}
+
+// launchInstance starts the new instance with the loading overlay, first
+// installing the dependencies of its repository as configured.
+func (m *home) launchInstance(instance *session.Instance) tea.Cmd {
+	commands := m.installCommands(instance)
+	if len(commands) == 0 {
+		return m.startNewInstance(instance)
+	}
+	if m.appConfig.InstallDependencies == config.InstallDependenciesAuto {
+		instance.SetInstallCommands(commands)
+		return m.startNewInstance(instance)
+	}
+
+	message := fmt.Sprintf("Install dependencies with %s before starting '%s'? Press n to start without them.",
+		strings.Join(commands, ", "), instance.Title)
+	cmd := m.confirmAction(message, func() tea.Msg {
+		instance.SetInstallCommands(commands)
+		return startInstanceMsg{instance: instance}
+	})
+	m.confirmationOverlay.OnCancel = func() {
+		m.state = stateDefault
+		m.confirmResult = startInstanceMsg{instance: instance}
+	}
+	return cmd
+}
+
+// installCommands returns the commands that install the dependencies of the
+// new instance, or none if installing is off or doesn't apply to it.
+func (m *home) installCommands(instance *session.Instance) []string {
+	if m.appConfig.InstallDependencies == config.InstallDependenciesOff || instance.Scratch {
+		return nil
+	}
+	// Docker sessions would run the commands on the host rather than in the
+	// container the program runs in
+	switch instance.GetSessionType() {
+	case config.SessionTypeDockerBind, config.SessionTypeDockerClone:
+		return nil
+	}
+	// The repository's setup commands replace the detected ones
+	if repoConfig, err := config.LoadRepoConfig(instance.Path); err == nil && repoConfig != nil && len(repoConfig.SetupCommands) > 0 {
+		return repoConfig.SetupCommands
+	}
+	return session.DetectInstallCommands(instance.Path)
+}
+
+// startNewInstance shows the loading overlay and starts the instance in the
+// background, or queues it if max_running instances are working already.
+func (m *home) startNewInstance(instance *session.Instance) tea.Cmd {
+	if m.atRunningLimit() {
+		return m.queueInstance(instance)
+	}
+	m.loadingOverlay = overlay.NewLoadingOverlay("Creating Instance", &m.spinner)
+	m.loadingOverlay.SetWidth(50)
+	m.loadingOverlay.SetStatus("Initializing...")
+	m.state = stateLoading
+	return m.startInstanceAsync(instance)
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"claude-squad/session/git"
+	"claude-squad/ui/overlay"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/truncate"
+)
+
+const (
+	// issuePickerLimit is how many open issues the picker lists at most.
+	issuePickerLimit = 30
+	// issueTitleWidth is the width issue titles are truncated to in the picker.
+	issueTitleWidth = 50
+)
+
+// issuesLoadedMsg is sent when the open issues of a repository were listed.
+type issuesLoadedMsg struct {
+	dir    string
+	issues []git.Issue
+	err    error
+}
+
+// showIssuePicker lists the open issues of the selected instance's repository,
+// or of the working directory, in the background.
+func (m *home) showIssuePicker() (tea.Model, tea.Cmd) {
+	dir := "."
+	if selected := m.list.GetSelectedInstance(); selected != nil && !selected.Scratch {
+		dir = selected.Path
+		if worktree, err := selected.GetGitWorktree(); err == nil && worktree != nil {
+			dir = worktree.GetRepoPath()
+		}
+	}
+	return m, tea.Batch(m.showInfo("Loading open issues..."), func() tea.Msg {
+		issues, err := git.ListIssues(dir, issuePickerLimit)
+		return issuesLoadedMsg{dir: dir, issues: issues, err: err}
+	})
+}
+
+// handleIssuesLoaded asks which of the listed issues the new instance works on.
+func (m *home) handleIssuesLoaded(msg issuesLoadedMsg) tea.Cmd {
+	// The user moved on while the issues were loading
+	if m.state != stateDefault {
+		return nil
+	}
+	if msg.err != nil {
+		return m.handleError(msg.err)
+	}
+	if len(msg.issues) == 0 {
+		return m.showInfo("The repository has no open issues")
+	}
+
+	names := make([]string, len(msg.issues))
+	for i, issue := range msg.issues {
+		names[i] = fmt.Sprintf("#%d %s", issue.Number, truncate.StringWithTail(issue.Title, issueTitleWidth, "..."))
+	}
+	m.issues = msg.issues
+	m.issueRepoDir = msg.dir
+	m.selectionOverlay = overlay.NewSelectionOverlay("Create a session for issue", names)
+	m.state = stateIssueSelect
+	return nil
+}
+
+// handleIssueSelectState handles key presses while choosing an issue. The
+// instance is created in the issue's repository, titled after the issue, and
+// its prompt is prefilled with the issue.
+func (m *home) handleIssueSelectState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.selectionOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+	idx := m.selectionOverlay.GetSelectedIndex()
+	m.selectionOverlay = nil
+	issues, dir := m.issues, m.issueRepoDir
+	m.issues = nil
+	m.issueRepoDir = ""
+	m.state = stateDefault
+	if idx < 0 {
+		return m, nil
+	}
+
+	issue := issues[idx]
+	m.pendingIssue = &issue
+	m.pendingInstancePath = dir
+	m.promptAfterName = true
+	return m.selectRepoDirectory(dir)
+}
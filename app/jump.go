@@ -0,0 +1,61 @@
+package app
+
+import (
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxJumpDigits is how many digits can follow the jump key.
+const maxJumpDigits = 2
+
+// handleJumpKey handles jumping to an instance by the number shown in the list. A
+// digit 1-9 jumps directly; after the jump key (g) up to two digits can be typed,
+// with enter jumping early. Returns false if the key was not part of a jump.
+func (m *home) handleJumpKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	key := msg.String()
+	isDigit := len(key) == 1 && key[0] >= '0' && key[0] <= '9'
+
+	if !m.jumpPending {
+		if isDigit && key != "0" {
+			return true, m.jumpTo(key)
+		}
+		return false, nil
+	}
+
+	switch {
+	case isDigit:
+		m.jumpDigits += key
+		if len(m.jumpDigits) < maxJumpDigits {
+			return true, nil
+		}
+	case key == "enter" && m.jumpDigits != "":
+	case key == "esc":
+		m.resetJump()
+		return true, nil
+	default:
+		// Any other key cancels the jump and is handled normally
+		m.resetJump()
+		return false, nil
+	}
+
+	digits := m.jumpDigits
+	m.resetJump()
+	return true, m.jumpTo(digits)
+}
+
+// jumpTo selects the instance with the given (1-based) list number.
+func (m *home) jumpTo(number string) tea.Cmd {
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 || n > m.list.NumInstances() {
+		return nil
+	}
+	m.list.SetSelectedInstance(n - 1)
+	return m.instanceChanged()
+}
+
+// resetJump clears a pending jump.
+func (m *home) resetJump() {
+	m.jumpPending = false
+	m.jumpDigits = ""
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"claude-squad/audit"
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// untrackedListedMsg is sent when the untracked files of an instance about to
+// be killed have been listed.
+type untrackedListedMsg struct {
+	instance  *session.Instance
+	untracked []git.UntrackedEntry
+}
+
+// listUntrackedForKill lists the untracked files that will be lost with the
+// worktree of the instance in the background, as adding up their sizes can take
+// a while. The kill is confirmed once they are listed.
+func (m *home) listUntrackedForKill(instance *session.Instance) tea.Cmd {
+	worktree, err := instance.GetGitWorktree()
+	if err != nil || worktree == nil {
+		return func() tea.Msg { return untrackedListedMsg{instance: instance} }
+	}
+	return func() tea.Msg {
+		untracked, err := worktree.UntrackedFiles()
+		if err != nil {
+			log.WarningLog.Printf("could not list untracked files for %s: %v", instance.Title, err)
+		}
+		return untrackedListedMsg{instance: instance, untracked: untracked}
+	}
+}
+
+// killConfirmedMsg is sent when the kill of an instance has been confirmed.
+type killConfirmedMsg struct {
+	instance  *session.Instance
+	worktree  *git.GitWorktree
+	untracked []git.UntrackedEntry
+}
+
+// confirmKill asks to kill the instance, listing its untracked files. Nothing
+// is asked if something else was opened or the instance was killed meanwhile.
+func (m *home) confirmKill(msg untrackedListedMsg) tea.Cmd {
+	if m.state != stateDefault || msg.instance.Tombstoned() {
+		return nil
+	}
+	selected, untracked := msg.instance, msg.untracked
+
+	// Create the kill action as a tea.Cmd
+	killAction := func() tea.Msg {
+		// Get worktree and check if branch is checked out
+		worktree, err := selected.GetGitWorktree()
+		if err != nil {
+			return err
+		}
+
+		// Only check if branch is checked out if worktree path exists
+		// Archived sessions may have had their worktree removed; scratch sessions have none
+		if worktree != nil {
+			if _, statErr := os.Stat(worktree.GetWorktreePath()); statErr == nil {
+				checkedOut, err := worktree.IsBranchCheckedOut()
+				if err != nil {
+					return err
+				}
+
+				if checkedOut {
+					return fmt.Errorf("instance %s is currently checked out", selected.Title)
+				}
+			}
+		}
+		return killConfirmedMsg{instance: selected, worktree: worktree, untracked: untracked}
+	}
+
+	// Show confirmation modal
+	message := fmt.Sprintf("[!] Kill session '%s'?", selected.Title)
+	if report := untrackedReport(untracked, m.appConfig.KillRescuePatterns); report != "" {
+		message += "\n\n" + report
+	}
+	return m.confirmAction(message, killAction)
+}
+
+// killInstance tombstones the instance and removes it from view. The worktree
+// and session are only cleaned up once the undo window has passed; the instance
+// is deleted from storage after that succeeded, like the tombstones left by a
+// previous run.
+func (m *home) killInstance(msg killConfirmedMsg) tea.Cmd {
+	selected, worktree, untracked := msg.instance, msg.worktree, msg.untracked
+	rescuePatterns := m.appConfig.KillRescuePatterns
+	trashRetentionDays := m.appConfig.TrashRetentionDays
+
+	// Tombstone the instance; the actual cleanup is deferred so the kill can be undone
+	deletedAt := time.Now()
+	if err := m.storage.TombstoneInstance(selected.Title, deletedAt); err != nil {
+		return m.handleError(err)
+	}
+	selected.DeletedAt = &deletedAt
+	m.list.RemoveSelectedFromView()
+	audit.Record(audit.EventKill, selected.Title, selected.Branch)
+
+	scheduled := m.deferAction(&pendingUndo{
+		description: fmt.Sprintf("Killed '%s'", selected.Title),
+		deferOnQuit: true,
+		undo: func() error {
+			selected.DeletedAt = nil
+			audit.Record(audit.EventKillUndone, selected.Title, "")
+			return m.storage.RestoreInstance(selected.Title)
+		},
+		cleanup: func() tea.Msg {
+			// Move selected untracked files back to the main checkout before the worktree is removed
+			if worktree != nil {
+				if moved, err := worktree.RescueUntracked(untracked, rescuePatterns); err != nil {
+					log.WarningLog.Printf("could not rescue untracked files for %s: %v", selected.Title, err)
+				} else if len(moved) > 0 {
+					log.InfoLog.Printf("moved %d untracked files from %s to main checkout: %v", len(moved), selected.Title, moved)
+				}
+			}
+			return tombstoneCleanedMsg{instance: selected, err: selected.Kill(trashRetentionDays)}
+		},
+	})
+	return func() tea.Msg { return scheduled }
+}
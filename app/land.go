@@ -0,0 +1,83 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// landResultMsg is sent when the merge queue has tried to land an instance.
+type landResultMsg struct {
+	instance *session.Instance
+	err      error
+}
+
+// toggleLand adds the selected instance to the merge queue, or takes it out if
+// it is queued already.
+func (m *home) toggleLand() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+
+	if selected.LandState == session.LandQueued {
+		selected.UnqueueLand()
+		return m, tea.Batch(m.showInfo(fmt.Sprintf("Removed '%s' from the merge queue", selected.Title)), m.requestSave())
+	}
+
+	lastError := selected.LandError
+	if err := selected.QueueLand(); err != nil {
+		return m, m.handleError(err)
+	}
+	info := fmt.Sprintf("Queued '%s' to land", selected.Title)
+	if lastError != "" {
+		info = fmt.Sprintf("Re-queued '%s' to land, last attempt: %s", selected.Title, firstErrorLine(lastError))
+	}
+	return m, tea.Batch(m.showInfo(info), m.requestSave(), m.landNext())
+}
+
+// landNext lands the next queued instance in the background, unless an instance
+// is being landed already.
+func (m *home) landNext() tea.Cmd {
+	if m.landing {
+		return nil
+	}
+	next := session.NextToLand(m.list.GetInstances())
+	if next == nil {
+		return nil
+	}
+
+	land, err := next.LandJob(m.appConfig.LandCheckCommand)
+	if err != nil {
+		return func() tea.Msg { return landResultMsg{instance: next, err: err} }
+	}
+	m.landing = true
+	log.InfoLog.Printf("landing %s", next.Title)
+	return func() tea.Msg {
+		return landResultMsg{instance: next, err: land()}
+	}
+}
+
+// handleLandResult reports the outcome of landing an instance and moves on to
+// the next one in the queue.
+func (m *home) handleLandResult(msg landResultMsg) tea.Cmd {
+	m.landing = false
+	msg.instance.FinishLand(msg.err)
+	var reportCmd tea.Cmd
+	if msg.err != nil {
+		reportCmd = m.handleError(fmt.Errorf("failed to land '%s': %s", msg.instance.Title, firstErrorLine(msg.err.Error())))
+	} else {
+		log.InfoLog.Printf("landed %s", msg.instance.Title)
+		reportCmd = m.showInfo(fmt.Sprintf("Landed '%s'", msg.instance.Title))
+	}
+	return tea.Batch(reportCmd, m.requestSave(), m.landNext(), m.instanceChanged())
+}
+
+// firstErrorLine returns the first line of an error message, for the error box.
+func firstErrorLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return line
+}
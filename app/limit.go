@@ -0,0 +1,44 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultInstanceLimit is how many instances can be created before a warning
+// is shown when instance_limit is not configured.
+const defaultInstanceLimit = 50
+
+// instanceLimit returns how many instances can be created before a warning is
+// shown, 0 if it is never shown.
+func (m *home) instanceLimit() int {
+	switch limit := m.appConfig.InstanceLimit; {
+	case limit < 0:
+		return 0
+	case limit == 0:
+		return defaultInstanceLimit
+	default:
+		return limit
+	}
+}
+
+// instanceLimitWarning returns the warning shown when adding instances to the
+// existing ones passes the instance limit, or an empty string.
+func instanceLimitWarning(existing, adding, limit int) string {
+	if limit <= 0 || existing+adding <= limit {
+		return ""
+	}
+	return fmt.Sprintf("%d instances is above the limit of %d, each runs its own agent and session, so expect high CPU and memory use",
+		existing+adding, limit)
+}
+
+// warnInstanceLimit shows a warning when adding instances passes the instance
+// limit. Creating them is still allowed.
+func (m *home) warnInstanceLimit(adding int) tea.Cmd {
+	warning := instanceLimitWarning(m.list.NumInstances(), adding, m.instanceLimit())
+	if warning == "" {
+		return nil
+	}
+	return m.showInfo(warning)
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// showNotes opens the tags and notes editor for the selected instance.
+func (m *home) showNotes() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+
+	m.notesOverlay = overlay.NewNotesOverlay(
+		fmt.Sprintf("Notes for '%s'", selected.Title),
+		strings.Join(selected.Tags, ", "),
+		selected.Notes,
+	)
+	m.state = stateNotes
+	m.menu.SetState(ui.StatePrompt)
+	return m, tea.WindowSize()
+}
+
+// handleNotesState handles key presses while editing the tags and notes of an instance.
+func (m *home) handleNotesState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.notesOverlay.HandleKeyPress(msg) {
+		if m.notesOverlay.EditorRequested {
+			return m, m.editNotes()
+		}
+		return m, nil
+	}
+
+	notesOverlay := m.notesOverlay
+	m.notesOverlay = nil
+	m.state = stateDefault
+	m.menu.SetState(ui.StateDefault)
+
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || !notesOverlay.IsSubmitted() {
+		return m, nil
+	}
+
+	selected.Tags = session.ParseTags(notesOverlay.GetTags())
+	selected.Notes = strings.TrimSpace(notesOverlay.GetNotes())
+	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+		return m, m.handleError(err)
+	}
+	return m, tea.Batch(tea.WindowSize(), m.instanceChanged())
+}
+
+// editNotes opens the notes being edited in the external editor.
+func (m *home) editNotes() tea.Cmd {
+	notesOverlay := m.notesOverlay
+	notesOverlay.EditorRequested = false
+	return m.editExternally(notesOverlay.GetNotes(), "claude-squad-notes-*.md", func(m *home, text string) tea.Cmd {
+		// The notes may have been closed in the meantime
+		if m.notesOverlay == notesOverlay {
+			notesOverlay.SetNotes(strings.TrimRight(text, "\r\n"))
+		}
+		return nil
+	})
+}
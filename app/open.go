@@ -0,0 +1,65 @@
+package app
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openCommandLine returns the command line that opens path, from a command
+// like "code {path}". {path} is replaced by the path, or the path is appended
+// when the command doesn't contain it.
+func openCommandLine(command, path string) []string {
+	fields := strings.Fields(command)
+	found := false
+	for i, field := range fields {
+		if strings.Contains(field, "{path}") {
+			fields[i] = strings.ReplaceAll(field, "{path}", path)
+			found = true
+		}
+	}
+	if !found {
+		fields = append(fields, path)
+	}
+	return fields
+}
+
+// openWorktree opens the selected instance's worktree with the configured open
+// command, e.g. in an editor or IDE. The command runs in the background.
+func (m *home) openWorktree() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || !selected.Started() || selected.Scratch {
+		return m, nil
+	}
+	command := m.appConfig.OpenCommand
+	if strings.TrimSpace(command) == "" {
+		return m, m.handleError(fmt.Errorf("no open command configured, set open_command in the config, e.g. \"code {path}\""))
+	}
+	worktree, err := selected.GetGitWorktree()
+	if err != nil {
+		return m, m.handleError(err)
+	}
+	if worktree == nil {
+		return m, m.handleError(fmt.Errorf("'%s' has no local worktree to open", selected.Title))
+	}
+	path := worktree.GetWorktreePath()
+
+	args := openCommandLine(command, path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = path
+	if err := cmd.Start(); err != nil {
+		return m, m.handleError(fmt.Errorf("failed to run %s: %w", args[0], err))
+	}
+	log.InfoLog.Printf("opened %s with %q", path, command)
+	// Reap the process; editors like code return right away, others keep
+	// running until the window is closed
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			log.WarningLog.Printf("open command %q for %s failed: %v", command, selected.Title, err)
+		}
+	}()
+	return m, m.showInfo(fmt.Sprintf("Opened '%s' with %s", selected.Title, args[0]))
+}
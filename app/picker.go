@@ -0,0 +1,33 @@
+package app
+
+import (
+	"claude-squad/ui/overlay"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// showPicker opens the picker to jump to any instance by name.
+func (m *home) showPicker() (tea.Model, tea.Cmd) {
+	m.pickerOverlay = overlay.NewPickerOverlay(m.list.GetInstances())
+	m.pickerOverlay.SetWidth(max(m.termWidth*6/10, 60))
+	m.state = statePicker
+	return m, nil
+}
+
+// handlePickerState handles key presses while the picker is shown.
+func (m *home) handlePickerState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.pickerOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+
+	selected, attach := m.pickerOverlay.Selected, m.pickerOverlay.AttachRequested
+	m.pickerOverlay = nil
+	m.state = stateDefault
+	if selected == nil || !m.list.SelectInstance(selected) {
+		return m, nil
+	}
+	if attach {
+		return m.attachSelected()
+	}
+	return m, m.instanceChanged()
+}
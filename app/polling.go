@@ -0,0 +1,69 @@
+package app
+
+import (
+	"claude-squad/session"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// defaultPreviewTickInterval is how often the preview is refreshed when
+	// preview_tick_interval is not configured.
+	defaultPreviewTickInterval = 250 * time.Millisecond
+	// defaultMetadataTickInterval is how often the selected instance is polled
+	// when metadata_tick_interval is not configured.
+	defaultMetadataTickInterval = 5 * time.Second
+	// defaultBackgroundPollInterval is how often the other instances are polled
+	// when background_poll_interval is not configured.
+	defaultBackgroundPollInterval = 15 * time.Second
+	// defaultIdlePollTimeout is how long an unchanged instance is polled when
+	// idle_poll_timeout is not configured.
+	defaultIdlePollTimeout = 5 * time.Minute
+	// maxPolledPerTick is how many instances are polled on one metadata tick,
+	// so that a large squad is polled a page at a time.
+	maxPolledPerTick = 16
+)
+
+// configuredInterval returns value in the given unit, or fallback when value
+// is not set.
+func configuredInterval(value int, unit, fallback time.Duration) time.Duration {
+	if value > 0 {
+		return time.Duration(value) * unit
+	}
+	return fallback
+}
+
+// previewTickCmd waits for the preview tick interval and triggers a preview update.
+func (m *home) previewTickCmd() tea.Cmd {
+	interval := configuredInterval(m.appConfig.PreviewTickInterval, time.Millisecond, defaultPreviewTickInterval)
+	return func() tea.Msg {
+		time.Sleep(interval)
+		return previewTickMsg{}
+	}
+}
+
+// tickUpdateMetadataCmd waits for the metadata tick interval and triggers a
+// metadata update of the instances that are due, see pollPolicy.
+func (m *home) tickUpdateMetadataCmd() tea.Cmd {
+	interval := configuredInterval(m.appConfig.MetadataTickInterval, time.Millisecond, defaultMetadataTickInterval)
+	return func() tea.Msg {
+		time.Sleep(interval)
+		return tickUpdateMetadataMessage{}
+	}
+}
+
+// pollPolicy polls the selected instance on every metadata tick and the
+// others less often, stopping once their output hasn't changed for a while.
+func (m *home) pollPolicy() session.PollPolicy {
+	idleTimeout := configuredInterval(m.appConfig.IdlePollTimeout, time.Second, defaultIdlePollTimeout)
+	if m.appConfig.IdlePollTimeout < 0 {
+		idleTimeout = 0
+	}
+	return session.PollPolicy{
+		Selected:           m.list.GetSelectedInstance(),
+		BackgroundInterval: configuredInterval(m.appConfig.BackgroundPollInterval, time.Millisecond, defaultBackgroundPollInterval),
+		IdleTimeout:        idleTimeout,
+		MaxPerTick:         maxPolledPerTick,
+	}
+}
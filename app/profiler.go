@@ -0,0 +1,107 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// profilerRefreshInterval is how often the profiler overlay is refreshed.
+const profilerRefreshInterval = time.Second
+
+// profilerTickMsg refreshes the profiler overlay it was sent for.
+type profilerTickMsg struct {
+	overlay *overlay.TextOverlay
+}
+
+// showProfiler opens the live render profiler. Metrics are recorded while it
+// is open, even without debug mode.
+func (m *home) showProfiler() (tea.Model, tea.Cmd) {
+	profiler := log.GetProfiler()
+	profiler.SetLive(true)
+	m.textOverlay = overlay.NewTextOverlay(profilerContent(profiler.Snapshot(time.Now())))
+	m.textOverlay.OnDismiss = func() {
+		profiler.SetLive(false)
+	}
+	m.state = stateHelp
+	return m, profilerTickCmd(m.textOverlay)
+}
+
+// profilerTickCmd waits for the next refresh of the profiler overlay.
+func profilerTickCmd(textOverlay *overlay.TextOverlay) tea.Cmd {
+	return tea.Tick(profilerRefreshInterval, func(time.Time) tea.Msg {
+		return profilerTickMsg{overlay: textOverlay}
+	})
+}
+
+// handleProfilerTick refreshes the profiler overlay while it is open.
+func (m *home) handleProfilerTick(msg profilerTickMsg) tea.Cmd {
+	if m.state != stateHelp || m.textOverlay != msg.overlay {
+		return nil
+	}
+	m.textOverlay.SetContent(profilerContent(log.GetProfiler().Snapshot(time.Now())))
+	return profilerTickCmd(msg.overlay)
+}
+
+// profilerContent renders the metrics of the profiler.
+func profilerContent(snapshot log.ProfileSnapshot) string {
+	lines := []string{
+		titleStyle.Render("Render Profiler"),
+		"",
+		headerStyle.Render("Frames:"),
+		descStyle.Render(fmt.Sprintf("%d fps, %d frames, %d slower than 16ms", snapshot.FPS, snapshot.Frames, snapshot.SlowFrames)),
+		descStyle.Render(fmt.Sprintf("Last 100: avg %s, max %s", formatProfileDuration(snapshot.RecentAvg), formatProfileDuration(snapshot.RecentMax))),
+		"",
+		headerStyle.Render("Components:"),
+	}
+
+	var components, captures []string
+	for _, metrics := range snapshot.Components {
+		if title, ok := strings.CutPrefix(metrics.Name, log.PollComponentPrefix); ok {
+			captures = append(captures, profileLine(title, metrics))
+		} else {
+			components = append(components, profileLine(metrics.Name, metrics))
+		}
+	}
+	if len(components) == 0 {
+		components = []string{descStyle.Render("no renders yet")}
+	}
+	lines = append(lines, components...)
+	lines = append(lines, "", headerStyle.Render("Capture latency:"))
+	if len(captures) == 0 {
+		captures = []string{descStyle.Render("no captures yet")}
+	}
+	lines = append(lines, captures...)
+
+	lines = append(lines,
+		"",
+		descStyle.Render("Refreshes every second. Press any key to close"),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// profileLine renders the timings of a component.
+func profileLine(name string, metrics log.ComponentMetrics) string {
+	var avg time.Duration
+	if metrics.RenderCount > 0 {
+		avg = metrics.TotalTime / time.Duration(metrics.RenderCount)
+	}
+	return keyStyle.Render(name) + descStyle.Render(fmt.Sprintf(" - last %s, avg %s, max %s (%d)",
+		formatProfileDuration(metrics.LastTime), formatProfileDuration(avg), formatProfileDuration(metrics.MaxTime), metrics.RenderCount))
+}
+
+// formatProfileDuration rounds a duration to a precision that fits it.
+func formatProfileDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"claude-squad/audit"
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newInitialPromptOverlay creates the overlay the first prompt of a new instance
+// is entered in. It is prefilled with the prompt the instance was created with,
+// e.g. from an issue, which is sent once submitted instead.
+func newInitialPromptOverlay(instance *session.Instance) *overlay.TextInputOverlay {
+	textInput := overlay.NewTextInputOverlay("Enter prompt", instance.Prompt)
+	textInput.FileLoadEnabled = true
+	instance.Prompt = ""
+	return textInput
+}
+
+// promptSentMsg is sent when a prompt has been typed into an instance and submitted.
+// The instance isn't changed while the prompt is typed in the background; the
+// prompt and the time it was submitted are recorded once this arrives.
+type promptSentMsg struct {
+	instance *session.Instance
+	title    string
+	prompt   string
+	sentAt   time.Time
+	err      error
+}
+
+// sendPrompt types the prompt into the instance in the background. Typing a long
+// prompt can take a while, so a notice is shown until it has been submitted.
+func (m *home) sendPrompt(instance *session.Instance, prompt string) tea.Cmd {
+	// Pending instances get their prompts once they are started
+	if instance.Status == session.Pending {
+		if instance.Prompt != "" {
+			prompt = instance.Prompt + "\n\n" + prompt
+		}
+		instance.Prompt = prompt
+		return tea.Batch(m.requestSave(), m.showInfo(fmt.Sprintf("'%s' is pending, the prompt is sent once it starts", instance.Title)))
+	}
+	m.errBox.SetInfo(sendingPromptInfo(instance.Title))
+	return typePrompt(instance, prompt)
+}
+
+// typePrompt returns the command typing the prompt into the instance.
+func typePrompt(instance *session.Instance, prompt string) tea.Cmd {
+	title := instance.Title
+	return func() tea.Msg {
+		err := instance.TypePrompt(prompt)
+		if err == nil || errors.Is(err, session.ErrPromptUnconfirmed) {
+			audit.Record(audit.EventPrompt, title, prompt)
+		}
+		return promptSentMsg{instance: instance, title: title, prompt: prompt, sentAt: time.Now(), err: err}
+	}
+}
+
+// handlePromptSent reports whether the prompt was delivered.
+func (m *home) handlePromptSent(msg promptSentMsg) tea.Cmd {
+	if m.errBox.Info() == sendingPromptInfo(msg.title) {
+		m.errBox.ClearInfo()
+	}
+	if msg.err != nil && !errors.Is(msg.err, session.ErrPromptUnconfirmed) {
+		return m.handleError(fmt.Errorf("failed to send prompt to '%s': %w", msg.title, msg.err))
+	}
+	msg.instance.RecordPrompt(msg.prompt, msg.sentAt)
+	if msg.err != nil {
+		return m.showInfo(fmt.Sprintf("Prompt submitted to '%s', but could not confirm delivery", msg.title))
+	}
+	return m.showInfo(fmt.Sprintf("Prompt delivered to '%s'", msg.title))
+}
+
+// sendingPromptInfo is the notice shown while a prompt is being typed.
+func sendingPromptInfo(title string) string {
+	return fmt.Sprintf("Sending prompt to '%s'…", title)
+}
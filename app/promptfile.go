@@ -0,0 +1,90 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// promptFileDepth is how many directories deep prompt files are looked for.
+	promptFileDepth = 4
+	// maxPromptFiles is how many prompt files the picker lists at most.
+	maxPromptFiles = 500
+)
+
+// promptFileExtensions are the extensions of the files offered as prompts.
+var promptFileExtensions = map[string]bool{".md": true, ".markdown": true, ".txt": true}
+
+// findPromptFiles returns the paths, relative to root, of the text files in it
+// that could hold a task description. Hidden and dependency directories are
+// skipped.
+func findPromptFiles(root string) []string {
+	var files []string
+	_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if len(files) == maxPromptFiles {
+			return fs.SkipAll
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" ||
+				strings.Count(rel, string(filepath.Separator)) >= promptFileDepth-1) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if promptFileExtensions[strings.ToLower(filepath.Ext(path))] {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files
+}
+
+// showPromptFilePicker lets the user pick a file to load into the prompt being
+// entered. The files of the selected instance's worktree are offered.
+func (m *home) showPromptFilePicker() (tea.Model, tea.Cmd) {
+	root := "."
+	if selected := m.list.GetSelectedInstance(); selected != nil {
+		root = selected.Path
+		if worktree, err := selected.GetGitWorktree(); err == nil && worktree != nil {
+			root = worktree.GetWorktreePath()
+		}
+	}
+	m.filePickerOverlay = overlay.NewFilePickerOverlay("Load prompt from file", root, findPromptFiles(root))
+	m.filePickerOverlay.SetWidth(max(m.termWidth*6/10, 60))
+	m.state = stateLoadPrompt
+	return m, nil
+}
+
+// handleLoadPromptState handles key presses while the prompt file picker is
+// shown. The picked file replaces the text of the prompt.
+func (m *home) handleLoadPromptState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.filePickerOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+
+	path := m.filePickerOverlay.Selected
+	m.filePickerOverlay = nil
+	m.state = statePrompt
+	if path == "" || m.textInputOverlay == nil {
+		return m, nil
+	}
+	prompt, err := session.ReadPromptFile(path, nil)
+	if err != nil {
+		return m, m.handleError(err)
+	}
+	m.textInputOverlay.SetValue(prompt)
+	return m, nil
+}
@@ -0,0 +1,27 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quarantineAcknowledgedMsg is sent once the user has cleared the quarantine
+// of an instance.
+type quarantineAcknowledgedMsg struct{}
+
+// acknowledgeQuarantine shows what put the selected instance in quarantine and
+// clears the quarantine once the user confirms they have looked into it.
+func (m *home) acknowledgeQuarantine() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || !selected.Quarantined() {
+		return m, nil
+	}
+
+	message := fmt.Sprintf("[!] '%s' was quarantined on %s: %s. Clear the quarantine? Auto-yes stays off.",
+		selected.Title, selected.QuarantinedAt.Format("2006-01-02 15:04"), selected.QuarantineReason)
+	return m, m.confirmAction(message, func() tea.Msg {
+		selected.AcknowledgeQuarantine()
+		return quarantineAcknowledgedMsg{}
+	})
+}
@@ -0,0 +1,34 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleRateLimits marks the instances that hit a rate limit and retries the
+// last prompt of those whose cooldown is over.
+func (m *home) handleRateLimits(results []session.UpdateResult) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, result := range results {
+		if result.Instance == nil || !result.RateLimited {
+			continue
+		}
+		result.Instance.MarkRateLimited(result.RateLimitedUntil)
+		log.InfoLog.Printf("%s is rate limited until %s", result.Instance.Title, result.RateLimitedUntil.Format(time.RFC3339))
+		cmds = append(cmds, m.requestSave(), m.showInfo(fmt.Sprintf("'%s' hit a rate limit, retrying at %s",
+			result.Instance.Title, result.RateLimitedUntil.Format("15:04"))))
+	}
+
+	now := time.Now()
+	for _, instance := range m.list.GetInstances() {
+		if prompt, ok := instance.RateLimitRetry(now); ok {
+			log.InfoLog.Printf("retrying the last prompt of %s after its rate limit", instance.Title)
+			cmds = append(cmds, m.requestSave(), m.sendPrompt(instance, prompt))
+		}
+	}
+	return tea.Batch(cmds...)
+}
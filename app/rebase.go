@@ -0,0 +1,187 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rebaseDoneMsg is sent when a rebase of an instance's branch has finished.
+type rebaseDoneMsg struct {
+	title string
+}
+
+// rebaseCommitsMsg is sent when the commits of an instance's branch have been
+// listed for the rebase assistant.
+type rebaseCommitsMsg struct {
+	instance *session.Instance
+	branch   string
+	commits  []git.RebaseCommit
+	err      error
+}
+
+// uncommittedSubject is the subject of the plan entry standing for the
+// uncommitted changes of the worktree. They are only committed once the
+// rebase is submitted.
+const uncommittedSubject = "(uncommitted changes)"
+
+// showRebase opens the interactive rebase assistant for the selected instance's
+// branch once its commits have been listed in the background. Uncommitted
+// changes are listed as a commit of their own so they can be squashed like any
+// other commit.
+func (m *home) showRebase() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || selected.Scratch || !selected.Started() || selected.Paused() {
+		return m, nil
+	}
+
+	worktree, err := selected.GetGitWorktree()
+	if err != nil {
+		return m, m.handleError(err)
+	}
+	if worktree == nil {
+		return m, m.handleError(fmt.Errorf("'%s' has no local worktree to rebase", selected.Title))
+	}
+
+	return m, func() tea.Msg {
+		msg := rebaseCommitsMsg{instance: selected, branch: worktree.GetBranchName()}
+		msg.commits, msg.err = worktree.BranchCommits()
+		if msg.err != nil {
+			return msg
+		}
+		dirty, err := worktree.IsDirty()
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		if dirty {
+			msg.commits = append(msg.commits, git.RebaseCommit{Subject: uncommittedSubject, Action: git.RebasePick})
+		}
+		return msg
+	}
+}
+
+// handleRebaseCommits opens the rebase assistant on the listed commits. Nothing
+// is opened if something else was opened meanwhile.
+func (m *home) handleRebaseCommits(msg rebaseCommitsMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.handleError(msg.err)
+	}
+	if m.state != stateDefault {
+		return nil
+	}
+	if len(msg.commits) == 0 {
+		return m.handleError(fmt.Errorf("branch %s has no commits to rebase", msg.branch))
+	}
+
+	m.rebaseOverlay = overlay.NewRebaseOverlay(fmt.Sprintf("Rebase %s", msg.branch), msg.commits)
+	m.rebaseOverlay.SetWidth(80)
+	m.state = stateRebase
+	return nil
+}
+
+// handleRebaseState handles key presses in the rebase assistant and runs the rebase once submitted.
+func (m *home) handleRebaseState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.rebaseOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+
+	rebaseOverlay := m.rebaseOverlay
+	m.rebaseOverlay = nil
+	m.state = stateDefault
+
+	selected := m.list.GetSelectedInstance()
+	if !rebaseOverlay.Submitted || selected == nil {
+		return m, nil
+	}
+
+	plan := rebaseOverlay.GetPlan()
+	return m, func() tea.Msg {
+		worktree, err := selected.GetGitWorktree()
+		if err != nil {
+			return err
+		}
+		if worktree == nil {
+			return fmt.Errorf("'%s' has no local worktree to rebase", selected.Title)
+		}
+		if err := commitUncommitted(worktree, selected.Title, plan); err != nil {
+			return err
+		}
+		if err := worktree.Rebase(plan); err != nil {
+			return err
+		}
+		log.InfoLog.Printf("rebased branch %s of %s", worktree.GetBranchName(), selected.Title)
+		return rebaseDoneMsg{title: selected.Title}
+	}
+}
+
+// commitUncommitted commits the uncommitted changes if the plan has an entry
+// for them, and points that entry at the new commit.
+func commitUncommitted(worktree *git.GitWorktree, title string, plan []git.RebaseCommit) error {
+	idx := slices.IndexFunc(plan, func(c git.RebaseCommit) bool { return c.SHA == "" })
+	if idx == -1 {
+		return nil
+	}
+	commitMsg := fmt.Sprintf("[claudesquad] update from '%s' on %s", title, time.Now().Format(time.RFC822))
+	if err := worktree.CommitChanges(commitMsg); err != nil {
+		return err
+	}
+	commits, err := worktree.BranchCommits()
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("no commit was made of the uncommitted changes")
+	}
+	plan[idx] = git.RebaseCommit{SHA: commits[len(commits)-1].SHA, Subject: commits[len(commits)-1].Subject, Action: plan[idx].Action}
+	return nil
+}
+
+// fixupDoneMsg reports the fixup commits created for an instance.
+type fixupDoneMsg struct {
+	created int
+	skipped []string
+}
+
+// String describes the result for the notice shown after creating fixups.
+func (f fixupDoneMsg) String() string {
+	info := fmt.Sprintf("Created %d fixup commit(s)", f.created)
+	if len(f.skipped) > 0 {
+		info += fmt.Sprintf(", left %d file(s) uncommitted: %s", len(f.skipped), strings.Join(f.skipped, ", "))
+	}
+	return info
+}
+
+// createFixupCommits routes the manual changes in the selected instance's worktree
+// to the branch commits that introduced the touched lines.
+func (m *home) createFixupCommits() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || selected.Scratch || !selected.Started() || selected.Paused() {
+		return m, nil
+	}
+
+	return m, func() tea.Msg {
+		worktree, err := selected.GetGitWorktree()
+		if err != nil {
+			return err
+		}
+		if worktree == nil {
+			return fmt.Errorf("'%s' has no local worktree to create fixups in", selected.Title)
+		}
+		created, skipped, err := worktree.CreateFixupCommits()
+		if err != nil {
+			return err
+		}
+		for _, fixup := range created {
+			log.InfoLog.Printf("created fixup of %.7s for %v in %s", fixup.Target.SHA, fixup.Files, selected.Title)
+		}
+		return fixupDoneMsg{created: len(created), skipped: skipped}
+	}
+}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handleRecoveryState handles key presses while the saved sessions could not
+// be loaded. Nothing is saved until one of the options succeeds, so the saved
+// sessions aren't overwritten by the empty list.
+func (m *home) handleRecoveryState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.recoveryOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+
+	var instances []*session.Instance
+	var info string
+	var err error
+	switch m.recoveryOverlay.Selected() {
+	case overlay.RecoveryQuit:
+		// Skip handleQuit, which saves the instances
+		return m, tea.Quit
+	case overlay.RecoveryRetry:
+		instances, err = m.reloadInstances()
+	case overlay.RecoveryLoadParsable:
+		var aside string
+		if aside, err = config.SetAsideState(); err != nil {
+			break
+		}
+		var skipped int
+		if instances, skipped, err = m.storage.LoadParsableInstances(); err == nil {
+			info = fmt.Sprintf("Skipped %d session(s) that could not be read, the previous state is kept at %s", skipped, aside)
+		}
+	case overlay.RecoveryRestoreBackup:
+		var aside string
+		if aside, err = config.SetAsideState(); err != nil {
+			break
+		}
+		if err = config.RestoreStateBackup(); err != nil {
+			break
+		}
+		if instances, err = m.reloadInstances(); err == nil {
+			info = fmt.Sprintf("Restored the sessions from the backup, the previous state is kept at %s", aside)
+		}
+	case overlay.RecoveryStartEmpty:
+		var aside string
+		if aside, err = config.SetAsideState(); err != nil {
+			break
+		}
+		if err = m.storage.DeleteAllInstances(); err != nil {
+			break
+		}
+		if instances, err = m.reloadInstances(); err == nil {
+			info = fmt.Sprintf("Started without sessions, the previous state is kept at %s", aside)
+		}
+	}
+	if err != nil {
+		log.ErrorLog.Printf("failed to recover instances: %v", err)
+		m.recoveryOverlay.SetError(err)
+		m.recoveryOverlay.SetStatus("That didn't work, try another option")
+		return m, nil
+	}
+
+	log.InfoLog.Printf("recovered %d instance(s)", len(instances))
+	m.recoveryOverlay = nil
+	m.state = stateDefault
+	m.addLoadedInstances(instances)
+	if err := config.BackupState(); err != nil {
+		log.WarningLog.Printf("%v", err)
+	}
+
+	cmds := []tea.Cmd{m.instanceChanged(), m.startDeferredCleanup()}
+	if info != "" {
+		cmds = append(cmds, m.showInfo(info))
+	}
+	if !m.fastStart {
+		cmds = append(cmds, m.restoreAll(m.unrestoredInstances(false)))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// reloadInstances reads the state from disk again and loads the instances.
+func (m *home) reloadInstances() ([]*session.Instance, error) {
+	state := config.LoadState()
+	storage, err := session.NewStorage(state)
+	if err != nil {
+		return nil, err
+	}
+	storage.SetDeferRestore(true)
+	m.appState, m.storage = state, storage
+	return storage.LoadInstances()
+}
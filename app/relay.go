@@ -0,0 +1,145 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/ui"
+	"claude-squad/ui/overlay"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultRelayLines is how many lines of output are relayed when relay_lines is not configured.
+const defaultRelayLines = 40
+
+// relayLines returns the configured number of output lines to relay.
+func (m *home) relayLines() int {
+	if m.appConfig != nil && m.appConfig.RelayLines > 0 {
+		return m.appConfig.RelayLines
+	}
+	return defaultRelayLines
+}
+
+// relayPrompt builds the prompt that forwards output from one session to another.
+func relayPrompt(source string, output string) string {
+	return fmt.Sprintf("Here is output from session '%s':\n\n%s\n\n", source, output)
+}
+
+// relayCapturedMsg is sent when the output of the instance to relay from has
+// been captured.
+type relayCapturedMsg struct {
+	source *session.Instance
+	output string
+	err    error
+}
+
+// startRelay captures the recent output of the selected instance in the
+// background, as the full history is dumped for it. The target session is
+// asked for once it is captured.
+func (m *home) startRelay() (tea.Model, tea.Cmd) {
+	source := m.list.GetSelectedInstance()
+	if source == nil || !source.Started() || source.Paused() {
+		return m, nil
+	}
+
+	lines := m.relayLines()
+	return m, func() tea.Msg {
+		output, err := source.RecentOutput(lines)
+		return relayCapturedMsg{source: source, output: output, err: err}
+	}
+}
+
+// handleRelayCaptured asks which session the captured output should be relayed
+// to. Nothing is asked if something else was opened meanwhile.
+func (m *home) handleRelayCaptured(msg relayCapturedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return m, m.handleError(fmt.Errorf("failed to capture output of '%s': %w", msg.source.Title, msg.err))
+	}
+	if m.state != stateDefault {
+		return m, nil
+	}
+	if msg.output == "" {
+		return m, m.handleError(fmt.Errorf("session '%s' has no output to relay", msg.source.Title))
+	}
+
+	return m.selectRelayTarget(msg.source, relayPrompt(msg.source.Title, msg.output))
+}
+
+// selectRelayTarget asks which running session (other than source) the prompt
+// should be sent to.
+func (m *home) selectRelayTarget(source *session.Instance, prompt string) (tea.Model, tea.Cmd) {
+	var targets []*session.Instance
+	var names []string
+	for _, instance := range m.list.GetInstances() {
+		if instance == source || !instance.Started() || instance.Paused() || instance.Tombstoned() {
+			continue
+		}
+		targets = append(targets, instance)
+		names = append(names, instance.Title)
+	}
+	if len(targets) == 0 {
+		m.state = stateDefault
+		return m, m.handleError(fmt.Errorf("no other running session to relay output to"))
+	}
+
+	m.relayOutput = prompt
+	m.relayTargets = targets
+	m.selectionOverlay = overlay.NewSelectionOverlay(fmt.Sprintf("Relay output of '%s' to", source.Title), names)
+	m.state = stateRelayTarget
+	return m, nil
+}
+
+// handleRelayTargetState handles key presses while choosing the session to relay to.
+func (m *home) handleRelayTargetState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.selectionOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+
+	idx := m.selectionOverlay.GetSelectedIndex()
+	m.selectionOverlay = nil
+	if idx < 0 {
+		m.resetRelay()
+		return m, nil
+	}
+
+	// Let the user edit the forwarded output and add instructions before sending
+	m.relayTarget = m.relayTargets[idx]
+	m.relayTargets = nil
+	m.state = stateRelayPrompt
+	m.menu.SetState(ui.StatePrompt)
+	m.textInputOverlay = overlay.NewTextInputOverlay(fmt.Sprintf("Relay to '%s'", m.relayTarget.Title), m.relayOutput)
+	return m, tea.WindowSize()
+}
+
+// handleRelayPromptState handles key presses while editing the relayed prompt.
+func (m *home) handleRelayPromptState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.textInputOverlay.HandleKeyPress(msg) {
+		if m.textInputOverlay.EditorRequested {
+			return m, m.editTextInput()
+		}
+		return m, nil
+	}
+
+	target := m.relayTarget
+	submitted := m.textInputOverlay.IsSubmitted()
+	prompt := m.textInputOverlay.GetValue()
+	m.textInputOverlay = nil
+	m.resetRelay()
+	m.menu.SetState(ui.StateDefault)
+
+	var sendCmd tea.Cmd
+	if submitted {
+		log.InfoLog.Printf("relaying %d bytes of output to %s", len(prompt), target.Title)
+		sendCmd = m.sendPrompt(target, prompt)
+	}
+	return m, tea.Batch(sendCmd, tea.WindowSize(), m.instanceChanged())
+}
+
+// resetRelay clears any in-progress relay and returns to the default state.
+func (m *home) resetRelay() {
+	m.relayOutput = ""
+	m.relayTargets = nil
+	m.relayTarget = nil
+	m.state = stateDefault
+}
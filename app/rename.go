@@ -0,0 +1,41 @@
+package app
+
+import (
+	"claude-squad/session"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// renameInstance renames the instance and saves the instances. If all is true,
+// its branch, worktree and session are renamed to match the new title too.
+func (m *home) renameInstance(instance *session.Instance, newTitle string, all bool) tea.Msg {
+	oldTitle := instance.Title
+	rename := instance.Rename
+	if all {
+		rename = instance.RenameAll
+	}
+	if err := rename(newTitle); err != nil {
+		return err
+	}
+	session.RenameReviewLinks(m.list.GetInstances(), oldTitle, newTitle)
+	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+		return err
+	}
+	return instanceChangedMsg{}
+}
+
+// confirmRenameAll asks whether to also rename the branch, worktree and session
+// of the instance. Declining still changes the title.
+func (m *home) confirmRenameAll(instance *session.Instance, newTitle string) tea.Cmd {
+	message := fmt.Sprintf("[!] Also rename the branch, worktree and session of '%s' to '%s'? Press n to only change the title.",
+		instance.Title, newTitle)
+	cmd := m.confirmAction(message, func() tea.Msg {
+		return m.renameInstance(instance, newTitle, true)
+	})
+	m.confirmationOverlay.OnCancel = func() {
+		m.state = stateDefault
+		m.confirmResult = m.renameInstance(instance, newTitle, false)
+	}
+	return cmd
+}
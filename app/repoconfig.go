@@ -0,0 +1,27 @@
+package app
+
+import (
+	"claude-squad/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// selectRepoDirectory continues creating an instance in the directory picked
+// in the file browser. The defaults of its repository are read first; the mode
+// selector is skipped if they set the session type.
+func (m *home) selectRepoDirectory(path string) (tea.Model, tea.Cmd) {
+	repoConfig, err := config.LoadRepoConfig(path)
+	if err != nil {
+		m.pendingInstancePath = ""
+		m.pendingIssue = nil
+		m.state = stateDefault
+		m.promptAfterName = false
+		return m, m.handleError(err)
+	}
+	m.pendingRepoConfig = repoConfig
+	if repoConfig != nil && repoConfig.SessionType != "" {
+		m.pendingSessionType = repoConfig.SessionType
+		return m.createInstanceWithPath(path)
+	}
+	return m.showModeSelector()
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// handlePromptResponseKey answers the prompt the selected agent is waiting on
+// with alt+y to accept, alt+a to always accept, alt+n to reject or alt+e to type
+// a reply. The keys are held with alt so they don't take over the keys of the
+// list while a prompt is pending. Always accepting has a key of its own, so
+// pressing a to type into the session can't grant the agent more permissions.
+func (m *home) handlePromptResponseKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || !selected.Started() || selected.Paused() || selected.PendingPrompt() == "" {
+		return false, nil
+	}
+	switch msg.String() {
+	case "alt+y":
+		return true, m.respondToPrompt(selected, session.PromptAccept)
+	case "alt+a":
+		return true, m.respondToPrompt(selected, session.PromptAlways)
+	case "alt+n":
+		return true, m.respondToPrompt(selected, session.PromptReject)
+	case "alt+e":
+		m.state = stateReplyPrompt
+		m.menu.SetState(ui.StateRename)
+		m.textInputOverlay = overlay.NewTextInputOverlay(fmt.Sprintf("Reply to '%s': %s", selected.Title, selected.PendingPrompt()), "")
+		return true, nil
+	}
+	return false, nil
+}
+
+// respondToPrompt accepts or rejects the prompt of the instance.
+func (m *home) respondToPrompt(instance *session.Instance, response session.PromptResponse) tea.Cmd {
+	if err := instance.RespondToPrompt(response); err != nil {
+		return m.handleError(err)
+	}
+	answer := "Accepted"
+	switch response {
+	case session.PromptReject:
+		answer = "Rejected"
+	case session.PromptAlways:
+		answer = "Always accepted"
+	}
+	return tea.Batch(m.showInfo(fmt.Sprintf("%s the prompt of '%s'", answer, instance.Title)), m.instanceChanged())
+}
+
+// handleReplyPromptState handles key presses while typing a reply to a prompt.
+// The prompt is rejected and the reply sent to the agent instead.
+func (m *home) handleReplyPromptState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	shouldClose := m.textInputOverlay.HandleKeyPress(msg)
+	if m.textInputOverlay.EditorRequested {
+		return m, m.editTextInput()
+	}
+	if !shouldClose {
+		return m, nil
+	}
+	submitted := m.textInputOverlay.IsSubmitted()
+	reply := strings.TrimSpace(m.textInputOverlay.GetValue())
+	m.textInputOverlay = nil
+	m.state = stateDefault
+	m.menu.SetState(ui.StateDefault)
+
+	selected := m.list.GetSelectedInstance()
+	if !submitted || reply == "" || selected == nil {
+		return m, tea.WindowSize()
+	}
+	if err := selected.RespondToPrompt(session.PromptReject); err != nil {
+		return m, m.handleError(err)
+	}
+	m.errBox.SetInfo(sendingPromptInfo(selected.Title))
+	return m, tea.Batch(typePrompt(selected, reply), tea.WindowSize())
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// staleInstancesMsg carries the instances the retention policy archives.
+type staleInstancesMsg struct {
+	instances []*session.Instance
+}
+
+// staleInstancesPausedMsg is sent once the archived stale instances have been
+// paused, with those that couldn't be.
+type staleInstancesPausedMsg struct {
+	failed []*session.Instance
+	err    error
+}
+
+// autoArchiveStale finds the instances that weren't opened for
+// auto_archive_days in the background, once their sessions are restored.
+func (m *home) autoArchiveStale() tea.Cmd {
+	if m.appConfig == nil || m.appConfig.AutoArchiveDays <= 0 {
+		return nil
+	}
+	days := m.appConfig.AutoArchiveDays
+	instances := m.list.GetInstances()
+	return func() tea.Msg {
+		retention := time.Duration(days) * 24 * time.Hour
+		return staleInstancesMsg{instances: session.StaleInstances(instances, retention, time.Now())}
+	}
+}
+
+// handleStaleInstances archives the stale instances. Like archiving a single
+// instance, archiving them is deferred so that u brings them all back. They
+// are paused in the background once archived.
+func (m *home) handleStaleInstances(msg staleInstancesMsg) tea.Cmd {
+	var stale []*session.Instance
+	for _, instance := range msg.instances {
+		// The instance may have been opened or archived in the meantime
+		if !instance.Archived && !instance.Tombstoned() {
+			stale = append(stale, instance)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	selected := m.list.GetSelectedInstance()
+	for _, instance := range stale {
+		instance.Archived = true
+	}
+	if selected == nil || selected.Archived || !m.list.SelectInstance(selected) {
+		m.list.ResetFilter()
+		m.list.SetSelectedInstance(0)
+	}
+
+	scheduled := m.deferAction(&pendingUndo{
+		description: fmt.Sprintf("Archived %d stale instance(s)", len(stale)),
+		undo: func() error {
+			for _, instance := range stale {
+				instance.Archived = false
+			}
+			return nil
+		},
+		commit: func() error {
+			var errs []error
+			for _, instance := range stale {
+				if err := m.storage.ArchiveInstance(instance.Title); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return errors.Join(errs...)
+		},
+		cleanup: func() tea.Msg {
+			var failed []*session.Instance
+			var errs []error
+			for _, instance := range stale {
+				if instance.Paused() {
+					continue
+				}
+				if err := instance.Pause(); err != nil {
+					failed = append(failed, instance)
+					errs = append(errs, fmt.Errorf("could not archive %s: %w", instance.Title, err))
+				}
+			}
+			return staleInstancesPausedMsg{failed: failed, err: errors.Join(errs...)}
+		},
+	})
+	return tea.Batch(m.instanceChanged(), func() tea.Msg { return scheduled })
+}
+
+// handleStaleInstancesPaused brings back the stale instances that couldn't be
+// paused.
+func (m *home) handleStaleInstancesPaused(msg staleInstancesPausedMsg) tea.Cmd {
+	if msg.err == nil {
+		return m.instanceChanged()
+	}
+	for _, instance := range msg.failed {
+		instance.Archived = false
+		if err := m.storage.UnarchiveInstance(instance.Title); err != nil {
+			log.WarningLog.Printf("could not unarchive %s: %v", instance.Title, err)
+		}
+	}
+	return tea.Batch(m.handleError(msg.err), m.instanceChanged())
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"claude-squad/audit"
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// reviewStartedMsg is sent when the reviewer instance of a review has started.
+type reviewStartedMsg struct {
+	reviewed *session.Instance
+	reviewer *session.Instance
+	prompt   string
+	err      error
+}
+
+// startReview asks a reviewer instance in the same repository to review the
+// changes of the selected instance. The reviewer is created on the first review
+// and reused when a review is requested again.
+func (m *home) startReview() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || !selected.Started() || selected.Paused() {
+		return m, nil
+	}
+	if selected.ReviewOf != "" {
+		return m, m.handleError(fmt.Errorf("'%s' is a reviewer, select the session it reviews", selected.Title))
+	}
+	worktree, err := selected.GetGitWorktree()
+	if err != nil || worktree == nil {
+		return m, m.handleError(fmt.Errorf("'%s' has no git worktree to review", selected.Title))
+	}
+
+	if err := selected.UpdateDiffStats(); err != nil {
+		return m, m.handleError(fmt.Errorf("failed to get the changes of '%s': %w", selected.Title, err))
+	}
+	stats := selected.GetDiffStats()
+	if stats == nil || stats.IsEmpty() {
+		return m, m.handleError(fmt.Errorf("'%s' has no changes to review", selected.Title))
+	}
+	prompt := session.ReviewPrompt(selected.Title, stats.Content)
+
+	// Ask the existing reviewer to review again
+	if reviewer := m.findInstance(selected.Reviewer); reviewer != nil && reviewer.Started() && !reviewer.Paused() {
+		selected.RequestReview()
+		return m, tea.Batch(m.sendPrompt(reviewer, prompt), m.requestSave())
+	}
+
+	title := session.ReviewTitle(selected.Title)
+	if err := session.ValidateTitle(title, nil, m.list.GetInstances()); err != nil {
+		return m, m.handleError(err)
+	}
+	reviewer, err := session.NewInstance(session.InstanceOptions{
+		Title:           title,
+		Path:            worktree.GetRepoPath(),
+		Program:         m.program,
+		SessionType:     selected.GetSessionType(),
+		DockerBaseImage: selected.DockerBaseImage,
+		DockerRepoURL:   selected.DockerRepoURL,
+		Owner:           m.appConfig.CurrentIdentity(),
+		ContextFile:     m.appConfig.ContextFile,
+		ContextTemplate: m.appConfig.ContextTemplate,
+		Prompt:          prompt,
+		Env:             selected.Env,
+	})
+	if err != nil {
+		return m, m.handleError(err)
+	}
+	reviewer.ReviewOf = selected.Title
+	finalize := m.list.AddInstance(reviewer)
+
+	m.loadingOverlay = overlay.NewLoadingOverlay("Creating Reviewer", &m.spinner)
+	m.loadingOverlay.SetWidth(50)
+	m.loadingOverlay.SetStatus("Initializing...")
+	m.state = stateLoading
+
+	return m, func() tea.Msg {
+		err := reviewer.StartWithProgress(true, func(status string) {
+			if m.loadingOverlay != nil {
+				m.loadingOverlay.SetStatus(status)
+			}
+		})
+		if err == nil {
+			finalize()
+		}
+		return reviewStartedMsg{reviewed: selected, reviewer: reviewer, prompt: prompt, err: err}
+	}
+}
+
+// handleReviewStarted links a started reviewer to the instance it reviews and
+// sends it the review prompt.
+func (m *home) handleReviewStarted(msg reviewStartedMsg) tea.Cmd {
+	m.loadingOverlay = nil
+	m.state = stateDefault
+	if msg.err != nil {
+		m.list.KillInstance(msg.reviewer)
+		return m.handleError(fmt.Errorf("failed to start reviewer for '%s': %w", msg.reviewed.Title, msg.err))
+	}
+
+	if m.autoYes {
+		msg.reviewer.AutoYes = true
+	}
+	msg.reviewed.Reviewer = msg.reviewer.Title
+	msg.reviewed.RequestReview()
+	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+		return m.handleError(err)
+	}
+	log.InfoLog.Printf("started reviewer %s for %s", msg.reviewer.Title, msg.reviewed.Title)
+	audit.Record(audit.EventCreated, msg.reviewer.Title, "reviewer of "+msg.reviewed.Title)
+	return tea.Batch(m.sendPrompt(msg.reviewer, msg.prompt), tea.WindowSize(), m.instanceChanged())
+}
+
+// handleReviewsUpdated reports the verdicts reviewers have given.
+func (m *home) handleReviewsUpdated(reviewed []*session.Instance) tea.Cmd {
+	if len(reviewed) == 0 {
+		return nil
+	}
+	for _, instance := range reviewed {
+		log.InfoLog.Printf("review of %s by %s: %s", instance.Title, instance.Reviewer, instance.ReviewStatus)
+	}
+	last := reviewed[len(reviewed)-1]
+	return tea.Batch(
+		m.showInfo(fmt.Sprintf("Review of '%s': %s", last.Title, last.ReviewStatus)),
+		m.requestSave(),
+	)
+}
+
+// findInstance returns the instance with the given title, or nil.
+func (m *home) findInstance(title string) *session.Instance {
+	if title == "" {
+		return nil
+	}
+	for _, instance := range m.list.GetInstances() {
+		if instance.Title == title && !instance.Tombstoned() {
+			return instance
+		}
+	}
+	return nil
+}
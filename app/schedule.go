@@ -0,0 +1,110 @@
+package app
+
+import (
+	"claude-squad/audit"
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/ui"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pendingStartedMsg is sent when the scheduler has started a pending instance.
+type pendingStartedMsg struct {
+	instance *session.Instance
+	err      error
+}
+
+// atRunningLimit returns true if no more instances may start working because
+// max_running of them already are.
+func (m *home) atRunningLimit() bool {
+	limit := m.appConfig.MaxRunning
+	if limit <= 0 {
+		return false
+	}
+	active := session.CountActive(m.list.GetInstances())
+	if m.startingPending {
+		active++
+	}
+	return active >= limit
+}
+
+// queueInstance puts a new instance in the Pending status instead of starting
+// it. The scheduler starts it once a slot frees.
+func (m *home) queueInstance(instance *session.Instance) tea.Cmd {
+	instance.SetStatus(session.Pending)
+	m.pendingFinalizers[instance] = m.newInstanceFinalizer
+	m.state = stateDefault
+	if m.promptAfterName {
+		// The prompt is kept with the instance and sent once it starts
+		m.state = statePrompt
+		m.menu.SetState(ui.StatePrompt)
+		m.textInputOverlay = newInitialPromptOverlay(instance)
+		m.promptAfterName = false
+	} else {
+		m.menu.SetState(ui.StateDefault)
+	}
+	return tea.Batch(
+		m.requestSave(),
+		m.instanceChanged(),
+		m.showInfo(fmt.Sprintf("'%s' is pending until fewer than %d agents are working", instance.Title, m.appConfig.MaxRunning)),
+	)
+}
+
+// startPending starts the pending instance that was created first in the
+// background, if a slot is free. Instances are started one at a time.
+func (m *home) startPending() tea.Cmd {
+	if m.startingPending || m.atRunningLimit() {
+		return nil
+	}
+	next := session.NextPending(m.list.GetInstances())
+	if next == nil {
+		return nil
+	}
+
+	m.startingPending = true
+	next.SetStatus(session.Loading)
+	log.InfoLog.Printf("starting pending instance %s", next.Title)
+	return func() tea.Msg {
+		return pendingStartedMsg{instance: next, err: next.Start(true)}
+	}
+}
+
+// handlePendingStarted sends the prompts queued for the started instance and
+// moves on to the next pending instance.
+func (m *home) handlePendingStarted(msg pendingStartedMsg) tea.Cmd {
+	m.startingPending = false
+	instance := msg.instance
+	if msg.err != nil {
+		delete(m.pendingFinalizers, instance)
+		m.list.KillInstance(instance)
+		return tea.Batch(
+			m.handleError(fmt.Errorf("failed to start pending instance '%s': %w", instance.Title, msg.err)),
+			m.requestSave(),
+			m.instanceChanged(),
+			m.startPending(),
+		)
+	}
+
+	if finalize, ok := m.pendingFinalizers[instance]; ok {
+		delete(m.pendingFinalizers, instance)
+		finalize()
+	}
+	audit.Record(audit.EventCreated, instance.Title, instance.Branch)
+	if m.autoYes {
+		instance.AutoYes = true
+	}
+
+	cmds := []tea.Cmd{m.requestSave(), m.instanceChanged(), m.startPending()}
+	if err := instance.InstallError(); err != nil {
+		cmds = append(cmds, m.handleError(err))
+	}
+	if prompt := instance.Prompt; prompt != "" {
+		instance.Prompt = ""
+		cmds = append(cmds, m.sendPrompt(instance, prompt))
+	} else {
+		cmds = append(cmds, m.showInfo(fmt.Sprintf("Started pending instance '%s'", instance.Title)))
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"claude-squad/config"
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionClosedForSwitchMsg is sent when the session of a paused instance was
+// closed, for its session type to be switched.
+type sessionClosedForSwitchMsg struct {
+	instance    *session.Instance
+	sessionType string
+	dockerImage string
+	err         error
+}
+
+// showModeSwitch opens the mode selector to switch the session type of the
+// selected paused instance.
+func (m *home) showModeSwitch() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || selected.Scratch || !selected.Started() {
+		return m, nil
+	}
+	if !selected.Paused() {
+		return m, m.handleError(fmt.Errorf("pause '%s' before switching its session mode", selected.Title))
+	}
+	m.modeSwitchInstance = selected
+	m.modeSelectorOverlay = overlay.NewModeSwitchOverlay(selected.GetSessionType())
+	m.modeSelectorOverlay.SetWidth(60)
+	m.state = stateModeSelect
+	return m, nil
+}
+
+// handleModeSwitchState handles key presses in the mode selector while it
+// switches the session type of an instance, asking before the old session is
+// closed.
+func (m *home) handleModeSwitchState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.modeSelectorOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+	instance := m.modeSwitchInstance
+	sessionType := m.modeSelectorOverlay.GetSelected()
+	m.modeSelectorOverlay = nil
+	m.modeSwitchInstance = nil
+	m.state = stateDefault
+	if sessionType == "" {
+		return m, nil
+	}
+
+	dockerImage := m.appConfig.DockerBaseImage
+	if repoConfig, err := config.LoadRepoConfig(instance.Path); err == nil && repoConfig != nil && repoConfig.DockerImage != "" {
+		dockerImage = repoConfig.DockerImage
+	}
+	if err := instance.CheckSessionTypeSwitch(sessionType, dockerImage); err != nil {
+		return m, m.handleError(err)
+	}
+	message := fmt.Sprintf("[!] Switch '%s' from %s to %s? Its %s session is closed and resuming starts a %s one.",
+		instance.Title, instance.GetSessionType(), sessionType, instance.GetSessionType(), sessionType)
+	return m, m.confirmAction(message, func() tea.Msg {
+		return sessionClosedForSwitchMsg{
+			instance:    instance,
+			sessionType: sessionType,
+			dockerImage: dockerImage,
+			err:         instance.CloseSessionForSwitch(sessionType, dockerImage),
+		}
+	})
+}
+
+// handleSessionClosedForSwitch switches the session type of the instance once
+// its old session was closed. The instance is changed here rather than in the
+// command, since the rest of the TUI reads it meanwhile.
+func (m *home) handleSessionClosedForSwitch(msg sessionClosedForSwitchMsg) tea.Cmd {
+	if msg.err == nil {
+		msg.err = msg.instance.SwitchSessionType(msg.sessionType, msg.dockerImage)
+	}
+	if msg.err != nil {
+		return m.handleError(msg.err)
+	}
+	info := fmt.Sprintf("Switched '%s' to %s, resume it with r", msg.instance.Title, msg.sessionType)
+	return tea.Batch(m.showInfo(info), m.requestSave(), m.instanceChanged())
+}
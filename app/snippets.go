@@ -0,0 +1,78 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// snippetsCapturedMsg is sent when the latest snippets of an instance have been captured.
+type snippetsCapturedMsg struct {
+	instance *session.Instance
+}
+
+// showSnippets captures the latest snippets of the selected instance in the
+// background, as the full history is dumped for them, and then opens the
+// snippet browser.
+func (m *home) showSnippets() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+
+	if !selected.Started() || selected.Paused() {
+		return m, m.openSnippets(selected)
+	}
+	return m, func() tea.Msg {
+		if err := selected.CaptureSnippets(); err != nil {
+			log.WarningLog.Printf("could not capture snippets for %s: %v", selected.Title, err)
+		}
+		return snippetsCapturedMsg{instance: selected}
+	}
+}
+
+// openSnippets opens the snippet browser on the snippets of the instance.
+// Nothing is opened if something else was opened meanwhile.
+func (m *home) openSnippets(instance *session.Instance) tea.Cmd {
+	if m.state != stateDefault {
+		return nil
+	}
+	m.snippetsOverlay = overlay.NewSnippetsOverlay(fmt.Sprintf("Snippets from '%s'", instance.Title), instance.Snippets())
+	m.snippetsOverlay.SetWidth(80)
+	m.state = stateSnippets
+	return nil
+}
+
+// handleSnippetsState handles key presses while browsing snippets.
+func (m *home) handleSnippetsState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	shouldClose := m.snippetsOverlay.HandleKeyPress(msg)
+
+	if m.snippetsOverlay.CopyRequested {
+		m.snippetsOverlay.CopyRequested = false
+		if snippet, ok := m.snippetsOverlay.GetSelected(); ok {
+			if err := clipboard.WriteAll(snippet.Content); err != nil {
+				return m, m.handleError(fmt.Errorf("failed to copy snippet: %w", err))
+			}
+			m.snippetsOverlay.SetStatus("Copied to clipboard")
+		}
+	}
+
+	if !shouldClose {
+		return m, nil
+	}
+
+	snippetsOverlay := m.snippetsOverlay
+	m.snippetsOverlay = nil
+	m.state = stateDefault
+
+	snippet, ok := snippetsOverlay.GetSelected()
+	source := m.list.GetSelectedInstance()
+	if !snippetsOverlay.SendRequested || !ok || source == nil {
+		return m, nil
+	}
+	return m.selectRelayTarget(source, relayPrompt(source.Title, snippet.Content))
+}
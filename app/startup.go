@@ -0,0 +1,250 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startupBudget is how long startup may take until the first paint before it is reported.
+const startupBudget = 500 * time.Millisecond
+
+// restoreWorkers is how many saved sessions are restored at once.
+const restoreWorkers = 4
+
+// startupCompleteMsg is sent once the first frame has been drawn.
+type startupCompleteMsg struct{}
+
+// startupCompleteCmd reports the end of startup. Bubble Tea draws the first frame
+// before it runs the commands returned by Init, so this arrives after first paint.
+func startupCompleteCmd() tea.Msg {
+	return startupCompleteMsg{}
+}
+
+// handleStartupComplete reports the startup time and starts the subsystems that
+// were deferred until after the first paint.
+func (m *home) handleStartupComplete() tea.Cmd {
+	elapsed := time.Since(m.startedAt)
+	log.InfoLog.Printf("startup took %s until first paint", elapsed.Round(time.Millisecond))
+
+	cmds := []tea.Cmd{tickUpdateSummaryCmd}
+	if elapsed > startupBudget {
+		info := fmt.Sprintf("Startup took %s, over the %s budget", elapsed.Round(10*time.Millisecond), startupBudget)
+		if !m.fastStart {
+			info += ", try --fast-start"
+		}
+		log.WarningLog.Print(info)
+		cmds = append(cmds, m.showInfo(info))
+	}
+
+	cmds = append(cmds, m.startDeferredCleanup())
+	// With --fast-start, sessions are restored once selected instead
+	if !m.fastStart {
+		cmds = append(cmds, m.restoreAll(m.unrestoredInstances(false)))
+	}
+	if m.fastStart || len(m.unrestoredInstances(false)) == 0 {
+		m.restartStopped = true
+		cmds = append(cmds, m.autoArchiveStale())
+	}
+	return tea.Batch(cmds...)
+}
+
+// tombstoneCleanedMsg is sent when an instance killed during a previous run has
+// been cleaned up.
+type tombstoneCleanedMsg struct {
+	instance *session.Instance
+	err      error
+}
+
+// startDeferredCleanup cleans up instances killed during a previous run and purges
+// expired trash in the background.
+func (m *home) startDeferredCleanup() tea.Cmd {
+	var cmds []tea.Cmd
+	if len(m.pendingCleanup) > 0 {
+		log.InfoLog.Printf("cleaning up %d killed instance(s)", len(m.pendingCleanup))
+	}
+	trashRetentionDays := m.appConfig.TrashRetentionDays
+	for _, instance := range m.pendingCleanup {
+		cmds = append(cmds, func() tea.Msg {
+			return tombstoneCleanedMsg{instance: instance, err: instance.Kill(trashRetentionDays)}
+		})
+	}
+	m.pendingCleanup = nil
+
+	if m.appConfig.TrashRetentionDays > 0 {
+		go func() {
+			if n, err := git.PurgeExpiredTrash(m.appConfig.TrashRetentionDays, m.appConfig.CurrentIdentity()); err != nil {
+				log.WarningLog.Printf("failed to purge trash: %v", err)
+			} else if n > 0 {
+				log.InfoLog.Printf("purged %d expired trash entries", n)
+			}
+		}()
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleTombstoneCleaned removes an instance killed during a previous run from
+// the list and from storage once it has been cleaned up. If that failed, it is
+// kept to be cleaned up again on the next start.
+func (m *home) handleTombstoneCleaned(msg tombstoneCleanedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.handleError(fmt.Errorf("failed to clean up killed instance '%s': %w", msg.instance.Title, msg.err))
+	}
+	m.list.RemoveInstance(msg.instance)
+	if err := m.storage.DeleteInstance(msg.instance.Title); err != nil {
+		return m.handleError(fmt.Errorf("failed to remove killed instance '%s' from storage: %w", msg.instance.Title, err))
+	}
+	return nil
+}
+
+// instanceRestoredMsg is sent when the session of a saved instance has been
+// restored.
+type instanceRestoredMsg struct {
+	instance *session.Instance
+	err      error
+	// startup is true if the session was restored by restoreAll
+	startup bool
+}
+
+// unrestoredInstances returns the instances whose session hasn't been restored
+// yet. With failed set, only those whose restore failed are returned.
+func (m *home) unrestoredInstances(failed bool) []*session.Instance {
+	var instances []*session.Instance
+	for _, instance := range m.list.GetInstances() {
+		if instance.RestorePending() && (!failed || instance.RestoreError() != nil) {
+			instances = append(instances, instance)
+		}
+	}
+	return instances
+}
+
+// restoreAll restores the sessions of the given instances in the background,
+// restoreWorkers at a time, and shows the progress. Sessions that can't be
+// restored are reported once all are done.
+func (m *home) restoreAll(instances []*session.Instance) tea.Cmd {
+	if len(instances) == 0 {
+		return nil
+	}
+	m.startupOverlay = overlay.NewStartupOverlay(len(instances))
+	m.startupOverlay.SetWidth(max(m.termWidth*6/10, 60))
+	if m.state == stateDefault {
+		m.state = stateStartup
+	}
+
+	slots := make(chan struct{}, restoreWorkers)
+	cmds := make([]tea.Cmd, 0, len(instances))
+	for _, instance := range instances {
+		cmds = append(cmds, func() tea.Msg {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			return instanceRestoredMsg{instance: instance, err: instance.Restore(), startup: true}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleStartupRestored records the progress of restoreAll and closes the
+// startup overlay once done, unless some sessions failed to restore.
+func (m *home) handleStartupRestored(msg instanceRestoredMsg) tea.Cmd {
+	if m.startupOverlay == nil {
+		return nil
+	}
+	m.startupOverlay.Restored(msg.instance.Title, msg.err)
+	if msg.err != nil {
+		log.ErrorLog.Print(msg.err)
+	}
+	if !m.startupOverlay.Done() {
+		return nil
+	}
+	// Sessions may have survived while their programs didn't, e.g. after the
+	// machine was turned off
+	m.restartStopped = true
+	archive := m.autoArchiveStale()
+
+	failed := m.startupOverlay.Failed()
+	if failed == 0 {
+		m.startupOverlay = nil
+		if m.state == stateStartup {
+			m.state = stateDefault
+		}
+		return archive
+	}
+	log.WarningLog.Printf("%d session(s) could not be restored", failed)
+	switch m.state {
+	case stateStartup:
+		return archive
+	case stateDefault:
+		m.state = stateStartup
+		return archive
+	}
+	// Don't interrupt another overlay with the report
+	m.startupOverlay = nil
+	return tea.Batch(archive, m.showInfo(fmt.Sprintf("%d session(s) could not be restored, select them to see why", failed)))
+}
+
+// handleStartupState handles key presses while the startup overlay is shown.
+// Closing it while sessions are being restored continues in the background.
+func (m *home) handleStartupState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	shouldClose := m.startupOverlay.HandleKeyPress(msg)
+
+	if m.startupOverlay.RetryRequested {
+		m.startupOverlay = nil
+		m.state = stateDefault
+		return m, m.restoreAll(m.unrestoredInstances(true))
+	}
+
+	if shouldClose {
+		if m.startupOverlay.Done() {
+			m.startupOverlay = nil
+		}
+		m.state = stateDefault
+	}
+	return m, nil
+}
+
+// restoreSelected restores the session of the selected instance if it hasn't
+// been restored yet and is not being restored already. Sessions that failed to
+// restore are only retried from the startup report.
+func (m *home) restoreSelected() tea.Cmd {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || !selected.RestorePending() || selected.RestoreError() != nil || m.restoring == selected {
+		return nil
+	}
+
+	m.restoring = selected
+	return func() tea.Msg {
+		return instanceRestoredMsg{instance: selected, err: selected.Restore()}
+	}
+}
+
+// handleInstanceRestored finishes restoring an instance's session.
+func (m *home) handleInstanceRestored(msg instanceRestoredMsg) tea.Cmd {
+	var progress tea.Cmd
+	if msg.startup {
+		progress = m.handleStartupRestored(msg)
+	} else if m.restoring == msg.instance {
+		m.restoring = nil
+	}
+	if msg.err != nil {
+		if msg.startup {
+			return tea.Batch(progress, m.instanceChanged())
+		}
+		return m.handleError(msg.err)
+	}
+	// The repo name of the instance is known now that it has a session
+	if finalize, ok := m.pendingFinalizers[msg.instance]; ok {
+		delete(m.pendingFinalizers, msg.instance)
+		finalize()
+	}
+	// Size the restored session to the preview
+	previewWidth, previewHeight := m.tabbedWindow.GetPreviewSize()
+	if err := msg.instance.SetPreviewSize(previewWidth, previewHeight); err != nil {
+		log.WarningLog.Printf("could not resize restored session %s: %v", msg.instance.Title, err)
+	}
+	return tea.Batch(progress, m.instanceChanged())
+}
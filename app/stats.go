@@ -0,0 +1,97 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxStatsRepos is how many of the busiest repositories are listed in the dashboard.
+const maxStatsRepos = 3
+
+// statsUnpushedMsg is sent when the instances with unpushed commits have been
+// found for the statistics dashboard.
+type statsUnpushedMsg struct {
+	unpushed map[*session.Instance]bool
+}
+
+// showStats opens the squad statistics dashboard once the instances with
+// unpushed commits have been found in the background.
+func (m *home) showStats() (tea.Model, tea.Cmd) {
+	instances := slices.Clone(m.list.GetInstances())
+	return m, func() tea.Msg {
+		return statsUnpushedMsg{unpushed: session.UnpushedInstances(instances)}
+	}
+}
+
+// handleStatsUnpushed opens the statistics dashboard. Nothing is opened if
+// something else was opened meanwhile.
+func (m *home) handleStatsUnpushed(msg statsUnpushedMsg) tea.Cmd {
+	if m.state != stateDefault {
+		return nil
+	}
+	stats := session.ComputeSquadStats(m.list.GetInstances(), msg.unpushed, time.Now())
+
+	m.textOverlay = overlay.NewTextOverlay(statsContent(stats))
+	m.state = stateHelp
+	return nil
+}
+
+// statsContent renders the statistics dashboard.
+func statsContent(stats session.SquadStats) string {
+	var statuses []string
+	for _, status := range []session.Status{session.Running, session.Ready, session.Loading, session.Paused, session.Pending} {
+		statuses = append(statuses, fmt.Sprintf("%s %s", keyStyle.Render(fmt.Sprint(stats.StatusCounts[status])), status))
+	}
+	statuses = append(statuses, fmt.Sprintf("%s archived", keyStyle.Render(fmt.Sprint(stats.Archived))))
+
+	lines := []string{
+		titleStyle.Render("Squad Statistics"),
+		"",
+		headerStyle.Render("Sessions:"),
+		descStyle.Render(strings.Join(statuses, "  ")),
+		"",
+		headerStyle.Render("Changes:"),
+		descStyle.Render(fmt.Sprintf("+%d / -%d lines across all sessions", stats.Added, stats.Removed)),
+		"",
+		headerStyle.Render("Busiest repos:"),
+	}
+
+	if len(stats.Repos) == 0 {
+		lines = append(lines, descStyle.Render("none"))
+	}
+	for i, repo := range stats.Repos {
+		if i == maxStatsRepos {
+			break
+		}
+		lines = append(lines, keyStyle.Render(repo.Name)+descStyle.Render(
+			fmt.Sprintf(" - %d session(s), +%d / -%d", repo.Instances, repo.Added, repo.Removed)))
+	}
+
+	avgToReady := "no data yet"
+	if stats.ReadyTransitions > 0 {
+		avgToReady = fmt.Sprintf("%s (%d samples)", stats.AvgTimeToReady.Round(time.Second), stats.ReadyTransitions)
+	}
+	oldestUnpushed := "none"
+	if instance := stats.OldestUnpushed; instance != nil {
+		oldestUnpushed = fmt.Sprintf("%s (%s, created %s)", instance.Title, instance.Branch, ui.FormatRelativeTime(instance.CreatedAt))
+	}
+
+	lines = append(lines,
+		"",
+		headerStyle.Render("Activity:"),
+		descStyle.Render("Average time to ready:   "+avgToReady),
+		descStyle.Render(fmt.Sprintf("Auto-yes answers today:  %d", stats.AutoYesToday)),
+		descStyle.Render("Oldest un-pushed branch: "+oldestUnpushed),
+		"",
+		descStyle.Render("Press any key to close"),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
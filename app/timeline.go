@@ -0,0 +1,99 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+const (
+	// timelineWindow is how far back the timeline goes.
+	timelineWindow = 7 * 24 * time.Hour
+	// timelineTitleWidth is the width of the session titles before the bars.
+	timelineTitleWidth = 20
+)
+
+// timelineCells are the characters and styles the timeline draws its cells with.
+var timelineCells = map[session.TimelineCell]string{
+	session.TimelineNone:    " ",
+	session.TimelineIdle:    ui.TextStyles.Muted.Render("─"),
+	session.TimelineRunning: ui.StatusStyles.Running.Render("█"),
+	session.TimelineReady:   ui.StatusStyles.Warning.Render("▒"),
+	session.TimelinePaused:  ui.StatusStyles.Paused.Render("░"),
+	session.TimelinePushed:  ui.StatusStyles.Success.Render("↑"),
+}
+
+// showTimeline opens a timeline of the activity of all sessions over the last week.
+func (m *home) showTimeline() (tea.Model, tea.Cmd) {
+	width := max(m.termWidth*9/10, 60)
+	// Leave room for the border, padding and the titles
+	slots := width - 6 - timelineTitleWidth - 1
+
+	m.textOverlay = overlay.NewTextOverlay(timelineContent(m.list.GetInstances(), time.Now(), slots))
+	m.textOverlay.SetWidth(width)
+	m.state = stateHelp
+	return m, nil
+}
+
+// timelineContent renders the activity of the instances over the timeline window
+// ending now, as a bar of the given number of slots per instance.
+func timelineContent(instances []*session.Instance, now time.Time, slots int) string {
+	start := now.Add(-timelineWindow)
+	rows := session.ComputeTimeline(instances, start, now, slots)
+
+	lines := []string{
+		titleStyle.Render("Timeline"),
+		descStyle.Render(fmt.Sprintf("Session activity over the last %d days, %s per column",
+			int(timelineWindow.Hours()/24), strings.TrimSuffix((timelineWindow/time.Duration(slots)).Round(time.Minute).String(), "0s"))),
+		"",
+		strings.Repeat(" ", timelineTitleWidth+1) + timelineAxis(start, now, slots),
+	}
+
+	if len(rows) == 0 {
+		lines = append(lines, descStyle.Render("No sessions"))
+	}
+	for _, row := range rows {
+		title := truncate.StringWithTail(row.Instance.Title, timelineTitleWidth, "...")
+		var bar strings.Builder
+		for _, cell := range row.Cells {
+			bar.WriteString(timelineCells[cell])
+		}
+		lines = append(lines, keyStyle.Render(fmt.Sprintf("%-*s", timelineTitleWidth, title))+" "+bar.String())
+	}
+
+	lines = append(lines,
+		"",
+		descStyle.Render("Legend: ")+timelineCells[session.TimelineRunning]+descStyle.Render(" running  ")+
+			timelineCells[session.TimelineReady]+descStyle.Render(" waiting  ")+
+			timelineCells[session.TimelinePaused]+descStyle.Render(" paused  ")+
+			timelineCells[session.TimelineIdle]+descStyle.Render(" no activity recorded  ")+
+			timelineCells[session.TimelinePushed]+descStyle.Render(" pushed"),
+		"",
+		descStyle.Render("Press any key to close"),
+	)
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// timelineAxis labels the slots where each day starts with the day's name.
+func timelineAxis(start, end time.Time, slots int) string {
+	axis := []rune(strings.Repeat(" ", slots))
+	slot := end.Sub(start) / time.Duration(slots)
+
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location()).AddDate(0, 0, 1)
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		label := []rune("|" + day.Format("Mon 2"))
+		at := int(day.Sub(start) / slot)
+		if at+len(label) > slots {
+			break
+		}
+		copy(axis[at:], label)
+	}
+	return descStyle.Render(string(axis))
+}
@@ -0,0 +1,21 @@
+package app
+
+import (
+	"claude-squad/session"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updateTerminalTitle sets the title of the terminal window to a summary of
+// the squad when enabled and it changed.
+func (m *home) updateTerminalTitle() tea.Cmd {
+	if !m.appConfig.TerminalTitle {
+		return nil
+	}
+	title := session.SquadStatusLine(m.list.GetInstances())
+	if title == m.terminalTitle {
+		return nil
+	}
+	m.terminalTitle = title
+	return tea.SetWindowTitle(title)
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// undoWindow is how long a destructive action can be undone before it is carried out.
+const undoWindow = 30 * time.Second
+
+// pendingUndo is a destructive action whose side effects have been deferred so
+// that it can still be undone.
+type pendingUndo struct {
+	id int
+	// description is shown in the undo notice, e.g. "Killed 'foo'".
+	description string
+	// undo reverts the visible effects of the action.
+	undo func() error
+	// commit carries out the deferred side effects, if any.
+	commit func() error
+	// cleanup carries out the slow part of the side effects in the background
+	// once the action is committed, e.g. removing the worktree of a killed instance.
+	cleanup tea.Cmd
+	// deferOnQuit leaves the action pending when quitting instead of committing it.
+	// Used for kills, whose tombstones are cleaned up on the next start.
+	deferOnQuit bool
+}
+
+// undoScheduledMsg is returned by an action after it has been deferred; it starts the undo timer.
+type undoScheduledMsg struct {
+	id int
+	// cleanup is the background cleanup of the previous action committed to make room, if any.
+	cleanup tea.Cmd
+}
+
+// undoExpiredMsg is sent when the undo window of a deferred action has passed.
+type undoExpiredMsg struct {
+	id int
+}
+
+// deferAction registers a destructive action that will be committed once the undo
+// window passes. Only one action can be pending: any previous one is committed now.
+func (m *home) deferAction(action *pendingUndo) tea.Msg {
+	cleanup, err := m.commitPendingUndo()
+	if err != nil {
+		log.ErrorLog.Printf("failed to commit pending action: %v", err)
+	}
+
+	m.undoSeq++
+	action.id = m.undoSeq
+	m.pendingUndo = action
+	m.errBox.SetInfo(fmt.Sprintf("%s - press u to undo", action.description))
+	return undoScheduledMsg{id: action.id, cleanup: cleanup}
+}
+
+// undoTimer returns a command that expires the given deferred action after the undo window.
+func (m *home) undoTimer(id int) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case <-m.ctx.Done():
+		case <-time.After(undoWindow):
+		}
+		return undoExpiredMsg{id: id}
+	}
+}
+
+// commitPendingUndo carries out the pending action, if any. The returned command
+// runs the background cleanup of the action.
+func (m *home) commitPendingUndo() (tea.Cmd, error) {
+	action := m.pendingUndo
+	if action == nil {
+		return nil, nil
+	}
+	m.pendingUndo = nil
+	m.errBox.ClearInfo()
+	if action.commit != nil {
+		if err := action.commit(); err != nil {
+			return nil, err
+		}
+	}
+	return action.cleanup, nil
+}
+
+// undoPendingAction reverts the pending action, if any.
+func (m *home) undoPendingAction() tea.Cmd {
+	action := m.pendingUndo
+	if action == nil {
+		return nil
+	}
+	m.pendingUndo = nil
+	m.errBox.ClearInfo()
+	if err := action.undo(); err != nil {
+		return m.handleError(fmt.Errorf("failed to undo: %w", err))
+	}
+	return tea.Batch(m.instanceChanged(), m.requestSave())
+}
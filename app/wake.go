@@ -0,0 +1,73 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// wakeGap is how much longer than the metadata tick interval, by the wall
+// clock, the time between two ticks may be before the machine is assumed to
+// have been asleep.
+const wakeGap = time.Minute
+
+// detectWake is called on every metadata tick. When the machine was asleep
+// since the last tick, the programs of all instances are checked on this tick
+// and restarted if they stopped.
+func (m *home) detectWake() {
+	// Round(0) drops the monotonic clock reading, which doesn't advance while
+	// the machine sleeps on every system, so the wall clock is compared
+	now := time.Now().Round(0)
+	last := m.lastMetadataTick
+	m.lastMetadataTick = now
+	if last.IsZero() {
+		return
+	}
+	interval := configuredInterval(m.appConfig.MetadataTickInterval, time.Millisecond, defaultMetadataTickInterval)
+	if gap := now.Sub(last); gap > interval+wakeGap {
+		log.InfoLog.Printf("no metadata tick for %s, the machine was probably asleep", gap.Round(time.Second))
+		m.restartStopped = true
+	}
+}
+
+// handleProgramsRestarted reports the instances whose stopped program was
+// restarted, e.g. after the machine woke up, instead of leaving them to be
+// found one by one.
+func (m *home) handleProgramsRestarted(results []session.RestartResult) tea.Cmd {
+	if len(results) == 0 {
+		return nil
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			failed++
+			log.ErrorLog.Printf("failed to restart the program of %s: %v", result.Instance.Title, result.Error)
+		} else {
+			log.InfoLog.Printf("restarted the stopped program of %s", result.Instance.Title)
+		}
+	}
+	cmds := []tea.Cmd{m.requestSave(), m.instanceChanged()}
+
+	// Don't interrupt another overlay with the report
+	if m.state != stateDefault || (m.bulkOverlay != nil && !m.bulkOverlay.Done()) {
+		if failed > 0 {
+			cmds = append(cmds, m.handleError(fmt.Errorf("%d stopped session(s) could not be restarted, see the log for why", failed)))
+		} else {
+			cmds = append(cmds, m.showInfo(fmt.Sprintf("Restarted %d stopped session(s)", len(results))))
+		}
+		return tea.Batch(cmds...)
+	}
+
+	m.bulkOverlay = overlay.NewBulkOverlay("Restarting", len(results))
+	m.bulkOverlay.SetNote("These agents had stopped, e.g. while the machine was asleep or off, and were restarted, resuming their conversations where possible.")
+	m.bulkOverlay.SetWidth(max(m.termWidth*6/10, 60))
+	for _, result := range results {
+		m.bulkOverlay.Finished(result.Instance.Title, result.Error)
+	}
+	m.state = stateBulk
+	return tea.Batch(cmds...)
+}
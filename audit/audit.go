@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"bufio"
+	"claude-squad/config"
+	"claude-squad/log"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the audit log in the config directory.
+const FileName = "audit.jsonl"
+
+// Kinds of events.
+const (
+	EventCreated    = "created"
+	EventPrompt     = "prompt"
+	EventAutoYes    = "auto-yes"
+	EventPush       = "push"
+	EventKill       = "kill"
+	EventKillUndone = "kill-undone"
+
+	EventQuarantined       = "quarantined"
+	EventQuarantineCleared = "quarantine-cleared"
+)
+
+// Initiators of events, i.e. the kind of process that recorded them.
+const (
+	InitiatorTUI    = "tui"
+	InitiatorDaemon = "daemon"
+	InitiatorCLI    = "cli"
+)
+
+// maxDetailLength caps the detail of an event, e.g. a long prompt.
+const maxDetailLength = 200
+
+// Event is a notable action on an instance.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"event"`
+	Instance  string    `json:"instance"`
+	Initiator string    `json:"initiator"`
+	// Identity is the person using claude-squad, see config.Config.Identity.
+	Identity string `json:"identity,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+var (
+	mu sync.Mutex
+	// path is the audit log, or empty until Initialize is called, so that
+	// tests don't write to the user's log.
+	path      string
+	initiator string
+	identity  string
+)
+
+// Initialize enables recording to the audit log in the config directory, with
+// the initiator and identity of this process.
+func Initialize(processInitiator, processIdentity string) error {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	path = filepath.Join(configDir, FileName)
+	initiator = processInitiator
+	identity = processIdentity
+	return nil
+}
+
+// Record appends an event to the audit log. Failures are logged rather than
+// returned, since they shouldn't stop the action being recorded.
+func Record(kind, instance, detail string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if path == "" {
+		return
+	}
+	if err := record(kind, instance, detail); err != nil {
+		log.WarningLog.Printf("failed to record %s of %s in the audit log: %v", kind, instance, err)
+	}
+}
+
+// record appends an event to the audit log at path.
+func record(kind, instance, detail string) error {
+	// Keep each event on one line
+	detail = strings.Join(strings.Fields(detail), " ")
+	if runes := []rune(detail); len(runes) > maxDetailLength {
+		detail = string(runes[:maxDetailLength-1]) + "…"
+	}
+	line, err := json.Marshal(Event{
+		Time:      time.Now(),
+		Kind:      kind,
+		Instance:  instance,
+		Initiator: initiator,
+		Identity:  identity,
+		Detail:    detail,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	// Appends of a single line are atomic, so the TUI, the daemon and the CLI
+	// can write to the log at the same time
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Read returns the events in the audit log, oldest first. Lines that can't be
+// parsed are skipped.
+func Read() ([]Event, error) {
+	mu.Lock()
+	logPath := path
+	mu.Unlock()
+	if logPath == "" {
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		logPath = filepath.Join(configDir, FileName)
+	}
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return events, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return events, nil
+}
+
+// String formats the event as a line of text.
+func (e Event) String() string {
+	line := fmt.Sprintf("%s  %-11s %-8s %s", e.Time.Format("2006-01-02 15:04:05"), e.Kind, e.Initiator, e.Instance)
+	if e.Identity != "" {
+		line += " @" + e.Identity
+	}
+	if e.Detail != "" {
+		line += ": " + e.Detail
+	}
+	return line
+}
+
+// Export writes the events as a JSON array to a timestamped file in the config
+// directory and returns its path.
+func Export(events []Event) (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit log: %w", err)
+	}
+	exportPath := filepath.Join(configDir, fmt.Sprintf("audit-export-%s.json", time.Now().Format("20060102-150405")))
+	if err := os.WriteFile(exportPath, append(data, '\n'), 0600); err != nil {
+		return "", fmt.Errorf("failed to export audit log: %w", err)
+	}
+	return exportPath, nil
+}
@@ -0,0 +1,36 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Nothing is recorded before Initialize
+	Record(EventCreated, "ignored", "")
+
+	if err := Initialize(InitiatorDaemon, "alice"); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	t.Cleanup(func() { path = "" })
+	Record(EventCreated, "login", "")
+	Record(EventPrompt, "login", "fix the\nlogin "+strings.Repeat("x", 300))
+
+	events, err := Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Read() returned %d events, want 2: %+v", len(events), events)
+	}
+	if events[0].Kind != EventCreated || events[0].Instance != "login" ||
+		events[0].Initiator != InitiatorDaemon || events[0].Identity != "alice" {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	detail := events[1].Detail
+	if !strings.HasPrefix(detail, "fix the login x") || len([]rune(detail)) != maxDetailLength {
+		t.Errorf("detail = %q, want one line of %d characters", detail, maxDetailLength)
+	}
+}
@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"bytes"
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/watch"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// requestTimeout bounds posting a notification to the webhook.
+const requestTimeout = 10 * time.Second
+
+// Bot posts to a Slack or Discord channel when an instance needs attention and
+// runs the slash commands sent from it.
+type Bot struct {
+	cfg    *config.BotConfig
+	client *http.Client
+	// secret is the signing secret of the Slack app
+	secret string
+	// publicKey verifies the interactions of the Discord application
+	publicKey ed25519.PublicKey
+
+	// mu guards the instances, which the daemon updates while commands run
+	mu        sync.Mutex
+	instances []*session.Instance
+	watcher   *watch.Watcher
+}
+
+// New creates a bot for the instances the daemon polls.
+func New(cfg *config.BotConfig, instances []*session.Instance) (*Bot, error) {
+	b := &Bot{
+		cfg:       cfg,
+		client:    &http.Client{Timeout: requestTimeout},
+		instances: instances,
+		watcher:   watch.NewWatcher(),
+	}
+	switch cfg.Platform {
+	case config.BotPlatformSlack:
+		b.secret = os.Getenv(config.BotSigningSecretEnv)
+		if cfg.ListenAddress != "" && b.secret == "" {
+			return nil, fmt.Errorf("slash commands need the signing secret of the Slack app in %s", config.BotSigningSecretEnv)
+		}
+	case config.BotPlatformDiscord:
+		if cfg.ListenAddress == "" {
+			break
+		}
+		key, err := hex.DecodeString(cfg.PublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("slash commands need the public key of the Discord application in public_key")
+		}
+		b.publicKey = key
+	default:
+		return nil, fmt.Errorf("unknown bot platform %q, use %q or %q", cfg.Platform, config.BotPlatformSlack, config.BotPlatformDiscord)
+	}
+	return b, nil
+}
+
+// Observe applies the results of polling the instances, with the same rules as
+// the TUI, and notifies the channel of instances that started waiting for
+// input or finished working.
+func (b *Bot) Observe(results []session.UpdateResult, now time.Time) {
+	b.mu.Lock()
+	prompts := make(map[*session.Instance]bool)
+	for _, result := range results {
+		if result.Instance == nil {
+			continue
+		}
+		result.Instance.SetPendingPrompt(result.PromptExcerpt)
+		if result.Updated {
+			result.Instance.SetStatus(session.Running)
+		} else if result.HasPrompt {
+			// Prompts accepted by auto-yes don't need anyone
+			prompts[result.Instance] = !result.Instance.AutoYes || result.Instance.Quarantined()
+		} else {
+			result.Instance.SetStatus(session.Ready)
+		}
+	}
+	events := b.watcher.Observe(b.instances, prompts, now)
+	var messages []string
+	for _, event := range events {
+		if message := b.attentionMessage(event); message != "" {
+			messages = append(messages, message)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, message := range messages {
+		b.post(message)
+	}
+}
+
+// attentionMessage returns the notification for the event, or an empty string
+// if the event doesn't need attention.
+func (b *Bot) attentionMessage(event watch.Event) string {
+	switch event.Kind {
+	case watch.EventPrompt:
+		message := fmt.Sprintf("'%s' needs attention: it is waiting for input", event.Instance)
+		if instance := b.instance(event.Instance); instance != nil && instance.PendingPrompt() != "" {
+			message += fmt.Sprintf(" (%s)", instance.PendingPrompt())
+		}
+		return message + fmt.Sprintf(". Answer with approve %s or send %s <prompt>.", event.Instance, event.Instance)
+	case watch.EventFinished:
+		return fmt.Sprintf("'%s' needs attention: it finished working. Continue with send %s <prompt>.", event.Instance, event.Instance)
+	}
+	return ""
+}
+
+// instance returns the instance with the title, or nil. b.mu must be held.
+func (b *Bot) instance(title string) *session.Instance {
+	for _, instance := range b.instances {
+		if instance.Title == title {
+			return instance
+		}
+	}
+	return nil
+}
+
+// post sends the message to the webhook of the channel in the background.
+func (b *Bot) post(message string) {
+	if b.cfg.WebhookURL == "" {
+		return
+	}
+	field := "text"
+	if b.cfg.Platform == config.BotPlatformDiscord {
+		field = "content"
+	}
+	body, err := json.Marshal(map[string]string{field: message})
+	if err != nil {
+		log.ErrorLog.Printf("failed to encode the bot message: %v", err)
+		return
+	}
+	go func() {
+		resp, err := b.client.Post(b.cfg.WebhookURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.ErrorLog.Printf("failed to post to the bot webhook: %v", err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			log.ErrorLog.Printf("failed to post to the bot webhook: %s", resp.Status)
+		}
+	}()
+}
+
+// Serve receives slash commands on the listen address until ctx is done. It
+// returns right away if commands are off.
+func (b *Bot) Serve(ctx context.Context) error {
+	if b.cfg.ListenAddress == "" {
+		return nil
+	}
+	handler := http.HandlerFunc(b.handleSlack)
+	if b.cfg.Platform == config.BotPlatformDiscord {
+		handler = b.handleDiscord
+	}
+	server := &http.Server{Addr: b.cfg.ListenAddress, Handler: handler, ReadHeaderTimeout: requestTimeout}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.InfoLog.Printf("receiving %s commands on %s", b.cfg.Platform, b.cfg.ListenAddress)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to receive bot commands: %w", err)
+	}
+	return nil
+}
+
+// allowed returns true if the user with the given Slack or Discord ID may run
+// slash commands.
+func (b *Bot) allowed(user string) bool {
+	return user != "" && slices.Contains(b.cfg.AllowedUsers, user)
+}
+
+// writeJSON writes the value as the JSON response.
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.ErrorLog.Printf("failed to write the bot response: %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package bot
+
+import (
+	"bytes"
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+
+	login := &session.Instance{Title: "login", Status: session.Ready}
+	loginForm := &session.Instance{Title: "login form", Status: session.Running}
+	archived := &session.Instance{Title: "old", Status: session.Paused, Archived: true}
+	b, err := New(&config.BotConfig{Platform: config.BotPlatformSlack}, []*session.Instance{login, loginForm, archived})
+	require.NoError(t, err)
+
+	assert.Equal(t, "login: ready\nlogin form: running", b.Execute("list"))
+	assert.Equal(t, usage, b.Execute("dance"))
+
+	instance, prompt := b.find("Login Form fix the tests")
+	assert.Same(t, loginForm, instance)
+	assert.Equal(t, "fix the tests", prompt)
+	instance, prompt = b.find("login formatting")
+	assert.Same(t, login, instance)
+	assert.Equal(t, "formatting", prompt)
+	instance, _ = b.find("signup")
+	assert.Nil(t, instance)
+
+	assert.Equal(t, "'login form' is not running", b.Execute("send login form fix the tests"))
+	assert.Equal(t, "Usage: send <title> <prompt>", b.Execute("send login"))
+	assert.Equal(t, "'login' is not waiting for input", b.Execute("approve login"))
+	assert.Contains(t, b.Execute("approve signup"), "No session matches")
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv(config.BotSigningSecretEnv, "")
+	_, err := New(&config.BotConfig{Platform: "irc"}, nil)
+	assert.Error(t, err)
+	_, err = New(&config.BotConfig{Platform: config.BotPlatformSlack, ListenAddress: "127.0.0.1:8642"}, nil)
+	assert.ErrorContains(t, err, config.BotSigningSecretEnv)
+	_, err = New(&config.BotConfig{Platform: config.BotPlatformDiscord, ListenAddress: "127.0.0.1:8642", PublicKey: "abc"}, nil)
+	assert.Error(t, err)
+	// Notifications alone need no credentials
+	_, err = New(&config.BotConfig{Platform: config.BotPlatformDiscord, WebhookURL: "https://discord.com/api/webhooks/1/x"}, nil)
+	assert.NoError(t, err)
+}
+
+func TestObserveNotifiesAttention(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+
+	posted := make(chan map[string]string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&message)
+		posted <- message
+	}))
+	defer server.Close()
+
+	working := &session.Instance{Title: "api", Status: session.Running}
+	asking := &session.Instance{Title: "docs", Status: session.Running}
+	b, err := New(&config.BotConfig{Platform: config.BotPlatformDiscord, WebhookURL: server.URL}, []*session.Instance{working, asking})
+	require.NoError(t, err)
+	now := time.Now()
+
+	// The first observation only records the instances
+	b.Observe([]session.UpdateResult{{Instance: working, Updated: true}, {Instance: asking, Updated: true}}, now)
+	b.Observe([]session.UpdateResult{
+		{Instance: working},
+		{Instance: asking, HasPrompt: true, PromptExcerpt: "Allow rm -rf build?"},
+	}, now)
+
+	var messages []string
+	for range 2 {
+		select {
+		case message := <-posted:
+			messages = append(messages, message["content"])
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected a notification for each instance")
+		}
+	}
+	assert.ElementsMatch(t, []string{
+		"'api' needs attention: it finished working. Continue with send api <prompt>.",
+		"'docs' needs attention: it is waiting for input (Allow rm -rf build?). Answer with approve docs or send docs <prompt>.",
+	}, messages)
+	assert.Equal(t, session.Ready, working.Status)
+	assert.Equal(t, "Allow rm -rf build?", asking.PendingPrompt())
+}
+
+func TestVerifySlack(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	body := []byte("command=%2Fcs&text=list")
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("v0:" + timestamp + ":" + string(body)))
+	signature := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.True(t, verifySlack("secret", timestamp, signature, body, now))
+	assert.False(t, verifySlack("other", timestamp, signature, body, now))
+	assert.False(t, verifySlack("secret", timestamp, signature, []byte("command=%2Fcs&text=send"), now))
+	assert.False(t, verifySlack("secret", timestamp, signature, body, now.Add(10*time.Minute)))
+}
+
+func TestHandleDiscord(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	b, err := New(&config.BotConfig{
+		Platform:      config.BotPlatformDiscord,
+		ListenAddress: "127.0.0.1:0",
+		PublicKey:     hex.EncodeToString(publicKey),
+		AllowedUsers:  []string{"80351110224678912"},
+	}, []*session.Instance{{Title: "api", Status: session.Ready}})
+	require.NoError(t, err)
+
+	send := func(body string, sign bool) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+		timestamp := "1760000000"
+		signature := ed25519.Sign(privateKey, []byte(timestamp+body))
+		if !sign {
+			signature = ed25519.Sign(privateKey, []byte(timestamp+"{}"))
+		}
+		request.Header.Set("X-Signature-Timestamp", timestamp)
+		request.Header.Set("X-Signature-Ed25519", hex.EncodeToString(signature))
+		recorder := httptest.NewRecorder()
+		b.handleDiscord(recorder, request)
+		return recorder
+	}
+
+	response := send(`{"type":1}`, true)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"type":1}`, response.Body.String())
+
+	response = send(`{"type":2,"member":{"user":{"id":"80351110224678912"}},"data":{"name":"cs","options":[{"name":"command","type":3,"value":"list"}]}}`, true)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"type":4,"data":{"content":"api: ready","flags":64}}`, response.Body.String())
+
+	// Commands from other users, and from unknown ones, aren't run
+	for _, body := range []string{
+		`{"type":2,"member":{"user":{"id":"1234"}},"data":{"name":"cs","options":[{"name":"command","type":3,"value":"list"}]}}`,
+		`{"type":2,"data":{"name":"cs","options":[{"name":"command","type":3,"value":"list"}]}}`,
+	} {
+		response = send(body, true)
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.Contains(t, response.Body.String(), "not allowed")
+		assert.NotContains(t, response.Body.String(), "api: ready")
+	}
+
+	response = send(`{"type":1}`, false)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.Contains(t, response.Body.String(), "invalid signature")
+}
+
+func TestHandleSlack(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+	t.Setenv(config.BotSigningSecretEnv, "secret")
+
+	instances := []*session.Instance{{Title: "api", Status: session.Ready}}
+	send := func(b *Bot, user string) string {
+		body := "command=%2Fcs&text=list&user_id=" + user
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte("v0:" + timestamp + ":" + body))
+		request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+		request.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		request.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+		recorder := httptest.NewRecorder()
+		b.handleSlack(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		return recorder.Body.String()
+	}
+
+	b, err := New(&config.BotConfig{
+		Platform:      config.BotPlatformSlack,
+		ListenAddress: "127.0.0.1:0",
+		AllowedUsers:  []string{"U024BE7LH"},
+	}, instances)
+	require.NoError(t, err)
+	assert.Contains(t, send(b, "U024BE7LH"), "api: ready")
+	assert.Contains(t, send(b, "U0G9QF9C6"), "not allowed")
+
+	// Nobody may run commands until allowed_users is set
+	b, err = New(&config.BotConfig{Platform: config.BotPlatformSlack, ListenAddress: "127.0.0.1:0"}, instances)
+	require.NoError(t, err)
+	assert.Contains(t, send(b, "U024BE7LH"), "not allowed")
+}
@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"claude-squad/audit"
+	"claude-squad/log"
+	"claude-squad/session"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// usage lists the commands the bot understands.
+const usage = "Commands: list, send <title> <prompt>, approve <title>"
+
+// notAllowedMessage is the reply to commands from users who aren't in
+// allowed_users.
+const notAllowedMessage = "You are not allowed to run commands. Add your user ID to allowed_users in the bot config."
+
+// Execute runs a command sent from the channel and returns the reply. Prompts
+// are sent in the background, since the platforms expect a reply within
+// seconds; failures are posted to the channel.
+func (b *Bot) Execute(text string) string {
+	command, args, _ := strings.Cut(strings.TrimSpace(text), " ")
+	args = strings.TrimSpace(args)
+	switch strings.ToLower(command) {
+	case "list":
+		return b.list()
+	case "send":
+		instance, prompt := b.find(args)
+		if instance == nil {
+			return fmt.Sprintf("No session matches %q. %s", args, usage)
+		}
+		if prompt == "" {
+			return "Usage: send <title> <prompt>"
+		}
+		if !instance.Started() || instance.Paused() {
+			return fmt.Sprintf("'%s' is not running", instance.Title)
+		}
+		go func() {
+			err := instance.SendPrompt(prompt)
+			if errors.Is(err, session.ErrPromptUnconfirmed) {
+				b.post(fmt.Sprintf("Sent the prompt to '%s', but could not confirm it was received", instance.Title))
+			} else if err != nil {
+				log.ErrorLog.Printf("failed to send the prompt from the bot to %s: %v", instance.Title, err)
+				b.post(fmt.Sprintf("Failed to send the prompt to '%s': %v", instance.Title, err))
+				return
+			}
+			audit.Record(audit.EventPrompt, instance.Title, prompt)
+		}()
+		return fmt.Sprintf("Sending the prompt to '%s'", instance.Title)
+	case "approve":
+		instance, rest := b.find(args)
+		if instance == nil || rest != "" {
+			return fmt.Sprintf("No session matches %q. %s", args, usage)
+		}
+		return b.approve(instance)
+	}
+	return usage
+}
+
+// approve accepts the prompt the instance is waiting on.
+func (b *Bot) approve(instance *session.Instance) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if instance.PendingPrompt() == "" {
+		return fmt.Sprintf("'%s' is not waiting for input", instance.Title)
+	}
+	if err := instance.RespondToPrompt(session.PromptAccept); err != nil {
+		return fmt.Sprintf("Failed to approve the prompt of '%s': %v", instance.Title, err)
+	}
+	return fmt.Sprintf("Approved the prompt of '%s'", instance.Title)
+}
+
+// list describes the status of each instance, one per line.
+func (b *Bot) list() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var lines []string
+	for _, instance := range b.instances {
+		if instance.Tombstoned() || instance.Archived {
+			continue
+		}
+		line := fmt.Sprintf("%s: %s", instance.Title, instance.Status.String())
+		if excerpt := instance.PendingPrompt(); excerpt != "" {
+			line += fmt.Sprintf(", waiting for input (%s)", excerpt)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return "No sessions"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// find returns the instance whose title starts the arguments, ignoring case,
+// and the rest of them. Titles may contain spaces, so the longest title that
+// matches wins.
+func (b *Bot) find(args string) (*session.Instance, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var found *session.Instance
+	for _, instance := range b.instances {
+		title := instance.Title
+		if instance.Tombstoned() || len(args) < len(title) || !strings.EqualFold(args[:len(title)], title) {
+			continue
+		}
+		if len(args) > len(title) && args[len(title)] != ' ' && args[len(title)] != '\n' {
+			continue
+		}
+		if found == nil || len(title) > len(found.Title) {
+			found = instance
+		}
+	}
+	if found == nil {
+		return nil, ""
+	}
+	return found, strings.TrimSpace(args[len(found.Title):])
+}
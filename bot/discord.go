@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Types of Discord interactions and of the responses to them
+const (
+	discordPing                     = 1
+	discordApplicationCommand       = 2
+	discordPong                     = 1
+	discordChannelMessageWithSource = 4
+	// discordEphemeral is the message flag that shows the reply only to the
+	// user who sent the command
+	discordEphemeral = 1 << 6
+)
+
+// discordInteraction is the part of a Discord interaction the bot reads.
+type discordInteraction struct {
+	Type int `json:"type"`
+	// Member is who sent the command in a server, User who sent it in a DM
+	Member struct {
+		User discordUser `json:"user"`
+	} `json:"member"`
+	User discordUser `json:"user"`
+	Data struct {
+		Options []struct {
+			Value any `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+}
+
+// discordUser is the part of a Discord user the bot reads.
+type discordUser struct {
+	ID string `json:"id"`
+}
+
+// handleDiscord runs a Discord slash command, e.g. /cs command:list, and
+// replies to the user who sent it.
+func (b *Bot) handleDiscord(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, "failed to read the request", http.StatusBadRequest)
+		return
+	}
+	if !verifyDiscord(b.publicKey, r.Header.Get("X-Signature-Timestamp"), r.Header.Get("X-Signature-Ed25519"), body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var interaction discordInteraction
+	if err := json.Unmarshal(body, &interaction); err != nil {
+		http.Error(w, "invalid interaction", http.StatusBadRequest)
+		return
+	}
+
+	switch interaction.Type {
+	case discordPing:
+		writeJSON(w, map[string]any{"type": discordPong})
+	case discordApplicationCommand:
+		// The options of the command are its words, e.g. a single text option
+		// holding "send login fix the form"
+		var words []string
+		for _, option := range interaction.Data.Options {
+			words = append(words, fmt.Sprint(option.Value))
+		}
+		user := interaction.Member.User.ID
+		if user == "" {
+			user = interaction.User.ID
+		}
+		reply := notAllowedMessage
+		if b.allowed(user) {
+			reply = b.Execute(strings.Join(words, " "))
+		}
+		writeJSON(w, map[string]any{
+			"type": discordChannelMessageWithSource,
+			"data": map[string]any{"content": reply, "flags": discordEphemeral},
+		})
+	default:
+		http.Error(w, "unsupported interaction", http.StatusBadRequest)
+	}
+}
+
+// verifyDiscord checks that the request was signed by the Discord application.
+func verifyDiscord(publicKey ed25519.PublicKey, timestamp, signature string, body []byte) bool {
+	sig, err := hex.DecodeString(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize || len(publicKey) != ed25519.PublicKeySize {
+		return false
+	}
+	return ed25519.Verify(publicKey, append([]byte(timestamp), body...), sig)
+}
@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRequestSize bounds the body of a slash command request.
+	maxRequestSize = 64 << 10
+	// maxRequestAge is how old a signed request may be, to refuse replays.
+	maxRequestAge = 5 * time.Minute
+)
+
+// handleSlack runs a Slack slash command, e.g. /cs list, and replies to the
+// user who sent it.
+func (b *Bot) handleSlack(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, "failed to read the request", http.StatusBadRequest)
+		return
+	}
+	if !verifySlack(b.secret, r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature"), body, time.Now()) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid command", http.StatusBadRequest)
+		return
+	}
+	reply := notAllowedMessage
+	if b.allowed(form.Get("user_id")) {
+		reply = b.Execute(form.Get("text"))
+	}
+	writeJSON(w, map[string]string{"response_type": "ephemeral", "text": reply})
+}
+
+// verifySlack checks that the request was signed with the signing secret of
+// the Slack app, recently.
+func verifySlack(secret, timestamp, signature string, body []byte, now time.Time) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
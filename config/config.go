@@ -32,11 +32,30 @@ const (
 	SessionTypeZellij      = "zellij"
 	SessionTypeDockerBind  = "docker-bind"
 	SessionTypeDockerClone = "docker-clone"
+	// SessionTypeNative runs the program directly under a pseudo terminal, for
+	// systems without zellij such as Windows
+	SessionTypeNative = "native"
+)
+
+// Summary mode constants
+const (
+	SummaryModeTerminal   = "terminal"
+	SummaryModeTranscript = "transcript"
+	SummaryModeCommand    = "command"
+)
+
+// Dependency install mode constants
+const (
+	InstallDependenciesAsk  = "ask"
+	InstallDependenciesAuto = "auto"
+	InstallDependenciesOff  = "off"
 )
 
 // Config represents the application configuration
 type Config struct {
-	// DefaultProgram is the default program to run in new instances
+	// DefaultProgram is the default program to run in new instances. The
+	// placeholders {branch}, {title}, {worktree} and {repo} are replaced when
+	// the session starts.
 	DefaultProgram string `json:"default_program"`
 	// AutoYes is a flag to automatically accept all prompts.
 	AutoYes bool `json:"auto_yes"`
@@ -51,8 +70,227 @@ type Config struct {
 	// Example: "ubuntu:24.04"
 	DockerBaseImage string `json:"docker_base_image"`
 	// DefaultSessionType controls the default session type for new instances.
-	// Valid values: "zellij", "docker-bind", "docker-clone"
+	// Valid values: "zellij", "docker-bind", "docker-clone", "native"
 	DefaultSessionType string `json:"default_session_type"`
+	// KillRescuePatterns are glob patterns for untracked files that should be moved
+	// back to the main checkout instead of being deleted when an instance is killed.
+	// Example: [".env", "*.md"]
+	KillRescuePatterns []string `json:"kill_rescue_patterns,omitempty"`
+	// TrashRetentionDays keeps the branch and a bundle of a killed instance's work in
+	// ~/.claude-squad/trash for this many days instead of deleting it immediately.
+	// 0 disables the trash.
+	TrashRetentionDays int `json:"trash_retention_days,omitempty"`
+	// RelayLines is how many trailing lines of output are forwarded when relaying
+	// one session's output to another. Defaults to 40 when unset.
+	RelayLines int `json:"relay_lines,omitempty"`
+	// SummaryMode selects how instance summaries are generated.
+	// Valid values: "terminal" (default) parses the pane output, "transcript" reads
+	// Claude's session transcript and falls back to the pane output without one,
+	// "command" asks SummaryCommand to summarize the pane output.
+	SummaryMode string `json:"summary_mode,omitempty"`
+	// SummaryCommand is run with the recent pane output on stdin and prints a
+	// one-line summary. Defaults to "claude -p" when unset.
+	SummaryCommand string `json:"summary_command,omitempty"`
+	// SummaryCommandInterval is the minimum number of seconds between two runs of
+	// SummaryCommand for the same instance. Defaults to 60 when unset.
+	SummaryCommandInterval int `json:"summary_command_interval,omitempty"`
+	// DiffRenderer is an external command used to render the diff tab, e.g. "delta"
+	// or "difft". Other commands read the unified diff on stdin and write the
+	// rendered diff to stdout. The built-in renderer is used when unset or when
+	// the command is unavailable.
+	DiffRenderer string `json:"diff_renderer,omitempty"`
+	// WorktreePoolSize is how many worktrees are pre-created for each repository
+	// instances are created in, so that new instances can claim one instantly.
+	// 0 disables the pool.
+	WorktreePoolSize int `json:"worktree_pool_size,omitempty"`
+	// FetchBeforeCreate fetches from the origin remote and fast-forwards the
+	// local default branch before the worktree of a new instance is created from
+	// it, so that it starts from the latest upstream code. A failed fetch, e.g. when
+	// offline, doesn't stop the instance from being created.
+	FetchBeforeCreate bool `json:"fetch_before_create,omitempty"`
+	// NoSharedCaches ignores the shared_caches of repository configs, so that
+	// every worktree fills its own caches, e.g. when builds running at once in
+	// several worktrees would clobber each other.
+	NoSharedCaches bool `json:"no_shared_caches,omitempty"`
+	// LandCheckCommand is run in an instance's worktree after its branch has been
+	// rebased onto the default branch by the merge queue, e.g. "make test". The
+	// branch only lands if the command succeeds. No check is run when unset.
+	LandCheckCommand string `json:"land_check_command,omitempty"`
+	// HistoryMaxLines is how many lines of scrollback history are loaded when
+	// scrolling the preview. Earlier history is loaded in steps of this size when
+	// scrolling past the top. Defaults to 5000 when unset.
+	HistoryMaxLines int `json:"history_max_lines,omitempty"`
+	// HistoryMaxBytes caps the size of the scrollback history loaded at once,
+	// growing in the same steps as HistoryMaxLines. Defaults to 4 MiB when unset.
+	HistoryMaxBytes int `json:"history_max_bytes,omitempty"`
+	// CheckCommand is run in an instance's worktree on demand, e.g. "make test".
+	// Its pass/fail result is shown in the list and its output in the Checks tab.
+	CheckCommand string `json:"check_command,omitempty"`
+	// OpenCommand opens an instance's worktree in an editor or IDE, e.g.
+	// "code {path}", "cursor {path}" or "idea {path}". {path} is replaced by the
+	// worktree path, which is appended when the command doesn't contain it.
+	OpenCommand string `json:"open_command,omitempty"`
+	// Forge selects the service hosting the remote for pushing and opening
+	// branches: "github", "gitlab" or "bitbucket". When unset it is detected
+	// from the origin remote URL, falling back to GitHub.
+	Forge string `json:"forge,omitempty"`
+	// Identity names the person using claude-squad when several people share one
+	// account, e.g. on a lab machine. New instances are tagged with it, the list
+	// can be filtered to your instances or others', and cleanup commands only
+	// touch your instances. The CLAUDE_SQUAD_IDENTITY environment variable takes
+	// precedence.
+	Identity string `json:"identity,omitempty"`
+	// InstallDependencies controls installing the dependencies of new instances
+	// whose repository has a manifest like package.json, go.mod or pyproject.toml,
+	// before the program starts. Valid values: "ask" (default) confirms the
+	// install commands first, "auto" runs them, "off" never installs.
+	InstallDependencies string `json:"install_dependencies,omitempty"`
+	// ContextFile is the name of a file written into each new worktree before the
+	// program starts, e.g. "CLAUDE.local.md", so every session begins with the
+	// same project context. It is excluded from git and never overwrites an
+	// existing file. No file is written when unset.
+	ContextFile string `json:"context_file,omitempty"`
+	// ContextTemplate is the path of a Go text/template the context file is
+	// rendered from, with the instance's title, branch, repository, tags and
+	// task prompt. A plain file is copied as is. A built-in template is used
+	// when unset.
+	ContextTemplate string `json:"context_template,omitempty"`
+	// ArtifactUploadCommand is run whenever an instance finishes working, to
+	// upload its transcript, diff and summary for auditing, e.g.
+	// `aws s3 cp --recursive "$CS_ARTIFACT_DIR" "s3://audit/$CS_INSTANCE/"`.
+	// The artifacts are files in $CS_ARTIFACT_DIR, which is removed afterwards.
+	ArtifactUploadCommand string `json:"artifact_upload_command,omitempty"`
+	// ArtifactUploadURL receives the artifacts of an instance that finished
+	// working as a JSON POST request, with the ArtifactUploadTokenEnv
+	// environment variable as bearer token if set.
+	ArtifactUploadURL string `json:"artifact_upload_url,omitempty"`
+	// DigestIntervalHours is how often, in hours, the daemon sends a digest of
+	// what each instance did, e.g. 12 to read about the night in the morning.
+	// The daemon keeps running after the TUI exits when it is set. 0 disables
+	// the digest; `cs digest` prints or sends one on demand either way.
+	DigestIntervalHours int `json:"digest_interval_hours,omitempty"`
+	// DigestCommand receives the digest as text on stdin, with its subject in
+	// $CS_DIGEST_SUBJECT, e.g. `mail -s "$CS_DIGEST_SUBJECT" me@example.com`.
+	DigestCommand string `json:"digest_command,omitempty"`
+	// DigestURL receives the digest as a JSON POST request, with the text in a
+	// "text" field so that Slack incoming webhooks can show it.
+	DigestURL string `json:"digest_url,omitempty"`
+	// MaxRunning is how many instances may have a working agent at once. New
+	// instances beyond it wait in the Pending status and are started when an
+	// agent finishes or an instance is paused. 0 means no limit.
+	MaxRunning int `json:"max_running,omitempty"`
+	// TerminalTitle sets the title of the terminal window to a summary of the
+	// squad, e.g. "CS: 2 running, 1 needs attention".
+	TerminalTitle bool `json:"terminal_title,omitempty"`
+	// PreviewTickInterval is how often, in milliseconds, the preview of the
+	// selected instance is refreshed. Defaults to 250 when unset.
+	PreviewTickInterval int `json:"preview_tick_interval,omitempty"`
+	// MetadataTickInterval is how often, in milliseconds, the status of the
+	// selected instance is updated. Defaults to 5000 when unset.
+	MetadataTickInterval int `json:"metadata_tick_interval,omitempty"`
+	// BackgroundPollInterval is how often, in milliseconds, the status of the
+	// instances that are not selected is updated. Defaults to 15000 when unset.
+	BackgroundPollInterval int `json:"background_poll_interval,omitempty"`
+	// IdlePollTimeout is how many seconds an instance's output may stay unchanged
+	// before it isn't polled at all, until it is selected, prompted or attached
+	// to. Defaults to 300 when unset; a negative value never stops polling.
+	IdlePollTimeout int `json:"idle_poll_timeout,omitempty"`
+	// FileBrowserIgnore are the directory name patterns, like "node_modules" or
+	// "*.tmp", hidden from the file browser. Defaults to node_modules, .cache and
+	// vendor when unset; an empty list shows everything.
+	FileBrowserIgnore []string `json:"file_browser_ignore,omitempty"`
+	// WorktreeRoot is the directory worktrees are created in instead of the
+	// worktrees directory next to this config. A leading ~ is the home
+	// directory, {repo} is replaced by the name of the repository and relative
+	// paths are relative to the repository, e.g. "../{repo}-worktrees".
+	WorktreeRoot string `json:"worktree_root,omitempty"`
+	// AutoArchiveDays archives instances on startup that weren't opened for this
+	// many days and have neither uncommitted changes nor unpushed commits. The
+	// archiving can be undone for a short while. 0 disables it.
+	AutoArchiveDays int `json:"auto_archive_days,omitempty"`
+	// StaleBaseCommits is how many commits of the default branch an instance's
+	// branch may lack before the preview warns that its base is stale and offers
+	// to merge the default branch. Defaults to 50 when unset; a negative value
+	// never warns.
+	StaleBaseCommits int `json:"stale_base_commits,omitempty"`
+	// InstanceLimit is how many instances can be created before a warning about
+	// their CPU and memory use is shown, since each runs its own agent and
+	// session. Defaults to 50 when unset; a negative value never warns.
+	InstanceLimit int `json:"instance_limit,omitempty"`
+	// PromptKeys are the keys that answer the prompts of a program, by the name
+	// of its command, e.g. "claude" or "aider". They are used by auto-yes and
+	// when answering a prompt from the list. Keys left empty keep the built-in
+	// ones.
+	PromptKeys map[string]PromptKeys `json:"prompt_keys,omitempty"`
+	// Env holds environment variables new instances start their program with,
+	// like feature flags or API endpoints. The env of a repository's config
+	// overrides them.
+	Env map[string]string `json:"env,omitempty"`
+	// Bot connects the daemon to a Slack or Discord channel, to be told when an
+	// instance needs attention and to list, prompt and approve instances with
+	// slash commands. The daemon keeps running after the TUI exits when set.
+	Bot *BotConfig `json:"bot,omitempty"`
+}
+
+// PromptKeys are the keys typed to answer a prompt, in JSON string notation,
+// so "\r" is enter and "\u001b" is escape, e.g. {"always": "2"} to pick the
+// second choice of a numbered menu.
+type PromptKeys struct {
+	// Accept accepts the request once.
+	Accept string `json:"accept,omitempty"`
+	// Reject rejects the request, so the agent can be told what to do instead.
+	Reject string `json:"reject,omitempty"`
+	// Always accepts the request and similar ones in the future.
+	Always string `json:"always,omitempty"`
+}
+
+// Bot platforms
+const (
+	BotPlatformSlack   = "slack"
+	BotPlatformDiscord = "discord"
+)
+
+// BotConfig is the channel the daemon posts to and takes commands from.
+type BotConfig struct {
+	// Platform is "slack" or "discord".
+	Platform string `json:"platform"`
+	// WebhookURL is the incoming webhook of the channel that notifications are
+	// posted to. Nothing is posted when unset.
+	WebhookURL string `json:"webhook_url,omitempty"`
+	// ListenAddress is the address slash commands are received on, e.g.
+	// "127.0.0.1:8642" behind a tunnel or reverse proxy. Commands are off when
+	// unset.
+	ListenAddress string `json:"listen_address,omitempty"`
+	// PublicKey is the hex encoded public key of the Discord application, which
+	// signs its interactions. Slack requests are verified with the signing
+	// secret in BotSigningSecretEnv instead.
+	PublicKey string `json:"public_key,omitempty"`
+	// AllowedUsers are the IDs of the Slack or Discord users allowed to run
+	// slash commands. Commands from anyone else are refused, from everyone if
+	// it's empty.
+	AllowedUsers []string `json:"allowed_users,omitempty"`
+}
+
+// BotSigningSecretEnv is the environment variable with the signing secret of
+// the Slack app that sends the slash commands. It is read from the environment
+// to keep it out of the config file.
+const BotSigningSecretEnv = "CLAUDE_SQUAD_SLACK_SIGNING_SECRET"
+
+// ArtifactUploadTokenEnv is the environment variable with the bearer token sent
+// to ArtifactUploadURL. It is read from the environment to keep it out of the
+// config file.
+const ArtifactUploadTokenEnv = "CLAUDE_SQUAD_UPLOAD_TOKEN"
+
+// IdentityEnv is the environment variable that overrides the configured identity.
+const IdentityEnv = "CLAUDE_SQUAD_IDENTITY"
+
+// CurrentIdentity returns the identity from the environment or the config, or
+// an empty string if none is set.
+func (c *Config) CurrentIdentity() string {
+	if identity := strings.TrimSpace(os.Getenv(IdentityEnv)); identity != "" {
+		return identity
+	}
+	return strings.TrimSpace(c.Identity)
 }
 
 // DefaultConfig returns the default configuration
@@ -237,3 +237,20 @@ func TestSaveConfig(t *testing.T) {
 		assert.Equal(t, testConfig.BranchPrefix, loadedConfig.BranchPrefix)
 	})
 }
+
+func TestCurrentIdentity(t *testing.T) {
+	t.Run("empty without config or environment", func(t *testing.T) {
+		t.Setenv(IdentityEnv, "")
+		assert.Equal(t, "", (&Config{}).CurrentIdentity())
+	})
+
+	t.Run("uses the config", func(t *testing.T) {
+		t.Setenv(IdentityEnv, "")
+		assert.Equal(t, "alice", (&Config{Identity: " alice "}).CurrentIdentity())
+	})
+
+	t.Run("environment takes precedence", func(t *testing.T) {
+		t.Setenv(IdentityEnv, "bob")
+		assert.Equal(t, "bob", (&Config{Identity: "alice"}).CurrentIdentity())
+	})
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Plan is a list of tasks to create instances for, read from a YAML file by
+// the plan command.
+type Plan struct {
+	Tasks []PlanTask `yaml:"tasks"`
+}
+
+// PlanTask is a task to create an instance for.
+type PlanTask struct {
+	// Title is the title of the instance
+	Title string `yaml:"title"`
+	// Prompt is the task sent to the program once it started
+	Prompt string `yaml:"prompt"`
+	// PromptFile is a file to read the prompt from instead, relative to the
+	// plan
+	PromptFile string `yaml:"prompt_file"`
+	// Repo is the repository to create the instance in, relative to the plan.
+	// Defaults to the directory of the plan.
+	Repo string `yaml:"repo"`
+	// Program is the program to run instead of the configured one
+	Program string `yaml:"program"`
+}
+
+// LoadPlan reads the plan at path. The paths of its tasks are made relative to
+// the current directory.
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var plan Plan
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(plan.Tasks) == 0 {
+		return nil, fmt.Errorf("no tasks in %s", path)
+	}
+
+	dir := filepath.Dir(path)
+	for i := range plan.Tasks {
+		task := &plan.Tasks[i]
+		if task.Title == "" {
+			return nil, fmt.Errorf("task #%d in %s has no title", i+1, path)
+		}
+		if task.Prompt != "" && task.PromptFile != "" {
+			return nil, fmt.Errorf("task %q in %s has both a prompt and a prompt_file", task.Title, path)
+		}
+		if task.PromptFile != "" && !filepath.IsAbs(task.PromptFile) {
+			task.PromptFile = filepath.Join(dir, task.PromptFile)
+		}
+		if !filepath.IsAbs(task.Repo) {
+			task.Repo = filepath.Join(dir, task.Repo)
+		}
+	}
+	return &plan, nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPlan(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "tasks.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	plan, err := LoadPlan(write(`tasks:
+  - title: fix-login
+    prompt: Fix the login form
+    repo: ../api
+    program: aider
+  - title: docs
+    prompt_file: tasks/docs.md
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []PlanTask{
+		{Title: "fix-login", Prompt: "Fix the login form", Repo: filepath.Join(filepath.Dir(dir), "api"), Program: "aider"},
+		{Title: "docs", PromptFile: filepath.Join(dir, "tasks", "docs.md"), Repo: dir},
+	}, plan.Tasks)
+
+	for name, content := range map[string]string{
+		"no tasks":        "tasks: []\n",
+		"missing title":   "tasks:\n  - prompt: hi\n",
+		"two prompts":     "tasks:\n  - title: a\n    prompt: hi\n    prompt_file: a.md\n",
+		"not a task list": "tasks: nope\n",
+	} {
+		_, err := LoadPlan(write(content))
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfigFileName is the name of the file in a repository that holds the
+// defaults of the instances created in it.
+const RepoConfigFileName = ".claude-squad.yaml"
+
+// RepoConfig holds the defaults of the instances created in a repository, so
+// repositories can use different agents without changing the global config.
+// Empty fields fall back to the global config.
+type RepoConfig struct {
+	// Program is the program to run in new instances
+	Program string `yaml:"program"`
+	// SessionType is the session type of new instances. The mode selector is
+	// skipped when it is set.
+	SessionType string `yaml:"session_type"`
+	// DockerImage is the base image of Docker sessions
+	DockerImage string `yaml:"docker_image"`
+	// SetupCommands run in the worktree before the program starts, instead of
+	// the detected dependency install commands
+	SetupCommands []string `yaml:"setup_commands"`
+	// BaseBranch is the branch new worktrees start from instead of HEAD
+	BaseBranch string `yaml:"base_branch"`
+	// WorktreeRoot is the directory worktrees are created in, overriding the
+	// worktree_root of the global config
+	WorktreeRoot string `yaml:"worktree_root"`
+	// SparseCheckout are the directories checked out in worktrees, in git's
+	// cone mode. Files at the root are always checked out. Empty checks out
+	// everything.
+	SparseCheckout []string `yaml:"sparse_checkout"`
+	// Submodules initializes the submodules of new worktrees, recursively, so
+	// that agents don't find their directories empty
+	Submodules bool `yaml:"submodules"`
+	// SkipLFS leaves the git-lfs pointer files of new worktrees as they are,
+	// instead of pulling the large files they stand for
+	SkipLFS bool `yaml:"skip_lfs"`
+	// CopyFiles are glob patterns, relative to the root of the repository, of
+	// ignored or untracked files copied from the main checkout into new
+	// worktrees, like .env or local certificates
+	CopyFiles []string `yaml:"copy_files"`
+	// LinkFiles are like CopyFiles, but new worktrees get symlinks to the files,
+	// so that changes to them are shared
+	LinkFiles []string `yaml:"link_files"`
+	// SharedCaches are directories of dependencies and build outputs, like
+	// node_modules, target or .gradle, that new worktrees symlink to a directory
+	// shared by all worktrees of the repository instead of filling their own.
+	// They must be ignored by git.
+	SharedCaches []string `yaml:"shared_caches"`
+	// SharedCacheRoot is the directory the shared caches are kept in. A leading
+	// ~ is the home directory, {repo} is the name of the repository and relative
+	// paths are relative to the repository. Defaults to a directory of the
+	// repository in the caches directory next to the config.
+	SharedCacheRoot string `yaml:"shared_cache_root"`
+	// Env holds environment variables to start the program with, added to
+	// and overriding the env of the global config
+	Env map[string]string `yaml:"env"`
+	// AuxCommand is run next to the program of new instances in a pane of its
+	// own, e.g. a dev server the agent works against.
+	AuxCommand string `yaml:"aux_command"`
+}
+
+// LoadRepoConfig reads the repository config of the directory, looking in it
+// and its parents up to the root of the git repository. It returns nil if there
+// is none.
+func LoadRepoConfig(dir string) (*RepoConfig, error) {
+	path := findRepoConfig(dir)
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var repoConfig RepoConfig
+	if err := yaml.Unmarshal(data, &repoConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	switch repoConfig.SessionType {
+	case "", SessionTypeZellij, SessionTypeNative, SessionTypeDockerBind, SessionTypeDockerClone:
+	default:
+		return nil, fmt.Errorf("unknown session_type %q in %s", repoConfig.SessionType, path)
+	}
+	return &repoConfig, nil
+}
+
+// findRepoConfig returns the path of the repository config that applies to
+// the directory, or an empty string if there is none.
+func findRepoConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, RepoConfigFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		// Don't pick up the config of an enclosing repository
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRepoConfig(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	sub := filepath.Join(repo, "sub")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0755))
+	require.NoError(t, os.MkdirAll(sub, 0755))
+
+	t.Run("no config", func(t *testing.T) {
+		repoConfig, err := LoadRepoConfig(sub)
+		require.NoError(t, err)
+		assert.Nil(t, repoConfig)
+	})
+
+	t.Run("config of an enclosing directory is ignored", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(root, RepoConfigFileName), []byte("program: aider\n"), 0644))
+		defer os.Remove(filepath.Join(root, RepoConfigFileName))
+
+		repoConfig, err := LoadRepoConfig(sub)
+		require.NoError(t, err)
+		assert.Nil(t, repoConfig)
+	})
+
+	t.Run("found from a subdirectory", func(t *testing.T) {
+		content := "program: aider --model sonnet\nsession_type: native\ndocker_image: ubuntu:24.04\nsetup_commands:\n  - npm ci\nbase_branch: main\n"
+		require.NoError(t, os.WriteFile(filepath.Join(repo, RepoConfigFileName), []byte(content), 0644))
+
+		repoConfig, err := LoadRepoConfig(sub)
+		require.NoError(t, err)
+		assert.Equal(t, &RepoConfig{
+			Program:       "aider --model sonnet",
+			SessionType:   SessionTypeNative,
+			DockerImage:   "ubuntu:24.04",
+			SetupCommands: []string{"npm ci"},
+			BaseBranch:    "main",
+		}, repoConfig)
+	})
+
+	t.Run("unknown session type", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(repo, RepoConfigFileName), []byte("session_type: tmux\n"), 0644))
+
+		_, err := LoadRepoConfig(repo)
+		assert.ErrorContains(t, err, "unknown session_type")
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(repo, RepoConfigFileName), []byte("program: [\n"), 0644))
+
+		_, err := LoadRepoConfig(repo)
+		assert.ErrorContains(t, err, "failed to parse")
+	})
+}
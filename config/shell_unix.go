@@ -0,0 +1,13 @@
+//go:build !windows
+
+package config
+
+import (
+	"context"
+	"os/exec"
+)
+
+// ShellCommand returns a command that runs the command line with the shell.
+func ShellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package config
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// ShellCommand returns a command that runs the command line with cmd.exe.
+func ShellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cmd.exe")
+	// Pass the command line through as is; cmd.exe doesn't follow the quoting
+	// rules Go uses to join arguments
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: "cmd.exe /s /c \"" + command + "\""}
+	return cmd
+}
@@ -22,14 +22,24 @@ type InstanceStorage interface {
 	GetInstances() json.RawMessage
 	// DeleteAllInstances removes all stored instances
 	DeleteAllInstances() error
+	// LoadError returns why the stored instances could not be read, or nil
+	LoadError() error
 }
 
 // AppState handles application-level state
 type AppState interface {
-	// GetHelpScreensSeen returns the bitmask of seen help screens
-	GetHelpScreensSeen() uint32
-	// SetHelpScreensSeen updates the bitmask of seen help screens
-	SetHelpScreensSeen(seen uint32) error
+	// HelpScreenSeen returns true if the named help screen has been shown
+	HelpScreenSeen(name string) bool
+	// SetHelpScreenSeen records that the named help screen has been shown
+	SetHelpScreenSeen(name string) error
+	// GetBookmarks returns the directories pinned in the file browser
+	GetBookmarks() []string
+	// SetBookmarks updates the directories pinned in the file browser
+	SetBookmarks(bookmarks []string) error
+	// GetLastRepoPath returns the directory last picked in the file browser
+	GetLastRepoPath() string
+	// SetLastRepoPath updates the directory last picked in the file browser
+	SetLastRepoPath(path string) error
 }
 
 // StateManager combines instance storage and app state management
@@ -38,26 +48,49 @@ type StateManager interface {
 	AppState
 }
 
+// Names of the help screens that are shown once
+const (
+	HelpScreenGeneral       = "general"
+	HelpScreenInstanceStart = "instance_start"
+	HelpScreenAttach        = "attach"
+	HelpScreenCheckout      = "checkout"
+)
+
+// legacyHelpScreens are the help screens of the bits of HelpScreensSeen, in bit order.
+var legacyHelpScreens = []string{HelpScreenGeneral, HelpScreenInstanceStart, HelpScreenAttach, HelpScreenCheckout}
+
 // State represents the application state that persists between sessions
 type State struct {
-	// HelpScreensSeen is a bitmask tracking which help screens have been shown
-	HelpScreensSeen uint32 `json:"help_screens_seen"`
+	// HelpSeen are the names of the help screens that have been shown
+	HelpSeen []string `json:"help_seen,omitempty"`
+	// HelpScreensSeen is the bitmask older versions tracked the shown help
+	// screens in. It's moved to HelpSeen when the state is loaded.
+	HelpScreensSeen uint32 `json:"help_screens_seen,omitempty"`
 	// Instances stores the serialized instance data as raw JSON
 	InstancesData json.RawMessage `json:"instances"`
+	// Bookmarks are the directories pinned in the file browser
+	Bookmarks []string `json:"bookmarks,omitempty"`
+	// LastRepoPath is the directory last picked in the file browser
+	LastRepoPath string `json:"last_repo_path,omitempty"`
+	// LastDigestAt is when the daemon last sent the digest of the instances
+	LastDigestAt *time.Time `json:"last_digest_at,omitempty"`
 
 	// lastModTime tracks when we last read the state file (not serialized)
 	lastModTime time.Time `json:"-"`
+	// loadErr is why the state file could not be parsed. The default state is
+	// used instead, but it isn't saved over the file (not serialized)
+	loadErr error `json:"-"`
 }
 
 // DefaultState returns the default state
 func DefaultState() *State {
 	return &State{
-		HelpScreensSeen: 0,
-		InstancesData:   json.RawMessage("[]"),
+		InstancesData: json.RawMessage("[]"),
 	}
 }
 
 // LoadState loads the state from disk. If it cannot be done, we return the default state.
+// If the state file cannot be parsed, its LoadError is set and it's not saved.
 // This function acquires a shared lock to allow concurrent reads.
 func LoadState() *State {
 	configDir, err := GetConfigDir()
@@ -102,16 +135,33 @@ func LoadState() *State {
 	var state State
 	if err := json.Unmarshal(data, &state); err != nil {
 		log.ErrorLog.Printf("failed to parse state file: %v", err)
-		return DefaultState()
+		defaultState := DefaultState()
+		defaultState.loadErr = fmt.Errorf("failed to parse state file: %w", err)
+		return defaultState
 	}
 
+	state.migrateHelpScreens()
 	state.lastModTime = modTime
 	return &state
 }
 
+// migrateHelpScreens moves the help screens marked in the legacy bitmask to HelpSeen.
+func (s *State) migrateHelpScreens() {
+	for bit, name := range legacyHelpScreens {
+		if s.HelpScreensSeen&(1<<bit) != 0 && !s.HelpScreenSeen(name) {
+			s.HelpSeen = append(s.HelpSeen, name)
+		}
+	}
+	s.HelpScreensSeen = 0
+}
+
 // SaveState saves the state to disk.
 // This function acquires an exclusive lock to prevent concurrent writes.
 func SaveState(state *State) error {
+	if state.loadErr != nil {
+		return fmt.Errorf("not saving over a state file that could not be loaded: %w", state.loadErr)
+	}
+
 	configDir, err := GetConfigDir()
 	if err != nil {
 		return fmt.Errorf("failed to get config directory: %w", err)
@@ -130,6 +180,12 @@ func SaveState(state *State) error {
 	}
 	defer lock.Unlock()
 
+	return writeState(statePath, state)
+}
+
+// writeState writes the state to the file at statePath. The exclusive lock of
+// the file must be held.
+func writeState(statePath string, state *State) error {
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal state: %w", err)
@@ -147,6 +203,94 @@ func SaveState(state *State) error {
 	return nil
 }
 
+// stateBackupSuffix is appended to the state file name for the copy kept by
+// BackupState.
+const stateBackupSuffix = ".bak"
+
+// statePath returns the path of the state file.
+func statePath() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, StateFileName), nil
+}
+
+// copyFile copies the file at src to dst, replacing dst.
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
+
+// BackupState copies the state file next to it, to restore with
+// RestoreStateBackup. Call it once the instances have been loaded successfully.
+// A state file that can't be parsed is not backed up.
+func BackupState() error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to back up state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("not backing up a state file that could not be parsed: %w", err)
+	}
+	if err := os.WriteFile(path+stateBackupSuffix, data, 0644); err != nil {
+		return fmt.Errorf("failed to back up state: %w", err)
+	}
+	return nil
+}
+
+// HasStateBackup returns true if there is a backup of the state file.
+func HasStateBackup() bool {
+	path, err := statePath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path + stateBackupSuffix)
+	return err == nil
+}
+
+// RestoreStateBackup replaces the state file with its backup.
+func RestoreStateBackup() error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	lock := NewFileLock(path)
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("failed to acquire write lock: %w", err)
+	}
+	defer lock.Unlock()
+	if err := copyFile(path+stateBackupSuffix, path); err != nil {
+		return fmt.Errorf("failed to restore state backup: %w", err)
+	}
+	return nil
+}
+
+// SetAsideState copies the state file next to it with a timestamp, to keep a
+// state that can't be loaded before it is overwritten. It returns the path of
+// the copy.
+func SetAsideState() (string, error) {
+	path, err := statePath()
+	if err != nil {
+		return "", err
+	}
+	aside := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405"))
+	if err := copyFile(path, aside); err != nil {
+		return "", fmt.Errorf("failed to set aside state: %w", err)
+	}
+	return aside, nil
+}
+
 // InstanceStorage interface implementation
 
 // SaveInstances saves the raw instance data
@@ -160,25 +304,103 @@ func (s *State) GetInstances() json.RawMessage {
 	return s.InstancesData
 }
 
-// DeleteAllInstances removes all stored instances
+// DeleteAllInstances removes all stored instances. It starts over, so it
+// saves over a state file that could not be loaded.
 func (s *State) DeleteAllInstances() error {
 	s.InstancesData = json.RawMessage("[]")
+	s.loadErr = nil
 	return SaveState(s)
 }
 
+// LoadError returns why the state file could not be parsed, or nil
+func (s *State) LoadError() error {
+	return s.loadErr
+}
+
 // AppState interface implementation
 
-// GetHelpScreensSeen returns the bitmask of seen help screens
-func (s *State) GetHelpScreensSeen() uint32 {
-	return s.HelpScreensSeen
+// HelpScreenSeen returns true if the named help screen has been shown
+func (s *State) HelpScreenSeen(name string) bool {
+	for _, seen := range s.HelpSeen {
+		if seen == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetHelpScreenSeen records that the named help screen has been shown
+func (s *State) SetHelpScreenSeen(name string) error {
+	if s.HelpScreenSeen(name) {
+		return nil
+	}
+	s.HelpSeen = append(s.HelpSeen, name)
+	return SaveState(s)
+}
+
+// GetBookmarks returns the directories pinned in the file browser
+func (s *State) GetBookmarks() []string {
+	return s.Bookmarks
+}
+
+// SetBookmarks updates the directories pinned in the file browser
+func (s *State) SetBookmarks(bookmarks []string) error {
+	s.Bookmarks = bookmarks
+	return SaveState(s)
+}
+
+// GetLastRepoPath returns the directory last picked in the file browser
+func (s *State) GetLastRepoPath() string {
+	return s.LastRepoPath
 }
 
-// SetHelpScreensSeen updates the bitmask of seen help screens
-func (s *State) SetHelpScreensSeen(seen uint32) error {
-	s.HelpScreensSeen = seen
+// SetLastRepoPath updates the directory last picked in the file browser
+func (s *State) SetLastRepoPath(path string) error {
+	s.LastRepoPath = path
 	return SaveState(s)
 }
 
+// GetLastDigestAt returns when the daemon last sent the digest, or nil if it never did
+func (s *State) GetLastDigestAt() *time.Time {
+	return s.LastDigestAt
+}
+
+// SetLastDigestAt records when the daemon sent the digest. Only the time is
+// written to the state on disk, so that the instances saved since the state was
+// read aren't overwritten.
+func (s *State) SetLastDigestAt(at time.Time) error {
+	if s.loadErr != nil {
+		return fmt.Errorf("not saving over a state file that could not be loaded: %w", s.loadErr)
+	}
+	s.LastDigestAt = &at
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to get config directory: %w", err)
+	}
+	statePath := filepath.Join(configDir, StateFileName)
+
+	lock := NewFileLock(statePath)
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("failed to acquire write lock: %w", err)
+	}
+	defer lock.Unlock()
+
+	data, err := os.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return writeState(statePath, s)
+	} else if err != nil {
+		return fmt.Errorf("failed to read state file: %w", err)
+	}
+	var onDisk State
+	if err := json.Unmarshal(data, &onDisk); err != nil {
+		return fmt.Errorf("failed to parse state file: %w", err)
+	}
+	onDisk.LastDigestAt = &at
+	// The state isn't marked as read, so the rest is refreshed from disk
+	return writeState(statePath, &onDisk)
+}
+
 // State sync methods
 
 // GetLastModTime returns the modification time when this state was last read from disk.
@@ -250,8 +472,12 @@ func (s *State) RefreshFromDisk() (bool, error) {
 	}
 
 	// Update this state with the new data
-	s.HelpScreensSeen = newState.HelpScreensSeen
+	newState.migrateHelpScreens()
+	s.HelpSeen = newState.HelpSeen
 	s.InstancesData = newState.InstancesData
+	s.Bookmarks = newState.Bookmarks
+	s.LastRepoPath = newState.LastRepoPath
+	s.LastDigestAt = newState.LastDigestAt
 	s.lastModTime = info.ModTime()
 
 	return true, nil
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadStateMigratesHelpScreens(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".claude-squad")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	// The general and attach help screens were seen by an older version
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, StateFileName),
+		[]byte(`{"help_screens_seen": 5, "instances": []}`), 0644))
+
+	state := LoadState()
+	assert.Equal(t, []string{HelpScreenGeneral, HelpScreenAttach}, state.HelpSeen)
+	assert.Zero(t, state.HelpScreensSeen)
+	assert.True(t, state.HelpScreenSeen(HelpScreenAttach))
+	assert.False(t, state.HelpScreenSeen(HelpScreenCheckout))
+
+	// The bitmask is gone once the state is saved again
+	require.NoError(t, state.SetHelpScreenSeen(HelpScreenCheckout))
+	data, err := os.ReadFile(filepath.Join(configDir, StateFileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "help_screens_seen")
+
+	reloaded := LoadState()
+	assert.Equal(t, []string{HelpScreenGeneral, HelpScreenAttach, HelpScreenCheckout}, reloaded.HelpSeen)
+}
+
+func TestLoadStateKeepsUnparsableState(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configDir := filepath.Join(home, ".claude-squad")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	statePath := filepath.Join(configDir, StateFileName)
+	corrupt := []byte(`{"instances": [{"title": "a"`)
+	require.NoError(t, os.WriteFile(statePath, corrupt, 0644))
+
+	state := LoadState()
+	assert.ErrorContains(t, state.LoadError(), "failed to parse state file")
+	assert.Error(t, state.SetBookmarks([]string{"/tmp"}))
+	assert.Error(t, BackupState())
+	assert.False(t, HasStateBackup())
+	data, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, corrupt, data)
+
+	// Starting over saves over it
+	require.NoError(t, state.DeleteAllInstances())
+	assert.NoError(t, LoadState().LoadError())
+	require.NoError(t, BackupState())
+	assert.True(t, HasStateBackup())
+}
+
+func TestSetLastDigestAtKeepsSavedInstances(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	// The daemon read the state before the TUI saved an instance
+	daemonState := LoadState()
+	tuiState := LoadState()
+	require.NoError(t, tuiState.SaveInstances([]byte(`[{"title":"a"}]`)))
+
+	at := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, daemonState.SetLastDigestAt(at))
+
+	reloaded := LoadState()
+	assert.JSONEq(t, `[{"title":"a"}]`, string(reloaded.GetInstances()))
+	require.NotNil(t, reloaded.GetLastDigestAt())
+	assert.True(t, at.Equal(*reloaded.GetLastDigestAt()))
+
+	// The daemon picks up the instances again
+	refreshed, err := daemonState.RefreshFromDisk()
+	require.NoError(t, err)
+	assert.True(t, refreshed)
+	assert.JSONEq(t, `[{"title":"a"}]`, string(daemonState.GetInstances()))
+}
@@ -0,0 +1,119 @@
+// Package create creates instances outside of the TUI, for the new, issue and
+// plan commands.
+package create
+
+import (
+	"claude-squad/audit"
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Instance creates and starts an instance in the repository at path and
+// sends it the prompt, if any. The program overrides the configured one. The
+// instance is linked to the issue, if given.
+func Instance(cfg *config.Config, title, path, program, prompt string, issue *session.IssueLink) error {
+	storage, instances, err := loadStoredInstances()
+	if err != nil {
+		return err
+	}
+	if err := session.ValidateTitle(title, nil, instances); err != nil {
+		return err
+	}
+	opts, err := instanceOptions(cfg, title, path, program, prompt)
+	if err != nil {
+		return err
+	}
+	instance, err := session.NewInstance(opts)
+	if err != nil {
+		return err
+	}
+	instance.Issue = issue
+	if err := startInstance(storage, instance); err != nil {
+		return err
+	}
+	fmt.Printf("Created %s on branch %s\n", instance.Title, instance.Branch)
+	return sendInitialPrompt(storage, instance, prompt)
+}
+
+// loadStoredInstances loads the stored instances without restoring their
+// sessions, to check the titles of new ones.
+func loadStoredInstances() (*session.Storage, []*session.Instance, error) {
+	storage, err := session.NewStorage(config.LoadState())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	storage.SetDeferRestore(true)
+	instances, err := storage.LoadInstances()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load instances: %w", err)
+	}
+	return storage, instances, nil
+}
+
+// instanceOptions returns the options of a new instance in the repository at
+// path, with the same defaults as the TUI. The program overrides the
+// configured one.
+func instanceOptions(cfg *config.Config, title, path, program, prompt string) (session.InstanceOptions, error) {
+	repoConfig, err := config.LoadRepoConfig(path)
+	if err != nil {
+		return session.InstanceOptions{}, err
+	}
+	sessionType := session.LocalSessionType()
+	if repoConfig != nil && repoConfig.SessionType != "" {
+		sessionType = repoConfig.SessionType
+	}
+	if sessionType == config.SessionTypeDockerClone {
+		return session.InstanceOptions{}, fmt.Errorf("docker-clone instances can only be created in the TUI")
+	}
+	if program == "" {
+		program = cfg.DefaultProgram
+	}
+	opts := session.InstanceOptions{
+		Title:           title,
+		Path:            path,
+		Program:         program,
+		SessionType:     sessionType,
+		DockerBaseImage: cfg.DockerBaseImage,
+		Owner:           cfg.CurrentIdentity(),
+		ContextFile:     cfg.ContextFile,
+		ContextTemplate: cfg.ContextTemplate,
+		Prompt:          prompt,
+		Env:             cfg.Env,
+	}
+	opts.ApplyRepoConfig(repoConfig)
+	return opts, nil
+}
+
+// startInstance starts the new instance and adds it to storage. It is killed
+// again if it can't be saved.
+func startInstance(storage *session.Storage, instance *session.Instance) error {
+	if err := instance.Start(true); err != nil {
+		return fmt.Errorf("failed to start %q: %w", instance.Title, err)
+	}
+	if err := storage.AddInstance(instance); err != nil {
+		if killErr := instance.Kill(0); killErr != nil {
+			log.ErrorLog.Printf("failed to clean up %s: %v", instance.Title, killErr)
+		}
+		return fmt.Errorf("failed to save %q: %w", instance.Title, err)
+	}
+	audit.Record(audit.EventCreated, instance.Title, instance.Branch)
+	return nil
+}
+
+// sendInitialPrompt sends the prompt, if any, to the started instance.
+func sendInitialPrompt(storage *session.Storage, instance *session.Instance, prompt string) error {
+	if prompt == "" {
+		return nil
+	}
+	if err := instance.SendPrompt(prompt); errors.Is(err, session.ErrPromptUnconfirmed) {
+		fmt.Fprintf(os.Stderr, "warning: the prompt was submitted to %q but could not be confirmed as received\n", instance.Title)
+	} else if err != nil {
+		return fmt.Errorf("failed to send the prompt to %q: %w", instance.Title, err)
+	}
+	audit.Record(audit.EventPrompt, instance.Title, prompt)
+	return storage.UpdateInstance(instance)
+}
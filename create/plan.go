@@ -0,0 +1,95 @@
+package create
+
+import (
+	"claude-squad/config"
+	"claude-squad/session"
+	"fmt"
+)
+
+// Plan creates an instance for each task of the plan at path and reports
+// which were created. Instances are started until max_running agents are
+// working; the others are stored as pending, for the TUI to start once a slot
+// frees.
+func Plan(cfg *config.Config, path string) error {
+	plan, err := config.LoadPlan(path)
+	if err != nil {
+		return err
+	}
+	storage, instances, err := loadStoredInstances()
+	if err != nil {
+		return err
+	}
+
+	// The sessions aren't restored, so the stored statuses tell which agents work
+	active := 0
+	for _, instance := range instances {
+		if !instance.Archived && !instance.Tombstoned() && (instance.Status == session.Running || instance.Status == session.Loading) {
+			active++
+		}
+	}
+
+	failed := 0
+	for _, task := range plan.Tasks {
+		instance, err := createPlanTask(cfg, storage, instances, task, cfg.MaxRunning <= 0 || active < cfg.MaxRunning)
+		if instance != nil {
+			instances = append(instances, instance)
+			if instance.Status != session.Pending {
+				active++
+			}
+		}
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("failed   %s: %v\n", task.Title, err)
+		case instance.Status == session.Pending:
+			fmt.Printf("pending  %s, starts once fewer than %d agents are working\n", task.Title, cfg.MaxRunning)
+		default:
+			fmt.Printf("created  %s on branch %s\n", task.Title, instance.Branch)
+		}
+	}
+
+	fmt.Printf("%d of %d task(s) created\n", len(plan.Tasks)-failed, len(plan.Tasks))
+	if failed > 0 {
+		return fmt.Errorf("%d task(s) failed", failed)
+	}
+	return nil
+}
+
+// createPlanTask creates the instance of the task and starts it, or stores it
+// as pending unless start is set. The instance is returned once it is stored,
+// even if sending its prompt failed.
+func createPlanTask(cfg *config.Config, storage *session.Storage, instances []*session.Instance, task config.PlanTask, start bool) (*session.Instance, error) {
+	prompt := task.Prompt
+	if task.PromptFile != "" {
+		var err error
+		if prompt, err = session.ReadPromptFile(task.PromptFile, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := session.ValidateTitle(task.Title, nil, instances); err != nil {
+		return nil, err
+	}
+	opts, err := instanceOptions(cfg, task.Title, task.Repo, task.Program, prompt)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := session.NewInstance(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if !start {
+		// The prompt is kept with the instance and sent once it starts
+		instance.SetStatus(session.Pending)
+		instance.Prompt = prompt
+		if err := storage.AddInstance(instance); err != nil {
+			return nil, fmt.Errorf("failed to save %q: %w", task.Title, err)
+		}
+		return instance, nil
+	}
+
+	if err := startInstance(storage, instance); err != nil {
+		return nil, err
+	}
+	return instance, sendInitialPrompt(storage, instance, prompt)
+}
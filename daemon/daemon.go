@@ -1,9 +1,13 @@
 package daemon
 
 import (
+	"claude-squad/audit"
+	"claude-squad/bot"
 	"claude-squad/config"
 	"claude-squad/log"
 	"claude-squad/session"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -14,10 +18,12 @@ import (
 	"time"
 )
 
-// RunDaemon runs the daemon process which iterates over all sessions and runs AutoYes mode on them.
+// RunDaemon runs the daemon process which iterates over all sessions and runs AutoYes mode on them
+// if autoYes is set, connects them to the configured bot and sends the digest of them.
 // It's expected that the main process kills the daemon when the main process starts.
-func RunDaemon(cfg *config.Config) error {
+func RunDaemon(cfg *config.Config, autoYes bool) error {
 	log.InfoLog.Printf("starting daemon")
+	session.SetPromptKeys(cfg.PromptKeys)
 	state := config.LoadState()
 	storage, err := session.NewStorage(state)
 	if err != nil {
@@ -28,12 +34,37 @@ func RunDaemon(cfg *config.Config) error {
 	if err != nil {
 		return fmt.Errorf("failed to load instacnes: %w", err)
 	}
+	// Killed instances awaiting deferred cleanup are kept (so they are saved back) but not polled.
+	active := make([]*session.Instance, 0, len(instances))
 	for _, instance := range instances {
-		// Assume AutoYes is true if the daemon is running.
-		instance.AutoYes = true
+		instance.AutoYes = autoYes
+		if !instance.Tombstoned() {
+			active = append(active, instance)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var chatBot *bot.Bot
+	if cfg.Bot != nil {
+		if chatBot, err = bot.New(cfg.Bot, active); err != nil {
+			log.ErrorLog.Printf("failed to start the bot: %v", err)
+		} else {
+			go func() {
+				if err := chatBot.Serve(ctx); err != nil {
+					log.ErrorLog.Print(err)
+				}
+			}()
+		}
 	}
 
+	digest := newDigestSchedule(cfg, state, time.Now())
+
 	pollInterval := time.Duration(cfg.DaemonPollInterval) * time.Millisecond
+	// landing is set while the merge queue lands an instance. The outcome is
+	// recorded by the polling loop, which owns the instances.
+	landing := false
+	landed := make(chan landOutcome, 1)
 
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
@@ -43,19 +74,63 @@ func RunDaemon(cfg *config.Config) error {
 		ticker := time.NewTimer(pollInterval)
 		for {
 			// Parallel update check - runs HasUpdated() concurrently
-			updateResults := session.ParallelUpdate(instances)
+			updateResults := session.ParallelUpdate(active)
 
+			rateLimitsChanged := false
 			for _, result := range updateResults {
-				if result.Instance != nil && result.HasPrompt {
+				// The process that polled a shared result acts on it
+				if result.Instance == nil || result.Shared {
+					continue
+				}
+				if result.HasPrompt {
 					result.Instance.TapEnter()
 				}
+				if result.RateLimited {
+					log.InfoLog.Printf("%s is rate limited until %s", result.Instance.Title, result.RateLimitedUntil.Format(time.RFC3339))
+					result.Instance.MarkRateLimited(result.RateLimitedUntil)
+					rateLimitsChanged = true
+				}
+			}
+			// Retry the last prompt of rate limited instances once their cooldown is over
+			if retryRateLimited(active) {
+				rateLimitsChanged = true
+			}
+			// Tell the channel about instances that need attention
+			if chatBot != nil {
+				chatBot.Observe(updateResults, time.Now())
+			}
+			if rateLimitsChanged {
+				if err := storage.SaveInstances(instances); err != nil {
+					log.ErrorLog.Printf("failed to save instances after rate limits changed: %v", err)
+				}
 			}
 
 			// Background diff stats update - non-blocking, rate-limited
 			// (10s delay after activity, max once per 30s per instance)
-			session.BackgroundUpdateDiffStats(instances)
+			session.BackgroundUpdateDiffStats(active)
 			// Background capture of Claude session IDs for instances that don't have one
-			session.BackgroundCaptureClaudeSessionIDs(instances)
+			session.BackgroundCaptureClaudeSessionIDs(active)
+			if digest != nil {
+				digest.check(instances, time.Now())
+			}
+			// Land queued instances one at a time, without blocking polling
+			select {
+			case outcome := <-landed:
+				landing = false
+				finishLand(outcome, storage, instances)
+			default:
+			}
+			if next := session.NextToLand(active); next != nil && !landing {
+				if land, err := next.LandJob(cfg.LandCheckCommand); err != nil {
+					finishLand(landOutcome{instance: next, err: err}, storage, instances)
+				} else {
+					landing = true
+					log.InfoLog.Printf("landing %s", next.Title)
+					go func() {
+						landed <- landOutcome{instance: next, err: land()}
+					}()
+				}
+			}
 
 			// Handle stop before ticker.
 			select {
@@ -85,15 +160,61 @@ func RunDaemon(cfg *config.Config) error {
 	return nil
 }
 
-// LaunchDaemon launches the daemon process.
-func LaunchDaemon() error {
+// retryRateLimited sends the last prompt again to the rate limited instances
+// whose cooldown is over. It returns true if any was retried.
+func retryRateLimited(instances []*session.Instance) bool {
+	retried := false
+	now := time.Now()
+	for _, instance := range instances {
+		prompt, ok := instance.RateLimitRetry(now)
+		if !ok {
+			continue
+		}
+		retried = true
+		log.InfoLog.Printf("retrying the last prompt of %s after its rate limit", instance.Title)
+		if err := instance.SendPrompt(prompt); errors.Is(err, session.ErrPromptUnconfirmed) {
+			log.WarningLog.Printf("could not confirm that %s received the retried prompt", instance.Title)
+		} else if err != nil {
+			log.ErrorLog.Printf("failed to retry the prompt of %s: %v", instance.Title, err)
+			continue
+		}
+		audit.Record(audit.EventPrompt, instance.Title, prompt)
+	}
+	return retried
+}
+
+// landOutcome is the outcome of landing an instance in the background.
+type landOutcome struct {
+	instance *session.Instance
+	err      error
+}
+
+// finishLand records the outcome of landing an instance and saves it.
+func finishLand(outcome landOutcome, storage *session.Storage, instances []*session.Instance) {
+	outcome.instance.FinishLand(outcome.err)
+	if outcome.err != nil {
+		log.ErrorLog.Printf("failed to land %s: %v", outcome.instance.Title, outcome.err)
+	} else {
+		log.InfoLog.Printf("landed %s", outcome.instance.Title)
+	}
+	if err := storage.SaveInstances(instances); err != nil {
+		log.ErrorLog.Printf("failed to save instances after landing %s: %v", outcome.instance.Title, err)
+	}
+}
+
+// LaunchDaemon launches the daemon process, which accepts prompts if autoYes is set.
+func LaunchDaemon(autoYes bool) error {
 	// Find the claude squad binary.
 	execPath, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("failed to get executable path: %w", err)
 	}
 
-	cmd := exec.Command(execPath, "--daemon")
+	args := []string{"--daemon"}
+	if autoYes {
+		args = append(args, "--autoyes")
+	}
+	cmd := exec.Command(execPath, args...)
 
 	// Detach the process from the parent
 	cmd.Stdin = nil
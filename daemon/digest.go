@@ -0,0 +1,50 @@
+package daemon
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+	"time"
+)
+
+// digestSchedule sends the digest of the instances every configured interval.
+type digestSchedule struct {
+	cfg      *config.Config
+	state    *config.State
+	interval time.Duration
+	// last is when the digest was last sent. Without one, the first digest
+	// covers the time since the daemon started.
+	last time.Time
+}
+
+// newDigestSchedule creates the schedule of the digest, or returns nil if the
+// digest is off.
+func newDigestSchedule(cfg *config.Config, state *config.State, now time.Time) *digestSchedule {
+	if cfg.DigestIntervalHours <= 0 {
+		return nil
+	}
+	schedule := &digestSchedule{cfg: cfg, state: state, interval: time.Duration(cfg.DigestIntervalHours) * time.Hour, last: now}
+	if last := state.GetLastDigestAt(); last != nil {
+		schedule.last = *last
+	}
+	return schedule
+}
+
+// check sends the digest in the background if it is due.
+func (d *digestSchedule) check(instances []*session.Instance, now time.Time) {
+	if now.Sub(d.last) < d.interval {
+		return
+	}
+	digest := session.NewDigest(instances, d.last, now)
+	d.last = now
+	if err := d.state.SetLastDigestAt(now); err != nil {
+		log.ErrorLog.Printf("failed to save when the digest was sent: %v", err)
+	}
+	go func() {
+		if err := session.SendDigest(d.cfg, digest); err != nil {
+			log.ErrorLog.Printf("failed to send the digest: %v", err)
+			return
+		}
+		log.InfoLog.Printf("sent the digest of %d instance(s)", len(digest.Instances))
+	}()
+}
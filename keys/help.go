@@ -0,0 +1,112 @@
+package keys
+
+// HelpContext is where the keys listed under it in the help apply.
+type HelpContext int
+
+const (
+	// HelpContextList is the session list, where most keys apply.
+	HelpContextList HelpContext = iota
+	// HelpContextDiff is the diff and files tabs of the preview.
+	HelpContextDiff
+	// HelpContextPrompt is the overlay a prompt is typed in.
+	HelpContextPrompt
+	// HelpContextAttach is an attached session, or typing into the preview.
+	HelpContextAttach
+)
+
+// HelpContexts are the contexts in the order the help lists them.
+var HelpContexts = []HelpContext{HelpContextList, HelpContextDiff, HelpContextPrompt, HelpContextAttach}
+
+// String returns the title of the context in the help.
+func (c HelpContext) String() string {
+	switch c {
+	case HelpContextList:
+		return "Sessions"
+	case HelpContextDiff:
+		return "Diff and files tabs"
+	case HelpContextPrompt:
+		return "Prompt"
+	case HelpContextAttach:
+		return "Attached"
+	default:
+		return "Other"
+	}
+}
+
+// HelpEntry describes what a key does in a context.
+type HelpEntry struct {
+	Name        KeyName
+	Context     HelpContext
+	Description string
+}
+
+// Key returns the keys of the entry as the help shows them, from its binding
+// in GlobalkeyBindings.
+func (e HelpEntry) Key() string {
+	return GlobalkeyBindings[e.Name].Help().Key
+}
+
+// Help lists the keys shown in the help, in order within each context.
+var Help = []HelpEntry{
+	{KeyNew, HelpContextList, "Create a new session"},
+	{KeyPrompt, HelpContextList, "Create a new session with a prompt"},
+	{KeyIssue, HelpContextList, "Create a new session working on an open GitHub issue"},
+	{KeyImport, HelpContextList, "Import orphaned Zellij sessions"},
+	{KeyKill, HelpContextList, "Kill (delete) the selected session"},
+	{KeyRename, HelpContextList, "Rename the selected session"},
+	{KeyArchive, HelpContextList, "Archive the selected session, or restore it from the archived filter"},
+	{KeyNotes, HelpContextList, "Edit tags and notes of the selected session"},
+	{KeyUndo, HelpContextList, "Undo the last kill or archive (30s)"},
+	{KeyUp, HelpContextList, "Select the previous session"},
+	{KeyDown, HelpContextList, "Select the next session"},
+	{KeyMoveUp, HelpContextList, "Move the selected session up"},
+	{KeyMoveDown, HelpContextList, "Move the selected session down"},
+	{KeyJump, HelpContextList, "Jump to a session by its two-digit number, 1-9 jump right away"},
+	{KeyFilterLeft, HelpContextList, "Previous filter (all, attention, archived, mine, others, tags)"},
+	{KeyFilterRight, HelpContextList, "Next filter"},
+	{KeyEnter, HelpContextList, "Attach to the selected session"},
+	{KeyOpenWorktree, HelpContextList, "Open the session's worktree with open_command, e.g. code {path}"},
+	{KeyInlineAttach, HelpContextList, "Type into the session while its preview stays on screen"},
+	{KeyPromptAccept, HelpContextList, "Accept the prompt the session waits on"},
+	{KeyPromptAlways, HelpContextList, "Always accept the prompt the session waits on"},
+	{KeyPromptReject, HelpContextList, "Reject the prompt the session waits on"},
+	{KeyPromptReply, HelpContextList, "Reply to the prompt the session waits on"},
+	{KeyRebase, HelpContextList, "Rebase: reorder, squash or drop the branch's commits"},
+	{KeyFixup, HelpContextList, "Commit manual edits as fixups of the commits they change"},
+	{KeyLand, HelpContextList, "Queue to land: rebase, check and push to the default branch"},
+	{KeyMergeBase, HelpContextList, "Merge the default branch into the branch"},
+	{KeyCheck, HelpContextList, "Run the check command, see the Checks tab for output"},
+	{KeyAuxCommand, HelpContextList, "Set a command to run next to the agent, e.g. npm run dev"},
+	{KeyAuxView, HelpContextList, "Switch the preview between the agent and that command"},
+	{KeySubmit, HelpContextList, "Commit and push branch to github (fixups are squashed)"},
+	{KeyOpenCI, HelpContextList, "Open the failed CI run of the pushed branch"},
+	{KeyCheckout, HelpContextList, "Checkout: commit changes and pause session"},
+	{KeyCheckoutRunning, HelpContextList, "Check out the changes detached in your repo, keep the session running"},
+	{KeyPauseAll, HelpContextList, "Pause all running sessions"},
+	{KeyResumeAll, HelpContextList, "Resume all paused sessions"},
+	{KeyResume, HelpContextList, "Resume a paused session"},
+	{KeySwitchMode, HelpContextList, "Switch a paused session to another mode, e.g. from zellij to docker"},
+	{KeyReview, HelpContextList, "Review the changes in a new reviewer session"},
+	{KeyRelay, HelpContextList, "Relay recent output to another session as a prompt"},
+	{KeySnippets, HelpContextList, "Browse code blocks and answers captured from the session"},
+	{KeyStats, HelpContextList, "Show statistics for all sessions"},
+	{KeyBoard, HelpContextList, "Show sessions as a board, move cards with H/L"},
+	{KeyTimeline, HelpContextList, "Show a timeline of session activity over the last week"},
+	{KeyAudit, HelpContextList, "Show the audit log of all sessions, export it with x"},
+	{KeyPicker, HelpContextList, "Go to a session by title, branch or repo; ctrl+a attaches"},
+	{KeyQuarantine, HelpContextList, "Review and clear the quarantine of a session"},
+	{KeyTodos, HelpContextList, "Collapse or expand the agent's task list"},
+	{KeyTab, HelpContextList, "Switch between preview, diff, files and checks tabs"},
+	{KeyHelp, HelpContextList, "Show this help"},
+	{KeyQuit, HelpContextList, "Quit the application"},
+
+	{KeyShiftUp, HelpContextDiff, "Scroll up"},
+	{KeyShiftDown, HelpContextDiff, "Scroll down"},
+	{KeyDiffTarget, HelpContextDiff, "In the diff tab, diff against the default branch, last commit or any ref"},
+	{KeyEditFile, HelpContextDiff, "In the files tab, open the selected file in $EDITOR; enter shows its diff"},
+
+	{KeyEditor, HelpContextPrompt, "Edit the prompt or notes in $VISUAL/$EDITOR"},
+	{KeyPromptFile, HelpContextPrompt, "Load the prompt from a file"},
+
+	{KeyDetach, HelpContextAttach, "Detach from the session, or stop typing into the preview"},
+}
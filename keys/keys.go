@@ -16,10 +16,19 @@ const (
 	KeyReview
 	KeyPush
 	KeySubmit
+	// Open the failed CI run of the selected instance's pushed branch
+	KeyOpenCI
 
 	KeyTab        // Tab is a special keybinding for switching between panes.
 	KeySubmitName // SubmitName is a special keybinding for submitting the name of a new instance.
 
+	// Answer the prompt the selected agent is waiting on. These are special
+	// keybindings, since they only do something while a prompt is pending.
+	KeyPromptAccept
+	KeyPromptReject
+	KeyPromptReply
+	KeyPromptAlways
+
 	KeyCheckout
 	KeyResume
 	KeyPrompt // New key for entering a prompt
@@ -45,6 +54,96 @@ const (
 
 	// Import orphaned sessions
 	KeyImport
+
+	// Undo the last kill or archive
+	KeyUndo
+
+	// Relay output from the selected session into another session
+	KeyRelay
+
+	// Edit the tags and notes of the selected instance
+	KeyNotes
+
+	// Browse output snippets captured from the selected instance
+	KeySnippets
+
+	// Reorder, squash and drop the commits of the selected instance's branch
+	KeyRebase
+
+	// Jump to an instance by its two-digit number
+	KeyJump
+
+	// Commit manual changes as fixups of the commits they touch
+	KeyFixup
+
+	// Show the squad statistics dashboard
+	KeyStats
+
+	// Collapse or expand the agent's todo list in the preview
+	KeyTodos
+
+	// Add the selected instance to the merge queue, or take it out
+	KeyLand
+
+	// Run the check command in the selected instance's worktree
+	KeyCheck
+
+	// Show the squad as a board
+	KeyBoard
+
+	// Show a timeline of the squad's activity
+	KeyTimeline
+
+	// Show the audit log of all instances
+	KeyAudit
+
+	// Clear the quarantine of the selected instance
+	KeyQuarantine
+
+	// Find an instance by title, branch or repo
+	KeyPicker
+
+	// Change what the diff tab compares the worktree with
+	KeyDiffTarget
+
+	// Open the file selected in the files tab in $EDITOR
+	KeyEditFile
+
+	// Open the selected instance's worktree in the configured editor or IDE
+	KeyOpenWorktree
+
+	// Check out the selected instance's changes in the main repository without pausing it
+	KeyCheckoutRunning
+
+	// Pause every running instance, or resume every paused one
+	KeyPauseAll
+	KeyResumeAll
+
+	// Type into the selected instance while its preview stays on screen
+	KeyInlineAttach
+
+	// Create an instance working on a GitHub issue
+	KeyIssue
+
+	// Merge the default branch into the selected instance's branch
+	KeyMergeBase
+
+	// Switch the session type of the selected paused instance
+	KeySwitchMode
+
+	// Switch the preview between the agent and the auxiliary command
+	KeyAuxView
+	// Set the auxiliary command of the selected instance
+	KeyAuxCommand
+
+	// Show the live render profiler. It's left out of the help.
+	KeyProfiler
+
+	// Keys of the prompt overlay and of attached sessions, which handle them
+	// themselves. They are in the keymap for the help.
+	KeyEditor
+	KeyPromptFile
+	KeyDetach
 )
 
 // GlobalKeyStringsMap is a global, immutable map string to keybinding.
@@ -59,7 +158,11 @@ var GlobalKeyStringsMap = map[string]KeyName{
 	"J":          KeyMoveDown,
 	"N":          KeyPrompt,
 	"enter":      KeyEnter,
-	"o":          KeyEnter,
+	"o":          KeyOpenWorktree,
+	"W":          KeyCheckoutRunning,
+	"P":          KeyPauseAll,
+	"U":          KeyResumeAll,
+	"a":          KeyInlineAttach,
 	"n":          KeyNew,
 	"D":          KeyKill,
 	"q":          KeyQuit,
@@ -67,12 +170,38 @@ var GlobalKeyStringsMap = map[string]KeyName{
 	"c":          KeyCheckout,
 	"r":          KeyResume,
 	"p":          KeySubmit,
+	"G":          KeyOpenCI,
 	"?":          KeyHelp,
 	"R":     KeyRename,
 	"A":     KeyArchive,
 	"left":  KeyFilterLeft,
 	"right": KeyFilterRight,
 	"i":     KeyImport,
+	"u":     KeyUndo,
+	"F":     KeyRelay,
+	"t":     KeyNotes,
+	"y":     KeySnippets,
+	"b":     KeyRebase,
+	"g":     KeyJump,
+	"f":     KeyFixup,
+	"S":     KeyStats,
+	"T":     KeyTodos,
+	"v":     KeyReview,
+	"L":     KeyLand,
+	"C":     KeyCheck,
+	"B":     KeyBoard,
+	"H":     KeyTimeline,
+	"E":     KeyAudit,
+	"Q":     KeyQuarantine,
+	"ctrl+p": KeyPicker,
+	"d":      KeyDiffTarget,
+	"e":      KeyEditFile,
+	"I":      KeyIssue,
+	"M":      KeyMergeBase,
+	"X":      KeySwitchMode,
+	"O":      KeyAuxView,
+	"V":      KeyAuxCommand,
+	"ctrl+d": KeyProfiler,
 }
 
 // GlobalkeyBindings is a global, immutable map of KeyName tot keybinding.
@@ -94,8 +223,8 @@ var GlobalkeyBindings = map[KeyName]key.Binding{
 		key.WithHelp("shift+↓", "scroll"),
 	),
 	KeyEnter: key.NewBinding(
-		key.WithKeys("enter", "o"),
-		key.WithHelp("↵/o", "open"),
+		key.WithKeys("enter"),
+		key.WithHelp("↵", "open"),
 	),
 	KeyNew: key.NewBinding(
 		key.WithKeys("n"),
@@ -117,6 +246,10 @@ var GlobalkeyBindings = map[KeyName]key.Binding{
 		key.WithKeys("p"),
 		key.WithHelp("p", "push branch"),
 	),
+	KeyOpenCI: key.NewBinding(
+		key.WithKeys("G"),
+		key.WithHelp("G", "open failed CI"),
+	),
 	KeyPrompt: key.NewBinding(
 		key.WithKeys("N"),
 		key.WithHelp("N", "new with prompt"),
@@ -161,6 +294,126 @@ var GlobalkeyBindings = map[KeyName]key.Binding{
 		key.WithKeys("i"),
 		key.WithHelp("i", "import"),
 	),
+	KeyUndo: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "undo"),
+	),
+	KeyRelay: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "relay output"),
+	),
+	KeyNotes: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "tags/notes"),
+	),
+	KeySnippets: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "snippets"),
+	),
+	KeyRebase: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "rebase"),
+	),
+	KeyJump: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "jump"),
+	),
+	KeyFixup: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "fixup"),
+	),
+	KeyStats: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "stats"),
+	),
+	KeyTodos: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "todos"),
+	),
+	KeyReview: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "review"),
+	),
+	KeyLand: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("L", "land"),
+	),
+	KeyCheck: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "check"),
+	),
+	KeyBoard: key.NewBinding(
+		key.WithKeys("B"),
+		key.WithHelp("B", "board"),
+	),
+	KeyTimeline: key.NewBinding(
+		key.WithKeys("H"),
+		key.WithHelp("H", "timeline"),
+	),
+	KeyAudit: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "audit log"),
+	),
+	KeyQuarantine: key.NewBinding(
+		key.WithKeys("Q"),
+		key.WithHelp("Q", "clear quarantine"),
+	),
+	KeyPicker: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "go to"),
+	),
+	KeyDiffTarget: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "diff against"),
+	),
+	KeyEditFile: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit file"),
+	),
+	KeyOpenWorktree: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "open in editor"),
+	),
+	KeyCheckoutRunning: key.NewBinding(
+		key.WithKeys("W"),
+		key.WithHelp("W", "checkout (keep running)"),
+	),
+	KeyPauseAll: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "pause all"),
+	),
+	KeyResumeAll: key.NewBinding(
+		key.WithKeys("U"),
+		key.WithHelp("U", "resume all"),
+	),
+	KeyInlineAttach: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "type in preview"),
+	),
+	KeyIssue: key.NewBinding(
+		key.WithKeys("I"),
+		key.WithHelp("I", "new from issue"),
+	),
+	KeyMergeBase: key.NewBinding(
+		key.WithKeys("M"),
+		key.WithHelp("M", "merge main"),
+	),
+	KeySwitchMode: key.NewBinding(
+		key.WithKeys("X"),
+		key.WithHelp("X", "switch mode"),
+	),
+	KeyAuxView: key.NewBinding(
+		key.WithKeys("O"),
+		key.WithHelp("O", "aux output"),
+	),
+	KeyAuxCommand: key.NewBinding(
+		key.WithKeys("V"),
+		key.WithHelp("V", "aux command"),
+	),
+	KeyProfiler: key.NewBinding(
+		key.WithKeys("ctrl+d"),
+		key.WithHelp("ctrl+d", "profiler"),
+	),
 
 	// -- Special keybindings --
 
@@ -168,4 +421,32 @@ var GlobalkeyBindings = map[KeyName]key.Binding{
 		key.WithKeys("enter"),
 		key.WithHelp("enter", "submit name"),
 	),
+	KeyPromptAccept: key.NewBinding(
+		key.WithKeys("alt+y"),
+		key.WithHelp("alt+y", "accept"),
+	),
+	KeyPromptReject: key.NewBinding(
+		key.WithKeys("alt+n"),
+		key.WithHelp("alt+n", "reject"),
+	),
+	KeyPromptReply: key.NewBinding(
+		key.WithKeys("alt+e"),
+		key.WithHelp("alt+e", "reply"),
+	),
+	KeyPromptAlways: key.NewBinding(
+		key.WithKeys("alt+a"),
+		key.WithHelp("alt+a", "always"),
+	),
+	KeyEditor: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("ctrl+o", "edit in $EDITOR"),
+	),
+	KeyPromptFile: key.NewBinding(
+		key.WithKeys("ctrl+l"),
+		key.WithHelp("ctrl+l", "load from file"),
+	),
+	KeyDetach: key.NewBinding(
+		key.WithKeys("ctrl+q"),
+		key.WithHelp("ctrl+q", "detach"),
+	),
 }
@@ -11,6 +11,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -66,14 +67,27 @@ func Debug(format string, v ...interface{}) {
 	}
 }
 
+// slowFrameThreshold is how long a frame may take at 60fps.
+const slowFrameThreshold = 16 * time.Millisecond
+
+// PollComponentPrefix starts the name of the component that records how long
+// polling an instance, which captures its pane, takes. The title of the
+// instance follows.
+const PollComponentPrefix = "poll "
+
 // RenderProfiler tracks rendering performance metrics.
 type RenderProfiler struct {
 	mu           sync.RWMutex
 	components   map[string]*ComponentMetrics
 	frameCount   int64
+	slowFrames   int64
 	totalTime    time.Duration
 	lastFrameAt  time.Time
 	frameTimings []time.Duration // Rolling window of frame times
+	frameTimes   []time.Time     // When each frame of frameTimings was rendered
+
+	// live records metrics without debug mode while they are shown
+	live atomic.Bool
 }
 
 // ComponentMetrics tracks metrics for a single component.
@@ -83,6 +97,7 @@ type ComponentMetrics struct {
 	TotalTime    time.Duration
 	MinTime      time.Duration
 	MaxTime      time.Duration
+	LastTime     time.Duration
 	LastRenderAt time.Time
 }
 
@@ -90,6 +105,7 @@ type ComponentMetrics struct {
 var profiler = &RenderProfiler{
 	components:   make(map[string]*ComponentMetrics),
 	frameTimings: make([]time.Duration, 0, 100),
+	frameTimes:   make([]time.Time, 0, 100),
 }
 
 // GetProfiler returns the global render profiler.
@@ -97,10 +113,21 @@ func GetProfiler() *RenderProfiler {
 	return profiler
 }
 
+// SetLive makes the profiler record metrics even without debug mode, e.g.
+// while they are shown in the TUI.
+func (p *RenderProfiler) SetLive(live bool) {
+	p.live.Store(live)
+}
+
+// enabled returns true if metrics are recorded.
+func (p *RenderProfiler) enabled() bool {
+	return DebugEnabled || p.live.Load()
+}
+
 // StartRender begins timing a component render.
 // Returns a function to call when render completes.
 func (p *RenderProfiler) StartRender(component string) func() {
-	if !DebugEnabled {
+	if !p.enabled() {
 		return func() {}
 	}
 
@@ -111,6 +138,18 @@ func (p *RenderProfiler) StartRender(component string) func() {
 	}
 }
 
+// RecordDuration records how long an operation of a component took, e.g. the
+// capture of an instance's pane.
+func (p *RenderProfiler) RecordDuration(component string, elapsed time.Duration) {
+	if !p.enabled() {
+		return
+	}
+	p.recordRender(component, elapsed)
+	if elapsed > time.Second && DebugLog != nil {
+		DebugLog.Printf("SLOW %s: %v", component, elapsed)
+	}
+}
+
 // recordRender records a render timing.
 func (p *RenderProfiler) recordRender(component string, elapsed time.Duration) {
 	p.mu.Lock()
@@ -128,6 +167,7 @@ func (p *RenderProfiler) recordRender(component string, elapsed time.Duration) {
 
 	metrics.RenderCount++
 	metrics.TotalTime += elapsed
+	metrics.LastTime = elapsed
 	metrics.LastRenderAt = time.Now()
 
 	if elapsed < metrics.MinTime {
@@ -140,7 +180,7 @@ func (p *RenderProfiler) recordRender(component string, elapsed time.Duration) {
 
 // RecordFrame records a complete frame render.
 func (p *RenderProfiler) RecordFrame(elapsed time.Duration) {
-	if !DebugEnabled {
+	if !p.enabled() {
 		return
 	}
 
@@ -154,15 +194,59 @@ func (p *RenderProfiler) RecordFrame(elapsed time.Duration) {
 	// Keep rolling window of last 100 frame times
 	if len(p.frameTimings) >= 100 {
 		p.frameTimings = p.frameTimings[1:]
+		p.frameTimes = p.frameTimes[1:]
 	}
 	p.frameTimings = append(p.frameTimings, elapsed)
+	p.frameTimes = append(p.frameTimes, p.lastFrameAt)
 
 	// Log slow frames (> 16ms = 60fps threshold)
-	if elapsed > 16*time.Millisecond && DebugLog != nil {
-		DebugLog.Printf("SLOW FRAME: %v", elapsed)
+	if elapsed > slowFrameThreshold {
+		p.slowFrames++
+		if DebugEnabled && DebugLog != nil {
+			DebugLog.Printf("SLOW FRAME: %v", elapsed)
+		}
 	}
 }
 
+// ProfileSnapshot is a copy of the metrics of the profiler.
+type ProfileSnapshot struct {
+	Frames     int64
+	SlowFrames int64
+	// FPS is how many frames were rendered in the last second
+	FPS int
+	// RecentAvg and RecentMax are the average and longest of the last 100 frames
+	RecentAvg time.Duration
+	RecentMax time.Duration
+	// Components are sorted by name
+	Components []ComponentMetrics
+}
+
+// Snapshot returns a copy of the current metrics.
+func (p *RenderProfiler) Snapshot(now time.Time) ProfileSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	snapshot := ProfileSnapshot{Frames: p.frameCount, SlowFrames: p.slowFrames}
+	var sum time.Duration
+	for i, elapsed := range p.frameTimings {
+		sum += elapsed
+		snapshot.RecentMax = max(snapshot.RecentMax, elapsed)
+		if now.Sub(p.frameTimes[i]) <= time.Second {
+			snapshot.FPS++
+		}
+	}
+	if len(p.frameTimings) > 0 {
+		snapshot.RecentAvg = sum / time.Duration(len(p.frameTimings))
+	}
+	for _, m := range p.components {
+		snapshot.Components = append(snapshot.Components, *m)
+	}
+	sort.Slice(snapshot.Components, func(i, j int) bool {
+		return snapshot.Components[i].Name < snapshot.Components[j].Name
+	})
+	return snapshot
+}
+
 // GetStats returns a summary of render statistics.
 func (p *RenderProfiler) GetStats() string {
 	if !DebugEnabled {
@@ -175,6 +259,7 @@ func (p *RenderProfiler) GetStats() string {
 	var sb strings.Builder
 	sb.WriteString("\n=== Render Profile ===\n")
 	sb.WriteString(fmt.Sprintf("Total frames: %d\n", p.frameCount))
+	sb.WriteString(fmt.Sprintf("Slow frames: %d\n", p.slowFrames))
 
 	if p.frameCount > 0 {
 		avgFrame := p.totalTime / time.Duration(p.frameCount)
@@ -239,8 +324,10 @@ func (p *RenderProfiler) Reset() {
 
 	p.components = make(map[string]*ComponentMetrics)
 	p.frameCount = 0
+	p.slowFrames = 0
 	p.totalTime = 0
 	p.frameTimings = make([]time.Duration, 0, 100)
+	p.frameTimes = make([]time.Time, 0, 100)
 }
 
 // ComponentTrace logs component lifecycle events.
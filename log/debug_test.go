@@ -124,6 +124,42 @@ func TestRecordFrame(t *testing.T) {
 	}
 }
 
+func TestSnapshot(t *testing.T) {
+	profiler.Reset()
+	DebugEnabled = false
+	defer profiler.SetLive(false)
+
+	profiler.RecordFrame(time.Millisecond)
+	if profiler.frameCount != 0 {
+		t.Fatal("Should not record when disabled")
+	}
+
+	// A live profiler records without debug mode
+	profiler.SetLive(true)
+	profiler.RecordFrame(10 * time.Millisecond)
+	profiler.RecordFrame(30 * time.Millisecond)
+	profiler.RecordDuration(PollComponentPrefix+"api", 5*time.Millisecond)
+
+	snapshot := profiler.Snapshot(time.Now())
+	if snapshot.Frames != 2 || snapshot.SlowFrames != 1 {
+		t.Errorf("Expected 2 frames and 1 slow frame, got %d and %d", snapshot.Frames, snapshot.SlowFrames)
+	}
+	if snapshot.FPS != 2 {
+		t.Errorf("Expected 2 fps, got %d", snapshot.FPS)
+	}
+	if snapshot.RecentAvg != 20*time.Millisecond || snapshot.RecentMax != 30*time.Millisecond {
+		t.Errorf("Expected avg 20ms and max 30ms, got %v and %v", snapshot.RecentAvg, snapshot.RecentMax)
+	}
+	if len(snapshot.Components) != 1 || snapshot.Components[0].LastTime != 5*time.Millisecond {
+		t.Errorf("Expected the poll of api, got %+v", snapshot.Components)
+	}
+
+	// Frames older than a second don't count towards the fps
+	if later := profiler.Snapshot(time.Now().Add(2 * time.Second)); later.FPS != 0 {
+		t.Errorf("Expected 0 fps two seconds later, got %d", later.FPS)
+	}
+}
+
 func TestGetStats(t *testing.T) {
 	profiler.Reset()
 	DebugEnabled = true
@@ -2,19 +2,28 @@ package main
 
 import (
 	"claude-squad/app"
+	"claude-squad/audit"
 	cmd2 "claude-squad/cmd"
 	"claude-squad/config"
+	"claude-squad/create"
 	"claude-squad/daemon"
 	"claude-squad/log"
 	"claude-squad/session"
 	"claude-squad/session/git"
 	"claude-squad/session/zellij"
+	"claude-squad/watch"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
@@ -22,7 +31,26 @@ var (
 	programFlag string
 	autoYesFlag bool
 	daemonFlag  bool
-	rootCmd     = &cobra.Command{
+	fastStart   bool
+
+	trashPurgeAllFlag bool
+	watchJSONFlag     bool
+	sendWaitFlag      bool
+	sendTimeoutFlag   time.Duration
+	auditJSONFlag     bool
+	statusShortFlag   bool
+	digestHoursFlag   int
+	digestSendFlag    bool
+	newPathFlag       string
+	newProgramFlag    string
+	newPromptFlag     string
+	newPromptFileFlag string
+	newTitleFlag      string
+	supportPathFlag   string
+	// everyoneFlag makes cleanup commands ignore the identity and act on everyone's instances
+	everyoneFlag bool
+
+	rootCmd = &cobra.Command{
 		Use:   "claude-squad",
 		Short: "Claude Squad - Manage multiple AI agents like Claude Code, Aider, Codex, and Amp.",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -34,12 +62,14 @@ var (
 
 			if daemonFlag {
 				cfg := config.LoadConfig()
-				err := daemon.RunDaemon(cfg)
+				initAudit(audit.InitiatorDaemon, cfg.CurrentIdentity())
+				err := daemon.RunDaemon(cfg, autoYesFlag)
 				log.ErrorLog.Printf("failed to start daemon %v", err)
 				return err
 			}
 
 			cfg := config.LoadConfig()
+			initAudit(audit.InitiatorTUI, cfg.CurrentIdentity())
 
 			// Program flag overrides config
 			program := cfg.DefaultProgram
@@ -51,9 +81,10 @@ var (
 			if autoYesFlag {
 				autoYes = true
 			}
-			if autoYes {
+			// The daemon accepts prompts with auto-yes, keeps the bot connected and sends the digest
+			if autoYes || cfg.Bot != nil || cfg.DigestIntervalHours > 0 {
 				defer func() {
-					if err := daemon.LaunchDaemon(); err != nil {
+					if err := daemon.LaunchDaemon(autoYes); err != nil {
 						log.ErrorLog.Printf("failed to launch daemon: %v", err)
 					}
 				}()
@@ -63,7 +94,7 @@ var (
 				log.ErrorLog.Printf("failed to stop daemon: %v", err)
 			}
 
-			return app.Run(ctx, program, autoYes)
+			return app.Run(ctx, program, autoYes, fastStart)
 		},
 	}
 
@@ -79,6 +110,23 @@ var (
 			if err != nil {
 				return fmt.Errorf("failed to initialize storage: %w", err)
 			}
+			cfg := config.LoadConfig()
+			identity := cfg.CurrentIdentity()
+			initAudit(audit.InitiatorCLI, identity)
+			// On a shared machine only your own instances are reset
+			if identity != "" && !everyoneFlag {
+				return resetOwnInstances(storage, identity, cfg.TrashRetentionDays)
+			}
+			if titles, err := storage.Titles(); err == nil {
+				for _, title := range titles {
+					audit.Record(audit.EventKill, title, "reset")
+				}
+			}
+			// The repositories are needed to find worktrees kept in their worktree_root
+			repoPaths, err := storage.RepoPaths()
+			if err != nil {
+				log.WarningLog.Printf("failed to get the repositories of the instances: %v", err)
+			}
 			if err := storage.DeleteAllInstances(); err != nil {
 				return fmt.Errorf("failed to reset storage: %w", err)
 			}
@@ -92,7 +140,7 @@ var (
 				fmt.Println("Zellij sessions have been cleaned up")
 			}
 
-			if err := git.CleanupWorktrees(); err != nil {
+			if err := git.CleanupWorktrees(repoPaths); err != nil {
 				return fmt.Errorf("failed to cleanup worktrees: %w", err)
 			}
 			fmt.Println("Worktrees have been cleaned up")
@@ -128,6 +176,328 @@ var (
 		},
 	}
 
+	trashCmd = &cobra.Command{
+		Use:   "trash",
+		Short: "Manage branches of killed instances kept in the trash",
+	}
+
+	trashListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List trashed instances",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			entries, err := git.ListTrash()
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Println("Trash is empty")
+				return nil
+			}
+			for _, entry := range entries {
+				owner := ""
+				if entry.Owner != "" {
+					owner = " @" + entry.Owner
+				}
+				fmt.Printf("%-30s %-40s %s (%s)%s\n", entry.Title, entry.BranchName,
+					entry.DeletedAt.Format(time.RFC822), filepath.Base(entry.RepoPath), owner)
+			}
+			return nil
+		},
+	}
+
+	trashRestoreCmd = &cobra.Command{
+		Use:               "restore <title|branch>",
+		Short:             "Restore the branch of a trashed instance",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTrashEntries,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			entry, err := findTrashEntry(args[0])
+			if err != nil {
+				return err
+			}
+			if err := entry.Restore(); err != nil {
+				return err
+			}
+			fmt.Printf("Restored branch %s in %s\n", entry.BranchName, entry.RepoPath)
+			return nil
+		},
+	}
+
+	trashPurgeCmd = &cobra.Command{
+		Use:   "purge",
+		Short: "Delete trashed instances older than the retention period",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			cfg := config.LoadConfig()
+			retentionDays := cfg.TrashRetentionDays
+			if trashPurgeAllFlag {
+				retentionDays = 0
+			}
+			// On a shared machine only your own entries are purged
+			owner := cfg.CurrentIdentity()
+			if everyoneFlag {
+				owner = ""
+			}
+			purged, err := git.PurgeExpiredTrash(retentionDays, owner)
+			fmt.Printf("Purged %d trash entries\n", purged)
+			return err
+		},
+	}
+
+	watchCmd = &cobra.Command{
+		Use:   "watch",
+		Short: "Print a line whenever an instance changes status, finishes or waits for input",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			return watch.Run(config.LoadConfig(), os.Stdout, watchJSONFlag)
+		},
+	}
+
+	attachCmd = &cobra.Command{
+		Use:               "attach <title>",
+		Short:             "Attach to an instance from the shell, without the TUI",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeInstanceTitles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			return attachInstance(args[0])
+		},
+	}
+
+	sendCmd = &cobra.Command{
+		Use:               "send <title> <prompt>",
+		Short:             "Send a prompt to an instance, optionally waiting until the agent is done",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeInstanceTitles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			cfg := config.LoadConfig()
+			initAudit(audit.InitiatorCLI, cfg.CurrentIdentity())
+			return sendPrompt(cfg, args[0], args[1], sendWaitFlag, sendTimeoutFlag)
+		},
+	}
+
+	newCmd = &cobra.Command{
+		Use:   "new <title>",
+		Short: "Create and start an instance without the TUI, optionally with a prompt",
+		Long: `Create and start an instance in the repository at --path, with the same defaults as the TUI.
+The prompt is given with --prompt or read from the file at --prompt-file, where - reads it from stdin.
+Close the TUI first, since it saves its own list of instances over the stored one.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			prompt := newPromptFlag
+			if newPromptFileFlag != "" {
+				var err error
+				if prompt, err = session.ReadPromptFile(newPromptFileFlag, os.Stdin); err != nil {
+					return err
+				}
+			}
+
+			cfg := config.LoadConfig()
+			initAudit(audit.InitiatorCLI, cfg.CurrentIdentity())
+			return create.Instance(cfg, args[0], newPathFlag, newProgramFlag, prompt, nil)
+		},
+	}
+
+	fromIssueCmd = &cobra.Command{
+		Use:   "from-issue <owner/repo#123|#123|issue URL>",
+		Short: "Create and start an instance working on a GitHub issue",
+		Long: `Create and start an instance in the repository at --path that works on a GitHub issue, fetched with gh.
+The instance is titled after the issue, its prompt is the title and body of the issue, and commits pushed
+from it reference the issue. #123 refers to an issue of the repository at --path.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			repo, number, err := git.ParseIssueRef(args[0])
+			if err != nil {
+				return err
+			}
+			issue, err := git.FetchIssue(newPathFlag, repo, number)
+			if err != nil {
+				return err
+			}
+			title := newTitleFlag
+			if title == "" {
+				title = session.IssueTitle(issue)
+			}
+
+			cfg := config.LoadConfig()
+			initAudit(audit.InitiatorCLI, cfg.CurrentIdentity())
+			return create.Instance(cfg, title, newPathFlag, newProgramFlag, session.IssuePrompt(issue), session.NewIssueLink(issue))
+		},
+	}
+
+	planCmd = &cobra.Command{
+		Use:   "plan <tasks.yaml>",
+		Short: "Create an instance for each task in a YAML file",
+		Long: `Create an instance for each task in a YAML file, like:
+
+  tasks:
+    - title: fix-login
+      repo: ../api          # relative to the file, defaults to its directory
+      program: aider        # defaults to the configured program
+      prompt: Fix the validation of the login form
+    - title: update-docs
+      prompt_file: tasks/docs.md
+
+Instances are started until max_running agents are working. The others are saved as pending and started
+by the TUI once a slot frees. Close the TUI first, since it saves its own list of instances over the stored one.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			cfg := config.LoadConfig()
+			initAudit(audit.InitiatorCLI, cfg.CurrentIdentity())
+			return create.Plan(cfg, args[0])
+		},
+	}
+
+	statusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Print the status of the instances, or a one-line summary with --short",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			initLogForStdout()
+
+			storage, err := session.NewStorage(config.LoadState())
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			// The saved statuses are enough, so no session is restored
+			storage.SetDeferRestore(true)
+			instances, err := storage.LoadInstances()
+			if err != nil {
+				return fmt.Errorf("failed to load instances: %w", err)
+			}
+
+			if !statusShortFlag {
+				for _, instance := range instances {
+					if instance.Archived || instance.Tombstoned() {
+						continue
+					}
+					status := instance.Status.String()
+					if instance.NeedsAttention() {
+						status += " (needs attention)"
+					}
+					fmt.Printf("%-32s %-26s %s\n", instance.Title, status, instance.Branch)
+				}
+			}
+			fmt.Println(session.SquadStatusLine(instances))
+			return nil
+		},
+	}
+
+	digestCmd = &cobra.Command{
+		Use:   "digest",
+		Short: "Print a digest of what each instance did, or send it with --send",
+		Long: `Print a digest of each instance: its status, summary, diff stats, the prompts auto-yes accepted and its errors.
+The digest covers the time since the daemon last sent one, or the last day, unless --hours is given. With --send it is
+delivered with digest_command and to digest_url from the config, like the digest the daemon sends every
+digest_interval_hours.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			state := config.LoadState()
+			storage, err := session.NewStorage(state)
+			if err != nil {
+				return fmt.Errorf("failed to initialize storage: %w", err)
+			}
+			// The saved instances are enough, so no session is restored
+			storage.SetDeferRestore(true)
+			instances, err := storage.LoadInstances()
+			if err != nil {
+				return fmt.Errorf("failed to load instances: %w", err)
+			}
+
+			now := time.Now()
+			since := now.Add(-24 * time.Hour)
+			if digestHoursFlag > 0 {
+				since = now.Add(-time.Duration(digestHoursFlag) * time.Hour)
+			} else if last := state.GetLastDigestAt(); last != nil {
+				since = *last
+			}
+			digest := session.NewDigest(instances, since, now)
+			if digestSendFlag {
+				return session.SendDigest(config.LoadConfig(), digest)
+			}
+			fmt.Print(digest.String())
+			return nil
+		},
+	}
+
+	supportFilesCmd = &cobra.Command{
+		Use:   "support-files",
+		Short: "Print the files copy_files and link_files would bring into new worktrees, without copying them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			repoPath, files, err := git.ListSupportFiles(supportPathFlag)
+			if err != nil {
+				return err
+			}
+			if len(files) == 0 {
+				fmt.Printf("No ignored or untracked files of %s match copy_files or link_files in %s\n", repoPath, config.RepoConfigFileName)
+				return nil
+			}
+			for _, file := range files {
+				action := "copy"
+				if file.Link {
+					action = "link"
+				}
+				fmt.Printf("%s %s\n", action, file.Path)
+			}
+			return nil
+		},
+	}
+
+	auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Print the audit log of created, prompted, pushed and killed instances",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Nothing is logged, and closing the log would print to stdout
+			events, err := audit.Read()
+			if err != nil {
+				return err
+			}
+			if auditJSONFlag {
+				if events == nil {
+					events = []audit.Event{}
+				}
+				data, err := json.MarshalIndent(events, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			for _, event := range events {
+				fmt.Println(event)
+			}
+			return nil
+		},
+	}
+
 	versionCmd = &cobra.Command{
 		Use:   "version",
 		Short: "Print the version number of claude-squad",
@@ -143,6 +513,8 @@ func init() {
 		"Program to run in new instances (e.g. 'aider --model ollama_chat/gemma3:1b')")
 	rootCmd.Flags().BoolVarP(&autoYesFlag, "autoyes", "y", false,
 		"[experimental] If enabled, all instances will automatically accept prompts")
+	rootCmd.Flags().BoolVar(&fastStart, "fast-start", false,
+		"Start faster by restoring each session only once its instance is selected")
 	rootCmd.Flags().BoolVar(&daemonFlag, "daemon", false, "Run a program that loads all sessions"+
 		" and runs autoyes mode on them.")
 
@@ -152,11 +524,279 @@ func init() {
 		panic(err)
 	}
 
+	trashPurgeCmd.Flags().BoolVar(&trashPurgeAllFlag, "all", false, "Purge all trash entries, regardless of age")
+	trashPurgeCmd.Flags().BoolVar(&everyoneFlag, "everyone", false, "Purge the entries of every identity, not only yours")
+	resetCmd.Flags().BoolVar(&everyoneFlag, "everyone", false, "Reset the instances of every identity, not only yours")
+	watchCmd.Flags().BoolVar(&watchJSONFlag, "json", false, "Print each event as a JSON object")
+	sendCmd.Flags().BoolVar(&sendWaitFlag, "wait", false, "Wait until the agent is done and print the end of its output")
+	statusCmd.Flags().BoolVar(&statusShortFlag, "short", false, "Print only the one-line summary, e.g. for the tmux status line")
+	digestCmd.Flags().IntVar(&digestHoursFlag, "hours", 0, "Cover the last number of hours")
+	digestCmd.Flags().BoolVar(&digestSendFlag, "send", false, "Send the digest as configured instead of printing it")
+	auditCmd.Flags().BoolVar(&auditJSONFlag, "json", false, "Print the log as a JSON array, e.g. to export it")
+	sendCmd.Flags().DurationVar(&sendTimeoutFlag, "timeout", 0, "Give up waiting after this long, e.g. 10m (default no limit)")
+	newCmd.Flags().StringVar(&newPathFlag, "path", ".", "Repository to create the instance in")
+	newCmd.Flags().StringVarP(&newProgramFlag, "program", "p", "", "Program to run instead of the configured one")
+	newCmd.Flags().StringVar(&newPromptFlag, "prompt", "", "Prompt to send once the program started")
+	newCmd.Flags().StringVar(&newPromptFileFlag, "prompt-file", "", "File to read the prompt from, or - for stdin")
+	newCmd.MarkFlagsMutuallyExclusive("prompt", "prompt-file")
+	fromIssueCmd.Flags().StringVar(&newPathFlag, "path", ".", "Repository to create the instance in")
+	fromIssueCmd.Flags().StringVarP(&newProgramFlag, "program", "p", "", "Program to run instead of the configured one")
+	supportFilesCmd.Flags().StringVar(&supportPathFlag, "path", ".", "Repository whose support files are listed")
+	fromIssueCmd.Flags().StringVar(&newTitleFlag, "title", "", "Title of the instance instead of one made from the issue")
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+
 	rootCmd.AddCommand(debugCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(resetCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(attachCmd)
+	rootCmd.AddCommand(sendCmd)
+	rootCmd.AddCommand(newCmd)
+	rootCmd.AddCommand(planCmd)
+	rootCmd.AddCommand(fromIssueCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(digestCmd)
+	rootCmd.AddCommand(supportFilesCmd)
 }
 
+// resetOwnInstances kills the instances created by the identity and removes them
+// from storage, leaving everyone else's instances alone. Instances that fail to
+// be killed are kept.
+func resetOwnInstances(storage *session.Storage, identity string, trashRetentionDays int) error {
+	instances, err := storage.LoadInstances()
+	if err != nil {
+		return fmt.Errorf("failed to load instances: %w", err)
+	}
+
+	var kept []*session.Instance
+	var errs []error
+	reset := 0
+	for _, instance := range instances {
+		if instance.Owner != identity {
+			kept = append(kept, instance)
+			continue
+		}
+		if err := instance.Kill(trashRetentionDays); err != nil {
+			errs = append(errs, fmt.Errorf("failed to kill %s: %w", instance.Title, err))
+			kept = append(kept, instance)
+			continue
+		}
+		audit.Record(audit.EventKill, instance.Title, "reset")
+		reset++
+	}
+	if err := storage.SaveInstances(kept); err != nil {
+		return fmt.Errorf("failed to save instances: %w", err)
+	}
+
+	fmt.Printf("Reset %d instance(s) of %s; use --everyone to reset all instances\n", reset, identity)
+	return errors.Join(errs...)
+}
+
+// initAudit enables the audit log for this process. Without it the actions
+// still work, they just aren't recorded.
+func initAudit(initiator, identity string) {
+	if err := audit.Initialize(initiator, identity); err != nil {
+		log.WarningLog.Printf("failed to initialize audit log: %v", err)
+	}
+}
+
+// loadRunningInstance loads the stored instance with the given title and
+// restores its session, leaving the other instances alone.
+func loadRunningInstance(title string) (*session.Storage, *session.Instance, error) {
+	storage, err := session.NewStorage(config.LoadState())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	// Only the requested instance needs its session
+	storage.SetDeferRestore(true)
+	instances, err := storage.LoadInstances()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load instances: %w", err)
+	}
+
+	var instance *session.Instance
+	for _, candidate := range instances {
+		if candidate.Title == title && !candidate.Tombstoned() {
+			instance = candidate
+			break
+		}
+	}
+	switch {
+	case instance == nil:
+		return nil, nil, fmt.Errorf("no instance named %q", title)
+	case instance.Archived:
+		return nil, nil, fmt.Errorf("instance %q is archived, unarchive it first", title)
+	case instance.Paused():
+		return nil, nil, fmt.Errorf("instance %q is paused, resume it first", title)
+	}
+
+	if err := instance.Restore(); err != nil {
+		return nil, nil, err
+	}
+	if !instance.SessionAlive() {
+		return nil, nil, fmt.Errorf("the session of instance %q is not running", title)
+	}
+	return storage, instance, nil
+}
+
+// attachInstance attaches the terminal to the stored instance with the given
+// title until the user detaches.
+func attachInstance(title string) error {
+	storage, instance, err := loadRunningInstance(title)
+	if err != nil {
+		return err
+	}
+
+	// The keys go to the session as they are typed, the detach key included,
+	// like when attaching from the TUI
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("failed to set terminal raw mode: %w", err)
+	}
+	defer term.Restore(int(os.Stdin.Fd()), oldState)
+
+	ch, err := instance.Attach()
+	if err != nil {
+		return fmt.Errorf("failed to attach to %q: %w", title, err)
+	}
+	<-ch
+
+	if err := storage.SetLastOpened(title, time.Now()); err != nil {
+		return fmt.Errorf("failed to save last opened time: %w", err)
+	}
+	return nil
+}
+
+// sendPrompt sends the prompt to the stored instance with the given title. With
+// wait set, it waits until the agent is done and prints the end of its output.
+func sendPrompt(cfg *config.Config, title, prompt string, wait bool, timeout time.Duration) error {
+	_, instance, err := loadRunningInstance(title)
+	if err != nil {
+		return err
+	}
+	if err := instance.SendPrompt(prompt); errors.Is(err, session.ErrPromptUnconfirmed) {
+		fmt.Fprintf(os.Stderr, "warning: the prompt was submitted to %q but could not be confirmed as received\n", title)
+	} else if err != nil {
+		return fmt.Errorf("failed to send prompt to %q: %w", title, err)
+	}
+	audit.Record(audit.EventPrompt, title, prompt)
+	if !wait {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := waitForReady(ctx, instance, time.Duration(cfg.DaemonPollInterval)*time.Millisecond); err != nil {
+		return fmt.Errorf("stopped waiting for %q: %w", title, err)
+	}
+
+	output, err := instance.RecentOutput(sendOutputLines)
+	if err != nil {
+		return fmt.Errorf("failed to capture the output of %q: %w", title, err)
+	}
+	fmt.Println(output)
+	return nil
+}
+
+// waitForReady polls the instance until it stops working, by the same rules as
+// the TUI: the output stopped changing after it started changing, or the
+// agent is waiting for input.
+func waitForReady(ctx context.Context, instance *session.Instance, pollInterval time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	working := false
+	for sample := 0; ; sample++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		updated, hasPrompt := instance.HasUpdated()
+		// The first sample only records the output to compare the next ones
+		// to, and always reports an update
+		if sample == 0 {
+			updated = false
+		}
+		switch {
+		case updated:
+			working = true
+		case hasPrompt, working:
+			return nil
+		}
+	}
+}
+
+// initLogForStdout initializes the log for commands whose output is read by
+// another program, like the shell reading completions or tmux reading the
+// status line. The log isn't closed, since closing it prints to stdout.
+func initLogForStdout() {
+	log.Initialize(false)
+}
+
+// completeInstanceTitles completes the first argument of commands that take an
+// instance title with the titles in storage.
+func completeInstanceTitles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	initLogForStdout()
+
+	storage, err := session.NewStorage(config.LoadState())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	titles, err := storage.Titles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return titles, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTrashEntries completes the argument of trash restore with the titles
+// and branch names of the trash entries.
+func completeTrashEntries(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	initLogForStdout()
+
+	entries, err := git.ListTrash()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Title+"\t"+entry.BranchName, entry.BranchName+"\t"+entry.Title)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// findTrashEntry returns the most recent trash entry matching the given title or branch name.
+func findTrashEntry(name string) (*git.TrashEntry, error) {
+	entries, err := git.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.Title == name || entry.BranchName == name {
+			return entry, nil
+		}
+	}
+	return nil, fmt.Errorf("no trash entry found for %q", name)
+}
+
+// sendOutputLines is how many lines of output send --wait prints.
+const sendOutputLines = 40
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"claude-squad/session"
+	"context"
+	"testing"
+	"time"
+)
+
+// scriptedSession reports the given updates in order, then no more.
+type scriptedSession struct {
+	session.Multiplexer
+	updates []bool
+	polls   int
+}
+
+func (s *scriptedSession) HasUpdated() (bool, bool) {
+	s.polls++
+	if s.polls <= len(s.updates) {
+		return s.updates[s.polls-1], false
+	}
+	return false, false
+}
+
+func TestWaitForReadyIgnoresFirstSample(t *testing.T) {
+	// The agent takes a while to start working on the prompt, then works for
+	// two polls. The first sample always reports an update.
+	fake := &scriptedSession{updates: []bool{true, false, false, true, true}}
+	instance := &session.Instance{Title: "worker"}
+	instance.SetSession(fake)
+	instance.MarkAsStartedForTesting()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := waitForReady(ctx, instance, time.Millisecond); err != nil {
+		t.Fatalf("waitForReady() error = %v", err)
+	}
+	if fake.polls != 6 {
+		t.Errorf("waitForReady() returned after %d polls, want 6: once the agent stopped working", fake.polls)
+	}
+}
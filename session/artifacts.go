@@ -0,0 +1,173 @@
+package session
+
+import (
+	"bytes"
+	"claude-squad/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// artifactUploadTimeout bounds each upload of an instance's artifacts.
+const artifactUploadTimeout = 5 * time.Minute
+
+// artifactUploadOutputLines is how many trailing lines of a failed upload
+// command's output are included in its error.
+const artifactUploadOutputLines = 10
+
+// Artifacts is the record of an instance's work that is uploaded when it
+// finishes working.
+type Artifacts struct {
+	Instance   string    `json:"instance"`
+	Branch     string    `json:"branch,omitempty"`
+	Program    string    `json:"program"`
+	Owner      string    `json:"owner,omitempty"`
+	FinishedAt time.Time `json:"finished_at"`
+	Summary    string    `json:"summary,omitempty"`
+	// Diff is the diff of the branch against its base commit.
+	Diff string `json:"diff,omitempty"`
+	// Transcript is the Claude session transcript in JSON lines, if the
+	// program is Claude and its session has been captured.
+	Transcript string `json:"transcript,omitempty"`
+}
+
+// CollectArtifacts gathers the summary, diff and transcript of the instance.
+func (i *Instance) CollectArtifacts(finishedAt time.Time) (*Artifacts, error) {
+	artifacts := &Artifacts{
+		Instance:   i.Title,
+		Branch:     i.Branch,
+		Program:    i.Program,
+		Owner:      i.Owner,
+		FinishedAt: finishedAt,
+		Summary:    i.Summary,
+	}
+
+	if i.gitWorktree != nil {
+		stats := i.gitWorktree.Diff()
+		if stats.Error != nil {
+			return nil, fmt.Errorf("failed to get diff: %w", stats.Error)
+		}
+		artifacts.Diff = stats.Content
+	}
+
+	if i.ClaudeSessionID != "" {
+		path, err := ClaudeTranscriptPath(i.claudeWorkingDir(), i.ClaudeSessionID)
+		if err != nil {
+			return nil, err
+		}
+		transcript, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read transcript: %w", err)
+		}
+		artifacts.Transcript = string(transcript)
+	}
+	return artifacts, nil
+}
+
+// UploadArtifacts uploads the artifacts with the configured command and to the
+// configured URL. It does nothing if neither is configured.
+func UploadArtifacts(cfg *config.Config, artifacts *Artifacts) error {
+	ctx, cancel := context.WithTimeout(context.Background(), artifactUploadTimeout)
+	defer cancel()
+
+	var errs []error
+	if strings.TrimSpace(cfg.ArtifactUploadCommand) != "" {
+		if err := uploadArtifactsWithCommand(ctx, cfg.ArtifactUploadCommand, artifacts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if strings.TrimSpace(cfg.ArtifactUploadURL) != "" {
+		if err := postArtifacts(ctx, cfg.ArtifactUploadURL, os.Getenv(config.ArtifactUploadTokenEnv), artifacts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// uploadArtifactsWithCommand writes the artifacts to files in a temporary
+// directory and runs the command with the directory in $CS_ARTIFACT_DIR.
+func uploadArtifactsWithCommand(ctx context.Context, command string, artifacts *Artifacts) error {
+	dir, err := os.MkdirTemp("", "claude-squad-artifacts-")
+	if err != nil {
+		return fmt.Errorf("failed to create artifact directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := writeArtifacts(dir, artifacts); err != nil {
+		return err
+	}
+
+	cmd := config.ShellCommand(ctx, command)
+	cmd.Env = append(os.Environ(),
+		"CS_ARTIFACT_DIR="+dir,
+		"CS_INSTANCE="+artifacts.Instance,
+		"CS_BRANCH="+artifacts.Branch,
+	)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		tail := lastLines(strings.TrimRight(output.String(), "\n"), artifactUploadOutputLines)
+		return fmt.Errorf("artifact upload command failed: %w\n%s", err, tail)
+	}
+	return nil
+}
+
+// writeArtifacts writes metadata.json, summary.txt, diff.patch and
+// transcript.jsonl to dir. Empty artifacts are left out.
+func writeArtifacts(dir string, artifacts *Artifacts) error {
+	metadata := *artifacts
+	metadata.Summary, metadata.Diff, metadata.Transcript = "", "", ""
+	metadataJSON, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal artifact metadata: %w", err)
+	}
+
+	files := map[string]string{
+		"metadata.json":    string(metadataJSON) + "\n",
+		"summary.txt":      artifacts.Summary,
+		"diff.patch":       artifacts.Diff,
+		"transcript.jsonl": artifacts.Transcript,
+	}
+	for name, content := range files {
+		if content == "" {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// postArtifacts sends the artifacts to the URL as a JSON POST request.
+func postArtifacts(ctx context.Context, url, token string, artifacts *Artifacts) error {
+	body, err := json.Marshal(artifacts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal artifacts: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid artifact upload URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload artifacts: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to upload artifacts: %s", resp.Status)
+	}
+	return nil
+}
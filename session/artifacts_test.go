@@ -0,0 +1,63 @@
+package session
+
+import (
+	"claude-squad/config"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestUploadArtifacts(t *testing.T) {
+	artifacts := &Artifacts{
+		Instance:   "login",
+		Branch:     "alice/login",
+		Program:    "claude",
+		FinishedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Summary:    "Fixed the login redirect",
+		Diff:       "+fix\n",
+	}
+
+	var received Artifacts
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	// The command copies the artifact directory, which is removed afterwards
+	copyDir := filepath.Join(t.TempDir(), "copy")
+	t.Setenv("COPY_DIR", copyDir)
+	t.Setenv(config.ArtifactUploadTokenEnv, "secret")
+	cfg := &config.Config{
+		ArtifactUploadCommand: `cp -r "$CS_ARTIFACT_DIR" "$COPY_DIR" && test "$CS_INSTANCE" = login`,
+		ArtifactUploadURL:     server.URL,
+	}
+	if err := UploadArtifacts(cfg, artifacts); err != nil {
+		t.Fatalf("UploadArtifacts() error = %v", err)
+	}
+
+	if received.Instance != "login" || received.Diff != "+fix\n" || authorization != "Bearer secret" {
+		t.Errorf("server received %+v with authorization %q", received, authorization)
+	}
+	for name, want := range map[string]string{"summary.txt": artifacts.Summary, "diff.patch": artifacts.Diff} {
+		content, err := os.ReadFile(filepath.Join(copyDir, name))
+		if err != nil || string(content) != want {
+			t.Errorf("%s = %q, %v; want %q", name, content, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(copyDir, "transcript.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("empty transcript was written, stat error = %v", err)
+	}
+
+	cfg = &config.Config{ArtifactUploadCommand: "echo denied; exit 1"}
+	if err := UploadArtifacts(cfg, artifacts); err == nil {
+		t.Error("UploadArtifacts() with a failing command succeeded")
+	}
+}
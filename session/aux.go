@@ -0,0 +1,77 @@
+package session
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"strings"
+)
+
+// AuxPaneName is the name of the pane running the auxiliary command.
+const AuxPaneName = "aux"
+
+// workDir returns the directory the program of the instance runs in.
+func (i *Instance) workDir() string {
+	if i.gitWorktree != nil {
+		return i.gitWorktree.GetWorktreePath()
+	}
+	return i.Path
+}
+
+// startAux starts the auxiliary command next to the program, or reconnects to
+// it if it is still running. A command that fails to start doesn't stop the
+// instance, its error is shown instead of the output.
+func (i *Instance) startAux() {
+	i.auxErr = nil
+	if i.AuxCommand == "" || i.session == nil {
+		return
+	}
+	if err := i.session.StartPane(AuxPaneName, i.AuxCommand, i.workDir()); err != nil {
+		log.WarningLog.Printf("failed to start the auxiliary command of %s: %v", i.Title, err)
+		i.auxErr = err
+	}
+}
+
+// stopAux stops the auxiliary command.
+func (i *Instance) stopAux() {
+	if i.session == nil {
+		return
+	}
+	if err := i.session.ClosePane(AuxPaneName); err != nil {
+		log.WarningLog.Printf("failed to stop the auxiliary command of %s: %v", i.Title, err)
+	}
+}
+
+// SetAuxCommand sets the auxiliary command and restarts it in a running
+// instance. An empty command stops it.
+func (i *Instance) SetAuxCommand(command string) {
+	command = strings.TrimSpace(command)
+	running := i.started && !i.Paused() && i.session != nil
+	if running {
+		i.stopAux()
+	}
+	i.AuxCommand = command
+	if running {
+		i.startAux()
+	}
+}
+
+// AuxError returns why the auxiliary command could not be started, or nil.
+func (i *Instance) AuxError() error {
+	return i.auxErr
+}
+
+// AuxRunning returns true while the auxiliary command runs.
+func (i *Instance) AuxRunning() bool {
+	return i.AuxCommand != "" && i.started && i.session != nil && i.session.PaneRunning(AuxPaneName)
+}
+
+// AuxPreview captures the output of the auxiliary command.
+func (i *Instance) AuxPreview() (string, error) {
+	if i.auxErr != nil {
+		return "", i.auxErr
+	}
+	if !i.started || i.session == nil {
+		return "", fmt.Errorf("'%s' is not running", i.Title)
+	}
+	return i.session.CapturePane(AuxPaneName)
+}
@@ -0,0 +1,77 @@
+package session
+
+import (
+	"claude-squad/log"
+	"claude-squad/session/git"
+	"fmt"
+	"time"
+)
+
+// baseCheckInterval is how often each instance's branch is compared with the
+// default branch, which fetches it from the remote.
+const baseCheckInterval = 10 * time.Minute
+
+// BaseStatus returns how far the branch is behind the default branch, or nil
+// if it wasn't checked yet.
+func (i *Instance) BaseStatus() *git.BaseStatus {
+	return i.baseStatus
+}
+
+// shouldCheckBase returns true if the branch of a running instance is due to
+// be compared with the default branch.
+func (i *Instance) shouldCheckBase(now time.Time) bool {
+	if !i.started || i.Paused() || i.gitWorktree == nil || i.Tombstoned() || i.Archived {
+		return false
+	}
+	return i.baseCheckedAt.IsZero() || now.Sub(i.baseCheckedAt) >= baseCheckInterval
+}
+
+// checkBase compares the branch with the default branch. A failed check keeps
+// the previous status.
+func (i *Instance) checkBase(now time.Time) {
+	i.baseCheckedAt = now
+	status, err := i.gitWorktree.CheckBase()
+	if err != nil {
+		log.WarningLog.Printf("failed to compare %s with the default branch: %v", i.Title, err)
+		return
+	}
+	i.baseStatus = status
+}
+
+// BackgroundCheckBases compares the branches of the instances that are due with
+// the default branch. The checks run one after the other in the background, so
+// that fetches of the same repository don't contend.
+func BackgroundCheckBases(instances []*Instance, now time.Time) {
+	var due []*Instance
+	for _, instance := range instances {
+		if instance != nil && instance.shouldCheckBase(now) {
+			// Claimed right away, so the next tick doesn't check it again
+			instance.baseCheckedAt = now
+			due = append(due, instance)
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+	go func() {
+		for _, instance := range due {
+			instance.checkBase(now)
+		}
+	}()
+}
+
+// MergeDefaultBranch merges the default branch into the instance's branch and
+// updates its diff.
+func (i *Instance) MergeDefaultBranch() error {
+	if !i.started || i.gitWorktree == nil {
+		return fmt.Errorf("session '%s' has no worktree to update", i.Title)
+	}
+	if err := i.gitWorktree.MergeDefaultBranch(); err != nil {
+		return err
+	}
+	i.checkBase(time.Now())
+	if err := i.UpdateDiffStats(); err != nil {
+		log.WarningLog.Printf("failed to update the diff of %s: %v", i.Title, err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"testing"
+	"time"
+)
+
+func TestShouldCheckBase(t *testing.T) {
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	worktree := git.NewGitWorktreeFromStorage("/repo", "/worktree", "api", "alice/api", "abc")
+	instance := &Instance{Title: "api", Status: Running, started: true, gitWorktree: worktree}
+
+	if !instance.shouldCheckBase(now) {
+		t.Error("an instance that was never checked should be due")
+	}
+	instance.baseCheckedAt = now.Add(-time.Minute)
+	if instance.shouldCheckBase(now) {
+		t.Error("an instance checked a minute ago should not be due")
+	}
+	if !instance.shouldCheckBase(now.Add(baseCheckInterval)) {
+		t.Error("an instance should be due again after the check interval")
+	}
+
+	for name, other := range map[string]*Instance{
+		"paused":   {Title: "paused", Status: Paused, started: true, gitWorktree: worktree},
+		"scratch":  {Title: "scratch", Status: Running, started: true},
+		"archived": {Title: "archived", Status: Running, started: true, gitWorktree: worktree, Archived: true},
+	} {
+		if other.shouldCheckBase(now) {
+			t.Errorf("a %s instance should not be checked", name)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package session
+
+// BoardColumn is a column of the board view of the squad.
+type BoardColumn string
+
+const (
+	// BoardQueued holds sessions that are not working yet or are set aside.
+	BoardQueued BoardColumn = "queued"
+	// BoardRunning holds sessions whose agent is working.
+	BoardRunning BoardColumn = "running"
+	// BoardNeedsInput holds sessions waiting for the user.
+	BoardNeedsInput BoardColumn = "needs-input"
+	// BoardDone holds finished sessions.
+	BoardDone BoardColumn = "done"
+	// BoardArchived holds archived sessions. Cards can't be moved in or out of it.
+	BoardArchived BoardColumn = "archived"
+)
+
+// BoardColumns are the columns of the board, left to right.
+var BoardColumns = []BoardColumn{BoardQueued, BoardRunning, BoardNeedsInput, BoardDone, BoardArchived}
+
+// Title returns the heading of the column.
+func (c BoardColumn) Title() string {
+	switch c {
+	case BoardQueued:
+		return "Queued"
+	case BoardRunning:
+		return "Running"
+	case BoardNeedsInput:
+		return "Needs input"
+	case BoardDone:
+		return "Done"
+	case BoardArchived:
+		return "Archived"
+	default:
+		return string(c)
+	}
+}
+
+// Column returns the column of the instance on the board: the one it was moved
+// to, or else the one matching its state.
+func (i *Instance) Column() BoardColumn {
+	if i.Archived {
+		return BoardArchived
+	}
+	if i.BoardColumn != "" {
+		return i.BoardColumn
+	}
+	return i.stateColumn()
+}
+
+// stateColumn returns the column matching the state of the instance.
+func (i *Instance) stateColumn() BoardColumn {
+	switch {
+	case i.LandState == LandLanded:
+		return BoardDone
+	case i.NeedsAttention():
+		return BoardNeedsInput
+	case i.Status == Running:
+		return BoardRunning
+	default:
+		return BoardQueued
+	}
+}
+
+// MoveToColumn moves the instance's card to the column. Moving it back to the
+// column matching its state lets the card follow the state again. It returns
+// false if the card can't be moved there.
+func (i *Instance) MoveToColumn(column BoardColumn) bool {
+	if i.Archived || column == BoardArchived {
+		return false
+	}
+	if column == i.stateColumn() {
+		i.BoardColumn = ""
+	} else {
+		i.BoardColumn = column
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package session
+
+import "testing"
+
+func TestColumn(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance *Instance
+		want     BoardColumn
+	}{
+		{"loading", &Instance{Status: Loading}, BoardQueued},
+		{"paused", &Instance{Status: Paused}, BoardQueued},
+		{"running", &Instance{Status: Running}, BoardRunning},
+		{"ready", &Instance{Status: Ready}, BoardNeedsInput},
+		{"landing failed", &Instance{Status: Running, LandState: LandFailed}, BoardNeedsInput},
+		{"landed", &Instance{Status: Ready, LandState: LandLanded}, BoardDone},
+		{"archived", &Instance{Status: Running, Archived: true, BoardColumn: BoardDone}, BoardArchived},
+		{"moved", &Instance{Status: Running, BoardColumn: BoardDone}, BoardDone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.instance.Column(); got != tt.want {
+				t.Errorf("Column() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoveToColumn(t *testing.T) {
+	instance := &Instance{Status: Running}
+
+	if !instance.MoveToColumn(BoardDone) || instance.Column() != BoardDone {
+		t.Fatalf("Column() = %s after moving to done", instance.Column())
+	}
+
+	// Moving back to the column of its state lets the card follow the state again
+	if !instance.MoveToColumn(BoardRunning) || instance.BoardColumn != "" {
+		t.Errorf("BoardColumn = %q after moving back, want it cleared", instance.BoardColumn)
+	}
+	instance.Status = Ready
+	if got := instance.Column(); got != BoardNeedsInput {
+		t.Errorf("Column() = %s, want %s", got, BoardNeedsInput)
+	}
+
+	if instance.MoveToColumn(BoardArchived) {
+		t.Error("MoveToColumn(BoardArchived) succeeded")
+	}
+	instance.Archived = true
+	if instance.MoveToColumn(BoardQueued) {
+		t.Error("MoveToColumn() succeeded for an archived instance")
+	}
+}
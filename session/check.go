@@ -0,0 +1,86 @@
+package session
+
+import (
+	"bytes"
+	"claude-squad/config"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// checkTimeout bounds how long a check command may run
+	checkTimeout = 30 * time.Minute
+	// checkOutputLines is how many trailing lines of a check's output are kept
+	checkOutputLines = 200
+)
+
+// CheckResult is the outcome of running the check command in an instance's worktree.
+type CheckResult struct {
+	Command    string        `json:"command"`
+	Passed     bool          `json:"passed"`
+	Duration   time.Duration `json:"duration"`
+	Output     string        `json:"output,omitempty"`
+	FinishedAt time.Time     `json:"finished_at"`
+}
+
+// StartCheck marks a check as running on the instance. It fails if a check is
+// running already or the instance has no local directory to run it in.
+func (i *Instance) StartCheck() error {
+	if !i.started || i.Paused() {
+		return fmt.Errorf("only running sessions can be checked")
+	}
+	if i.SessionType == config.SessionTypeDockerClone {
+		return fmt.Errorf("checks can't run in a docker-clone session")
+	}
+	if i.checkRunning {
+		return fmt.Errorf("a check is already running for '%s'", i.Title)
+	}
+	i.checkRunning = true
+	return nil
+}
+
+// CheckRunning returns true while a check started with StartCheck is running.
+func (i *Instance) CheckRunning() bool {
+	return i.checkRunning
+}
+
+// FinishCheck records the result of the running check.
+func (i *Instance) FinishCheck(result *CheckResult) {
+	i.checkRunning = false
+	if result != nil {
+		i.LastCheck = result
+	}
+}
+
+// RunCheck runs the check command with the shell in the instance's worktree and
+// returns its result, keeping the tail of the combined output. It doesn't modify
+// the instance, so it can run in the background; see FinishCheck.
+func (i *Instance) RunCheck(command string) *CheckResult {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	cmd := config.ShellCommand(ctx, command)
+	cmd.Dir = i.claudeWorkingDir()
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	start := time.Now()
+	err := cmd.Run()
+	result := &CheckResult{
+		Command:    command,
+		Passed:     err == nil,
+		Duration:   time.Since(start),
+		Output:     lastLines(strings.TrimRight(output.String(), "\n"), checkOutputLines),
+		FinishedAt: time.Now(),
+	}
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", checkTimeout)
+		}
+		result.Output = strings.TrimLeft(result.Output+"\n\n"+err.Error(), "\n")
+	}
+	return result
+}
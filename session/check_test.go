@@ -0,0 +1,67 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		command    string
+		wantPassed bool
+		wantOutput string
+	}{
+		{"passing", "echo ok", true, "ok"},
+		{"failing", "echo broken >&2; exit 3", false, "broken"},
+		{"runs in the worktree", "test -f marker && echo found", true, "found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "marker"), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+			instance := &Instance{Title: "test", Path: dir}
+
+			result := instance.RunCheck(tt.command)
+			if result.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v (output %q)", result.Passed, tt.wantPassed, result.Output)
+			}
+			if !strings.Contains(result.Output, tt.wantOutput) {
+				t.Errorf("Output = %q, want it to contain %q", result.Output, tt.wantOutput)
+			}
+			if result.Command != tt.command || result.FinishedAt.IsZero() {
+				t.Errorf("result = %+v, want the command and finish time recorded", result)
+			}
+		})
+	}
+}
+
+func TestStartCheck(t *testing.T) {
+	instance := &Instance{Title: "test"}
+	if err := instance.StartCheck(); err == nil {
+		t.Error("StartCheck() succeeded for an instance that is not started")
+	}
+
+	instance.MarkAsStartedForTesting()
+	if err := instance.StartCheck(); err != nil {
+		t.Fatalf("StartCheck() error = %v", err)
+	}
+	if !instance.CheckRunning() {
+		t.Error("CheckRunning() = false after StartCheck()")
+	}
+	if err := instance.StartCheck(); err == nil {
+		t.Error("StartCheck() succeeded while a check is running")
+	}
+
+	instance.FinishCheck(&CheckResult{Command: "true", Passed: true})
+	if instance.CheckRunning() || instance.LastCheck == nil || !instance.LastCheck.Passed {
+		t.Errorf("after FinishCheck() running = %v, last check = %+v", instance.CheckRunning(), instance.LastCheck)
+	}
+	if got := instance.ToInstanceData().LastCheck; got != instance.LastCheck {
+		t.Errorf("ToInstanceData().LastCheck = %+v, want the last check", got)
+	}
+}
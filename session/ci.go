@@ -0,0 +1,109 @@
+package session
+
+import (
+	"claude-squad/log"
+	"claude-squad/session/git"
+	"time"
+)
+
+const (
+	// ciCheckInterval is how often the checks of a pushed branch are polled
+	// while they run.
+	ciCheckInterval = time.Minute
+	// ciStartTimeout is how long after a push the checks are waited for to
+	// show up. Branches without checks by then are assumed to have no CI.
+	ciStartTimeout = 10 * time.Minute
+)
+
+// CIStatus returns the state of the checks on the pushed branch, or nil if
+// none ran yet.
+func (i *Instance) CIStatus() *git.CIStatus {
+	return i.ciStatus.Load()
+}
+
+// lastPush returns when the branch was last pushed, or the zero time.
+func (i *Instance) lastPush() time.Time {
+	if len(i.PushEvents) == 0 {
+		return time.Time{}
+	}
+	return i.PushEvents[len(i.PushEvents)-1]
+}
+
+// shouldCheckCI returns true if the checks of the instance's pushed branch are
+// due to be polled. Polling stops once the checks of the last push finished,
+// or when none showed up in time.
+func (i *Instance) shouldCheckCI(now time.Time) bool {
+	pushed := i.lastPush()
+	if pushed.IsZero() || i.gitWorktree == nil || i.Tombstoned() || i.Archived {
+		return false
+	}
+	// A new push is polled right away, as the old status is stale
+	if !i.ciCheckedAt.After(pushed) {
+		return true
+	}
+	status := i.ciStatus.Load()
+	if status != nil && status.State != git.CIPending {
+		return false
+	}
+	if status == nil && i.ciCheckedAt.Sub(pushed) > ciStartTimeout {
+		return false
+	}
+	return now.Sub(i.ciCheckedAt) >= ciCheckInterval
+}
+
+// ciCheck is a poll of the checks of an instance's branch. It holds what the
+// poll needs, so the instance isn't read while it is polled in the background.
+type ciCheck struct {
+	instance *Instance
+	title    string
+	// repoPath is the repository rather than the worktree, which is gone while paused
+	repoPath string
+	branch   string
+}
+
+// run polls the checks of the branch on the forge. A failed poll keeps the
+// previous status.
+func (c ciCheck) run(forgeName string) {
+	forge, err := git.DetectForge(c.repoPath, forgeName)
+	if err != nil {
+		log.WarningLog.Printf("failed to get the CI status of %s: %v", c.title, err)
+		return
+	}
+	checker, ok := forge.(git.CIChecker)
+	if !ok {
+		return
+	}
+	status, err := checker.CIStatus(c.repoPath, c.branch)
+	if err != nil {
+		log.WarningLog.Printf("failed to get the CI status of %s: %v", c.title, err)
+		return
+	}
+	c.instance.ciStatus.Store(status)
+}
+
+// BackgroundCheckCI polls the checks of the pushed branches that are due, one
+// after the other in the background. forgeName is the configured forge, empty
+// to detect it from the remote.
+func BackgroundCheckCI(instances []*Instance, forgeName string, now time.Time) {
+	var due []ciCheck
+	for _, instance := range instances {
+		if instance != nil && instance.shouldCheckCI(now) {
+			// Claimed right away, so the next tick doesn't poll it again
+			instance.ciCheckedAt = now
+			due = append(due, ciCheck{
+				instance: instance,
+				title:    instance.Title,
+				repoPath: instance.gitWorktree.GetRepoPath(),
+				branch:   instance.Branch,
+			})
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+	go func() {
+		for _, check := range due {
+			check.run(forgeName)
+		}
+	}()
+}
@@ -0,0 +1,50 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"testing"
+	"time"
+)
+
+func TestShouldCheckCI(t *testing.T) {
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	worktree := git.NewGitWorktreeFromStorage("/repo", "/worktree", "api", "alice/api", "abc")
+	instance := &Instance{Title: "api", Status: Paused, gitWorktree: worktree}
+
+	if instance.shouldCheckCI(now) {
+		t.Error("a branch that was never pushed should not be polled")
+	}
+	instance.PushEvents = []time.Time{now.Add(-time.Minute)}
+	if !instance.shouldCheckCI(now) {
+		t.Error("a pushed branch should be polled, even while paused")
+	}
+
+	instance.ciCheckedAt = now
+	instance.ciStatus.Store(&git.CIStatus{State: git.CIPending})
+	if instance.shouldCheckCI(now.Add(time.Second)) {
+		t.Error("running checks should not be polled again before the interval")
+	}
+	if !instance.shouldCheckCI(now.Add(ciCheckInterval)) {
+		t.Error("running checks should be polled again after the interval")
+	}
+
+	instance.ciStatus.Store(&git.CIStatus{State: git.CIFailed})
+	if instance.shouldCheckCI(now.Add(ciCheckInterval)) {
+		t.Error("finished checks should not be polled again")
+	}
+	instance.PushEvents = append(instance.PushEvents, now.Add(2*time.Second))
+	if !instance.shouldCheckCI(now.Add(3 * time.Second)) {
+		t.Error("a new push should be polled right away")
+	}
+
+	instance.ciStatus.Store(nil)
+	instance.ciCheckedAt = now.Add(2*time.Second + ciStartTimeout + time.Second)
+	if instance.shouldCheckCI(instance.ciCheckedAt.Add(ciCheckInterval)) {
+		t.Error("a branch without checks long after the push should not be polled")
+	}
+
+	archived := &Instance{Title: "archived", gitWorktree: worktree, Archived: true, PushEvents: []time.Time{now}}
+	if archived.shouldCheckCI(now) {
+		t.Error("an archived instance should not be polled")
+	}
+}
@@ -23,17 +23,11 @@ var ErrNoSessionFiles = errors.New("no session files found")
 // transformed version of the worktree path.
 // Returns ErrClaudeProjectNotFound if the directory doesn't exist yet (expected for new instances).
 func ExtractClaudeSessionID(worktreePath string) (string, error) {
-	homeDir, err := os.UserHomeDir()
+	projectDir, err := claudeProjectDir(worktreePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
+		return "", err
 	}
 
-	// Convert worktree path to Claude project directory format
-	// e.g., /Users/jerred/.claude-squad/worktrees/jerred/colors_18840af3cf6904f0
-	// becomes: -Users-jerred--claude-squad-worktrees-jerred-colors-18840af3cf6904f0
-	projectDirName := pathToClaudeProjectDir(worktreePath)
-	projectDir := filepath.Join(homeDir, ".claude", "projects", projectDirName)
-
 	// Check if the project directory exists
 	if _, err := os.Stat(projectDir); os.IsNotExist(err) {
 		return "", ErrClaudeProjectNotFound
@@ -73,6 +67,20 @@ func ExtractClaudeSessionID(worktreePath string) (string, error) {
 	return extractSessionIDFromJSONL(sessionFilePath)
 }
 
+// claudeProjectDir returns the directory Claude keeps the session files of a worktree in.
+func claudeProjectDir(worktreePath string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	// Convert worktree path to Claude project directory format
+	// e.g., /Users/jerred/.claude-squad/worktrees/jerred/colors_18840af3cf6904f0
+	// becomes: -Users-jerred--claude-squad-worktrees-jerred-colors-18840af3cf6904f0
+	projectDirName := pathToClaudeProjectDir(worktreePath)
+	return filepath.Join(homeDir, ".claude", "projects", projectDirName), nil
+}
+
 // pathToClaudeProjectDir converts a filesystem path to Claude's project directory format.
 // Claude replaces / with - in the path.
 func pathToClaudeProjectDir(path string) string {
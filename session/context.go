@@ -0,0 +1,127 @@
+package session
+
+import (
+	"bytes"
+	"claude-squad/log"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultContextTemplate is the context file written when no template is
+// configured.
+const defaultContextTemplate = `# Session: {{.Title}}
+
+You are working in an isolated git worktree of {{.Repo}} on the branch
+{{.Branch}}{{if .BaseCommit}}, created from commit {{.BaseCommit}}{{end}}. Commit your work to
+this branch; other sessions work on their own branches in parallel.
+{{- if .Owner}}
+
+This session belongs to {{.Owner}}.
+{{- end}}
+{{- if .Tags}}
+
+Tags: {{join .Tags ", "}}
+{{- end}}
+{{- if .Prompt}}
+
+## Task
+
+{{.Prompt}}
+{{- end}}
+`
+
+// ContextData is what a context file template can refer to.
+type ContextData struct {
+	Title      string
+	Branch     string
+	Program    string
+	Owner      string
+	Tags       []string
+	Prompt     string
+	CreatedAt  time.Time
+	Repo       string
+	RepoPath   string
+	BaseCommit string
+}
+
+// RenderContext renders the context file template, or the built-in template if
+// templatePath is empty.
+func RenderContext(templatePath string, data ContextData) (string, error) {
+	text := defaultContextTemplate
+	if templatePath != "" {
+		content, err := os.ReadFile(templatePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read context template: %w", err)
+		}
+		text = string(content)
+	}
+
+	tmpl, err := template.New("context").Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse context template: %w", err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render context template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// writeContextFile writes the context file into the instance's new worktree
+// and excludes it from git. An existing file, e.g. one tracked by the
+// repository, is left alone.
+func (i *Instance) writeContextFile() error {
+	path := filepath.Join(i.gitWorktree.GetWorktreePath(), i.contextFile)
+	if _, err := os.Stat(path); err == nil {
+		log.InfoLog.Printf("not writing context file %s for %s, it already exists", path, i.Title)
+		return nil
+	}
+	if err := i.renderContextFile(path); err != nil {
+		return err
+	}
+	i.contextWritten = true
+	return i.gitWorktree.ExcludeFile(i.contextFile)
+}
+
+// renderContextFile renders the context of the instance into the file at path.
+func (i *Instance) renderContextFile(path string) error {
+	content, err := RenderContext(i.contextTemplate, ContextData{
+		Title:      i.Title,
+		Branch:     i.Branch,
+		Program:    i.Program,
+		Owner:      i.Owner,
+		Tags:       i.Tags,
+		Prompt:     i.contextPrompt,
+		CreatedAt:  i.CreatedAt,
+		Repo:       i.gitWorktree.GetRepoName(),
+		RepoPath:   i.gitWorktree.GetRepoPath(),
+		BaseCommit: i.gitWorktree.GetBaseCommitSHA(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for context file: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write context file: %w", err)
+	}
+	return nil
+}
+
+// recordContextPrompt writes the first prompt sent to the instance into its
+// context file as the task, if the file was written before there was one.
+func (i *Instance) recordContextPrompt(prompt string) {
+	if !i.contextWritten || i.contextPrompt != "" || i.gitWorktree == nil {
+		return
+	}
+	i.contextPrompt = prompt
+	path := filepath.Join(i.gitWorktree.GetWorktreePath(), i.contextFile)
+	if err := i.renderContextFile(path); err != nil {
+		log.WarningLog.Printf("failed to add the task to the context file of %s: %v", i.Title, err)
+	}
+}
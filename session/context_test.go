@@ -0,0 +1,87 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderContext(t *testing.T) {
+	data := ContextData{
+		Title:  "login",
+		Branch: "alice/login",
+		Repo:   "webapp",
+		Tags:   []string{"backend", "auth"},
+		Prompt: "Fix the login redirect",
+	}
+
+	content, err := RenderContext("", data)
+	if err != nil {
+		t.Fatalf("RenderContext() error = %v", err)
+	}
+	for _, want := range []string{"# Session: login", "of webapp on the branch\nalice/login", "Tags: backend, auth", "## Task\n\nFix the login redirect"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("built-in context is missing %q:\n%s", want, content)
+		}
+	}
+
+	// Without a prompt there is no task section
+	data.Prompt = ""
+	content, err = RenderContext("", data)
+	if err != nil {
+		t.Fatalf("RenderContext() error = %v", err)
+	}
+	if strings.Contains(content, "## Task") {
+		t.Errorf("context without a prompt has a task section:\n%s", content)
+	}
+
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "context.tmpl")
+	if err := os.WriteFile(templatePath, []byte("{{.Repo}}: {{.Title}}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if content, err = RenderContext(templatePath, data); err != nil || content != "webapp: login\n" {
+		t.Errorf("RenderContext() = %q, %v; want %q", content, err, "webapp: login\n")
+	}
+
+	if err := os.WriteFile(templatePath, []byte("{{.Missing"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RenderContext(templatePath, data); err == nil {
+		t.Error("RenderContext() of an invalid template succeeded")
+	}
+}
+
+func TestContextFileTaskFromFirstPrompt(t *testing.T) {
+	repo := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %s (%v)", output, err)
+	}
+	instance := &Instance{
+		Title:       "login",
+		Branch:      "alice/login",
+		gitWorktree: git.NewGitWorktreeFromStorage(repo, repo, "login", "alice/login", ""),
+		contextFile: "CONTEXT.md",
+	}
+	if err := instance.writeContextFile(); err != nil {
+		t.Fatalf("writeContextFile() error = %v", err)
+	}
+	path := filepath.Join(repo, "CONTEXT.md")
+	if content, _ := os.ReadFile(path); strings.Contains(string(content), "## Task") {
+		t.Fatalf("context written before the first prompt has a task:\n%s", content)
+	}
+
+	instance.RecordPrompt("Fix the login redirect", time.Now())
+	instance.RecordPrompt("Also add a test", time.Now())
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "## Task\n\nFix the login redirect") || strings.Contains(string(content), "Also add a test") {
+		t.Errorf("the first prompt should be the task of the context file:\n%s", content)
+	}
+}
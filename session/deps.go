@@ -0,0 +1,132 @@
+package session
+
+import (
+	"bytes"
+	"claude-squad/config"
+	"claude-squad/log"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// installTimeout bounds each dependency install command.
+const installTimeout = 10 * time.Minute
+
+// installOutputLines is how many trailing lines of a failed install's output
+// are included in its error.
+const installOutputLines = 10
+
+// dependencyManifest maps a manifest file to the command that installs its
+// dependencies. Lock files pick between package managers of the same ecosystem.
+type dependencyManifest struct {
+	file      string
+	command   string
+	lockFiles []lockFile
+}
+
+// lockFile selects the command of a manifest when it exists next to it.
+type lockFile struct {
+	file    string
+	command string
+}
+
+// dependencyManifests are checked in order; each ecosystem adds one command.
+var dependencyManifests = []dependencyManifest{
+	{file: "package.json", command: "npm install", lockFiles: []lockFile{
+		{"pnpm-lock.yaml", "pnpm install"},
+		{"yarn.lock", "yarn install"},
+		{"bun.lockb", "bun install"},
+		{"bun.lock", "bun install"},
+	}},
+	{file: "go.mod", command: "go mod download"},
+	{file: "pyproject.toml", command: "pip install -e .", lockFiles: []lockFile{
+		{"uv.lock", "uv sync"},
+		{"poetry.lock", "poetry install"},
+	}},
+	{file: "requirements.txt", command: "pip install -r requirements.txt"},
+	{file: "Gemfile", command: "bundle install"},
+	{file: "Cargo.toml", command: "cargo fetch"},
+}
+
+// DetectInstallCommands returns the commands that install the dependencies of
+// the project in dir, based on the manifests at its top level.
+func DetectInstallCommands(dir string) []string {
+	var commands []string
+	python := false
+	for _, manifest := range dependencyManifests {
+		if !fileExists(filepath.Join(dir, manifest.file)) {
+			continue
+		}
+		// requirements.txt is only used when there's no pyproject.toml
+		if manifest.file == "requirements.txt" && python {
+			continue
+		}
+		if manifest.file == "pyproject.toml" {
+			python = true
+		}
+		command := manifest.command
+		for _, lock := range manifest.lockFiles {
+			if fileExists(filepath.Join(dir, lock.file)) {
+				command = lock.command
+				break
+			}
+		}
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// SetInstallCommands sets the commands that install the instance's dependencies
+// in its worktree when it is first started, before the program starts.
+func (i *Instance) SetInstallCommands(commands []string) {
+	i.installCommands = commands
+}
+
+// InstallError returns why installing the dependencies failed when the
+// instance was started, or nil. A failed install doesn't stop the instance.
+func (i *Instance) InstallError() error {
+	return i.installErr
+}
+
+// installDependencies runs the install commands in dir, reporting each one to
+// progressCallback. All commands are run even if one fails.
+func (i *Instance) installDependencies(dir string, progressCallback func(string)) error {
+	var errs []error
+	for _, command := range i.installCommands {
+		if progressCallback != nil {
+			progressCallback(fmt.Sprintf("Installing dependencies (%s)...", command))
+		}
+		if err := runInstallCommand(dir, command); err != nil {
+			log.WarningLog.Printf("failed to install dependencies of %s: %v", i.Title, err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runInstallCommand runs an install command in dir, returning the end of its
+// output if it fails.
+func runInstallCommand(dir, command string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), installTimeout)
+	defer cancel()
+
+	cmd := config.ShellCommand(ctx, command)
+	cmd.Dir = dir
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		tail := lastLines(strings.TrimRight(output.String(), "\n"), installOutputLines)
+		return fmt.Errorf("%s failed: %w\n%s", command, err, tail)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDetectInstallCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{"no manifests", []string{"README.md"}, nil},
+		{"npm", []string{"package.json"}, []string{"npm install"}},
+		{"pnpm lock file", []string{"package.json", "pnpm-lock.yaml"}, []string{"pnpm install"}},
+		{"go and uv", []string{"go.mod", "pyproject.toml", "uv.lock"}, []string{"go mod download", "uv sync"}},
+		{"requirements without pyproject", []string{"requirements.txt"}, []string{"pip install -r requirements.txt"}},
+		{"pyproject takes precedence", []string{"pyproject.toml", "requirements.txt"}, []string{"pip install -e ."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, file := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, file), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := DetectInstallCommands(dir); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("DetectInstallCommands() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package session
+
+import (
+	"bytes"
+	"claude-squad/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// digestSendTimeout bounds each delivery of the digest.
+const digestSendTimeout = time.Minute
+
+// DigestEntry is what an instance did during the period of a digest.
+type DigestEntry struct {
+	Instance string `json:"instance"`
+	Branch   string `json:"branch,omitempty"`
+	Status   string `json:"status"`
+	Summary  string `json:"summary,omitempty"`
+	Added    int    `json:"added"`
+	Removed  int    `json:"removed"`
+	// AutoYes counts the prompts auto-yes accepted during the period.
+	AutoYes int `json:"auto_yes"`
+	// Errors are the problems the instance has, like a failed check or landing.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Digest summarizes the instances for a period, to be read after being away.
+type Digest struct {
+	Since     time.Time     `json:"since"`
+	Until     time.Time     `json:"until"`
+	Instances []DigestEntry `json:"instances"`
+}
+
+// NewDigest summarizes the instances for the period from since to now.
+// Tombstoned and archived instances are left out.
+func NewDigest(instances []*Instance, since, now time.Time) *Digest {
+	digest := &Digest{Since: since, Until: now, Instances: []DigestEntry{}}
+	for _, instance := range instances {
+		if instance.Tombstoned() || instance.Archived {
+			continue
+		}
+		entry := DigestEntry{
+			Instance: instance.Title,
+			Branch:   instance.Branch,
+			Status:   instance.Status.String(),
+			Summary:  instance.Summary,
+			Errors:   instance.digestErrors(),
+		}
+		if diff := instance.GetDiffStats(); diff != nil && diff.Error == nil {
+			entry.Added, entry.Removed = diff.Added, diff.Removed
+		}
+		for _, at := range instance.AutoYesEvents {
+			if !at.Before(since) && !at.After(now) {
+				entry.AutoYes++
+			}
+		}
+		digest.Instances = append(digest.Instances, entry)
+	}
+	return digest
+}
+
+// digestErrors describes the problems the instance has.
+func (i *Instance) digestErrors() []string {
+	var errs []string
+	if i.Quarantined() {
+		errs = append(errs, "quarantined: "+i.QuarantineReason)
+	}
+	if i.LandState == LandFailed {
+		errs = append(errs, "landing failed: "+i.LandError)
+	}
+	if i.LastCheck != nil && !i.LastCheck.Passed {
+		errs = append(errs, fmt.Sprintf("check failed: %s", i.LastCheck.Command))
+	}
+	if i.RateLimited() {
+		errs = append(errs, "rate limited until "+i.RateLimitedUntil.Format("15:04"))
+	}
+	if err := i.InstallError(); err != nil {
+		errs = append(errs, "installing dependencies failed")
+	}
+	if err := i.RestoreError(); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to restore: %v", err))
+	}
+	if diff := i.GetDiffStats(); diff != nil && diff.Error != nil {
+		errs = append(errs, fmt.Sprintf("failed to compute the diff: %v", diff.Error))
+	}
+	return errs
+}
+
+// Subject is a one-line summary of the digest, e.g. for an email.
+func (d *Digest) Subject() string {
+	problems := 0
+	for _, entry := range d.Instances {
+		if len(entry.Errors) > 0 {
+			problems++
+		}
+	}
+	subject := fmt.Sprintf("claude-squad digest: %d instance(s)", len(d.Instances))
+	if problems > 0 {
+		subject += fmt.Sprintf(", %d with errors", problems)
+	}
+	return subject
+}
+
+// String formats the digest as text, one paragraph per instance.
+func (d *Digest) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\nFrom %s to %s\n", d.Subject(), d.Since.Format("2006-01-02 15:04"), d.Until.Format("2006-01-02 15:04"))
+	if len(d.Instances) == 0 {
+		b.WriteString("\nNo instances.\n")
+	}
+	for _, entry := range d.Instances {
+		fmt.Fprintf(&b, "\n%s (%s)", entry.Instance, entry.Status)
+		if entry.Branch != "" {
+			fmt.Fprintf(&b, " on %s", entry.Branch)
+		}
+		fmt.Fprintf(&b, ": +%d -%d, %d prompt(s) auto-accepted\n", entry.Added, entry.Removed, entry.AutoYes)
+		if entry.Summary != "" {
+			fmt.Fprintf(&b, "  %s\n", entry.Summary)
+		}
+		for _, err := range entry.Errors {
+			fmt.Fprintf(&b, "  ! %s\n", err)
+		}
+	}
+	return b.String()
+}
+
+// SendDigest delivers the digest with the configured command and to the
+// configured URL. It returns an error if neither is configured.
+func SendDigest(cfg *config.Config, digest *Digest) error {
+	command, url := strings.TrimSpace(cfg.DigestCommand), strings.TrimSpace(cfg.DigestURL)
+	if command == "" && url == "" {
+		return fmt.Errorf("set digest_command or digest_url in the config to send the digest")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), digestSendTimeout)
+	defer cancel()
+
+	var errs []error
+	if command != "" {
+		if err := sendDigestWithCommand(ctx, command, digest); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if url != "" {
+		if err := postDigest(ctx, url, digest); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendDigestWithCommand runs the command with the digest on stdin and its
+// subject in $CS_DIGEST_SUBJECT.
+func sendDigestWithCommand(ctx context.Context, command string, digest *Digest) error {
+	cmd := config.ShellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), "CS_DIGEST_SUBJECT="+digest.Subject())
+	cmd.Stdin = strings.NewReader(digest.String())
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("digest command failed: %w\n%s", err, lastLines(output.String(), artifactUploadOutputLines))
+	}
+	return nil
+}
+
+// postDigest sends the digest to the URL as a JSON POST request.
+func postDigest(ctx context.Context, url string, digest *Digest) error {
+	body, err := json.Marshal(struct {
+		Subject string `json:"subject"`
+		Text    string `json:"text"`
+		*Digest
+	}{Subject: digest.Subject(), Text: digest.String(), Digest: digest})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the digest: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid digest URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send the digest: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send the digest: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"claude-squad/config"
+	"claude-squad/session/git"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewDigest(t *testing.T) {
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	since := now.Add(-12 * time.Hour)
+	deleted := now.Add(-time.Hour)
+
+	api := &Instance{
+		Title:         "api",
+		Branch:        "alice/api",
+		Status:        Ready,
+		Summary:       "Added pagination to the list endpoint",
+		AutoYesEvents: []time.Time{since.Add(-time.Minute), since.Add(time.Hour), now.Add(-time.Minute)},
+		LastCheck:     &CheckResult{Command: "make test", Passed: false},
+		diffStats:     &git.DiffStats{Added: 120, Removed: 8},
+	}
+	docs := &Instance{Title: "docs", Status: Running, LandState: LandFailed, LandError: "conflict in README.md"}
+	archived := &Instance{Title: "old", Status: Paused, Archived: true}
+	killed := &Instance{Title: "gone", Status: Ready, DeletedAt: &deleted}
+
+	digest := NewDigest([]*Instance{api, docs, archived, killed}, since, now)
+	if len(digest.Instances) != 2 {
+		t.Fatalf("expected the digest of 2 instances, got %+v", digest.Instances)
+	}
+	entry := digest.Instances[0]
+	if entry.Instance != "api" || entry.Added != 120 || entry.Removed != 8 || entry.AutoYes != 2 {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if len(entry.Errors) != 1 || entry.Errors[0] != "check failed: make test" {
+		t.Errorf("expected the failed check as error, got %v", entry.Errors)
+	}
+	if errs := digest.Instances[1].Errors; len(errs) != 1 || errs[0] != "landing failed: conflict in README.md" {
+		t.Errorf("expected the failed landing as error, got %v", errs)
+	}
+
+	if subject := digest.Subject(); subject != "claude-squad digest: 2 instance(s), 2 with errors" {
+		t.Errorf("Subject() = %q", subject)
+	}
+	text := digest.String()
+	for _, want := range []string{
+		"From 2026-10-14 20:00 to 2026-10-15 08:00",
+		"api (ready) on alice/api: +120 -8, 2 prompt(s) auto-accepted",
+		"  Added pagination to the list endpoint",
+		"  ! landing failed: conflict in README.md",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("String() = %q, missing %q", text, want)
+		}
+	}
+}
+
+func TestSendDigest(t *testing.T) {
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	digest := NewDigest([]*Instance{{Title: "api", Status: Ready}}, now.Add(-time.Hour), now)
+
+	var received struct {
+		Subject   string        `json:"subject"`
+		Text      string        `json:"text"`
+		Instances []DigestEntry `json:"instances"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	mail := filepath.Join(t.TempDir(), "mail")
+	t.Setenv("MAIL_FILE", mail)
+	cfg := &config.Config{
+		DigestCommand: `echo "$CS_DIGEST_SUBJECT" > "$MAIL_FILE" && cat >> "$MAIL_FILE"`,
+		DigestURL:     server.URL,
+	}
+	if err := SendDigest(cfg, digest); err != nil {
+		t.Fatalf("SendDigest() error = %v", err)
+	}
+
+	if received.Subject != digest.Subject() || received.Text != digest.String() || len(received.Instances) != 1 {
+		t.Errorf("server received %+v", received)
+	}
+	content, err := os.ReadFile(mail)
+	if err != nil || string(content) != digest.Subject()+"\n"+digest.String() {
+		t.Errorf("command received %q, %v", content, err)
+	}
+
+	if err := SendDigest(&config.Config{}, digest); err == nil {
+		t.Error("expected an error without digest_command or digest_url")
+	}
+}
@@ -46,6 +46,11 @@ type DockerSession struct {
 	repoURL    string
 	branchName string
 
+	// env holds the environment variables the container is started with
+	env map[string]string
+	// mounts are the host directories mounted at the same path
+	mounts []string
+
 	// Host paths
 	hostWorkDir   string
 	hostClaudeDir string
@@ -54,6 +59,10 @@ type DockerSession struct {
 	ptmx    *os.File
 	execCmd *exec.Cmd
 
+	// panes are the commands running next to the program, by name
+	panes   map[string]*pane
+	panesMu sync.Mutex
+
 	// Terminal buffer for capturing output with colors
 	termBuffer      *zellij.TerminalBuffer
 	ptyReaderCtx    context.Context
@@ -76,6 +85,10 @@ type MultiplexerOptions struct {
 	RepoURL    string
 	BranchName string
 	WorkDir    string
+	// Env holds environment variables to start the container with
+	Env map[string]string
+	// Mounts are host directories mounted at the same path in the container
+	Mounts []string
 }
 
 // NewDockerSession creates a new DockerSession with the given parameters.
@@ -95,6 +108,8 @@ func NewDockerSession(name, program, sessionType string, opts MultiplexerOptions
 		sessionType:   sessionType,
 		repoURL:       opts.RepoURL,
 		branchName:    opts.BranchName,
+		env:           opts.Env,
+		mounts:        opts.Mounts,
 		hostWorkDir:   opts.WorkDir,
 		hostClaudeDir: claudeDir,
 		termBuffer:    zellij.NewTerminalBuffer(),
@@ -136,12 +151,28 @@ func (d *DockerSession) Start(workDir string) error {
 		// Bind-mount mode: mount the worktree
 		args = append(args, "-v", fmt.Sprintf("%s:%s", workDir, containerWorkDir))
 		args = append(args, "-w", containerWorkDir)
+		// The worktree links to them by their host path. Docker would
+		// create missing ones owned by root.
+		for _, dir := range d.mounts {
+			if _, err := os.Stat(dir); err == nil {
+				args = append(args, "-v", fmt.Sprintf("%s:%s", dir, dir))
+			}
+		}
+	}
+
+	// The values of the environment variables are left out of the logged
+	// command, since they may hold secrets
+	shown := append([]string(nil), args...)
+	for _, name := range zellij.EnvNames(d.env) {
+		args = append(args, "-e", name+"="+d.env[name])
+		shown = append(shown, "-e", name+"=<redacted>")
 	}
 
 	// Use sleep infinity as entrypoint so container stays running
 	args = append(args, d.baseImage, "sleep", "infinity")
+	shown = append(shown, d.baseImage, "sleep", "infinity")
 
-	dockerCmd := fmt.Sprintf("docker %s", strings.Join(args, " "))
+	dockerCmd := fmt.Sprintf("docker %s", strings.Join(shown, " "))
 	log.InfoLog.Printf("Creating Docker container: %s", dockerCmd)
 
 	cmd := exec.Command("docker", args...)
@@ -208,7 +239,13 @@ func (d *DockerSession) Restore() error {
 
 // isContainerRunning checks if the container is in running state.
 func (d *DockerSession) isContainerRunning() bool {
-	cmd := exec.Command("docker", "inspect", "-f", "{{.State.Running}}", d.containerName)
+	return d.isContainerRunningContext(context.Background())
+}
+
+// isContainerRunningContext is isContainerRunning, stopping docker inspect
+// once ctx is done.
+func (d *DockerSession) isContainerRunningContext(ctx context.Context) bool {
+	cmd := exec.CommandContext(ctx, "docker", "inspect", "-f", "{{.State.Running}}", d.containerName)
 	output, err := cmd.Output()
 	if err != nil {
 		return false
@@ -393,6 +430,8 @@ func (d *DockerSession) DetachSafely() error {
 
 // Close terminates the session and removes the container.
 func (d *DockerSession) Close() error {
+	d.closePanes()
+
 	// First detach if attached
 	if d.ptmx != nil {
 		d.DetachSafely()
@@ -446,6 +485,18 @@ func (d *DockerSession) CapturePaneContentWithOptions(start, end string) (string
 	return d.CapturePaneContent()
 }
 
+// CaptureHistoryTail captures the end of the scroll history. Docker sessions
+// have no scroll history, so this is the current content.
+func (d *DockerSession) CaptureHistoryTail(maxLines, maxBytes int) (string, bool, error) {
+	content, err := d.CapturePaneContent()
+	return content, false, err
+}
+
+// LoadScreen shows the screen in the preview until new output replaces it.
+func (d *DockerSession) LoadScreen(screen string) {
+	d.termBuffer.Load(screen)
+}
+
 // HasUpdated checks if pane content has changed since the last check.
 func (d *DockerSession) HasUpdated() (updated bool, hasPrompt bool) {
 	content := d.termBuffer.Render()
@@ -488,6 +539,7 @@ func (d *DockerSession) DoesSessionExist() bool {
 // SetDetachedSize sets the pane dimensions while detached.
 func (d *DockerSession) SetDetachedSize(width, height int) error {
 	d.termBuffer.Resize(height, width)
+	d.resizePanes(width, height)
 	log.DebugLog.Printf("Docker terminal buffer resized to %dx%d (container: %s)",
 		width, height, d.containerName)
 
@@ -508,17 +560,36 @@ func (d *DockerSession) GetProgram() string {
 
 // IsProgramRunning checks if the configured program is actively running.
 func (d *DockerSession) IsProgramRunning() (bool, error) {
+	return d.IsProgramRunningContext(context.Background())
+}
+
+// IsProgramRunningContext is IsProgramRunning, stopping the docker commands
+// once ctx is done. A check stopped that way returns the error of ctx, so the
+// program isn't taken for stopped.
+func (d *DockerSession) IsProgramRunningContext(ctx context.Context) (bool, error) {
 	// Check if container is running first
-	if !d.isContainerRunning() {
+	running := d.isContainerRunningContext(ctx)
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	if !running {
 		return false, nil
 	}
 
 	// Check if Claude process is running in the container
-	psCmd := exec.Command("docker", "exec", d.containerName, "pgrep", "-f", "claude")
+	psCmd := exec.CommandContext(ctx, "docker", "exec", d.containerName, "pgrep", "-f", "claude")
 	err := psCmd.Run()
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
 	return err == nil, nil
 }
 
+// HasUpdatedContext is HasUpdated, which runs no commands.
+func (d *DockerSession) HasUpdatedContext(ctx context.Context) (updated bool, hasPrompt bool) {
+	return d.HasUpdated()
+}
+
 // RestartProgram restarts the program in the existing session with optional arguments.
 func (d *DockerSession) RestartProgram(args string) error {
 	// Close existing PTY session
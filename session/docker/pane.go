@@ -0,0 +1,151 @@
+package docker
+
+import (
+	"claude-squad/log"
+	"claude-squad/session/zellij"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// pane is a command run with docker exec in the container next to the program.
+type pane struct {
+	cmd        *exec.Cmd
+	ptmx       *os.File
+	exited     chan struct{}
+	termBuffer *zellij.TerminalBuffer
+}
+
+// running returns true until the command of the pane exits.
+func (p *pane) running() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// stop terminates the command of the pane and releases its PTY.
+func (p *pane) stop() {
+	if p.running() && p.cmd.Process != nil {
+		_ = p.cmd.Process.Kill()
+		select {
+		case <-p.exited:
+		case <-time.After(5 * time.Second):
+		}
+	}
+	_ = p.ptmx.Close()
+	p.termBuffer.Stop()
+}
+
+// StartPane runs the command in the work directory of the container with
+// docker exec. The work directory on the host is mounted there, if at all. A
+// running pane is kept and an exited one is started again.
+func (d *DockerSession) StartPane(name, command, workDir string) error {
+	if existing := d.pane(name); existing != nil {
+		if existing.running() {
+			return nil
+		}
+		existing.stop()
+	}
+	if !d.isContainerRunning() {
+		return fmt.Errorf("docker container %s is not running", d.containerName)
+	}
+
+	cmd := exec.Command("docker", "exec", "-it", "-w", containerWorkDir, d.containerName, "sh", "-c", command)
+	height, width := d.termBuffer.GetSize()
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Rows: uint16(height), Cols: uint16(width)})
+	if err != nil {
+		return fmt.Errorf("failed to start %s in container %s: %w", command, d.containerName, err)
+	}
+	p := &pane{cmd: cmd, ptmx: ptmx, exited: make(chan struct{}), termBuffer: zellij.NewTerminalBuffer()}
+	p.termBuffer.Resize(height, width)
+
+	d.panesMu.Lock()
+	if d.panes == nil {
+		d.panes = make(map[string]*pane)
+	}
+	d.panes[name] = p
+	d.panesMu.Unlock()
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := ptmx.Read(buf)
+			if n > 0 {
+				p.termBuffer.Write(buf[:n])
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			log.InfoLog.Printf("pane %s of container %s exited: %v", name, d.containerName, err)
+		}
+		close(p.exited)
+	}()
+	return nil
+}
+
+// pane returns the named pane, or nil if there is none.
+func (d *DockerSession) pane(name string) *pane {
+	d.panesMu.Lock()
+	defer d.panesMu.Unlock()
+	return d.panes[name]
+}
+
+// CapturePane captures the current visible content of the named pane.
+func (d *DockerSession) CapturePane(name string) (string, error) {
+	p := d.pane(name)
+	if p == nil {
+		return "", fmt.Errorf("container %s has no pane %s", d.containerName, name)
+	}
+	return p.termBuffer.Render(), nil
+}
+
+// PaneRunning returns true while the command of the named pane runs.
+func (d *DockerSession) PaneRunning(name string) bool {
+	p := d.pane(name)
+	return p != nil && p.running()
+}
+
+// ClosePane stops the command of the named pane and removes the pane.
+func (d *DockerSession) ClosePane(name string) error {
+	d.panesMu.Lock()
+	p := d.panes[name]
+	delete(d.panes, name)
+	d.panesMu.Unlock()
+	if p != nil {
+		p.stop()
+	}
+	return nil
+}
+
+// closePanes stops the commands of all panes.
+func (d *DockerSession) closePanes() {
+	d.panesMu.Lock()
+	panes := d.panes
+	d.panes = nil
+	d.panesMu.Unlock()
+	for _, p := range panes {
+		p.stop()
+	}
+}
+
+// resizePanes sizes the PTYs of the panes like the program's.
+func (d *DockerSession) resizePanes(width, height int) {
+	d.panesMu.Lock()
+	defer d.panesMu.Unlock()
+	for _, p := range d.panes {
+		p.termBuffer.Resize(height, width)
+		if p.running() {
+			_ = pty.Setsize(p.ptmx, &pty.Winsize{Rows: uint16(height), Cols: uint16(width)})
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// envNameRegex matches the names sh accepts for environment variables.
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateEnv returns an error if a name in env can't be exported to the
+// program.
+func ValidateEnv(env map[string]string) error {
+	for name := range env {
+		if !envNameRegex.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name: %q", name)
+		}
+	}
+	return nil
+}
+
+// MergeEnv returns the variables of base overridden by those of overrides. It
+// returns nil when both are empty.
+func MergeEnv(base, overrides map[string]string) map[string]string {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	for name, value := range base {
+		merged[name] = value
+	}
+	for name, value := range overrides {
+		merged[name] = value
+	}
+	return merged
+}
@@ -0,0 +1,38 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateEnv(t *testing.T) {
+	assert.NoError(t, ValidateEnv(nil))
+	assert.NoError(t, ValidateEnv(map[string]string{"API_URL": "http://localhost", "_flag2": ""}))
+	assert.Error(t, ValidateEnv(map[string]string{"2FAST": "1"}))
+	assert.Error(t, ValidateEnv(map[string]string{"A B": "1"}))
+	assert.Error(t, ValidateEnv(map[string]string{"A=B": "1"}))
+}
+
+func TestMergeEnv(t *testing.T) {
+	assert.Nil(t, MergeEnv(nil, map[string]string{}))
+	assert.Equal(t, map[string]string{"A": "repo", "B": "global", "C": "repo"}, MergeEnv(
+		map[string]string{"A": "global", "B": "global"},
+		map[string]string{"A": "repo", "C": "repo"},
+	))
+}
+
+func TestInstanceEnvIsPersisted(t *testing.T) {
+	instance, err := NewInstance(InstanceOptions{
+		Title:   "env",
+		Path:    t.TempDir(),
+		Program: "claude",
+		Env:     map[string]string{"FEATURE_FLAG": "on"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"FEATURE_FLAG": "on"}, instance.ToInstanceData().Env)
+
+	_, err = NewInstance(InstanceOptions{Title: "bad", Path: t.TempDir(), Env: map[string]string{"BAD-NAME": "1"}})
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package session
 import (
 	"claude-squad/config"
 	"claude-squad/session/docker"
+	"claude-squad/session/native"
 	"claude-squad/session/zellij"
 )
 
@@ -21,6 +22,11 @@ type MultiplexerOptions struct {
 	RepoURL    string
 	BranchName string
 	WorkDir    string
+	// Env holds environment variables to start the program with
+	Env map[string]string
+	// Mounts are host directories that containers mount at the same path,
+	// like the shared caches the worktree links to and the hooks directory
+	Mounts []string
 }
 
 // NewMultiplexer creates a new session based on the session type.
@@ -33,9 +39,17 @@ func NewMultiplexer(sessionType string, name, program string, opts MultiplexerOp
 			RepoURL:    opts.RepoURL,
 			BranchName: opts.BranchName,
 			WorkDir:    opts.WorkDir,
+			Env:        opts.Env,
+			Mounts:     opts.Mounts,
 		})
+	case config.SessionTypeNative:
+		s := native.NewNativeSession(name, program, opts.WorkDir)
+		s.SetEnv(opts.Env)
+		return s
 	default:
-		return zellij.NewZellijSession(name, program)
+		s := zellij.NewZellijSession(name, program)
+		s.SetEnv(opts.Env)
+		return s
 	}
 }
 
@@ -50,16 +64,32 @@ func IsMultiplexerAvailable(sessionType string) bool {
 	switch sessionType {
 	case config.SessionTypeDockerBind, config.SessionTypeDockerClone:
 		return docker.IsDockerAvailable()
+	case config.SessionTypeNative:
+		return native.IsAvailable()
 	default:
 		return zellij.IsAvailable()
 	}
 }
 
+// LocalSessionType returns the session type for sessions that run on this
+// machine: zellij, or native if zellij isn't installed.
+func LocalSessionType() string {
+	if zellij.IsAvailable() {
+		return config.SessionTypeZellij
+	}
+	return config.SessionTypeNative
+}
+
 // IsZellijAvailable checks if Zellij is available on the system.
 func IsZellijAvailable() bool {
 	return zellij.IsAvailable()
 }
 
+// IsNativeAvailable checks if native sessions are supported on the system.
+func IsNativeAvailable() bool {
+	return native.IsAvailable()
+}
+
 // IsDockerAvailable checks if Docker is available on the system.
 func IsDockerAvailable() bool {
 	return docker.IsDockerAvailable()
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrMergeConflict is returned by MergeDefaultBranch when the default branch
+// doesn't merge cleanly into the branch.
+var ErrMergeConflict = errors.New("default branch conflicts with the branch")
+
+// ErrDirtyWorktree is returned by MergeDefaultBranch when the worktree has
+// uncommitted changes, which an aborted merge could lose.
+var ErrDirtyWorktree = errors.New("worktree has uncommitted changes")
+
+// BaseStatus tells how far a branch has fallen behind the default branch.
+type BaseStatus struct {
+	// DefaultBranch is the name of the default branch, e.g. "main".
+	DefaultBranch string
+	// Behind is the number of commits on the tip of the default branch that the
+	// branch lacks.
+	Behind int
+}
+
+// CheckBase counts the commits on the tip of the default branch that the
+// branch lacks. With an origin remote the default branch is fetched first and
+// its remote tip is compared; a failed fetch compares what was fetched before.
+func (g *GitWorktree) CheckBase() (*BaseStatus, error) {
+	target, tip, err := g.defaultBranchTip()
+	if err != nil {
+		return nil, err
+	}
+	output, err := g.runGitCommand(g.worktreePath, "rev-list", "--count", "HEAD.."+tip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare with %s: %w", tip, err)
+	}
+	behind, err := strconv.Atoi(strings.TrimSpace(output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare with %s: %w", tip, err)
+	}
+	return &BaseStatus{DefaultBranch: target, Behind: behind}, nil
+}
+
+// MergeDefaultBranch merges the tip of the default branch into the branch, so
+// that the agent works against current code. Unlike a rebase it leaves the
+// commits of the branch, which may have been pushed, as they are. A merge that
+// stops is aborted, leaving the branch as it was. A worktree with uncommitted
+// changes isn't merged into.
+func (g *GitWorktree) MergeDefaultBranch() error {
+	target, tip, err := g.defaultBranchTip()
+	if err != nil {
+		return err
+	}
+	if target == g.branchName {
+		return fmt.Errorf("branch %s is the default branch", g.branchName)
+	}
+	// Aborting the merge would reset the uncommitted changes with it
+	if dirty, err := g.IsDirty(); err != nil {
+		return err
+	} else if dirty {
+		return ErrDirtyWorktree
+	}
+
+	if _, err := g.runGitCommand(g.worktreePath, "merge", "-q", "--no-edit", tip); err != nil {
+		_, _ = g.runGitCommand(g.worktreePath, "merge", "--abort")
+		return fmt.Errorf("%w: %v", ErrMergeConflict, err)
+	}
+	g.InvalidateDiffCache()
+	return nil
+}
+
+// defaultBranchTip returns the name of the default branch and the ref of its
+// tip: the remote branch if there is an origin remote, fetched first, or the
+// local branch otherwise.
+func (g *GitWorktree) defaultBranchTip() (string, string, error) {
+	target, err := g.findDefaultBranch()
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := g.runGitCommand(g.repoPath, "remote", "get-url", "origin"); err != nil {
+		return target, target, nil
+	}
+	// Offline, the last fetched tip is still better than nothing
+	_, _ = g.runGitCommand(g.worktreePath, "fetch", "-q", "origin", target)
+	remote := "origin/" + target
+	if _, err := g.runGitCommand(g.worktreePath, "rev-parse", "--verify", "--quiet", remote); err != nil {
+		return target, target, nil
+	}
+	return target, remote, nil
+}
@@ -0,0 +1,83 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// commitFile commits a file with the content in the directory.
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", name}, {"commit", "-q", "-m", "change " + name}} {
+		if _, err := runGit(dir, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+}
+
+func TestCheckBaseAndMerge(t *testing.T) {
+	g := setupTestWorktree(t)
+	commitFile(t, g.worktreePath, "feature.txt", "feature\n")
+	commitFile(t, g.repoPath, "one.txt", "one\n")
+	commitFile(t, g.repoPath, "two.txt", "two\n")
+
+	status, err := g.CheckBase()
+	if err != nil {
+		t.Fatalf("CheckBase() error = %v", err)
+	}
+	if status.Behind != 2 || (status.DefaultBranch != "main" && status.DefaultBranch != "master") {
+		t.Errorf("CheckBase() = %+v, want 2 commits behind the default branch", status)
+	}
+
+	if err := g.MergeDefaultBranch(); err != nil {
+		t.Fatalf("MergeDefaultBranch() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(g.worktreePath, "two.txt")); err != nil {
+		t.Errorf("two.txt missing from the branch after the merge: %v", err)
+	}
+	if status, err := g.CheckBase(); err != nil || status.Behind != 0 {
+		t.Errorf("CheckBase() after the merge = %+v, %v, want 0 behind", status, err)
+	}
+}
+
+func TestMergeDefaultBranchConflict(t *testing.T) {
+	g := setupTestWorktree(t)
+	commitFile(t, g.worktreePath, "file.txt", "branch\n")
+	commitFile(t, g.repoPath, "file.txt", "upstream\n")
+
+	err := g.MergeDefaultBranch()
+	if !errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("MergeDefaultBranch() error = %v, want ErrMergeConflict", err)
+	}
+	// The merge was aborted
+	if status, _ := runGit(g.worktreePath, "status"); strings.Contains(status, "merging") || strings.Contains(status, "Unmerged") {
+		t.Errorf("worktree is still merging:\n%s", status)
+	}
+}
+
+func TestMergeDefaultBranchDirty(t *testing.T) {
+	g := setupTestWorktree(t)
+	commitFile(t, g.worktreePath, "file.txt", "branch\n")
+	commitFile(t, g.repoPath, "other.txt", "upstream\n")
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "file.txt"), []byte("uncommitted\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := g.MergeDefaultBranch()
+	if !errors.Is(err, ErrDirtyWorktree) || errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("MergeDefaultBranch() error = %v, want ErrDirtyWorktree", err)
+	}
+	// The changes and the branch are left alone
+	if data, _ := os.ReadFile(filepath.Join(g.worktreePath, "file.txt")); string(data) != "uncommitted\n" {
+		t.Errorf("file.txt = %q, want the uncommitted change", data)
+	}
+	if _, err := os.Stat(filepath.Join(g.worktreePath, "other.txt")); !os.IsNotExist(err) {
+		t.Errorf("other.txt was merged into the dirty worktree")
+	}
+}
@@ -0,0 +1,140 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// CIState is the combined state of the checks that ran on a commit.
+type CIState int
+
+const (
+	// CIPending means some checks are queued or still running, and none failed.
+	CIPending CIState = iota
+	// CIPassed means all checks completed without failing.
+	CIPassed
+	// CIFailed means at least one check failed.
+	CIFailed
+)
+
+func (s CIState) String() string {
+	switch s {
+	case CIPending:
+		return "pending"
+	case CIPassed:
+		return "passed"
+	case CIFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// CIStatus is the state of the checks on the tip of a pushed branch.
+type CIStatus struct {
+	State CIState
+	// FailedURL is the page of the first failed check, if any.
+	FailedURL string
+}
+
+// CIChecker is implemented by forges that can report the checks of a branch.
+type CIChecker interface {
+	// CIStatus returns the state of the checks on the tip of the branch on the
+	// remote of the repository in dir, or nil if no checks ran on it.
+	CIStatus(dir, branch string) (*CIStatus, error)
+}
+
+// CIStatus asks the GitHub API for the check runs of the branch.
+func (githubForge) CIStatus(dir, branch string) (*CIStatus, error) {
+	// gh fills in {owner}/{repo} from the remote of the repository in dir
+	endpoint := fmt.Sprintf("repos/{owner}/{repo}/commits/%s/check-runs?per_page=100", url.PathEscape(branch))
+	cmd := exec.Command("gh", "api", endpoint)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to get the checks of %s: %s (%w)", branch, strings.TrimSpace(string(exitErr.Stderr)), err)
+		}
+		return nil, fmt.Errorf("failed to get the checks of %s: %w", branch, err)
+	}
+	return parseCheckRuns(output)
+}
+
+// parseCheckRuns combines the check runs of a commit, as returned by the GitHub
+// API, into one status.
+func parseCheckRuns(data []byte) (*CIStatus, error) {
+	var response struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+			HTMLURL    string `json:"html_url"`
+		} `json:"check_runs"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse the check runs: %w", err)
+	}
+	if len(response.CheckRuns) == 0 {
+		return nil, nil
+	}
+
+	status := &CIStatus{State: CIPassed}
+	for _, run := range response.CheckRuns {
+		switch {
+		case run.Status != "completed":
+			if status.State == CIPassed {
+				status.State = CIPending
+			}
+		case run.Conclusion == "failure", run.Conclusion == "timed_out",
+			run.Conclusion == "action_required", run.Conclusion == "startup_failure":
+			if status.State != CIFailed {
+				status.State = CIFailed
+				status.FailedURL = run.HTMLURL
+			}
+		}
+	}
+	return status, nil
+}
+
+// CIStatus asks the GitLab API for the latest pipeline of the branch.
+func (gitlabForge) CIStatus(dir, branch string) (*CIStatus, error) {
+	// glab fills in :id from the remote of the repository in dir
+	endpoint := fmt.Sprintf("projects/:id/pipelines?ref=%s&per_page=1", url.QueryEscape(branch))
+	cmd := exec.Command("glab", "api", endpoint)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to get the pipelines of %s: %s (%w)", branch, strings.TrimSpace(string(exitErr.Stderr)), err)
+		}
+		return nil, fmt.Errorf("failed to get the pipelines of %s: %w", branch, err)
+	}
+	return parsePipelines(output)
+}
+
+// parsePipelines returns the status of the latest pipeline, as returned by the
+// GitLab API, newest first.
+func parsePipelines(data []byte) (*CIStatus, error) {
+	var pipelines []struct {
+		Status string `json:"status"`
+		WebURL string `json:"web_url"`
+	}
+	if err := json.Unmarshal(data, &pipelines); err != nil {
+		return nil, fmt.Errorf("failed to parse the pipelines: %w", err)
+	}
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+
+	switch latest := pipelines[0]; latest.Status {
+	case "failed":
+		return &CIStatus{State: CIFailed, FailedURL: latest.WebURL}, nil
+	case "success", "skipped", "canceled", "manual":
+		return &CIStatus{State: CIPassed}, nil
+	default:
+		// created, waiting_for_resource, preparing, pending, running, scheduled
+		return &CIStatus{State: CIPending}, nil
+	}
+}
@@ -0,0 +1,78 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestParseCheckRuns(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     CIState
+		wantURL  string
+		wantNone bool
+	}{
+		{"no checks", `{"total_count": 0, "check_runs": []}`, 0, "", true},
+		{"passed", `{"check_runs": [
+			{"status": "completed", "conclusion": "success", "html_url": "https://github.com/o/r/runs/1"},
+			{"status": "completed", "conclusion": "skipped", "html_url": "https://github.com/o/r/runs/2"}]}`, CIPassed, "", false},
+		{"pending", `{"check_runs": [
+			{"status": "completed", "conclusion": "success", "html_url": "https://github.com/o/r/runs/1"},
+			{"status": "in_progress", "conclusion": null, "html_url": "https://github.com/o/r/runs/2"}]}`, CIPending, "", false},
+		{"failed while others run", `{"check_runs": [
+			{"status": "queued", "conclusion": null, "html_url": "https://github.com/o/r/runs/1"},
+			{"status": "completed", "conclusion": "failure", "html_url": "https://github.com/o/r/runs/2"},
+			{"status": "completed", "conclusion": "timed_out", "html_url": "https://github.com/o/r/runs/3"}]}`, CIFailed, "https://github.com/o/r/runs/2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := parseCheckRuns([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("parseCheckRuns() error = %v", err)
+			}
+			if tt.wantNone {
+				if status != nil {
+					t.Errorf("parseCheckRuns() = %+v, want nil", status)
+				}
+				return
+			}
+			if status == nil || status.State != tt.want || status.FailedURL != tt.wantURL {
+				t.Errorf("parseCheckRuns() = %+v, want %s with URL %q", status, tt.want, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestParsePipelines(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		want     CIState
+		wantURL  string
+		wantNone bool
+	}{
+		{"no pipelines", `[]`, 0, "", true},
+		{"passed", `[{"status": "success", "web_url": "https://gitlab.com/g/r/-/pipelines/2"}]`, CIPassed, "", false},
+		{"running", `[{"status": "running", "web_url": "https://gitlab.com/g/r/-/pipelines/2"}]`, CIPending, "", false},
+		{"latest failed", `[
+			{"status": "failed", "web_url": "https://gitlab.com/g/r/-/pipelines/2"},
+			{"status": "success", "web_url": "https://gitlab.com/g/r/-/pipelines/1"}]`, CIFailed, "https://gitlab.com/g/r/-/pipelines/2", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := parsePipelines([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("parsePipelines() error = %v", err)
+			}
+			if tt.wantNone {
+				if status != nil {
+					t.Errorf("parsePipelines() = %+v, want nil", status)
+				}
+				return
+			}
+			if status == nil || status.State != tt.want || status.FailedURL != tt.wantURL {
+				t.Errorf("parsePipelines() = %+v, want %s with URL %q", status, tt.want, tt.wantURL)
+			}
+		})
+	}
+}
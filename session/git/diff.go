@@ -1,6 +1,11 @@
 package git
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -8,6 +13,11 @@ import (
 // Default cache duration for diff stats
 const defaultDiffCacheDuration = 5 * time.Second
 
+// maxFileDiffSize is the size above which the diff of a file is left out of the
+// diff content, e.g. for large generated files or untracked binaries, to keep
+// the diff pane fast. Its lines are still counted.
+const maxFileDiffSize = 1 << 20
+
 // DiffStats holds statistics about the changes in a diff
 type DiffStats struct {
 	// Content is the full diff content
@@ -16,6 +26,15 @@ type DiffStats struct {
 	Added int
 	// Removed is the number of removed lines
 	Removed int
+	// Untracked are the paths of the untracked files included in Content
+	Untracked []string
+	// Unpushed is the number of commits on the branch since its base commit
+	// that are on no remote
+	Unpushed int
+	// Pushed is true if some of those commits are on a remote
+	Pushed bool
+	// Behind is the number of commits on the upstream that the branch lacks
+	Behind int
 	// Error holds any error that occurred during diff computation
 	// This allows propagating setup errors (like missing base commit) without breaking the flow
 	Error error
@@ -69,20 +88,45 @@ func (g *GitWorktree) Diff() *DiffStats {
 
 // diffUncached performs the actual git diff operation without caching
 func (g *GitWorktree) diffUncached() *DiffStats {
+	return g.diffAgainstCommit(g.GetBaseCommitSHA())
+}
+
+// DiffAgainst returns the git diff between the worktree and a ref, e.g. a
+// branch, tag or commit, along with statistics. Unlike Diff, the result isn't
+// cached.
+func (g *GitWorktree) DiffAgainst(ref string) *DiffStats {
+	commit, err := g.runGitCommand(g.worktreePath, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return &DiffStats{Error: fmt.Errorf("unknown ref %s", ref)}
+	}
+	return g.diffAgainstCommit(strings.TrimSpace(commit))
+}
+
+// DefaultBranch returns the name of the default branch of the repository.
+func (g *GitWorktree) DefaultBranch() (string, error) {
+	return g.findDefaultBranch()
+}
+
+// diffAgainstCommit returns the git diff between the worktree and a commit
+func (g *GitWorktree) diffAgainstCommit(commit string) *DiffStats {
 	stats := &DiffStats{}
 
-	// -N stages untracked files (intent to add), including them in the diff
-	_, err := g.runGitCommand(g.worktreePath, "add", "-N", ".")
+	// Changes in submodules are shown as the diff of their files rather than
+	// of the commit they point to
+	content, err := g.runGitCommand(g.worktreePath, "--no-pager", "diff", "--submodule=diff", commit)
 	if err != nil {
 		stats.Error = err
 		return stats
 	}
-
-	content, err := g.runGitCommand(g.worktreePath, "--no-pager", "diff", g.GetBaseCommitSHA())
+	// New files the agent hasn't staged are work too
+	untracked, paths, err := g.untrackedDiff()
 	if err != nil {
 		stats.Error = err
 		return stats
 	}
+	content += untracked
+	stats.Untracked = paths
+
 	lines := strings.Split(content, "\n")
 	for _, line := range lines {
 		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
@@ -91,11 +135,163 @@ func (g *GitWorktree) diffUncached() *DiffStats {
 			stats.Removed++
 		}
 	}
-	stats.Content = content
+	stats.Content = omitLargeFileDiffs(content)
+	g.countCommits(stats)
 
 	return stats
 }
 
+// countCommits fills in which commits of the branch since its base commit are
+// on a remote, and what its upstream has that it lacks. The counts are left at zero if git can't
+// tell, e.g. before the base commit is known.
+func (g *GitWorktree) countCommits(stats *DiffStats) {
+	count := func(args ...string) int {
+		output, err := g.runGitCommand(g.worktreePath, append([]string{"rev-list", "--count"}, args...)...)
+		if err != nil {
+			return 0
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(output))
+		return n
+	}
+
+	if base := g.GetBaseCommitSHA(); base != "" {
+		stats.Unpushed = count(base+"..HEAD", "--not", "--remotes")
+		stats.Pushed = count(base+"..HEAD") > stats.Unpushed
+	}
+	if _, err := g.runGitCommand(g.worktreePath, "rev-parse", "--verify", "--quiet", "@{upstream}"); err == nil {
+		stats.Behind = count("HEAD..@{upstream}")
+	}
+}
+
+// untrackedDiff renders the untracked files of the worktree, except those
+// ignored by .gitignore, as new-file diffs. Unlike staging them with
+// intent-to-add, this leaves the index of the worktree alone. It also returns
+// the paths of the files.
+func (g *GitWorktree) untrackedDiff() (string, []string, error) {
+	output, err := g.runGitCommand(g.worktreePath, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	var b strings.Builder
+	var paths []string
+	for _, path := range strings.Split(output, "\x00") {
+		if path == "" {
+			continue
+		}
+		fullPath := filepath.Join(g.worktreePath, filepath.FromSlash(path))
+		info, err := os.Lstat(fullPath)
+		if err != nil || !info.Mode().IsRegular() {
+			// Removed since it was listed, or a symlink
+			continue
+		}
+		if info.Size() > maxFileDiffSize {
+			// Not worth reading, it would be left out of the diff anyway
+			fmt.Fprintf(&b, "diff --git a/%s b/%s\nnew file mode %s\n%s", path, path, gitFileMode(info.Mode()), omittedFileDiff(path, info.Size()))
+			paths = append(paths, path)
+			continue
+		}
+		content, err := os.ReadFile(fullPath)
+		if err != nil {
+			continue
+		}
+		b.WriteString(newFileDiff(path, info.Mode(), content))
+		paths = append(paths, path)
+	}
+	return b.String(), paths, nil
+}
+
+// newFileDiff renders a file as a git diff that adds it.
+func newFileDiff(path string, mode os.FileMode, content []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\nnew file mode %s\n", path, path, gitFileMode(mode))
+	if len(content) == 0 {
+		return b.String()
+	}
+	// Git treats files with a NUL byte in their first 8000 bytes as binary
+	if bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0 {
+		fmt.Fprintf(&b, "Binary files /dev/null and b/%s differ\n", path)
+		return b.String()
+	}
+
+	text := string(content)
+	noNewline := !strings.HasSuffix(text, "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	fmt.Fprintf(&b, "--- /dev/null\n+++ b/%s\n", path)
+	if len(lines) == 1 {
+		b.WriteString("@@ -0,0 +1 @@\n")
+	} else {
+		fmt.Fprintf(&b, "@@ -0,0 +1,%d @@\n", len(lines))
+	}
+	for _, line := range lines {
+		b.WriteString("+" + line + "\n")
+	}
+	if noNewline {
+		b.WriteString("\\ No newline at end of file\n")
+	}
+	return b.String()
+}
+
+// gitFileMode returns the mode git records for a regular file.
+func gitFileMode(mode os.FileMode) string {
+	if mode&0111 != 0 {
+		return "100755"
+	}
+	return "100644"
+}
+
+// omittedFileDiff is the line that stands in for the diff of a file that is
+// too large to show.
+func omittedFileDiff(path string, size int64) string {
+	return fmt.Sprintf("Diff of %s not shown, it is %s\n", path, FormatSize(size))
+}
+
+// omitLargeFileDiffs replaces the diffs of files that are larger than
+// maxFileDiffSize with a line saying so, keeping their headers so that the
+// files are still listed as changed.
+func omitLargeFileDiffs(content string) string {
+	if len(content) <= maxFileDiffSize {
+		return content
+	}
+	var b strings.Builder
+	for _, section := range splitFileDiffs(content) {
+		if len(section) <= maxFileDiffSize {
+			b.WriteString(section)
+			continue
+		}
+		// The extended header ends where the changes start
+		header := section
+		if i := strings.Index(section, "\n--- "); i >= 0 {
+			header = section[:i+1]
+		} else if i := strings.Index(section, "\n@@ "); i >= 0 {
+			header = section[:i+1]
+		}
+		path := ""
+		if first, _, _ := strings.Cut(header, "\n"); strings.Contains(first, " b/") {
+			path = first[strings.LastIndex(first, " b/")+len(" b/"):]
+		}
+		b.WriteString(header + omittedFileDiff(path, int64(len(section))))
+	}
+	return b.String()
+}
+
+// splitFileDiffs splits diff content into the diffs of single files, each
+// starting with its "diff --git" line. Anything before the first one is kept
+// as a section of its own.
+func splitFileDiffs(content string) []string {
+	var sections []string
+	start := 0
+	for i := 0; i < len(content); {
+		next := strings.Index(content[i:], "\ndiff --git ")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+		sections = append(sections, content[start:i])
+		start = i
+	}
+	return append(sections, content[start:])
+}
+
 // InvalidateDiffCache clears the cached diff stats, forcing the next Diff() call
 // to perform a fresh git diff operation. Call this when you know the worktree
 // has changed (e.g., after Resume).
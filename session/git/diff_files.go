@@ -0,0 +1,84 @@
+package git
+
+import (
+	"strings"
+)
+
+// ChangedFile is a file changed in a diff
+type ChangedFile struct {
+	// Path is relative to the root of the worktree, with forward slashes
+	Path string
+	// Status is M, A, D or R like in git status, or ?? for untracked files
+	Status string
+	// Diff is the part of the diff that changes the file
+	Diff string
+}
+
+// ChangedFiles splits the diff content into the files it changes, in the order
+// of the diff.
+func (d *DiffStats) ChangedFiles() []ChangedFile {
+	untracked := make(map[string]bool, len(d.Untracked))
+	for _, path := range d.Untracked {
+		untracked[path] = true
+	}
+
+	var files []ChangedFile
+	var current []string
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		file := parseFileDiff(current)
+		if untracked[file.Path] {
+			file.Status = "??"
+		}
+		files = append(files, file)
+		current = nil
+	}
+	for _, line := range strings.SplitAfter(d.Content, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+		}
+		// "Submodule lib contains modified content" and the like head the
+		// diffs of a submodule's files rather than belonging to a file
+		if strings.HasPrefix(line, "Submodule ") {
+			flush()
+			continue
+		}
+		if line != "" && (len(current) > 0 || strings.HasPrefix(line, "diff --git ")) {
+			current = append(current, line)
+		}
+	}
+	flush()
+	return files
+}
+
+// parseFileDiff returns the file changed by the diff of a single file, from its
+// extended header lines.
+func parseFileDiff(lines []string) ChangedFile {
+	file := ChangedFile{Status: "M", Diff: strings.Join(lines, "")}
+	// diff --git a/<path> b/<path>, used when no other line names the file
+	header := strings.TrimSuffix(lines[0], "\n")
+	if i := strings.LastIndex(header, " b/"); i >= 0 {
+		file.Path = header[i+len(" b/"):]
+	}
+	for _, line := range lines[1:] {
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case strings.HasPrefix(line, "@@"), strings.HasPrefix(line, "Binary files"):
+			return file
+		case strings.HasPrefix(line, "new file mode"):
+			file.Status = "A"
+		case strings.HasPrefix(line, "deleted file mode"):
+			file.Status = "D"
+		case strings.HasPrefix(line, "rename to "):
+			file.Status = "R"
+			file.Path = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "+++ b/"):
+			file.Path = strings.TrimPrefix(line, "+++ b/")
+		case strings.HasPrefix(line, "--- a/") && file.Status == "D":
+			file.Path = strings.TrimPrefix(line, "--- a/")
+		}
+	}
+	return file
+}
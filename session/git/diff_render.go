@@ -0,0 +1,63 @@
+package git
+
+import (
+	"bytes"
+	"claude-squad/config"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// diffRenderTimeout bounds how long an external diff renderer may take.
+const diffRenderTimeout = 5 * time.Second
+
+// RenderDiff renders the diff with an external renderer. against is the ref the
+// diff was computed against, the base commit if empty.
+//
+// difftastic ("difft" or "difftastic") cannot read a unified diff, so it is run as
+// git's external diff tool against the same ref. delta is given the options it needs for non-interactive
+// output. Any other command is run with the shell, reads the unified diff on stdin
+// and writes the rendered diff to stdout; COLUMNS is set to the available width.
+func (g *GitWorktree) RenderDiff(renderer, against, diff string, width int) (string, error) {
+	fields := strings.Fields(renderer)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("no diff renderer configured")
+	}
+	if _, err := exec.LookPath(fields[0]); err != nil {
+		return "", fmt.Errorf("diff renderer %s not found: %w", fields[0], err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), diffRenderTimeout)
+	defer cancel()
+
+	if against == "" {
+		against = g.GetBaseCommitSHA()
+	}
+
+	var cmd *exec.Cmd
+	switch filepath.Base(fields[0]) {
+	case "difft", "difftastic":
+		cmd = exec.CommandContext(ctx, "git", "-C", g.worktreePath,
+			"-c", "diff.external="+renderer, "--no-pager", "diff", "--ext-diff", against)
+		cmd.Env = append(os.Environ(), "DFT_COLOR=always", fmt.Sprintf("DFT_WIDTH=%d", width))
+	case "delta":
+		cmd = config.ShellCommand(ctx, fmt.Sprintf("%s --paging=never --width=%d", renderer, width))
+		cmd.Stdin = strings.NewReader(diff)
+	default:
+		cmd = config.ShellCommand(ctx, renderer)
+		cmd.Stdin = strings.NewReader(diff)
+		cmd.Env = append(os.Environ(), fmt.Sprintf("COLUMNS=%d", width))
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("diff renderer %s failed: %s (%w)", fields[0], strings.TrimSpace(stderr.String()), err)
+	}
+	return string(output), nil
+}
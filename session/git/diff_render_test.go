@@ -0,0 +1,31 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderDiff(t *testing.T) {
+	g := setupTestWorktree(t)
+	diff := "+added line\n-removed line\n"
+
+	output, err := g.RenderDiff("tr a-z A-Z", "", diff, 80)
+	if err != nil {
+		t.Fatalf("RenderDiff() error = %v", err)
+	}
+	if output != strings.ToUpper(diff) {
+		t.Errorf("RenderDiff() = %q, want the diff piped through the command", output)
+	}
+
+	output, err = g.RenderDiff(`sh -c 'echo $COLUMNS'`, "", diff, 42)
+	if err != nil || strings.TrimSpace(output) != "42" {
+		t.Errorf("RenderDiff() COLUMNS = %q, %v; want 42", output, err)
+	}
+
+	if _, err := g.RenderDiff("claude-squad-no-such-renderer", "", diff, 80); err == nil {
+		t.Error("RenderDiff() with a missing renderer should fail")
+	}
+	if _, err := g.RenderDiff("false", "", diff, 80); err == nil {
+		t.Error("RenderDiff() with a failing renderer should fail")
+	}
+}
@@ -0,0 +1,271 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffAgainst(t *testing.T) {
+	g := setupTestWorktree(t)
+	base, err := runGit(g.worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.baseCommitSHA = strings.TrimSpace(base)
+
+	// One committed and one uncommitted change
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "committed.txt"), []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"add", "."},
+		{"commit", "-q", "-m", "commit"},
+	} {
+		if _, err := runGit(g.worktreePath, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "uncommitted.txt"), []byte("two\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := g.DiffAgainst("HEAD")
+	if stats.Error != nil {
+		t.Fatalf("DiffAgainst(HEAD) error = %v", stats.Error)
+	}
+	if stats.Added != 1 || strings.Contains(stats.Content, "committed.txt b/committed.txt") {
+		t.Errorf("DiffAgainst(HEAD) = +%d with\n%s\nwant only the uncommitted file", stats.Added, stats.Content)
+	}
+
+	stats = g.DiffAgainst(g.baseCommitSHA)
+	if stats.Error != nil || stats.Added != 2 {
+		t.Errorf("DiffAgainst(base) = +%d (%v), want both files", stats.Added, stats.Error)
+	}
+	if diff := g.Diff(); diff.Added != stats.Added || diff.Content != stats.Content {
+		t.Errorf("Diff() = +%d, want it to match the diff against the base commit", diff.Added)
+	}
+
+	if stats := g.DiffAgainst("no-such-ref"); stats.Error == nil {
+		t.Error("DiffAgainst() of an unknown ref should fail")
+	}
+}
+
+func TestDiffIncludesUntrackedFiles(t *testing.T) {
+	g := setupTestWorktree(t)
+	base, err := runGit(g.worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.baseCommitSHA = strings.TrimSpace(base)
+	if err := os.WriteFile(filepath.Join(g.worktreePath, ".gitignore"), []byte("build/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(g.worktreePath, "build"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "build", "out"), []byte("ignored\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "new.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := g.Diff()
+	if stats.Error != nil {
+		t.Fatalf("Diff() error = %v", stats.Error)
+	}
+	// .gitignore and new.go, but not the ignored build output
+	if stats.Added != 4 || !strings.Contains(stats.Content, "+++ b/new.go") || strings.Contains(stats.Content, "build/out") {
+		t.Errorf("Diff() = +%d with\n%s\nwant the untracked files that aren't ignored", stats.Added, stats.Content)
+	}
+
+	// The files stay untracked rather than being staged
+	status, err := runGit(g.worktreePath, "status", "--porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(status, "?? new.go") {
+		t.Errorf("status = %q, want new.go still untracked", status)
+	}
+}
+
+func TestNewFileDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    os.FileMode
+		content string
+		want    string
+	}{
+		{
+			name:    "lines",
+			mode:    0644,
+			content: "a\nb\n",
+			want:    "diff --git a/f b/f\nnew file mode 100644\n--- /dev/null\n+++ b/f\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "no newline at end",
+			mode:    0755,
+			content: "a",
+			want:    "diff --git a/f b/f\nnew file mode 100755\n--- /dev/null\n+++ b/f\n@@ -0,0 +1 @@\n+a\n\\ No newline at end of file\n",
+		},
+		{
+			name:    "empty",
+			mode:    0644,
+			content: "",
+			want:    "diff --git a/f b/f\nnew file mode 100644\n",
+		},
+		{
+			name:    "binary",
+			mode:    0644,
+			content: "a\x00b",
+			want:    "diff --git a/f b/f\nnew file mode 100644\nBinary files /dev/null and b/f differ\n",
+		},
+	}
+	for _, tt := range tests {
+		if got := newFileDiff("f", tt.mode, []byte(tt.content)); got != tt.want {
+			t.Errorf("%s: newFileDiff() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	content := "diff --git a/main.go b/main.go\nindex 1111111..2222222 100644\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"diff --git a/old.go b/old.go\ndeleted file mode 100644\nindex 1111111..0000000\n--- a/old.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-a\n" +
+		"diff --git a/a.go b/dir/b.go\nsimilarity index 100%\nrename from a.go\nrename to dir/b.go\n" +
+		"diff --git a/dir/new.go b/dir/new.go\nnew file mode 100644\n--- /dev/null\n+++ b/dir/new.go\n@@ -0,0 +1 @@\n+a\n" +
+		"diff --git a/notes.txt b/notes.txt\nnew file mode 100644\n--- /dev/null\n+++ b/notes.txt\n@@ -0,0 +1 @@\n+a\n"
+	stats := &DiffStats{Content: content, Untracked: []string{"notes.txt"}}
+
+	want := []struct{ path, status string }{
+		{"main.go", "M"},
+		{"old.go", "D"},
+		{"dir/b.go", "R"},
+		{"dir/new.go", "A"},
+		{"notes.txt", "??"},
+	}
+	files := stats.ChangedFiles()
+	if len(files) != len(want) {
+		t.Fatalf("ChangedFiles() returned %d files, want %d", len(files), len(want))
+	}
+	for i, w := range want {
+		if files[i].Path != w.path || files[i].Status != w.status {
+			t.Errorf("file %d = %s %s, want %s %s", i, files[i].Status, files[i].Path, w.status, w.path)
+		}
+		if !strings.HasPrefix(files[i].Diff, "diff --git ") {
+			t.Errorf("file %d diff = %q, want it to start with the diff header", i, files[i].Diff)
+		}
+	}
+	if !strings.HasSuffix(files[0].Diff, "+b\n") || strings.Contains(files[0].Diff, "old.go") {
+		t.Errorf("diff of main.go = %q, want only its own hunks", files[0].Diff)
+	}
+}
+
+func TestDiffCountsCommits(t *testing.T) {
+	g := setupTestWorktree(t)
+	base, err := runGit(g.worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.baseCommitSHA = strings.TrimSpace(base)
+	commit := func(dir, name string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", name}} {
+			if _, err := runGit(dir, args...); err != nil {
+				t.Fatalf("git %v: %v", args, err)
+			}
+		}
+	}
+
+	commit(g.worktreePath, "one.txt")
+	commit(g.worktreePath, "two.txt")
+	stats := g.DiffAgainst(g.baseCommitSHA)
+	if stats.Unpushed != 2 || stats.Pushed || stats.Behind != 0 {
+		t.Errorf("before pushing: unpushed %d, pushed %v, behind %d; want 2 commits only local",
+			stats.Unpushed, stats.Pushed, stats.Behind)
+	}
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if _, err := runGit(g.repoPath, "init", "-q", "--bare", remote); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"remote", "add", "origin", remote},
+		{"push", "-q", "origin", "test/branch"},
+	} {
+		if _, err := runGit(g.worktreePath, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	// The commits are pushed, though the branch doesn't track the remote one
+	stats = g.DiffAgainst(g.baseCommitSHA)
+	if stats.Unpushed != 0 || !stats.Pushed {
+		t.Errorf("after pushing: unpushed %d, pushed %v; want all commits pushed", stats.Unpushed, stats.Pushed)
+	}
+	if _, err := runGit(g.worktreePath, "branch", "-q", "--set-upstream-to", "origin/test/branch"); err != nil {
+		t.Fatal(err)
+	}
+	commit(g.worktreePath, "three.txt")
+
+	// Someone else pushes to the branch
+	clone := filepath.Join(t.TempDir(), "clone")
+	if _, err := runGit(g.repoPath, "clone", "-q", "-b", "test/branch", remote, clone); err != nil {
+		t.Fatal(err)
+	}
+	commit(clone, "theirs.txt")
+	if _, err := runGit(clone, "push", "-q"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(g.worktreePath, "fetch", "-q"); err != nil {
+		t.Fatal(err)
+	}
+
+	stats = g.DiffAgainst(g.baseCommitSHA)
+	if stats.Unpushed != 1 || !stats.Pushed || stats.Behind != 1 {
+		t.Errorf("after committing more: unpushed %d, pushed %v, behind %d; want 1 unpushed, 1 behind",
+			stats.Unpushed, stats.Pushed, stats.Behind)
+	}
+}
+
+func TestDiffOmitsLargeFiles(t *testing.T) {
+	g := setupTestWorktree(t)
+	base, err := runGit(g.worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.baseCommitSHA = strings.TrimSpace(base)
+
+	large := strings.Repeat("generated line\n", maxFileDiffSize/10)
+	commitFile(t, g.worktreePath, "generated.txt", large)
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "model.bin"), []byte(large), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "small.txt"), []byte("small\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := g.Diff()
+	if stats.Error != nil {
+		t.Fatalf("Diff() error = %v", stats.Error)
+	}
+	if len(stats.Content) > 10000 {
+		t.Errorf("Diff() content is %d bytes, want the large files left out", len(stats.Content))
+	}
+	// The committed file is still counted, the unread untracked one isn't
+	if stats.Added != maxFileDiffSize/10+1 {
+		t.Errorf("Diff() = +%d, want the lines of generated.txt and small.txt", stats.Added)
+	}
+	files := stats.ChangedFiles()
+	if len(files) != 3 {
+		t.Fatalf("ChangedFiles() = %+v, want all three files listed", files)
+	}
+	for _, file := range files {
+		if file.Path != "small.txt" && !strings.Contains(file.Diff, "Diff of "+file.Path+" not shown") {
+			t.Errorf("diff of %s = %q, want it omitted", file.Path, file.Diff)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package git
+
+import "golang.org/x/sys/windows"
+
+// freeDiskSpace returns the bytes available to the user on the drive of path,
+// or of its closest existing parent.
+func freeDiskSpace(path string) (uint64, error) {
+	dir, err := windows.UTF16PtrFromString(existingParent(path))
+	if err != nil {
+		return 0, err
+	}
+	var free, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(dir, &free, &total, &totalFree); err != nil {
+		return 0, err
+	}
+	return free, nil
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"strings"
+)
+
+// fetchDefaultBranch fetches from the origin remote and fast-forwards the local
+// default branch to its remote tip, so that a new worktree starts from the
+// latest upstream code. It returns the ref of the default branch to start the
+// worktree from, or "" without an origin remote. A local default branch with
+// commits of its own, or with changes in the checkout that the fast-forward
+// would overwrite, is left alone.
+func (g *GitWorktree) fetchDefaultBranch() (string, error) {
+	if _, err := g.runGitCommand(g.repoPath, "remote", "get-url", "origin"); err != nil {
+		return "", nil
+	}
+	g.reportProgress("Fetching from origin...")
+	if _, err := g.runGitCommand(g.repoPath, "fetch", "-q", "origin"); err != nil {
+		return "", fmt.Errorf("failed to fetch from origin: %w", err)
+	}
+
+	target, err := g.findDefaultBranch()
+	if err != nil {
+		return "", err
+	}
+	local := "refs/heads/" + target
+	remote := "refs/remotes/origin/" + target
+	if _, err := g.runGitCommand(g.repoPath, "rev-parse", "--verify", "--quiet", remote); err != nil {
+		if _, err := g.runGitCommand(g.repoPath, "rev-parse", "--verify", "--quiet", local); err != nil {
+			return "", nil
+		}
+		return local, nil
+	}
+	if _, err := g.runGitCommand(g.repoPath, "rev-parse", "--verify", "--quiet", local); err != nil {
+		return remote, nil
+	}
+	if _, err := g.runGitCommand(g.repoPath, "merge-base", "--is-ancestor", local, remote); err != nil {
+		log.InfoLog.Printf("not fast-forwarding %s, it has commits that are not on origin", target)
+		return local, nil
+	}
+
+	g.reportProgress(fmt.Sprintf("Fast-forwarding '%s'...", target))
+	// The checkout of the repository has to follow its branch. A bare
+	// repository has none.
+	current, err := g.runGitCommand(g.repoPath, "symbolic-ref", "-q", "--short", "HEAD")
+	if err == nil && strings.TrimSpace(current) == target && !isBareRepo(g.repoPath) {
+		if _, err := g.runGitCommand(g.repoPath, "merge", "-q", "--ff-only", remote); err != nil {
+			// The worktree still starts from the fetched code
+			return remote, fmt.Errorf("failed to fast-forward %s: %w", target, err)
+		}
+		return local, nil
+	}
+	if _, err := g.runGitCommand(g.repoPath, "update-ref", local, remote); err != nil {
+		return remote, fmt.Errorf("failed to fast-forward %s: %w", target, err)
+	}
+	return local, nil
+}
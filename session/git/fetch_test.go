@@ -0,0 +1,86 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetupFetchesBeforeCreate(t *testing.T) {
+	g := setupTestWorktree(t)
+	upstream := filepath.Join(t.TempDir(), "upstream")
+	if _, err := runGit(g.repoPath, "clone", "-q", g.repoPath, upstream); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, upstream, "upstream.txt", "upstream\n")
+	if _, err := runGit(g.repoPath, "remote", "add", "origin", upstream); err != nil {
+		t.Fatal(err)
+	}
+
+	worktree := NewGitWorktreeFromStorage(g.repoPath, filepath.Join(t.TempDir(), "fetched"), "fetched", "test/fetched", "")
+	worktree.fetchBeforeCreate = true
+	if err := worktree.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer worktree.Cleanup()
+
+	// The new instance and the checkout of the default branch are up to date
+	for _, dir := range []string{worktree.worktreePath, g.repoPath} {
+		if _, err := os.Stat(filepath.Join(dir, "upstream.txt")); err != nil {
+			t.Errorf("upstream.txt missing from %s: %v", dir, err)
+		}
+	}
+}
+
+func TestFetchDefaultBranchKeepsLocalCommits(t *testing.T) {
+	g := setupTestWorktree(t)
+	upstream := filepath.Join(t.TempDir(), "upstream")
+	if _, err := runGit(g.repoPath, "clone", "-q", g.repoPath, upstream); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, upstream, "upstream.txt", "upstream\n")
+	commitFile(t, g.repoPath, "local.txt", "local\n")
+	if _, err := runGit(g.repoPath, "remote", "add", "origin", upstream); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.fetchDefaultBranch(); err != nil {
+		t.Fatalf("fetchDefaultBranch() error = %v", err)
+	}
+	// The default branch diverged from origin, so it isn't moved
+	if _, err := os.Stat(filepath.Join(g.repoPath, "upstream.txt")); !os.IsNotExist(err) {
+		t.Errorf("the diverged default branch was updated")
+	}
+}
+
+func TestSetupFetchesDefaultBranchOffAnotherCheckout(t *testing.T) {
+	g := setupTestWorktree(t)
+	upstream := filepath.Join(t.TempDir(), "upstream")
+	if _, err := runGit(g.repoPath, "clone", "-q", g.repoPath, upstream); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, upstream, "upstream.txt", "upstream\n")
+	if _, err := runGit(g.repoPath, "remote", "add", "origin", upstream); err != nil {
+		t.Fatal(err)
+	}
+	// The repository has a feature branch checked out
+	if _, err := runGit(g.repoPath, "checkout", "-q", "-b", "feature"); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, g.repoPath, "feature.txt", "feature\n")
+
+	worktree := NewGitWorktreeFromStorage(g.repoPath, filepath.Join(t.TempDir(), "fetched"), "fetched", "test/fetched", "")
+	worktree.fetchBeforeCreate = true
+	if err := worktree.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer worktree.Cleanup()
+
+	// The instance starts from the fetched default branch, not the feature branch
+	if _, err := os.Stat(filepath.Join(worktree.worktreePath, "upstream.txt")); err != nil {
+		t.Errorf("upstream.txt missing from the worktree: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktree.worktreePath, "feature.txt")); !os.IsNotExist(err) {
+		t.Errorf("the worktree started from the checked out feature branch")
+	}
+}
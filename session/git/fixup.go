@@ -0,0 +1,165 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// hunkHeaderPattern matches the old-file range of a unified diff hunk header
+	hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+`)
+	// blameHeaderPattern matches the commit header lines of `git blame --porcelain`
+	blameHeaderPattern = regexp.MustCompile(`^([0-9a-f]{40}) \d+ \d+`)
+)
+
+// FixupCommit is a fixup commit created for manual changes.
+type FixupCommit struct {
+	// Target is the branch commit the changes are folded into.
+	Target RebaseCommit
+	Files  []string
+}
+
+// lineRange is a range of lines in the HEAD version of a file.
+type lineRange struct {
+	start, count int
+}
+
+// CreateFixupCommits commits the uncommitted changes to tracked files as
+// "fixup!" commits targeting the branch commit that last touched the changed
+// lines, according to git blame. A file is only routed if all of its changed
+// lines come from a single commit on the branch; the remaining files are left
+// uncommitted and returned as skipped.
+func (g *GitWorktree) CreateFixupCommits() (created []FixupCommit, skipped []string, err error) {
+	branchCommits, err := g.BranchCommits()
+	if err != nil {
+		return nil, nil, err
+	}
+	bySHA := make(map[string]RebaseCommit, len(branchCommits))
+	for _, c := range branchCommits {
+		bySHA[c.SHA] = c
+	}
+
+	output, err := g.runGitCommand(g.worktreePath, "diff", "--name-only", "HEAD")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+
+	// Group the changed files by the commit they fix up, keeping the commit order
+	filesByTarget := make(map[string][]string)
+	for _, file := range strings.Split(strings.TrimSpace(output), "\n") {
+		if file == "" {
+			continue
+		}
+		target, err := g.fixupTarget(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, onBranch := bySHA[target]; !onBranch {
+			skipped = append(skipped, file)
+			continue
+		}
+		filesByTarget[target] = append(filesByTarget[target], file)
+	}
+	if len(filesByTarget) == 0 {
+		return nil, skipped, nil
+	}
+
+	// Start from a clean index so each fixup commit only contains its own files
+	if _, err := g.runGitCommand(g.worktreePath, "reset", "-q"); err != nil {
+		return nil, nil, fmt.Errorf("failed to reset index: %w", err)
+	}
+	for _, c := range branchCommits {
+		files, ok := filesByTarget[c.SHA]
+		if !ok {
+			continue
+		}
+		if _, err := g.runGitCommand(g.worktreePath, append([]string{"add", "-A", "--"}, files...)...); err != nil {
+			return created, skipped, fmt.Errorf("failed to stage changes: %w", err)
+		}
+		if _, err := g.runGitCommand(g.worktreePath, "commit", "-q", "--no-verify", "--fixup="+c.SHA); err != nil {
+			return created, skipped, fmt.Errorf("failed to create fixup commit for %.7s: %w", c.SHA, err)
+		}
+		created = append(created, FixupCommit{Target: c, Files: files})
+	}
+
+	g.InvalidateDiffCache()
+	return created, skipped, nil
+}
+
+// fixupTarget returns the commit that introduced all the lines changed in the
+// file, or an empty string if the lines come from more than one commit.
+func (g *GitWorktree) fixupTarget(file string) (string, error) {
+	diff, err := g.runGitCommand(g.worktreePath, "diff", "-U0", "HEAD", "--", file)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s: %w", file, err)
+	}
+
+	target := ""
+	for _, r := range parseChangedRanges(diff) {
+		blame, err := g.runGitCommand(g.worktreePath, "blame", "--porcelain", "-L", fmt.Sprintf("%d,+%d", r.start, r.count), "HEAD", "--", file)
+		if err != nil {
+			if g.emptyAtHead(file) {
+				// A new file or a pure addition to an empty one, there is nothing to blame
+				return "", nil
+			}
+			return "", fmt.Errorf("failed to blame %s: %w", file, err)
+		}
+		for _, sha := range parseBlameCommits(blame) {
+			if target != "" && sha != target {
+				return "", nil
+			}
+			target = sha
+		}
+	}
+	return target, nil
+}
+
+// emptyAtHead returns true if the file has no lines in HEAD, because it is
+// empty or doesn't exist there.
+func (g *GitWorktree) emptyAtHead(file string) bool {
+	size, err := g.runGitCommand(g.worktreePath, "cat-file", "-s", "HEAD:"+file)
+	return err != nil || strings.TrimSpace(size) == "0"
+}
+
+// parseChangedRanges returns the lines of the old file touched by each hunk of
+// a `git diff -U0` output. Pure additions are attributed to the line they follow.
+func parseChangedRanges(diff string) []lineRange {
+	var ranges []lineRange
+	for _, line := range strings.Split(diff, "\n") {
+		m := hunkHeaderPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		start, _ := strconv.Atoi(m[1])
+		count := 1
+		if m[2] != "" {
+			count, _ = strconv.Atoi(m[2])
+		}
+		if count == 0 {
+			// Lines were only added after line `start`; use it (or the first line) as context
+			count = 1
+			if start == 0 {
+				start = 1
+			}
+		}
+		ranges = append(ranges, lineRange{start: start, count: count})
+	}
+	return ranges
+}
+
+// parseBlameCommits returns the distinct commits in `git blame --porcelain` output.
+func parseBlameCommits(blame string) []string {
+	var commits []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(blame, "\n") {
+		m := blameHeaderPattern.FindStringSubmatch(line)
+		if m == nil || seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		commits = append(commits, m[1])
+	}
+	return commits
+}
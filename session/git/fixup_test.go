@@ -0,0 +1,98 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseChangedRanges(t *testing.T) {
+	diff := `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -3 +3 @@ context
+@@ -7,2 +7,0 @@
+@@ -10,0 +9,2 @@
+@@ -0,0 +1 @@
+`
+	got := parseChangedRanges(diff)
+	want := []lineRange{{3, 1}, {7, 2}, {10, 1}, {1, 1}}
+	if len(got) != len(want) {
+		t.Fatalf("parseChangedRanges() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("range %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCreateFixupCommits(t *testing.T) {
+	g := setupTestWorktree(t)
+
+	commit := func(file, content, msg string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(g.worktreePath, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runGit(g.worktreePath, "add", "."); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runGit(g.worktreePath, "commit", "-q", "-m", msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commit("base.txt", "base\n", "base")
+	base, err := runGit(g.worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.baseCommitSHA = strings.TrimSpace(base)
+
+	commit("a.txt", "one\ntwo\n", "add a")
+	commit("b.txt", "three\n", "add b")
+
+	// Manual tweaks: one per agent commit, and one to a file from before the branch
+	for file, content := range map[string]string{
+		"a.txt":    "one\nTWO\n",
+		"b.txt":    "three\nfour\n",
+		"base.txt": "changed\n",
+	} {
+		if err := os.WriteFile(filepath.Join(g.worktreePath, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	created, skipped, err := g.CreateFixupCommits()
+	if err != nil {
+		t.Fatalf("CreateFixupCommits() error = %v", err)
+	}
+	if len(created) != 2 || created[0].Target.Subject != "add a" || created[1].Target.Subject != "add b" {
+		t.Fatalf("CreateFixupCommits() created = %+v, want fixups for 'add a' and 'add b'", created)
+	}
+	if len(skipped) != 1 || skipped[0] != "base.txt" {
+		t.Errorf("CreateFixupCommits() skipped = %v, want [base.txt]", skipped)
+	}
+
+	// The rebase needs a clean worktree
+	if _, err := runGit(g.worktreePath, "checkout", "--", "base.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Autosquash(); err != nil {
+		t.Fatalf("Autosquash() error = %v", err)
+	}
+
+	commits, err := g.BranchCommits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("branch should have 2 commits after autosquash, got %+v", commits)
+	}
+	show, err := runGit(g.worktreePath, "show", commits[0].SHA+":a.txt")
+	if err != nil || show != "one\nTWO\n" {
+		t.Errorf("first commit a.txt = %q, %v; want the fixup folded in", show, err)
+	}
+}
@@ -0,0 +1,233 @@
+package git
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Names of the supported forges, as used in the config.
+const (
+	ForgeGitHub    = "github"
+	ForgeGitLab    = "gitlab"
+	ForgeBitbucket = "bitbucket"
+)
+
+// Forge is the service hosting a repository's remote. It performs the parts of
+// the push workflow that go beyond plain git.
+type Forge interface {
+	// Name returns the name of the forge, e.g. "github".
+	Name() string
+	// CheckCLI returns an error if the command line tool the forge needs is
+	// missing or not logged in.
+	CheckCLI() error
+	// Push publishes the branch checked out in dir to the remote.
+	Push(dir, branch string) error
+	// OpenBranch opens the branch on the forge in the default browser.
+	OpenBranch(dir, branch string) error
+}
+
+// MergeRequester is implemented by forges whose branches are opened as a merge
+// request rather than as a plain branch after pushing.
+type MergeRequester interface {
+	// OpenMergeRequest creates a merge request for the pushed branch in dir,
+	// unless one exists, and opens it in the default browser.
+	OpenMergeRequest(dir, branch string) error
+}
+
+// DetectForge returns the forge with the given name, or detects it from the
+// host of the repository's origin remote if name is empty. GitHub is assumed
+// if the remote is missing or its host is not recognized.
+func DetectForge(repoPath, name string) (Forge, error) {
+	remote, err := runGit(repoPath, "remote", "get-url", "origin")
+	if err != nil {
+		remote = ""
+	}
+	host, path := parseRemoteURL(strings.TrimSpace(remote))
+
+	if name == "" {
+		switch {
+		case strings.Contains(host, "gitlab"):
+			name = ForgeGitLab
+		case strings.Contains(host, "bitbucket"):
+			name = ForgeBitbucket
+		default:
+			name = ForgeGitHub
+		}
+	}
+
+	switch strings.ToLower(name) {
+	case ForgeGitHub:
+		return githubForge{}, nil
+	case ForgeGitLab:
+		return gitlabForge{}, nil
+	case ForgeBitbucket:
+		return bitbucketForge{host: host, path: path}, nil
+	default:
+		return nil, fmt.Errorf("unknown forge %q, expected %s, %s or %s", name, ForgeGitHub, ForgeGitLab, ForgeBitbucket)
+	}
+}
+
+// parseRemoteURL returns the host and the repository path, without the .git
+// suffix, of a remote URL in either URL or scp-like syntax.
+func parseRemoteURL(remote string) (host, path string) {
+	if strings.Contains(remote, "://") {
+		parsed, err := url.Parse(remote)
+		if err != nil {
+			return "", ""
+		}
+		host, path = parsed.Hostname(), parsed.Path
+	} else if at, rest, ok := strings.Cut(remote, ":"); ok {
+		// user@host:path
+		if _, h, found := strings.Cut(at, "@"); found {
+			at = h
+		}
+		host, path = at, rest
+	}
+	return strings.ToLower(host), strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
+
+// pushBranch pushes the branch to origin and sets it as the upstream.
+func pushBranch(dir, branch string) error {
+	cmd := exec.Command("git", "push", "-u", "origin", branch)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		log.ErrorLog.Print(err)
+		return fmt.Errorf("failed to push branch: %s (%w)", output, err)
+	}
+	return nil
+}
+
+// OpenURL opens the URL in the default browser.
+func OpenURL(target string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target).Run()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target).Run()
+	default:
+		return exec.Command("xdg-open", target).Run()
+	}
+}
+
+// githubForge uses the GitHub CLI (gh).
+type githubForge struct{}
+
+func (githubForge) Name() string { return ForgeGitHub }
+
+func (githubForge) CheckCLI() error { return checkGHCLI() }
+
+func (githubForge) Push(dir, branch string) error {
+	// First push the branch to remote to ensure it exists
+	pushCmd := exec.Command("gh", "repo", "sync", "--source", "-b", branch)
+	pushCmd.Dir = dir
+	if err := pushCmd.Run(); err != nil {
+		// If sync fails, try creating the branch on remote first
+		if err := pushBranch(dir, branch); err != nil {
+			return err
+		}
+	}
+
+	// Now sync with remote
+	syncCmd := exec.Command("gh", "repo", "sync", "-b", branch)
+	syncCmd.Dir = dir
+	if output, err := syncCmd.CombinedOutput(); err != nil {
+		log.ErrorLog.Print(err)
+		return fmt.Errorf("failed to sync changes: %s (%w)", output, err)
+	}
+	return nil
+}
+
+func (githubForge) OpenBranch(dir, branch string) error {
+	cmd := exec.Command("gh", "browse", "--branch", branch)
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to open branch URL: %w", err)
+	}
+	return nil
+}
+
+// gitlabForge uses the GitLab CLI (glab), which also works with self-hosted instances.
+type gitlabForge struct{}
+
+func (gitlabForge) Name() string { return ForgeGitLab }
+
+func (gitlabForge) CheckCLI() error {
+	if _, err := exec.LookPath("glab"); err != nil {
+		return fmt.Errorf("GitLab CLI (glab) is not installed. Please install it first")
+	}
+	if err := exec.Command("glab", "auth", "status").Run(); err != nil {
+		return fmt.Errorf("GitLab CLI is not configured. Please run 'glab auth login' first")
+	}
+	return nil
+}
+
+func (gitlabForge) Push(dir, branch string) error {
+	return pushBranch(dir, branch)
+}
+
+func (gitlabForge) OpenBranch(dir, branch string) error {
+	cmd := exec.Command("glab", "repo", "view", "--web", "--branch", branch)
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to open branch URL: %w", err)
+	}
+	return nil
+}
+
+func (gitlabForge) OpenMergeRequest(dir, branch string) error {
+	view := exec.Command("glab", "mr", "view", branch, "--web")
+	view.Dir = dir
+	if view.Run() == nil {
+		return nil
+	}
+
+	// No merge request yet: create one titled and described from the commits
+	create := exec.Command("glab", "mr", "create", "--fill", "--yes", "--source-branch", branch)
+	create.Dir = dir
+	if output, err := create.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create merge request: %s (%w)", strings.TrimSpace(string(output)), err)
+	}
+	view = exec.Command("glab", "mr", "view", branch, "--web")
+	view.Dir = dir
+	if err := view.Run(); err != nil {
+		return fmt.Errorf("failed to open merge request: %w", err)
+	}
+	return nil
+}
+
+// bitbucketForge needs no CLI: branches are pushed with git and opened by URL.
+type bitbucketForge struct {
+	host string
+	path string
+}
+
+func (bitbucketForge) Name() string { return ForgeBitbucket }
+
+func (bitbucketForge) CheckCLI() error { return nil }
+
+func (bitbucketForge) Push(dir, branch string) error {
+	return pushBranch(dir, branch)
+}
+
+func (b bitbucketForge) OpenBranch(dir, branch string) error {
+	target, err := b.branchURL(branch)
+	if err != nil {
+		return err
+	}
+	if err := OpenURL(target); err != nil {
+		return fmt.Errorf("failed to open branch URL: %w", err)
+	}
+	return nil
+}
+
+// branchURL returns the page of the branch on Bitbucket.
+func (b bitbucketForge) branchURL(branch string) (string, error) {
+	if b.host == "" || b.path == "" {
+		return "", fmt.Errorf("cannot determine the Bitbucket repository from the origin remote")
+	}
+	return fmt.Sprintf("https://%s/%s/branch/%s", b.host, b.path, url.PathEscape(branch)), nil
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		remote   string
+		wantHost string
+		wantPath string
+	}{
+		{"git@github.com:owner/repo.git", "github.com", "owner/repo"},
+		{"https://github.com/owner/repo.git", "github.com", "owner/repo"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/repo.git", "gitlab.example.com", "group/sub/repo"},
+		{"https://user@bitbucket.org/workspace/repo", "bitbucket.org", "workspace/repo"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			host, path := parseRemoteURL(tt.remote)
+			if host != tt.wantHost || path != tt.wantPath {
+				t.Errorf("parseRemoteURL(%q) = %q, %q, want %q, %q", tt.remote, host, path, tt.wantHost, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestDetectForge(t *testing.T) {
+	tests := []struct {
+		name   string
+		remote string
+		config string
+		want   string
+	}{
+		{"github remote", "git@github.com:owner/repo.git", "", ForgeGitHub},
+		{"gitlab remote", "https://gitlab.com/group/repo.git", "", ForgeGitLab},
+		{"self-hosted gitlab", "git@gitlab.internal:group/repo.git", "", ForgeGitLab},
+		{"bitbucket remote", "git@bitbucket.org:workspace/repo.git", "", ForgeBitbucket},
+		{"unknown host", "git@git.example.com:group/repo.git", "", ForgeGitHub},
+		{"no remote", "", "", ForgeGitHub},
+		{"configured", "git@git.example.com:group/repo.git", "GitLab", ForgeGitLab},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoPath := t.TempDir()
+			if _, err := runGit(repoPath, "init", "-q"); err != nil {
+				t.Fatal(err)
+			}
+			if tt.remote != "" {
+				if _, err := runGit(repoPath, "remote", "add", "origin", tt.remote); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			forge, err := DetectForge(repoPath, tt.config)
+			if err != nil {
+				t.Fatalf("DetectForge() error = %v", err)
+			}
+			if forge.Name() != tt.want {
+				t.Errorf("DetectForge() = %s, want %s", forge.Name(), tt.want)
+			}
+		})
+	}
+
+	if _, err := DetectForge(t.TempDir(), "sourcehut"); err == nil {
+		t.Error("DetectForge() succeeded for an unknown forge")
+	}
+}
+
+func TestBitbucketBranchURL(t *testing.T) {
+	forge := bitbucketForge{host: "bitbucket.org", path: "workspace/repo"}
+	got, err := forge.branchURL("feature/x")
+	if err != nil {
+		t.Fatalf("branchURL() error = %v", err)
+	}
+	if want := "https://bitbucket.org/workspace/repo/branch/feature%2Fx"; got != want {
+		t.Errorf("branchURL() = %q, want %q", got, want)
+	}
+}
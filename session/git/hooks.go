@@ -0,0 +1,124 @@
+package git
+
+import (
+	"claude-squad/config"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HookEvent is a Claude Code hook event reported by the hooks installed in the worktree.
+type HookEvent string
+
+const (
+	// HookEventUserPromptSubmit fires when a prompt is submitted to Claude.
+	HookEventUserPromptSubmit HookEvent = "UserPromptSubmit"
+	// HookEventPreToolUse fires before Claude runs a tool.
+	HookEventPreToolUse HookEvent = "PreToolUse"
+	// HookEventNotification fires when Claude needs permission or is waiting for input.
+	HookEventNotification HookEvent = "Notification"
+	// HookEventStop fires when Claude has finished responding.
+	HookEventStop HookEvent = "Stop"
+)
+
+// statusHookEvents are the events that are written to the hook status file.
+var statusHookEvents = []HookEvent{
+	HookEventUserPromptSubmit,
+	HookEventPreToolUse,
+	HookEventNotification,
+	HookEventStop,
+}
+
+// ClaudeHookMatcher is a group of hooks in the hooks section of Claude settings
+type ClaudeHookMatcher struct {
+	Matcher string       `json:"matcher,omitempty"`
+	Hooks   []ClaudeHook `json:"hooks"`
+}
+
+// ClaudeHook is a single command hook in Claude settings
+type ClaudeHook struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+}
+
+// HooksDirectory returns the directory the Claude hooks of the worktrees write
+// their status files to.
+func HooksDirectory() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "hooks"), nil
+}
+
+// HookStatusFile returns the file the worktree's Claude hooks write their events to.
+func (g *GitWorktree) HookStatusFile() (string, error) {
+	hooksDir, err := HooksDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(hooksDir, filepath.Base(g.worktreePath)+".status"), nil
+}
+
+// claudeHooks returns the hooks section of the Claude settings. Each hook
+// overwrites the status file with the name of its event, so the file always
+// holds the latest event.
+func claudeHooks(statusFile string) map[string][]ClaudeHookMatcher {
+	hooks := make(map[string][]ClaudeHookMatcher, len(statusHookEvents))
+	for _, event := range statusHookEvents {
+		hooks[string(event)] = []ClaudeHookMatcher{{
+			Hooks: []ClaudeHook{{
+				Type:    "command",
+				Command: fmt.Sprintf("echo %s > %s", event, shellQuote(filepath.ToSlash(statusFile))),
+			}},
+		}}
+	}
+	return hooks
+}
+
+// installClaudeHooks prepares the hook status file and returns the hooks to add
+// to the Claude settings. Any event left over from a previous run is cleared.
+func (g *GitWorktree) installClaudeHooks() (map[string][]ClaudeHookMatcher, error) {
+	statusFile, err := g.HookStatusFile()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(statusFile), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := g.removeHookStatusFile(); err != nil {
+		return nil, err
+	}
+	return claudeHooks(statusFile), nil
+}
+
+// ReadHookEvent returns the latest event written by the worktree's Claude hooks,
+// or an empty event if none has fired yet.
+func (g *GitWorktree) ReadHookEvent() (HookEvent, error) {
+	statusFile, err := g.HookStatusFile()
+	if err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(statusFile)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read hook status file: %w", err)
+	}
+	return HookEvent(strings.TrimSpace(string(content))), nil
+}
+
+// removeHookStatusFile removes the hook status file if it exists.
+func (g *GitWorktree) removeHookStatusFile() error {
+	statusFile, err := g.HookStatusFile()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(statusFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove hook status file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestClaudeHooksReportStatus(t *testing.T) {
+	g := setupTestWorktree(t)
+
+	if err := g.createClaudeSettingsFile(); err != nil {
+		t.Fatalf("createClaudeSettingsFile() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(g.worktreePath, ".claude", "settings.local.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings ClaudeSettings
+	if err := json.Unmarshal(content, &settings); err != nil {
+		t.Fatalf("settings are not valid JSON: %v", err)
+	}
+
+	if event, err := g.ReadHookEvent(); err != nil || event != "" {
+		t.Fatalf("ReadHookEvent() before any hook = %q, %v; want empty", event, err)
+	}
+
+	// Run the hook commands the way Claude would
+	for _, event := range []HookEvent{HookEventPreToolUse, HookEventStop} {
+		matchers := settings.Hooks[string(event)]
+		if len(matchers) != 1 || len(matchers[0].Hooks) != 1 {
+			t.Fatalf("hooks for %s = %+v, want a single command", event, matchers)
+		}
+		if output, err := exec.Command("sh", "-c", matchers[0].Hooks[0].Command).CombinedOutput(); err != nil {
+			t.Fatalf("hook for %s failed: %v: %s", event, err, output)
+		}
+		if got, err := g.ReadHookEvent(); err != nil || got != event {
+			t.Errorf("ReadHookEvent() after %s hook = %q, %v", event, got, err)
+		}
+	}
+
+	// Reinstalling the hooks clears the stale event
+	if err := g.createClaudeSettingsFile(); err != nil {
+		t.Fatal(err)
+	}
+	if event, err := g.ReadHookEvent(); err != nil || event != "" {
+		t.Errorf("ReadHookEvent() after reinstall = %q, %v; want empty", event, err)
+	}
+}
@@ -0,0 +1,121 @@
+package git
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Issue is a GitHub issue, as described by the GitHub CLI.
+type Issue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	URL    string `json:"url"`
+}
+
+// issueFields are the fields of Issue requested from the GitHub CLI.
+const issueFields = "number,title,body,url"
+
+// Repo returns the owner/name of the issue's repository, taken from its URL.
+func (i Issue) Repo() string {
+	repo, _, err := parseIssueURL(i.URL)
+	if err != nil {
+		return ""
+	}
+	return repo
+}
+
+// ParseIssueRef parses a reference to a GitHub issue: owner/repo#123, the URL
+// of the issue, or #123 or 123 for an issue of the repository the GitHub CLI
+// finds in the working directory, in which case repo is empty.
+func ParseIssueRef(ref string) (repo string, number int, err error) {
+	ref = strings.TrimSpace(ref)
+	if strings.Contains(ref, "://") {
+		return parseIssueURL(ref)
+	}
+	repo, num, found := strings.Cut(ref, "#")
+	if !found {
+		repo, num = "", ref
+	}
+	number, err = strconv.Atoi(num)
+	if err != nil || number <= 0 {
+		return "", 0, fmt.Errorf("invalid issue %q, expected owner/repo#123, #123 or the issue URL", ref)
+	}
+	if repo != "" && strings.Count(repo, "/") != 1 {
+		return "", 0, fmt.Errorf("invalid repository %q in issue %q, expected owner/repo", repo, ref)
+	}
+	return repo, number, nil
+}
+
+// parseIssueURL returns the repository and number of an issue URL like
+// https://github.com/owner/repo/issues/123.
+func parseIssueURL(ref string) (string, int, error) {
+	parsed, err := url.Parse(ref)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid issue URL %q: %w", ref, err)
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) != 4 || parts[2] != "issues" {
+		return "", 0, fmt.Errorf("invalid issue URL %q, expected https://github.com/owner/repo/issues/123", ref)
+	}
+	number, err := strconv.Atoi(parts[3])
+	if err != nil || number <= 0 {
+		return "", 0, fmt.Errorf("invalid issue number in %q", ref)
+	}
+	return parts[0] + "/" + parts[1], number, nil
+}
+
+// FetchIssue fetches the issue with the GitHub CLI. repo is owner/name, or
+// empty for the repository of dir.
+func FetchIssue(dir, repo string, number int) (*Issue, error) {
+	args := []string{"issue", "view", strconv.Itoa(number), "--json", issueFields}
+	if repo != "" {
+		args = append(args, "--repo", repo)
+	}
+	output, err := runGH(dir, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue #%d: %w", number, err)
+	}
+	var issue Issue
+	if err := json.Unmarshal(output, &issue); err != nil {
+		return nil, fmt.Errorf("failed to parse issue #%d: %w", number, err)
+	}
+	return &issue, nil
+}
+
+// ListIssues lists up to limit open issues of the repository of dir, newest
+// first, with the GitHub CLI.
+func ListIssues(dir string, limit int) ([]Issue, error) {
+	output, err := runGH(dir, "issue", "list", "--state", "open", "--limit", strconv.Itoa(limit), "--json", issueFields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+	var issues []Issue
+	if err := json.Unmarshal(output, &issues); err != nil {
+		return nil, fmt.Errorf("failed to parse issues: %w", err)
+	}
+	return issues, nil
+}
+
+// runGH runs the GitHub CLI in dir and returns its output.
+func runGH(dir string, args ...string) ([]byte, error) {
+	if err := checkGHCLI(); err != nil {
+		return nil, err
+	}
+	cmd := exec.Command("gh", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("%s (%w)", strings.TrimSpace(string(exitErr.Stderr)), err)
+		}
+		return nil, err
+	}
+	return output, nil
+}
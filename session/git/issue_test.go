@@ -0,0 +1,34 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseIssueRef(t *testing.T) {
+	tests := []struct {
+		ref    string
+		repo   string
+		number int
+	}{
+		{"owner/repo#123", "owner/repo", 123},
+		{"#7", "", 7},
+		{"42", "", 42},
+		{"https://github.com/owner/repo/issues/9", "owner/repo", 9},
+	}
+	for _, tt := range tests {
+		repo, number, err := ParseIssueRef(tt.ref)
+		assert.NoError(t, err, tt.ref)
+		assert.Equal(t, tt.repo, repo, tt.ref)
+		assert.Equal(t, tt.number, number, tt.ref)
+	}
+
+	for _, ref := range []string{"", "owner/repo#", "repo#12", "a/b/c#1", "#-1", "https://github.com/owner/repo/pull/9"} {
+		_, _, err := ParseIssueRef(ref)
+		assert.Error(t, err, ref)
+	}
+
+	assert.Equal(t, "owner/repo", Issue{URL: "https://github.com/owner/repo/issues/9"}.Repo())
+	assert.Equal(t, "", Issue{}.Repo())
+}
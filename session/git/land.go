@@ -0,0 +1,124 @@
+package git
+
+import (
+	"bytes"
+	"claude-squad/config"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// landCheckTimeout bounds how long the check command run by Land may take.
+const landCheckTimeout = 30 * time.Minute
+
+// landCheckOutputLines is how many trailing lines of a failed check's output are reported.
+const landCheckOutputLines = 20
+
+var (
+	// ErrLandConflict is returned by Land when the branch doesn't rebase cleanly onto the default branch.
+	ErrLandConflict = errors.New("branch conflicts with the default branch")
+	// ErrLandCheckFailed is returned by Land when the check command fails on the rebased branch.
+	ErrLandCheckFailed = errors.New("check command failed")
+)
+
+// Land merges the branch into the default branch: uncommitted changes are
+// committed, fixups are squashed, the branch is rebased onto the default branch,
+// the check command (if any) is run in the worktree and the result is pushed to
+// the default branch as a fast-forward. Without an origin remote the local
+// default branch is updated instead. A rebase that stops is aborted, leaving
+// the branch as it was.
+func (g *GitWorktree) Land(commitMessage, checkCommand string) error {
+	target, err := g.findDefaultBranch()
+	if err != nil {
+		return err
+	}
+	if target == g.branchName {
+		return fmt.Errorf("branch %s is the default branch", g.branchName)
+	}
+
+	if err := g.CommitChanges(commitMessage); err != nil {
+		return err
+	}
+	if g.baseCommitSHA != "" {
+		if err := g.Autosquash(); err != nil {
+			return fmt.Errorf("failed to autosquash fixup commits: %w", err)
+		}
+	}
+
+	upstream := target
+	_, err = g.runGitCommand(g.repoPath, "remote", "get-url", "origin")
+	hasRemote := err == nil
+	if hasRemote {
+		if _, err := g.runGitCommand(g.worktreePath, "fetch", "-q", "origin", target); err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", target, err)
+		}
+		upstream = "origin/" + target
+	}
+
+	if _, err := g.runGitCommand(g.worktreePath, "rebase", upstream); err != nil {
+		_, _ = g.runGitCommand(g.worktreePath, "rebase", "--abort")
+		return fmt.Errorf("%w: %v", ErrLandConflict, err)
+	}
+
+	if err := g.runLandCheck(checkCommand); err != nil {
+		return err
+	}
+
+	if hasRemote {
+		if _, err := g.runGitCommand(g.worktreePath, "push", "origin", "HEAD:refs/heads/"+target); err != nil {
+			return fmt.Errorf("failed to push to %s: %w", target, err)
+		}
+	} else if err := g.fastForwardLocal(target); err != nil {
+		return err
+	}
+
+	// The branch is now part of the default branch, so nothing is left to diff
+	if head, err := g.runGitCommand(g.worktreePath, "rev-parse", "HEAD"); err == nil {
+		g.baseCommitSHA = strings.TrimSpace(head)
+		g.InvalidateDiffCache()
+	}
+	return nil
+}
+
+// runLandCheck runs the check command in the worktree. An empty command passes.
+func (g *GitWorktree) runLandCheck(checkCommand string) error {
+	if strings.TrimSpace(checkCommand) == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), landCheckTimeout)
+	defer cancel()
+
+	cmd := config.ShellCommand(ctx, checkCommand)
+	cmd.Dir = g.worktreePath
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		lines := strings.Split(strings.TrimRight(output.String(), "\n"), "\n")
+		if len(lines) > landCheckOutputLines {
+			lines = lines[len(lines)-landCheckOutputLines:]
+		}
+		return fmt.Errorf("%w: %s: %v\n%s", ErrLandCheckFailed, checkCommand, err, strings.Join(lines, "\n"))
+	}
+	return nil
+}
+
+// fastForwardLocal moves the local target branch to the worktree's HEAD. If the
+// target is checked out in the main repository it is merged there so that the
+// checkout stays in sync. A bare repository has no checkout to keep in sync.
+func (g *GitWorktree) fastForwardLocal(target string) error {
+	current, err := g.runGitCommand(g.repoPath, "symbolic-ref", "-q", "--short", "HEAD")
+	if err == nil && strings.TrimSpace(current) == target && !isBareRepo(g.repoPath) {
+		if _, err := g.runGitCommand(g.repoPath, "merge", "-q", "--ff-only", g.branchName); err != nil {
+			return fmt.Errorf("failed to fast-forward %s: %w", target, err)
+		}
+		return nil
+	}
+	if _, err := g.runGitCommand(g.worktreePath, "fetch", "-q", ".", "HEAD:refs/heads/"+target); err != nil {
+		return fmt.Errorf("failed to fast-forward %s: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLand(t *testing.T) {
+	g := setupTestWorktree(t)
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Land("add feature", ""); err != nil {
+		t.Fatalf("Land() error = %v", err)
+	}
+
+	// The default branch is checked out in the repository and was fast-forwarded
+	if _, err := os.Stat(filepath.Join(g.repoPath, "feature.txt")); err != nil {
+		t.Errorf("feature.txt missing from the default branch: %v", err)
+	}
+	head, _ := runGit(g.worktreePath, "rev-parse", "HEAD")
+	if g.baseCommitSHA != strings.TrimSpace(head) {
+		t.Errorf("baseCommitSHA = %q, want HEAD %q", g.baseCommitSHA, strings.TrimSpace(head))
+	}
+}
+
+func TestLandConflict(t *testing.T) {
+	g := setupTestWorktree(t)
+	for path, content := range map[string]string{
+		filepath.Join(g.repoPath, "file.txt"):     "upstream\n",
+		filepath.Join(g.worktreePath, "file.txt"): "branch\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"add", "file.txt"}, {"commit", "-q", "-m", "upstream change"}} {
+		if _, err := runGit(g.repoPath, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := g.Land("branch change", "")
+	if !errors.Is(err, ErrLandConflict) {
+		t.Fatalf("Land() error = %v, want ErrLandConflict", err)
+	}
+	// The rebase was aborted
+	if status, _ := runGit(g.worktreePath, "status"); strings.Contains(status, "rebase") {
+		t.Errorf("worktree is still rebasing:\n%s", status)
+	}
+}
+
+func TestLandCheckFailed(t *testing.T) {
+	g := setupTestWorktree(t)
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := g.Land("add feature", "echo checking; exit 1")
+	if !errors.Is(err, ErrLandCheckFailed) {
+		t.Fatalf("Land() error = %v, want ErrLandCheckFailed", err)
+	}
+	if !strings.Contains(err.Error(), "checking") {
+		t.Errorf("Land() error = %v, want the check output", err)
+	}
+	if _, err := os.Stat(filepath.Join(g.repoPath, "feature.txt")); !os.IsNotExist(err) {
+		t.Errorf("the default branch was updated despite the failed check")
+	}
+}
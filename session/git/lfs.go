@@ -0,0 +1,37 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// usesLFS returns true if the .gitattributes at the root of the worktree
+// stores files with git-lfs.
+func (g *GitWorktree) usesLFS() bool {
+	attributes, err := os.ReadFile(filepath.Join(g.worktreePath, ".gitattributes"))
+	return err == nil && strings.Contains(string(attributes), "filter=lfs")
+}
+
+// pullLFS replaces the git-lfs pointer files of the worktree with the large
+// files they stand for. A new worktree only has the pointers unless the git-lfs
+// filters were installed when it was checked out. It does nothing if the
+// repository doesn't use git-lfs.
+func (g *GitWorktree) pullLFS() error {
+	if !g.usesLFS() {
+		return nil
+	}
+	if _, err := exec.LookPath("git-lfs"); err != nil {
+		return fmt.Errorf("the repository uses git-lfs, which is not installed: large files are left as pointer files")
+	}
+	g.reportProgress("Pulling git-lfs files...")
+	if _, err := g.runGitCommand(g.worktreePath, "lfs", "install", "--local"); err != nil {
+		return fmt.Errorf("failed to install git-lfs: %w", err)
+	}
+	if _, err := g.runGitCommand(g.worktreePath, "lfs", "pull"); err != nil {
+		return fmt.Errorf("failed to pull git-lfs files: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUsesLFS(t *testing.T) {
+	g := setupTestWorktree(t)
+	if g.usesLFS() {
+		t.Error("usesLFS() without .gitattributes should be false")
+	}
+	if err := g.pullLFS(); err != nil {
+		t.Errorf("pullLFS() without git-lfs files should do nothing, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(g.worktreePath, ".gitattributes"), []byte("*.txt text\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if g.usesLFS() {
+		t.Error("usesLFS() without lfs attributes should be false")
+	}
+	if err := os.WriteFile(filepath.Join(g.worktreePath, ".gitattributes"), []byte("*.psd filter=lfs diff=lfs merge=lfs -text\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !g.usesLFS() {
+		t.Error("usesLFS() with lfs attributes should be true")
+	}
+}
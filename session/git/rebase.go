@@ -0,0 +1,180 @@
+package git
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// RebaseAction is what an interactive rebase does with a commit.
+type RebaseAction string
+
+const (
+	RebasePick   RebaseAction = "pick"
+	RebaseSquash RebaseAction = "squash"
+	RebaseFixup  RebaseAction = "fixup"
+	RebaseDrop   RebaseAction = "drop"
+)
+
+// RebaseCommit is one line of an interactive rebase plan.
+type RebaseCommit struct {
+	SHA     string
+	Subject string
+	Action  RebaseAction
+}
+
+// BranchCommits returns the commits made on the branch since its base commit,
+// oldest first, each planned as a pick.
+func (g *GitWorktree) BranchCommits() ([]RebaseCommit, error) {
+	if g.baseCommitSHA == "" {
+		return nil, fmt.Errorf("base commit SHA not set")
+	}
+
+	output, err := g.runGitCommand(g.worktreePath, "log", "--reverse", "--format=%H%x00%s", g.baseCommitSHA+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branch commits: %w", err)
+	}
+
+	var commits []RebaseCommit
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		sha, subject, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		commits = append(commits, RebaseCommit{SHA: sha, Subject: subject, Action: RebasePick})
+	}
+	return commits, nil
+}
+
+// ValidateRebasePlan checks that the plan can be applied: squash and fixup need
+// an earlier commit that is kept to fold into.
+func ValidateRebasePlan(commits []RebaseCommit) error {
+	kept := false
+	for _, c := range commits {
+		switch c.Action {
+		case RebasePick:
+			kept = true
+		case RebaseSquash, RebaseFixup:
+			if !kept {
+				return fmt.Errorf("cannot %s %.7s: there is no earlier commit to fold it into", c.Action, c.SHA)
+			}
+		case RebaseDrop:
+		default:
+			return fmt.Errorf("unknown rebase action %q", c.Action)
+		}
+	}
+	return nil
+}
+
+// rebaseTodo renders the plan as a git-rebase-todo file.
+func rebaseTodo(commits []RebaseCommit) string {
+	var b strings.Builder
+	for _, c := range commits {
+		fmt.Fprintf(&b, "%s %s %s\n", c.Action, c.SHA, c.Subject)
+	}
+	return b.String()
+}
+
+// Rebase rewrites the branch according to the plan by running an interactive
+// rebase onto the base commit with a generated todo file. Squashed commit
+// messages are combined without opening an editor.
+func (g *GitWorktree) Rebase(commits []RebaseCommit) error {
+	if err := ValidateRebasePlan(commits); err != nil {
+		return err
+	}
+	if dirty, err := g.IsDirty(); err != nil {
+		return err
+	} else if dirty {
+		return fmt.Errorf("worktree has uncommitted changes, commit them before rebasing")
+	}
+
+	todoFile, err := os.CreateTemp("", "claudesquad-rebase-todo-*")
+	if err != nil {
+		return fmt.Errorf("failed to create rebase todo: %w", err)
+	}
+	defer os.Remove(todoFile.Name())
+	if _, err := todoFile.WriteString(rebaseTodo(commits)); err != nil {
+		todoFile.Close()
+		return fmt.Errorf("failed to write rebase todo: %w", err)
+	}
+	todoFile.Close()
+
+	// git invokes the sequence editor with the path of its todo file; replace it with ours
+	return g.runInteractiveRebase(g.baseCommitSHA, fmt.Sprintf("cp %s", shellQuote(filepath.ToSlash(todoFile.Name()))))
+}
+
+// Autosquash folds the branch's "fixup!" and "squash!" commits into their targets.
+// Only the commits that haven't been pushed to a remote are rewritten, so the
+// branch can still be pushed without forcing: fixups of pushed commits are kept
+// as they are. It does nothing if there are none to fold.
+func (g *GitWorktree) Autosquash() error {
+	commits, err := g.unpushedCommits()
+	if err != nil {
+		return err
+	}
+	needed := false
+	for _, c := range commits {
+		if strings.HasPrefix(c.Subject, "fixup! ") || strings.HasPrefix(c.Subject, "squash! ") {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil
+	}
+
+	// Accept the todo list that --autosquash generates as is
+	return g.runInteractiveRebase(commits[0].SHA+"^", "true", "--autosquash")
+}
+
+// unpushedCommits returns the commits made on the branch since its base commit
+// that no remote branch contains, oldest first.
+func (g *GitWorktree) unpushedCommits() ([]RebaseCommit, error) {
+	if g.baseCommitSHA == "" {
+		return nil, fmt.Errorf("base commit SHA not set")
+	}
+
+	output, err := g.runGitCommand(g.worktreePath, "log", "--reverse", "--format=%H%x00%s", g.baseCommitSHA+"..HEAD", "--not", "--remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unpushed commits: %w", err)
+	}
+
+	var commits []RebaseCommit
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		sha, subject, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		commits = append(commits, RebaseCommit{SHA: sha, Subject: subject, Action: RebasePick})
+	}
+	return commits, nil
+}
+
+// runInteractiveRebase runs an interactive rebase onto the given commit using the
+// given sequence editor command. Commit message editors are skipped. If the
+// rebase stops (e.g. on a conflict) it is aborted and the branch is left unchanged.
+func (g *GitWorktree) runInteractiveRebase(onto, sequenceEditor string, args ...string) error {
+	rebaseArgs := append([]string{"-C", g.worktreePath, "rebase", "--interactive"}, args...)
+	cmd := exec.Command("git", append(rebaseArgs, onto)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_SEQUENCE_EDITOR="+sequenceEditor,
+		"GIT_EDITOR=true",
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if _, abortErr := g.runGitCommand(g.worktreePath, "rebase", "--abort"); abortErr != nil {
+			log.ErrorLog.Printf("failed to abort rebase of %s: %v", g.branchName, abortErr)
+		}
+		return fmt.Errorf("rebase failed and was aborted: %s (%w)", strings.TrimSpace(string(output)), err)
+	}
+
+	g.InvalidateDiffCache()
+	return nil
+}
+
+// shellQuote quotes a string for use as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
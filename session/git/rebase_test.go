@@ -0,0 +1,154 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateRebasePlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []RebaseAction
+		wantErr bool
+	}{
+		{name: "all picks", actions: []RebaseAction{RebasePick, RebasePick}},
+		{name: "squash into previous", actions: []RebaseAction{RebasePick, RebaseSquash, RebaseFixup}},
+		{name: "squash first commit", actions: []RebaseAction{RebaseSquash, RebasePick}, wantErr: true},
+		{name: "fixup after drop only", actions: []RebaseAction{RebaseDrop, RebaseFixup}, wantErr: true},
+		{name: "unknown action", actions: []RebaseAction{"edit"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var commits []RebaseCommit
+			for _, a := range tt.actions {
+				commits = append(commits, RebaseCommit{SHA: "0123456789", Action: a})
+			}
+			err := ValidateRebasePlan(commits)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRebasePlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRebase(t *testing.T) {
+	g := setupTestWorktree(t)
+	base, err := runGit(g.worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.baseCommitSHA = strings.TrimSpace(base)
+
+	for _, name := range []string{"one", "two", "three"} {
+		if err := os.WriteFile(filepath.Join(g.worktreePath, name+".txt"), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runGit(g.worktreePath, "add", "."); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runGit(g.worktreePath, "commit", "-q", "-m", "add "+name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commits, err := g.BranchCommits()
+	if err != nil || len(commits) != 3 {
+		t.Fatalf("BranchCommits() = %v, %v; want 3 commits", commits, err)
+	}
+	if commits[0].Subject != "add one" || commits[2].Subject != "add three" {
+		t.Fatalf("BranchCommits() should list oldest first, got %+v", commits)
+	}
+
+	// Move "three" first and fold "two" into "one"
+	commits[0], commits[1], commits[2] = commits[2], commits[0], commits[1]
+	commits[2].Action = RebaseFixup
+	if err := g.Rebase(commits); err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+
+	after, err := g.BranchCommits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subjects []string
+	for _, c := range after {
+		subjects = append(subjects, c.Subject)
+	}
+	if strings.Join(subjects, ",") != "add three,add one" {
+		t.Errorf("commits after rebase = %v, want [add three, add one]", subjects)
+	}
+	if _, err := os.Stat(filepath.Join(g.worktreePath, "two.txt")); err != nil {
+		t.Errorf("fixup commit content should be kept: %v", err)
+	}
+
+	// Dropping a commit removes its changes
+	after[0].Action = RebaseDrop
+	if err := g.Rebase(after); err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(g.worktreePath, "three.txt")); !os.IsNotExist(err) {
+		t.Errorf("dropped commit content should be gone, stat error = %v", err)
+	}
+}
+
+func TestAutosquashKeepsPushedCommits(t *testing.T) {
+	g := setupTestWorktree(t)
+	base, err := runGit(g.worktreePath, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.baseCommitSHA = strings.TrimSpace(base)
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if _, err := runGit(g.repoPath, "init", "-q", "--bare", remote); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(g.repoPath, "remote", "add", "origin", remote); err != nil {
+		t.Fatal(err)
+	}
+
+	commitFile(t, g.worktreePath, "a.txt", "a\n")
+	if _, err := runGit(g.worktreePath, "push", "-q", "origin", "test/branch"); err != nil {
+		t.Fatal(err)
+	}
+	pushed, err := g.BranchCommits()
+	if err != nil || len(pushed) != 1 {
+		t.Fatalf("BranchCommits() = %v, %v; want 1 commit", pushed, err)
+	}
+
+	fixup := func(target string) {
+		t.Helper()
+		if _, err := runGit(g.worktreePath, "commit", "-q", "--allow-empty", "--fixup="+target); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fixup(pushed[0].SHA)
+	commitFile(t, g.worktreePath, "b.txt", "b\n")
+	unpushed, err := g.BranchCommits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixup(unpushed[2].SHA)
+
+	if err := g.Autosquash(); err != nil {
+		t.Fatalf("Autosquash() error = %v", err)
+	}
+
+	after, err := g.BranchCommits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subjects []string
+	for _, c := range after {
+		subjects = append(subjects, c.Subject)
+	}
+	if want := "change a.txt,fixup! change a.txt,change b.txt"; strings.Join(subjects, ",") != want {
+		t.Errorf("commits after autosquash = %v, want %s", subjects, want)
+	}
+	if after[0].SHA != pushed[0].SHA || after[1].SHA != unpushed[1].SHA {
+		t.Errorf("pushed commits were rewritten: %+v", after)
+	}
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"claude-squad/config"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// getSharedCacheDirectory returns the directory the shared caches of a
+// repository are kept in: the configured root if set, otherwise a directory of
+// the repository in the caches directory next to the config.
+func getSharedCacheDirectory(repoPath, root string) (string, error) {
+	if root != "" {
+		return resolveWorktreeRoot(root, repoPath)
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "caches", repoDirName(repoPath)), nil
+}
+
+// cleanSharedCachePath returns the configured cache path with forward slashes,
+// or an error if it could point outside of the worktree or into .git.
+func cleanSharedCachePath(cache string) (string, error) {
+	if filepath.IsAbs(cache) || strings.HasPrefix(cache, "/") {
+		return "", fmt.Errorf("shared cache %s must be relative to the repository", cache)
+	}
+	clean := path.Clean(filepath.ToSlash(cache))
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("shared cache %s must be inside the repository", cache)
+	}
+	if clean == ".git" || strings.HasPrefix(clean, ".git/") {
+		return "", fmt.Errorf("shared cache %s must not be in .git", cache)
+	}
+	return clean, nil
+}
+
+// SharedCacheDirs returns the shared directories that the caches of new
+// worktrees of the repository link to, so that containers can mount them at
+// the same path and the links resolve.
+func (g *GitWorktree) SharedCacheDirs() []string {
+	repoConfig := loadRepoConfig(g.repoPath)
+	if len(repoConfig.SharedCaches) == 0 || g.noSharedCaches || config.LoadConfig().NoSharedCaches {
+		return nil
+	}
+	dir, err := getSharedCacheDirectory(g.repoPath, repoConfig.SharedCacheRoot)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, cache := range repoConfig.SharedCaches {
+		if clean, err := cleanSharedCachePath(cache); err == nil {
+			dirs = append(dirs, filepath.Join(dir, filepath.FromSlash(clean)))
+		}
+	}
+	return dirs
+}
+
+// linkSharedCaches replaces the cache directories of the new worktree with
+// symlinks to the shared caches of the repository, so that dependencies and
+// build outputs are reused instead of rebuilt in every worktree. Only paths
+// ignored by git are linked, so the caches never show up in the diff, and
+// paths the worktree already has are left alone.
+func (g *GitWorktree) linkSharedCaches() error {
+	if g.noSharedCaches || len(g.sharedCaches) == 0 || isBareRepo(g.repoPath) {
+		return nil
+	}
+	dir, err := getSharedCacheDirectory(g.repoPath, g.sharedCacheRoot)
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(g.worktreePath, dir); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("shared cache directory %s must not be inside the worktree", dir)
+	}
+	g.reportProgress(fmt.Sprintf("Linking %d shared cache(s)...", len(g.sharedCaches)))
+
+	var errs []error
+	for _, cache := range g.sharedCaches {
+		clean, err := cleanSharedCachePath(cache)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		dst := filepath.Join(g.worktreePath, filepath.FromSlash(clean))
+		if _, err := os.Lstat(dst); err == nil {
+			continue
+		}
+		// The exit code is 1 for paths that are not ignored. The trailing
+		// slash matches patterns that only apply to directories.
+		if _, err := g.runGitCommand(g.worktreePath, "check-ignore", "-q", "--no-index", clean+"/"); err != nil {
+			errs = append(errs, fmt.Errorf("shared cache %s is not ignored by git, linking it would add it to the diff", cache))
+			continue
+		}
+		shared := filepath.Join(dir, filepath.FromSlash(clean))
+		if err := os.MkdirAll(shared, 0755); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create shared cache %s: %w", shared, err))
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create directory for %s: %w", cache, err))
+			continue
+		}
+		if err := os.Symlink(shared, dst); err != nil {
+			errs = append(errs, fmt.Errorf("failed to link shared cache %s: %w", cache, err))
+		}
+	}
+	return g.combineErrors(errs)
+}
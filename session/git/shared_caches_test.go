@@ -0,0 +1,70 @@
+package git
+
+import (
+	"claude-squad/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetupLinksSharedCaches(t *testing.T) {
+	g := setupTestWorktree(t)
+	commitFile(t, g.repoPath, ".gitignore", "node_modules/\n")
+	caches := t.TempDir()
+	repoConfig := "shared_caches: [node_modules, src]\nshared_cache_root: " + caches + "\n"
+	if err := os.WriteFile(filepath.Join(g.repoPath, config.RepoConfigFileName), []byte(repoConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var worktrees []*GitWorktree
+	for _, name := range []string{"first", "second"} {
+		worktree := NewGitWorktreeFromStorage(g.repoPath, filepath.Join(t.TempDir(), name), name, "test/"+name, "")
+		if err := worktree.Setup(); err != nil {
+			t.Fatalf("Setup() error = %v", err)
+		}
+		defer worktree.Cleanup()
+		worktrees = append(worktrees, worktree)
+	}
+
+	// A dependency installed in one worktree is there in the other
+	if err := os.WriteFile(filepath.Join(worktrees[0].worktreePath, "node_modules", "dep.js"), []byte("dep\n"), 0644); err != nil {
+		t.Fatalf("node_modules isn't usable: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktrees[1].worktreePath, "node_modules", "dep.js")); err != nil {
+		t.Errorf("node_modules isn't shared: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(caches, "node_modules", "dep.js")); err != nil {
+		t.Errorf("the shared cache isn't in the cache root: %v", err)
+	}
+
+	// Paths that are not ignored are not linked, so they stay out of the diff
+	if _, err := os.Lstat(filepath.Join(worktrees[0].worktreePath, "src")); !os.IsNotExist(err) {
+		t.Errorf("src was linked although git doesn't ignore it")
+	}
+	for _, file := range worktrees[0].Diff().ChangedFiles() {
+		if strings.HasPrefix(file.Path, "node_modules") {
+			t.Errorf("the shared cache is part of the diff: %s", file.Path)
+		}
+	}
+	if dirs := worktrees[0].SharedCacheDirs(); len(dirs) != 2 || dirs[0] != filepath.Join(caches, "node_modules") {
+		t.Errorf("SharedCacheDirs() = %v", dirs)
+	}
+}
+
+func TestCleanSharedCachePath(t *testing.T) {
+	for cache, want := range map[string]string{
+		"node_modules":       "node_modules",
+		"./web/node_modules": "web/node_modules",
+		"target/":            "target",
+	} {
+		if got, err := cleanSharedCachePath(cache); err != nil || got != want {
+			t.Errorf("cleanSharedCachePath(%q) = %q, %v, want %q", cache, got, err, want)
+		}
+	}
+	for _, cache := range []string{"", ".", "..", "../cache", "a/../../b", "/tmp/cache", ".git", ".git/hooks"} {
+		if _, err := cleanSharedCachePath(cache); err == nil {
+			t.Errorf("cleanSharedCachePath(%q) should fail", cache)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// initSubmodules checks out the submodules of the worktree, recursively, at
+// the commits the branch points them to. It does nothing if the worktree has
+// no submodules.
+func (g *GitWorktree) initSubmodules() error {
+	if _, err := os.Stat(filepath.Join(g.worktreePath, ".gitmodules")); err != nil {
+		return nil
+	}
+	g.reportProgress("Initializing submodules...")
+	if _, err := g.runGitCommand(g.worktreePath, "submodule", "update", "--init", "--recursive"); err != nil {
+		return fmt.Errorf("failed to initialize submodules: %w", err)
+	}
+	return nil
+}
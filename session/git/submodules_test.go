@@ -0,0 +1,61 @@
+package git
+
+import (
+	"claude-squad/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetupInitializesSubmodules(t *testing.T) {
+	g := setupTestWorktree(t)
+	// Submodules are cloned from a local path in the test
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	lib := filepath.Join(t.TempDir(), "lib")
+	if _, err := runGit(t.TempDir(), "init", "-q", lib); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, lib, "lib.txt", "lib\n")
+	for _, args := range [][]string{
+		{"submodule", "add", "-q", lib, "lib"},
+		{"commit", "-q", "-m", "add lib"},
+	} {
+		if _, err := runGit(g.repoPath, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(g.repoPath, config.RepoConfigFileName), []byte("submodules: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	worktree := NewGitWorktreeFromStorage(g.repoPath, filepath.Join(t.TempDir(), "instance"), "instance", "test/instance", "")
+	if err := worktree.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer worktree.Cleanup()
+	if _, err := os.Stat(filepath.Join(worktree.worktreePath, "lib", "lib.txt")); err != nil {
+		t.Fatalf("the submodule wasn't initialized: %v", err)
+	}
+
+	// Changes to the files of the submodule are part of the diff
+	if err := os.WriteFile(filepath.Join(worktree.worktreePath, "lib", "lib.txt"), []byte("lib\nchanged\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stats := worktree.Diff()
+	if stats.Error != nil {
+		t.Fatalf("Diff() error = %v", stats.Error)
+	}
+	var found bool
+	for _, file := range stats.ChangedFiles() {
+		if file.Path == "lib/lib.txt" {
+			found = strings.Contains(file.Diff, "+changed")
+		}
+	}
+	if !found {
+		t.Errorf("Diff() is missing the change to lib/lib.txt:\n%s", stats.Content)
+	}
+}
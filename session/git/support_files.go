@@ -0,0 +1,185 @@
+package git
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SupportFile is an ignored or untracked file of the main checkout that new
+// worktrees get, like .env or local certificates.
+type SupportFile struct {
+	// Path is relative to the root of the checkout, with forward slashes
+	Path string
+	// Link is true if worktrees get a symlink to the file rather than a copy
+	Link bool
+}
+
+// ListSupportFiles returns the main repository of the directory and the files
+// that the copy_files and link_files patterns of its repository config match,
+// without copying anything.
+func ListSupportFiles(dir string) (string, []SupportFile, error) {
+	absPath, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	repoPath, err := findGitRepoRoot(absPath)
+	if err != nil {
+		return "", nil, err
+	}
+	repoConfig := loadRepoConfig(repoPath)
+	files, err := findSupportFiles(repoPath, repoConfig.CopyFiles, repoConfig.LinkFiles)
+	return repoPath, files, err
+}
+
+// findSupportFiles matches the glob patterns, relative to the root of the
+// checkout, against its files and directories. Tracked files are left out,
+// since worktrees check them out anyway. Link patterns win over copy patterns.
+func findSupportFiles(repoPath string, copyPatterns, linkPatterns []string) ([]SupportFile, error) {
+	if len(copyPatterns)+len(linkPatterns) == 0 || isBareRepo(repoPath) {
+		return nil, nil
+	}
+
+	byPath := make(map[string]SupportFile)
+	for _, group := range []struct {
+		patterns []string
+		link     bool
+	}{{copyPatterns, false}, {linkPatterns, true}} {
+		for _, pattern := range group.patterns {
+			matches, err := filepath.Glob(filepath.Join(repoPath, filepath.FromSlash(pattern)))
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			for _, match := range matches {
+				rel, err := filepath.Rel(repoPath, match)
+				if err != nil {
+					continue
+				}
+				rel = filepath.ToSlash(rel)
+				if rel == ".git" || strings.HasPrefix(rel, ".git/") || strings.HasPrefix(rel, "../") {
+					continue
+				}
+				byPath[rel] = SupportFile{Path: rel, Link: group.link}
+			}
+		}
+	}
+	if len(byPath) == 0 {
+		return nil, nil
+	}
+
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	tracked, err := runGit(repoPath, append([]string{"ls-files", "-z", "--"}, paths...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked files: %w", err)
+	}
+	trackedPaths := strings.Split(tracked, "\x00")
+
+	var files []SupportFile
+	for _, path := range paths {
+		if !containsTracked(trackedPaths, path) {
+			files = append(files, byPath[path])
+		}
+	}
+	return files, nil
+}
+
+// containsTracked returns true if the path, or any path under it, is tracked.
+func containsTracked(tracked []string, path string) bool {
+	for _, trackedPath := range tracked {
+		if trackedPath == path || strings.HasPrefix(trackedPath, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// copySupportFiles copies or links the support files of the main checkout into
+// the worktree. Files the worktree already has are left alone.
+func (g *GitWorktree) copySupportFiles() error {
+	files, err := findSupportFiles(g.repoPath, g.copyFiles, g.linkFiles)
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	g.reportProgress(fmt.Sprintf("Copying %d support file(s)...", len(files)))
+
+	var errs []error
+	for _, file := range files {
+		src := filepath.Join(g.repoPath, filepath.FromSlash(file.Path))
+		dst := filepath.Join(g.worktreePath, filepath.FromSlash(file.Path))
+		if _, err := os.Lstat(dst); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create directory for %s: %w", file.Path, err))
+			continue
+		}
+		if file.Link {
+			err = os.Symlink(src, dst)
+		} else {
+			err = copyTree(src, dst)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to copy %s: %w", file.Path, err))
+		}
+	}
+	return g.combineErrors(errs)
+}
+
+// copyTree copies the file or directory at src to dst, keeping file modes and
+// symlinks.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyRegularFile(path, target, info.Mode().Perm())
+		}
+		// Sockets, pipes and devices have nothing to copy
+		return nil
+	})
+}
+
+// copyRegularFile copies the content of the file at src to a new file at dst.
+func copyRegularFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
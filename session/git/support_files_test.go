@@ -0,0 +1,62 @@
+package git
+
+import (
+	"claude-squad/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetupCopiesSupportFiles(t *testing.T) {
+	g := setupTestWorktree(t)
+	for path, content := range map[string]string{
+		".gitignore":         ".env\ncerts/\nnode_modules/\n",
+		".env":               "API_KEY=secret\n",
+		"certs/local.pem":    "certificate\n",
+		"node_modules/x.js":  "module\n",
+		"config/tracked.yml": "tracked\n",
+	} {
+		path = filepath.Join(g.repoPath, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"add", ".gitignore", "config"}, {"commit", "-q", "-m", "tracked files"}} {
+		if _, err := runGit(g.repoPath, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	repoConfig := "copy_files: [.env, certs, config/*, missing.txt]\nlink_files: [node_modules]\n"
+	if err := os.WriteFile(filepath.Join(g.repoPath, config.RepoConfigFileName), []byte(repoConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tracked config file and the missing one are left out
+	_, files, err := ListSupportFiles(g.repoPath)
+	if err != nil {
+		t.Fatalf("ListSupportFiles() error = %v", err)
+	}
+	want := []SupportFile{{Path: ".env"}, {Path: "certs"}, {Path: "node_modules", Link: true}}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ListSupportFiles() = %+v, want %+v", files, want)
+	}
+
+	worktree := NewGitWorktreeFromStorage(g.repoPath, filepath.Join(t.TempDir(), "instance"), "instance", "test/instance", "")
+	if err := worktree.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer worktree.Cleanup()
+	if content, err := os.ReadFile(filepath.Join(worktree.worktreePath, "certs", "local.pem")); err != nil || string(content) != "certificate\n" {
+		t.Errorf("certs/local.pem = %q, %v, want a copy", content, err)
+	}
+	if info, err := os.Lstat(filepath.Join(worktree.worktreePath, ".env")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf(".env should be copied, got %v", err)
+	}
+	if info, err := os.Lstat(filepath.Join(worktree.worktreePath, "node_modules")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("node_modules should be a symlink, got %v", err)
+	}
+}
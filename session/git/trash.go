@@ -0,0 +1,220 @@
+package git
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	trashEntryFile  = "entry.json"
+	trashBundleFile = "branch.bundle"
+)
+
+// TrashEntry describes the branch of a killed instance that was kept in the trash.
+type TrashEntry struct {
+	Title      string    `json:"title"`
+	RepoPath   string    `json:"repo_path"`
+	BranchName string    `json:"branch_name"`
+	CommitSHA  string    `json:"commit_sha"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	Owner      string    `json:"owner,omitempty"`
+
+	// Dir is the trash directory holding this entry.
+	Dir string `json:"-"`
+}
+
+func getTrashDirectory() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "trash"), nil
+}
+
+// MoveToTrash commits all uncommitted and untracked (but not ignored) work onto the
+// branch, saves a bundle of the branch in the trash directory and removes the
+// worktree. The branch itself is kept until the trash entry is purged.
+func (g *GitWorktree) MoveToTrash(title, owner string) (*TrashEntry, error) {
+	if _, err := os.Stat(g.worktreePath); err == nil {
+		if dirty, err := g.IsDirty(); err != nil {
+			return nil, err
+		} else if dirty {
+			if _, err := g.runGitCommand(g.worktreePath, "add", "-A"); err != nil {
+				return nil, fmt.Errorf("failed to stage changes: %w", err)
+			}
+			commitMsg := fmt.Sprintf("[claudesquad] snapshot of '%s' before kill", title)
+			if _, err := g.runGitCommand(g.worktreePath, "commit", "-m", commitMsg, "--no-verify"); err != nil {
+				return nil, fmt.Errorf("failed to commit changes: %w", err)
+			}
+		}
+	}
+
+	sha, err := g.runGitCommand(g.repoPath, "rev-parse", "--verify", g.branchName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve branch %s: %w", g.branchName, err)
+	}
+
+	trashDir, err := getTrashDirectory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash directory: %w", err)
+	}
+
+	deletedAt := time.Now()
+	entry := &TrashEntry{
+		Title:      title,
+		RepoPath:   g.repoPath,
+		BranchName: g.branchName,
+		CommitSHA:  strings.TrimSpace(sha),
+		DeletedAt:  deletedAt,
+		Owner:      owner,
+		Dir:        filepath.Join(trashDir, fmt.Sprintf("%s_%d", strings.ReplaceAll(g.branchName, "/", "_"), deletedAt.Unix())),
+	}
+	if err := os.MkdirAll(entry.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trash entry: %w", err)
+	}
+
+	if _, err := g.runGitCommand(g.repoPath, "bundle", "create", filepath.Join(entry.Dir, trashBundleFile), g.branchName); err != nil {
+		os.RemoveAll(entry.Dir)
+		return nil, fmt.Errorf("failed to bundle branch %s: %w", g.branchName, err)
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal trash entry: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(entry.Dir, trashEntryFile), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write trash entry: %w", err)
+	}
+
+	// Remove the worktree but keep the branch
+	if _, err := os.Stat(g.worktreePath); err == nil {
+		if err := g.Remove(); err != nil {
+			return entry, err
+		}
+	}
+	if err := g.Prune(); err != nil {
+		return entry, err
+	}
+
+	log.InfoLog.Printf("moved branch %s of %s to trash at %s", g.branchName, title, entry.Dir)
+	return entry, nil
+}
+
+// ListTrash returns all trash entries, most recently deleted first.
+func ListTrash() ([]*TrashEntry, error) {
+	trashDir, err := getTrashDirectory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash directory: %w", err)
+	}
+
+	dirEntries, err := os.ReadDir(trashDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read trash directory: %w", err)
+	}
+
+	var entries []*TrashEntry
+	for _, de := range dirEntries {
+		if !de.IsDir() {
+			continue
+		}
+		dir := filepath.Join(trashDir, de.Name())
+		data, err := os.ReadFile(filepath.Join(dir, trashEntryFile))
+		if err != nil {
+			log.WarningLog.Printf("skipping trash entry %s: %v", dir, err)
+			continue
+		}
+		var entry TrashEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			log.WarningLog.Printf("skipping trash entry %s: %v", dir, err)
+			continue
+		}
+		entry.Dir = dir
+		entries = append(entries, &entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// Restore makes sure the trashed branch exists in its repository again, recreating
+// it from the bundle if it was deleted, and removes the entry from the trash.
+func (e *TrashEntry) Restore() error {
+	if _, err := runGit(e.RepoPath, "rev-parse", "--verify", "refs/heads/"+e.BranchName); err != nil {
+		bundle := filepath.Join(e.Dir, trashBundleFile)
+		refspec := fmt.Sprintf("refs/heads/%s:refs/heads/%s", e.BranchName, e.BranchName)
+		if _, err := runGit(e.RepoPath, "fetch", bundle, refspec); err != nil {
+			return fmt.Errorf("failed to restore branch %s from bundle: %w", e.BranchName, err)
+		}
+	}
+
+	if err := os.RemoveAll(e.Dir); err != nil {
+		return fmt.Errorf("failed to remove trash entry: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes the trash entry and its branch, unless the branch has moved on
+// since it was trashed.
+func (e *TrashEntry) Purge() error {
+	if sha, err := runGit(e.RepoPath, "rev-parse", "--verify", "refs/heads/"+e.BranchName); err == nil {
+		if strings.TrimSpace(sha) == e.CommitSHA {
+			if _, err := runGit(e.RepoPath, "branch", "-D", e.BranchName); err != nil {
+				return fmt.Errorf("failed to delete branch %s: %w", e.BranchName, err)
+			}
+		} else {
+			log.InfoLog.Printf("keeping branch %s: it has new commits since it was trashed", e.BranchName)
+		}
+	}
+
+	if err := os.RemoveAll(e.Dir); err != nil {
+		return fmt.Errorf("failed to remove trash entry: %w", err)
+	}
+	return nil
+}
+
+// Expired returns true if the entry is older than the retention period.
+func (e *TrashEntry) Expired(retentionDays int) bool {
+	return time.Since(e.DeletedAt) > time.Duration(retentionDays)*24*time.Hour
+}
+
+// PurgeExpiredTrash purges all trash entries older than the retention period and
+// returns how many were purged. If owner is set, only that owner's entries are
+// purged.
+func PurgeExpiredTrash(retentionDays int, owner string) (int, error) {
+	entries, err := ListTrash()
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	var errs []error
+	for _, entry := range entries {
+		if !entry.Expired(retentionDays) || (owner != "" && entry.Owner != owner) {
+			continue
+		}
+		if err := entry.Purge(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		purged++
+	}
+
+	if len(errs) > 0 {
+		return purged, errors.Join(errs...)
+	}
+	return purged, nil
+}
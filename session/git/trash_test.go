@@ -0,0 +1,99 @@
+package git
+
+import (
+	"claude-squad/log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestMain runs before all tests to set up the test environment
+func TestMain(m *testing.M) {
+	log.Initialize(false)
+	defer log.Close()
+
+	exitCode := m.Run()
+	os.Exit(exitCode)
+}
+
+// setupTestWorktree creates a repository with one commit and a worktree on a new branch.
+func setupTestWorktree(t *testing.T) *GitWorktree {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repoPath := t.TempDir()
+	worktreePath := filepath.Join(t.TempDir(), "worktree")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+		{"worktree", "add", "-q", "-b", "test/branch", worktreePath},
+	} {
+		if _, err := runGit(repoPath, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	return NewGitWorktreeFromStorage(repoPath, worktreePath, "session", "test/branch", "")
+}
+
+func TestMoveToTrashRestoreAndPurge(t *testing.T) {
+	g := setupTestWorktree(t)
+
+	// Untracked work should survive the kill
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "notes.txt"), []byte("agent work"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := g.MoveToTrash("my-session", "")
+	if err != nil {
+		t.Fatalf("MoveToTrash() error = %v", err)
+	}
+	if _, err := os.Stat(g.worktreePath); !os.IsNotExist(err) {
+		t.Errorf("worktree should have been removed, stat error = %v", err)
+	}
+
+	entries, err := ListTrash()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListTrash() = %v, %v; want 1 entry", entries, err)
+	}
+	if entries[0].Title != "my-session" || entries[0].BranchName != "test/branch" {
+		t.Errorf("unexpected trash entry: %+v", entries[0])
+	}
+
+	// Delete the branch, then restore it from the bundle
+	if _, err := runGit(g.repoPath, "branch", "-D", "test/branch"); err != nil {
+		t.Fatal(err)
+	}
+	if err := entry.Restore(); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if _, err := runGit(g.repoPath, "cat-file", "-e", "test/branch:notes.txt"); err != nil {
+		t.Errorf("restored branch should contain the untracked file: %v", err)
+	}
+
+	// Trash it again as someone else's and purge everything
+	g = NewGitWorktreeFromStorage(g.repoPath, g.worktreePath, "session", "test/branch", "")
+	if _, err := g.MoveToTrash("my-session", "alice"); err != nil {
+		t.Fatalf("MoveToTrash() error = %v", err)
+	}
+	purged, err := PurgeExpiredTrash(0, "bob")
+	if err != nil || purged != 0 {
+		t.Fatalf("PurgeExpiredTrash(0, bob) = %d, %v; want 0", purged, err)
+	}
+	purged, err = PurgeExpiredTrash(0, "alice")
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeExpiredTrash(0, alice) = %d, %v; want 1", purged, err)
+	}
+	if _, err := runGit(g.repoPath, "rev-parse", "--verify", "refs/heads/test/branch"); err == nil {
+		t.Errorf("branch should have been deleted by purge")
+	}
+}
@@ -0,0 +1,218 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// UntrackedEntry describes an untracked or ignored path in the worktree that
+// would be lost when the worktree is removed.
+type UntrackedEntry struct {
+	// Path is relative to the worktree root. Directories end with a slash.
+	Path string
+	// Size is the total size in bytes of the file or directory contents.
+	Size int64
+	// Ignored is true if the path is matched by .gitignore.
+	Ignored bool
+}
+
+// UntrackedFiles lists the untracked and ignored paths in the worktree, largest
+// first. Whole untracked directories (e.g. node_modules/) are reported as a
+// single entry rather than file by file.
+func (g *GitWorktree) UntrackedFiles() ([]UntrackedEntry, error) {
+	if _, err := os.Stat(g.worktreePath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to stat worktree: %w", err)
+	}
+
+	output, err := g.runGitCommand(g.worktreePath, "status", "--porcelain", "-z", "--ignored", "--untracked-files=normal")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+
+	entries := parseUntrackedStatus(output)
+	for i := range entries {
+		entries[i].Size = pathSize(filepath.Join(g.worktreePath, entries[i].Path))
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Size > entries[j].Size
+	})
+	return entries, nil
+}
+
+// RescueUntracked moves the untracked entries matching any of the given glob
+// patterns back into the main repository checkout at the same relative path.
+// Entries whose destination already exists are left alone. Returns the paths
+// that were moved.
+func (g *GitWorktree) RescueUntracked(entries []UntrackedEntry, patterns []string) ([]string, error) {
+	if len(entries) > 0 && isBareRepo(g.repoPath) {
+		return nil, fmt.Errorf("%s is a bare repository, it has no checkout to move untracked files to", g.repoPath)
+	}
+	var moved []string
+	var errs []error
+
+	for _, entry := range entries {
+		if !MatchesAnyPattern(entry.Path, patterns) {
+			continue
+		}
+
+		rel := strings.TrimSuffix(entry.Path, "/")
+		src := filepath.Join(g.worktreePath, rel)
+		dst := filepath.Join(g.repoPath, rel)
+
+		if _, err := os.Stat(dst); err == nil {
+			errs = append(errs, fmt.Errorf("not moving %s: already exists in %s", rel, g.repoPath))
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create directory for %s: %w", rel, err))
+			continue
+		}
+		if err := movePath(src, dst); err != nil {
+			errs = append(errs, fmt.Errorf("failed to move %s: %w", rel, err))
+			continue
+		}
+		moved = append(moved, rel)
+	}
+
+	if len(errs) > 0 {
+		return moved, g.combineErrors(errs)
+	}
+	return moved, nil
+}
+
+// movePath moves the file or directory at src to dst. Worktrees can live on
+// another filesystem than the repository, where renaming fails, so the path is
+// copied and then removed instead.
+func movePath(src, dst string) error {
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyTree(src, dst); err != nil {
+		_ = os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// MatchesAnyPattern reports whether the path (or its base name) matches any of
+// the glob patterns. A trailing slash on directory paths is ignored.
+func MatchesAnyPattern(path string, patterns []string) bool {
+	path = strings.TrimSuffix(path, "/")
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(path)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatSize renders a byte count in a short human-readable form.
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// parseUntrackedStatus extracts untracked ("??") and ignored ("!!") entries
+// from `git status --porcelain -z --ignored` output. With -z, entries are
+// separated by NUL and paths are not quoted, so unusual file names come through
+// verbatim. Renames are followed by an extra entry holding the original path.
+func parseUntrackedStatus(output string) []UntrackedEntry {
+	var entries []UntrackedEntry
+	fields := strings.Split(output, "\x00")
+	for i := 0; i < len(fields); i++ {
+		line := fields[i]
+		if len(line) < 4 {
+			continue
+		}
+		code, path := line[:2], line[3:]
+		if code[0] == 'R' || code[0] == 'C' {
+			i++
+			continue
+		}
+		switch code {
+		case "??":
+			entries = append(entries, UntrackedEntry{Path: path})
+		case "!!":
+			entries = append(entries, UntrackedEntry{Path: path, Ignored: true})
+		}
+	}
+	return entries
+}
+
+// pathSize returns the total size of a file or directory tree, ignoring errors.
+func pathSize(path string) int64 {
+	var total int64
+	_ = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// ExcludeFile keeps the file at the root of the worktree out of git status and
+// commits by adding it to the repository's info/exclude, which all of its
+// worktrees share.
+func (g *GitWorktree) ExcludeFile(name string) error {
+	output, err := g.runGitCommand(g.worktreePath, "rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		return fmt.Errorf("failed to find exclude file: %w", err)
+	}
+	excludePath := strings.TrimSpace(output)
+	if !filepath.IsAbs(excludePath) {
+		excludePath = filepath.Join(g.worktreePath, excludePath)
+	}
+
+	pattern := "/" + filepath.ToSlash(name)
+	content, err := os.ReadFile(excludePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read exclude file: %w", err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		pattern = "\n" + pattern
+	}
+	if err := os.MkdirAll(filepath.Dir(excludePath), 0755); err != nil {
+		return fmt.Errorf("failed to create exclude file: %w", err)
+	}
+	f, err := os.OpenFile(excludePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open exclude file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(pattern + "\n"); err != nil {
+		return fmt.Errorf("failed to write exclude file: %w", err)
+	}
+	return nil
+}
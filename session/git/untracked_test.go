@@ -0,0 +1,107 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseUntrackedStatus(t *testing.T) {
+	output := " M README.md\x00?? notes.txt\x00!! node_modules/\x00?? scratch/\x00A  added.go\x00" +
+		"R  new.go\x00?? renamed-from.txt\x00?? \"quoted\" name.txt\x00?? caf\u00e9.md\x00"
+
+	got := parseUntrackedStatus(output)
+	want := []UntrackedEntry{
+		{Path: "notes.txt"},
+		{Path: "node_modules/", Ignored: true},
+		{Path: "scratch/"},
+		{Path: "\"quoted\" name.txt"},
+		{Path: "caf\u00e9.md"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("parseUntrackedStatus() returned %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMatchesAnyPattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		patterns []string
+		expected bool
+	}{
+		{name: "base name glob", path: "docs/plan.md", patterns: []string{"*.md"}, expected: true},
+		{name: "directory with trailing slash", path: "scratch/", patterns: []string{"scratch"}, expected: true},
+		{name: "full path glob", path: "out/report.txt", patterns: []string{"out/*.txt"}, expected: true},
+		{name: "no match", path: "node_modules/", patterns: []string{"*.md", ".env"}, expected: false},
+		{name: "no patterns", path: "notes.txt", patterns: nil, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MatchesAnyPattern(tt.path, tt.patterns)
+			if got != tt.expected {
+				t.Errorf("MatchesAnyPattern(%q, %v) = %v, want %v", tt.path, tt.patterns, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size     int64
+		expected string
+	}{
+		{size: 0, expected: "0 B"},
+		{size: 512, expected: "512 B"},
+		{size: 1536, expected: "1.5 KiB"},
+		{size: 3 * 1024 * 1024, expected: "3.0 MiB"},
+	}
+
+	for _, tt := range tests {
+		got := FormatSize(tt.size)
+		if got != tt.expected {
+			t.Errorf("FormatSize(%d) = %q, want %q", tt.size, got, tt.expected)
+		}
+	}
+}
+
+func TestExcludeFile(t *testing.T) {
+	g := setupTestWorktree(t)
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "CONTEXT.md"), []byte("context"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Excluding twice adds the pattern once
+	for i := 0; i < 2; i++ {
+		if err := g.ExcludeFile("CONTEXT.md"); err != nil {
+			t.Fatalf("ExcludeFile() error = %v", err)
+		}
+	}
+
+	dirty, err := g.IsDirty()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dirty {
+		t.Error("worktree is dirty after excluding the only untracked file")
+	}
+	output, err := runGit(g.repoPath, "rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(g.repoPath, strings.TrimSpace(output)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(content), "/CONTEXT.md\n"); n != 1 {
+		t.Errorf("exclude file has the pattern %d times, want 1:\n%s", n, content)
+	}
+}
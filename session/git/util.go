@@ -1,6 +1,8 @@
 package git
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"os/exec"
 	"path/filepath"
@@ -67,20 +69,52 @@ func IsGitRepo(path string) bool {
 	}
 }
 
+// findGitRepoRoot returns the main repository of the repository containing
+// path: the main checkout for a normal repository, even if path is in one of
+// its linked worktrees, or the git directory for a bare repository. Instance
+// worktrees are created for the main repository, so that they don't depend on
+// the checkout claude-squad happened to be started from.
 func findGitRepoRoot(path string) (string, error) {
-	currentPath := path
-	for {
-		_, err := git.PlainOpen(currentPath)
-		if err == nil {
-			// Found the repository root
-			return currentPath, nil
-		}
+	output, err := runGit(path, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", fmt.Errorf("failed to find Git repository root from path: %s", path)
+	}
+	commonDir := strings.TrimSpace(output)
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(path, commonDir)
+	}
+	commonDir = filepath.Clean(commonDir)
 
-		parent := filepath.Dir(currentPath)
-		if parent == currentPath {
-			// Reached the filesystem root without finding a repository
-			return "", fmt.Errorf("failed to find Git repository root from path: %s", path)
-		}
-		currentPath = parent
+	if isBareRepo(commonDir) {
+		return commonDir, nil
+	}
+	if filepath.Base(commonDir) == ".git" {
+		return filepath.Dir(commonDir), nil
 	}
+	// The git directory was separated from the checkout
+	output, err = runGit(path, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("failed to find Git repository root from path: %s", path)
+	}
+	return filepath.Clean(strings.TrimSpace(output)), nil
+}
+
+// isBareRepo returns true if the repository at path has no checkout of its
+// own, so its branches can only be checked out in linked worktrees.
+func isBareRepo(path string) bool {
+	output, err := runGit(path, "rev-parse", "--is-bare-repository")
+	return err == nil && strings.TrimSpace(output) == "true"
+}
+
+// repoName returns the name of the repository at path, without the .git
+// suffix of bare repositories.
+func repoName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), ".git")
+}
+
+// repoDirName returns a directory name for the repository at path that is
+// unique among repositories with the same name.
+func repoDirName(path string) string {
+	hash := sha1.Sum([]byte(path))
+	return fmt.Sprintf("%s_%s", repoName(path), hex.EncodeToString(hash[:])[:8])
 }
@@ -1,6 +1,9 @@
 package git
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -71,3 +74,73 @@ func TestSanitizeBranchName(t *testing.T) {
 		})
 	}
 }
+
+func TestFindGitRepoRoot(t *testing.T) {
+	g := setupTestWorktree(t)
+	bare := filepath.Join(t.TempDir(), "project.git")
+	bareWorktree := filepath.Join(t.TempDir(), "bare-worktree")
+	if err := os.MkdirAll(filepath.Join(g.repoPath, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(g.repoPath, "clone", "-q", "--bare", g.repoPath, bare); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(bare, "worktree", "add", "-q", "-b", "feature", bareWorktree); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		path string
+		want string
+	}{
+		{"main checkout", g.repoPath, g.repoPath},
+		{"subdirectory", filepath.Join(g.repoPath, "sub"), g.repoPath},
+		{"git directory", filepath.Join(g.repoPath, ".git"), g.repoPath},
+		{"linked worktree", g.worktreePath, g.repoPath},
+		{"bare repository", bare, bare},
+		{"worktree of a bare repository", bareWorktree, bare},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findGitRepoRoot(tt.path)
+			if err != nil {
+				t.Fatalf("findGitRepoRoot() error = %v", err)
+			}
+			gotReal, _ := filepath.EvalSymlinks(got)
+			wantReal, _ := filepath.EvalSymlinks(tt.want)
+			if gotReal != wantReal {
+				t.Errorf("findGitRepoRoot(%s) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if !isBareRepo(bare) || isBareRepo(g.repoPath) {
+		t.Error("isBareRepo() should only be true for the bare repository")
+	}
+	if name := repoName(bare); name != "project" {
+		t.Errorf("repoName() = %q, want the name without .git", name)
+	}
+	if _, err := findGitRepoRoot(t.TempDir()); err == nil {
+		t.Error("findGitRepoRoot() outside of a repository should fail")
+	}
+}
+
+func TestSetupInBareRepo(t *testing.T) {
+	g := setupTestWorktree(t)
+	bare := filepath.Join(t.TempDir(), "project.git")
+	if _, err := runGit(g.repoPath, "clone", "-q", "--bare", g.repoPath, bare); err != nil {
+		t.Fatal(err)
+	}
+
+	worktree := NewGitWorktreeFromStorage(bare, filepath.Join(t.TempDir(), "instance"), "instance", "test/instance", "")
+	if err := worktree.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	defer worktree.Cleanup()
+	if branch, err := runGit(worktree.worktreePath, "branch", "--show-current"); err != nil || strings.TrimSpace(branch) != "test/instance" {
+		t.Errorf("worktree is on %q (%v), want test/instance", branch, err)
+	}
+	if _, err := worktree.CheckoutSnapshot(); err == nil {
+		t.Error("CheckoutSnapshot() should fail without a checkout")
+	}
+}
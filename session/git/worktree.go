@@ -35,6 +35,28 @@ type GitWorktree struct {
 	baseCommitSHA string
 	// Progress callback for status updates
 	progressCallback ProgressCallback
+	// poolSize is how many pre-created worktrees to keep for the repository, 0 disables the pool
+	poolSize int
+	// fetchBeforeCreate fetches and fast-forwards the default branch before a new worktree is created
+	fetchBeforeCreate bool
+	// baseBranch is the branch a new worktree starts from, HEAD of the repository if empty
+	baseBranch string
+	// sparseCheckout are the directories checked out in the worktree, all of them if empty
+	sparseCheckout []string
+	// submodules initializes the submodules of the worktree after it is created
+	submodules bool
+	// skipLFS leaves the git-lfs pointer files of the worktree as they are
+	skipLFS bool
+	// copyFiles and linkFiles are the patterns of the support files copied or
+	// linked from the main checkout into the worktree
+	copyFiles []string
+	linkFiles []string
+	// sharedCaches are the cache directories linked to the shared caches of the
+	// repository, kept in sharedCacheRoot
+	sharedCaches    []string
+	sharedCacheRoot string
+	// noSharedCaches ignores the shared caches of the repository config
+	noSharedCaches bool
 
 	// Diff caching
 	cachedDiffStats   *DiffStats
@@ -73,7 +95,7 @@ func NewGitWorktree(repoPath string, sessionName string) (tree *GitWorktree, bra
 		return nil, "", err
 	}
 
-	worktreeDir, err := getWorktreeDirectory()
+	worktreeDir, err := getRepoWorktreeDirectory(repoPath)
 	if err != nil {
 		return nil, "", err
 	}
@@ -90,10 +112,13 @@ func NewGitWorktree(repoPath string, sessionName string) (tree *GitWorktree, bra
 	}
 
 	return &GitWorktree{
-		repoPath:     repoPath,
-		sessionName:  sessionName,
-		branchName:   branchName,
-		worktreePath: worktreePath,
+		repoPath:          repoPath,
+		sessionName:       sessionName,
+		branchName:        branchName,
+		worktreePath:      worktreePath,
+		poolSize:          cfg.WorktreePoolSize,
+		fetchBeforeCreate: cfg.FetchBeforeCreate,
+		noSharedCaches:    cfg.NoSharedCaches,
 	}, branchName, nil
 }
 
@@ -112,9 +137,10 @@ func (g *GitWorktree) GetRepoPath() string {
 	return g.repoPath
 }
 
-// GetRepoName returns the name of the repository (last part of the repoPath).
+// GetRepoName returns the name of the repository (last part of the repoPath,
+// without the .git suffix of a bare repository).
 func (g *GitWorktree) GetRepoName() string {
-	return filepath.Base(g.repoPath)
+	return repoName(g.repoPath)
 }
 
 // GetBaseCommitSHA returns the base commit SHA for the worktree
@@ -129,6 +155,11 @@ func (g *GitWorktree) GetSessionName() string {
 	return g.sessionName
 }
 
+// SetBaseBranch sets the branch a new worktree starts from instead of HEAD.
+func (g *GitWorktree) SetBaseBranch(branch string) {
+	g.baseBranch = branch
+}
+
 // SetProgressCallback sets the callback function for progress updates
 func (g *GitWorktree) SetProgressCallback(callback ProgressCallback) {
 	g.progressCallback = callback
@@ -9,6 +9,11 @@ import (
 
 // runGitCommand executes a git command and returns any error
 func (g *GitWorktree) runGitCommand(path string, args ...string) (string, error) {
+	return runGit(path, args...)
+}
+
+// runGit executes a git command in the given path. It is used where no worktree is at hand.
+func runGit(path string, args ...string) (string, error) {
 	baseArgs := []string{"-C", path}
 	cmd := exec.Command("git", append(baseArgs, args...)...)
 
@@ -20,9 +25,9 @@ func (g *GitWorktree) runGitCommand(path string, args ...string) (string, error)
 	return string(output), nil
 }
 
-// PushChanges commits and pushes changes in the worktree to the remote branch
-func (g *GitWorktree) PushChanges(commitMessage string, open bool) error {
-	if err := checkGHCLI(); err != nil {
+// PushChanges commits and pushes changes in the worktree to the remote branch on the forge
+func (g *GitWorktree) PushChanges(forge Forge, commitMessage string, open bool) error {
+	if err := forge.CheckCLI(); err != nil {
 		return err
 	}
 
@@ -46,30 +51,25 @@ func (g *GitWorktree) PushChanges(commitMessage string, open bool) error {
 		}
 	}
 
-	// First push the branch to remote to ensure it exists
-	pushCmd := exec.Command("gh", "repo", "sync", "--source", "-b", g.branchName)
-	pushCmd.Dir = g.worktreePath
-	if err := pushCmd.Run(); err != nil {
-		// If sync fails, try creating the branch on remote first
-		gitPushCmd := exec.Command("git", "push", "-u", "origin", g.branchName)
-		gitPushCmd.Dir = g.worktreePath
-		if pushOutput, pushErr := gitPushCmd.CombinedOutput(); pushErr != nil {
-			log.ErrorLog.Print(pushErr)
-			return fmt.Errorf("failed to push branch: %s (%w)", pushOutput, pushErr)
+	// Fold fixup commits into their targets before they are published
+	if g.baseCommitSHA != "" {
+		if err := g.Autosquash(); err != nil {
+			return fmt.Errorf("failed to autosquash fixup commits: %w", err)
 		}
 	}
 
-	// Now sync with remote
-	syncCmd := exec.Command("gh", "repo", "sync", "-b", g.branchName)
-	syncCmd.Dir = g.worktreePath
-	if output, err := syncCmd.CombinedOutput(); err != nil {
-		log.ErrorLog.Print(err)
-		return fmt.Errorf("failed to sync changes: %s (%w)", output, err)
+	if err := forge.Push(g.worktreePath, g.branchName); err != nil {
+		return err
 	}
 
-	// Open the branch in the browser
+	// Open the branch in the browser, as a merge request on forges that have them
 	if open {
-		if err := g.OpenBranchURL(); err != nil {
+		if requester, ok := forge.(MergeRequester); ok {
+			if err := requester.OpenMergeRequest(g.worktreePath, g.branchName); err != nil {
+				// Just log the error but don't fail the push operation
+				log.ErrorLog.Printf("failed to open merge request: %v", err)
+			}
+		} else if err := forge.OpenBranch(g.worktreePath, g.branchName); err != nil {
 			// Just log the error but don't fail the push operation
 			log.ErrorLog.Printf("failed to open branch URL: %v", err)
 		}
@@ -112,6 +112,19 @@ func (g *GitWorktree) IsDirty() (bool, error) {
 	return len(output) > 0, nil
 }
 
+// HasUnpushedCommits checks if the branch has commits since its base that are not on any remote
+func (g *GitWorktree) HasUnpushedCommits() (bool, error) {
+	args := []string{"rev-list", "--count", g.branchName, "--not", "--remotes"}
+	if g.baseCommitSHA != "" {
+		args = append(args, g.baseCommitSHA)
+	}
+	output, err := g.runGitCommand(g.repoPath, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to count unpushed commits: %w", err)
+	}
+	return strings.TrimSpace(output) != "0", nil
+}
+
 // IsBranchCheckedOut checks if the instance branch is currently checked out
 func (g *GitWorktree) IsBranchCheckedOut() (bool, error) {
 	output, err := g.runGitCommand(g.repoPath, "branch", "--show-current")
@@ -120,18 +133,3 @@ func (g *GitWorktree) IsBranchCheckedOut() (bool, error) {
 	}
 	return strings.TrimSpace(string(output)) == g.branchName, nil
 }
-
-// OpenBranchURL opens the branch URL in the default browser
-func (g *GitWorktree) OpenBranchURL() error {
-	// Check if GitHub CLI is available
-	if err := checkGHCLI(); err != nil {
-		return err
-	}
-
-	cmd := exec.Command("gh", "browse", "--branch", g.branchName)
-	cmd.Dir = g.worktreePath
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to open branch URL: %w", err)
-	}
-	return nil
-}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
@@ -15,7 +16,8 @@ import (
 
 // ClaudeSettings represents the structure of .claude/settings.local.json
 type ClaudeSettings struct {
-	Permissions ClaudePermissions `json:"permissions"`
+	Permissions ClaudePermissions              `json:"permissions"`
+	Hooks       map[string][]ClaudeHookMatcher `json:"hooks,omitempty"`
 }
 
 // ClaudePermissions represents the permissions section of Claude settings
@@ -30,7 +32,7 @@ var DefaultAllowedCommands = []string{
 }
 
 // createClaudeSettingsFile creates a .claude/settings.local.json file in the worktree
-// that auto-approves git and gh commands
+// that auto-approves git and gh commands and installs the status hooks
 func (g *GitWorktree) createClaudeSettingsFile() error {
 	claudeDir := filepath.Join(g.worktreePath, ".claude")
 	if err := os.MkdirAll(claudeDir, 0755); err != nil {
@@ -43,6 +45,13 @@ func (g *GitWorktree) createClaudeSettingsFile() error {
 		},
 	}
 
+	// Without the hooks the status is still detected from the pane content
+	if hooks, err := g.installClaudeHooks(); err != nil {
+		log.WarningLog.Printf("failed to install Claude hooks: %v", err)
+	} else {
+		settings.Hooks = hooks
+	}
+
 	settingsJSON, err := json.MarshalIndent(settings, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal settings: %w", err)
@@ -61,10 +70,7 @@ func (g *GitWorktree) Setup() error {
 	g.reportProgress("Preparing worktree directory...")
 
 	// Ensure worktrees directory exists early (can be done in parallel with branch check)
-	worktreesDir, err := getWorktreeDirectory()
-	if err != nil {
-		return fmt.Errorf("failed to get worktree directory: %w", err)
-	}
+	worktreesDir := filepath.Dir(g.worktreePath)
 
 	// Create directory and check branch existence in parallel
 	errChan := make(chan error, 2)
@@ -97,12 +103,60 @@ func (g *GitWorktree) Setup() error {
 		}
 	}
 
+	repoConfig := loadRepoConfig(g.repoPath)
+	g.sparseCheckout = repoConfig.SparseCheckout
+	g.submodules = repoConfig.Submodules
+	g.skipLFS = repoConfig.SkipLFS
+	g.copyFiles = repoConfig.CopyFiles
+	g.linkFiles = repoConfig.LinkFiles
+	g.sharedCaches = repoConfig.SharedCaches
+	g.sharedCacheRoot = repoConfig.SharedCacheRoot
+
+	g.reportProgress("Checking free disk space...")
+	rev := "HEAD"
+	if branchExists {
+		rev = g.branchName
+	} else if g.baseBranch != "" {
+		rev = g.baseBranch
+	}
+	if err := g.checkDiskSpace(rev); err != nil {
+		return err
+	}
+
+	var err error
 	if branchExists {
 		g.reportProgress(fmt.Sprintf("Setting up worktree from existing branch '%s'...", g.branchName))
-		return g.setupFromExistingBranch()
+		err = g.setupFromExistingBranch()
+	} else {
+		g.reportProgress(fmt.Sprintf("Creating new worktree with branch '%s'...", g.branchName))
+		err = g.setupNewWorktree()
+	}
+	if err != nil {
+		return err
+	}
+	// Without its submodules, large files, support files or shared caches the
+	// worktree is still usable, just incomplete or slower to build
+	if g.submodules {
+		if err := g.initSubmodules(); err != nil {
+			log.WarningLog.Printf("%v", err)
+			g.reportProgress("Failed to initialize submodules, continuing without them...")
+		}
+	}
+	if !g.skipLFS {
+		if err := g.pullLFS(); err != nil {
+			log.WarningLog.Printf("%v", err)
+			g.reportProgress("Failed to pull git-lfs files, continuing with pointer files...")
+		}
+	}
+	if err := g.copySupportFiles(); err != nil {
+		log.WarningLog.Printf("%v", err)
+		g.reportProgress("Failed to copy some support files, continuing without them...")
+	}
+	if err := g.linkSharedCaches(); err != nil {
+		log.WarningLog.Printf("%v", err)
+		g.reportProgress("Failed to link some shared caches, continuing without them...")
 	}
-	g.reportProgress(fmt.Sprintf("Creating new worktree with branch '%s'...", g.branchName))
-	return g.setupNewWorktree()
+	return nil
 }
 
 // setupFromExistingBranch creates a worktree from an existing branch
@@ -115,7 +169,7 @@ func (g *GitWorktree) setupFromExistingBranch() error {
 
 	// Create a new worktree from the existing branch
 	g.reportProgress("Creating worktree...")
-	if _, err := g.runGitCommand(g.repoPath, "worktree", "add", g.worktreePath, g.branchName); err != nil {
+	if err := g.addWorktree(g.worktreePath, g.branchName); err != nil {
 		return fmt.Errorf("failed to create worktree from branch %s: %w", g.branchName, err)
 	}
 
@@ -136,7 +190,8 @@ func (g *GitWorktree) setupFromExistingBranch() error {
 	return nil
 }
 
-// setupNewWorktree creates a new worktree from HEAD
+// setupNewWorktree creates a new worktree from HEAD, or from the base branch if
+// set, or from the fetched default branch with fetch_before_create
 func (g *GitWorktree) setupNewWorktree() error {
 	// Ensure worktrees directory exists
 	worktreesDir := filepath.Join(g.repoPath, "worktrees")
@@ -160,6 +215,31 @@ func (g *GitWorktree) setupNewWorktree() error {
 		return fmt.Errorf("failed to cleanup existing branch: %w", err)
 	}
 
+	base := g.baseBranch
+	if g.fetchBeforeCreate {
+		// Offline, the instance still starts from the local code
+		fetched, err := g.fetchDefaultBranch()
+		if err != nil {
+			log.WarningLog.Printf("%v", err)
+			if fetched == "" {
+				g.reportProgress("Fetch failed, starting from the local code...")
+			}
+		}
+		// The fetched default branch rather than whatever is checked out
+		if base == "" {
+			base = fetched
+		}
+	}
+
+	if base != "" {
+		g.reportProgress(fmt.Sprintf("Getting commit of base branch '%s'...", strings.TrimPrefix(base, "refs/heads/")))
+		output, err := g.runGitCommand(g.repoPath, "rev-parse", "--verify", base+"^{commit}")
+		if err != nil {
+			return fmt.Errorf("failed to find base branch %s: %w", base, err)
+		}
+		return g.createWorktreeFrom(strings.TrimSpace(output))
+	}
+
 	g.reportProgress("Getting HEAD commit...")
 	output, err := g.runGitCommand(g.repoPath, "rev-parse", "HEAD")
 	if err != nil {
@@ -170,16 +250,29 @@ func (g *GitWorktree) setupNewWorktree() error {
 		}
 		return fmt.Errorf("failed to get HEAD commit hash: %w", err)
 	}
-	headCommit := strings.TrimSpace(string(output))
-	g.baseCommitSHA = headCommit
+	return g.createWorktreeFrom(strings.TrimSpace(string(output)))
+}
 
-	// Create a new worktree from the HEAD commit
+// createWorktreeFrom creates the worktree with a new branch at the given commit.
+func (g *GitWorktree) createWorktreeFrom(commit string) error {
+	g.baseCommitSHA = commit
+
+	// Create a new worktree from the commit rather than the current checkout.
 	// Otherwise, we'll inherit uncommitted changes from the previous worktree.
 	// This way, we can start the worktree with a clean slate.
-	// TODO: we might want to give an option to use main/master instead of the current branch.
-	g.reportProgress("Creating worktree...")
-	if _, err := g.runGitCommand(g.repoPath, "worktree", "add", "-b", g.branchName, g.worktreePath, headCommit); err != nil {
-		return fmt.Errorf("failed to create worktree from commit %s: %w", headCommit, err)
+	// Pooled worktrees are full checkouts, so sparse worktrees can't use them
+	usePool := g.poolSize > 0 && len(g.sparseCheckout) == 0
+	if usePool && g.claimPooledWorktree(commit) {
+		g.reportProgress("Claimed pre-created worktree")
+	} else {
+		g.reportProgress("Creating worktree...")
+		if err := g.addWorktree("-b", g.branchName, g.worktreePath, commit); err != nil {
+			return fmt.Errorf("failed to create worktree from commit %s: %w", commit, err)
+		}
+	}
+	if usePool {
+		// Replace the claimed worktree, or pre-create worktrees for the next instances
+		go FillWorktreePool(g.repoPath, g.poolSize)
 	}
 
 	// Create Claude settings file to auto-approve git/gh commands
@@ -206,6 +299,10 @@ func (g *GitWorktree) Cleanup() error {
 		errs = append(errs, fmt.Errorf("failed to check worktree path: %w", err))
 	}
 
+	if err := g.removeHookStatusFile(); err != nil {
+		errs = append(errs, err)
+	}
+
 	// Open the repository for branch cleanup
 	repo, err := git.PlainOpen(g.repoPath)
 	if err != nil {
@@ -260,15 +357,18 @@ func (g *GitWorktree) Prune() error {
 	return nil
 }
 
-// CleanupWorktrees removes all worktrees and their associated branches
-func CleanupWorktrees() error {
+// CleanupWorktrees removes all worktrees and their associated branches: those
+// in the worktrees directory, and those the repositories keep in the
+// worktree_root they are configured with. The repository of the current
+// directory is cleaned up along with repoPaths.
+func CleanupWorktrees(repoPaths []string) error {
 	worktreesDir, err := getWorktreeDirectory()
 	if err != nil {
 		return fmt.Errorf("failed to get worktree directory: %w", err)
 	}
 
 	entries, err := os.ReadDir(worktreesDir)
-	if err != nil {
+	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to read worktree directory: %w", err)
 	}
 
@@ -278,23 +378,7 @@ func CleanupWorktrees() error {
 	if err != nil {
 		return fmt.Errorf("failed to list worktrees: %w", err)
 	}
-
-	// Parse the output to extract branch names
-	worktreeBranches := make(map[string]string)
-	currentWorktree := ""
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "worktree ") {
-			currentWorktree = strings.TrimPrefix(line, "worktree ")
-		} else if strings.HasPrefix(line, "branch ") {
-			branchPath := strings.TrimPrefix(line, "branch ")
-			// Extract branch name from refs/heads/branch-name
-			branchName := strings.TrimPrefix(branchPath, "refs/heads/")
-			if currentWorktree != "" {
-				worktreeBranches[currentWorktree] = branchName
-			}
-		}
-	}
+	worktreeBranches := parseWorktreeBranches(string(output))
 
 	for _, entry := range entries {
 		if entry.IsDir() {
@@ -325,9 +409,93 @@ func CleanupWorktrees() error {
 		return fmt.Errorf("failed to prune worktrees: %w", err)
 	}
 
+	if cwd, err := findGitRepoRoot("."); err == nil && !slices.Contains(repoPaths, cwd) {
+		repoPaths = append(repoPaths, cwd)
+	}
+	for _, repoPath := range repoPaths {
+		root, err := getRepoWorktreeDirectory(repoPath)
+		if err != nil {
+			log.ErrorLog.Printf("failed to get the worktree directory of %s: %v", repoPath, err)
+			continue
+		}
+		if root == worktreesDir {
+			continue
+		}
+		if err := cleanupRepoWorktrees(repoPath, root); err != nil {
+			log.ErrorLog.Printf("failed to clean up the worktrees of %s: %v", repoPath, err)
+		}
+	}
+
+	return nil
+}
+
+// parseWorktreeBranches returns the branches of the worktrees listed by git
+// worktree list --porcelain, by worktree path.
+func parseWorktreeBranches(output string) map[string]string {
+	worktreeBranches := make(map[string]string)
+	currentWorktree := ""
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "worktree ") {
+			currentWorktree = strings.TrimPrefix(line, "worktree ")
+		} else if strings.HasPrefix(line, "branch ") {
+			branchPath := strings.TrimPrefix(line, "branch ")
+			// Extract branch name from refs/heads/branch-name
+			branchName := strings.TrimPrefix(branchPath, "refs/heads/")
+			if currentWorktree != "" {
+				worktreeBranches[currentWorktree] = branchName
+			}
+		}
+	}
+	return worktreeBranches
+}
+
+// cleanupRepoWorktrees removes the worktrees of the repository in root, and
+// their branches. A configured root may hold other files, so only the
+// worktrees git knows of are removed.
+func cleanupRepoWorktrees(repoPath, root string) error {
+	output, err := runGit(repoPath, "worktree", "list", "--porcelain")
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	var paths []string
+	for _, line := range strings.Split(output, "\n") {
+		if path, ok := strings.CutPrefix(line, "worktree "); ok {
+			paths = append(paths, path)
+		}
+	}
+	branches := parseWorktreeBranches(output)
+	for _, path := range paths {
+		if !isInsideDir(root, path) {
+			continue
+		}
+		if _, err := runGit(repoPath, "worktree", "remove", "-f", "-f", path); err != nil {
+			log.ErrorLog.Printf("failed to remove worktree %s: %v", path, err)
+			continue
+		}
+		if branch, ok := branches[path]; ok {
+			if _, err := runGit(repoPath, "branch", "-D", branch); err != nil {
+				log.ErrorLog.Printf("failed to delete branch %s: %v", branch, err)
+			}
+		}
+	}
+	if _, err := runGit(repoPath, "worktree", "prune"); err != nil {
+		return fmt.Errorf("failed to prune worktrees: %w", err)
+	}
 	return nil
 }
 
+// isInsideDir returns whether path is below dir, following symlinks.
+func isInsideDir(dir, path string) bool {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // computeBaseCommitSHA finds the merge-base between the current branch and the default branch
 // This is used to compute diffs for existing branches that were resumed
 func (g *GitWorktree) computeBaseCommitSHA() error {
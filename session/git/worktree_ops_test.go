@@ -0,0 +1,42 @@
+package git
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetupFromBaseBranch(t *testing.T) {
+	g := setupTestWorktree(t)
+	for _, args := range [][]string{
+		{"branch", "base"},
+		{"commit", "-q", "--allow-empty", "-m", "after base"},
+	} {
+		if _, err := runGit(g.repoPath, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	base, err := runGit(g.repoPath, "rev-parse", "base")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worktree := NewGitWorktreeFromStorage(g.repoPath, filepath.Join(t.TempDir(), "new"), "new", "test/new", "")
+	worktree.SetBaseBranch("base")
+	if err := worktree.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	head, err := runGit(worktree.GetWorktreePath(), "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(head) != strings.TrimSpace(base) || worktree.GetBaseCommitSHA() != strings.TrimSpace(base) {
+		t.Errorf("worktree starts at %s with base %s, want the base branch at %s", strings.TrimSpace(head), worktree.GetBaseCommitSHA(), strings.TrimSpace(base))
+	}
+
+	missing := NewGitWorktreeFromStorage(g.repoPath, filepath.Join(t.TempDir(), "missing"), "missing", "test/missing", "")
+	missing.SetBaseBranch("no-such-branch")
+	if err := missing.Setup(); err == nil || !strings.Contains(err.Error(), "base branch") {
+		t.Errorf("Setup() error = %v, want a missing base branch error", err)
+	}
+}
@@ -0,0 +1,178 @@
+package git
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// poolMaxAge is how long a pooled worktree is kept. Older ones are replaced,
+	// so a claim doesn't have to check out a long way from where they were made.
+	poolMaxAge = 24 * time.Hour
+	// poolLeftoverAge is how old a hidden worktree has to be to count as left
+	// over by a fill that was interrupted, rather than one still being created.
+	poolLeftoverAge = 10 * time.Minute
+)
+
+var (
+	// poolMu serializes claiming pooled worktrees
+	poolMu sync.Mutex
+	// poolFilling tracks the repositories whose pool is being filled
+	poolFilling   = make(map[string]bool)
+	poolFillingMu sync.Mutex
+)
+
+// getWorktreePoolDirectory returns the directory holding the pre-created worktrees of a repository.
+func getWorktreePoolDirectory(repoPath string) (string, error) {
+	// Keep the pool next to the worktrees, so claiming one only renames it
+	worktreeDir, err := getRepoWorktreeDirectory(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(worktreeDir, ".pool", repoDirName(repoPath)), nil
+}
+
+// claimPooledWorktree moves a pre-created worktree of the repository to the
+// worktree path and creates the branch in it at the given commit. Pooled
+// worktrees that can't be used are discarded. It returns false if no pooled
+// worktree could be claimed.
+func (g *GitWorktree) claimPooledWorktree(commit string) bool {
+	poolDir, err := getWorktreePoolDirectory(g.repoPath)
+	if err != nil {
+		return false
+	}
+
+	poolMu.Lock()
+	defer poolMu.Unlock()
+
+	entries, err := os.ReadDir(poolDir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		// Worktrees that are still being created are hidden
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		pooled := filepath.Join(poolDir, entry.Name())
+		if poolEntryOlderThan(entry, poolMaxAge) {
+			discardPooledWorktree(g.repoPath, pooled)
+			continue
+		}
+		if err := g.adoptPooledWorktree(pooled, commit); err != nil {
+			log.WarningLog.Printf("discarding pooled worktree %s: %v", pooled, err)
+			discardPooledWorktree(g.repoPath, pooled)
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// adoptPooledWorktree turns a pooled worktree into this worktree. Checking out
+// the commit only touches the files that changed since the worktree was pooled.
+func (g *GitWorktree) adoptPooledWorktree(pooled, commit string) error {
+	status, err := g.runGitCommand(pooled, "status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(status) != "" {
+		return fmt.Errorf("pooled worktree has changes")
+	}
+
+	if _, err := g.runGitCommand(g.repoPath, "worktree", "move", pooled, g.worktreePath); err != nil {
+		return fmt.Errorf("failed to move pooled worktree: %w", err)
+	}
+	if _, err := g.runGitCommand(g.worktreePath, "checkout", "-q", "-b", g.branchName, commit); err != nil {
+		_, _ = g.runGitCommand(g.repoPath, "worktree", "remove", "-f", g.worktreePath)
+		return fmt.Errorf("failed to create branch %s in pooled worktree: %w", g.branchName, err)
+	}
+	return nil
+}
+
+// FillWorktreePool creates detached worktrees of the repository at HEAD until
+// its pool holds size of them. Only one fill per repository runs at a time.
+func FillWorktreePool(repoPath string, size int) {
+	poolFillingMu.Lock()
+	if poolFilling[repoPath] {
+		poolFillingMu.Unlock()
+		return
+	}
+	poolFilling[repoPath] = true
+	poolFillingMu.Unlock()
+
+	defer func() {
+		poolFillingMu.Lock()
+		delete(poolFilling, repoPath)
+		poolFillingMu.Unlock()
+	}()
+
+	if err := fillWorktreePool(repoPath, size); err != nil {
+		log.WarningLog.Printf("failed to fill worktree pool for %s: %v", repoPath, err)
+	}
+}
+
+func fillWorktreePool(repoPath string, size int) error {
+	poolDir, err := getWorktreePoolDirectory(repoPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(poolDir, 0755); err != nil {
+		return fmt.Errorf("failed to create worktree pool directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(poolDir)
+	if err != nil {
+		return fmt.Errorf("failed to read worktree pool directory: %w", err)
+	}
+	pooled := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		hidden := strings.HasPrefix(entry.Name(), ".")
+		switch {
+		case hidden && poolEntryOlderThan(entry, poolLeftoverAge):
+			// Left over by a fill that was interrupted
+			discardPooledWorktree(repoPath, filepath.Join(poolDir, entry.Name()))
+		case !hidden && poolEntryOlderThan(entry, poolMaxAge):
+			// Replaced by a fresh one below
+			discardPooledWorktree(repoPath, filepath.Join(poolDir, entry.Name()))
+		case !hidden:
+			pooled++
+		}
+	}
+
+	for ; pooled < size; pooled++ {
+		// Create the worktree under a hidden name so it can't be claimed half-done
+		name := fmt.Sprintf("%x", time.Now().UnixNano())
+		creating := filepath.Join(poolDir, "."+name)
+		if _, err := runGit(repoPath, "worktree", "add", "-q", "--detach", creating, "HEAD"); err != nil {
+			return fmt.Errorf("failed to create pooled worktree: %w", err)
+		}
+		if _, err := runGit(repoPath, "worktree", "move", creating, filepath.Join(poolDir, name)); err != nil {
+			_, _ = runGit(repoPath, "worktree", "remove", "-f", creating)
+			return fmt.Errorf("failed to add worktree to the pool: %w", err)
+		}
+	}
+	return nil
+}
+
+// poolEntryOlderThan returns true if the pooled worktree was created more than
+// age ago.
+func poolEntryOlderThan(entry os.DirEntry, age time.Duration) bool {
+	info, err := entry.Info()
+	return err == nil && time.Since(info.ModTime()) > age
+}
+
+// discardPooledWorktree removes a pooled worktree, also if git no longer knows it.
+func discardPooledWorktree(repoPath, path string) {
+	_, _ = runGit(repoPath, "worktree", "remove", "-f", path)
+	_ = os.RemoveAll(path)
+	_, _ = runGit(repoPath, "worktree", "prune")
+}
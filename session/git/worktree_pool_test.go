@@ -0,0 +1,127 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorktreePool(t *testing.T) {
+	g := setupTestWorktree(t)
+	repoPath := g.repoPath
+
+	poolDir, err := getWorktreePoolDirectory(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pooled := func() int {
+		entries, _ := os.ReadDir(poolDir)
+		return len(entries)
+	}
+
+	FillWorktreePool(repoPath, 2)
+	if pooled() != 2 {
+		t.Fatalf("pool holds %d worktrees, want 2", pooled())
+	}
+	FillWorktreePool(repoPath, 2)
+	if pooled() != 2 {
+		t.Fatalf("refilling a full pool should not add worktrees, got %d", pooled())
+	}
+
+	// HEAD moves on after the pool was filled
+	if err := os.WriteFile(filepath.Join(repoPath, "new.txt"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "new"}} {
+		if _, err := runGit(repoPath, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	head, err := runGit(repoPath, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	head = strings.TrimSpace(head)
+
+	claimed := &GitWorktree{
+		repoPath:     repoPath,
+		worktreePath: filepath.Join(t.TempDir(), "claimed"),
+		branchName:   "test/claimed",
+		poolSize:     2,
+	}
+	if !claimed.claimPooledWorktree(head) {
+		t.Fatal("claimPooledWorktree() = false, want a pooled worktree")
+	}
+	if pooled() != 1 {
+		t.Errorf("pool holds %d worktrees after a claim, want 1", pooled())
+	}
+
+	branch, err := runGit(claimed.worktreePath, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || strings.TrimSpace(branch) != "test/claimed" {
+		t.Errorf("claimed worktree is on %q, %v; want test/claimed", branch, err)
+	}
+	if _, err := os.Stat(filepath.Join(claimed.worktreePath, "new.txt")); err != nil {
+		t.Errorf("claimed worktree should be at the current HEAD: %v", err)
+	}
+
+	// A pooled worktree with changes is discarded instead of claimed
+	entries, _ := os.ReadDir(poolDir)
+	if err := os.WriteFile(filepath.Join(poolDir, entries[0].Name(), "dirty.txt"), []byte("dirty"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	other := &GitWorktree{
+		repoPath:     repoPath,
+		worktreePath: filepath.Join(t.TempDir(), "other"),
+		branchName:   "test/other",
+	}
+	if other.claimPooledWorktree(head) {
+		t.Error("claimPooledWorktree() should not claim a worktree with changes")
+	}
+	if pooled() != 0 {
+		t.Errorf("the dirty pooled worktree should have been discarded, pool holds %d", pooled())
+	}
+}
+
+func TestWorktreePoolDiscardsStaleAndLeftovers(t *testing.T) {
+	g := setupTestWorktree(t)
+	repoPath := g.repoPath
+
+	poolDir, err := getWorktreePoolDirectory(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	FillWorktreePool(repoPath, 1)
+	entries, err := os.ReadDir(poolDir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("pool holds %v, %v; want 1 worktree", entries, err)
+	}
+	stale := filepath.Join(poolDir, entries[0].Name())
+
+	// A worktree left hidden by an interrupted fill
+	leftover := filepath.Join(poolDir, ".leftover")
+	if _, err := runGit(repoPath, "worktree", "add", "-q", "--detach", leftover, "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * poolMaxAge)
+	for _, path := range []string{stale, leftover} {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	FillWorktreePool(repoPath, 1)
+	for _, path := range []string{stale, leftover} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should have been discarded: %v", filepath.Base(path), err)
+		}
+	}
+	entries, _ = os.ReadDir(poolDir)
+	if len(entries) != 1 {
+		t.Errorf("pool holds %d worktrees, want a fresh one", len(entries))
+	}
+	if list, _ := runGit(repoPath, "worktree", "list"); strings.Contains(list, "leftover") {
+		t.Errorf("git still knows the discarded worktree:\n%s", list)
+	}
+}
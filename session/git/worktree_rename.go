@@ -0,0 +1,112 @@
+package git
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Rename renames the branch of the worktree to match a new session name and
+// moves the worktree directory along with it. The branch name is derived from
+// the session name the same way as in NewGitWorktree. If moving the worktree
+// fails, the branch is renamed back.
+func (g *GitWorktree) Rename(sessionName string) error {
+	cfg := config.LoadConfig()
+	branchName := sanitizeBranchName(fmt.Sprintf("%s%s", cfg.BranchPrefix, sessionName))
+	worktreePath := renamedWorktreePath(g.worktreePath, g.branchName, branchName)
+
+	if branchName != g.branchName {
+		if _, err := g.runGitCommand(g.repoPath, "branch", "-m", g.branchName, branchName); err != nil {
+			return fmt.Errorf("failed to rename branch %s: %w", g.branchName, err)
+		}
+	}
+
+	// A paused instance has no worktree; it is created at the new path on resume
+	moved := false
+	if _, err := os.Stat(g.worktreePath); err == nil && worktreePath != g.worktreePath {
+		err := os.MkdirAll(filepath.Dir(worktreePath), 0755)
+		if err == nil {
+			_, err = g.runGitCommand(g.repoPath, "worktree", "move", g.worktreePath, worktreePath)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to move worktree: %w", err)
+			if branchName != g.branchName {
+				if _, rollbackErr := g.runGitCommand(g.repoPath, "branch", "-m", branchName, g.branchName); rollbackErr != nil {
+					err = fmt.Errorf("%v (rollback error: %v)", err, rollbackErr)
+				}
+			}
+			return err
+		}
+		moved = true
+	}
+
+	g.branchName = branchName
+	g.worktreePath = worktreePath
+	g.sessionName = sessionName
+
+	// The hook status file is named after the worktree directory
+	if moved {
+		if err := g.updateClaudeHooks(); err != nil {
+			log.WarningLog.Printf("failed to update Claude hooks after moving worktree: %v", err)
+		}
+	}
+	return nil
+}
+
+// renamedWorktreePath returns where a worktree moves to when its branch is
+// renamed: the branch name in its path is replaced, keeping the directory and
+// the unique suffix. Worktrees whose path isn't named after the branch stay
+// where they are.
+func renamedWorktreePath(worktreePath, oldBranch, newBranch string) string {
+	name := string(filepath.Separator) + filepath.FromSlash(oldBranch)
+	i := strings.LastIndex(worktreePath, name)
+	if i < 0 {
+		return worktreePath
+	}
+	rest := worktreePath[i+len(name):]
+	if rest != "" && !strings.HasPrefix(rest, "_") {
+		return worktreePath
+	}
+	return worktreePath[:i+1] + filepath.FromSlash(newBranch) + rest
+}
+
+// updateClaudeHooks points the hooks in the worktree's Claude settings at the
+// current hook status file. Other settings are kept as they are.
+func (g *GitWorktree) updateClaudeHooks() error {
+	settingsPath := filepath.Join(g.worktreePath, ".claude", "settings.local.json")
+	data, err := os.ReadFile(settingsPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("failed to parse settings file: %w", err)
+	}
+	if _, ok := settings["hooks"]; !ok {
+		return nil
+	}
+
+	hooks, err := g.installClaudeHooks()
+	if err != nil {
+		return err
+	}
+	if settings["hooks"], err = json.Marshal(hooks); err != nil {
+		return fmt.Errorf("failed to marshal hooks: %w", err)
+	}
+	settingsJSON, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+	if err := os.WriteFile(settingsPath, settingsJSON, 0644); err != nil {
+		return fmt.Errorf("failed to write settings file: %w", err)
+	}
+	return nil
+}
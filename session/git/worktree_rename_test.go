@@ -0,0 +1,132 @@
+package git
+
+import (
+	"claude-squad/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenamedWorktreePath(t *testing.T) {
+	dir := filepath.Join("home", "worktrees")
+	tests := []struct {
+		name         string
+		worktreePath string
+		oldBranch    string
+		newBranch    string
+		want         string
+	}{
+		{
+			name:         "suffix kept",
+			worktreePath: filepath.Join(dir, "feature_abc_abc"),
+			oldBranch:    "feature_abc",
+			newBranch:    "renamed_abc",
+			want:         filepath.Join(dir, "renamed_abc_abc"),
+		},
+		{
+			name:         "branch prefix",
+			worktreePath: filepath.Join(dir, "user", "feature_abc_abc"),
+			oldBranch:    "user/feature_abc",
+			newBranch:    "user/renamed_abc",
+			want:         filepath.Join(dir, "user", "renamed_abc_abc"),
+		},
+		{
+			name:         "not named after the branch",
+			worktreePath: filepath.Join(dir, "something-else"),
+			oldBranch:    "feature_abc",
+			newBranch:    "renamed_abc",
+			want:         filepath.Join(dir, "something-else"),
+		},
+		{
+			name:         "branch is only a prefix of the directory",
+			worktreePath: filepath.Join(dir, "feature_abcdef"),
+			oldBranch:    "feature_abc",
+			newBranch:    "renamed_abc",
+			want:         filepath.Join(dir, "feature_abcdef"),
+		},
+	}
+	for _, tt := range tests {
+		if got := renamedWorktreePath(tt.worktreePath, tt.oldBranch, tt.newBranch); got != tt.want {
+			t.Errorf("%s: renamedWorktreePath() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// setupNamedTestWorktree returns a test worktree whose directory is named
+// after its branch, like the worktrees created by NewGitWorktree.
+func setupNamedTestWorktree(t *testing.T) *GitWorktree {
+	t.Helper()
+	g := setupTestWorktree(t)
+	worktreePath := filepath.Join(t.TempDir(), "test", "branch_abc")
+	if err := os.MkdirAll(filepath.Dir(worktreePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(g.repoPath, "worktree", "move", g.worktreePath, worktreePath); err != nil {
+		t.Fatal(err)
+	}
+	g.worktreePath = worktreePath
+	return g
+}
+
+func TestWorktreeRename(t *testing.T) {
+	g := setupNamedTestWorktree(t)
+	oldPath := g.worktreePath
+	if err := g.createClaudeSettingsFile(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Rename("renamed_abc"); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if !strings.HasSuffix(g.GetBranchName(), "renamed_abc") {
+		t.Errorf("branch = %q, want it to end in the new session name", g.GetBranchName())
+	}
+	if g.GetSessionName() != "renamed_abc" {
+		t.Errorf("session name = %q, want %q", g.GetSessionName(), "renamed_abc")
+	}
+	if branch, err := runGit(g.worktreePath, "rev-parse", "--abbrev-ref", "HEAD"); err != nil || strings.TrimSpace(branch) != g.GetBranchName() {
+		t.Errorf("worktree is on branch %q (%v), want %q", strings.TrimSpace(branch), err, g.GetBranchName())
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("old worktree directory should be gone, stat error = %v", err)
+	}
+
+	// The hooks write to the status file of the moved worktree
+	statusFile, err := g.HookStatusFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := os.ReadFile(filepath.Join(g.worktreePath, ".claude", "settings.local.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(settings), filepath.ToSlash(statusFile)) {
+		t.Errorf("settings don't use the status file %s:\n%s", statusFile, settings)
+	}
+}
+
+func TestWorktreeRenameRollsBackBranch(t *testing.T) {
+	g := setupNamedTestWorktree(t)
+	oldBranch, oldPath := g.branchName, g.worktreePath
+
+	// Something is already in the way of the moved worktree
+	newBranch := sanitizeBranchName(config.LoadConfig().BranchPrefix + "renamed_abc")
+	blocked := renamedWorktreePath(oldPath, oldBranch, newBranch)
+	if err := os.MkdirAll(filepath.Dir(blocked), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(blocked, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.Rename("renamed_abc"); err == nil {
+		t.Fatal("Rename() should fail when the worktree can't be moved")
+	}
+	if g.branchName != oldBranch || g.worktreePath != oldPath {
+		t.Errorf("worktree = %s on %s, want it unchanged", g.worktreePath, g.branchName)
+	}
+	if _, err := runGit(g.repoPath, "rev-parse", "--verify", "refs/heads/"+oldBranch); err != nil {
+		t.Errorf("branch %s should have been renamed back: %v", oldBranch, err)
+	}
+}
@@ -0,0 +1,137 @@
+package git
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// diskSpaceHeadroom is the free space left over after checking out a worktree,
+// for the agent's builds and dependencies.
+const diskSpaceHeadroom = 100 << 20
+
+// getRepoWorktreeDirectory returns the directory the worktrees of a repository
+// are created in: the worktree_root of the repository config if set, otherwise
+// that of the global config, otherwise the worktrees directory in the config
+// directory.
+func getRepoWorktreeDirectory(repoPath string) (string, error) {
+	root := config.LoadConfig().WorktreeRoot
+	if repoConfig := loadRepoConfig(repoPath); repoConfig.WorktreeRoot != "" {
+		root = repoConfig.WorktreeRoot
+	}
+	if root == "" {
+		return getWorktreeDirectory()
+	}
+	return resolveWorktreeRoot(root, repoPath)
+}
+
+// loadRepoConfig returns the repository config of the repository, or an empty
+// one if there is none or it can't be read.
+func loadRepoConfig(repoPath string) *config.RepoConfig {
+	repoConfig, err := config.LoadRepoConfig(repoPath)
+	if err != nil {
+		log.WarningLog.Printf("ignoring repository config: %v", err)
+	}
+	if repoConfig == nil {
+		return &config.RepoConfig{}
+	}
+	return repoConfig
+}
+
+// resolveWorktreeRoot expands a configured worktree root. A leading ~ is the
+// home directory, {repo} is replaced by the name of the repository and relative
+// paths are relative to the repository, so "../{repo}-worktrees" keeps the
+// worktrees next to the repository, on the same drive.
+func resolveWorktreeRoot(root, repoPath string) (string, error) {
+	root = strings.ReplaceAll(root, "{repo}", repoName(repoPath))
+	if root == "~" || strings.HasPrefix(root, "~/") || strings.HasPrefix(root, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand worktree root %s: %w", root, err)
+		}
+		root = filepath.Join(home, root[1:])
+	}
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(repoPath, root)
+	}
+	return filepath.Clean(root), nil
+}
+
+// checkDiskSpace returns an error if the drive of the worktree doesn't have
+// room for a checkout of rev. The check is skipped if the size of the checkout
+// or the free space can't be determined.
+func (g *GitWorktree) checkDiskSpace(rev string) error {
+	needed, err := g.checkoutSize(rev)
+	if err != nil {
+		log.WarningLog.Printf("could not estimate the size of the worktree: %v", err)
+		return nil
+	}
+	dir := filepath.Dir(g.worktreePath)
+	free, err := freeDiskSpace(dir)
+	if err != nil {
+		log.WarningLog.Printf("could not get the free disk space of %s: %v", dir, err)
+		return nil
+	}
+	if free < uint64(needed)+diskSpaceHeadroom {
+		return fmt.Errorf("not enough disk space in %s: the worktree needs about %s and %s more for builds, but only %s is free",
+			dir, FormatSize(needed), FormatSize(diskSpaceHeadroom), FormatSize(int64(free)))
+	}
+	return nil
+}
+
+// checkoutSize returns the total size of the files in rev that are checked out.
+func (g *GitWorktree) checkoutSize(rev string) (int64, error) {
+	output, err := g.runGitCommand(g.repoPath, "ls-tree", "-r", "-l", "--full-tree", rev)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, line := range strings.Split(output, "\n") {
+		// <mode> <type> <object> <size>\t<path>, with a size of "-" for submodules
+		info, path, ok := strings.Cut(line, "\t")
+		if !ok || !g.isCheckedOut(path) {
+			continue
+		}
+		fields := strings.Fields(info)
+		if len(fields) != 4 {
+			continue
+		}
+		if size, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			total += size
+		}
+	}
+	return total, nil
+}
+
+// isCheckedOut returns whether a file of the repository is checked out in the
+// worktree: all of them are unless it is sparse, and then only those at the root
+// and in the sparse checkout directories.
+func (g *GitWorktree) isCheckedOut(path string) bool {
+	if len(g.sparseCheckout) == 0 || !strings.Contains(path, "/") {
+		return true
+	}
+	for _, dir := range g.sparseCheckout {
+		if strings.HasPrefix(path, strings.Trim(dir, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// existingParent returns the path, or its closest parent that exists.
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
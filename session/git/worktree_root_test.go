@@ -0,0 +1,148 @@
+package git
+
+import (
+	"claude-squad/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveWorktreeRoot(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	repo := filepath.Join(string(filepath.Separator), "src", "myrepo")
+	tests := []struct {
+		name string
+		root string
+		want string
+	}{
+		{
+			name: "absolute",
+			root: filepath.Join(string(filepath.Separator), "worktrees"),
+			want: filepath.Join(string(filepath.Separator), "worktrees"),
+		},
+		{
+			name: "home",
+			root: "~/worktrees",
+			want: filepath.Join(home, "worktrees"),
+		},
+		{
+			name: "next to the repository",
+			root: "../{repo}-worktrees",
+			want: filepath.Join(string(filepath.Separator), "src", "myrepo-worktrees"),
+		},
+		{
+			name: "inside the repository",
+			root: ".worktrees",
+			want: filepath.Join(repo, ".worktrees"),
+		},
+	}
+	for _, tt := range tests {
+		got, err := resolveWorktreeRoot(tt.root, repo)
+		if err != nil {
+			t.Errorf("%s: resolveWorktreeRoot() error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: resolveWorktreeRoot() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewGitWorktreeUsesRepoWorktreeRoot(t *testing.T) {
+	g := setupTestWorktree(t)
+	repoConfig := filepath.Join(g.repoPath, config.RepoConfigFileName)
+	if err := os.WriteFile(repoConfig, []byte("worktree_root: ../{repo}-worktrees\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	worktree, _, err := NewGitWorktree(g.repoPath, "feature_abc")
+	if err != nil {
+		t.Fatalf("NewGitWorktree() error = %v", err)
+	}
+	root := filepath.Join(filepath.Dir(worktree.GetRepoPath()), worktree.GetRepoName()+"-worktrees")
+	if !strings.HasPrefix(worktree.GetWorktreePath(), root+string(filepath.Separator)) {
+		t.Errorf("worktree path = %s, want it in %s", worktree.GetWorktreePath(), root)
+	}
+
+	poolDir, err := getWorktreePoolDirectory(worktree.GetRepoPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(poolDir, root+string(filepath.Separator)) {
+		t.Errorf("pool directory = %s, want it in %s", poolDir, root)
+	}
+}
+
+func TestCheckoutSize(t *testing.T) {
+	g := setupTestWorktree(t)
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "a.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(g.worktreePath, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(g.worktreePath, "dir", "b.txt"), []byte("world!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"add", "."},
+		{"commit", "-q", "-m", "files"},
+	} {
+		if _, err := runGit(g.worktreePath, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+
+	size, err := g.checkoutSize("test/branch")
+	if err != nil {
+		t.Fatalf("checkoutSize() error = %v", err)
+	}
+	if size != 11 {
+		t.Errorf("checkoutSize() = %d, want 11", size)
+	}
+	if err := g.checkDiskSpace("test/branch"); err != nil {
+		t.Errorf("checkDiskSpace() error = %v", err)
+	}
+}
+
+func TestCleanupWorktreesInRepoWorktreeRoot(t *testing.T) {
+	g := setupTestWorktree(t)
+	repoConfig := filepath.Join(g.repoPath, config.RepoConfigFileName)
+	if err := os.WriteFile(repoConfig, []byte("worktree_root: ../{repo}-worktrees\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	root, err := getRepoWorktreeDirectory(g.repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+	worktreePath := filepath.Join(root, "feature")
+	if _, err := runGit(g.repoPath, "worktree", "add", "-q", "-b", "test/feature", worktreePath); err != nil {
+		t.Fatal(err)
+	}
+	// Other files in a configured root are left alone
+	other := filepath.Join(root, "notes.txt")
+	if err := os.WriteFile(other, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CleanupWorktrees([]string{g.repoPath}); err != nil {
+		t.Fatalf("CleanupWorktrees() error = %v", err)
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Errorf("the worktree in the worktree root should be removed: %v", err)
+	}
+	if _, err := runGit(g.repoPath, "rev-parse", "--verify", "test/feature"); err == nil {
+		t.Error("the branch of the removed worktree should be deleted")
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("other files in the worktree root should be kept: %v", err)
+	}
+	// The worktree outside of the worktree root is not touched
+	if _, err := os.Stat(g.worktreePath); err != nil {
+		t.Errorf("worktrees outside of the worktree root should be kept: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CheckoutSnapshot checks out the worktree's current state in the main
+// repository, detached so the branch stays with the worktree, for reviewing
+// the changes while the session keeps running. Uncommitted changes to tracked
+// files are included, without touching the worktree; untracked files are not.
+// It fails when the main repository has uncommitted changes, which the checkout
+// could overwrite. It returns the checked out commit.
+func (g *GitWorktree) CheckoutSnapshot() (string, error) {
+	if isBareRepo(g.repoPath) {
+		return "", fmt.Errorf("%s is a bare repository, it has no checkout to check out %s in", g.repoPath, g.branchName)
+	}
+	status, err := g.runGitCommand(g.repoPath, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return "", fmt.Errorf("failed to check repository status: %w", err)
+	}
+	if strings.TrimSpace(status) != "" {
+		return "", fmt.Errorf("%s has uncommitted changes, commit or stash them before checking out %s", g.repoPath, g.branchName)
+	}
+
+	// stash create records the uncommitted changes as a commit without
+	// changing the worktree or its index, and prints nothing when it is clean
+	output, err := g.runGitCommand(g.worktreePath, "stash", "create", fmt.Sprintf("snapshot of %s", g.branchName))
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot uncommitted changes: %w", err)
+	}
+	rev := strings.TrimSpace(output)
+	if rev == "" {
+		output, err = g.runGitCommand(g.worktreePath, "rev-parse", "HEAD")
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", g.branchName, err)
+		}
+		rev = strings.TrimSpace(output)
+	}
+
+	if _, err := g.runGitCommand(g.repoPath, "checkout", "--detach", rev); err != nil {
+		return "", fmt.Errorf("failed to check out %s: %w", g.branchName, err)
+	}
+	return rev, nil
+}
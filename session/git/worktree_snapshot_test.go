@@ -0,0 +1,52 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckoutSnapshot(t *testing.T) {
+	g := setupTestWorktree(t)
+	file := filepath.Join(g.worktreePath, "main.go")
+	if err := os.WriteFile(file, []byte("committed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"add", "."},
+		{"commit", "-q", "-m", "file"},
+	} {
+		if _, err := runGit(g.worktreePath, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	if err := os.WriteFile(file, []byte("uncommitted"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.CheckoutSnapshot(); err != nil {
+		t.Fatalf("CheckoutSnapshot() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(g.repoPath, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "uncommitted" {
+		t.Errorf("repository has %q, want the uncommitted change", content)
+	}
+	if branch, _ := runGit(g.repoPath, "branch", "--show-current"); strings.TrimSpace(branch) != "" {
+		t.Errorf("repository is on branch %q, want a detached HEAD", strings.TrimSpace(branch))
+	}
+	if dirty, err := g.IsDirty(); err != nil || !dirty {
+		t.Errorf("IsDirty() = %v, %v, want the worktree to keep its changes", dirty, err)
+	}
+
+	// A checkout that could overwrite changes in the repository is refused
+	if err := os.WriteFile(filepath.Join(g.repoPath, "main.go"), []byte("mine"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.CheckoutSnapshot(); err == nil {
+		t.Error("CheckoutSnapshot() with uncommitted changes in the repository succeeded, want an error")
+	}
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// addWorktree runs git worktree add with the arguments. With sparse checkout
+// directories, the worktree is added without files, limited to the directories
+// and then checked out, reporting the progress of the checkout.
+func (g *GitWorktree) addWorktree(args ...string) error {
+	if len(g.sparseCheckout) == 0 {
+		_, err := g.runGitCommand(g.repoPath, append([]string{"worktree", "add"}, args...)...)
+		return err
+	}
+
+	if _, err := g.runGitCommand(g.repoPath, append([]string{"worktree", "add", "--no-checkout"}, args...)...); err != nil {
+		return err
+	}
+	g.reportProgress("Setting up sparse checkout...")
+	if _, err := g.runGitCommand(g.worktreePath, append([]string{"sparse-checkout", "set", "--cone"}, g.sparseCheckout...)...); err != nil {
+		_, _ = g.runGitCommand(g.repoPath, "worktree", "remove", "-f", g.worktreePath)
+		return fmt.Errorf("failed to set sparse checkout directories: %w", err)
+	}
+	g.reportProgress("Checking out files...")
+	if err := g.runGitWithProgress(g.worktreePath, "checkout", "--progress"); err != nil {
+		_, _ = g.runGitCommand(g.repoPath, "worktree", "remove", "-f", g.worktreePath)
+		return fmt.Errorf("failed to check out files: %w", err)
+	}
+	return nil
+}
+
+// runGitWithProgress runs a git command in the given path and reports the
+// progress it writes, like "Updating files:  40% (400/1000)", through the
+// progress callback.
+func (g *GitWorktree) runGitWithProgress(path string, args ...string) error {
+	cmd := exec.Command("git", append([]string{"-C", path}, args...)...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	var output strings.Builder
+	scanner := bufio.NewScanner(stderr)
+	scanner.Split(scanProgressLines)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		output.WriteString(line + "\n")
+		g.reportProgress(line)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git command failed: %s (%w)", output.String(), err)
+	}
+	return nil
+}
+
+// scanProgressLines is a bufio.SplitFunc that splits on carriage returns as
+// well as newlines, as git redraws its progress lines with carriage returns.
+func scanProgressLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
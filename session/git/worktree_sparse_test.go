@@ -0,0 +1,94 @@
+package git
+
+import (
+	"bufio"
+	"claude-squad/config"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanProgressLines(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("Updating files:  50% (1/2)\rUpdating files: 100% (2/2), done.\nwarning: x"))
+	scanner.Split(scanProgressLines)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	want := []string{"Updating files:  50% (1/2)", "Updating files: 100% (2/2), done.", "warning: x"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
+
+func TestSetupSparseCheckout(t *testing.T) {
+	g := setupTestWorktree(t)
+	for path, content := range map[string]string{
+		"README":       "top",
+		"app/main.go":  "package main",
+		"lib/lib.go":   "package lib",
+		"docs/guide":   "guide",
+		"app/sub/file": "nested",
+	} {
+		full := filepath.Join(g.worktreePath, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"add", "."},
+		{"commit", "-q", "-m", "files"},
+	} {
+		if _, err := runGit(g.worktreePath, args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	if _, err := runGit(g.repoPath, "reset", "-q", "--hard", "test/branch"); err != nil {
+		t.Fatal(err)
+	}
+	repoConfig := filepath.Join(g.repoPath, config.RepoConfigFileName)
+	if err := os.WriteFile(repoConfig, []byte("sparse_checkout:\n  - app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	worktree := NewGitWorktreeFromStorage(g.repoPath, filepath.Join(t.TempDir(), "sparse"), "sparse", "test/sparse", "")
+	var progress []string
+	worktree.SetProgressCallback(func(message string) {
+		progress = append(progress, message)
+	})
+	if err := worktree.Setup(); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	for path, want := range map[string]bool{
+		"README":       true,
+		"app/main.go":  true,
+		"app/sub/file": true,
+		"lib/lib.go":   false,
+		"docs/guide":   false,
+	} {
+		_, err := os.Stat(filepath.Join(worktree.GetWorktreePath(), path))
+		if got := err == nil; got != want {
+			t.Errorf("%s checked out = %v, want %v", path, got, want)
+		}
+	}
+	if status, err := runGit(worktree.GetWorktreePath(), "status", "--porcelain", "--untracked-files=no"); err != nil || strings.TrimSpace(status) != "" {
+		t.Errorf("worktree status = %q (%v), want it clean", status, err)
+	}
+	if !strings.Contains(strings.Join(progress, "\n"), "Checking out files...") {
+		t.Errorf("progress = %q, want the checkout reported", progress)
+	}
+
+	size, err := worktree.checkoutSize("HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(len("top") + len("package main") + len("nested")); size != want {
+		t.Errorf("checkoutSize() = %d, want %d", size, want)
+	}
+}
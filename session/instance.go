@@ -1,18 +1,24 @@
 package session
 
 import (
+	"claude-squad/audit"
 	"claude-squad/config"
 	"claude-squad/log"
 	"claude-squad/session/git"
 	"claude-squad/session/wordgen"
 	"claude-squad/session/zellij"
+	"context"
 	"errors"
 	"path/filepath"
 
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 
 	"github.com/atotto/clipboard"
 )
@@ -28,8 +34,29 @@ const (
 	Loading
 	// Paused is if the instance is paused (worktree removed but branch preserved).
 	Paused
+	// Pending is if the instance waits for a free slot to be started, see
+	// config.Config.MaxRunning. Pending instances have not been started.
+	Pending
 )
 
+// String returns a human-readable name for the status.
+func (s Status) String() string {
+	switch s {
+	case Running:
+		return "running"
+	case Ready:
+		return "ready"
+	case Loading:
+		return "loading"
+	case Paused:
+		return "paused"
+	case Pending:
+		return "pending"
+	default:
+		return "unknown"
+	}
+}
+
 // Instance is a running instance of claude code.
 type Instance struct {
 	// Title is the title of the instance.
@@ -54,19 +81,105 @@ type Instance struct {
 	LastOpenedAt *time.Time
 	// AutoYes is true if the instance should automatically press enter when prompted.
 	AutoYes bool
-	// Prompt is the initial prompt to pass to the instance on startup
+	// Prompt is the initial prompt to pass to the instance on startup. It holds
+	// the prompts sent to a pending instance until it is started.
 	Prompt string
 	// Archived is true if the instance has been archived (hidden but not deleted).
 	Archived bool
+	// Scratch is true for ephemeral sessions run directly in a plain directory,
+	// without a git worktree or branch.
+	Scratch bool
+	// DeletedAt is set when the instance has been killed but its cleanup is deferred
+	// so the kill can still be undone. Tombstoned instances are hidden and never started.
+	DeletedAt *time.Time
+	// Notes are free-form notes attached to the instance by the user.
+	Notes string
+	// Tags are short labels (e.g. "blocked", "backend") used to group and filter instances.
+	Tags []string
+	// StatusHistory records the most recent status changes, oldest first.
+	StatusHistory []StatusChange
+	// AutoYesEvents records when auto-yes answered a prompt, oldest first.
+	AutoYesEvents []time.Time
+	// PushEvents records when the branch was pushed or landed, oldest first.
+	PushEvents []time.Time
+	// Reviewer is the title of the instance reviewing this instance's changes.
+	Reviewer string
+	// ReviewStatus is the verdict of the reviewer.
+	ReviewStatus ReviewStatus
+	// ReviewRequestedAt is when the last review was requested from the reviewer.
+	ReviewRequestedAt time.Time
+	// ReviewOf is the title of the instance whose changes this instance reviews.
+	ReviewOf string
+	// LandState is the state of the instance in the merge queue.
+	LandState LandState
+	// LandQueuedAt is when the instance was marked as ready to land.
+	LandQueuedAt time.Time
+	// LandError describes why landing failed.
+	LandError string
+	// LastCheck is the result of the last run of the check command.
+	LastCheck *CheckResult
+	// BoardColumn is the column the instance was moved to on the board, empty
+	// if its card follows its state.
+	BoardColumn BoardColumn
+	// Owner is the identity of the person who created the instance, empty if no
+	// identity was set.
+	Owner string
+	// QuarantinedAt is set when the instance was quarantined after suspicious
+	// activity, see Quarantine. QuarantineReason is what triggered it.
+	QuarantinedAt    *time.Time
+	QuarantineReason string
+	// RateLimitedUntil is set when the agent hit a rate limit, see
+	// MarkRateLimited. LastPrompt is retried once it has passed.
+	RateLimitedUntil *time.Time
+	// LastPrompt is the last prompt sent to the instance.
+	LastPrompt string
+	// rateLimitCheckedAt is when the pane was last searched for a rate limit
+	// message, and rateLimitTail the bottom of the pane where one was last
+	// found. Not persisted.
+	rateLimitCheckedAt time.Time
+	rateLimitTail      string
+	// lastPolledAt is when the pane was last polled for changes and
+	// lastChangedAt when it last changed, see PollPolicy. Not persisted.
+	lastPolledAt  time.Time
+	lastChangedAt time.Time
+	// sharedChangedAt is when the pane last changed according to the poll
+	// cache, see PollCache. Not persisted.
+	sharedChangedAt time.Time
+	// updating is set while ParallelUpdate checks the instance, which may
+	// outlast its timeout when a command hangs. Not persisted.
+	updating atomic.Bool
+	// PausedScreen is the last screen of the session before it was paused. It is
+	// shown in the preview after resuming until the session draws again.
+	PausedScreen string
+	// checkRunning is true while the check command runs. Not persisted.
+	checkRunning bool
 
 	// DiffStats stores the current git diff statistics
 	diffStats *git.DiffStats
+	// baseStatus is how far the branch is behind the default branch, checked
+	// at baseCheckedAt. Not persisted.
+	baseStatus    *git.BaseStatus
+	baseCheckedAt time.Time
+	// ciStatus is the state of the checks on the pushed branch, polled at
+	// ciCheckedAt. It is stored by the background poll. Not persisted.
+	ciStatus    atomic.Pointer[git.CIStatus]
+	ciCheckedAt time.Time
 
 	// Summary is a short AI-generated description of the current session state
 	Summary string
 	// SummaryUpdatedAt is when the summary was last updated
 	SummaryUpdatedAt time.Time
 
+	// snippets holds notable output blocks captured from the session. Not persisted.
+	snippets SnippetHistory
+
+	// transcript is the last summary of the Claude session transcript, read when
+	// the transcript file was last modified at transcriptModTime. Not persisted.
+	// It is read by the summarizer in the background, so transcriptMu guards it.
+	transcript        *TranscriptSummary
+	transcriptModTime time.Time
+	transcriptMu      sync.Mutex
+
 	// Background diff calculation timing
 	lastDiffUpdate time.Time // When diff was last calculated
 	lastActivity   time.Time // When instance status last changed
@@ -75,7 +188,7 @@ type Instance struct {
 	// This is captured from Claude's project files after Claude starts.
 	ClaudeSessionID string
 
-	// SessionType indicates the type of session: "zellij", "docker-bind", or "docker-clone"
+	// SessionType indicates the type of session: "zellij", "docker-bind", "docker-clone" or "native"
 	SessionType string
 	// DockerContainerID is the Docker container ID for Docker sessions
 	DockerContainerID string
@@ -85,17 +198,56 @@ type Instance struct {
 	DockerBaseImage string
 	// RandomSuffix is the random word pair suffix for this instance
 	RandomSuffix string
+	// Env holds the environment variables the program is started with
+	Env map[string]string
+	// Issue is the GitHub issue the instance was created for, if any
+	Issue *IssueLink
+	// AuxCommand is run next to the program in a pane of its own, e.g. a dev
+	// server the agent works against. Empty if there is none.
+	AuxCommand string
 
 	// The below fields are initialized upon calling Start().
 
 	started bool
+	// restorePending is true for a loaded instance whose session is only started
+	// once Restore is called, see FromInstanceDataDeferred. It is read while a
+	// restore is in progress.
+	restorePending atomic.Bool
+	restoreMu      sync.Mutex
+	// restoreErr is why the last call to Restore failed, if it did. It is read
+	// without waiting for a restore in progress.
+	restoreErr atomic.Pointer[error]
 	// session is the multiplexer session for the instance.
 	session Multiplexer
+	// reconnect tracks reconnecting to the session after its connection was lost
+	reconnect reconnectState
+	// sizeEpoch changes whenever the session may have lost the size it was
+	// given, see SizeEpoch.
+	sizeEpoch atomic.Uint64
 	// multiplexerType is the type of multiplexer used for this instance.
 	// Deprecated: Use SessionType instead.
 	multiplexerType MultiplexerType
 	// gitWorktree is the git worktree for the instance.
 	gitWorktree *git.GitWorktree
+	// installCommands install the dependencies when the instance is first
+	// started, see SetInstallCommands. installErr is why they failed.
+	installCommands []string
+	installErr      error
+	// contextFile, contextTemplate and contextPrompt are from InstanceOptions.
+	// contextPrompt is set by the first prompt if there was none. Not persisted.
+	contextFile     string
+	contextTemplate string
+	contextPrompt   string
+	// contextWritten is true if the context file was written for the instance. Not persisted.
+	contextWritten bool
+	// baseBranch is from InstanceOptions. Not persisted.
+	baseBranch string
+	// diffRef is the ref the diff is computed against, the base commit if empty. Not persisted.
+	diffRef string
+	// pendingPrompt is an excerpt of the prompt the agent is waiting on. Not persisted.
+	pendingPrompt string
+	// auxErr is why the auxiliary command could not be started. Not persisted.
+	auxErr error
 }
 
 // ToInstanceData converts an Instance to its serializable form
@@ -111,8 +263,31 @@ func (i *Instance) ToInstanceData() InstanceData {
 		UpdatedAt:         time.Now(),
 		LastOpenedAt:      i.LastOpenedAt,
 		Program:           i.Program,
+		Prompt:            i.Prompt,
 		AutoYes:           i.AutoYes,
 		Archived:          i.Archived,
+		DeletedAt:         i.DeletedAt,
+		Scratch:           i.Scratch,
+		Notes:             i.Notes,
+		Tags:              i.Tags,
+		StatusHistory:     i.StatusHistory,
+		AutoYesEvents:     i.AutoYesEvents,
+		PushEvents:        i.PushEvents,
+		Reviewer:          i.Reviewer,
+		ReviewStatus:      i.ReviewStatus,
+		ReviewRequestedAt: i.ReviewRequestedAt,
+		ReviewOf:          i.ReviewOf,
+		LandState:         i.LandState,
+		LandQueuedAt:      i.LandQueuedAt,
+		LandError:         i.LandError,
+		LastCheck:         i.LastCheck,
+		PausedScreen:      i.PausedScreen,
+		BoardColumn:       i.BoardColumn,
+		Owner:             i.Owner,
+		QuarantinedAt:     i.QuarantinedAt,
+		QuarantineReason:  i.QuarantineReason,
+		RateLimitedUntil:  i.RateLimitedUntil,
+		LastPrompt:        i.LastPrompt,
 		Multiplexer:       string(i.multiplexerType),
 		Summary:           i.Summary,
 		SummaryUpdatedAt:  i.SummaryUpdatedAt,
@@ -120,7 +295,11 @@ func (i *Instance) ToInstanceData() InstanceData {
 		SessionType:       i.SessionType,
 		DockerContainerID: i.DockerContainerID,
 		DockerRepoURL:     i.DockerRepoURL,
+		DockerBaseImage:   i.DockerBaseImage,
+		AuxCommand:        i.AuxCommand,
 		RandomSuffix:      i.RandomSuffix,
+		Env:               i.Env,
+		Issue:             i.Issue,
 	}
 
 	// Only include worktree data if gitWorktree is initialized
@@ -137,9 +316,12 @@ func (i *Instance) ToInstanceData() InstanceData {
 	// Only include diff stats if they exist
 	if i.diffStats != nil {
 		data.DiffStats = DiffStatsData{
-			Added:   i.diffStats.Added,
-			Removed: i.diffStats.Removed,
-			Content: i.diffStats.Content,
+			Added:    i.diffStats.Added,
+			Removed:  i.diffStats.Removed,
+			Content:  i.diffStats.Content,
+			Unpushed: i.diffStats.Unpushed,
+			Pushed:   i.diffStats.Pushed,
+			Behind:   i.diffStats.Behind,
 		}
 	}
 
@@ -210,6 +392,16 @@ func NewInstanceFromOrphan(orphan *zellij.OrphanedSession) (*Instance, error) {
 
 // FromInstanceData creates a new Instance from serialized data
 func FromInstanceData(data InstanceData) (*Instance, error) {
+	return fromInstanceData(data, false)
+}
+
+// FromInstanceDataDeferred creates a new Instance from serialized data without
+// starting its session. Call Restore to start it when it is needed.
+func FromInstanceDataDeferred(data InstanceData) (*Instance, error) {
+	return fromInstanceData(data, true)
+}
+
+func fromInstanceData(data InstanceData, deferRestore bool) (*Instance, error) {
 	// For backwards compatibility, default to zellij if no session type
 	sessionType := data.SessionType
 	if sessionType == "" {
@@ -228,24 +420,54 @@ func FromInstanceData(data InstanceData) (*Instance, error) {
 		UpdatedAt:         data.UpdatedAt,
 		LastOpenedAt:      data.LastOpenedAt,
 		Program:           data.Program,
+		Prompt:            data.Prompt,
 		Archived:          data.Archived,
+		DeletedAt:         data.DeletedAt,
+		Scratch:           data.Scratch,
+		Notes:             data.Notes,
+		Tags:              data.Tags,
+		StatusHistory:     data.StatusHistory,
+		AutoYesEvents:     data.AutoYesEvents,
+		PushEvents:        data.PushEvents,
+		Reviewer:          data.Reviewer,
+		ReviewStatus:      data.ReviewStatus,
+		ReviewRequestedAt: data.ReviewRequestedAt,
+		ReviewOf:          data.ReviewOf,
+		LandState:         data.LandState,
+		LandQueuedAt:      data.LandQueuedAt,
+		LandError:         data.LandError,
+		LastCheck:         data.LastCheck,
+		PausedScreen:      data.PausedScreen,
+		BoardColumn:       data.BoardColumn,
+		Owner:             data.Owner,
+		QuarantinedAt:     data.QuarantinedAt,
+		QuarantineReason:  data.QuarantineReason,
+		RateLimitedUntil:  data.RateLimitedUntil,
+		LastPrompt:        data.LastPrompt,
 		Summary:           data.Summary,
 		SummaryUpdatedAt:  data.SummaryUpdatedAt,
 		ClaudeSessionID:   data.ClaudeSessionID,
 		SessionType:       sessionType,
 		DockerContainerID: data.DockerContainerID,
 		DockerRepoURL:     data.DockerRepoURL,
+		DockerBaseImage:   data.DockerBaseImage,
+		AuxCommand:        data.AuxCommand,
 		RandomSuffix:      data.RandomSuffix,
+		Env:               data.Env,
+		Issue:             data.Issue,
 		multiplexerType:   mtype,
 		diffStats: &git.DiffStats{
-			Added:   data.DiffStats.Added,
-			Removed: data.DiffStats.Removed,
-			Content: data.DiffStats.Content,
+			Added:    data.DiffStats.Added,
+			Removed:  data.DiffStats.Removed,
+			Content:  data.DiffStats.Content,
+			Unpushed: data.DiffStats.Unpushed,
+			Pushed:   data.DiffStats.Pushed,
+			Behind:   data.DiffStats.Behind,
 		},
 	}
 
-	// For Docker clone mode, we may not have a worktree
-	if data.Worktree.WorktreePath != "" || sessionType != config.SessionTypeDockerClone {
+	// For Docker clone mode we may not have a worktree; scratch sessions never do
+	if !data.Scratch && (data.Worktree.WorktreePath != "" || sessionType != config.SessionTypeDockerClone) {
 		instance.gitWorktree = git.NewGitWorktreeFromStorage(
 			data.Worktree.RepoPath,
 			data.Worktree.WorktreePath,
@@ -255,18 +477,27 @@ func FromInstanceData(data InstanceData) (*Instance, error) {
 		)
 	}
 
-	if instance.Paused() || instance.Archived {
+	// Pending instances are started by the scheduler when a slot frees
+	if instance.Status == Pending {
+		return instance, nil
+	}
+
+	if instance.Paused() || instance.Archived || instance.Tombstoned() {
 		instance.started = true
 		// Create session based on session type
 		sessionName := data.Title
 		if instance.gitWorktree != nil {
 			sessionName = instance.gitWorktree.GetSessionName()
 		}
-		instance.session = NewMultiplexer(sessionType, sessionName, instance.Program, MultiplexerOptions{
+		instance.session = NewMultiplexer(sessionType, sessionName, instance.expandProgram(), MultiplexerOptions{
 			BaseImage:  instance.DockerBaseImage,
 			RepoURL:    instance.DockerRepoURL,
 			BranchName: instance.Branch,
+			Env:        instance.Env,
+			Mounts:     instance.containerMounts(),
 		})
+	} else if deferRestore {
+		instance.restorePending.Store(true)
 	} else {
 		if err := instance.Start(false); err != nil {
 			return nil, err
@@ -276,6 +507,40 @@ func FromInstanceData(data InstanceData) (*Instance, error) {
 	return instance, nil
 }
 
+// RestorePending returns true if the instance's session has not been restored yet.
+func (i *Instance) RestorePending() bool {
+	return i.restorePending.Load()
+}
+
+// Restore starts the session of an instance loaded with deferred restoration.
+// It does nothing if the instance has already been restored.
+func (i *Instance) Restore() error {
+	i.restoreMu.Lock()
+	defer i.restoreMu.Unlock()
+
+	if !i.restorePending.Load() {
+		return nil
+	}
+	if err := i.Start(false); err != nil {
+		err = fmt.Errorf("failed to restore session %s: %w", i.Title, err)
+		i.restoreErr.Store(&err)
+		return err
+	}
+	i.restorePending.Store(false)
+	i.restoreErr.Store(nil)
+	i.sizeEpoch.Add(1)
+	return nil
+}
+
+// RestoreError returns why restoring the instance's session failed, or nil if
+// it hasn't failed.
+func (i *Instance) RestoreError() error {
+	if err := i.restoreErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
 // Options for creating a new instance
 type InstanceOptions struct {
 	// Title is the title of the instance.
@@ -295,6 +560,23 @@ type InstanceOptions struct {
 	DockerBaseImage string
 	// DockerRepoURL is the git repo URL for docker-clone mode
 	DockerRepoURL string
+	// Scratch creates an ephemeral session in Path itself, without a git worktree.
+	Scratch bool
+	// Owner is the identity of the person creating the instance.
+	Owner string
+	// ContextFile is the name of the file written into the new worktree before
+	// the program starts, rendered from ContextTemplate (see RenderContext).
+	// No file is written when empty.
+	ContextFile     string
+	ContextTemplate string
+	// Prompt is the task the instance starts with, for the context file.
+	Prompt string
+	// BaseBranch is the branch the new worktree starts from instead of HEAD.
+	BaseBranch string
+	// Env holds environment variables to start the program with.
+	Env map[string]string
+	// AuxCommand is run next to the program, see Instance.AuxCommand.
+	AuxCommand string
 }
 
 func NewInstance(opts InstanceOptions) (*Instance, error) {
@@ -312,6 +594,10 @@ func NewInstance(opts InstanceOptions) (*Instance, error) {
 		log.ErrorLog.Printf("failed to generate random suffix, continuing without suffix")
 	}
 
+	if err := ValidateEnv(opts.Env); err != nil {
+		return nil, err
+	}
+
 	// Default to zellij if no session type specified
 	sessionType := opts.SessionType
 	if sessionType == "" {
@@ -336,6 +622,14 @@ func NewInstance(opts InstanceOptions) (*Instance, error) {
 		SessionType:     sessionType,
 		DockerBaseImage: opts.DockerBaseImage,
 		DockerRepoURL:   opts.DockerRepoURL,
+		Scratch:         opts.Scratch,
+		Owner:           opts.Owner,
+		contextFile:     opts.ContextFile,
+		contextTemplate: opts.ContextTemplate,
+		contextPrompt:   opts.Prompt,
+		baseBranch:      opts.BaseBranch,
+		Env:             opts.Env,
+		AuxCommand:      opts.AuxCommand,
 	}, nil
 }
 
@@ -343,19 +637,32 @@ func (i *Instance) RepoName() (string, error) {
 	if !i.started {
 		return "", fmt.Errorf("cannot get repo name for instance that has not been started")
 	}
+	if i.gitWorktree == nil {
+		// Scratch and docker-clone sessions have no local repo; use the directory name
+		return filepath.Base(i.Path), nil
+	}
 	return i.gitWorktree.GetRepoName(), nil
 }
 
 // GetSessionName returns the session name with random suffix.
 // This is the immutable identifier used for git branches and multiplexer sessions.
 func (i *Instance) GetSessionName() string {
-	if i.RandomSuffix == "" {
-		return i.Title // Backward compatibility
+	return sessionNameFor(i.Title, i.RandomSuffix)
+}
+
+// sessionNameFor returns the session name of an instance with the given title
+// and random suffix.
+func sessionNameFor(title, suffix string) string {
+	if suffix == "" {
+		return title // Backward compatibility
 	}
-	return fmt.Sprintf("%s_%s", i.Title, i.RandomSuffix)
+	return fmt.Sprintf("%s_%s", title, suffix)
 }
 
 func (i *Instance) SetStatus(status Status) {
+	if status != i.Status || len(i.StatusHistory) == 0 {
+		i.recordStatusChange(status, time.Now())
+	}
 	i.Status = status
 	i.lastActivity = time.Now()
 }
@@ -387,7 +694,10 @@ func (i *Instance) startInternal(firstTimeSetup bool, progressCallback git.Progr
 	// For Docker clone mode, we skip worktree setup (repo is cloned inside container)
 	isDockerClone := i.SessionType == config.SessionTypeDockerClone
 
-	if firstTimeSetup && !isDockerClone {
+	if firstTimeSetup && i.Scratch {
+		// Scratch sessions run directly in the chosen directory, with no branch
+		i.Branch = ""
+	} else if firstTimeSetup && !isDockerClone {
 		// Create git worktree for Zellij and Docker bind-mount modes
 		gitWorktree, branchName, err := git.NewGitWorktree(i.Path, i.GetSessionName())
 		if err != nil {
@@ -395,6 +705,9 @@ func (i *Instance) startInternal(firstTimeSetup bool, progressCallback git.Progr
 		}
 		i.gitWorktree = gitWorktree
 		i.Branch = branchName
+		if i.baseBranch != "" {
+			i.gitWorktree.SetBaseBranch(i.baseBranch)
+		}
 		// Set progress callback if provided
 		if progressCallback != nil {
 			i.gitWorktree.SetProgressCallback(progressCallback)
@@ -406,33 +719,18 @@ func (i *Instance) startInternal(firstTimeSetup bool, progressCallback git.Progr
 	}
 
 	// Create the multiplexer session
-	var session Multiplexer
-	if i.session != nil {
-		// Use existing session (useful for testing)
-		session = i.session
-	} else {
-		// Determine session name (includes random suffix)
-		sessionName := i.GetSessionName()
-		if i.gitWorktree != nil {
-			// Use gitWorktree's session name for consistency
-			sessionName = i.gitWorktree.GetSessionName()
-		}
-
-		// Create new session using factory
-		session = NewMultiplexer(i.SessionType, sessionName, i.Program, MultiplexerOptions{
-			BaseImage:  i.DockerBaseImage,
-			RepoURL:    i.DockerRepoURL,
-			BranchName: i.Branch,
-			WorkDir:    i.Path,
-		})
+	session := i.session
+	if session == nil {
+		session = i.newSession()
 	}
 	i.session = session
 
-	// Setup error handler to cleanup resources on any error
+	// Setup error handler to cleanup resources on any error. A failed restore
+	// keeps them, so the work of the previous run isn't lost.
 	var setupErr error
 	defer func() {
-		if setupErr != nil {
-			if cleanupErr := i.Kill(); cleanupErr != nil {
+		if setupErr != nil && firstTimeSetup {
+			if cleanupErr := i.Kill(0); cleanupErr != nil {
 				setupErr = fmt.Errorf("%v (cleanup error: %v)", setupErr, cleanupErr)
 			}
 		} else {
@@ -456,6 +754,19 @@ func (i *Instance) startInternal(firstTimeSetup bool, progressCallback git.Progr
 			}
 		}
 
+		if i.gitWorktree != nil && i.contextFile != "" {
+			if err := i.writeContextFile(); err != nil {
+				setupErr = fmt.Errorf("failed to write context file: %w", err)
+				return setupErr
+			}
+		}
+
+		// Install dependencies before the program starts, so it doesn't fail on
+		// missing ones. A failed install is reported but doesn't stop the instance.
+		if i.gitWorktree != nil && len(i.installCommands) > 0 {
+			i.installErr = i.installDependencies(i.gitWorktree.GetWorktreePath(), progressCallback)
+		}
+
 		// Report progress for session start
 		if progressCallback != nil {
 			progressCallback("Starting terminal session...")
@@ -469,42 +780,46 @@ func (i *Instance) startInternal(firstTimeSetup bool, progressCallback git.Progr
 
 		// Create new session
 		if err := i.session.Start(workDir); err != nil {
-			// Cleanup git worktree if session creation fails
-			if i.gitWorktree != nil {
-				if cleanupErr := i.gitWorktree.Cleanup(); cleanupErr != nil {
-					err = fmt.Errorf("%v (cleanup error: %v)", err, cleanupErr)
-				}
-			}
 			setupErr = fmt.Errorf("failed to start new session: %w", err)
 			return setupErr
 		}
 	}
+	i.startAux()
 
 	i.SetStatus(Running)
 
 	return nil
 }
 
-// Kill terminates the instance and cleans up all resources
-func (i *Instance) Kill() error {
-	if !i.started {
-		// If instance was never started, just return success
-		return nil
-	}
-
+// Kill terminates the instance and cleans up all resources. If trashRetentionDays
+// is positive, the work is moved to the trash instead of deleted, see
+// config.TrashRetentionDays. The worktree is left in place if that fails.
+func (i *Instance) Kill(trashRetentionDays int) error {
 	var errs []error
 
 	// Always try to cleanup both resources, even if one fails
-	// Clean up session first since it's using the git worktree
-	if i.session != nil {
-		if err := i.session.Close(); err != nil {
+	// Clean up session first since it's using the git worktree. An instance
+	// whose start failed, or whose restore is pending or failed, may still
+	// have a session, from the start or the previous run.
+	session := i.session
+	if session == nil && i.restorePending.Load() {
+		session = i.newSession()
+	}
+	if session != nil && (i.started || session.DoesSessionExist()) {
+		if err := session.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to close session: %w", err))
 		}
 	}
 
-	// Then clean up git worktree
-	if i.gitWorktree != nil {
-		if err := i.gitWorktree.Cleanup(); err != nil {
+	// Then clean up git worktree, keeping the branch in the trash if configured.
+	// The worktree of an instance that never started only exists if its setup
+	// got that far; its branch may not be its own yet.
+	if i.gitWorktree != nil && (i.started || i.restorePending.Load() || i.worktreeExists()) {
+		if trashRetentionDays > 0 {
+			if _, err := i.gitWorktree.MoveToTrash(i.Title, i.Owner); err != nil {
+				errs = append(errs, fmt.Errorf("failed to move git worktree to trash: %w", err))
+			}
+		} else if err := i.gitWorktree.Cleanup(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to cleanup git worktree: %w", err))
 		}
 	}
@@ -512,6 +827,38 @@ func (i *Instance) Kill() error {
 	return i.combineErrors(errs)
 }
 
+// worktreeExists returns true if the instance's worktree directory exists.
+func (i *Instance) worktreeExists() bool {
+	_, err := os.Stat(i.gitWorktree.GetWorktreePath())
+	return err == nil
+}
+
+// newSession creates the multiplexer session of the instance, without starting it.
+func (i *Instance) newSession() Multiplexer {
+	// Determine session name (includes random suffix)
+	sessionName := i.GetSessionName()
+	if i.gitWorktree != nil {
+		// Use gitWorktree's session name for consistency
+		sessionName = i.gitWorktree.GetSessionName()
+	}
+
+	// Native sessions start the program again in the worktree when restored
+	workDir := i.Path
+	if i.gitWorktree != nil {
+		workDir = i.gitWorktree.GetWorktreePath()
+	}
+
+	// Create new session using factory
+	return NewMultiplexer(i.SessionType, sessionName, i.expandProgram(), MultiplexerOptions{
+		BaseImage:  i.DockerBaseImage,
+		RepoURL:    i.DockerRepoURL,
+		BranchName: i.Branch,
+		WorkDir:    workDir,
+		Env:        i.Env,
+		Mounts:     i.containerMounts(),
+	})
+}
+
 // combineErrors combines multiple errors into a single error
 func (i *Instance) combineErrors(errs []error) error {
 	if len(errs) == 0 {
@@ -536,20 +883,37 @@ func (i *Instance) Preview() (string, error) {
 }
 
 func (i *Instance) HasUpdated() (updated bool, hasPrompt bool) {
+	return i.hasUpdated(context.Background())
+}
+
+// hasUpdated is HasUpdated, stopping the commands of sessions polled through
+// them once ctx is done.
+func (i *Instance) hasUpdated(ctx context.Context) (updated bool, hasPrompt bool) {
 	if !i.started {
 		return false, false
 	}
+	if poller, ok := i.session.(ContextPoller); ok {
+		return poller.HasUpdatedContext(ctx)
+	}
 	return i.session.HasUpdated()
 }
 
-// TapEnter sends an enter key press to the session if AutoYes is enabled.
+// TapEnter accepts the prompt the agent is waiting on if AutoYes is enabled,
+// with the keys configured for the program, enter by default.
 func (i *Instance) TapEnter() {
-	if !i.started || !i.AutoYes {
+	// Auto-yes is turned on again for every instance when the program runs with
+	// it, so quarantine is checked here as well
+	if !i.started || !i.AutoYes || i.Quarantined() {
 		return
 	}
-	if err := i.session.TapEnter(); err != nil {
-		log.ErrorLog.Printf("error tapping enter: %v", err)
+	if err := i.sendPromptKeys(promptKeys(i.Program, PromptAccept)); err != nil {
+		log.ErrorLog.Printf("error accepting prompt: %v", err)
+		return
 	}
+	now := time.Now()
+	i.recordAutoYes(now)
+	audit.Record(audit.EventAutoYes, i.Title, "")
+	i.checkAutoYesBurst(now)
 }
 
 func (i *Instance) Attach() (chan struct{}, error) {
@@ -559,9 +923,19 @@ func (i *Instance) Attach() (chan struct{}, error) {
 	// Track when the user last opened this instance
 	now := time.Now()
 	i.LastOpenedAt = &now
+	i.markActive()
+	// Attaching gives the session the size of the terminal
+	i.sizeEpoch.Add(1)
 	return i.session.Attach()
 }
 
+// SizeEpoch changes whenever the session may have lost the size given with
+// SetPreviewSize: when it's attached, restarted, resumed, restored or
+// reconnected.
+func (i *Instance) SizeEpoch() uint64 {
+	return i.sizeEpoch.Load()
+}
+
 func (i *Instance) SetPreviewSize(width, height int) error {
 	if !i.started || i.Status == Paused {
 		return fmt.Errorf("cannot set preview size for instance that has not been started or " +
@@ -594,14 +968,52 @@ func (i *Instance) SetTitle(title string) error {
 
 // Rename changes the display title of the instance. Unlike SetTitle, this can be called
 // after the instance has started. Note that this only changes the display name - the
-// underlying session name and git worktree path remain unchanged.
+// underlying session name and git worktree path remain unchanged. See RenameAll.
 func (i *Instance) Rename(newTitle string) error {
-	if newTitle == "" {
-		return fmt.Errorf("title cannot be empty")
+	if err := validateTitle(newTitle); err != nil {
+		return err
 	}
-	if len(newTitle) > 32 {
-		return fmt.Errorf("title cannot be longer than 32 characters")
+	i.Title = newTitle
+	i.UpdatedAt = time.Now()
+	return nil
+}
+
+// RenameAll renames the instance like Rename, and also renames its git branch,
+// moves its worktree and renames its multiplexer session to match the new title.
+// If a step fails, the steps already done are undone.
+func (i *Instance) RenameAll(newTitle string) error {
+	if err := validateTitle(newTitle); err != nil {
+		return err
+	}
+	if !i.started {
+		return fmt.Errorf("cannot rename the branch and session of an instance that has not started")
+	}
+
+	oldSessionName := i.GetSessionName()
+	if i.gitWorktree != nil {
+		oldSessionName = i.gitWorktree.GetSessionName()
+	}
+	newSessionName := sessionNameFor(newTitle, i.RandomSuffix)
+
+	renamer, renameSession := i.session.(Renamer)
+	if renameSession {
+		if err := renamer.Rename(newSessionName); err != nil {
+			return fmt.Errorf("failed to rename session: %w", err)
+		}
+	}
+
+	if i.gitWorktree != nil {
+		if err := i.gitWorktree.Rename(newSessionName); err != nil {
+			if renameSession {
+				if rollbackErr := renamer.Rename(oldSessionName); rollbackErr != nil {
+					err = fmt.Errorf("%v (rollback error: %v)", err, rollbackErr)
+				}
+			}
+			return err
+		}
+		i.Branch = i.gitWorktree.GetBranchName()
 	}
+
 	i.Title = newTitle
 	i.UpdatedAt = time.Now()
 	return nil
@@ -611,6 +1023,45 @@ func (i *Instance) Paused() bool {
 	return i.Status == Paused
 }
 
+// Tombstoned returns true if the instance has been killed and is waiting for deferred cleanup.
+func (i *Instance) Tombstoned() bool {
+	return i.DeletedAt != nil
+}
+
+// HasTag returns true if the instance is tagged with the given tag.
+func (i *Instance) HasTag(tag string) bool {
+	for _, t := range i.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// OwnedBy returns true if the instance was created by the identity. Without an
+// identity every instance counts as owned, since nobody is telling them apart.
+func (i *Instance) OwnedBy(identity string) bool {
+	return identity == "" || i.Owner == identity
+}
+
+// ParseTags splits a comma or space separated list of tags, normalizing them to
+// lower case and dropping duplicates.
+func ParseTags(input string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		tag := strings.ToLower(strings.TrimPrefix(field, "#"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
 // SessionAlive returns true if the multiplexer session is alive. This is a sanity check before attaching.
 func (i *Instance) SessionAlive() bool {
 	if i.session == nil {
@@ -627,6 +1078,9 @@ func (i *Instance) Pause() error {
 	if i.Status == Paused {
 		return fmt.Errorf("instance is already paused")
 	}
+	if i.gitWorktree == nil {
+		return fmt.Errorf("cannot pause instance without a git worktree")
+	}
 
 	var errs []error
 
@@ -645,6 +1099,16 @@ func (i *Instance) Pause() error {
 		}
 	}
 
+	// Keep the last screen to show after resuming, while the session starts up
+	if screen, err := i.session.CapturePaneContent(); err == nil {
+		i.PausedScreen = screen
+	} else {
+		log.WarningLog.Printf("failed to capture the screen of %s before pausing: %v", i.Title, err)
+	}
+
+	// The auxiliary command runs in the worktree that is about to be removed
+	i.stopAux()
+
 	// Detach from session instead of closing to preserve session output
 	if err := i.session.DetachSafely(); err != nil {
 		errs = append(errs, fmt.Errorf("failed to detach session: %w", err))
@@ -679,6 +1143,19 @@ func (i *Instance) Pause() error {
 	return nil
 }
 
+// CheckoutSnapshot checks out the instance's branch with its uncommitted
+// changes, detached, in the main repository without pausing the session. It
+// returns the checked out commit.
+func (i *Instance) CheckoutSnapshot() (string, error) {
+	if !i.started || i.Status == Paused {
+		return "", fmt.Errorf("can only check out running instances without pausing them")
+	}
+	if i.gitWorktree == nil {
+		return "", fmt.Errorf("cannot check out instance without a git worktree")
+	}
+	return i.gitWorktree.CheckoutSnapshot()
+}
+
 // Resume recreates the worktree and restarts the session
 func (i *Instance) Resume() error {
 	if !i.started {
@@ -687,6 +1164,9 @@ func (i *Instance) Resume() error {
 	if i.Status != Paused {
 		return fmt.Errorf("can only resume paused instances")
 	}
+	if i.gitWorktree == nil {
+		return fmt.Errorf("cannot resume instance without a git worktree")
+	}
 
 	// Check if branch is checked out
 	if checked, err := i.gitWorktree.IsBranchCheckedOut(); err != nil {
@@ -702,6 +1182,10 @@ func (i *Instance) Resume() error {
 		return fmt.Errorf("failed to setup git worktree: %w", err)
 	}
 
+	if i.session != nil && i.PausedScreen != "" {
+		i.session.LoadScreen(i.PausedScreen)
+	}
+
 	// Check if session still exists from pause, otherwise create new one
 	if i.session != nil && i.session.DoesSessionExist() {
 		// Session exists, just restore PTY connection to it
@@ -731,7 +1215,10 @@ func (i *Instance) Resume() error {
 		}
 	}
 
+	i.startAux()
+	i.PausedScreen = ""
 	i.SetStatus(Running)
+	i.sizeEpoch.Add(1)
 	return nil
 }
 
@@ -747,7 +1234,18 @@ func (i *Instance) UpdateDiffStats() error {
 		return nil
 	}
 
-	stats := i.gitWorktree.Diff()
+	if i.gitWorktree == nil {
+		// Nothing to diff against for scratch sessions
+		i.diffStats = nil
+		return nil
+	}
+
+	var stats *git.DiffStats
+	if i.diffRef != "" {
+		stats = i.gitWorktree.DiffAgainst(i.diffRef)
+	} else {
+		stats = i.gitWorktree.Diff()
+	}
 	if stats.Error != nil {
 		if strings.Contains(stats.Error.Error(), "base commit SHA not set") {
 			// Worktree is not fully set up yet, not an error
@@ -767,6 +1265,80 @@ func (i *Instance) GetDiffStats() *git.DiffStats {
 	return i.diffStats
 }
 
+// DiffRef returns the ref the diff is computed against, or an empty string for
+// the base commit.
+func (i *Instance) DiffRef() string {
+	return i.diffRef
+}
+
+// SetDiffRef changes the ref the diff is computed against and recomputes the
+// diff. An empty ref compares with the base commit. If the diff can't be
+// computed, e.g. because the ref doesn't exist, the previous ref is kept.
+func (i *Instance) SetDiffRef(ref string) error {
+	if !i.started || i.gitWorktree == nil {
+		return fmt.Errorf("session '%s' has no worktree to diff", i.Title)
+	}
+	previous := i.diffRef
+	i.diffRef = ref
+	if err := i.UpdateDiffStats(); err != nil {
+		i.diffRef = previous
+		return err
+	}
+	return nil
+}
+
+// DefaultBranch returns the name of the default branch of the instance's repository.
+func (i *Instance) DefaultBranch() (string, error) {
+	if i.gitWorktree == nil {
+		return "", fmt.Errorf("session '%s' has no worktree", i.Title)
+	}
+	return i.gitWorktree.DefaultBranch()
+}
+
+// HookStatus returns the status reported by the Claude hooks installed in the
+// worktree. ok is false if no hook has fired yet, e.g. for programs other than
+// Claude, in which case the status has to be detected from the pane content.
+func (i *Instance) HookStatus() (status Status, ok bool) {
+	if !i.started || i.gitWorktree == nil {
+		return 0, false
+	}
+	event, err := i.gitWorktree.ReadHookEvent()
+	if err != nil {
+		log.WarningLog.Printf("could not read hook status for %s: %v", i.Title, err)
+		return 0, false
+	}
+	switch event {
+	case git.HookEventUserPromptSubmit, git.HookEventPreToolUse:
+		return Running, true
+	case git.HookEventStop, git.HookEventNotification:
+		return Ready, true
+	}
+	return 0, false
+}
+
+// RenderDiff renders the diff of the instance computed against ref (see DiffRef)
+// with an external diff renderer. The diff and ref are passed in so it can run in
+// the background while the instance changes.
+func (i *Instance) RenderDiff(renderer, ref, diff string, width int) (string, error) {
+	if !i.started || i.gitWorktree == nil {
+		return "", fmt.Errorf("no diff to render for %s", i.Title)
+	}
+	return i.gitWorktree.RenderDiff(renderer, ref, diff, width)
+}
+
+// HasUnpushedCommits returns true if the instance's branch has commits that are not on any remote.
+func (i *Instance) HasUnpushedCommits() bool {
+	if !i.started || i.gitWorktree == nil {
+		return false
+	}
+	unpushed, err := i.gitWorktree.HasUnpushedCommits()
+	if err != nil {
+		log.WarningLog.Printf("could not check unpushed commits for %s: %v", i.Title, err)
+		return false
+	}
+	return unpushed
+}
+
 // ShouldUpdateDiff returns true if the instance is due for a diff stats update.
 // Rate limiting: at least 10s since last activity, at most once per 30s.
 func (i *Instance) ShouldUpdateDiff() bool {
@@ -785,16 +1357,53 @@ func (i *Instance) ShouldUpdateDiff() bool {
 	return true
 }
 
-// SendPrompt sends a prompt to the session
+// SendPrompt types the prompt into the session and submits it. The prompt is
+// submitted once the typed text shows up in the pane. If none of it shows up, it
+// is typed once more; if it still doesn't, e.g. because the program was redrawing
+// the screen, or only part of it arrived, it is submitted anyway and
+// ErrPromptUnconfirmed is returned. A submitted prompt is recorded as the last
+// one, even if it couldn't be confirmed.
 func (i *Instance) SendPrompt(prompt string) error {
+	err := i.TypePrompt(prompt)
+	if err != nil && !errors.Is(err, ErrPromptUnconfirmed) {
+		return err
+	}
+	i.RecordPrompt(prompt, time.Now())
+	return err
+}
+
+// ErrPromptUnconfirmed is returned when a prompt was submitted without showing
+// up in the input of the program first, so it may not have been received.
+var ErrPromptUnconfirmed = errors.New("prompt was submitted but could not be confirmed as received")
+
+// TypePrompt types the prompt into the session and submits it like SendPrompt,
+// but leaves the instance as it is. It can run in the background while the
+// instance is used elsewhere; RecordPrompt records the prompt afterwards.
+func (i *Instance) TypePrompt(prompt string) error {
 	if !i.started {
 		return fmt.Errorf("instance not started")
 	}
 	if i.session == nil {
 		return fmt.Errorf("session not initialized")
 	}
-	if err := i.session.SendKeys(prompt); err != nil {
-		return fmt.Errorf("error sending keys to session: %w", err)
+
+	confirmed := false
+	for attempt := 1; ; attempt++ {
+		if err := i.session.SendKeys(prompt); err != nil {
+			return fmt.Errorf("error sending keys to session: %w", err)
+		}
+
+		echo := i.waitForPromptEcho(prompt)
+		if echo == promptEchoComplete {
+			confirmed = true
+			break
+		}
+		// Typing a partially received prompt again would duplicate it
+		if echo == promptEchoPartial || attempt == promptSendAttempts {
+			log.WarningLog.Printf("prompt sent to %s did not show up in its input after %d attempt(s), submitting it anyway", i.Title, attempt)
+			break
+		}
+		log.WarningLog.Printf("prompt sent to %s was not received, retrying", i.Title)
 	}
 
 	// Brief pause to prevent carriage return from being interpreted as newline
@@ -802,16 +1411,175 @@ func (i *Instance) SendPrompt(prompt string) error {
 	if err := i.session.TapEnter(); err != nil {
 		return fmt.Errorf("error tapping enter: %w", err)
 	}
-
+	if !confirmed {
+		return ErrPromptUnconfirmed
+	}
 	return nil
 }
 
-// PreviewFullHistory captures the entire pane output including full scrollback history
+// RecordPrompt records the prompt submitted at sentAt as the last one, and
+// resumes polling the instance. The first prompt becomes the task of the
+// context file.
+func (i *Instance) RecordPrompt(prompt string, sentAt time.Time) {
+	i.LastPrompt = prompt
+	i.lastChangedAt = sentAt
+	i.recordContextPrompt(prompt)
+}
+
+// waitForPromptEcho polls the pane for up to promptEchoTimeout until the typed prompt
+// is complete, and returns how much of it was last seen.
+func (i *Instance) waitForPromptEcho(prompt string) promptEchoState {
+	echo := promptEchoNone
+	deadline := time.Now().Add(promptEchoTimeout)
+	for {
+		if content, err := i.session.CapturePaneContent(); err == nil {
+			if echo = promptEcho(content, prompt); echo == promptEchoComplete {
+				return echo
+			}
+		}
+		if time.Now().After(deadline) {
+			return echo
+		}
+		time.Sleep(promptEchoPollInterval)
+	}
+}
+
+const (
+	// promptSendAttempts is how many times SendPrompt types a prompt that doesn't show up.
+	promptSendAttempts = 2
+	// promptEchoTimeout is how long SendPrompt waits for the typed prompt to show up in the pane.
+	promptEchoTimeout = 5 * time.Second
+	// promptEchoPollInterval is how often the pane is checked for the typed prompt.
+	promptEchoPollInterval = 50 * time.Millisecond
+	// promptEchoMatchLength is how many characters from the start and end of the prompt are looked for.
+	promptEchoMatchLength = 16
+)
+
+// promptEchoState is how much of a typed prompt shows up in the pane.
+type promptEchoState int
+
+const (
+	promptEchoNone promptEchoState = iota
+	// promptEchoPartial means the start of the prompt shows up but not its end.
+	promptEchoPartial
+	promptEchoComplete
+)
+
+// promptEchoPattern matches the characters that are ignored when looking for an
+// echoed prompt: whitespace and the borders of a wrapped input box.
+var promptEchoPattern = regexp.MustCompile(`[\s│]+`)
+
+// promptEcho checks how much of the prompt appears in the pane content. A prompt
+// that the program collapsed into a pasted text placeholder counts as complete.
+func promptEcho(content, prompt string) promptEchoState {
+	content = ansiEscapePattern.ReplaceAllString(content, "")
+	// Placeholders of earlier prompts stay on screen above the input
+	if strings.Contains(promptInputArea(content), "[Pasted text") {
+		return promptEchoComplete
+	}
+
+	text := []rune(promptEchoPattern.ReplaceAllString(prompt, ""))
+	if len(text) == 0 {
+		return promptEchoComplete
+	}
+	content = promptEchoPattern.ReplaceAllString(content, "")
+
+	head, tail := text, text
+	if len(text) > promptEchoMatchLength {
+		head = text[:promptEchoMatchLength]
+		tail = text[len(text)-promptEchoMatchLength:]
+	}
+	switch {
+	case strings.Contains(content, string(tail)):
+		return promptEchoComplete
+	case strings.Contains(content, string(head)):
+		return promptEchoPartial
+	default:
+		return promptEchoNone
+	}
+}
+
+// promptInputLines is how many lines at the end of the pane are taken as the
+// input area when no prompt marker shows up in them.
+const promptInputLines = 5
+
+// promptInputArea returns the input the program shows at the end of the pane
+// content: the lines from the last one starting with a > prompt marker, or the
+// last promptInputLines lines if there is none.
+func promptInputArea(content string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	start := max(len(lines)-promptInputLines, 0)
+	for n := len(lines) - 1; n >= 0; n-- {
+		if strings.HasPrefix(strings.TrimLeft(lines[n], " │"), ">") {
+			start = n
+			break
+		}
+	}
+	return strings.Join(lines[start:], "\n")
+}
+
+// Default limits of the scrollback history captured from a session. Chatty
+// agents can produce tens of MB of history, which would stall the UI.
+const (
+	DefaultHistoryMaxLines = 5000
+	DefaultHistoryMaxBytes = 4 << 20
+)
+
+// PreviewFullHistory captures the pane output including scrollback history, up to
+// the default limits.
 func (i *Instance) PreviewFullHistory() (string, error) {
+	content, _, err := i.PreviewHistory(DefaultHistoryMaxLines, DefaultHistoryMaxBytes)
+	return content, err
+}
+
+// PreviewHistory captures the end of the pane output's scrollback history: at most
+// maxLines lines and maxBytes bytes, where 0 means no limit. truncated reports
+// whether earlier history was left out.
+func (i *Instance) PreviewHistory(maxLines, maxBytes int) (content string, truncated bool, err error) {
 	if !i.started || i.Status == Paused {
-		return "", nil
+		return "", false, nil
 	}
-	return i.session.CapturePaneContentWithOptions("-", "-")
+	return i.session.CaptureHistoryTail(maxLines, maxBytes)
+}
+
+// ansiEscapePattern matches CSI and OSC 8 hyperlink escape sequences in captured pane content.
+var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]|\x1b\]8;;[^\x1b]*\x1b\\`)
+
+// RecentOutput returns the last n lines of the session output as
+// plain text, suitable for forwarding to another session as a prompt.
+func (i *Instance) RecentOutput(n int) (string, error) {
+	content, err := i.PreviewFullHistory()
+	if err != nil {
+		return "", err
+	}
+	return lastLines(ansiEscapePattern.ReplaceAllString(content, ""), n), nil
+}
+
+// CaptureSnippets records the notable output blocks in the session's full history.
+func (i *Instance) CaptureSnippets() error {
+	content, err := i.PreviewFullHistory()
+	if err != nil {
+		return err
+	}
+	i.snippets.Record(content)
+	return nil
+}
+
+// Snippets returns the output blocks captured from the session, most recent first.
+func (i *Instance) Snippets() []Snippet {
+	return i.snippets.List()
+}
+
+// lastLines returns the last n lines of content, ignoring trailing whitespace and blank lines.
+func lastLines(content string, n int) string {
+	lines := strings.Split(strings.TrimRight(content, " \t\r\n"), "\n")
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for idx, line := range lines {
+		lines[idx] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Join(lines, "\n")
 }
 
 // SetSession sets the multiplexer session for testing purposes
@@ -853,21 +1621,34 @@ func (i *Instance) IsDockerSession() bool {
 // CheckAndRestartProgram checks if the program needs to be restarted and does so if possible.
 // This is used to handle system restarts where the Zellij session survives but the program
 // (e.g., Claude) has exited. If a Claude session ID is available, it will restart with --resume.
-func (i *Instance) CheckAndRestartProgram() error {
+// It returns true if the program was restarted.
+func (i *Instance) CheckAndRestartProgram() (bool, error) {
+	return i.checkAndRestartProgram(context.Background())
+}
+
+// checkAndRestartProgram is CheckAndRestartProgram, stopping the check of
+// sessions polled through commands once ctx is done.
+func (i *Instance) checkAndRestartProgram(ctx context.Context) (bool, error) {
 	if !i.started || i.Status == Paused {
-		return nil
+		return false, nil
 	}
 
 	// Check if program is running
-	running, err := i.session.IsProgramRunning()
+	var running bool
+	var err error
+	if poller, ok := i.session.(ContextPoller); ok {
+		running, err = poller.IsProgramRunningContext(ctx)
+	} else {
+		running, err = i.session.IsProgramRunning()
+	}
 	if err != nil {
 		log.DebugLog.Printf("[CheckAndRestartProgram] Error checking if program running for instance %s: %v", i.Title, err)
-		return fmt.Errorf("failed to check if program is running: %w", err)
+		return false, fmt.Errorf("failed to check if program is running: %w", err)
 	}
 
 	if running {
 		log.DebugLog.Printf("[CheckAndRestartProgram] Program is running in instance %s, no restart needed", i.Title)
-		return nil // Program is running, nothing to do
+		return false, nil // Program is running, nothing to do
 	}
 
 	// Program is not running, try to restart it
@@ -881,10 +1662,11 @@ func (i *Instance) CheckAndRestartProgram() error {
 	}
 
 	if err := i.session.RestartProgram(args); err != nil {
-		return fmt.Errorf("failed to restart program: %w", err)
+		return false, fmt.Errorf("failed to restart program: %w", err)
 	}
+	i.sizeEpoch.Add(1)
 
-	return nil
+	return true, nil
 }
 
 // CaptureClaudeSessionID captures and stores the Claude session ID from Claude's project files.
@@ -895,16 +1677,7 @@ func (i *Instance) CaptureClaudeSessionID() {
 		return
 	}
 
-	// Get the worktree path for Claude's project directory
-	worktreePath := ""
-	if i.gitWorktree != nil {
-		worktreePath = i.gitWorktree.GetWorktreePath()
-	}
-	if worktreePath == "" {
-		worktreePath = i.Path
-	}
-
-	sessionID, err := ExtractClaudeSessionID(worktreePath)
+	sessionID, err := ExtractClaudeSessionID(i.claudeWorkingDir())
 	if err != nil {
 		// Don't log warnings for expected errors (project not created yet, no session files yet)
 		if !errors.Is(err, ErrClaudeProjectNotFound) && !errors.Is(err, ErrNoSessionFiles) {
@@ -919,6 +1692,57 @@ func (i *Instance) CaptureClaudeSessionID() {
 	}
 }
 
+// claudeWorkingDir returns the directory Claude runs in, which determines its project directory.
+func (i *Instance) claudeWorkingDir() string {
+	if i.gitWorktree != nil {
+		if worktreePath := i.gitWorktree.GetWorktreePath(); worktreePath != "" {
+			return worktreePath
+		}
+	}
+	return i.Path
+}
+
+// ReadTranscriptSummary summarizes the transcript of the instance's Claude session.
+// The transcript is only read again once it has been modified. It fails if the
+// session ID has not been captured yet.
+func (i *Instance) ReadTranscriptSummary() (*TranscriptSummary, error) {
+	if i.ClaudeSessionID == "" {
+		return nil, fmt.Errorf("no Claude session ID captured for %s", i.Title)
+	}
+	path, err := ClaudeTranscriptPath(i.claudeWorkingDir(), i.ClaudeSessionID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat transcript: %w", err)
+	}
+
+	i.transcriptMu.Lock()
+	defer i.transcriptMu.Unlock()
+	if i.transcript != nil && info.ModTime().Equal(i.transcriptModTime) {
+		return i.transcript, nil
+	}
+
+	transcript, err := ReadTranscriptSummary(path)
+	if err != nil {
+		return nil, err
+	}
+	i.transcript = transcript
+	i.transcriptModTime = info.ModTime()
+	return transcript, nil
+}
+
+// Todos returns the agent's todo list as of the last time the transcript was read.
+func (i *Instance) Todos() []TranscriptTodo {
+	i.transcriptMu.Lock()
+	defer i.transcriptMu.Unlock()
+	if i.transcript == nil {
+		return nil
+	}
+	return i.transcript.Todos
+}
+
 // GetClaudeSessionID returns the stored Claude session ID.
 func (i *Instance) GetClaudeSessionID() string {
 	return i.ClaudeSessionID
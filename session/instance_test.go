@@ -0,0 +1,215 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLastLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		n        int
+		expected string
+	}{
+		{
+			name:     "fewer lines than requested",
+			content:  "one\ntwo",
+			n:        5,
+			expected: "one\ntwo",
+		},
+		{
+			name:     "keeps the last n lines",
+			content:  "one\ntwo\nthree\nfour",
+			n:        2,
+			expected: "three\nfour",
+		},
+		{
+			name:     "ignores trailing blank lines",
+			content:  "one\ntwo   \n\n  \n\n",
+			n:        1,
+			expected: "two",
+		},
+		{
+			name:     "zero returns everything",
+			content:  "one\ntwo",
+			n:        0,
+			expected: "one\ntwo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lastLines(tt.content, tt.n)
+			if got != tt.expected {
+				t.Errorf("lastLines(%q, %d) = %q, want %q", tt.content, tt.n, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAnsiEscapePattern(t *testing.T) {
+	content := "\x1b[1;32mdone\x1b[0m see \x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\"
+	got := ansiEscapePattern.ReplaceAllString(content, "")
+	if got != "done see link" {
+		t.Errorf("stripped content = %q, want %q", got, "done see link")
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	got := ParseTags("Blocked, needs-review  #backend,,blocked")
+	want := []string{"blocked", "needs-review", "backend"}
+	if len(got) != len(want) {
+		t.Fatalf("ParseTags() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ParseTags()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPromptEcho(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		prompt  string
+		want    promptEchoState
+	}{
+		{"not typed yet", "> ", "fix the failing test", promptEchoNone},
+		{"typed", "> fix the failing test", "fix the failing test", promptEchoComplete},
+		{"wrapped in input box", "│ > fix the fail │\n│ ing test        │", "fix the failing test", promptEchoComplete},
+		{"multi-line prompt", "> first line\n  second line", "first line\nsecond line", promptEchoComplete},
+		{"only start typed", "> please refactor the", "please refactor the storage layer", promptEchoPartial},
+		{"with colors", "> \x1b[1mfix the failing test\x1b[0m", "fix the failing test", promptEchoComplete},
+		{"collapsed paste", "> [Pasted text #1 +40 lines]", "long\noutput", promptEchoComplete},
+		{"earlier paste on screen", "> [Pasted text #1 +40 lines]\n\nDone, the tests pass.\n\n> ", "long\noutput", promptEchoNone},
+		{"paste in input box", "Done.\n╭──────╮\n│ > [Pasted text #2 +12 lines] │\n╰──────╯", "long\noutput", promptEchoComplete},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := promptEcho(tt.content, tt.prompt); got != tt.want {
+				t.Errorf("promptEcho(%q, %q) = %v, want %v", tt.content, tt.prompt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromInstanceDataDeferred(t *testing.T) {
+	data := InstanceData{Title: "deferred", Path: t.TempDir(), Status: Running, Scratch: true}
+
+	instance, err := FromInstanceDataDeferred(data)
+	if err != nil {
+		t.Fatalf("FromInstanceDataDeferred() error = %v", err)
+	}
+	if !instance.RestorePending() {
+		t.Errorf("RestorePending() = false, want true for a running instance")
+	}
+	if instance.Started() {
+		t.Errorf("Started() = true, want false before Restore")
+	}
+
+	data.Status = Paused
+	instance, err = FromInstanceDataDeferred(data)
+	if err != nil {
+		t.Fatalf("FromInstanceDataDeferred() error = %v", err)
+	}
+	if instance.RestorePending() {
+		t.Errorf("RestorePending() = true, want false for a paused instance")
+	}
+}
+
+func TestPausedScreenPersisted(t *testing.T) {
+	data := InstanceData{Title: "paused", Path: t.TempDir(), Status: Paused, Scratch: true, PausedScreen: "last screen"}
+
+	instance, err := FromInstanceDataDeferred(data)
+	if err != nil {
+		t.Fatalf("FromInstanceDataDeferred() error = %v", err)
+	}
+	if instance.PausedScreen != data.PausedScreen {
+		t.Errorf("PausedScreen = %q, want %q", instance.PausedScreen, data.PausedScreen)
+	}
+	if got := instance.ToInstanceData().PausedScreen; got != data.PausedScreen {
+		t.Errorf("ToInstanceData().PausedScreen = %q, want %q", got, data.PausedScreen)
+	}
+}
+
+// closeSession is a Multiplexer that exists until it is closed.
+type closeSession struct {
+	Multiplexer
+	closed bool
+}
+
+func (s *closeSession) DoesSessionExist() bool { return !s.closed }
+
+func (s *closeSession) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestKillCleansUpUnstartedInstances(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+		{"branch", "existing"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %s (%v)", args, output, err)
+		}
+	}
+	branchExists := func(branch string) bool {
+		return exec.Command("git", "-C", repo, "rev-parse", "--verify", "-q", "refs/heads/"+branch).Run() == nil
+	}
+
+	// A restored instance whose session hasn't been restored yet still has the
+	// session and worktree of the previous run
+	worktreePath := filepath.Join(t.TempDir(), "restored")
+	if output, err := exec.Command("git", "-C", repo, "worktree", "add", "-q", "-b", "test/restored", worktreePath).CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %s (%v)", output, err)
+	}
+	restored, err := FromInstanceDataDeferred(InstanceData{
+		Title:  "restored",
+		Path:   repo,
+		Status: Running,
+		Worktree: GitWorktreeData{
+			RepoPath:     repo,
+			WorktreePath: worktreePath,
+			SessionName:  "restored",
+			BranchName:   "test/restored",
+		},
+	})
+	if err != nil {
+		t.Fatalf("FromInstanceDataDeferred() error = %v", err)
+	}
+	fake := &closeSession{}
+	restored.SetSession(fake)
+	if err := restored.Kill(0); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	if !fake.closed {
+		t.Error("Kill() left the session of the previous run")
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Errorf("Kill() left the worktree: %v", err)
+	}
+	if branchExists("test/restored") {
+		t.Error("Kill() left the branch")
+	}
+
+	// An instance that never got to set up its worktree leaves the branch alone
+	pending := &Instance{
+		Title:       "pending",
+		Status:      Pending,
+		gitWorktree: git.NewGitWorktreeFromStorage(repo, filepath.Join(t.TempDir(), "pending"), "pending", "existing", ""),
+	}
+	if err := pending.Kill(0); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	if !branchExists("existing") {
+		t.Error("Kill() of an instance that never started deleted its branch")
+	}
+}
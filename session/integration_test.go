@@ -0,0 +1,179 @@
+//go:build integration
+
+package session
+
+import (
+	"claude-squad/log"
+	"claude-squad/session/zellij"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Integration tests run instances in real Zellij sessions against throwaway git
+// repositories. Run them with:
+//
+//	go test -tags integration ./session/...
+
+// integrationTimeout bounds how long an integration test waits for a session to react.
+const integrationTimeout = 10 * time.Second
+
+func TestMain(m *testing.M) {
+	log.Initialize(false)
+	defer log.Close()
+
+	os.Exit(m.Run())
+}
+
+// setupIntegrationRepo creates a repository with one commit. HOME is pointed at
+// a temporary directory so worktrees and config stay out of the user's.
+func setupIntegrationRepo(t *testing.T) string {
+	t.Helper()
+	if !zellij.IsAvailable() {
+		t.Skip("zellij not available")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	repoPath := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if output, err := exec.Command("git", append([]string{"-C", repoPath}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v (%s)", args, err, output)
+		}
+	}
+	return repoPath
+}
+
+// waitForOutput waits until the instance's pane shows the text.
+func waitForOutput(t *testing.T, instance *Instance, text string) {
+	t.Helper()
+	deadline := time.Now().Add(integrationTimeout)
+	var content string
+	for time.Now().Before(deadline) {
+		var err error
+		if content, err = instance.Preview(); err == nil && strings.Contains(content, text) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("pane never showed %q, last content:\n%s", text, content)
+}
+
+func TestIntegrationInstanceLifecycle(t *testing.T) {
+	repoPath := setupIntegrationRepo(t)
+
+	instance, err := NewInstance(InstanceOptions{
+		Title:   fmt.Sprintf("integration-%d", time.Now().UnixNano()%100000),
+		Path:    repoPath,
+		Program: "sh",
+	})
+	if err != nil {
+		t.Fatalf("NewInstance() error = %v", err)
+	}
+	if err := instance.Start(true); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = instance.Kill(0) })
+
+	worktree, err := instance.GetGitWorktree()
+	if err != nil {
+		t.Fatalf("GetGitWorktree() error = %v", err)
+	}
+	worktreePath := worktree.GetWorktreePath()
+	if _, err := os.Stat(worktreePath); err != nil {
+		t.Fatalf("worktree was not created: %v", err)
+	}
+
+	// The session runs in the worktree
+	if err := instance.SendKeys("pwd"); err != nil {
+		t.Fatalf("SendKeys() error = %v", err)
+	}
+	instance.TapEnter()
+	waitForOutput(t, instance, worktreePath)
+
+	// Pausing commits the changes, detaches and removes the worktree
+	if err := os.WriteFile(filepath.Join(worktreePath, "file.txt"), []byte("change\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := instance.Pause(); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Errorf("worktree still exists after Pause: %v", err)
+	}
+
+	// Resuming recreates the worktree with the committed changes and reattaches
+	if err := instance.Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktreePath, "file.txt")); err != nil {
+		t.Errorf("committed change missing after Resume: %v", err)
+	}
+	if !instance.SessionAlive() {
+		t.Errorf("session is not alive after Resume")
+	}
+
+	if err := instance.Kill(0); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	if _, err := os.Stat(worktreePath); !os.IsNotExist(err) {
+		t.Errorf("worktree still exists after Kill: %v", err)
+	}
+	if instance.SessionAlive() {
+		t.Errorf("session is still alive after Kill")
+	}
+}
+
+func TestIntegrationRenameAll(t *testing.T) {
+	repoPath := setupIntegrationRepo(t)
+
+	instance, err := NewInstance(InstanceOptions{
+		Title:   fmt.Sprintf("rename-%d", time.Now().UnixNano()%100000),
+		Path:    repoPath,
+		Program: "sh",
+	})
+	if err != nil {
+		t.Fatalf("NewInstance() error = %v", err)
+	}
+	if err := instance.Start(true); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = instance.Kill(0) })
+
+	worktree, err := instance.GetGitWorktree()
+	if err != nil {
+		t.Fatalf("GetGitWorktree() error = %v", err)
+	}
+	oldBranch, oldPath := worktree.GetBranchName(), worktree.GetWorktreePath()
+
+	newTitle := instance.Title + "-renamed"
+	if err := instance.RenameAll(newTitle); err != nil {
+		t.Fatalf("RenameAll() error = %v", err)
+	}
+	if instance.Title != newTitle || worktree.GetSessionName() != instance.GetSessionName() {
+		t.Errorf("instance = %q with session %q, want both renamed", instance.Title, worktree.GetSessionName())
+	}
+	if instance.Branch == oldBranch || instance.Branch != worktree.GetBranchName() {
+		t.Errorf("branch = %q, want it renamed from %q", instance.Branch, oldBranch)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("worktree still exists at the old path: %v", err)
+	}
+	if _, err := os.Stat(worktree.GetWorktreePath()); err != nil {
+		t.Errorf("worktree missing at the new path: %v", err)
+	}
+	if !instance.SessionAlive() {
+		t.Errorf("renamed session is not alive")
+	}
+}
@@ -0,0 +1,66 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// IssueLink is the GitHub issue an instance was created for.
+type IssueLink struct {
+	// Repo is the owner/name of the issue's repository
+	Repo   string `json:"repo,omitempty"`
+	Number int    `json:"number"`
+	URL    string `json:"url,omitempty"`
+}
+
+// NewIssueLink returns the link to the issue.
+func NewIssueLink(issue *git.Issue) *IssueLink {
+	return &IssueLink{Repo: issue.Repo(), Number: issue.Number, URL: issue.URL}
+}
+
+// Reference returns how the issue is referenced in commit messages and pull
+// requests, e.g. owner/repo#123.
+func (l *IssueLink) Reference() string {
+	return l.Repo + "#" + strconv.Itoa(l.Number)
+}
+
+// IssueTitle returns the title of an instance working on the issue: its
+// number followed by the words of its title, e.g. "123-fix-login-form".
+func IssueTitle(issue *git.Issue) string {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(issue.Number))
+	dash := true
+	for _, r := range strings.ToLower(issue.Title) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			dash = true
+			continue
+		}
+		if dash {
+			b.WriteRune('-')
+			dash = false
+		}
+		b.WriteRune(r)
+	}
+
+	runes := []rune(b.String())
+	if len(runes) > TitleMaxLength {
+		runes = runes[:TitleMaxLength]
+	}
+	return strings.TrimRight(string(runes), "-")
+}
+
+// IssuePrompt returns the prompt telling the agent to work on the issue.
+func IssuePrompt(issue *git.Issue) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Work on GitHub issue #%d: %s", issue.Number, issue.Title)
+	if issue.URL != "" {
+		fmt.Fprintf(&b, "\n%s", issue.URL)
+	}
+	if body := strings.TrimSpace(issue.Body); body != "" {
+		fmt.Fprintf(&b, "\n\n%s", body)
+	}
+	return b.String()
+}
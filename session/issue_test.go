@@ -0,0 +1,26 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueTitle(t *testing.T) {
+	assert.Equal(t, "123-fix-the-login-form", IssueTitle(&git.Issue{Number: 123, Title: "Fix the login form!"}))
+	assert.Equal(t, "7-crash-in-api-v2-handler", IssueTitle(&git.Issue{Number: 7, Title: "Crash in api/v2 handler"}))
+
+	title := IssueTitle(&git.Issue{Number: 4567, Title: "Support exporting sessions to a file and importing them again"})
+	assert.Equal(t, "4567-support-exporting-sessions", title)
+	assert.NoError(t, validateTitle(title))
+}
+
+func TestIssuePromptAndLink(t *testing.T) {
+	issue := &git.Issue{Number: 12, Title: "Login fails", Body: "Steps:\n1. log in\n", URL: "https://github.com/acme/web/issues/12"}
+	assert.Equal(t, "Work on GitHub issue #12: Login fails\nhttps://github.com/acme/web/issues/12\n\nSteps:\n1. log in", IssuePrompt(issue))
+
+	link := NewIssueLink(issue)
+	assert.Equal(t, &IssueLink{Repo: "acme/web", Number: 12, URL: issue.URL}, link)
+	assert.Equal(t, "acme/web#12", link.Reference())
+}
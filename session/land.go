@@ -0,0 +1,104 @@
+package session
+
+import (
+	"fmt"
+	"time"
+)
+
+// LandState is the state of an instance in the merge queue.
+type LandState string
+
+const (
+	// LandNone means the instance is not in the merge queue.
+	LandNone LandState = ""
+	// LandQueued means the instance is ready to land and waits for its turn.
+	LandQueued LandState = "queued"
+	// LandLanded means the branch was merged into the default branch.
+	LandLanded LandState = "landed"
+	// LandFailed means landing failed, see LandError. The instance needs attention.
+	LandFailed LandState = "failed"
+)
+
+// QueueLand marks the instance as ready to land. Queued instances are landed
+// one at a time, in the order they were queued.
+func (i *Instance) QueueLand() error {
+	if !i.started || i.Paused() {
+		return fmt.Errorf("only running sessions can land")
+	}
+	if err := i.checkNotQuarantined("land"); err != nil {
+		return err
+	}
+	if i.gitWorktree == nil {
+		return fmt.Errorf("cannot land instance without a git worktree")
+	}
+	i.LandState = LandQueued
+	i.LandQueuedAt = time.Now()
+	i.LandError = ""
+	return nil
+}
+
+// UnqueueLand takes the instance out of the merge queue.
+func (i *Instance) UnqueueLand() {
+	i.LandState = LandNone
+	i.LandError = ""
+}
+
+// NeedsAttention returns true if the instance is waiting for the user.
+func (i *Instance) NeedsAttention() bool {
+	return i.Status == Ready || i.LandState == LandFailed || i.Quarantined()
+}
+
+// Land merges the instance's branch into the default branch and records the outcome.
+func (i *Instance) Land(checkCommand string) error {
+	land, err := i.LandJob(checkCommand)
+	if err == nil {
+		err = land()
+	}
+	i.FinishLand(err)
+	return err
+}
+
+// LandJob returns the git work of landing the instance. It does not touch the
+// instance, so it can run in the background while the instance is polled; record
+// its outcome with FinishLand.
+func (i *Instance) LandJob(checkCommand string) (func() error, error) {
+	// The instance may have been quarantined while it waited in the queue
+	if err := i.checkNotQuarantined("land"); err != nil {
+		return nil, err
+	}
+	if i.gitWorktree == nil || i.Paused() {
+		return nil, fmt.Errorf("the worktree of '%s' is not available", i.Title)
+	}
+
+	worktree := i.gitWorktree
+	commitMsg := fmt.Sprintf("[claudesquad] update from '%s' on %s", i.Title, time.Now().Format(time.RFC822))
+	return func() error {
+		return worktree.Land(commitMsg, checkCommand)
+	}, nil
+}
+
+// FinishLand records the outcome of landing the instance.
+func (i *Instance) FinishLand(err error) {
+	if err != nil {
+		i.LandState = LandFailed
+		i.LandError = err.Error()
+		return
+	}
+	i.LandState = LandLanded
+	i.LandError = ""
+	i.RecordPush()
+}
+
+// NextToLand returns the queued instance that was queued first, or nil.
+func NextToLand(instances []*Instance) *Instance {
+	var next *Instance
+	for _, instance := range instances {
+		if instance == nil || instance.Tombstoned() || instance.LandState != LandQueued {
+			continue
+		}
+		if next == nil || instance.LandQueuedAt.Before(next.LandQueuedAt) {
+			next = instance
+		}
+	}
+	return next
+}
@@ -0,0 +1,42 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextToLand(t *testing.T) {
+	now := time.Now()
+	first := &Instance{Title: "first", LandState: LandQueued, LandQueuedAt: now.Add(-time.Minute)}
+	second := &Instance{Title: "second", LandState: LandQueued, LandQueuedAt: now}
+	failed := &Instance{Title: "failed", LandState: LandFailed, LandQueuedAt: now.Add(-time.Hour)}
+	deleted := &Instance{Title: "deleted", LandState: LandQueued, LandQueuedAt: now.Add(-time.Hour), DeletedAt: &now}
+
+	if got := NextToLand([]*Instance{second, failed, first, deleted}); got != first {
+		t.Errorf("NextToLand() = %v, want the instance queued first", got)
+	}
+	if got := NextToLand([]*Instance{failed, deleted, {Title: "idle"}}); got != nil {
+		t.Errorf("NextToLand() = %v, want nil without queued instances", got.Title)
+	}
+}
+
+func TestNeedsAttention(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance *Instance
+		want     bool
+	}{
+		{"ready", &Instance{Status: Ready}, true},
+		{"running", &Instance{Status: Running}, false},
+		{"landing failed", &Instance{Status: Running, LandState: LandFailed}, true},
+		{"queued", &Instance{Status: Running, LandState: LandQueued}, false},
+		{"quarantined", &Instance{Status: Running, QuarantinedAt: &time.Time{}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.instance.NeedsAttention(); got != tt.want {
+				t.Errorf("NeedsAttention() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package session
 
+import "context"
+
 // Multiplexer defines the interface for terminal multiplexer sessions.
 // This abstraction allows claude-squad to use Zellij for terminal session management.
 type Multiplexer interface {
@@ -40,6 +42,16 @@ type Multiplexer interface {
 	// start and end specify line numbers (use "-" for start/end of history).
 	CapturePaneContentWithOptions(start, end string) (string, error)
 
+	// CaptureHistoryTail captures the end of the scroll history: at most maxLines
+	// lines and maxBytes bytes (0 for no limit). truncated reports whether earlier
+	// history was left out.
+	CaptureHistoryTail(maxLines, maxBytes int) (content string, truncated bool, err error)
+
+	// LoadScreen shows a screen captured with CapturePaneContent, e.g. before the
+	// session was paused, until the session draws over it. Call it before the
+	// session is started or restored.
+	LoadScreen(screen string)
+
 	// HasUpdated checks if pane content has changed since the last check.
 	// Returns (updated, hasPrompt) where hasPrompt indicates a user prompt is waiting.
 	HasUpdated() (updated bool, hasPrompt bool)
@@ -62,4 +74,47 @@ type Multiplexer interface {
 	// RestartProgram restarts the program in the existing session with optional arguments.
 	// This sends the program command followed by the args to the terminal and executes it.
 	RestartProgram(args string) error
+
+	// StartPane runs the command in workDir in a pane of the given name next to
+	// the program, e.g. a dev server the agent works against. A pane of the
+	// same name that is still running is reconnected to instead.
+	StartPane(name, command, workDir string) error
+
+	// CapturePane captures the current visible content of the named pane.
+	CapturePane(name string) (string, error)
+
+	// PaneRunning returns true while the command of the named pane runs.
+	PaneRunning(name string) bool
+
+	// ClosePane stops the command of the named pane and removes the pane.
+	ClosePane(name string) error
+}
+
+// Reconnector is implemented by sessions connected to a server outside of
+// claude-squad, such as Zellij sessions, whose connection can be lost when the
+// server restarts.
+type Reconnector interface {
+	// Disconnected returns true if the connection to the session was lost.
+	Disconnected() bool
+	// Reconnect connects to the session again.
+	Reconnect() error
+}
+
+// ContextPoller is implemented by sessions that are polled through commands,
+// such as Zellij and Docker sessions, so that the commands of a poll that timed
+// out are stopped instead of piling up.
+type ContextPoller interface {
+	// HasUpdatedContext is HasUpdated, stopping its commands once ctx is done.
+	HasUpdatedContext(ctx context.Context) (updated bool, hasPrompt bool)
+	// IsProgramRunningContext is IsProgramRunning, stopping its commands once
+	// ctx is done.
+	IsProgramRunningContext(ctx context.Context) (bool, error)
+}
+
+// Renamer is implemented by sessions that have a name outside of claude-squad,
+// such as Zellij sessions, so they can follow a renamed instance.
+type Renamer interface {
+	// Rename renames the session. name is the unsanitized name, as passed to
+	// NewMultiplexer.
+	Rename(name string) error
 }
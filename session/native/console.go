@@ -0,0 +1,15 @@
+package native
+
+import "io"
+
+// console is a pseudo terminal with the program running in it. Reads return
+// the program's output and writes are its input.
+type console interface {
+	io.ReadWriteCloser
+	// Resize sets the size of the terminal.
+	Resize(width, height int) error
+	// Wait waits for the program to exit.
+	Wait() error
+	// Kill terminates the program.
+	Kill() error
+}
@@ -0,0 +1,48 @@
+//go:build !windows
+
+package native
+
+import (
+	"claude-squad/config"
+	"context"
+	"os"
+	"os/exec"
+
+	"github.com/creack/pty"
+)
+
+// ptyConsole runs the program under a pty.
+type ptyConsole struct {
+	*os.File
+	cmd *exec.Cmd
+}
+
+// consoleAvailable returns true since every supported Unix has ptys.
+func consoleAvailable() bool {
+	return true
+}
+
+// startConsole starts the command line with the shell in dir with the
+// environment env under a pty of the given size.
+func startConsole(command, dir string, env []string, width, height int) (console, error) {
+	cmd := config.ShellCommand(context.Background(), command)
+	cmd.Dir = dir
+	cmd.Env = env
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Rows: uint16(height), Cols: uint16(width)})
+	if err != nil {
+		return nil, err
+	}
+	return &ptyConsole{File: ptmx, cmd: cmd}, nil
+}
+
+func (c *ptyConsole) Resize(width, height int) error {
+	return pty.Setsize(c.File, &pty.Winsize{Rows: uint16(height), Cols: uint16(width)})
+}
+
+func (c *ptyConsole) Wait() error {
+	return c.cmd.Wait()
+}
+
+func (c *ptyConsole) Kill() error {
+	return c.cmd.Process.Kill()
+}
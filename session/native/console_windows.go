@@ -0,0 +1,174 @@
+//go:build windows
+
+package native
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// conPTY runs the program under a Windows pseudo console.
+type conPTY struct {
+	console windows.Handle
+	process windows.Handle
+	attrs   *windows.ProcThreadAttributeListContainer
+	// input and output are our ends of the pipes to the pseudo console
+	input  *os.File
+	output *os.File
+
+	closeOnce sync.Once
+}
+
+// consoleAvailable returns true if Windows has pseudo consoles, which were
+// added in Windows 10 1809.
+func consoleAvailable() bool {
+	return windows.NewLazySystemDLL("kernel32.dll").NewProc("CreatePseudoConsole").Find() == nil
+}
+
+// startConsole starts the command line with cmd.exe in dir with the
+// environment env under a pseudo console of the given size.
+func startConsole(command, dir string, env []string, width, height int) (console, error) {
+	if !consoleAvailable() {
+		return nil, fmt.Errorf("pseudo consoles require Windows 10 1809 or later")
+	}
+
+	var inRead, inWrite, outRead, outWrite windows.Handle
+	if err := windows.CreatePipe(&inRead, &inWrite, nil, 0); err != nil {
+		return nil, fmt.Errorf("failed to create input pipe: %w", err)
+	}
+	if err := windows.CreatePipe(&outRead, &outWrite, nil, 0); err != nil {
+		windows.CloseHandle(inRead)
+		windows.CloseHandle(inWrite)
+		return nil, fmt.Errorf("failed to create output pipe: %w", err)
+	}
+
+	c := &conPTY{
+		input:  os.NewFile(uintptr(inWrite), "conpty-input"),
+		output: os.NewFile(uintptr(outRead), "conpty-output"),
+	}
+	size := windows.Coord{X: int16(width), Y: int16(height)}
+	err := windows.CreatePseudoConsole(size, inRead, outWrite, 0, &c.console)
+	// The pseudo console holds on to its own ends of the pipes
+	windows.CloseHandle(inRead)
+	windows.CloseHandle(outWrite)
+	if err != nil {
+		c.input.Close()
+		c.output.Close()
+		return nil, fmt.Errorf("failed to create pseudo console: %w", err)
+	}
+
+	if err := c.startProcess(command, dir, env); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// startProcess starts the command line attached to the pseudo console.
+func (c *conPTY) startProcess(command, dir string, env []string) error {
+	attrs, err := windows.NewProcThreadAttributeList(1)
+	if err != nil {
+		return fmt.Errorf("failed to create process attributes: %w", err)
+	}
+	c.attrs = attrs
+	// The attribute's value is the pseudo console handle itself, not a pointer to it
+	if err := attrs.Update(windows.PROC_THREAD_ATTRIBUTE_PSEUDOCONSOLE, *(*unsafe.Pointer)(unsafe.Pointer(&c.console)), unsafe.Sizeof(c.console)); err != nil {
+		return fmt.Errorf("failed to set pseudo console attribute: %w", err)
+	}
+
+	si := &windows.StartupInfoEx{ProcThreadAttributeList: attrs.List()}
+	si.Cb = uint32(unsafe.Sizeof(*si))
+	// Keep the program from inheriting our standard handles instead of the pseudo console's
+	si.Flags = windows.STARTF_USESTDHANDLES
+
+	commandLine, err := windows.UTF16PtrFromString("cmd.exe /s /c \"" + command + "\"")
+	if err != nil {
+		return err
+	}
+	var currentDir *uint16
+	if dir != "" {
+		if currentDir, err = windows.UTF16PtrFromString(dir); err != nil {
+			return err
+		}
+	}
+
+	envBlock, err := environmentBlock(env)
+	if err != nil {
+		return err
+	}
+
+	var pi windows.ProcessInformation
+	flags := uint32(windows.EXTENDED_STARTUPINFO_PRESENT | windows.CREATE_UNICODE_ENVIRONMENT)
+	if err := windows.CreateProcess(nil, commandLine, nil, nil, false, flags, envBlock, currentDir, &si.StartupInfo, &pi); err != nil {
+		return fmt.Errorf("failed to start process: %w", err)
+	}
+	windows.CloseHandle(pi.Thread)
+	c.process = pi.Process
+	return nil
+}
+
+// environmentBlock returns env as the block of NUL terminated strings
+// CreateProcess takes.
+func environmentBlock(env []string) (*uint16, error) {
+	var block []uint16
+	for _, kv := range env {
+		s, err := windows.UTF16FromString(kv)
+		if err != nil {
+			return nil, err
+		}
+		block = append(block, s...)
+	}
+	// The block ends with an empty string
+	block = append(block, 0)
+	return &block[0], nil
+}
+
+func (c *conPTY) Read(p []byte) (int, error) {
+	return c.output.Read(p)
+}
+
+func (c *conPTY) Write(p []byte) (int, error) {
+	return c.input.Write(p)
+}
+
+func (c *conPTY) Resize(width, height int) error {
+	return windows.ResizePseudoConsole(c.console, windows.Coord{X: int16(width), Y: int16(height)})
+}
+
+func (c *conPTY) Wait() error {
+	if _, err := windows.WaitForSingleObject(c.process, windows.INFINITE); err != nil {
+		return err
+	}
+	var code uint32
+	if err := windows.GetExitCodeProcess(c.process, &code); err != nil {
+		return err
+	}
+	if code != 0 {
+		return fmt.Errorf("exit status %d", code)
+	}
+	return nil
+}
+
+func (c *conPTY) Kill() error {
+	return windows.TerminateProcess(c.process, 1)
+}
+
+// Close closes the pseudo console, which also ends the output stream.
+func (c *conPTY) Close() error {
+	c.closeOnce.Do(func() {
+		windows.ClosePseudoConsole(c.console)
+		c.input.Close()
+		c.output.Close()
+		if c.attrs != nil {
+			c.attrs.Delete()
+		}
+		if c.process != 0 {
+			windows.CloseHandle(c.process)
+		}
+	})
+	return nil
+}
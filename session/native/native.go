@@ -0,0 +1,399 @@
+package native
+
+import (
+	"bytes"
+	"claude-squad/log"
+	"claude-squad/session/zellij"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// NativeSession runs the program directly under a pseudo terminal, without a
+// multiplexer: a ConPTY on Windows and a pty elsewhere. The program only lives
+// as long as claude-squad, so restoring a session starts the program again.
+type NativeSession struct {
+	// Initialized by NewNativeSession
+	name    string
+	program string
+	workDir string
+	// env holds the environment variables the program is started with
+	env map[string]string
+
+	mu      sync.Mutex
+	console console
+	// exited is closed when the program of the current console exits
+	exited chan struct{}
+	// output receives the program's output while attached
+	output io.Writer
+	// panes are the commands running next to the program, by name
+	panes map[string]*pane
+
+	// Terminal buffer for capturing output with colors
+	termBuffer *zellij.TerminalBuffer
+	lastHash   []byte
+
+	// Attach state
+	attachCh chan struct{}
+	ctx      context.Context
+	cancel   func()
+	detach   sync.Once
+}
+
+// NewNativeSession creates a NativeSession that runs the program in workDir
+// when it is restored.
+func NewNativeSession(name, program, workDir string) *NativeSession {
+	return &NativeSession{
+		name:       name,
+		program:    program,
+		workDir:    workDir,
+		termBuffer: zellij.NewTerminalBuffer(),
+	}
+}
+
+// SetEnv sets the environment variables the program is started with.
+func (s *NativeSession) SetEnv(env map[string]string) {
+	s.env = env
+}
+
+// environ returns our environment with the session's variables added.
+func (s *NativeSession) environ() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := s.env[name]; !ok {
+			env = append(env, kv)
+		}
+	}
+	for _, name := range zellij.EnvNames(s.env) {
+		env = append(env, name+"="+s.env[name])
+	}
+	return env
+}
+
+// IsAvailable returns true if pseudo terminals are supported on this system.
+func IsAvailable() bool {
+	return consoleAvailable()
+}
+
+// Start starts the program in workDir.
+func (s *NativeSession) Start(workDir string) error {
+	if s.DoesSessionExist() {
+		return fmt.Errorf("native session already running: %s", s.name)
+	}
+	s.workDir = workDir
+	return s.startProgram(s.program)
+}
+
+// startProgram starts the command line in the work directory and reads its
+// output into the terminal buffer until it exits.
+func (s *NativeSession) startProgram(command string) error {
+	height, width := s.termBuffer.GetSize()
+	c, err := startConsole(command, s.workDir, s.environ(), width, height)
+	if err != nil {
+		return fmt.Errorf("failed to start %s: %w", command, err)
+	}
+
+	exited := make(chan struct{})
+	s.mu.Lock()
+	s.console = c
+	s.exited = exited
+	s.mu.Unlock()
+
+	go s.readOutput(c)
+	go func() {
+		if err := c.Wait(); err != nil {
+			log.InfoLog.Printf("native session %s exited: %v", s.name, err)
+		}
+		close(exited)
+	}()
+	return nil
+}
+
+// readOutput copies the console's output to the terminal buffer, and to the
+// terminal while attached.
+func (s *NativeSession) readOutput(c console) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := c.Read(buf)
+		if n > 0 {
+			s.termBuffer.Write(buf[:n])
+			s.mu.Lock()
+			if s.output != nil {
+				_, _ = s.output.Write(buf[:n])
+			}
+			s.mu.Unlock()
+		}
+		if err != nil {
+			// Reading fails with EIO on Linux once the program has exited
+			return
+		}
+	}
+}
+
+// current returns the running console, or nil if the program isn't running.
+func (s *NativeSession) current() console {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.console == nil {
+		return nil
+	}
+	select {
+	case <-s.exited:
+		return nil
+	default:
+		return s.console
+	}
+}
+
+// stop terminates the program and releases its console.
+func (s *NativeSession) stop() {
+	s.mu.Lock()
+	c, exited := s.console, s.exited
+	s.console = nil
+	s.mu.Unlock()
+	if c == nil {
+		return
+	}
+
+	select {
+	case <-exited:
+	default:
+		if err := c.Kill(); err != nil {
+			log.WarningLog.Printf("failed to stop native session %s: %v", s.name, err)
+		}
+		select {
+		case <-exited:
+		case <-time.After(5 * time.Second):
+			log.WarningLog.Printf("native session %s did not exit", s.name)
+		}
+	}
+	_ = c.Close()
+}
+
+// Restore starts the program again if it isn't running, since nothing keeps it
+// alive across restarts of claude-squad.
+func (s *NativeSession) Restore() error {
+	if s.DoesSessionExist() {
+		return nil
+	}
+	if s.workDir == "" {
+		return fmt.Errorf("native session %s has no work directory", s.name)
+	}
+	return s.startProgram(s.program)
+}
+
+// Attach attaches to the session for interactive use. Ctrl+Q detaches.
+func (s *NativeSession) Attach() (chan struct{}, error) {
+	if s.current() == nil {
+		if err := s.Restore(); err != nil {
+			return nil, err
+		}
+	}
+	c := s.current()
+	if c == nil {
+		return nil, fmt.Errorf("native session %s is not running", s.name)
+	}
+
+	oldState, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set terminal raw mode: %w", err)
+	}
+
+	s.attachCh = make(chan struct{})
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.detach = sync.Once{}
+	attachCh, ctx, cancel := s.attachCh, s.ctx, s.cancel
+	detach := func() {
+		s.detach.Do(func() {
+			s.mu.Lock()
+			s.output = nil
+			s.mu.Unlock()
+			_ = term.Restore(int(os.Stdin.Fd()), oldState)
+			cancel()
+			close(attachCh)
+		})
+	}
+
+	s.resize(c)
+
+	// Redraw the current screen, then pass the program's output through
+	s.mu.Lock()
+	_, _ = os.Stdout.WriteString("\x1b[2J\x1b[H" + s.termBuffer.Render())
+	s.output = os.Stdout
+	s.mu.Unlock()
+
+	// Copy stdin -> console (with detach detection)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil || ctx.Err() != nil {
+				return
+			}
+			// Check for Ctrl+Q (ASCII 17) to detach
+			if i := bytes.IndexByte(buf[:n], 17); i >= 0 {
+				_, _ = c.Write(buf[:i])
+				detach()
+				return
+			}
+			_, _ = c.Write(buf[:n])
+		}
+	}()
+
+	// Detach when the program exits
+	s.mu.Lock()
+	exited := s.exited
+	s.mu.Unlock()
+	go func() {
+		select {
+		case <-exited:
+			detach()
+		case <-ctx.Done():
+		}
+	}()
+
+	go s.handleResize(ctx, c)
+
+	return attachCh, nil
+}
+
+// resize sizes the console and the terminal buffer to the terminal.
+func (s *NativeSession) resize(c console) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return
+	}
+	if err := c.Resize(width, height); err != nil {
+		log.ErrorLog.Printf("failed to resize native session %s: %v", s.name, err)
+		return
+	}
+	s.termBuffer.Resize(height, width)
+}
+
+// Detach disconnects from the current session.
+func (s *NativeSession) Detach() {
+	if err := s.DetachSafely(); err != nil {
+		panic(fmt.Sprintf("detach failed: %v", err))
+	}
+}
+
+// DetachSafely stops the program. Without a multiplexer the program can't
+// outlive its console, so this is used when pausing.
+func (s *NativeSession) DetachSafely() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.stop()
+	return nil
+}
+
+// Close terminates the session.
+func (s *NativeSession) Close() error {
+	s.stop()
+	s.closePanes()
+	s.termBuffer.Stop()
+	return nil
+}
+
+// SendKeys sends keystrokes to the session.
+func (s *NativeSession) SendKeys(keys string) error {
+	c := s.current()
+	if c == nil {
+		return fmt.Errorf("native session %s is not running", s.name)
+	}
+	_, err := c.Write([]byte(keys))
+	return err
+}
+
+// TapEnter sends an enter keystroke to the session.
+func (s *NativeSession) TapEnter() error {
+	return s.SendKeys("\r")
+}
+
+// TapDAndEnter sends 'D' followed by enter (for Aider/Gemini).
+func (s *NativeSession) TapDAndEnter() error {
+	return s.SendKeys("D\r")
+}
+
+// CapturePaneContent captures the current visible content of the pane.
+func (s *NativeSession) CapturePaneContent() (string, error) {
+	return s.termBuffer.Render(), nil
+}
+
+// CapturePaneContentWithOptions captures pane content with scroll history.
+// Native sessions keep no scroll history, so this is the current content.
+func (s *NativeSession) CapturePaneContentWithOptions(start, end string) (string, error) {
+	return s.CapturePaneContent()
+}
+
+// CaptureHistoryTail captures the end of the scroll history. Native sessions
+// have no scroll history, so this is the current content.
+func (s *NativeSession) CaptureHistoryTail(maxLines, maxBytes int) (string, bool, error) {
+	content, err := s.CapturePaneContent()
+	return content, false, err
+}
+
+// LoadScreen shows the screen in the preview until new output replaces it.
+func (s *NativeSession) LoadScreen(screen string) {
+	s.termBuffer.Load(screen)
+}
+
+// HasUpdated checks if pane content has changed since the last check.
+func (s *NativeSession) HasUpdated() (updated bool, hasPrompt bool) {
+	content := s.termBuffer.Render()
+
+	hash := sha256.Sum256([]byte(content))
+	s.mu.Lock()
+	updated = !bytes.Equal(s.lastHash, hash[:])
+	s.lastHash = hash[:]
+	s.mu.Unlock()
+
+	return updated, zellij.HasPrompt(s.program, content)
+}
+
+// DoesSessionExist returns true while the program is running.
+func (s *NativeSession) DoesSessionExist() bool {
+	return s.current() != nil
+}
+
+// SetDetachedSize sets the pane dimensions while detached.
+func (s *NativeSession) SetDetachedSize(width, height int) error {
+	s.termBuffer.Resize(height, width)
+	s.resizePanes(width, height)
+	if c := s.current(); c != nil {
+		if err := c.Resize(width, height); err != nil {
+			return fmt.Errorf("failed to resize native session to %dx%d: %w", width, height, err)
+		}
+	}
+	return nil
+}
+
+// GetProgram returns the program being run in this session.
+func (s *NativeSession) GetProgram() string {
+	return s.program
+}
+
+// IsProgramRunning returns true while the program is running.
+func (s *NativeSession) IsProgramRunning() (bool, error) {
+	return s.DoesSessionExist(), nil
+}
+
+// RestartProgram restarts the program with optional arguments.
+func (s *NativeSession) RestartProgram(args string) error {
+	s.stop()
+	s.termBuffer.Reset()
+
+	command := s.program
+	if args != "" {
+		command += " " + args
+	}
+	return s.startProgram(command)
+}
@@ -0,0 +1,141 @@
+//go:build !windows
+
+package native
+
+import (
+	"claude-squad/log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitFor polls the condition until it holds or the timeout passes.
+func waitFor(t *testing.T, condition func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return false
+}
+
+func TestNativeSessionCapturesOutput(t *testing.T) {
+	s := NewNativeSession("test", "echo native-output", "")
+	if err := s.Start(t.TempDir()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer s.Close()
+
+	if !waitFor(t, func() bool {
+		content, _ := s.CapturePaneContent()
+		return strings.Contains(content, "native-output")
+	}) {
+		content, _ := s.CapturePaneContent()
+		t.Fatalf("expected the output in the pane, got %q", content)
+	}
+	if !waitFor(t, func() bool { return !s.DoesSessionExist() }) {
+		t.Errorf("expected the session to end when the program exits")
+	}
+}
+
+func TestNativeSessionPassesEnv(t *testing.T) {
+	s := NewNativeSession("test", "echo flag=$FEATURE_FLAG", "")
+	s.SetEnv(map[string]string{"FEATURE_FLAG": "on"})
+	if err := s.Start(t.TempDir()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer s.Close()
+
+	if !waitFor(t, func() bool {
+		content, _ := s.CapturePaneContent()
+		return strings.Contains(content, "flag=on")
+	}) {
+		content, _ := s.CapturePaneContent()
+		t.Fatalf("expected the program to see the variable, got %q", content)
+	}
+}
+
+func TestNativeSessionRestoreStartsProgramAgain(t *testing.T) {
+	s := NewNativeSession("test", "echo restored", t.TempDir())
+	if err := s.Restore(); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	defer s.Close()
+
+	if !waitFor(t, func() bool {
+		content, _ := s.CapturePaneContent()
+		return strings.Contains(content, "restored")
+	}) {
+		t.Errorf("expected Restore to start the program")
+	}
+
+	if err := NewNativeSession("test", "echo", "").Restore(); err == nil {
+		t.Errorf("expected Restore without a work directory to fail")
+	}
+}
+
+func TestNativeSessionSendKeys(t *testing.T) {
+	s := NewNativeSession("test", "read line && echo got-$line", "")
+	if err := s.Start(t.TempDir()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.SendKeys("hello"); err != nil {
+		t.Fatalf("SendKeys failed: %v", err)
+	}
+	if err := s.TapEnter(); err != nil {
+		t.Fatalf("TapEnter failed: %v", err)
+	}
+	if !waitFor(t, func() bool {
+		content, _ := s.CapturePaneContent()
+		return strings.Contains(content, "got-hello")
+	}) {
+		content, _ := s.CapturePaneContent()
+		t.Errorf("expected the program to read the keys, got %q", content)
+	}
+}
+
+func TestNativeSessionPanes(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+
+	s := NewNativeSession("test", "sleep 5", "")
+	if err := s.Start(t.TempDir()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.StartPane("aux", "echo dev-server-ready; read line", t.TempDir()); err != nil {
+		t.Fatalf("StartPane failed: %v", err)
+	}
+	if !waitFor(t, func() bool {
+		content, _ := s.CapturePane("aux")
+		return strings.Contains(content, "dev-server-ready")
+	}) {
+		content, _ := s.CapturePane("aux")
+		t.Fatalf("expected the output of the pane, got %q", content)
+	}
+	if content, _ := s.CapturePaneContent(); strings.Contains(content, "dev-server-ready") {
+		t.Errorf("the output of the pane is in the program's pane")
+	}
+	if !s.PaneRunning("aux") {
+		t.Errorf("expected the pane to run")
+	}
+
+	if err := s.ClosePane("aux"); err != nil {
+		t.Fatalf("ClosePane failed: %v", err)
+	}
+	if s.PaneRunning("aux") {
+		t.Errorf("expected the closed pane to stop")
+	}
+	if _, err := s.CapturePane("aux"); err == nil {
+		t.Errorf("expected capturing a closed pane to fail")
+	}
+	if !s.DoesSessionExist() {
+		t.Errorf("closing the pane stopped the program")
+	}
+}
@@ -0,0 +1,145 @@
+package native
+
+import (
+	"claude-squad/log"
+	"claude-squad/session/zellij"
+	"fmt"
+	"time"
+)
+
+// pane is a command running under its own console next to the program.
+type pane struct {
+	console    console
+	exited     chan struct{}
+	termBuffer *zellij.TerminalBuffer
+}
+
+// running returns true until the command of the pane exits.
+func (p *pane) running() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// stop terminates the command of the pane and releases its console.
+func (p *pane) stop() {
+	if p.running() {
+		_ = p.console.Kill()
+		select {
+		case <-p.exited:
+		case <-time.After(5 * time.Second):
+		}
+	}
+	_ = p.console.Close()
+	p.termBuffer.Stop()
+}
+
+// StartPane runs the command in workDir under a console of its own. Nothing
+// outlives claude-squad in native sessions, so a running pane is kept and an
+// exited one is started again.
+func (s *NativeSession) StartPane(name, command, workDir string) error {
+	s.mu.Lock()
+	existing := s.panes[name]
+	s.mu.Unlock()
+	if existing != nil && existing.running() {
+		return nil
+	}
+	if existing != nil {
+		existing.stop()
+	}
+
+	height, width := s.termBuffer.GetSize()
+	c, err := startConsole(command, workDir, s.environ(), width, height)
+	if err != nil {
+		return fmt.Errorf("failed to start %s: %w", command, err)
+	}
+	p := &pane{console: c, exited: make(chan struct{}), termBuffer: zellij.NewTerminalBuffer()}
+	p.termBuffer.Resize(height, width)
+
+	s.mu.Lock()
+	if s.panes == nil {
+		s.panes = make(map[string]*pane)
+	}
+	s.panes[name] = p
+	s.mu.Unlock()
+
+	go func() {
+		buf := make([]byte, 4096)
+		for {
+			n, err := c.Read(buf)
+			if n > 0 {
+				p.termBuffer.Write(buf[:n])
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	go func() {
+		if err := c.Wait(); err != nil {
+			log.InfoLog.Printf("pane %s of native session %s exited: %v", name, s.name, err)
+		}
+		close(p.exited)
+	}()
+	return nil
+}
+
+// pane returns the named pane, or nil if there is none.
+func (s *NativeSession) pane(name string) *pane {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.panes[name]
+}
+
+// CapturePane captures the current visible content of the named pane.
+func (s *NativeSession) CapturePane(name string) (string, error) {
+	p := s.pane(name)
+	if p == nil {
+		return "", fmt.Errorf("native session %s has no pane %s", s.name, name)
+	}
+	return p.termBuffer.Render(), nil
+}
+
+// PaneRunning returns true while the command of the named pane runs.
+func (s *NativeSession) PaneRunning(name string) bool {
+	p := s.pane(name)
+	return p != nil && p.running()
+}
+
+// ClosePane stops the command of the named pane and removes the pane.
+func (s *NativeSession) ClosePane(name string) error {
+	s.mu.Lock()
+	p := s.panes[name]
+	delete(s.panes, name)
+	s.mu.Unlock()
+	if p != nil {
+		p.stop()
+	}
+	return nil
+}
+
+// closePanes stops the commands of all panes.
+func (s *NativeSession) closePanes() {
+	s.mu.Lock()
+	panes := s.panes
+	s.panes = nil
+	s.mu.Unlock()
+	for _, p := range panes {
+		p.stop()
+	}
+}
+
+// resizePanes sizes the consoles of the panes like the program's.
+func (s *NativeSession) resizePanes(width, height int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.panes {
+		p.termBuffer.Resize(height, width)
+		if p.running() {
+			_ = p.console.Resize(width, height)
+		}
+	}
+}
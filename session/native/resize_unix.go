@@ -0,0 +1,26 @@
+//go:build !windows
+
+package native
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// handleResize resizes the console whenever the terminal is resized.
+func (s *NativeSession) handleResize(ctx context.Context, c console) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+			s.resize(c)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package native
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// handleResize resizes the console whenever the terminal is resized. Windows
+// has no SIGWINCH, so the size is polled.
+func (s *NativeSession) handleResize(ctx context.Context, c console) {
+	lastWidth, lastHeight, _ := term.GetSize(int(os.Stdout.Fd()))
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			width, height, err := term.GetSize(int(os.Stdout.Fd()))
+			if err == nil && (width != lastWidth || height != lastHeight) {
+				lastWidth, lastHeight = width, height
+				s.resize(c)
+			}
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package session
 
 import (
+	"claude-squad/log"
+	"context"
 	"runtime"
 	"sync"
+	"time"
 )
 
 // UpdateResult contains the result of updating a single instance.
@@ -12,13 +15,45 @@ type UpdateResult struct {
 	HasPrompt    bool
 	Error        error
 	WasRestarted bool // True if the program was restarted due to not running
+	// PromptExcerpt is the question of the prompt the agent waits on, if HasPrompt
+	PromptExcerpt string
+	// RateLimited is true if a rate limit message showed up in the idle pane;
+	// the instance should be retried at RateLimitedUntil.
+	RateLimited      bool
+	RateLimitedUntil time.Time
+	// Shared is true if the result was polled by another process, which acts
+	// on it. It only shows the status; the prompt is answered over there.
+	Shared bool
 }
 
+// updateTimeout bounds how long ParallelUpdate waits for the check of a
+// single instance, so that a hung command doesn't hold up the others.
+var updateTimeout = 10 * time.Second
+
 // ParallelUpdate updates all instances concurrently and returns the results.
-// Uses a semaphore to limit concurrency to the number of CPUs.
+// Uses a semaphore to limit concurrency to the number of CPUs. Instances that
+// another process, like the daemon, has just polled take its results from the
+// poll cache instead of capturing their panes again. An instance whose check
+// takes longer than updateTimeout has no result, and is skipped until that
+// check returns.
 func ParallelUpdate(instances []*Instance) []UpdateResult {
+	return parallelUpdate(instances, true)
+}
+
+// ParallelPoll is ParallelUpdate for observers, like watch: the programs that
+// have stopped are left alone instead of being restarted.
+func ParallelPoll(instances []*Instance) []UpdateResult {
+	return parallelUpdate(instances, false)
+}
+
+// parallelUpdate is ParallelUpdate, restarting the stopped programs if restart
+// is true.
+func parallelUpdate(instances []*Instance, restart bool) []UpdateResult {
 	results := make([]UpdateResult, len(instances))
 	var wg sync.WaitGroup
+	cache := LoadPollCache()
+	var polledMu sync.Mutex
+	polled := make(map[string]PollEntry)
 
 	// Limit concurrency to number of CPUs
 	sem := make(chan struct{}, runtime.NumCPU())
@@ -27,6 +62,16 @@ func ParallelUpdate(instances []*Instance) []UpdateResult {
 		if instance == nil || !instance.Started() || instance.Paused() {
 			continue
 		}
+		// The previous check is still stuck in a command
+		if !instance.updating.CompareAndSwap(false, true) {
+			log.WarningLog.Printf("skipping the update of %s, its previous check hasn't finished", instance.Title)
+			continue
+		}
+		if entry, ok := cache.Fresh(instance.Title, time.Now()); ok {
+			results[i] = instance.sharedUpdate(entry, time.Now())
+			instance.updating.Store(false)
+			continue
+		}
 
 		wg.Add(1)
 		sem <- struct{}{} // Acquire semaphore
@@ -35,24 +80,125 @@ func ParallelUpdate(instances []*Instance) []UpdateResult {
 			defer wg.Done()
 			defer func() { <-sem }() // Release semaphore
 
-			// Check if program needs restart (e.g., after system reboot)
-			wasRestarted := false
-			if err := inst.CheckAndRestartProgram(); err == nil {
-				// If CheckAndRestartProgram returns nil without error,
-				// check if it actually restarted by seeing if program wasn't running
-				// This is a bit of a hack - ideally CheckAndRestartProgram would return this info
-			}
+			ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+			defer cancel()
+			start := time.Now()
+			done := make(chan UpdateResult, 1)
+			go func() {
+				defer inst.updating.Store(false)
+				result, changed, ok := inst.update(ctx, restart)
+				log.GetProfiler().RecordDuration(log.PollComponentPrefix+inst.Title, time.Since(start))
+				if !ok {
+					return
+				}
+				polledMu.Lock()
+				polled[inst.Title] = cache.pollEntry(inst, start, changed, result)
+				polledMu.Unlock()
+				done <- result
+			}()
 
-			updated, hasPrompt := inst.HasUpdated()
-			results[idx] = UpdateResult{
-				Instance:     inst,
-				Updated:      updated,
-				HasPrompt:    hasPrompt,
-				WasRestarted: wasRestarted,
+			select {
+			case results[idx] = <-done:
+			case <-ctx.Done():
+				log.WarningLog.Printf("updating %s timed out after %s", inst.Title, updateTimeout)
 			}
 		}(i, instance)
 	}
 
+	wg.Wait()
+	polledMu.Lock()
+	defer polledMu.Unlock()
+	if err := cache.Save(polled); err != nil {
+		log.WarningLog.Printf("could not share poll results: %v", err)
+	}
+	return results
+}
+
+// update checks whether the instance's pane changed or shows a prompt,
+// restarting its program first if it has stopped and restart is true. It
+// stops between steps once the context is done, and then returns false.
+func (i *Instance) update(ctx context.Context, restart bool) (result UpdateResult, changed bool, ok bool) {
+	// Check if program needs restart (e.g., after system reboot)
+	var wasRestarted bool
+	if restart {
+		wasRestarted, _ = i.checkAndRestartProgram(ctx)
+		if ctx.Err() != nil {
+			return UpdateResult{}, false, false
+		}
+	}
+
+	now := time.Now()
+	changed, hasPrompt := i.hasUpdated(ctx)
+	i.recordPoll(now, changed)
+	updated := changed
+	// Claude's hooks report the status precisely; pane changes are the fallback
+	if status, ok := i.HookStatus(); ok {
+		updated = status == Running
+	}
+	result = UpdateResult{
+		Instance:     i,
+		Updated:      updated,
+		HasPrompt:    hasPrompt,
+		WasRestarted: wasRestarted,
+	}
+	if ctx.Err() != nil {
+		return UpdateResult{}, false, false
+	}
+	if hasPrompt {
+		result.PromptExcerpt = "waiting for an answer"
+		if content, err := i.Preview(); err == nil {
+			if excerpt := PromptExcerpt(content); excerpt != "" {
+				result.PromptExcerpt = excerpt
+			}
+		}
+	}
+	// A rate limited agent stops working, so only idle panes are searched
+	if !updated && !hasPrompt {
+		result.RateLimitedUntil, result.RateLimited = i.detectRateLimit(time.Now())
+	}
+	return result, changed, ctx.Err() == nil
+}
+
+// RestartResult is the outcome of restarting an instance's stopped program.
+type RestartResult struct {
+	Instance *Instance
+	Error    error
+}
+
+// RestartStoppedPrograms checks the programs of all running instances
+// concurrently and restarts those that have stopped, e.g. while the machine
+// was asleep or off, resuming Claude's conversation when its session ID is
+// known. Only the instances whose program had stopped, or couldn't be checked,
+// are returned.
+func RestartStoppedPrograms(instances []*Instance) []RestartResult {
+	var mu sync.Mutex
+	var results []RestartResult
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, runtime.NumCPU())
+
+	for _, instance := range instances {
+		// Sessions that weren't restored yet have no program to check
+		if instance == nil || !instance.Started() || instance.Paused() || instance.RestorePending() {
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(inst *Instance) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			restarted, err := inst.CheckAndRestartProgram()
+			if restarted || err != nil {
+				mu.Lock()
+				results = append(results, RestartResult{Instance: inst, Error: err})
+				mu.Unlock()
+			}
+		}(instance)
+	}
+
 	wg.Wait()
 	return results
 }
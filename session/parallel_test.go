@@ -0,0 +1,22 @@
+package session
+
+import (
+	"claude-squad/log"
+	"testing"
+)
+
+func TestParallelUpdateSkipsUnfinishedChecks(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+	t.Setenv("HOME", t.TempDir())
+	stuck := &Instance{Title: "stuck", Status: Running, started: true}
+	stuck.updating.Store(true)
+
+	results := ParallelUpdate([]*Instance{stuck})
+	if results[0].Instance != nil {
+		t.Errorf("ParallelUpdate() updated an instance whose previous check hasn't finished")
+	}
+	if !stuck.updating.Load() {
+		t.Errorf("ParallelUpdate() cleared the flag of the unfinished check")
+	}
+}
@@ -0,0 +1,142 @@
+package session
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// pollCacheFileName is the file in the config directory the poll results
+	// are shared through.
+	pollCacheFileName = "poll_cache.json"
+	// pollCacheMaxAge is how long another process's poll result is used
+	// instead of capturing the pane again.
+	pollCacheMaxAge = 2 * time.Second
+)
+
+// PollEntry is the outcome of polling an instance's pane.
+type PollEntry struct {
+	// CheckedAt is when the pane was captured, and ChangedAt when its content
+	// last changed.
+	CheckedAt time.Time `json:"checked_at"`
+	ChangedAt time.Time `json:"changed_at"`
+	HasPrompt bool      `json:"has_prompt,omitempty"`
+	// PromptExcerpt is the question of the prompt, if HasPrompt.
+	PromptExcerpt string `json:"prompt_excerpt,omitempty"`
+	// PID is the process that polled the pane, and acted on the prompt and
+	// rate limit it found.
+	PID int `json:"pid"`
+}
+
+// PollCache shares the poll results of instances between the TUI and the
+// daemon, so that only one of them captures each pane while both run. Entries
+// are keyed by instance title. Concurrent writers may drop each other's
+// entries, which only costs an extra capture on the next poll.
+type PollCache struct {
+	path    string
+	entries map[string]PollEntry
+}
+
+// LoadPollCache reads the poll cache in the config directory. A missing or
+// unreadable cache is empty.
+func LoadPollCache() *PollCache {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		log.WarningLog.Printf("could not find the poll cache: %v", err)
+		return &PollCache{entries: map[string]PollEntry{}}
+	}
+	return loadPollCache(filepath.Join(dir, pollCacheFileName))
+}
+
+func loadPollCache(path string) *PollCache {
+	c := &PollCache{path: path, entries: map[string]PollEntry{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		log.WarningLog.Printf("ignoring corrupt poll cache %s: %v", path, err)
+		c.entries = map[string]PollEntry{}
+	}
+	return c
+}
+
+// Fresh returns the entry of the instance if another process polled it less
+// than pollCacheMaxAge ago.
+func (c *PollCache) Fresh(title string, now time.Time) (PollEntry, bool) {
+	entry, ok := c.entries[title]
+	if !ok || entry.PID == os.Getpid() || now.Sub(entry.CheckedAt) >= pollCacheMaxAge {
+		return PollEntry{}, false
+	}
+	return entry, true
+}
+
+// Save writes the entries polled by this process, keeping the entries of
+// instances polled elsewhere since the cache was loaded.
+func (c *PollCache) Save(polled map[string]PollEntry) error {
+	if c.path == "" || len(polled) == 0 {
+		return nil
+	}
+	entries := loadPollCache(c.path).entries
+	for title, entry := range polled {
+		entries[title] = entry
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal poll cache: %w", err)
+	}
+	// Readers in the other process must never see a partial file
+	tmp := c.path + fmt.Sprintf(".%d.tmp", os.Getpid())
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write poll cache: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace poll cache: %w", err)
+	}
+	c.entries = entries
+	return nil
+}
+
+// pollEntry records the outcome of capturing the instance's pane. The time
+// the content changed is carried over from the previous entry.
+func (c *PollCache) pollEntry(inst *Instance, now time.Time, changed bool, result UpdateResult) PollEntry {
+	entry := PollEntry{
+		CheckedAt:     now,
+		ChangedAt:     c.entries[inst.Title].ChangedAt,
+		HasPrompt:     result.HasPrompt,
+		PromptExcerpt: result.PromptExcerpt,
+		PID:           os.Getpid(),
+	}
+	if changed || entry.ChangedAt.IsZero() {
+		entry.ChangedAt = now
+	}
+	inst.sharedChangedAt = entry.ChangedAt
+	return entry
+}
+
+// sharedUpdate turns another process's poll result into the update of the
+// instance, without capturing its pane. The result is marked Shared, since the
+// other process answers the prompt and records the rate limit, which reach
+// this one through the stored state.
+func (i *Instance) sharedUpdate(entry PollEntry, now time.Time) UpdateResult {
+	updated := entry.ChangedAt.After(i.sharedChangedAt)
+	i.sharedChangedAt = entry.ChangedAt
+	i.recordPoll(now, updated)
+	if status, ok := i.HookStatus(); ok {
+		updated = status == Running
+	}
+	result := UpdateResult{
+		Instance:      i,
+		Updated:       updated,
+		HasPrompt:     entry.HasPrompt,
+		PromptExcerpt: entry.PromptExcerpt,
+		Shared:        true,
+	}
+	return result
+}
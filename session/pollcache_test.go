@@ -0,0 +1,70 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPollCacheSharesResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), pollCacheFileName)
+	now := time.Now()
+	other := os.Getpid() + 1
+
+	// Another process polled one instance just now and one a while ago
+	writer := loadPollCache(path)
+	err := writer.Save(map[string]PollEntry{
+		"fresh": {CheckedAt: now, ChangedAt: now, HasPrompt: true, PromptExcerpt: "Allow?", PID: other},
+		"stale": {CheckedAt: now.Add(-time.Minute), ChangedAt: now.Add(-time.Minute), PID: other},
+	})
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	cache := loadPollCache(path)
+	entry, ok := cache.Fresh("fresh", now)
+	if !ok {
+		t.Fatal("Fresh() found no entry polled by the other process")
+	}
+	if _, ok := cache.Fresh("stale", now); ok {
+		t.Error("Fresh() returned an entry older than the maximum age")
+	}
+	if _, ok := cache.Fresh("missing", now); ok {
+		t.Error("Fresh() returned an entry for an instance that wasn't polled")
+	}
+
+	inst := &Instance{Title: "fresh"}
+	result := inst.sharedUpdate(entry, now)
+	if !result.Updated || !result.HasPrompt || result.PromptExcerpt != "Allow?" {
+		t.Errorf("sharedUpdate() = %+v, want an update with the prompt", result)
+	}
+	// The other process answers the prompt, this one only shows it
+	if !result.Shared {
+		t.Error("sharedUpdate() should mark the result as shared")
+	}
+	if result := inst.sharedUpdate(entry, now.Add(time.Second)); result.Updated {
+		t.Error("sharedUpdate() reported an update for content it has already seen")
+	}
+}
+
+func TestPollCacheIgnoresOwnEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), pollCacheFileName)
+	now := time.Now()
+	inst := &Instance{Title: "own"}
+
+	cache := loadPollCache(path)
+	entry := cache.pollEntry(inst, now, true, UpdateResult{Instance: inst})
+	if err := cache.Save(map[string]PollEntry{inst.Title: entry}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, ok := loadPollCache(path).Fresh(inst.Title, now); ok {
+		t.Error("Fresh() returned an entry polled by this process")
+	}
+
+	// Unchanged content keeps the time it last changed
+	later := loadPollCache(path).pollEntry(inst, now.Add(time.Second), false, UpdateResult{Instance: inst})
+	if !later.ChangedAt.Equal(entry.ChangedAt) {
+		t.Errorf("ChangedAt = %v, want %v", later.ChangedAt, entry.ChangedAt)
+	}
+}
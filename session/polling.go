@@ -0,0 +1,86 @@
+package session
+
+import (
+	"sort"
+	"time"
+)
+
+// PollPolicy decides which instances are polled on a metadata tick. Capturing
+// a pane is the expensive part of updating an instance, so instances that are
+// not looked at are polled less often.
+type PollPolicy struct {
+	// Selected is the instance shown in the preview, polled on every tick.
+	Selected *Instance
+	// BackgroundInterval is the minimum time between two polls of any other
+	// instance.
+	BackgroundInterval time.Duration
+	// IdleTimeout stops polling instances whose pane hasn't changed for this
+	// long, until they are selected, prompted or attached to. 0 never stops.
+	IdleTimeout time.Duration
+	// MaxPerTick caps how many instances are polled at once, so that large
+	// squads are polled a page at a time, the longest unpolled first. The
+	// selected instance is always polled. 0 means no cap.
+	MaxPerTick int
+}
+
+// Due returns the instances that should be polled now.
+func (p PollPolicy) Due(instances []*Instance, now time.Time) []*Instance {
+	var due []*Instance
+	for _, instance := range instances {
+		if instance == nil || !instance.Started() || instance.Paused() {
+			continue
+		}
+		if instance == p.Selected || instance.lastPolledAt.IsZero() {
+			due = append(due, instance)
+			continue
+		}
+		// A working agent keeps changing its pane, so only idle ones are paused
+		if p.IdleTimeout > 0 && instance.Status != Running && now.Sub(instance.lastChangedAt) >= p.IdleTimeout {
+			continue
+		}
+		if now.Sub(instance.lastPolledAt) >= p.BackgroundInterval {
+			due = append(due, instance)
+		}
+	}
+	return p.page(due)
+}
+
+// page returns at most MaxPerTick of the due instances: the selected one and
+// those polled longest ago, in their original order.
+func (p PollPolicy) page(due []*Instance) []*Instance {
+	if p.MaxPerTick <= 0 || len(due) <= p.MaxPerTick {
+		return due
+	}
+	byAge := make([]*Instance, len(due))
+	copy(byAge, due)
+	sort.SliceStable(byAge, func(a, b int) bool {
+		if (byAge[a] == p.Selected) != (byAge[b] == p.Selected) {
+			return byAge[a] == p.Selected
+		}
+		return byAge[a].lastPolledAt.Before(byAge[b].lastPolledAt)
+	})
+	chosen := make(map[*Instance]bool, p.MaxPerTick)
+	for _, instance := range byAge[:p.MaxPerTick] {
+		chosen[instance] = true
+	}
+	page := make([]*Instance, 0, p.MaxPerTick)
+	for _, instance := range due {
+		if chosen[instance] {
+			page = append(page, instance)
+		}
+	}
+	return page
+}
+
+// recordPoll records that the pane was polled, and whether it changed.
+func (i *Instance) recordPoll(now time.Time, changed bool) {
+	i.lastPolledAt = now
+	if changed || i.lastChangedAt.IsZero() {
+		i.lastChangedAt = now
+	}
+}
+
+// markActive resumes polling an instance the user interacts with.
+func (i *Instance) markActive() {
+	i.lastChangedAt = time.Now()
+}
@@ -0,0 +1,81 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollPolicyDue(t *testing.T) {
+	now := time.Now()
+	selected := &Instance{Title: "selected", Status: Ready, started: true,
+		lastPolledAt: now.Add(-time.Second), lastChangedAt: now.Add(-time.Hour)}
+	fresh := &Instance{Title: "fresh", Status: Ready, started: true}
+	recent := &Instance{Title: "recent", Status: Ready, started: true,
+		lastPolledAt: now.Add(-5 * time.Second), lastChangedAt: now.Add(-5 * time.Second)}
+	background := &Instance{Title: "background", Status: Ready, started: true,
+		lastPolledAt: now.Add(-20 * time.Second), lastChangedAt: now.Add(-time.Minute)}
+	idle := &Instance{Title: "idle", Status: Ready, started: true,
+		lastPolledAt: now.Add(-20 * time.Second), lastChangedAt: now.Add(-10 * time.Minute)}
+	working := &Instance{Title: "working", Status: Running, started: true,
+		lastPolledAt: now.Add(-20 * time.Second), lastChangedAt: now.Add(-10 * time.Minute)}
+	paused := &Instance{Title: "paused", Status: Paused, started: true}
+	instances := []*Instance{selected, fresh, recent, background, idle, working, paused, nil}
+
+	tests := []struct {
+		name   string
+		policy PollPolicy
+		want   []string
+	}{
+		{
+			name:   "selected, due and working instances",
+			policy: PollPolicy{Selected: selected, BackgroundInterval: 15 * time.Second, IdleTimeout: 5 * time.Minute},
+			want:   []string{"selected", "fresh", "background", "working"},
+		},
+		{
+			name:   "a page of the longest unpolled instances",
+			policy: PollPolicy{Selected: selected, BackgroundInterval: 15 * time.Second, IdleTimeout: 5 * time.Minute, MaxPerTick: 2},
+			want:   []string{"selected", "fresh"},
+		},
+		{
+			name:   "idle instances are polled without a timeout",
+			policy: PollPolicy{Selected: selected, BackgroundInterval: 15 * time.Second},
+			want:   []string{"selected", "fresh", "background", "idle", "working"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, instance := range tt.policy.Due(instances, now) {
+				got = append(got, instance.Title)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Due() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Due() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestRecordPollResumesAfterActivity(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	instance := &Instance{Title: "idle", Status: Ready, started: true}
+	instance.recordPoll(start, false)
+	instance.recordPoll(start.Add(time.Minute), false)
+	if !instance.lastChangedAt.Equal(start) {
+		t.Errorf("lastChangedAt = %v, want the first poll at %v", instance.lastChangedAt, start)
+	}
+
+	policy := PollPolicy{IdleTimeout: 5 * time.Minute}
+	if due := policy.Due([]*Instance{instance}, time.Now()); len(due) != 0 {
+		t.Errorf("Due() = %v, want no idle instances", due)
+	}
+	instance.markActive()
+	if due := policy.Due([]*Instance{instance}, time.Now()); len(due) != 1 {
+		t.Errorf("Due() = %v, want the instance after activity", due)
+	}
+}
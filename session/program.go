@@ -0,0 +1,69 @@
+package session
+
+import (
+	"claude-squad/session/zellij"
+	"path/filepath"
+	"strings"
+)
+
+// expandProgram returns the program command line with the placeholders
+// {branch}, {title}, {worktree} and {repo} replaced by the branch, title,
+// worktree path and repository name of the instance. The values are quoted for
+// where the placeholders are, bare or in quotes, so that they are always taken
+// literally by the shell.
+func (i *Instance) expandProgram() string {
+	worktree := i.Path
+	repo := i.Path
+	if i.gitWorktree != nil {
+		worktree = i.gitWorktree.GetWorktreePath()
+		repo = i.gitWorktree.GetRepoPath()
+	}
+	values := map[string]string{
+		"{branch}":   i.Branch,
+		"{title}":    i.Title,
+		"{worktree}": worktree,
+		"{repo}":     filepath.Base(repo),
+	}
+
+	program := i.Program
+	var b strings.Builder
+	// quote is the quote the command line is in at n, 0 outside of quotes
+	var quote byte
+	for n := 0; n < len(program); n++ {
+		c := program[n]
+		if c == '{' {
+			if end := strings.IndexByte(program[n:], '}'); end > 0 {
+				if value, ok := values[program[n:n+end+1]]; ok {
+					b.WriteString(quoteIn(value, quote))
+					n += end
+					continue
+				}
+			}
+		}
+		switch {
+		case c == '\\' && quote != '\'' && n+1 < len(program):
+			// The escaped character is taken as it is
+			b.WriteByte(c)
+			n++
+			c = program[n]
+		case quote == 0 && (c == '\'' || c == '"'):
+			quote = c
+		case c == quote:
+			quote = 0
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// quoteIn quotes s for sh, to be inserted inside the given quote, 0 for none.
+func quoteIn(s string, quote byte) string {
+	switch quote {
+	case '"':
+		return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(s)
+	case '\'':
+		// Close the quote around the quoted value
+		return "'" + zellij.ShellQuote(s) + "'"
+	}
+	return zellij.ShellQuote(s)
+}
@@ -0,0 +1,55 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandProgram(t *testing.T) {
+	instance := &Instance{
+		Title:   "fix login",
+		Branch:  "user/fix-login",
+		Path:    "/src/app",
+		Program: `claude --append-system-prompt "You are working on branch {branch} of {repo}" # {title} {unknown}`,
+	}
+	assert.Equal(t, `claude --append-system-prompt "You are working on branch user/fix-login of app" # 'fix login' {unknown}`, instance.expandProgram())
+
+	instance.Program = "aider {worktree}"
+	assert.Equal(t, "aider '/src/app'", instance.expandProgram())
+
+	instance.gitWorktree = git.NewGitWorktreeFromStorage("/src/api", "/worktrees/fix-login", "fix login", "user/fix-login", "")
+	instance.Program = "aider --cwd {worktree} --repo {repo}"
+	assert.Equal(t, "aider --cwd '/worktrees/fix-login' --repo 'api'", instance.expandProgram())
+}
+
+func TestExpandProgramQuotesValues(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	title := `it's "$(touch pwned)" ` + "`touch pwned`" + ` \ ; touch pwned`
+	instance := &Instance{Title: title, Path: t.TempDir()}
+
+	// The title comes out as it is, wherever the placeholder is
+	for _, program := range []string{
+		`printf %s {title}`,
+		`printf %s "{title}"`,
+		`printf %s '{title}'`,
+		`printf %s "title: {title}"`,
+	} {
+		instance.Program = program
+		cmd := exec.Command("sh", "-c", instance.expandProgram())
+		cmd.Dir = instance.Path
+		output, err := cmd.Output()
+		require.NoError(t, err, program)
+		want := title
+		if program == `printf %s "title: {title}"` {
+			want = "title: " + title
+		}
+		assert.Equal(t, want, string(output), program)
+		assert.NoFileExists(t, instance.Path+"/pwned", program)
+	}
+}
@@ -0,0 +1,31 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadPromptFile reads a prompt from the file at path, or from stdin if path is
+// "-". Surrounding blank lines are dropped, and an empty prompt is an error.
+func ReadPromptFile(path string, stdin io.Reader) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read prompt: %w", err)
+	}
+	prompt := strings.Trim(string(data), "\r\n")
+	if strings.TrimSpace(prompt) == "" {
+		if path == "-" {
+			return "", fmt.Errorf("the prompt from stdin is empty")
+		}
+		return "", fmt.Errorf("the prompt in %s is empty", path)
+	}
+	return prompt, nil
+}
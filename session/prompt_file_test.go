@@ -0,0 +1,28 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPromptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task.md")
+	require.NoError(t, os.WriteFile(path, []byte("\n# Task\n\n  Fix the login form\n\n"), 0644))
+	prompt, err := ReadPromptFile(path, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "# Task\n\n  Fix the login form", prompt)
+
+	prompt, err = ReadPromptFile("-", strings.NewReader("from stdin\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "from stdin", prompt)
+
+	_, err = ReadPromptFile("-", strings.NewReader(" \n\n"))
+	assert.Error(t, err)
+	_, err = ReadPromptFile(filepath.Join(t.TempDir(), "missing.md"), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"claude-squad/audit"
+	"fmt"
+	"time"
+)
+
+const (
+	// autoYesBurstLimit is how many prompts auto-yes may answer within
+	// autoYesBurstWindow. An agent asking for more permissions than that is
+	// likely stuck in a loop, or doing more than it was asked to.
+	autoYesBurstLimit  = 30
+	autoYesBurstWindow = 10 * time.Minute
+)
+
+// Quarantine puts the instance in quarantine after suspicious activity, e.g.
+// a leaked secret or a change to a protected path. Auto-yes is disabled and
+// pushing and landing are refused until the user acknowledges the reason with
+// AcknowledgeQuarantine. Quarantining an instance again keeps the first reason.
+func (i *Instance) Quarantine(reason string) {
+	if i.Quarantined() {
+		return
+	}
+	now := time.Now()
+	i.QuarantinedAt = &now
+	i.QuarantineReason = reason
+	i.AutoYes = false
+	audit.Record(audit.EventQuarantined, i.Title, reason)
+}
+
+// checkAutoYesBurst quarantines the instance if auto-yes answered more than
+// autoYesBurstLimit prompts within autoYesBurstWindow before now.
+func (i *Instance) checkAutoYesBurst(now time.Time) {
+	answered := 0
+	for _, at := range i.AutoYesEvents {
+		if now.Sub(at) <= autoYesBurstWindow {
+			answered++
+		}
+	}
+	if answered > autoYesBurstLimit {
+		i.Quarantine(fmt.Sprintf("auto-yes answered %d prompts within %s", answered, autoYesBurstWindow))
+	}
+}
+
+// Quarantined returns true if the instance is in quarantine.
+func (i *Instance) Quarantined() bool {
+	return i.QuarantinedAt != nil
+}
+
+// AcknowledgeQuarantine takes the instance out of quarantine. Auto-yes stays
+// off until it is turned on again.
+func (i *Instance) AcknowledgeQuarantine() {
+	if !i.Quarantined() {
+		return
+	}
+	audit.Record(audit.EventQuarantineCleared, i.Title, i.QuarantineReason)
+	i.QuarantinedAt = nil
+	i.QuarantineReason = ""
+}
+
+// checkNotQuarantined returns an error if the instance is in quarantine, for
+// actions that quarantine blocks.
+func (i *Instance) checkNotQuarantined(action string) error {
+	if !i.Quarantined() {
+		return nil
+	}
+	return fmt.Errorf("cannot %s '%s' while it is quarantined (%s)", action, i.Title, i.QuarantineReason)
+}
+
+// CheckPush returns an error if the instance's branch may not be pushed.
+func (i *Instance) CheckPush() error {
+	return i.checkNotQuarantined("push")
+}
@@ -0,0 +1,80 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuarantine(t *testing.T) {
+	instance := &Instance{Title: "agent", AutoYes: true, started: true, LandState: LandQueued}
+
+	instance.Quarantine("secret in diff")
+	instance.Quarantine("protected path changed")
+	if !instance.Quarantined() || instance.QuarantineReason != "secret in diff" {
+		t.Fatalf("Quarantine() reason = %q, want the first reason", instance.QuarantineReason)
+	}
+	if instance.AutoYes {
+		t.Errorf("Quarantine() left auto-yes on")
+	}
+	if err := instance.CheckPush(); err == nil {
+		t.Errorf("CheckPush() = nil, want an error while quarantined")
+	}
+	if err := instance.Land(""); err == nil || instance.LandState != LandFailed {
+		t.Errorf("Land() = %v with state %q, want landing to fail while quarantined", err, instance.LandState)
+	}
+
+	data := instance.ToInstanceData()
+	if data.QuarantinedAt == nil || data.QuarantineReason != "secret in diff" {
+		t.Errorf("ToInstanceData() lost the quarantine: %v %q", data.QuarantinedAt, data.QuarantineReason)
+	}
+
+	instance.AcknowledgeQuarantine()
+	if instance.Quarantined() || instance.QuarantineReason != "" {
+		t.Errorf("AcknowledgeQuarantine() left the instance quarantined")
+	}
+	if err := instance.CheckPush(); err != nil {
+		t.Errorf("CheckPush() = %v after acknowledging", err)
+	}
+	if instance.AutoYes {
+		t.Errorf("AcknowledgeQuarantine() turned auto-yes back on")
+	}
+}
+
+// keysSession is a Multiplexer that accepts keys.
+type keysSession struct {
+	Multiplexer
+	sent []string
+}
+
+func (s *keysSession) SendKeys(keys string) error {
+	s.sent = append(s.sent, keys)
+	return nil
+}
+
+func (s *keysSession) TapEnter() error {
+	s.sent = append(s.sent, "\r")
+	return nil
+}
+
+func TestAutoYesBurstQuarantines(t *testing.T) {
+	fake := &keysSession{}
+	instance := &Instance{Title: "agent", Program: "claude", AutoYes: true, started: true, session: fake}
+
+	for range autoYesBurstLimit {
+		instance.TapEnter()
+	}
+	if instance.Quarantined() {
+		t.Fatalf("quarantined after %d auto-yes answers, the limit", autoYesBurstLimit)
+	}
+	instance.TapEnter()
+	if !instance.Quarantined() || !strings.Contains(instance.QuarantineReason, "auto-yes") {
+		t.Fatalf("not quarantined past the auto-yes limit, reason %q", instance.QuarantineReason)
+	}
+
+	// Quarantine turns auto-yes off, so no more prompts are answered
+	sent := len(fake.sent)
+	instance.TapEnter()
+	if len(fake.sent) != sent {
+		t.Errorf("auto-yes answered a prompt while quarantined")
+	}
+}
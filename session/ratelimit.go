@@ -0,0 +1,131 @@
+package session
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// rateLimitTailLines is how many lines at the bottom of the pane are
+	// searched for rate limit messages.
+	rateLimitTailLines = 20
+	// rateLimitCheckInterval is how often the pane of an idle instance is
+	// searched for rate limit messages.
+	rateLimitCheckInterval = 10 * time.Second
+	// defaultRateLimitCooldown is how long to wait before retrying when the
+	// message doesn't say.
+	defaultRateLimitCooldown = 2 * time.Minute
+	// rateLimitRetryPrompt is sent when an instance was rate limited before any
+	// prompt was sent to it from claude-squad.
+	rateLimitRetryPrompt = "continue"
+)
+
+var (
+	// rateLimitPattern matches the lines Claude shows for the errors of rate
+	// limited and overloaded APIs, and for its usage limit. Free text isn't
+	// matched, so that agents working on rate limiting code don't trigger it.
+	rateLimitPattern = regexp.MustCompile(`API Error:\s*(429|529)\b|rate_limit_error|overloaded_error|` +
+		`usage limit reached\|\d{10}\b`)
+	// rateLimitResetEpochPattern matches the reset time of Claude's usage limit
+	// message, e.g. "Claude AI usage limit reached|1748281200".
+	rateLimitResetEpochPattern = regexp.MustCompile(`limit reached\|(\d{10})\b`)
+	// rateLimitRetryInPattern matches cooldowns like "try again in 30 seconds".
+	rateLimitRetryInPattern = regexp.MustCompile(`(?i)(?:retry|try again|wait)\D{0,20}?\b(\d+)\s*` +
+		`(seconds?|secs?|s|minutes?|mins?|m|hours?|hrs?|h)\b`)
+	// rateLimitResetAtPattern matches reset times like "resets 5pm" or
+	// "will reset at 3:30 pm".
+	rateLimitResetAtPattern = regexp.MustCompile(`(?i)resets?\s+(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)\b`)
+)
+
+// ParseRateLimit searches the content of a pane for a rate limit message. It
+// returns when to retry, as given by the message or after a default cooldown.
+func ParseRateLimit(content string, now time.Time) (time.Time, bool) {
+	if !rateLimitPattern.MatchString(content) {
+		return time.Time{}, false
+	}
+
+	until := now.Add(defaultRateLimitCooldown)
+	if match := rateLimitResetEpochPattern.FindStringSubmatch(content); match != nil {
+		seconds, _ := strconv.ParseInt(match[1], 10, 64)
+		until = time.Unix(seconds, 0)
+	} else if match := rateLimitRetryInPattern.FindStringSubmatch(content); match != nil {
+		amount, _ := strconv.Atoi(match[1])
+		unit := time.Second
+		switch strings.ToLower(match[2])[0] {
+		case 'm':
+			unit = time.Minute
+		case 'h':
+			unit = time.Hour
+		}
+		until = now.Add(time.Duration(amount) * unit)
+	} else if match := rateLimitResetAtPattern.FindStringSubmatch(content); match != nil {
+		hour, _ := strconv.Atoi(match[1])
+		minute, _ := strconv.Atoi(match[2])
+		if hour == 12 {
+			hour = 0
+		}
+		if strings.EqualFold(match[3], "pm") {
+			hour += 12
+		}
+		until = time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+		if !until.After(now) {
+			until = until.Add(24 * time.Hour)
+		}
+	}
+
+	if !until.After(now) {
+		until = now.Add(defaultRateLimitCooldown)
+	}
+	return until, true
+}
+
+// detectRateLimit searches the bottom of the pane of an idle instance for a
+// rate limit message, at most every rateLimitCheckInterval. A message that
+// was already detected is ignored until the pane changes.
+func (i *Instance) detectRateLimit(now time.Time) (time.Time, bool) {
+	if i.RateLimited() || now.Sub(i.rateLimitCheckedAt) < rateLimitCheckInterval {
+		return time.Time{}, false
+	}
+	i.rateLimitCheckedAt = now
+
+	content, err := i.Preview()
+	if err != nil {
+		return time.Time{}, false
+	}
+	tail := lastLines(content, rateLimitTailLines)
+	if tail == i.rateLimitTail {
+		return time.Time{}, false
+	}
+	until, ok := ParseRateLimit(tail, now)
+	if ok {
+		i.rateLimitTail = tail
+	}
+	return until, ok
+}
+
+// RateLimited returns true if the instance hit a rate limit and waits to retry.
+func (i *Instance) RateLimited() bool {
+	return i.RateLimitedUntil != nil
+}
+
+// MarkRateLimited marks the instance as rate limited until the given time,
+// when its last prompt is retried.
+func (i *Instance) MarkRateLimited(until time.Time) {
+	i.RateLimitedUntil = &until
+}
+
+// RateLimitRetry returns the prompt to retry once the cooldown of a rate
+// limited instance is over, and clears the rate limit. It returns false while
+// the instance isn't due for a retry.
+func (i *Instance) RateLimitRetry(now time.Time) (string, bool) {
+	if !i.RateLimited() || now.Before(*i.RateLimitedUntil) || !i.started || i.Paused() {
+		return "", false
+	}
+	i.RateLimitedUntil = nil
+	if i.LastPrompt == "" {
+		return rateLimitRetryPrompt, true
+	}
+	return i.LastPrompt, true
+}
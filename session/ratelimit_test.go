@@ -0,0 +1,56 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Date(2025, 6, 1, 14, 0, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		content string
+		want    time.Time
+		limited bool
+	}{
+		{"working", "⏺ Implemented the rate limiter in api/limit.go", time.Time{}, false},
+		{"overloaded", `API Error: 529 {"type":"error","error":{"type":"overloaded_error"}}`, now.Add(defaultRateLimitCooldown), true},
+		{"retry in seconds", "API Error: 429 Too Many Requests. Please try again in 30 seconds.", now.Add(30 * time.Second), true},
+		{"retry in minutes", "rate_limit_error: retry after 5 min", now.Add(5 * time.Minute), true},
+		{"usage limit epoch", "Claude AI usage limit reached|1748800800", time.Unix(1748800800, 0), true},
+		{"resets later today", "API Error: 429 rate_limit_error · resets 5pm", time.Date(2025, 6, 1, 17, 0, 0, 0, time.Local), true},
+		{"resets tomorrow", `API Error: 429 {"type":"rate_limit_error","message":"Your limit will reset at 9:30 am."}`, time.Date(2025, 6, 2, 9, 30, 0, 0, time.Local), true},
+		{"free text", "⏺ Return 429 Too Many Requests until the limit will reset; usage limit reached ∙ rate limit exceeded", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, limited := ParseRateLimit(tt.content, now)
+			if limited != tt.limited || !got.Equal(tt.want) {
+				t.Errorf("ParseRateLimit() = %v, %v, want %v, %v", got, limited, tt.want, tt.limited)
+			}
+		})
+	}
+}
+
+func TestRateLimitRetry(t *testing.T) {
+	now := time.Now()
+	instance := &Instance{Title: "agent", started: true, LastPrompt: "fix the tests"}
+	instance.MarkRateLimited(now.Add(time.Minute))
+
+	if _, ok := instance.RateLimitRetry(now); ok {
+		t.Errorf("RateLimitRetry() retried before the cooldown was over")
+	}
+	prompt, ok := instance.RateLimitRetry(now.Add(time.Minute))
+	if !ok || prompt != "fix the tests" {
+		t.Errorf("RateLimitRetry() = %q, %v, want the last prompt", prompt, ok)
+	}
+	if instance.RateLimited() {
+		t.Errorf("RateLimitRetry() left the instance rate limited")
+	}
+
+	instance.LastPrompt = ""
+	instance.MarkRateLimited(now)
+	if prompt, _ := instance.RateLimitRetry(now); prompt != rateLimitRetryPrompt {
+		t.Errorf("RateLimitRetry() = %q without a last prompt, want %q", prompt, rateLimitRetryPrompt)
+	}
+}
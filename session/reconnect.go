@@ -0,0 +1,104 @@
+package session
+
+import (
+	"claude-squad/log"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// reconnectBackoffMin is how long to wait before the first attempt to
+	// reconnect to a session whose connection was lost. The wait doubles with
+	// every failed attempt, up to reconnectBackoffMax.
+	reconnectBackoffMin = 2 * time.Second
+	reconnectBackoffMax = 5 * time.Minute
+	// reconnectFailureLimit is how many attempts to reconnect may fail before
+	// the failure is shown in the list.
+	reconnectFailureLimit = 3
+)
+
+// reconnectState tracks the attempts to reconnect to an instance's session.
+type reconnectState struct {
+	mu       sync.Mutex
+	failures int
+	next     time.Time
+	err      error
+}
+
+// reconnectDelay returns how long to wait before the next attempt to reconnect
+// after the given number of failed attempts.
+func reconnectDelay(failures int) time.Duration {
+	delay := reconnectBackoffMin
+	for n := 0; n < failures && delay < reconnectBackoffMax; n++ {
+		delay *= 2
+	}
+	return min(delay, reconnectBackoffMax)
+}
+
+// Disconnected returns true if the connection to the instance's session was
+// lost, e.g. because the zellij server restarted, so its preview is frozen.
+func (i *Instance) Disconnected() bool {
+	if !i.started || i.Status == Paused || i.session == nil {
+		return false
+	}
+	reconnector, ok := i.session.(Reconnector)
+	return ok && reconnector.Disconnected()
+}
+
+// ReconnectError returns why reconnecting to the instance's session keeps
+// failing, or nil while it hasn't failed reconnectFailureLimit times in a row.
+func (i *Instance) ReconnectError() error {
+	if !i.Disconnected() {
+		return nil
+	}
+	i.reconnect.mu.Lock()
+	defer i.reconnect.mu.Unlock()
+	if i.reconnect.failures < reconnectFailureLimit {
+		return nil
+	}
+	return i.reconnect.err
+}
+
+// reconnectIfDue reconnects to the instance's session if the connection was
+// lost and the backoff since the last failed attempt has passed.
+func (i *Instance) reconnectIfDue(now time.Time) {
+	if !i.Disconnected() {
+		return
+	}
+	i.reconnect.mu.Lock()
+	defer i.reconnect.mu.Unlock()
+	if now.Before(i.reconnect.next) {
+		return
+	}
+
+	if err := i.session.(Reconnector).Reconnect(); err != nil {
+		i.reconnect.failures++
+		i.reconnect.err = fmt.Errorf("failed to reconnect %d times: %w", i.reconnect.failures, err)
+		i.reconnect.next = now.Add(reconnectDelay(i.reconnect.failures))
+		log.WarningLog.Printf("%s: %v", i.Title, i.reconnect.err)
+		return
+	}
+	log.InfoLog.Printf("reconnected to the session of %s", i.Title)
+	i.sizeEpoch.Add(1)
+	i.reconnect.failures = 0
+	i.reconnect.err = nil
+	i.reconnect.next = time.Time{}
+}
+
+// ReconnectDisconnected reconnects the sessions of the instances whose
+// connection was lost, backing off exponentially while it fails.
+func ReconnectDisconnected(instances []*Instance, now time.Time) {
+	var wg sync.WaitGroup
+	for _, instance := range instances {
+		if instance == nil || !instance.Disconnected() {
+			continue
+		}
+		wg.Add(1)
+		go func(inst *Instance) {
+			defer wg.Done()
+			inst.reconnectIfDue(now)
+		}(instance)
+	}
+	wg.Wait()
+}
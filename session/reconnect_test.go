@@ -0,0 +1,25 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconnectDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 2 * time.Second},
+		{1, 4 * time.Second},
+		{3, 16 * time.Second},
+		{7, 256 * time.Second},
+		{8, 5 * time.Minute},
+		{100, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := reconnectDelay(tt.failures); got != tt.want {
+			t.Errorf("reconnectDelay(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
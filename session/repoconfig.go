@@ -0,0 +1,37 @@
+package session
+
+import (
+	"claude-squad/config"
+	"claude-squad/session/git"
+)
+
+// ApplyRepoConfig applies the defaults of the repository to the options of a
+// new instance.
+func (opts *InstanceOptions) ApplyRepoConfig(repoConfig *config.RepoConfig) {
+	if repoConfig == nil {
+		return
+	}
+	if repoConfig.Program != "" {
+		opts.Program = repoConfig.Program
+	}
+	if repoConfig.DockerImage != "" {
+		opts.DockerBaseImage = repoConfig.DockerImage
+	}
+	opts.BaseBranch = repoConfig.BaseBranch
+	opts.Env = MergeEnv(opts.Env, repoConfig.Env)
+	opts.AuxCommand = repoConfig.AuxCommand
+}
+
+// containerMounts returns the host directories that docker sessions mount at
+// the same path: the shared caches that the worktree of the instance links to,
+// and the directory its Claude hooks write their status to.
+func (i *Instance) containerMounts() []string {
+	if i.gitWorktree == nil || i.SessionType != config.SessionTypeDockerBind {
+		return nil
+	}
+	mounts := i.gitWorktree.SharedCacheDirs()
+	if hooksDir, err := git.HooksDirectory(); err == nil {
+		mounts = append(mounts, hooksDir)
+	}
+	return mounts
+}
@@ -0,0 +1,168 @@
+package session
+
+import (
+	"claude-squad/config"
+	"claude-squad/session/zellij"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// PromptResponse is an answer to a prompt the agent is waiting on, like a
+// permission request.
+type PromptResponse int
+
+const (
+	// PromptAccept accepts the default choice, like allowing a command once.
+	PromptAccept PromptResponse = iota
+	// PromptReject rejects the request, so the agent can be told what to do
+	// instead.
+	PromptReject
+	// PromptAlways accepts the request and doesn't ask again for similar ones.
+	PromptAlways
+)
+
+// defaultPromptKeys are the keys answering the prompts of the known programs.
+// "\r" stands for the enter key.
+var defaultPromptKeys = map[string]config.PromptKeys{
+	// Claude and Gemini select the highlighted choice with enter, the second
+	// choice of their numbered menus allows always, and escape leaves the prompt
+	zellij.ProgramClaude: {Accept: "\r", Reject: "\x1b", Always: "2"},
+	zellij.ProgramGemini: {Accept: "\r", Reject: "\x1b", Always: "2"},
+	zellij.ProgramAider:  {Accept: "y\r", Reject: "n\r", Always: "a\r"},
+}
+
+// fallbackPromptKeys answer the prompts of other programs.
+var fallbackPromptKeys = config.PromptKeys{Accept: "\r", Reject: "\x1b"}
+
+var (
+	promptKeysMu sync.RWMutex
+	// configuredPromptKeys override the default keys per program.
+	configuredPromptKeys map[string]config.PromptKeys
+)
+
+// SetPromptKeys configures the keys that answer the prompts of programs, by
+// the name of their command. Keys left empty keep their defaults.
+func SetPromptKeys(keys map[string]config.PromptKeys) {
+	promptKeysMu.Lock()
+	defer promptKeysMu.Unlock()
+	configuredPromptKeys = keys
+}
+
+// programName returns the name of the command of the program, e.g. "aider"
+// for "/usr/local/bin/aider --model sonnet".
+func programName(program string) string {
+	fields := strings.Fields(program)
+	if len(fields) == 0 {
+		return ""
+	}
+	return filepath.Base(fields[0])
+}
+
+// promptKeys returns the keys that answer a prompt of the program. "\r" stands
+// for the enter key.
+func promptKeys(program string, response PromptResponse) string {
+	name := programName(program)
+	keys, ok := defaultPromptKeys[name]
+	if !ok {
+		keys = fallbackPromptKeys
+	}
+	promptKeysMu.RLock()
+	configured := configuredPromptKeys[name]
+	promptKeysMu.RUnlock()
+	if configured.Accept != "" {
+		keys.Accept = configured.Accept
+	}
+	if configured.Reject != "" {
+		keys.Reject = configured.Reject
+	}
+	if configured.Always != "" {
+		keys.Always = configured.Always
+	}
+
+	switch response {
+	case PromptReject:
+		return keys.Reject
+	case PromptAlways:
+		return keys.Always
+	default:
+		return keys.Accept
+	}
+}
+
+// PendingPrompt returns an excerpt of the prompt the agent is waiting on, or
+// an empty string if it isn't waiting on one.
+func (i *Instance) PendingPrompt() string {
+	return i.pendingPrompt
+}
+
+// SetPendingPrompt records the excerpt of the prompt the agent is waiting on,
+// an empty string if there is none.
+func (i *Instance) SetPendingPrompt(excerpt string) {
+	i.pendingPrompt = excerpt
+}
+
+// RespondToPrompt answers the prompt the agent is waiting on without
+// attaching to the session.
+func (i *Instance) RespondToPrompt(response PromptResponse) error {
+	if !i.started || i.Status == Paused {
+		return fmt.Errorf("cannot respond to a prompt of an instance that is not running")
+	}
+	if err := i.checkNotQuarantined("respond to prompts"); err != nil {
+		return err
+	}
+
+	keys := promptKeys(i.Program, response)
+	if keys == "" {
+		return fmt.Errorf("no keys to always allow prompts of %s, set them in prompt_keys in the config", programName(i.Program))
+	}
+	if err := i.sendPromptKeys(keys); err != nil {
+		return err
+	}
+	i.pendingPrompt = ""
+	i.markActive()
+	return nil
+}
+
+// sendPromptKeys types the keys into the session, tapping enter for each "\r".
+func (i *Instance) sendPromptKeys(keys string) error {
+	for n, part := range strings.Split(keys, "\r") {
+		if n > 0 {
+			if err := i.session.TapEnter(); err != nil {
+				return fmt.Errorf("error sending enter to session: %w", err)
+			}
+		}
+		if part == "" {
+			continue
+		}
+		if err := i.session.SendKeys(part); err != nil {
+			return fmt.Errorf("error sending keys to session: %w", err)
+		}
+	}
+	return nil
+}
+
+// PromptExcerpt returns the question of the prompt shown in the pane content:
+// the last line ending in a question mark, or else the last line with text.
+// Box drawing characters around dialogs are left out.
+func PromptExcerpt(content string) string {
+	var last string
+	lines := strings.Split(content, "\n")
+	for n := len(lines) - 1; n >= 0; n-- {
+		line := strings.TrimFunc(lines[n], func(r rune) bool {
+			return unicode.IsSpace(r) || (r >= '─' && r <= '╿')
+		})
+		if line == "" {
+			continue
+		}
+		if strings.HasSuffix(line, "?") {
+			return line
+		}
+		if last == "" {
+			last = line
+		}
+	}
+	return last
+}
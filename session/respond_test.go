@@ -0,0 +1,69 @@
+package session
+
+import (
+	"claude-squad/config"
+	"testing"
+)
+
+func TestPromptExcerpt(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty", "", ""},
+		{"question", "Edit main.go\n\nDo you want to make this edit to main.go?\n❯ 1. Yes\n  2. No\n", "Do you want to make this edit to main.go?"},
+		{"box", "╭──────╮\n│ Allow this command? │\n╰──────╯\n", "Allow this command?"},
+		{"no question", "Running tests\n  waiting  \n\n", "waiting"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PromptExcerpt(tt.content); got != tt.want {
+				t.Errorf("PromptExcerpt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptKeys(t *testing.T) {
+	tests := []struct {
+		program  string
+		response PromptResponse
+		want     string
+	}{
+		{"claude", PromptAccept, "\r"},
+		{"claude", PromptReject, "\x1b"},
+		{"/usr/local/bin/claude --continue", PromptAlways, "2"},
+		{"aider --model sonnet", PromptAccept, "y\r"},
+		{"aider --model sonnet", PromptReject, "n\r"},
+		{"aider --model sonnet", PromptAlways, "a\r"},
+		{"codex", PromptAccept, "\r"},
+		{"codex", PromptAlways, ""},
+	}
+	for _, tt := range tests {
+		if got := promptKeys(tt.program, tt.response); got != tt.want {
+			t.Errorf("promptKeys(%q, %d) = %q, want %q", tt.program, tt.response, got, tt.want)
+		}
+	}
+}
+
+func TestPromptKeysConfigured(t *testing.T) {
+	SetPromptKeys(map[string]config.PromptKeys{
+		"claude": {Always: "3"},
+		"codex":  {Accept: "y", Always: "a"},
+	})
+	defer SetPromptKeys(nil)
+
+	if got := promptKeys("claude", PromptAlways); got != "3" {
+		t.Errorf("configured always key = %q, want %q", got, "3")
+	}
+	if got := promptKeys("claude", PromptAccept); got != "\r" {
+		t.Errorf("accept key without configuration = %q, want the default", got)
+	}
+	if got := promptKeys("codex", PromptAccept); got != "y" {
+		t.Errorf("configured accept key = %q, want %q", got, "y")
+	}
+	if got := promptKeys("codex", PromptReject); got != "\x1b" {
+		t.Errorf("reject key without configuration = %q, want the fallback", got)
+	}
+}
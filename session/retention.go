@@ -0,0 +1,66 @@
+package session
+
+import (
+	"claude-squad/log"
+	"time"
+)
+
+// StaleInstances returns the instances that weren't opened within the
+// retention period, or created within it if they were never opened, and that
+// have no work that would only exist locally: uncommitted changes or
+// unpushed commits. Instances that are working, pending or not restored yet
+// are left alone.
+func StaleInstances(instances []*Instance, retention time.Duration, now time.Time) []*Instance {
+	var stale []*Instance
+	for _, instance := range instances {
+		if !instance.retentionExpired(retention, now) {
+			continue
+		}
+		if instance.hasLocalWork() {
+			continue
+		}
+		stale = append(stale, instance)
+	}
+	return stale
+}
+
+// retentionExpired returns true if the instance may be archived after not
+// being used for the retention period.
+func (i *Instance) retentionExpired(retention time.Duration, now time.Time) bool {
+	if i.Archived || i.Tombstoned() || i.Scratch || !i.started || i.RestorePending() {
+		return false
+	}
+	if i.Status == Running || i.Status == Pending {
+		return false
+	}
+	lastUsed := i.CreatedAt
+	if i.LastOpenedAt != nil {
+		lastUsed = *i.LastOpenedAt
+	}
+	return now.Sub(lastUsed) >= retention
+}
+
+// hasLocalWork returns true if the instance has uncommitted changes or
+// unpushed commits, or if that can't be determined.
+func (i *Instance) hasLocalWork() bool {
+	if i.gitWorktree == nil {
+		return true
+	}
+	// Paused instances have no worktree, their changes were committed
+	if !i.Paused() {
+		dirty, err := i.gitWorktree.IsDirty()
+		if err != nil {
+			log.WarningLog.Printf("could not check changes of %s: %v", i.Title, err)
+			return true
+		}
+		if dirty {
+			return true
+		}
+	}
+	unpushed, err := i.gitWorktree.HasUnpushedCommits()
+	if err != nil {
+		log.WarningLog.Printf("could not check unpushed commits of %s: %v", i.Title, err)
+		return true
+	}
+	return unpushed
+}
@@ -0,0 +1,37 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionExpired(t *testing.T) {
+	now := time.Now()
+	retention := 14 * 24 * time.Hour
+	old := now.Add(-30 * 24 * time.Hour)
+	recent := now.Add(-time.Hour)
+	notRestored := &Instance{Status: Ready, started: true, CreatedAt: old}
+	notRestored.restorePending.Store(true)
+
+	tests := []struct {
+		name     string
+		instance *Instance
+		want     bool
+	}{
+		{"opened long ago", &Instance{Status: Ready, started: true, CreatedAt: old, LastOpenedAt: &old}, true},
+		{"never opened", &Instance{Status: Paused, started: true, CreatedAt: old}, true},
+		{"opened recently", &Instance{Status: Ready, started: true, CreatedAt: old, LastOpenedAt: &recent}, false},
+		{"working", &Instance{Status: Running, started: true, CreatedAt: old}, false},
+		{"pending", &Instance{Status: Pending, CreatedAt: old}, false},
+		{"archived", &Instance{Status: Paused, started: true, CreatedAt: old, Archived: true}, false},
+		{"scratch", &Instance{Status: Ready, started: true, CreatedAt: old, Scratch: true}, false},
+		{"not restored", notRestored, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.instance.retentionExpired(retention, now); got != tt.want {
+				t.Errorf("retentionExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
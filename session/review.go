@@ -0,0 +1,135 @@
+package session
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ReviewStatus is the outcome of the review of an instance's changes by a reviewer instance.
+type ReviewStatus string
+
+const (
+	// ReviewNone means no review has been requested.
+	ReviewNone ReviewStatus = ""
+	// ReviewPending means the reviewer has not given its verdict yet.
+	ReviewPending ReviewStatus = "pending"
+	// ReviewApproved means the reviewer approved the changes.
+	ReviewApproved ReviewStatus = "approved"
+	// ReviewChangesRequested means the reviewer asked for changes.
+	ReviewChangesRequested ReviewStatus = "changes-requested"
+)
+
+// reviewTitleSuffix is appended to an instance's title to name its reviewer.
+const reviewTitleSuffix = "-review"
+
+// reviewOutputLines is how many trailing lines of a reviewer's output are searched for its verdict.
+const reviewOutputLines = 50
+
+// reviewRubric tells the reviewer what to look at and how to report its verdict.
+// The verdicts are quoted so the prompt itself never matches reviewVerdictPattern.
+const reviewRubric = `Review the changes for:
+- Correctness: bugs, unhandled errors and edge cases.
+- Design: whether the change fits the existing code and is no more complex than needed.
+- Tests: whether the behavior is covered and the tests would catch a regression.
+- Readability: naming, comments and dead code.
+Do not modify any files. List each problem with the file and line it concerns.
+Finish your review with a last line that reads exactly "VERDICT: approved" if the changes can be merged as they are, or "VERDICT: changes-requested" otherwise.`
+
+// reviewVerdictPattern matches the verdict line of a reviewer, allowing for the
+// bullets and markdown emphasis agents put around it.
+var reviewVerdictPattern = regexp.MustCompile(`^[^A-Za-z]*VERDICT:\s*(approved|changes-requested)[\s*_]*$`)
+
+// ReviewTitle returns the title of the reviewer of the instance with the given title.
+func ReviewTitle(title string) string {
+	if runes := []rune(title); len(runes)+len(reviewTitleSuffix) > TitleMaxLength {
+		title = string(runes[:TitleMaxLength-len(reviewTitleSuffix)])
+	}
+	return title + reviewTitleSuffix
+}
+
+// ReviewPrompt returns the prompt asking a reviewer to review the diff of an instance.
+func ReviewPrompt(title, diff string) string {
+	return fmt.Sprintf("Please review the changes made in session '%s'.\n\n%s\n\n```diff\n%s\n```\n",
+		title, reviewRubric, strings.TrimRight(diff, "\n"))
+}
+
+// RequestReview marks the review of the instance as pending from now on, so
+// the verdict of an earlier review is not taken for the new one.
+func (i *Instance) RequestReview() {
+	i.ReviewStatus = ReviewPending
+	i.ReviewRequestedAt = time.Now()
+}
+
+// workedSince reports whether the instance started working after t.
+func (i *Instance) workedSince(t time.Time) bool {
+	for _, change := range i.StatusHistory {
+		if change.Status == Running && change.At.After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseReviewVerdict returns the last verdict in a reviewer's output.
+func ParseReviewVerdict(output string) (ReviewStatus, bool) {
+	lines := strings.Split(ansiEscapePattern.ReplaceAllString(output, ""), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if match := reviewVerdictPattern.FindStringSubmatch(strings.TrimSpace(lines[i])); match != nil {
+			return ReviewStatus(match[1]), true
+		}
+	}
+	return ReviewNone, false
+}
+
+// UpdateReviewStatuses looks for a verdict in the output of the reviewers that
+// are waiting for input and records it on the instances they review. A pending
+// review only takes a verdict given after it was requested. It returns
+// the reviewed instances whose review status changed.
+func UpdateReviewStatuses(instances []*Instance) []*Instance {
+	byTitle := make(map[string]*Instance, len(instances))
+	for _, instance := range instances {
+		if instance != nil && !instance.Tombstoned() {
+			byTitle[instance.Title] = instance
+		}
+	}
+
+	var changed []*Instance
+	for _, reviewer := range instances {
+		if reviewer == nil || reviewer.ReviewOf == "" || !reviewer.Started() || reviewer.Status != Ready {
+			continue
+		}
+		reviewed, ok := byTitle[reviewer.ReviewOf]
+		if !ok || reviewed.Reviewer != reviewer.Title {
+			continue
+		}
+		// The verdict still on screen is the previous one until the reviewer
+		// has worked on the new request.
+		if reviewed.ReviewStatus == ReviewPending && !reviewer.workedSince(reviewed.ReviewRequestedAt) {
+			continue
+		}
+		output, err := reviewer.RecentOutput(reviewOutputLines)
+		if err != nil {
+			continue
+		}
+		if verdict, ok := ParseReviewVerdict(output); ok && verdict != reviewed.ReviewStatus {
+			reviewed.ReviewStatus = verdict
+			changed = append(changed, reviewed)
+		}
+	}
+	return changed
+}
+
+// RenameReviewLinks updates the links between reviewers and the instances they
+// review after an instance was renamed.
+func RenameReviewLinks(instances []*Instance, oldTitle, newTitle string) {
+	for _, instance := range instances {
+		if instance.ReviewOf == oldTitle {
+			instance.ReviewOf = newTitle
+		}
+		if instance.Reviewer == oldTitle {
+			instance.Reviewer = newTitle
+		}
+	}
+}
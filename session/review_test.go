@@ -0,0 +1,123 @@
+package session
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseReviewVerdict(t *testing.T) {
+	tests := []struct {
+		name      string
+		output    string
+		want      ReviewStatus
+		noVerdict bool
+	}{
+		{
+			name:   "approved",
+			output: "Looks good overall.\n\nVERDICT: approved\n",
+			want:   ReviewApproved,
+		},
+		{
+			name:   "changes requested with bullet and emphasis",
+			output: "⏺ 1. main.go:12 ignores the error\n\n⏺ **VERDICT: changes-requested**\n\n> ",
+			want:   ReviewChangesRequested,
+		},
+		{
+			name:   "last verdict wins",
+			output: "VERDICT: changes-requested\n...\nVERDICT: approved",
+			want:   ReviewApproved,
+		},
+		{
+			name:   "colored output",
+			output: "\x1b[1mVERDICT: approved\x1b[0m",
+			want:   ReviewApproved,
+		},
+		{
+			name:      "no verdict yet",
+			output:    "Reading main.go...",
+			noVerdict: true,
+		},
+		{
+			name:      "review prompt",
+			output:    ReviewPrompt("feature", "+added line"),
+			noVerdict: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseReviewVerdict(tt.output)
+			if ok == tt.noVerdict {
+				t.Fatalf("ParseReviewVerdict() ok = %v, want %v", ok, !tt.noVerdict)
+			}
+			if got != tt.want {
+				t.Errorf("ParseReviewVerdict() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewTitle(t *testing.T) {
+	if got := ReviewTitle("feature"); got != "feature-review" {
+		t.Errorf("ReviewTitle() = %q, want %q", got, "feature-review")
+	}
+	long := strings.Repeat("a", 32)
+	if got := ReviewTitle(long); len(got) != 32 || !strings.HasSuffix(got, reviewTitleSuffix) {
+		t.Errorf("ReviewTitle() = %q, want 32 characters ending in %q", got, reviewTitleSuffix)
+	}
+	wide := strings.Repeat("日", 32)
+	if got := ReviewTitle(wide); !utf8.ValidString(got) || utf8.RuneCountInString(got) != 32 {
+		t.Errorf("ReviewTitle() = %q, want 32 whole characters", got)
+	}
+}
+
+func TestRenameReviewLinks(t *testing.T) {
+	reviewed := &Instance{Title: "feature", Reviewer: "feature-review", ReviewStatus: ReviewPending}
+	reviewer := &Instance{Title: "feature-review", ReviewOf: "feature"}
+	instances := []*Instance{reviewed, reviewer}
+
+	reviewed.Title = "renamed"
+	RenameReviewLinks(instances, "feature", "renamed")
+	if reviewer.ReviewOf != "renamed" {
+		t.Errorf("ReviewOf = %q, want %q", reviewer.ReviewOf, "renamed")
+	}
+
+	reviewer.Title = "checker"
+	RenameReviewLinks(instances, "feature-review", "checker")
+	if reviewed.Reviewer != "checker" {
+		t.Errorf("Reviewer = %q, want %q", reviewed.Reviewer, "checker")
+	}
+}
+
+// outputSession is a Multiplexer whose history is a fixed output.
+type outputSession struct {
+	Multiplexer
+	output string
+}
+
+func (s *outputSession) CaptureHistoryTail(maxLines, maxBytes int) (string, bool, error) {
+	return s.output, false, nil
+}
+
+func TestUpdateReviewStatusesIgnoresEarlierVerdict(t *testing.T) {
+	reviewed := &Instance{Title: "feature", Reviewer: "feature-review", ReviewStatus: ReviewApproved}
+	reviewer := &Instance{Title: "feature-review", ReviewOf: "feature", started: true,
+		session: &outputSession{output: "VERDICT: approved\n> "}}
+	reviewer.SetStatus(Ready)
+	instances := []*Instance{reviewed, reviewer}
+
+	// Asking again while the previous verdict is still on screen
+	reviewed.RequestReview()
+	if changed := UpdateReviewStatuses(instances); len(changed) != 0 || reviewed.ReviewStatus != ReviewPending {
+		t.Fatalf("the earlier verdict was taken: status %q", reviewed.ReviewStatus)
+	}
+
+	// The reviewer works on the new request and gives its verdict
+	reviewer.SetStatus(Running)
+	reviewer.session = &outputSession{output: "VERDICT: approved\n...\nVERDICT: changes-requested\n> "}
+	reviewer.SetStatus(Ready)
+	if changed := UpdateReviewStatuses(instances); len(changed) != 1 || reviewed.ReviewStatus != ReviewChangesRequested {
+		t.Errorf("ReviewStatus = %q, want %q", reviewed.ReviewStatus, ReviewChangesRequested)
+	}
+}
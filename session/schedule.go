@@ -0,0 +1,31 @@
+package session
+
+// CountActive returns how many instances count against config.Config.MaxRunning:
+// started instances whose agent is working or starting up.
+func CountActive(instances []*Instance) int {
+	active := 0
+	for _, instance := range instances {
+		if instance == nil || !instance.Started() || instance.Tombstoned() {
+			continue
+		}
+		if instance.Status == Running || instance.Status == Loading {
+			active++
+		}
+	}
+	return active
+}
+
+// NextPending returns the pending instance that was created first, or nil.
+// Archived and killed instances are skipped.
+func NextPending(instances []*Instance) *Instance {
+	var next *Instance
+	for _, instance := range instances {
+		if instance == nil || instance.Status != Pending || instance.Archived || instance.Tombstoned() {
+			continue
+		}
+		if next == nil || instance.CreatedAt.Before(next.CreatedAt) {
+			next = instance
+		}
+	}
+	return next
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCountActive(t *testing.T) {
+	now := time.Now()
+	instances := []*Instance{
+		{Title: "running", Status: Running, started: true},
+		{Title: "loading", Status: Loading, started: true},
+		{Title: "ready", Status: Ready, started: true},
+		{Title: "paused", Status: Paused, started: true},
+		{Title: "pending", Status: Pending},
+		{Title: "killed", Status: Running, started: true, DeletedAt: &now},
+		nil,
+	}
+	if got := CountActive(instances); got != 2 {
+		t.Errorf("CountActive() = %d, want 2", got)
+	}
+}
+
+func TestNextPending(t *testing.T) {
+	now := time.Now()
+	first := &Instance{Title: "first", Status: Pending, CreatedAt: now.Add(-time.Minute)}
+	second := &Instance{Title: "second", Status: Pending, CreatedAt: now}
+	archived := &Instance{Title: "archived", Status: Pending, CreatedAt: now.Add(-time.Hour), Archived: true}
+	running := &Instance{Title: "running", Status: Running, CreatedAt: now.Add(-time.Hour)}
+
+	if got := NextPending([]*Instance{second, archived, running, first}); got != first {
+		t.Errorf("NextPending() = %v, want the instance created first", got)
+	}
+	if got := NextPending([]*Instance{archived, running}); got != nil {
+		t.Errorf("NextPending() = %v, want nil without pending instances", got.Title)
+	}
+}
+
+func TestPendingInstanceIsSavedWithoutStarting(t *testing.T) {
+	pending := &Instance{Title: "pending", Path: t.TempDir(), Status: Pending, Prompt: "fix the tests"}
+	state := &memoryState{}
+	storage, err := NewStorage(state)
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	if err := storage.SaveInstances([]*Instance{pending}); err != nil {
+		t.Fatalf("SaveInstances() error = %v", err)
+	}
+
+	loaded, err := storage.LoadInstances()
+	if err != nil {
+		t.Fatalf("LoadInstances() error = %v", err)
+	}
+	if len(loaded) != 1 {
+		t.Fatalf("LoadInstances() returned %d instances, want 1", len(loaded))
+	}
+	if loaded[0].Started() || loaded[0].Status != Pending || loaded[0].Prompt != "fix the tests" {
+		t.Errorf("loaded instance started=%v status=%v prompt=%q, want an unstarted pending instance with its prompt",
+			loaded[0].Started(), loaded[0].Status, loaded[0].Prompt)
+	}
+}
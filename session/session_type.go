@@ -0,0 +1,81 @@
+package session
+
+import (
+	"claude-squad/config"
+	"fmt"
+	"strings"
+)
+
+// CheckSessionTypeSwitch returns why the session type of the instance can't be
+// switched to sessionType, or nil if it can. dockerImage is the image of Docker
+// sessions if the instance has none yet.
+func (i *Instance) CheckSessionTypeSwitch(sessionType, dockerImage string) error {
+	if !i.started || i.Status != Paused {
+		return fmt.Errorf("pause '%s' before switching its session type", i.Title)
+	}
+	if i.gitWorktree == nil {
+		return fmt.Errorf("'%s' has no worktree to start a new session on", i.Title)
+	}
+	switch sessionType {
+	case config.SessionTypeZellij, config.SessionTypeNative, config.SessionTypeDockerBind:
+	case config.SessionTypeDockerClone:
+		return fmt.Errorf("docker-clone sessions clone the repository instead of using the worktree of '%s'", i.Title)
+	default:
+		return fmt.Errorf("unknown session type %q", sessionType)
+	}
+	if sessionType == i.SessionType {
+		return fmt.Errorf("'%s' already uses a %s session", i.Title, sessionType)
+	}
+	if sessionType == config.SessionTypeDockerBind && i.DockerBaseImage == "" && dockerImage == "" {
+		return fmt.Errorf("no Docker image configured for '%s'", i.Title)
+	}
+	return nil
+}
+
+// CloseSessionForSwitch closes the session of the paused instance before its
+// session type is switched. It blocks on the session, so the TUI runs it in a
+// command and switches the session type once it's done.
+func (i *Instance) CloseSessionForSwitch(sessionType, dockerImage string) error {
+	if err := i.CheckSessionTypeSwitch(sessionType, dockerImage); err != nil {
+		return err
+	}
+	if i.session != nil && i.session.DoesSessionExist() {
+		if err := i.session.Close(); err != nil {
+			return fmt.Errorf("failed to close the %s session of '%s': %w", i.SessionType, i.Title, err)
+		}
+	}
+	return nil
+}
+
+// SwitchSessionType changes the session type of a paused instance, e.g. from
+// zellij to docker-bind for isolation, once its old session was closed with
+// CloseSessionForSwitch. A session of the new type is created, which the next
+// resume starts on the same worktree and branch. dockerImage is the image of
+// Docker sessions if the instance has none yet. Claude continues its
+// conversation if the working directory stays the same, which it doesn't
+// inside a container.
+func (i *Instance) SwitchSessionType(sessionType, dockerImage string) error {
+	if err := i.CheckSessionTypeSwitch(sessionType, dockerImage); err != nil {
+		return err
+	}
+
+	isDocker := sessionType == config.SessionTypeDockerBind
+	if isDocker && i.DockerBaseImage == "" {
+		i.DockerBaseImage = dockerImage
+	}
+
+	program := i.expandProgram()
+	if strings.Contains(i.Program, "claude") && i.ClaudeSessionID != "" && !isDocker && !i.IsDockerSession() {
+		program += " --resume " + i.ClaudeSessionID
+	}
+	i.SessionType = sessionType
+	i.DockerContainerID = ""
+	i.session = NewMultiplexer(sessionType, i.gitWorktree.GetSessionName(), program, MultiplexerOptions{
+		BaseImage:  i.DockerBaseImage,
+		BranchName: i.Branch,
+		WorkDir:    i.gitWorktree.GetWorktreePath(),
+		Env:        i.Env,
+		Mounts:     i.containerMounts(),
+	})
+	return nil
+}
@@ -0,0 +1,79 @@
+package session
+
+import (
+	"claude-squad/config"
+	"claude-squad/session/docker"
+	"claude-squad/session/git"
+	"claude-squad/session/native"
+	"testing"
+)
+
+func TestSwitchSessionType(t *testing.T) {
+	worktree := git.NewGitWorktreeFromStorage("/repo", "/worktree", "api", "alice/api", "abc")
+	instance := &Instance{
+		Title:       "api",
+		Program:     "claude",
+		Branch:      "alice/api",
+		Status:      Paused,
+		SessionType: config.SessionTypeNative,
+		started:     true,
+		gitWorktree: worktree,
+		session:     native.NewNativeSession("api", "claude", "/worktree"),
+	}
+
+	for _, sessionType := range []string{config.SessionTypeNative, config.SessionTypeDockerClone, "tmux"} {
+		if err := instance.SwitchSessionType(sessionType, "ubuntu:24.04"); err == nil {
+			t.Errorf("switching to %s should fail", sessionType)
+		}
+	}
+
+	if err := instance.CloseSessionForSwitch(config.SessionTypeDockerBind, "ubuntu:24.04"); err != nil {
+		t.Fatalf("CloseSessionForSwitch() error = %v", err)
+	}
+	if err := instance.SwitchSessionType(config.SessionTypeDockerBind, "ubuntu:24.04"); err != nil {
+		t.Fatalf("SwitchSessionType() error = %v", err)
+	}
+	if instance.SessionType != config.SessionTypeDockerBind {
+		t.Errorf("SessionType = %q, want %q", instance.SessionType, config.SessionTypeDockerBind)
+	}
+	if _, ok := instance.session.(*docker.DockerSession); !ok {
+		t.Errorf("session is a %T, want a Docker session", instance.session)
+	}
+	if instance.DockerBaseImage != "ubuntu:24.04" {
+		t.Errorf("DockerBaseImage = %q, want the default image", instance.DockerBaseImage)
+	}
+	if data := instance.ToInstanceData(); data.SessionType != config.SessionTypeDockerBind || data.DockerBaseImage != "ubuntu:24.04" {
+		t.Errorf("the switch isn't saved: %+v", data)
+	}
+
+	instance.Status = Running
+	if err := instance.CloseSessionForSwitch(config.SessionTypeNative, ""); err == nil {
+		t.Error("closing a running instance for a switch should fail")
+	}
+	if err := instance.SwitchSessionType(config.SessionTypeNative, ""); err == nil {
+		t.Error("switching a running instance should fail")
+	}
+}
+
+func TestContainerMountsIncludeHooksDirectory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	hooksDir, err := git.HooksDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance := &Instance{
+		SessionType: config.SessionTypeDockerBind,
+		gitWorktree: git.NewGitWorktreeFromStorage("/repo", "/worktree", "api", "alice/api", "abc"),
+	}
+
+	// The hooks write their status to the same path inside the container
+	mounts := instance.containerMounts()
+	if len(mounts) == 0 || mounts[len(mounts)-1] != hooksDir {
+		t.Errorf("containerMounts() = %v, want it to include %s", mounts, hooksDir)
+	}
+
+	instance.SessionType = config.SessionTypeNative
+	if mounts := instance.containerMounts(); mounts != nil {
+		t.Errorf("containerMounts() = %v for a native session", mounts)
+	}
+}
@@ -0,0 +1,182 @@
+package session
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxSnippets is the maximum number of snippets kept per instance; the oldest are dropped first.
+	MaxSnippets = 50
+	// snippetMinAnswerLength is the minimum length of an answer block worth keeping.
+	snippetMinAnswerLength = 40
+	// answerMarker prefixes messages and tool calls in Claude Code output.
+	answerMarker = "⏺"
+)
+
+// SnippetKind describes what kind of output block a snippet was captured from.
+type SnippetKind string
+
+const (
+	// SnippetCode is a fenced code block.
+	SnippetCode SnippetKind = "code"
+	// SnippetAnswer is a prose answer from the agent.
+	SnippetAnswer SnippetKind = "answer"
+)
+
+// Snippet is a notable block of agent output, such as a code fence or a final answer.
+type Snippet struct {
+	Kind SnippetKind
+	// Language is the info string of a code fence, if any.
+	Language string
+	Content  string
+	// CapturedAt is when the snippet was first seen.
+	CapturedAt time.Time
+}
+
+// Title returns a short one-line description of the snippet.
+func (s Snippet) Title() string {
+	first := strings.TrimSpace(strings.SplitN(s.Content, "\n", 2)[0])
+	if s.Kind == SnippetCode && s.Language != "" {
+		return "[" + s.Language + "] " + first
+	}
+	return first
+}
+
+// SnippetHistory is a bounded, de-duplicated list of snippets captured from an instance.
+type SnippetHistory struct {
+	mu       sync.Mutex
+	snippets []Snippet
+}
+
+// Record adds the snippets extracted from content that haven't been seen before.
+func (h *SnippetHistory) Record(content string) {
+	extracted := ExtractSnippets(ansiEscapePattern.ReplaceAllString(content, ""))
+	if len(extracted) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	seen := make(map[string]bool, len(h.snippets))
+	for _, s := range h.snippets {
+		seen[s.Content] = true
+	}
+	now := time.Now()
+	for _, s := range extracted {
+		if seen[s.Content] {
+			continue
+		}
+		seen[s.Content] = true
+		s.CapturedAt = now
+		h.snippets = append(h.snippets, s)
+	}
+	if len(h.snippets) > MaxSnippets {
+		h.snippets = h.snippets[len(h.snippets)-MaxSnippets:]
+	}
+}
+
+// List returns the captured snippets, most recent first.
+func (h *SnippetHistory) List() []Snippet {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	list := make([]Snippet, len(h.snippets))
+	for i, s := range h.snippets {
+		list[len(h.snippets)-1-i] = s
+	}
+	return list
+}
+
+// ExtractSnippets finds fenced code blocks and agent answers in plain-text terminal output.
+func ExtractSnippets(content string) []Snippet {
+	var snippets []Snippet
+	lines := strings.Split(content, "\n")
+
+	for idx := 0; idx < len(lines); idx++ {
+		trimmed := strings.TrimSpace(lines[idx])
+
+		// Fenced code block: collect until the closing fence
+		if strings.HasPrefix(trimmed, "```") {
+			lang := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			var body []string
+			end := idx + 1
+			for ; end < len(lines); end++ {
+				if strings.TrimSpace(lines[end]) == "```" {
+					break
+				}
+				body = append(body, lines[end])
+			}
+			if end == len(lines) {
+				// Unterminated fence, the block is probably still being written
+				break
+			}
+			if code := dedent(body); code != "" {
+				snippets = append(snippets, Snippet{Kind: SnippetCode, Language: lang, Content: code})
+			}
+			idx = end
+			continue
+		}
+
+		// Agent message: the marker line followed by indented continuation lines
+		if strings.HasPrefix(trimmed, answerMarker) {
+			first := strings.TrimSpace(strings.TrimPrefix(trimmed, answerMarker))
+			if toolActionPattern.MatchString(first) && strings.HasSuffix(first, ")") {
+				continue
+			}
+			body := []string{first}
+			end := idx + 1
+			for ; end < len(lines); end++ {
+				line := lines[end]
+				t := strings.TrimSpace(line)
+				if t != "" && (!strings.HasPrefix(line, " ") || isAnswerBoundary(t)) {
+					break
+				}
+				body = append(body, line)
+			}
+			answer := strings.TrimSpace(first + "\n" + dedent(body[1:]))
+			if len(answer) >= snippetMinAnswerLength {
+				snippets = append(snippets, Snippet{Kind: SnippetAnswer, Content: answer})
+			}
+			idx = end - 1
+		}
+	}
+	return snippets
+}
+
+// isAnswerBoundary returns true for lines that end an agent message, like tool
+// output, the next message or the input box.
+func isAnswerBoundary(line string) bool {
+	for _, prefix := range []string{answerMarker, "⎿", "╭", "╰", "│", ">", "```"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// dedent removes the common leading indentation and surrounding blank lines.
+func dedent(lines []string) string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if len(line) >= indent && indent > 0 {
+			line = line[indent:]
+		}
+		out[i] = line
+	}
+	return strings.Trim(strings.Join(out, "\n"), "\n")
+}
@@ -0,0 +1,72 @@
+package session
+
+import (
+	"testing"
+)
+
+func TestExtractSnippets(t *testing.T) {
+	content := `> add a helper
+
+⏺ Read(main.go)
+  ⎿  Read 20 lines
+
+⏺ Here is a helper that reverses a string in place:
+
+  ` + "```go" + `
+  func reverse(s string) string {
+      return s
+  }
+  ` + "```" + `
+
+⏺ Done. The helper handles unicode by working on runes
+  rather than bytes.
+
+⏺ ok
+╭──────────────╮
+│ >            │
+╰──────────────╯`
+
+	got := ExtractSnippets(content)
+	want := []Snippet{
+		{Kind: SnippetAnswer, Content: "Here is a helper that reverses a string in place:"},
+		{Kind: SnippetCode, Language: "go", Content: "func reverse(s string) string {\n    return s\n}"},
+		{Kind: SnippetAnswer, Content: "Done. The helper handles unicode by working on runes\nrather than bytes."},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ExtractSnippets() returned %d snippets, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("snippet %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExtractSnippetsSkipsUnterminatedFence(t *testing.T) {
+	got := ExtractSnippets("```python\nprint('still streaming')")
+	if len(got) != 0 {
+		t.Errorf("ExtractSnippets() = %+v, want no snippets", got)
+	}
+}
+
+func TestSnippetHistoryRecord(t *testing.T) {
+	var h SnippetHistory
+	h.Record("```\nfirst\n```")
+	h.Record("```\nfirst\n```\n```\nsecond\n```")
+
+	list := h.List()
+	if len(list) != 2 {
+		t.Fatalf("List() returned %d snippets, want 2: %+v", len(list), list)
+	}
+	if list[0].Content != "second" || list[1].Content != "first" {
+		t.Errorf("List() = %+v, want most recent first", list)
+	}
+
+	for i := 0; i < MaxSnippets+5; i++ {
+		h.Record("```\n" + string(rune('a'+i%26)) + string(rune('0'+i/26)) + "\n```")
+	}
+	if got := len(h.List()); got != MaxSnippets {
+		t.Errorf("List() returned %d snippets, want %d", got, MaxSnippets)
+	}
+}
@@ -0,0 +1,201 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// maxStatusHistory is how many status changes are kept per instance.
+	maxStatusHistory = 50
+	// maxAutoYesEvents is how many auto-yes events are kept per instance.
+	maxAutoYesEvents = 100
+)
+
+// StatusChange records when an instance entered a status.
+type StatusChange struct {
+	Status Status    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// recordStatusChange appends a status change, dropping the oldest beyond maxStatusHistory.
+func (i *Instance) recordStatusChange(status Status, at time.Time) {
+	i.StatusHistory = append(i.StatusHistory, StatusChange{Status: status, At: at})
+	if len(i.StatusHistory) > maxStatusHistory {
+		i.StatusHistory = i.StatusHistory[len(i.StatusHistory)-maxStatusHistory:]
+	}
+}
+
+// recordAutoYes appends an auto-yes event, dropping the oldest beyond maxAutoYesEvents.
+func (i *Instance) recordAutoYes(at time.Time) {
+	i.AutoYesEvents = append(i.AutoYesEvents, at)
+	if len(i.AutoYesEvents) > maxAutoYesEvents {
+		i.AutoYesEvents = i.AutoYesEvents[len(i.AutoYesEvents)-maxAutoYesEvents:]
+	}
+}
+
+// RepoStats summarizes the instances working on one repository.
+type RepoStats struct {
+	Name      string
+	Instances int
+	Added     int
+	Removed   int
+}
+
+// SquadStats summarizes all instances.
+type SquadStats struct {
+	// StatusCounts counts the non-archived instances by status.
+	StatusCounts map[Status]int
+	Archived     int
+	// Added and Removed are the total diff lines across all instances.
+	Added   int
+	Removed int
+	// Repos lists the repositories by number of instances, busiest first.
+	Repos []RepoStats
+	// AvgTimeToReady is the average time from Running to Ready, over ReadyTransitions samples.
+	AvgTimeToReady   time.Duration
+	ReadyTransitions int
+	// AutoYesToday counts the prompts answered by auto-yes since midnight.
+	AutoYesToday int
+	// OldestUnpushed is the oldest instance with commits that are on no remote, if any.
+	OldestUnpushed *Instance
+}
+
+// UnpushedInstances returns the instances with commits that are on no remote.
+// It runs git for every instance, so it shouldn't be called on the UI thread.
+func UnpushedInstances(instances []*Instance) map[*Instance]bool {
+	unpushed := make(map[*Instance]bool)
+	for _, instance := range instances {
+		if !instance.Tombstoned() && instance.HasUnpushedCommits() {
+			unpushed[instance] = true
+		}
+	}
+	return unpushed
+}
+
+// ComputeSquadStats summarizes the given instances. Tombstoned instances are
+// ignored. unpushed holds the instances with commits on no remote, see
+// UnpushedInstances.
+func ComputeSquadStats(instances []*Instance, unpushed map[*Instance]bool, now time.Time) SquadStats {
+	stats := SquadStats{StatusCounts: make(map[Status]int)}
+	repos := make(map[string]*RepoStats)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var totalToReady time.Duration
+
+	for _, instance := range instances {
+		if instance.Tombstoned() {
+			continue
+		}
+		if instance.Archived {
+			stats.Archived++
+		} else {
+			stats.StatusCounts[instance.Status]++
+		}
+
+		repoName, err := instance.RepoName()
+		if err != nil {
+			repoName = filepath.Base(instance.Path)
+		}
+		repo, ok := repos[repoName]
+		if !ok {
+			repo = &RepoStats{Name: repoName}
+			repos[repoName] = repo
+		}
+		repo.Instances++
+
+		if diff := instance.GetDiffStats(); diff != nil && diff.Error == nil {
+			stats.Added += diff.Added
+			stats.Removed += diff.Removed
+			repo.Added += diff.Added
+			repo.Removed += diff.Removed
+		}
+
+		for _, d := range timesToReady(instance.StatusHistory) {
+			totalToReady += d
+			stats.ReadyTransitions++
+		}
+
+		for _, at := range instance.AutoYesEvents {
+			if !at.Before(midnight) {
+				stats.AutoYesToday++
+			}
+		}
+
+		if unpushed[instance] {
+			if stats.OldestUnpushed == nil || instance.CreatedAt.Before(stats.OldestUnpushed.CreatedAt) {
+				stats.OldestUnpushed = instance
+			}
+		}
+	}
+
+	if stats.ReadyTransitions > 0 {
+		stats.AvgTimeToReady = totalToReady / time.Duration(stats.ReadyTransitions)
+	}
+
+	for _, repo := range repos {
+		stats.Repos = append(stats.Repos, *repo)
+	}
+	sort.Slice(stats.Repos, func(a, b int) bool {
+		if stats.Repos[a].Instances != stats.Repos[b].Instances {
+			return stats.Repos[a].Instances > stats.Repos[b].Instances
+		}
+		return stats.Repos[a].Added+stats.Repos[a].Removed > stats.Repos[b].Added+stats.Repos[b].Removed
+	})
+	return stats
+}
+
+// timesToReady returns how long each Running period lasted before the instance became Ready.
+func timesToReady(history []StatusChange) []time.Duration {
+	var durations []time.Duration
+	var runningSince time.Time
+	for _, change := range history {
+		switch change.Status {
+		case Running:
+			if runningSince.IsZero() {
+				runningSince = change.At
+			}
+		case Ready:
+			if !runningSince.IsZero() {
+				durations = append(durations, change.At.Sub(runningSince))
+			}
+			runningSince = time.Time{}
+		default:
+			runningSince = time.Time{}
+		}
+	}
+	return durations
+}
+
+// SquadStatusLine summarizes the working state of the squad on one line, e.g.
+// "CS: 2 running, 1 needs attention", for a terminal title or a status bar.
+// Archived and tombstoned instances are ignored.
+func SquadStatusLine(instances []*Instance) string {
+	var running, attention, pending, rateLimited int
+	for _, instance := range instances {
+		if instance == nil || instance.Archived || instance.Tombstoned() {
+			continue
+		}
+		switch {
+		case instance.NeedsAttention():
+			attention++
+		case instance.Status == Running || instance.Status == Loading:
+			running++
+		case instance.Status == Pending:
+			pending++
+		}
+		if instance.RateLimited() {
+			rateLimited++
+		}
+	}
+
+	line := fmt.Sprintf("CS: %d running, %d needs attention", running, attention)
+	if pending > 0 {
+		line += fmt.Sprintf(", %d pending", pending)
+	}
+	if rateLimited > 0 {
+		line += fmt.Sprintf(", %d rate limited", rateLimited)
+	}
+	return line
+}
@@ -0,0 +1,103 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimesToReady(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	history := []StatusChange{
+		{Status: Loading, At: at(0)},
+		{Status: Running, At: at(time.Second)},
+		{Status: Running, At: at(5 * time.Second)},
+		{Status: Ready, At: at(11 * time.Second)},
+		{Status: Running, At: at(20 * time.Second)},
+		{Status: Paused, At: at(25 * time.Second)},
+		{Status: Ready, At: at(30 * time.Second)},
+		{Status: Running, At: at(40 * time.Second)},
+		{Status: Ready, At: at(44 * time.Second)},
+	}
+
+	got := timesToReady(history)
+	want := []time.Duration{10 * time.Second, 4 * time.Second}
+	if len(got) != len(want) {
+		t.Fatalf("timesToReady() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("timesToReady()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestComputeSquadStats(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	deleted := now.Add(-time.Hour)
+
+	instances := []*Instance{
+		{Title: "a", Path: "/src/api", Status: Running},
+		{Title: "b", Path: "/src/web", Status: Ready, AutoYesEvents: []time.Time{
+			now.Add(-24 * time.Hour), // yesterday
+			now.Add(-time.Hour),
+			now.Add(-time.Minute),
+		}},
+		{Title: "c", Path: "/src/web", Status: Ready, StatusHistory: []StatusChange{
+			{Status: Running, At: now.Add(-time.Minute)},
+			{Status: Ready, At: now},
+		}},
+		{Title: "d", Path: "/src/web", Status: Paused, Archived: true},
+		{Title: "e", Path: "/src/api", Status: Running, DeletedAt: &deleted},
+	}
+
+	unpushed := map[*Instance]bool{instances[1]: true, instances[2]: true, instances[4]: true}
+	instances[1].CreatedAt = now.Add(-time.Hour)
+	instances[2].CreatedAt = now.Add(-2 * time.Hour)
+	instances[4].CreatedAt = now.Add(-3 * time.Hour)
+	stats := ComputeSquadStats(instances, unpushed, now)
+
+	if stats.StatusCounts[Running] != 1 || stats.StatusCounts[Ready] != 2 || stats.StatusCounts[Paused] != 0 {
+		t.Errorf("StatusCounts = %v, want 1 running and 2 ready", stats.StatusCounts)
+	}
+	if stats.Archived != 1 {
+		t.Errorf("Archived = %d, want 1", stats.Archived)
+	}
+	if stats.AutoYesToday != 2 {
+		t.Errorf("AutoYesToday = %d, want 2", stats.AutoYesToday)
+	}
+	if stats.ReadyTransitions != 1 || stats.AvgTimeToReady != time.Minute {
+		t.Errorf("AvgTimeToReady = %v over %d, want 1m0s over 1", stats.AvgTimeToReady, stats.ReadyTransitions)
+	}
+	if len(stats.Repos) != 2 || stats.Repos[0].Name != "web" || stats.Repos[0].Instances != 3 || stats.Repos[1].Name != "api" {
+		t.Errorf("Repos = %+v, want web (3) before api (1)", stats.Repos)
+	}
+	if stats.OldestUnpushed != instances[2] {
+		t.Errorf("OldestUnpushed = %v, want c, ignoring the tombstoned e", stats.OldestUnpushed)
+	}
+	if got := UnpushedInstances(instances); len(got) != 0 {
+		t.Errorf("UnpushedInstances() = %v, want none for unstarted instances", got)
+	}
+}
+
+func TestSquadStatusLine(t *testing.T) {
+	now := time.Now()
+	instances := []*Instance{
+		{Title: "a", Status: Running},
+		{Title: "b", Status: Running, RateLimitedUntil: &now},
+		{Title: "c", Status: Ready},
+		{Title: "d", Status: Running, LandState: LandFailed},
+		{Title: "e", Status: Pending},
+		{Title: "f", Status: Paused},
+		{Title: "g", Status: Ready, Archived: true},
+		{Title: "h", Status: Running, DeletedAt: &now},
+	}
+	want := "CS: 2 running, 2 needs attention, 1 pending, 1 rate limited"
+	if got := SquadStatusLine(instances); got != want {
+		t.Errorf("SquadStatusLine() = %q, want %q", got, want)
+	}
+	if got := SquadStatusLine(nil); got != "CS: 0 running, 0 needs attention" {
+		t.Errorf("SquadStatusLine(nil) = %q", got)
+	}
+}
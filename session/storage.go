@@ -5,6 +5,8 @@ import (
 	"claude-squad/log"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 )
 
@@ -20,7 +22,35 @@ type InstanceData struct {
 	UpdatedAt    time.Time  `json:"updated_at"`
 	LastOpenedAt *time.Time `json:"last_opened_at,omitempty"`
 	AutoYes      bool       `json:"auto_yes"`
+	Prompt       string     `json:"prompt,omitempty"`
 	Archived     bool       `json:"archived"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	Scratch      bool       `json:"scratch,omitempty"`
+	Notes        string     `json:"notes,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
+
+	StatusHistory []StatusChange `json:"status_history,omitempty"`
+	AutoYesEvents []time.Time    `json:"auto_yes_events,omitempty"`
+	PushEvents    []time.Time    `json:"push_events,omitempty"`
+
+	Reviewer          string       `json:"reviewer,omitempty"`
+	ReviewStatus      ReviewStatus `json:"review_status,omitempty"`
+	ReviewRequestedAt time.Time    `json:"review_requested_at,omitempty"`
+	ReviewOf          string       `json:"review_of,omitempty"`
+
+	LandState    LandState `json:"land_state,omitempty"`
+	LandQueuedAt time.Time `json:"land_queued_at,omitempty"`
+	LandError    string    `json:"land_error,omitempty"`
+
+	LastCheck    *CheckResult `json:"last_check,omitempty"`
+	PausedScreen string       `json:"paused_screen,omitempty"`
+	BoardColumn  BoardColumn  `json:"board_column,omitempty"`
+	Owner        string       `json:"owner,omitempty"`
+
+	QuarantinedAt    *time.Time `json:"quarantined_at,omitempty"`
+	QuarantineReason string     `json:"quarantine_reason,omitempty"`
+	RateLimitedUntil *time.Time `json:"rate_limited_until,omitempty"`
+	LastPrompt       string     `json:"last_prompt,omitempty"`
 
 	Program          string          `json:"program"`
 	Multiplexer      string          `json:"multiplexer"`
@@ -32,7 +62,7 @@ type InstanceData struct {
 	// ClaudeSessionID is the Claude CLI session ID for resuming conversations after restart
 	ClaudeSessionID string `json:"claude_session_id,omitempty"`
 
-	// SessionType indicates the session type: "zellij", "docker-bind", or "docker-clone"
+	// SessionType indicates the session type: "zellij", "docker-bind", "docker-clone" or "native"
 	SessionType string `json:"session_type,omitempty"`
 
 	// DockerContainerID is the Docker container ID for Docker sessions
@@ -41,8 +71,20 @@ type InstanceData struct {
 	// DockerRepoURL is the git repo URL for docker-clone mode
 	DockerRepoURL string `json:"docker_repo_url,omitempty"`
 
+	// DockerBaseImage is the image of Docker sessions
+	DockerBaseImage string `json:"docker_base_image,omitempty"`
+
+	// AuxCommand is run next to the program in a pane of its own
+	AuxCommand string `json:"aux_command,omitempty"`
+
 	// RandomSuffix is the random word pair suffix for this instance
 	RandomSuffix string `json:"random_suffix,omitempty"`
+
+	// Env holds the environment variables the program is started with
+	Env map[string]string `json:"env,omitempty"`
+
+	// Issue is the GitHub issue the instance was created for
+	Issue *IssueLink `json:"issue,omitempty"`
 }
 
 // GitWorktreeData represents the serializable data of a GitWorktree
@@ -56,14 +98,28 @@ type GitWorktreeData struct {
 
 // DiffStatsData represents the serializable data of a DiffStats
 type DiffStatsData struct {
-	Added   int    `json:"added"`
-	Removed int    `json:"removed"`
-	Content string `json:"content"`
+	Added    int    `json:"added"`
+	Removed  int    `json:"removed"`
+	Content  string `json:"content"`
+	Unpushed int    `json:"unpushed,omitempty"`
+	Pushed   bool   `json:"pushed,omitempty"`
+	Behind   int    `json:"behind,omitempty"`
 }
 
 // Storage handles saving and loading instances using the state interface
 type Storage struct {
 	state config.InstanceStorage
+	// deferRestore loads instances without starting their sessions
+	deferRestore bool
+
+	// mu guards loadErr and cached, and serializes the writes of the stored
+	// instances, which commands save from their own goroutines.
+	mu sync.Mutex
+	// loadErr is why the stored instances could not be loaded. Saving is
+	// refused while it is set so they aren't overwritten.
+	loadErr error
+	// cached is the index of the stored instances, see index.
+	cached *instanceIndex
 }
 
 // NewStorage creates a new storage instance
@@ -73,13 +129,25 @@ func NewStorage(state config.InstanceStorage) (*Storage, error) {
 	}, nil
 }
 
+// SetDeferRestore makes LoadInstances skip starting the sessions of the loaded
+// instances; they are started by Instance.Restore instead.
+func (s *Storage) SetDeferRestore(deferRestore bool) {
+	s.deferRestore = deferRestore
+}
+
 // SaveInstances saves the list of instances to disk
 func (s *Storage) SaveInstances(instances []*Instance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loadErr != nil {
+		return fmt.Errorf("not saving over instances that could not be loaded: %w", s.loadErr)
+	}
+
 	// Convert instances to InstanceData, deduplicating by title
 	data := make([]InstanceData, 0)
 	seenTitles := make(map[string]bool)
 	for _, instance := range instances {
-		if instance.Started() {
+		if instance.Started() || instance.RestorePending() || instance.Status == Pending {
 			instanceData := instance.ToInstanceData()
 			// Skip duplicates - keep only the first instance with each title
 			if seenTitles[instanceData.Title] {
@@ -91,30 +159,68 @@ func (s *Storage) SaveInstances(instances []*Instance) error {
 		}
 	}
 
-	// Marshal to JSON
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return fmt.Errorf("failed to marshal instances: %w", err)
-	}
-
-	return s.state.SaveInstances(jsonData)
+	return s.flush(data)
 }
 
 // LoadInstances loads the list of instances from disk.
 // Invalid instances (e.g., those whose multiplexer sessions no longer exist)
 // are automatically filtered out and the cleaned state is saved back to disk.
 func (s *Storage) LoadInstances() ([]*Instance, error) {
-	jsonData := s.state.GetInstances()
+	s.mu.Lock()
+	idx, err := s.index()
+	if err != nil {
+		s.loadErr = err
+		s.mu.Unlock()
+		return nil, err
+	}
+	s.loadErr = nil
+	// Loading may save the cleaned state, so it works on a copy
+	data := append([]InstanceData(nil), idx.data...)
+	s.mu.Unlock()
+
+	return s.loadInstancesData(data, 0)
+}
+
+// LoadParsableInstances loads the stored instances that can be parsed, skipping
+// the others instead of failing. It returns how many were skipped. The skipped
+// instances are dropped from the stored state.
+func (s *Storage) LoadParsableInstances() ([]*Instance, int, error) {
+	if err := s.state.LoadError(); err != nil {
+		return nil, 0, err
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(s.state.GetInstances(), &entries); err != nil {
+		return nil, 0, fmt.Errorf("failed to unmarshal instances: %w", err)
+	}
 
-	var instancesData []InstanceData
-	if err := json.Unmarshal(jsonData, &instancesData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal instances: %w", err)
+	instancesData := make([]InstanceData, 0, len(entries))
+	for i, entry := range entries {
+		var data InstanceData
+		if err := json.Unmarshal(entry, &data); err != nil {
+			log.WarningLog.Printf("Skipping unparsable instance #%d: %v", i+1, err)
+			continue
+		}
+		instancesData = append(instancesData, data)
 	}
+	s.mu.Lock()
+	s.loadErr = nil
+	s.mu.Unlock()
 
+	unparsable := len(entries) - len(instancesData)
+	instances, err := s.loadInstancesData(instancesData, unparsable)
+	return instances, unparsable, err
+}
+
+// loadInstancesData creates the instances from their stored data. The cleaned
+// state is saved if any were skipped, counting already skipped ones.
+func (s *Storage) loadInstancesData(instancesData []InstanceData, skippedCount int) ([]*Instance, error) {
 	instances := make([]*Instance, 0, len(instancesData))
-	skippedCount := 0
 	for _, data := range instancesData {
-		instance, err := FromInstanceData(data)
+		load := FromInstanceData
+		if s.deferRestore {
+			load = FromInstanceDataDeferred
+		}
+		instance, err := load(data)
 		if err != nil {
 			// Log warning and skip this instance instead of failing
 			log.WarningLog.Printf("Skipping invalid instance %q: %v", data.Title, err)
@@ -135,58 +241,81 @@ func (s *Storage) LoadInstances() ([]*Instance, error) {
 	return instances, nil
 }
 
-// DeleteInstance removes an instance from storage
-func (s *Storage) DeleteInstance(title string) error {
-	instances, err := s.LoadInstances()
+// Titles returns the titles of the stored instances, except killed ones. It
+// reads the stored data, so it's fast and doesn't restore any sessions.
+func (s *Storage) Titles() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx, err := s.index()
 	if err != nil {
-		return fmt.Errorf("failed to load instances: %w", err)
+		return nil, err
 	}
 
-	found := false
-	newInstances := make([]*Instance, 0)
-	for _, instance := range instances {
-		data := instance.ToInstanceData()
-		if data.Title != title {
-			newInstances = append(newInstances, instance)
-		} else {
-			found = true
+	titles := make([]string, 0, len(idx.data))
+	for _, data := range idx.data {
+		if data.DeletedAt != nil {
+			continue
 		}
+		titles = append(titles, data.Title)
 	}
-
-	if !found {
-		return fmt.Errorf("instance not found: %s", title)
-	}
-
-	return s.SaveInstances(newInstances)
+	return titles, nil
 }
 
-// UpdateInstance updates an existing instance in storage
-func (s *Storage) UpdateInstance(instance *Instance) error {
-	instances, err := s.LoadInstances()
+// RepoPaths returns the repositories of the stored instances, each once. Like
+// Titles, it reads the stored data without restoring any sessions.
+func (s *Storage) RepoPaths() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx, err := s.index()
 	if err != nil {
-		return fmt.Errorf("failed to load instances: %w", err)
+		return nil, err
 	}
 
-	data := instance.ToInstanceData()
-	found := false
-	for i, existing := range instances {
-		existingData := existing.ToInstanceData()
-		if existingData.Title == data.Title {
-			instances[i] = instance
-			found = true
-			break
+	var repoPaths []string
+	for _, data := range idx.data {
+		if data.Worktree.RepoPath != "" && !slices.Contains(repoPaths, data.Worktree.RepoPath) {
+			repoPaths = append(repoPaths, data.Worktree.RepoPath)
 		}
 	}
+	return repoPaths, nil
+}
 
-	if !found {
-		return fmt.Errorf("instance not found: %s", data.Title)
-	}
+// AddInstance adds a new instance to storage, leaving the stored ones alone.
+func (s *Storage) AddInstance(instance *Instance) error {
+	data := instance.ToInstanceData()
+	return s.mutate(func(idx *instanceIndex) error {
+		if _, ok := idx.get(data.Title); ok {
+			return fmt.Errorf("a session named '%s' already exists", data.Title)
+		}
+		idx.byTitle[data.Title] = len(idx.data)
+		idx.data = append(idx.data, data)
+		return nil
+	})
+}
 
-	return s.SaveInstances(instances)
+// DeleteInstance removes an instance from storage
+func (s *Storage) DeleteInstance(title string) error {
+	return s.mutate(func(idx *instanceIndex) error {
+		if !idx.remove(title) {
+			return fmt.Errorf("instance not found: %s", title)
+		}
+		return nil
+	})
+}
+
+// UpdateInstance updates an existing instance in storage
+func (s *Storage) UpdateInstance(instance *Instance) error {
+	data := instance.ToInstanceData()
+	return s.updateInstanceData(data.Title, func(stored *InstanceData) {
+		*stored = data
+	})
 }
 
 // DeleteAllInstances removes all stored instances
 func (s *Storage) DeleteAllInstances() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cached = nil
 	return s.state.DeleteAllInstances()
 }
 
@@ -200,35 +329,47 @@ func (s *Storage) UnarchiveInstance(title string) error {
 	return s.setInstanceArchived(title, false)
 }
 
-// setInstanceArchived sets the archived state of an instance
-func (s *Storage) setInstanceArchived(title string, archived bool) error {
-	jsonData := s.state.GetInstances()
-
-	var instancesData []InstanceData
-	if err := json.Unmarshal(jsonData, &instancesData); err != nil {
-		return fmt.Errorf("failed to unmarshal instances: %w", err)
-	}
+// TombstoneInstance marks an instance as killed without removing it, so that the
+// kill can be undone until its deferred cleanup runs.
+func (s *Storage) TombstoneInstance(title string, deletedAt time.Time) error {
+	return s.updateInstanceData(title, func(data *InstanceData) {
+		data.DeletedAt = &deletedAt
+	})
+}
 
-	found := false
-	for i := range instancesData {
-		if instancesData[i].Title == title {
-			instancesData[i].Archived = archived
-			found = true
-			break
-		}
-	}
+// RestoreInstance clears the tombstone of a killed instance.
+func (s *Storage) RestoreInstance(title string) error {
+	return s.updateInstanceData(title, func(data *InstanceData) {
+		data.DeletedAt = nil
+	})
+}
 
-	if !found {
-		return fmt.Errorf("instance not found: %s", title)
-	}
+// SetLastOpened records when the instance was last attached to, without
+// loading the other instances.
+func (s *Storage) SetLastOpened(title string, openedAt time.Time) error {
+	return s.updateInstanceData(title, func(data *InstanceData) {
+		data.LastOpenedAt = &openedAt
+	})
+}
 
-	// Marshal and save
-	jsonData, err := json.Marshal(instancesData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal instances: %w", err)
-	}
+// setInstanceArchived sets the archived state of an instance
+func (s *Storage) setInstanceArchived(title string, archived bool) error {
+	return s.updateInstanceData(title, func(data *InstanceData) {
+		data.Archived = archived
+	})
+}
 
-	return s.state.SaveInstances(jsonData)
+// updateInstanceData applies fn to the stored data of the instance with the given
+// title. It works on the stored data so that instances aren't restarted.
+func (s *Storage) updateInstanceData(title string, fn func(data *InstanceData)) error {
+	return s.mutate(func(idx *instanceIndex) error {
+		data, ok := idx.get(title)
+		if !ok {
+			return fmt.Errorf("instance not found: %s", title)
+		}
+		fn(data)
+		return nil
+	})
 }
 
 // StateSyncer is an optional interface for states that support sync from disk
@@ -246,8 +387,11 @@ func (s *Storage) SyncFromDisk() ([]*Instance, bool, error) {
 		return nil, false, nil
 	}
 
-	// Try to refresh from disk
+	// Try to refresh from disk. It replaces the stored instances, so it's
+	// guarded like a write.
+	s.mu.Lock()
 	refreshed, err := syncer.RefreshFromDisk()
+	s.mu.Unlock()
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to refresh state from disk: %w", err)
 	}
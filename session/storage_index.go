@@ -0,0 +1,101 @@
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// instanceIndex is the stored instance data in memory, in stored order and
+// indexed by title.
+type instanceIndex struct {
+	data    []InstanceData
+	byTitle map[string]int
+	// raw is the JSON the index was parsed from or flushed as.
+	raw json.RawMessage
+}
+
+// newInstanceIndex indexes the instance data. Only the first instance with a
+// title is indexed.
+func newInstanceIndex(data []InstanceData, raw json.RawMessage) *instanceIndex {
+	idx := &instanceIndex{data: data, byTitle: make(map[string]int, len(data)), raw: raw}
+	for i, d := range data {
+		if _, ok := idx.byTitle[d.Title]; !ok {
+			idx.byTitle[d.Title] = i
+		}
+	}
+	return idx
+}
+
+// get returns the data of the instance with the title.
+func (idx *instanceIndex) get(title string) (*InstanceData, bool) {
+	i, ok := idx.byTitle[title]
+	if !ok {
+		return nil, false
+	}
+	return &idx.data[i], true
+}
+
+// remove drops the instance with the title.
+func (idx *instanceIndex) remove(title string) bool {
+	i, ok := idx.byTitle[title]
+	if !ok {
+		return false
+	}
+	idx.data = append(idx.data[:i], idx.data[i+1:]...)
+	delete(idx.byTitle, title)
+	for t, j := range idx.byTitle {
+		if j > i {
+			idx.byTitle[t] = j - 1
+		}
+	}
+	return true
+}
+
+// index returns the stored instances, parsing them only if the stored JSON
+// changed since they were last parsed or saved. s.mu must be held.
+func (s *Storage) index() (*instanceIndex, error) {
+	if err := s.state.LoadError(); err != nil {
+		return nil, err
+	}
+	raw := s.state.GetInstances()
+	if s.cached != nil && bytes.Equal(s.cached.raw, raw) {
+		return s.cached, nil
+	}
+	var data []InstanceData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal instances: %w", err)
+	}
+	s.cached = newInstanceIndex(data, raw)
+	return s.cached, nil
+}
+
+// mutate applies fn to the stored instances and saves them.
+func (s *Storage) mutate(fn func(idx *instanceIndex) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx, err := s.index()
+	if err != nil {
+		return err
+	}
+	// fn changes the cached data, so it's parsed again if saving fails
+	s.cached = nil
+	if err := fn(idx); err != nil {
+		return err
+	}
+	return s.flush(idx.data)
+}
+
+// flush saves the instance data. It is the only place the stored instances
+// are written. s.mu must be held.
+func (s *Storage) flush(data []InstanceData) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal instances: %w", err)
+	}
+	if err := s.state.SaveInstances(raw); err != nil {
+		return err
+	}
+	s.cached = newInstanceIndex(data, raw)
+	return nil
+}
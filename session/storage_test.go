@@ -0,0 +1,244 @@
+package session
+
+import (
+	"claude-squad/log"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryState keeps the instances in memory.
+type memoryState struct {
+	instances json.RawMessage
+}
+
+func (s *memoryState) SaveInstances(instancesJSON json.RawMessage) error {
+	s.instances = instancesJSON
+	return nil
+}
+
+func (s *memoryState) GetInstances() json.RawMessage {
+	return s.instances
+}
+
+func (s *memoryState) DeleteAllInstances() error {
+	s.instances = json.RawMessage("[]")
+	return nil
+}
+
+func (s *memoryState) LoadError() error {
+	return nil
+}
+
+func TestStorageTitles(t *testing.T) {
+	deletedAt := time.Now()
+	data, err := json.Marshal([]InstanceData{
+		{Title: "api"},
+		{Title: "killed", DeletedAt: &deletedAt},
+		{Title: "docs", Archived: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage, err := NewStorage(&memoryState{instances: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	titles, err := storage.Titles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(titles) != 2 || titles[0] != "api" || titles[1] != "docs" {
+		t.Errorf("Titles() = %v, want [api docs]", titles)
+	}
+}
+
+func TestStorageSetLastOpened(t *testing.T) {
+	data, err := json.Marshal([]InstanceData{{Title: "api"}, {Title: "docs"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &memoryState{instances: data}
+	storage, err := NewStorage(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	openedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := storage.SetLastOpened("docs", openedAt); err != nil {
+		t.Fatal(err)
+	}
+	var stored []InstanceData
+	if err := json.Unmarshal(state.instances, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored[0].LastOpenedAt != nil {
+		t.Errorf("LastOpenedAt of api = %v, want nil", stored[0].LastOpenedAt)
+	}
+	if stored[1].LastOpenedAt == nil || !stored[1].LastOpenedAt.Equal(openedAt) {
+		t.Errorf("LastOpenedAt of docs = %v, want %v", stored[1].LastOpenedAt, openedAt)
+	}
+
+	if err := storage.SetLastOpened("missing", openedAt); err == nil {
+		t.Error("SetLastOpened() of a missing instance succeeded")
+	}
+}
+
+func TestStorageMutationsKeepOtherInstances(t *testing.T) {
+	data, err := json.Marshal([]InstanceData{{Title: "api"}, {Title: "docs"}, {Title: "web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &memoryState{instances: data}
+	storage, err := NewStorage(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.DeleteInstance("docs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.UpdateInstance(&Instance{Title: "web", Notes: "ship it"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.ArchiveInstance("api"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.DeleteInstance("docs"); err == nil {
+		t.Error("DeleteInstance() of a deleted instance succeeded")
+	}
+
+	var stored []InstanceData
+	if err := json.Unmarshal(state.instances, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 || stored[0].Title != "api" || stored[1].Title != "web" {
+		t.Fatalf("stored instances = %+v, want api and web", stored)
+	}
+	if !stored[0].Archived || stored[1].Notes != "ship it" {
+		t.Errorf("stored instances = %+v, want api archived and the notes of web", stored)
+	}
+
+	// Changes made by another process are picked up
+	state.instances = json.RawMessage(`[{"title":"other"}]`)
+	titles, err := storage.Titles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(titles) != 1 || titles[0] != "other" {
+		t.Errorf("Titles() = %v, want [other]", titles)
+	}
+}
+
+func TestStorageAddInstance(t *testing.T) {
+	data, err := json.Marshal([]InstanceData{{Title: "api"}, {Title: "web", Notes: "ship it"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &memoryState{instances: data}
+	storage, err := NewStorage(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.AddInstance(&Instance{Title: "cli"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.AddInstance(&Instance{Title: "web"}); err == nil {
+		t.Error("AddInstance() of an existing title succeeded")
+	}
+
+	var stored []InstanceData
+	if err := json.Unmarshal(state.instances, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 3 || stored[0].Title != "api" || stored[1].Title != "web" || stored[2].Title != "cli" {
+		t.Fatalf("stored instances = %+v, want api, web and cli", stored)
+	}
+	if stored[1].Notes != "ship it" {
+		t.Errorf("stored instances = %+v, want web kept as it was", stored)
+	}
+}
+
+func TestStorageLoadFailureKeepsInstances(t *testing.T) {
+	log.Initialize(false)
+	defer log.Close()
+
+	// The second instance has a title of the wrong type
+	corrupt := json.RawMessage(`[{"title":"api","status":4},{"title":42},{"title":"docs","status":4}]`)
+	state := &memoryState{instances: corrupt}
+	storage, err := NewStorage(state)
+	if err != nil {
+		t.Fatalf("NewStorage() error = %v", err)
+	}
+	storage.SetDeferRestore(true)
+
+	if _, err := storage.LoadInstances(); err == nil {
+		t.Fatal("LoadInstances() succeeded with a corrupt instance")
+	}
+	if err := storage.SaveInstances(nil); err == nil {
+		t.Error("SaveInstances() overwrote instances that could not be loaded")
+	}
+	if string(state.instances) != string(corrupt) {
+		t.Errorf("stored instances = %s, want them untouched", state.instances)
+	}
+
+	instances, skipped, err := storage.LoadParsableInstances()
+	if err != nil {
+		t.Fatalf("LoadParsableInstances() error = %v", err)
+	}
+	if skipped != 1 || len(instances) != 2 || instances[0].Title != "api" || instances[1].Title != "docs" {
+		t.Errorf("LoadParsableInstances() = %d instance(s), %d skipped, want api and docs with 1 skipped", len(instances), skipped)
+	}
+	titles, err := storage.Titles()
+	if err != nil || len(titles) != 2 {
+		t.Errorf("Titles() = %v, %v, want the unparsable instance dropped from the stored state", titles, err)
+	}
+}
+
+func TestStorageConcurrentMutations(t *testing.T) {
+	titles := []string{"api", "docs", "web", "cli"}
+	stored := make([]InstanceData, len(titles))
+	for i, title := range titles {
+		stored[i] = InstanceData{Title: title}
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &memoryState{instances: data}
+	storage, err := NewStorage(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Commands update instances from their own goroutines
+	openedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for _, title := range titles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				if err := storage.SetLastOpened(title, openedAt); err != nil {
+					t.Error(err)
+				}
+				if _, err := storage.Titles(); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := json.Unmarshal(state.instances, &stored); err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range stored {
+		if data.LastOpenedAt == nil {
+			t.Errorf("the update of %s was lost", data.Title)
+		}
+	}
+}
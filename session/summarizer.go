@@ -1,11 +1,14 @@
 package session
 
 import (
+	"claude-squad/config"
 	"claude-squad/log"
 	"regexp"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/mattn/go-runewidth"
 )
 
 const (
@@ -39,22 +42,63 @@ var (
 	gitPattern = regexp.MustCompile(`(?i)(commit|push|pull|merge|rebase|checkout|branch)`)
 )
 
-// Summarizer handles generating AI-powered summaries for instances
-type Summarizer struct {
+// Summarizer produces a one-line summary of what an instance is doing.
+type Summarizer interface {
+	// Summarize summarizes the instance given its current pane content.
+	Summarize(instance *Instance, content string) (string, error)
+}
+
+// NewSummarizer creates the Summarizer selected by the summary mode in the config
+func NewSummarizer(cfg *config.Config) Summarizer {
+	switch cfg.SummaryMode {
+	case config.SummaryModeTranscript:
+		return TranscriptSummarizer{}
+	case config.SummaryModeCommand:
+		return NewCommandSummarizer(cfg.SummaryCommand, time.Duration(cfg.SummaryCommandInterval)*time.Second)
+	default:
+		return HeuristicSummarizer{}
+	}
+}
+
+// HeuristicSummarizer summarizes the pane content by matching known patterns.
+type HeuristicSummarizer struct{}
+
+// Summarize implements Summarizer.
+func (HeuristicSummarizer) Summarize(_ *Instance, content string) (string, error) {
+	return extractSummaryFromContent(content), nil
+}
+
+// TranscriptSummarizer summarizes Claude's session transcript, falling back to
+// the pane content for instances without one.
+type TranscriptSummarizer struct{}
+
+// Summarize implements Summarizer.
+func (TranscriptSummarizer) Summarize(instance *Instance, content string) (string, error) {
+	transcript, err := instance.ReadTranscriptSummary()
+	if err != nil {
+		log.DebugLog.Printf("falling back to terminal summary for %s: %v", instance.Title, err)
+		return extractSummaryFromContent(content), nil
+	}
+	return transcript.String(), nil
+}
+
+// SummaryUpdater refreshes the summaries of instances one at a time
+type SummaryUpdater struct {
 	mu sync.Mutex
 	// lastUpdateIndex tracks which instance was last updated for staggered refresh
 	lastUpdateIndex int
+	summarizer      Summarizer
 }
 
-// NewSummarizer creates a new Summarizer
-func NewSummarizer() *Summarizer {
-	return &Summarizer{}
+// NewSummaryUpdater creates a new SummaryUpdater using the given Summarizer
+func NewSummaryUpdater(summarizer Summarizer) *SummaryUpdater {
+	return &SummaryUpdater{summarizer: summarizer}
 }
 
 // UpdateNextSummary updates the summary for the next instance in the rotation
 // Returns the instance that was updated, or nil if no update was performed
 // Each instance is only updated at most once per SummaryPerInstanceCooldown
-func (s *Summarizer) UpdateNextSummary(instances []*Instance) *Instance {
+func (s *SummaryUpdater) UpdateNextSummary(instances []*Instance) *Instance {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -95,8 +139,8 @@ func (s *Summarizer) UpdateNextSummary(instances []*Instance) *Instance {
 	return nil
 }
 
-// generateSummary generates a summary for the given instance by parsing terminal content
-func (s *Summarizer) generateSummary(instance *Instance) error {
+// generateSummary generates a summary for the given instance from its terminal content
+func (s *SummaryUpdater) generateSummary(instance *Instance) error {
 	// Get the current terminal content
 	content, err := instance.Preview()
 	if err != nil {
@@ -109,8 +153,16 @@ func (s *Summarizer) generateSummary(instance *Instance) error {
 		return nil
 	}
 
-	// Extract summary from terminal content
-	summary := extractSummaryFromContent(content)
+	// Keep notable output blocks before they scroll away
+	instance.snippets.Record(content)
+
+	// Refresh the agent's todo list; not every program keeps a transcript
+	_, _ = instance.ReadTranscriptSummary()
+
+	summary, err := s.summarizer.Summarize(instance, content)
+	if err != nil {
+		return err
+	}
 
 	instance.Summary = summary
 	instance.SummaryUpdatedAt = time.Now()
@@ -206,11 +258,13 @@ func extractSummaryFromContent(content string) string {
 	}
 
 	summary := strings.Join(parts, " - ")
-	if len(summary) > SummaryMaxLength {
-		summary = summary[:SummaryMaxLength-3] + "..."
-	}
+	return truncateSummary(summary)
+}
 
-	return summary
+// truncateSummary cuts a summary to SummaryMaxLength columns, ending it with an
+// ellipsis. Characters are never split.
+func truncateSummary(summary string) string {
+	return runewidth.Truncate(summary, SummaryMaxLength, "...")
 }
 
 // GetSummary returns the summary for an instance, or a placeholder if none exists
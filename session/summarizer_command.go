@@ -0,0 +1,114 @@
+package session
+
+import (
+	"bytes"
+	"claude-squad/config"
+	"claude-squad/log"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultSummaryCommand is used when no summary command is configured
+	defaultSummaryCommand = "claude -p"
+	// defaultSummaryCommandInterval is used when no summary command interval is configured
+	defaultSummaryCommandInterval = 60 * time.Second
+	// summaryCommandTimeout bounds how long a single run of the summary command may take
+	summaryCommandTimeout = 60 * time.Second
+	// summaryCommandLines is how many trailing lines of pane content are sent to the command
+	summaryCommandLines = 100
+)
+
+// summaryCommandPrompt precedes the pane content sent to the summary command
+const summaryCommandPrompt = "Below is the terminal output of an AI coding agent. " +
+	"Summarize what it is currently doing in a single line of at most 80 characters. " +
+	"Reply with the summary only.\n\n"
+
+// CommandSummarizer asks an external command, such as an LLM CLI, to summarize
+// the recent pane content. The command runs at most once per interval for each
+// instance and only when the content changed; the last summary is reused in the
+// meantime. The heuristic summary is used until the command has succeeded.
+type CommandSummarizer struct {
+	command  string
+	interval time.Duration
+
+	mu    sync.Mutex
+	cache map[*Instance]commandSummary
+}
+
+// commandSummary is the last summary produced for an instance
+type commandSummary struct {
+	content string
+	summary string
+	at      time.Time
+}
+
+// NewCommandSummarizer creates a CommandSummarizer, using the defaults for an
+// empty command or a non-positive interval.
+func NewCommandSummarizer(command string, interval time.Duration) *CommandSummarizer {
+	if strings.TrimSpace(command) == "" {
+		command = defaultSummaryCommand
+	}
+	if interval <= 0 {
+		interval = defaultSummaryCommandInterval
+	}
+	return &CommandSummarizer{
+		command:  command,
+		interval: interval,
+		cache:    make(map[*Instance]commandSummary),
+	}
+}
+
+// Summarize implements Summarizer.
+func (c *CommandSummarizer) Summarize(instance *Instance, content string) (string, error) {
+	recent := lastLines(ansiEscapePattern.ReplaceAllString(content, ""), summaryCommandLines)
+
+	c.mu.Lock()
+	cached, ok := c.cache[instance]
+	c.mu.Unlock()
+	if ok && (cached.content == recent || time.Since(cached.at) < c.interval) {
+		return cached.summary, nil
+	}
+
+	summary, err := c.run(recent)
+	if err != nil {
+		// Still wait for the interval before running the command again
+		log.WarningLog.Printf("summary command failed for %s, using heuristic summary: %v", instance.Title, err)
+		summary = extractSummaryFromContent(content)
+	}
+
+	c.mu.Lock()
+	c.cache[instance] = commandSummary{content: recent, summary: summary, at: time.Now()}
+	c.mu.Unlock()
+	return summary, nil
+}
+
+// run runs the summary command with the content on stdin and returns the first
+// line of its output.
+func (c *CommandSummarizer) run(content string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), summaryCommandTimeout)
+	defer cancel()
+
+	cmd := config.ShellCommand(ctx, c.command)
+	cmd.Stdin = strings.NewReader(summaryCommandPrompt + content)
+	// Run outside the worktree so that an agent CLI doesn't trigger the instance's
+	// hooks or record its session next to the instance's own
+	cmd.Dir = os.TempDir()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %s (%w)", c.command, strings.TrimSpace(stderr.String()), err)
+	}
+
+	summary := strings.Trim(firstLine(string(output)), "\"'`")
+	if summary == "" {
+		return "", fmt.Errorf("%s produced no summary", c.command)
+	}
+	return truncateSummary(summary), nil
+}
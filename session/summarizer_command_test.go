@@ -0,0 +1,67 @@
+package session
+
+import (
+	"claude-squad/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandSummarizer(t *testing.T) {
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := `cat > /dev/null; echo run >> '` + runs + `'; echo '"Refactoring the storage layer"'`
+	summarizer := NewCommandSummarizer(command, time.Hour)
+	instance := &Instance{Title: "test"}
+
+	countRuns := func() int {
+		content, _ := os.ReadFile(runs)
+		return strings.Count(string(content), "run")
+	}
+
+	summary, err := summarizer.Summarize(instance, "Editing storage.go")
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if summary != "Refactoring the storage layer" {
+		t.Errorf("Summarize() = %q, want the command output without quotes", summary)
+	}
+
+	// Within the interval the cached summary is reused, even for new content
+	if summary, _ := summarizer.Summarize(instance, "Running tests"); summary != "Refactoring the storage layer" || countRuns() != 1 {
+		t.Errorf("Summarize() within interval = %q after %d runs, want the cached summary after 1 run", summary, countRuns())
+	}
+
+	// Past the interval the command only runs again if the content changed
+	summarizer.interval = 0
+	summarizer.Summarize(instance, "Editing storage.go")
+	if countRuns() != 1 {
+		t.Errorf("command ran %d times for unchanged content, want 1", countRuns())
+	}
+	summarizer.Summarize(instance, "Running tests")
+	if countRuns() != 2 {
+		t.Errorf("command ran %d times for changed content, want 2", countRuns())
+	}
+}
+
+func TestNewSummarizer(t *testing.T) {
+	tests := []struct {
+		mode string
+		want Summarizer
+	}{
+		{"", HeuristicSummarizer{}},
+		{config.SummaryModeTerminal, HeuristicSummarizer{}},
+		{config.SummaryModeTranscript, TranscriptSummarizer{}},
+	}
+	for _, tt := range tests {
+		if got := NewSummarizer(&config.Config{SummaryMode: tt.mode}); got != tt.want {
+			t.Errorf("NewSummarizer(%q) = %T, want %T", tt.mode, got, tt.want)
+		}
+	}
+
+	command, ok := NewSummarizer(&config.Config{SummaryMode: config.SummaryModeCommand}).(*CommandSummarizer)
+	if !ok || command.command != defaultSummaryCommand || command.interval != defaultSummaryCommandInterval {
+		t.Errorf("NewSummarizer(command) = %+v, want a CommandSummarizer with the defaults", command)
+	}
+}
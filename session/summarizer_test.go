@@ -1,6 +1,7 @@
 package session
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -104,3 +105,21 @@ func TestExtractSummaryMaxLength(t *testing.T) {
 		t.Errorf("summary length %d exceeds max length %d", len(result), SummaryMaxLength)
 	}
 }
+
+func FuzzExtractSummaryFromContent(f *testing.F) {
+	f.Add("")
+	f.Add("Running go test ./...\nPASS\nok  \tclaude-squad/session")
+	f.Add("⏺ Update(session/instance.go)\n  ⎿  Error: file not found")
+	f.Add("\x1b[31merror\x1b[0m: build failed in src/main.go\n\x1b]8;;file:///x\x07x\x1b]8;;\x07")
+	f.Add(strings.Repeat("a/b/c.go ", 100) + "\n> ")
+
+	f.Fuzz(func(t *testing.T, content string) {
+		summary := extractSummaryFromContent(content)
+		if summary == "" {
+			t.Errorf("extractSummaryFromContent(%q) returned an empty summary", content)
+		}
+		if len(summary) > SummaryMaxLength {
+			t.Errorf("summary length %d exceeds max length %d", len(summary), SummaryMaxLength)
+		}
+	})
+}
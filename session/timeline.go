@@ -0,0 +1,116 @@
+package session
+
+import (
+	"claude-squad/audit"
+	"sort"
+	"time"
+)
+
+// maxPushEvents is how many pushes are kept per instance.
+const maxPushEvents = 50
+
+// TimelineCell is what an instance was doing during a slot of the timeline.
+type TimelineCell int
+
+const (
+	// TimelineNone means the instance did not exist yet.
+	TimelineNone TimelineCell = iota
+	// TimelineIdle means the instance existed but no status was recorded, e.g.
+	// while loading or before the oldest recorded status change.
+	TimelineIdle
+	// TimelineRunning means the agent was working at some point of the slot.
+	TimelineRunning
+	// TimelineReady means the agent was waiting for input.
+	TimelineReady
+	// TimelinePaused means the instance was paused.
+	TimelinePaused
+	// TimelinePushed means the branch was pushed or landed during the slot.
+	TimelinePushed
+)
+
+// TimelineRow is the activity of an instance over a timeline.
+type TimelineRow struct {
+	Instance *Instance
+	Cells    []TimelineCell
+}
+
+// recordPush appends a push event, dropping the oldest beyond maxPushEvents.
+func (i *Instance) recordPush(at time.Time) {
+	i.PushEvents = append(i.PushEvents, at)
+	if len(i.PushEvents) > maxPushEvents {
+		i.PushEvents = i.PushEvents[len(i.PushEvents)-maxPushEvents:]
+	}
+}
+
+// RecordPush records that the instance's branch was pushed.
+func (i *Instance) RecordPush() {
+	i.recordPush(time.Now())
+	audit.Record(audit.EventPush, i.Title, i.Branch)
+}
+
+// ComputeTimeline divides the time from start to end into slots and returns
+// what each instance did in each slot, oldest instance first. Tombstoned
+// instances and instances created after end are left out.
+func ComputeTimeline(instances []*Instance, start, end time.Time, slots int) []TimelineRow {
+	if slots <= 0 || !end.After(start) {
+		return nil
+	}
+	slot := end.Sub(start) / time.Duration(slots)
+
+	var rows []TimelineRow
+	for _, instance := range instances {
+		if instance == nil || instance.Tombstoned() || instance.CreatedAt.After(end) {
+			continue
+		}
+		cells := make([]TimelineCell, slots)
+		for n := range cells {
+			from := start.Add(time.Duration(n) * slot)
+			cells[n] = instance.timelineCell(from, from.Add(slot))
+		}
+		rows = append(rows, TimelineRow{Instance: instance, Cells: cells})
+	}
+	sort.SliceStable(rows, func(a, b int) bool {
+		return rows[a].Instance.CreatedAt.Before(rows[b].Instance.CreatedAt)
+	})
+	return rows
+}
+
+// timelineCell returns what the instance did from from until to. Pushes stand
+// out over running, which stands out over the status at the end of the slot.
+func (i *Instance) timelineCell(from, to time.Time) TimelineCell {
+	if !i.CreatedAt.Before(to) {
+		return TimelineNone
+	}
+	for _, at := range i.PushEvents {
+		if !at.Before(from) && at.Before(to) {
+			return TimelinePushed
+		}
+	}
+
+	// The status at the start of the slot, then every change during it
+	status, known := Status(0), false
+	running := false
+	for _, change := range i.StatusHistory {
+		if !change.At.Before(to) {
+			break
+		}
+		status, known = change.Status, true
+		if !change.At.Before(from) && status == Running {
+			running = true
+		}
+	}
+	if running || (known && status == Running) {
+		return TimelineRunning
+	}
+	if !known {
+		return TimelineIdle
+	}
+	switch status {
+	case Ready:
+		return TimelineReady
+	case Paused:
+		return TimelinePaused
+	default:
+		return TimelineIdle
+	}
+}
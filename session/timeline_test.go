@@ -0,0 +1,73 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeTimeline(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return start.Add(time.Duration(h) * time.Hour) }
+	deleted := at(1)
+
+	instances := []*Instance{
+		{
+			Title:     "newer",
+			CreatedAt: at(2),
+			StatusHistory: []StatusChange{
+				{Status: Running, At: at(2)},
+				{Status: Ready, At: at(3)},
+				{Status: Paused, At: at(5)},
+			},
+			PushEvents: []time.Time{at(4).Add(30 * time.Minute)},
+		},
+		{
+			// Created before the window, with its early history dropped
+			Title:         "older",
+			CreatedAt:     start.Add(-time.Hour),
+			StatusHistory: []StatusChange{{Status: Running, At: at(1).Add(30 * time.Minute)}, {Status: Ready, At: at(1).Add(45 * time.Minute)}},
+		},
+		{Title: "deleted", CreatedAt: at(0), DeletedAt: &deleted},
+		{Title: "future", CreatedAt: at(7)},
+	}
+
+	rows := ComputeTimeline(instances, start, at(6), 6)
+	if len(rows) != 2 {
+		t.Fatalf("ComputeTimeline() returned %d rows, want 2", len(rows))
+	}
+	if rows[0].Instance.Title != "older" || rows[1].Instance.Title != "newer" {
+		t.Errorf("rows = %s, %s, want the oldest instance first", rows[0].Instance.Title, rows[1].Instance.Title)
+	}
+
+	tests := []struct {
+		title string
+		row   TimelineRow
+		want  []TimelineCell
+	}{
+		{"older", rows[0], []TimelineCell{TimelineIdle, TimelineRunning, TimelineReady, TimelineReady, TimelineReady, TimelineReady}},
+		{"newer", rows[1], []TimelineCell{TimelineNone, TimelineNone, TimelineRunning, TimelineReady, TimelinePushed, TimelinePaused}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			for n, want := range tt.want {
+				if got := tt.row.Cells[n]; got != want {
+					t.Errorf("Cells[%d] = %v, want %v", n, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordPushKeepsLatest(t *testing.T) {
+	instance := &Instance{}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for n := 0; n < maxPushEvents+5; n++ {
+		instance.recordPush(start.Add(time.Duration(n) * time.Minute))
+	}
+	if len(instance.PushEvents) != maxPushEvents {
+		t.Fatalf("len(PushEvents) = %d, want %d", len(instance.PushEvents), maxPushEvents)
+	}
+	if want := start.Add(5 * time.Minute); !instance.PushEvents[0].Equal(want) {
+		t.Errorf("PushEvents[0] = %v, want the oldest events dropped", instance.PushEvents[0])
+	}
+}
@@ -0,0 +1,59 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TitleMaxLength is the maximum number of characters in an instance title.
+const TitleMaxLength = 32
+
+// titleForbiddenChars are characters that can't be part of a git branch name
+// or that break the session and worktree names derived from the title.
+const titleForbiddenChars = `/\:~^?*[`
+
+// ValidateTitle returns an error if the title can't be used for the instance:
+// it must not be empty or too long, must not contain characters that break
+// branch or session names, and must not be the title of another instance,
+// ignoring case. instances may include the instance itself.
+func ValidateTitle(title string, instance *Instance, instances []*Instance) error {
+	if err := validateTitle(title); err != nil {
+		return err
+	}
+	for _, other := range instances {
+		if other != instance && strings.EqualFold(other.Title, title) {
+			return fmt.Errorf("a session named '%s' already exists", other.Title)
+		}
+	}
+	return nil
+}
+
+// validateTitle returns an error if the title can't be used for an instance,
+// regardless of the other instances.
+func validateTitle(title string) error {
+	if strings.TrimSpace(title) == "" {
+		return fmt.Errorf("title cannot be empty")
+	}
+	if utf8.RuneCountInString(title) > TitleMaxLength {
+		return fmt.Errorf("title cannot be longer than %d characters", TitleMaxLength)
+	}
+	for _, r := range title {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("title cannot contain control characters")
+		}
+		if strings.ContainsRune(titleForbiddenChars, r) {
+			return fmt.Errorf("title cannot contain '%c'", r)
+		}
+	}
+	switch {
+	case strings.Contains(title, ".."), strings.Contains(title, "@{"):
+		return fmt.Errorf("title cannot contain '..' or '@{'")
+	case strings.HasPrefix(title, "."), strings.HasPrefix(title, "-"):
+		return fmt.Errorf("title cannot start with '%c'", title[0])
+	case strings.HasSuffix(title, "."), strings.HasSuffix(title, ".lock"):
+		return fmt.Errorf("title cannot end with '.' or '.lock'")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package session
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTitle(t *testing.T) {
+	existing := &Instance{Title: "Feature"}
+	self := &Instance{Title: "mine"}
+	instances := []*Instance{existing, self}
+
+	tests := []struct {
+		name    string
+		title   string
+		wantErr string
+	}{
+		{"valid", "fix login bug", ""},
+		{"unicode", "日本語のタイトル", ""},
+		{"own title", "mine", ""},
+		{"empty", "", "empty"},
+		{"blank", "   ", "empty"},
+		{"too long", strings.Repeat("a", 33), "longer"},
+		{"duplicate ignoring case", "feature", "already exists"},
+		{"slash", "feat/login", "'/'"},
+		{"backslash", `feat\login`, `'\'`},
+		{"colon", "fix: login", "':'"},
+		{"control character", "fix\tlogin", "control"},
+		{"dot dot", "fix..login", "'..'"},
+		{"leading dot", ".hidden", "start with '.'"},
+		{"leading dash", "-fix", "start with '-'"},
+		{"lock suffix", "fix.lock", "'.lock'"},
+	}
+	for _, tt := range tests {
+		err := ValidateTitle(tt.title, self, instances)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: ValidateTitle(%q) error = %v, want nil", tt.name, tt.title, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: ValidateTitle(%q) error = %v, want it to mention %q", tt.name, tt.title, err, tt.wantErr)
+		}
+	}
+}
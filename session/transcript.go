@@ -0,0 +1,233 @@
+package session
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Statuses of a TranscriptTodo
+const (
+	TodoPending    = "pending"
+	TodoInProgress = "in_progress"
+	TodoCompleted  = "completed"
+)
+
+// TranscriptTodo is an item of the todo list Claude keeps with the TodoWrite tool.
+type TranscriptTodo struct {
+	Content    string `json:"content"`
+	Status     string `json:"status"`
+	ActiveForm string `json:"activeForm"`
+}
+
+// TranscriptSummary is the state of a Claude session as recorded in its JSONL transcript.
+type TranscriptSummary struct {
+	// LastUserMessage is the last prompt the user sent.
+	LastUserMessage string
+	// LastAction describes the last tool call or reply of the assistant.
+	LastAction string
+	// Todos is the latest todo list.
+	Todos []TranscriptTodo
+	// ToolErrors counts the tool calls that failed.
+	ToolErrors int
+	// awaitingReply is true if the assistant has not acted since the last user message.
+	awaitingReply bool
+}
+
+// transcriptEntry is a line of Claude's session .jsonl file
+type transcriptEntry struct {
+	Type    string `json:"type"`
+	IsMeta  bool   `json:"isMeta"`
+	Message struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// transcriptContent is an item of a message's content
+type transcriptContent struct {
+	Type    string          `json:"type"`
+	Text    string          `json:"text"`
+	Name    string          `json:"name"`
+	Input   json.RawMessage `json:"input"`
+	IsError bool            `json:"is_error"`
+}
+
+// ClaudeTranscriptPath returns the path of the transcript of a Claude session in a worktree.
+func ClaudeTranscriptPath(worktreePath, sessionID string) (string, error) {
+	projectDir, err := claudeProjectDir(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, sessionID+".jsonl"), nil
+}
+
+// ReadTranscriptSummary reads a Claude session transcript and summarizes it.
+func ReadTranscriptSummary(path string) (*TranscriptSummary, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer file.Close()
+
+	summary := &TranscriptSummary{}
+	scanner := bufio.NewScanner(file)
+	// Tool results can make for very long lines
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 16*1024*1024)
+
+	for scanner.Scan() {
+		var entry transcriptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// Skip lines that don't parse as JSON
+			continue
+		}
+		summary.add(entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading transcript: %w", err)
+	}
+	return summary, nil
+}
+
+// add updates the summary with a transcript entry.
+func (s *TranscriptSummary) add(entry transcriptEntry) {
+	if entry.IsMeta || (entry.Type != "user" && entry.Type != "assistant") {
+		return
+	}
+
+	// Content is either plain text or a list of items
+	var text string
+	if err := json.Unmarshal(entry.Message.Content, &text); err == nil {
+		s.addText(entry.Type, text)
+		return
+	}
+	var items []transcriptContent
+	if err := json.Unmarshal(entry.Message.Content, &items); err != nil {
+		return
+	}
+	for _, item := range items {
+		switch item.Type {
+		case "text":
+			s.addText(entry.Type, item.Text)
+		case "tool_use":
+			s.addToolUse(item)
+		case "tool_result":
+			if item.IsError {
+				s.ToolErrors++
+			}
+		}
+	}
+}
+
+// addText records a text message from the user or the assistant.
+func (s *TranscriptSummary) addText(role, text string) {
+	text = firstLine(text)
+	// Skip command output and system reminders that are recorded as user messages
+	if text == "" || strings.HasPrefix(text, "<") {
+		return
+	}
+	if role == "user" {
+		s.LastUserMessage = text
+		s.awaitingReply = true
+		return
+	}
+	s.LastAction = text
+	s.awaitingReply = false
+}
+
+// addToolUse records a tool call of the assistant.
+func (s *TranscriptSummary) addToolUse(item transcriptContent) {
+	s.awaitingReply = false
+
+	if item.Name == "TodoWrite" {
+		var input struct {
+			Todos []TranscriptTodo `json:"todos"`
+		}
+		if err := json.Unmarshal(item.Input, &input); err == nil {
+			s.Todos = input.Todos
+		}
+	}
+
+	var input map[string]any
+	_ = json.Unmarshal(item.Input, &input)
+	s.LastAction = item.Name
+	for _, key := range []string{"file_path", "notebook_path", "command", "pattern", "url", "query", "description"} {
+		if value, ok := input[key].(string); ok && value != "" {
+			if strings.HasSuffix(key, "_path") {
+				value = filepath.Base(value)
+			}
+			s.LastAction += " " + firstLine(value)
+			break
+		}
+	}
+}
+
+// currentTodo returns the todo being worked on and how many are completed.
+func (s *TranscriptSummary) currentTodo() (current *TranscriptTodo, completed int) {
+	for i, todo := range s.Todos {
+		switch todo.Status {
+		case TodoCompleted:
+			completed++
+		case TodoInProgress:
+			if current == nil {
+				current = &s.Todos[i]
+			}
+		}
+	}
+	return current, completed
+}
+
+// TodoProgress returns how many todos are completed out of how many in total.
+func TodoProgress(todos []TranscriptTodo) (completed, total int) {
+	for _, todo := range todos {
+		if todo.Status == TodoCompleted {
+			completed++
+		}
+	}
+	return completed, len(todos)
+}
+
+// String formats the summary as a single line of at most SummaryMaxLength characters.
+func (s *TranscriptSummary) String() string {
+	var parts []string
+
+	current, completed := s.currentTodo()
+	switch {
+	case s.awaitingReply:
+		parts = append(parts, "Asked: "+s.LastUserMessage)
+	case current != nil:
+		activity := current.ActiveForm
+		if activity == "" {
+			activity = current.Content
+		}
+		parts = append(parts, activity)
+	case s.LastAction != "":
+		parts = append(parts, s.LastAction)
+	}
+	if len(s.Todos) > 0 {
+		parts = append(parts, fmt.Sprintf("todos %d/%d", completed, len(s.Todos)))
+	}
+	if s.ToolErrors > 0 {
+		parts = append(parts, fmt.Sprintf("%d tool error(s)", s.ToolErrors))
+	}
+
+	if len(parts) == 0 {
+		return "No activity yet"
+	}
+	summary := strings.Join(parts, " - ")
+	return truncateSummary(summary)
+}
+
+// firstLine returns the first non-empty line of the text, trimmed.
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
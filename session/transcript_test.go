@@ -0,0 +1,69 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadTranscriptSummary(t *testing.T) {
+	lines := []string{
+		`{"type":"summary","summary":"Earlier work"}`,
+		`{"type":"user","message":{"role":"user","content":"<command-name>/clear</command-name>"}}`,
+		`{"type":"user","isMeta":true,"message":{"role":"user","content":"Caveat: ignore"}}`,
+		`{"type":"user","message":{"role":"user","content":"Fix the flaky storage test\nIt fails on CI"}}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Let me look."},{"type":"tool_use","name":"TodoWrite","input":{"todos":[` +
+			`{"content":"Find the flake","status":"completed","activeForm":"Finding the flake"},` +
+			`{"content":"Fix the race","status":"in_progress","activeForm":"Fixing the race"},` +
+			`{"content":"Run the tests","status":"pending","activeForm":"Running the tests"}]}}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"1","content":"ok"}]}}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","name":"Edit","input":{"file_path":"/repo/session/storage.go"}}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"2","is_error":true,"content":"old_string not found"}]}}`,
+		`not json`,
+	}
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := ReadTranscriptSummary(path)
+	if err != nil {
+		t.Fatalf("ReadTranscriptSummary() error = %v", err)
+	}
+	if summary.LastUserMessage != "Fix the flaky storage test" {
+		t.Errorf("LastUserMessage = %q", summary.LastUserMessage)
+	}
+	if summary.LastAction != "Edit storage.go" {
+		t.Errorf("LastAction = %q, want %q", summary.LastAction, "Edit storage.go")
+	}
+	if len(summary.Todos) != 3 {
+		t.Errorf("Todos = %+v, want 3 items", summary.Todos)
+	}
+	if summary.ToolErrors != 1 {
+		t.Errorf("ToolErrors = %d, want 1", summary.ToolErrors)
+	}
+	if got, want := summary.String(), "Fixing the race - todos 1/3 - 1 tool error(s)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestTranscriptSummaryString(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary TranscriptSummary
+		want    string
+	}{
+		{"empty", TranscriptSummary{}, "No activity yet"},
+		{"last action", TranscriptSummary{LastAction: "Bash go test ./..."}, "Bash go test ./..."},
+		{"awaiting reply", TranscriptSummary{LastUserMessage: "Add a flag", LastAction: "Done.", awaitingReply: true}, "Asked: Add a flag"},
+		{"too long", TranscriptSummary{LastAction: strings.Repeat("x", 100)}, strings.Repeat("x", SummaryMaxLength-3) + "..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.summary.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package zellij
+
+import (
+	"sort"
+	"strings"
+)
+
+// EnvNames returns the names of env sorted, so the variables are always set in
+// the same order.
+func EnvNames(env map[string]string) []string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ShellExports returns the sh commands exporting env, to put in front of a
+// command line. It is empty when env is.
+func ShellExports(env map[string]string) string {
+	var b strings.Builder
+	for _, name := range EnvNames(env) {
+		b.WriteString("export " + name + "=" + ShellQuote(env[name]) + "; ")
+	}
+	return b.String()
+}
+
+// ShellQuote quotes s for sh so it is taken literally.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// kdlEscape escapes s for use inside a quoted KDL string.
+func kdlEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// SetEnv sets the environment variables the program is started with.
+func (z *ZellijSession) SetEnv(env map[string]string) {
+	z.env = env
+}
+
+// command returns the command line starting the program with its environment.
+func (z *ZellijSession) command() string {
+	return ShellExports(z.env) + z.program
+}
@@ -0,0 +1,23 @@
+package zellij
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellExports(t *testing.T) {
+	assert.Equal(t, "", ShellExports(nil))
+	assert.Equal(t, `export A='1'; export B='it'\''s "on"'; `, ShellExports(map[string]string{
+		"B": `it's "on"`,
+		"A": "1",
+	}))
+}
+
+func TestCommandEscapesForLayout(t *testing.T) {
+	z := NewZellijSession("test", "claude")
+	z.SetEnv(map[string]string{"API_URL": `http://localhost\api`})
+	assert.Equal(t, `export API_URL='http://localhost\api'; claude`, z.command())
+	assert.Equal(t, `export API_URL='http://localhost\\api'; claude`, kdlEscape(z.command()))
+	assert.Equal(t, `sh -c \"echo hi\"`, kdlEscape(`sh -c "echo hi"`))
+}
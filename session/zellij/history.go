@@ -0,0 +1,88 @@
+package zellij
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// CaptureHistoryTail captures the end of the pane's scroll history: at most
+// maxLines lines and maxBytes bytes, where 0 means no limit. Only the tail of
+// the dump is read, so very long histories don't have to be loaded. truncated
+// reports whether earlier history was left out.
+func (z *ZellijSession) CaptureHistoryTail(maxLines, maxBytes int) (content string, truncated bool, err error) {
+	tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("zellij_capture_%s_%d.txt", z.sanitizedName, time.Now().UnixNano()))
+	defer os.Remove(tmpFile)
+
+	cmd := exec.Command("zellij", "-s", z.sanitizedName, "action", "dump-screen", "--full", tmpFile)
+	if err := z.cmdExec.Run(cmd); err != nil {
+		return "", false, fmt.Errorf("error capturing pane content: %w", err)
+	}
+
+	data, err := readCaptureWithRetry(tmpFile, func(path string) ([]byte, error) {
+		var tail []byte
+		var err error
+		tail, truncated, err = readTail(path, maxLines, maxBytes)
+		return tail, err
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("error reading capture file for session %s: %w", z.sanitizedName, err)
+	}
+	return string(data), truncated, nil
+}
+
+// readTail reads the last maxLines lines of a file, reading at most its last
+// maxBytes bytes. A line cut by the byte limit is dropped.
+func readTail(path string, maxLines, maxBytes int) ([]byte, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+
+	offset := int64(0)
+	if maxBytes > 0 && info.Size() > int64(maxBytes) {
+		offset = info.Size() - int64(maxBytes)
+	}
+	data := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(data, offset); err != nil && err != io.EOF {
+		return nil, false, err
+	}
+
+	truncated := offset > 0
+	if truncated {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	data, cut := lastLinesOf(data, maxLines)
+	return data, truncated || cut, nil
+}
+
+// lastLinesOf returns the last n lines of data, or all of it if n is 0. cut
+// reports whether lines were dropped.
+func lastLinesOf(data []byte, n int) (tail []byte, cut bool) {
+	if n <= 0 {
+		return data, false
+	}
+	// A trailing newline ends the last line rather than starting a new one
+	end := len(bytes.TrimRight(data, "\n"))
+	for i := end - 1; i >= 0; i-- {
+		if data[i] == '\n' {
+			n--
+			if n == 0 {
+				return data[i+1:], true
+			}
+		}
+	}
+	return data, false
+}
@@ -0,0 +1,53 @@
+package zellij
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastLinesOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		n       int
+		want    string
+		wantCut bool
+	}{
+		{"no limit", "a\nb\nc\n", 0, "a\nb\nc\n", false},
+		{"fewer lines than limit", "a\nb\n", 5, "a\nb\n", false},
+		{"exact number of lines", "a\nb\n", 2, "a\nb\n", false},
+		{"cut", "a\nb\nc\n", 2, "b\nc\n", true},
+		{"without trailing newline", "a\nb\nc", 1, "c", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cut := lastLinesOf([]byte(tt.data), tt.n)
+			require.Equal(t, tt.want, string(got))
+			require.Equal(t, tt.wantCut, cut)
+		})
+	}
+}
+
+func TestReadTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.txt")
+	require.NoError(t, os.WriteFile(path, []byte("first line\nsecond\nthird\n"), 0644))
+
+	data, truncated, err := readTail(path, 0, 0)
+	require.NoError(t, err)
+	require.False(t, truncated)
+	require.Equal(t, "first line\nsecond\nthird\n", string(data))
+
+	// The line cut by the byte limit is dropped
+	data, truncated, err = readTail(path, 0, 16)
+	require.NoError(t, err)
+	require.True(t, truncated)
+	require.Equal(t, "second\nthird\n", string(data))
+
+	data, truncated, err = readTail(path, 1, 16)
+	require.NoError(t, err)
+	require.True(t, truncated)
+	require.Equal(t, "third\n", string(data))
+}
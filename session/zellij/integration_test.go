@@ -0,0 +1,99 @@
+//go:build integration
+
+package zellij
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Integration tests run real Zellij sessions. Run them with:
+//
+//	go test -tags integration ./session/...
+
+// integrationTimeout bounds how long an integration test waits for a session to react.
+const integrationTimeout = 10 * time.Second
+
+// requireZellij skips the test when zellij is not installed.
+func requireZellij(t *testing.T) {
+	t.Helper()
+	if !IsAvailable() {
+		t.Skip("zellij not available")
+	}
+}
+
+// newIntegrationSession starts a shell in a real Zellij session in a throwaway
+// git repository. The session is killed when the test ends.
+func newIntegrationSession(t *testing.T) *ZellijSession {
+	t.Helper()
+	requireZellij(t)
+
+	repoPath := t.TempDir()
+	require.NoError(t, exec.Command("git", "-C", repoPath, "init", "-q").Run())
+
+	name := fmt.Sprintf("integration-%d", time.Now().UnixNano())
+	session := NewZellijSession(name, "sh")
+	require.NoError(t, session.Start(repoPath))
+	t.Cleanup(func() {
+		if session.DoesSessionExist() {
+			_ = session.Close()
+		}
+	})
+	return session
+}
+
+// waitForContent waits until the session's pane shows the text.
+func waitForContent(t *testing.T, session *ZellijSession, text string) string {
+	t.Helper()
+	var content string
+	require.Eventually(t, func() bool {
+		var err error
+		content, err = session.CapturePaneContent()
+		return err == nil && strings.Contains(content, text)
+	}, integrationTimeout, 100*time.Millisecond, "pane never showed %q, last content:\n%s", text, content)
+	return content
+}
+
+func TestIntegrationStartCaptureAndClose(t *testing.T) {
+	session := newIntegrationSession(t)
+	require.True(t, session.DoesSessionExist())
+
+	require.NoError(t, session.SendKeys("echo integration-$((20 + 22))"))
+	require.NoError(t, session.TapEnter())
+	waitForContent(t, session, "integration-42")
+
+	history, err := session.CapturePaneContentWithOptions("-", "-")
+	require.NoError(t, err)
+	require.Contains(t, history, "integration-42")
+
+	require.NoError(t, session.Close())
+	require.Eventually(t, func() bool { return !session.DoesSessionExist() },
+		integrationTimeout, 100*time.Millisecond, "session still exists after Close")
+}
+
+func TestIntegrationDetachAndRestore(t *testing.T) {
+	session := newIntegrationSession(t)
+
+	require.NoError(t, session.SendKeys("echo before-detach"))
+	require.NoError(t, session.TapEnter())
+	waitForContent(t, session, "before-detach")
+
+	// Detaching keeps the session and its output alive
+	require.NoError(t, session.DetachSafely())
+	require.True(t, session.DoesSessionExist())
+
+	// A new process restores the session by name, as after a restart
+	restored := NewZellijSession(strings.TrimPrefix(session.sanitizedName, ZellijPrefix), "sh")
+	require.NoError(t, restored.Restore())
+	t.Cleanup(func() { restored.stopPTYReader() })
+	waitForContent(t, restored, "before-detach")
+
+	require.NoError(t, restored.SendKeys("echo after-restore"))
+	require.NoError(t, restored.TapEnter())
+	waitForContent(t, restored, "after-restore")
+}
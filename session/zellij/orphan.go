@@ -42,7 +42,8 @@ func ListOrphanedSessions(trackedTitles []string, cmdExec cmd.Executor) ([]Orpha
 		trackedSet[title] = true
 	}
 
-	var orphans []OrphanedSession
+	var names []string
+	running := make(map[string]bool)
 	lines := strings.Split(cleanOutput, "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -66,15 +67,29 @@ func ListOrphanedSessions(trackedTitles []string, cmdExec cmd.Executor) ([]Orpha
 		if !strings.HasPrefix(sessionName, ZellijPrefix) {
 			continue
 		}
+		names = append(names, sessionName)
+		running[sessionName] = true
+	}
 
-		// Extract title by removing prefix
-		title := strings.TrimPrefix(sessionName, ZellijPrefix)
+	var orphans []OrphanedSession
+	for _, sessionName := range names {
+		// A session running the pane of another session is left to that
+		// session, unless it is a leftover of one that's gone
+		owner := sessionName
+		if isPaneSession(sessionName) {
+			owner = sessionName[:strings.Index(sessionName, paneSeparator)]
+			if running[owner] {
+				continue
+			}
+		}
 
-		// Skip if this session is already tracked
-		if trackedSet[title] {
+		// Skip if this session, or the session of its pane, is already tracked
+		if trackedSet[strings.TrimPrefix(owner, ZellijPrefix)] {
 			continue
 		}
 
+		// Extract title by removing prefix
+		title := strings.TrimPrefix(sessionName, ZellijPrefix)
 		orphans = append(orphans, OrphanedSession{
 			SessionName: sessionName,
 			Title:       title,
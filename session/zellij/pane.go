@@ -0,0 +1,164 @@
+package zellij
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// paneSeparator separates the name of a session from the name of one of its
+// panes in the name of the zellij session running the pane.
+const paneSeparator = "__pane_"
+
+// isPaneSession returns true if the zellij session runs a pane of another
+// session rather than a program.
+func isPaneSession(sessionName string) bool {
+	return strings.Contains(sessionName, paneSeparator)
+}
+
+// paneSession returns the zellij session running the named pane. Panes run in
+// sessions of their own, since zellij can only capture the focused pane of a
+// session.
+func (z *ZellijSession) paneSession(name string) *ZellijSession {
+	z.panesMu.Lock()
+	defer z.panesMu.Unlock()
+	if pane, ok := z.panes[name]; ok {
+		return pane
+	}
+	pane := newZellijSession("", "", z.cmdExec)
+	pane.sanitizedName = z.sanitizedName + paneSeparator + name
+	if z.panes == nil {
+		z.panes = make(map[string]*ZellijSession)
+	}
+	z.panes[name] = pane
+	return pane
+}
+
+// exitFile returns the file the exit code of the pane's command is written to.
+func (z *ZellijSession) exitFile() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("zellij_pane_%s.exit", z.sanitizedName))
+}
+
+// StartPane runs the command in workDir in a zellij session of its own, which
+// survives restarts of claude-squad like the program's. A pane whose command
+// still runs is attached to again, and one whose command exited is replaced.
+func (z *ZellijSession) StartPane(name, command, workDir string) error {
+	pane := z.paneSession(name)
+	exitFile := pane.exitFile()
+	if pane.DoesSessionExist() {
+		if _, err := os.Stat(exitFile); err != nil {
+			if pane.ptmx != nil {
+				return nil
+			}
+			return pane.Restore()
+		}
+		if err := pane.Close(); err != nil {
+			return err
+		}
+	}
+
+	_ = os.Remove(exitFile)
+	pane.program = command + "; echo $? > " + ShellQuote(exitFile)
+	pane.env = z.env
+	if height, width := z.termBuffer.GetSize(); height > 0 && width > 0 {
+		pane.termBuffer.Resize(height, width)
+	}
+	return pane.Start(workDir)
+}
+
+// CapturePane captures the current visible content of the named pane.
+func (z *ZellijSession) CapturePane(name string) (string, error) {
+	z.panesMu.Lock()
+	pane := z.panes[name]
+	z.panesMu.Unlock()
+	if pane == nil {
+		return "", fmt.Errorf("zellij session %s has no pane %s", z.sanitizedName, name)
+	}
+	return pane.CapturePaneContent()
+}
+
+// PaneRunning returns true while the command of the named pane runs, which
+// ends with writing its exit code.
+func (z *ZellijSession) PaneRunning(name string) bool {
+	z.panesMu.Lock()
+	pane := z.panes[name]
+	z.panesMu.Unlock()
+	if pane == nil || pane.ptmx == nil {
+		return false
+	}
+	_, err := os.Stat(pane.exitFile())
+	return os.IsNotExist(err)
+}
+
+// ClosePane kills the session of the named pane.
+func (z *ZellijSession) ClosePane(name string) error {
+	pane := z.paneSession(name)
+	z.panesMu.Lock()
+	delete(z.panes, name)
+	z.panesMu.Unlock()
+
+	_ = os.Remove(pane.exitFile())
+	if !pane.DoesSessionExist() {
+		pane.stopPTYReader()
+		return nil
+	}
+	return pane.Close()
+}
+
+// closePanes kills the sessions of all panes, including those started before
+// claude-squad restarted, which are found by their names.
+func (z *ZellijSession) closePanes() error {
+	for _, name := range z.listPaneSessions() {
+		z.paneSession(name)
+	}
+
+	z.panesMu.Lock()
+	names := make([]string, 0, len(z.panes))
+	for name := range z.panes {
+		names = append(names, name)
+	}
+	z.panesMu.Unlock()
+
+	var errs []error
+	for _, name := range names {
+		if err := z.ClosePane(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("error closing panes: %v", errs)
+	}
+	return nil
+}
+
+// listPaneSessions returns the names of the panes whose zellij sessions run.
+func (z *ZellijSession) listPaneSessions() []string {
+	output, err := z.cmdExec.Output(exec.Command("zellij", "list-sessions"))
+	if err != nil {
+		return nil
+	}
+
+	// Strip ANSI escape codes from output (zellij uses colors in list-sessions)
+	cleanOutput := ansiEscapeRegex.ReplaceAllString(string(output), "")
+
+	prefix := z.sanitizedName + paneSeparator
+	var names []string
+	for _, session := range strings.Split(cleanOutput, "\n") {
+		name := strings.Fields(session)
+		if len(name) > 0 && strings.HasPrefix(name[0], prefix) {
+			names = append(names, strings.TrimPrefix(name[0], prefix))
+		}
+	}
+	return names
+}
+
+// resizePanes sizes the sessions of the panes like the program's.
+func (z *ZellijSession) resizePanes(width, height int) {
+	z.panesMu.Lock()
+	defer z.panesMu.Unlock()
+	for _, pane := range z.panes {
+		_ = pane.SetDetachedSize(width, height)
+	}
+}
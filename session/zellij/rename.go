@@ -0,0 +1,23 @@
+package zellij
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// Rename renames the Zellij session to match a new name. If the session isn't
+// running, only the name it will be started or restored with changes.
+func (z *ZellijSession) Rename(name string) error {
+	sanitizedName := toClaudeSquadZellijName(name)
+	if sanitizedName == z.sanitizedName {
+		return nil
+	}
+	if z.DoesSessionExist() {
+		cmd := exec.Command("zellij", "-s", z.sanitizedName, "action", "rename-session", sanitizedName)
+		if err := z.cmdExec.Run(cmd); err != nil {
+			return fmt.Errorf("error renaming zellij session: %w", err)
+		}
+	}
+	z.sanitizedName = sanitizedName
+	return nil
+}
@@ -0,0 +1,306 @@
+package zellij
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/tonistiigi/vt100"
+)
+
+// maxStyles is the number of distinct styles a buffer keeps before it forgets
+// the ones that are no longer on the screen.
+const maxStyles = 4096
+
+// colorKind is how the color of a cell was given.
+type colorKind uint8
+
+const (
+	colorDefault colorKind = iota
+	// colorIndexed is one of the 256 palette colors, 0-15 being the basic and
+	// bright ones
+	colorIndexed
+	// colorRGB is a 24-bit true color
+	colorRGB
+)
+
+// termColor is a foreground or background color as the program wrote it, so
+// it's rendered the same way rather than mapped to another palette.
+type termColor struct {
+	kind    colorKind
+	index   uint8
+	r, g, b uint8
+}
+
+// cellStyle holds the SGR attributes of a cell.
+type cellStyle struct {
+	fg, bg termColor
+
+	bold, dim, italic, underline, blink, inverse, conceal, strike bool
+}
+
+// vt100 only knows the 8 basic colors and a few attributes, so the buffer
+// handles SGR sequences itself. Each distinct style gets an ID that is stored
+// in the format of the cells vt100 writes, which only serves as a key. The
+// zero format is the default style.
+
+// formatOf returns the format that stands for the style, adding the style to
+// the table of the buffer. Must be called with mu held.
+func (tb *TerminalBuffer) formatOf(style cellStyle) vt100.Format {
+	if style == (cellStyle{}) {
+		return vt100.Format{}
+	}
+	id, ok := tb.styleIDs[style]
+	if !ok {
+		if len(tb.styleIDs) >= maxStyles {
+			tb.compactStyles()
+		}
+		if n := len(tb.freeStyleIDs); n > 0 {
+			id = tb.freeStyleIDs[n-1]
+			tb.freeStyleIDs = tb.freeStyleIDs[:n-1]
+			tb.styles[id] = style
+		} else {
+			id = uint32(len(tb.styles))
+			tb.styles = append(tb.styles, style)
+		}
+		tb.styleIDs[style] = id
+	}
+	return styleFormat(id)
+}
+
+// styleFormat returns the format that stands for the style ID.
+func styleFormat(id uint32) vt100.Format {
+	return vt100.Format{Fg: color.RGBA{R: uint8(id >> 16), G: uint8(id >> 8), B: uint8(id), A: 255}}
+}
+
+// styleOf returns the style of a format returned by formatOf.
+// Must be called with mu held.
+func (tb *TerminalBuffer) styleOf(f vt100.Format) cellStyle {
+	if f.Fg.A == 0 {
+		return cellStyle{}
+	}
+	id := uint32(f.Fg.R)<<16 | uint32(f.Fg.G)<<8 | uint32(f.Fg.B)
+	if id == 0 || int(id) >= len(tb.styles) {
+		return cellStyle{}
+	}
+	return tb.styles[id]
+}
+
+// compactStyles forgets the styles that no cell and the cursor use, so their
+// IDs can be used again. A cursor saved with ESC 7 can't be seen from here; it
+// may come back with another style after thousands of distinct styles.
+// Must be called with mu held.
+func (tb *TerminalBuffer) compactStyles() {
+	used := map[vt100.Format]bool{tb.vt.Cursor.F: true}
+	for _, row := range tb.vt.Format {
+		for _, f := range row {
+			used[f] = true
+		}
+	}
+	for style, id := range tb.styleIDs {
+		if !used[styleFormat(id)] {
+			delete(tb.styleIDs, style)
+			tb.freeStyleIDs = append(tb.freeStyleIDs, id)
+		}
+	}
+}
+
+// resetStyles forgets all styles. Must be called with mu held.
+func (tb *TerminalBuffer) resetStyles() {
+	tb.styles = []cellStyle{{}}
+	tb.styleIDs = make(map[cellStyle]uint32)
+	tb.freeStyleIDs = nil
+}
+
+// setGraphicRendition applies the parameters of an SGR sequence to the style
+// of the cursor. Must be called with mu held.
+func (tb *TerminalBuffer) setGraphicRendition(params string) {
+	style := tb.styleOf(tb.vt.Cursor.F)
+	style.apply(params)
+	tb.vt.Cursor.F = tb.formatOf(style)
+}
+
+// apply applies the parameters of an SGR sequence, separated by semicolons.
+// Extended colors may be given either as 38;2;r;g;b or as 38:2::r:g:b.
+// Parameters that aren't understood are skipped.
+func (s *cellStyle) apply(params string) {
+	if params == "" {
+		*s = cellStyle{}
+		return
+	}
+	fields := strings.Split(params, ";")
+	for i := 0; i < len(fields); i++ {
+		sub := strings.Split(fields[i], ":")
+		code, ok := sgrNumber(sub[0])
+		if !ok {
+			continue
+		}
+		switch {
+		case code == 0:
+			*s = cellStyle{}
+		case code == 1:
+			s.bold = true
+		case code == 2:
+			s.dim = true
+		case code == 3:
+			s.italic = true
+		case code == 4:
+			// 4:0 turns underlining off, 4:1 to 4:5 are its kinds
+			s.underline = len(sub) < 2 || sub[1] != "0"
+		case code == 5 || code == 6:
+			s.blink = true
+		case code == 7:
+			s.inverse = true
+		case code == 8:
+			s.conceal = true
+		case code == 9:
+			s.strike = true
+		case code == 21:
+			s.underline = true
+		case code == 22:
+			s.bold, s.dim = false, false
+		case code == 23:
+			s.italic = false
+		case code == 24:
+			s.underline = false
+		case code == 25:
+			s.blink = false
+		case code == 27:
+			s.inverse = false
+		case code == 28:
+			s.conceal = false
+		case code == 29:
+			s.strike = false
+		case code >= 30 && code <= 37:
+			s.fg = termColor{kind: colorIndexed, index: uint8(code - 30)}
+		case code == 39:
+			s.fg = termColor{}
+		case code >= 40 && code <= 47:
+			s.bg = termColor{kind: colorIndexed, index: uint8(code - 40)}
+		case code == 49:
+			s.bg = termColor{}
+		case code >= 90 && code <= 97:
+			s.fg = termColor{kind: colorIndexed, index: uint8(code - 90 + 8)}
+		case code >= 100 && code <= 107:
+			s.bg = termColor{kind: colorIndexed, index: uint8(code - 100 + 8)}
+		case code == 38 || code == 48 || code == 58:
+			var c termColor
+			var ok bool
+			if len(sub) > 1 {
+				c, ok = extendedColor(sub[1:], true)
+			} else {
+				var n int
+				c, n, ok = extendedColorFields(fields[i+1:])
+				i += n
+			}
+			// 58 is the color of the underline, which isn't kept
+			if ok && code == 38 {
+				s.fg = c
+			} else if ok && code == 48 {
+				s.bg = c
+			}
+		}
+	}
+}
+
+// extendedColorFields reads the color following a 38, 48 or 58 parameter in
+// the semicolon form and returns how many fields it used.
+func extendedColorFields(fields []string) (termColor, int, bool) {
+	if len(fields) == 0 {
+		return termColor{}, 0, false
+	}
+	n := 0
+	switch fields[0] {
+	case "5":
+		n = 2
+	case "2":
+		n = 4
+	default:
+		return termColor{}, 1, false
+	}
+	if len(fields) < n {
+		return termColor{}, len(fields), false
+	}
+	c, ok := extendedColor(fields[:n], false)
+	return c, n, ok
+}
+
+// extendedColor parses 5;n or 2;r;g;b. The colon form of a true color may
+// have a color space ID before the components, which is usually left empty.
+func extendedColor(args []string, colon bool) (termColor, bool) {
+	switch {
+	case len(args) >= 2 && args[0] == "5":
+		index, ok := sgrNumber(args[1])
+		if !ok || index > 255 {
+			return termColor{}, false
+		}
+		return termColor{kind: colorIndexed, index: uint8(index)}, true
+	case len(args) >= 4 && args[0] == "2":
+		components := args[1:4]
+		if colon && len(args) >= 5 {
+			components = args[2:5]
+		}
+		var rgb [3]uint8
+		for i, component := range components {
+			value, ok := sgrNumber(component)
+			if !ok || value > 255 {
+				return termColor{}, false
+			}
+			rgb[i] = uint8(value)
+		}
+		return termColor{kind: colorRGB, r: rgb[0], g: rgb[1], b: rgb[2]}, true
+	}
+	return termColor{}, false
+}
+
+// sgrNumber parses an SGR parameter. An empty parameter is 0.
+func sgrNumber(param string) (int, bool) {
+	if param == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(param)
+	return n, err == nil && n >= 0
+}
+
+// sgr returns the SGR sequence that sets the style from scratch.
+func (s cellStyle) sgr() string {
+	codes := []string{"0"}
+	for _, attr := range []struct {
+		on   bool
+		code string
+	}{
+		{s.bold, "1"}, {s.dim, "2"}, {s.italic, "3"}, {s.underline, "4"},
+		{s.blink, "5"}, {s.inverse, "7"}, {s.conceal, "8"}, {s.strike, "9"},
+	} {
+		if attr.on {
+			codes = append(codes, attr.code)
+		}
+	}
+	if fg := s.fg.sgr(true); fg != "" {
+		codes = append(codes, fg)
+	}
+	if bg := s.bg.sgr(false); bg != "" {
+		codes = append(codes, bg)
+	}
+	return fmt.Sprintf("\x1b[%sm", strings.Join(codes, ";"))
+}
+
+// sgr returns the SGR parameters of the color, in the form it was written in.
+func (c termColor) sgr(foreground bool) string {
+	base, bright, extended := 30, 90, "38"
+	if !foreground {
+		base, bright, extended = 40, 100, "48"
+	}
+	switch {
+	case c.kind == colorIndexed && c.index < 8:
+		return strconv.Itoa(base + int(c.index))
+	case c.kind == colorIndexed && c.index < 16:
+		return strconv.Itoa(bright + int(c.index) - 8)
+	case c.kind == colorIndexed:
+		return fmt.Sprintf("%s;5;%d", extended, c.index)
+	case c.kind == colorRGB:
+		return fmt.Sprintf("%s;2;%d;%d;%d", extended, c.r, c.g, c.b)
+	}
+	return ""
+}
@@ -1,8 +1,8 @@
 package zellij
 
 import (
+	"bytes"
 	"fmt"
-	"image/color"
 	"regexp"
 	"strings"
 	"sync"
@@ -20,6 +20,10 @@ const (
 // Format: ESC ] 8 ; params ; URI ST (where ST is ESC \ or BEL)
 var oscSequenceRegex = regexp.MustCompile(`\x1b\]8;[^;]*;[^\x1b\x07]*(?:\x1b\\|\x07)`)
 
+// maxPendingSequence is the length up to which an escape sequence cut off at the
+// end of a write is kept for the next one.
+const maxPendingSequence = 64
+
 // TerminalBuffer wraps a VT100 terminal emulator to capture PTY output with colors.
 // It maintains a cached render of the screen content with ANSI escape codes.
 type TerminalBuffer struct {
@@ -29,6 +33,15 @@ type TerminalBuffer struct {
 	width  int
 	height int
 
+	// Styles of the cells, see formatOf
+	styles       []cellStyle
+	styleIDs     map[cellStyle]uint32
+	freeStyleIDs []uint32
+	// Zero-width runes of the cells, see writeText
+	combining map[cellPos]combiningRunes
+	// Start of an escape sequence that the last write cut off
+	pending []byte
+
 	// Cached render output
 	cachedRender string
 	dirty        bool
@@ -46,14 +59,17 @@ func NewTerminalBuffer() *TerminalBuffer {
 
 // NewTerminalBufferWithSize creates a new terminal buffer with specified dimensions.
 func NewTerminalBufferWithSize(height, width int) *TerminalBuffer {
-	return &TerminalBuffer{
-		vt:     vt100.NewVT100(height, width),
-		width:  width,
-		height: height,
-		dirty:  true,
-		stopCh: make(chan struct{}),
-		doneCh: make(chan struct{}),
+	tb := &TerminalBuffer{
+		vt:        vt100.NewVT100(height, width),
+		width:     width,
+		height:    height,
+		dirty:     true,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+		combining: make(map[cellPos]combiningRunes),
 	}
+	tb.resetStyles()
+	return tb
 }
 
 // Write feeds data to the terminal emulator.
@@ -65,12 +81,64 @@ func (tb *TerminalBuffer) Write(p []byte) (n int, err error) {
 	// Strip OSC 8 hyperlink sequences that vt100 doesn't handle
 	cleaned := oscSequenceRegex.ReplaceAll(p, nil)
 
-	_, err = tb.vt.Write(cleaned)
 	if len(cleaned) > 0 {
+		tb.feed(cleaned)
 		tb.dirty = true
 	}
 	// Return original length so callers don't see unexpected write lengths
-	return len(p), err
+	return len(p), nil
+}
+
+// feed passes the data to vt100, except for SGR sequences, which set the
+// style of the cursor. Must be called with mu held.
+func (tb *TerminalBuffer) feed(data []byte) {
+	if len(tb.pending) > 0 {
+		data = append(tb.pending, data...)
+		tb.pending = nil
+	}
+	for len(data) > 0 {
+		start := bytes.Index(data, []byte("\x1b["))
+		if start < 0 {
+			// A trailing escape may start a sequence in the next write
+			if data[len(data)-1] == '\x1b' {
+				tb.pending = []byte{'\x1b'}
+				data = data[:len(data)-1]
+			}
+			tb.writeText(data)
+			return
+		}
+		if start > 0 {
+			tb.writeText(data[:start])
+		}
+
+		// Parameter and intermediate bytes, then the final byte
+		end := start + 2
+		for end < len(data) && data[end] >= 0x20 && data[end] <= 0x3f {
+			end++
+		}
+		switch {
+		case end == len(data):
+			if end-start <= maxPendingSequence {
+				tb.pending = append([]byte(nil), data[start:]...)
+			} else {
+				_, _ = tb.vt.Write(data[start:])
+			}
+			return
+		case data[end] < 0x40 || data[end] > 0x7e:
+			// Not a valid sequence, it's dropped like terminals do
+			data = data[end:]
+		case data[end] == 'm':
+			params := string(data[start+2 : end])
+			// Private SGR sequences like ESC [ > 4 m set keyboard modes
+			if params == "" || params[0] < 0x3c {
+				tb.setGraphicRendition(params)
+			}
+			data = data[end+1:]
+		default:
+			_, _ = tb.vt.Write(data[start : end+1])
+			data = data[end+1:]
+		}
+	}
 }
 
 // Resize changes the terminal dimensions.
@@ -108,7 +176,7 @@ func (tb *TerminalBuffer) renderToANSI() string {
 	var sb strings.Builder
 	sb.Grow(tb.width * tb.height * 2) // Rough estimate
 
-	var prevFormat vt100.Format
+	var prevStyle cellStyle
 	firstCell := true
 
 	for y := 0; y < tb.height; y++ {
@@ -126,25 +194,20 @@ func (tb *TerminalBuffer) renderToANSI() string {
 		}
 
 		for x := 0; x <= lastNonSpace || x == 0; x++ {
-			char := tb.vt.Content[y][x]
-			format := tb.vt.Format[y][x]
-
-			// Emit ANSI codes if format changed
-			if firstCell || !formatsEqual(format, prevFormat) {
-				ansi := formatToANSI(format)
-				if ansi != "" {
-					sb.WriteString(ansi)
-				}
-				prevFormat = format
-				firstCell = false
+			text := tb.cellText(y, x)
+			if text == "" {
+				continue
 			}
+			style := tb.styleOf(tb.vt.Format[y][x])
 
-			// Write the character
-			if char == 0 {
-				sb.WriteRune(' ')
-			} else {
-				sb.WriteRune(char)
+			// Emit ANSI codes if the style changed
+			if firstCell || style != prevStyle {
+				sb.WriteString(style.sgr())
+				prevStyle = style
+				firstCell = false
 			}
+
+			sb.WriteString(text)
 		}
 	}
 
@@ -154,87 +217,36 @@ func (tb *TerminalBuffer) renderToANSI() string {
 	return sb.String()
 }
 
-// formatsEqual compares two Format structs for equality.
-func formatsEqual(a, b vt100.Format) bool {
-	return a.Fg == b.Fg &&
-		a.Bg == b.Bg &&
-		a.Intensity == b.Intensity &&
-		a.Underscore == b.Underscore &&
-		a.Conceal == b.Conceal &&
-		a.Negative == b.Negative &&
-		a.Blink == b.Blink &&
-		a.Inverse == b.Inverse
-}
-
-// formatToANSI converts a Format to ANSI escape sequence.
-func formatToANSI(f vt100.Format) string {
-	var codes []string
-
-	// Reset first
-	codes = append(codes, "0")
-
-	// Intensity
-	switch f.Intensity {
-	case vt100.Bright:
-		codes = append(codes, "1")
-	case vt100.Dim:
-		codes = append(codes, "2")
-	}
-
-	// Text attributes
-	if f.Underscore {
-		codes = append(codes, "4")
-	}
-	if f.Blink {
-		codes = append(codes, "5")
-	}
-	if f.Inverse {
-		codes = append(codes, "7")
-	}
-	if f.Conceal {
-		codes = append(codes, "8")
-	}
-
-	// Foreground color
-	if fg := colorToANSI(f.Fg, true); fg != "" {
-		codes = append(codes, fg)
-	}
-
-	// Background color
-	if bg := colorToANSI(f.Bg, false); bg != "" {
-		codes = append(codes, bg)
-	}
-
-	if len(codes) == 1 && codes[0] == "0" {
-		// Just reset, which is the default
-		return "\x1b[0m"
-	}
-
-	return fmt.Sprintf("\x1b[%sm", strings.Join(codes, ";"))
-}
-
-// colorToANSI converts a color.RGBA to ANSI color code.
-// foreground: true for foreground colors (30-37, 90-97), false for background (40-47, 100-107)
-func colorToANSI(c color.RGBA, foreground bool) string {
-	// Check if it's the default/zero color
-	if c.R == 0 && c.G == 0 && c.B == 0 && c.A == 0 {
-		return ""
-	}
+// Reset clears the terminal buffer and creates a fresh VT100 instance.
+func (tb *TerminalBuffer) Reset() {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
 
-	// Use 24-bit true color for accurate color representation
-	// Modern terminals support this and it avoids incorrect color mapping
-	if foreground {
-		return fmt.Sprintf("38;2;%d;%d;%d", c.R, c.G, c.B)
-	}
-	return fmt.Sprintf("48;2;%d;%d;%d", c.R, c.G, c.B)
+	tb.vt = vt100.NewVT100(tb.height, tb.width)
+	tb.resetStyles()
+	tb.combining = make(map[cellPos]combiningRunes)
+	tb.pending = nil
+	tb.cachedRender = ""
+	tb.dirty = true
 }
 
-// Reset clears the terminal buffer and creates a fresh VT100 instance.
-func (tb *TerminalBuffer) Reset() {
+// Load replaces the screen with a screen returned by Render, e.g. to show the
+// last screen of a hibernated session until it draws again.
+func (tb *TerminalBuffer) Load(screen string) {
 	tb.mu.Lock()
 	defer tb.mu.Unlock()
 
 	tb.vt = vt100.NewVT100(tb.height, tb.width)
+	tb.resetStyles()
+	tb.combining = make(map[cellPos]combiningRunes)
+	tb.pending = nil
+	// Move to the start of each row rather than writing newlines, which would
+	// scroll after a full-width row
+	var sb strings.Builder
+	for y, row := range strings.Split(screen, "\n") {
+		fmt.Fprintf(&sb, "\x1b[%d;1H%s", y+1, row)
+	}
+	tb.feed([]byte(sb.String()))
 	tb.cachedRender = ""
 	tb.dirty = true
 }
@@ -1,6 +1,7 @@
 package zellij
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -89,6 +90,121 @@ func TestTerminalBuffer_Reset(t *testing.T) {
 	_ = rendered1 // Use the variable
 }
 
+func TestTerminalBuffer_Load(t *testing.T) {
+	tb := NewTerminalBufferWithSize(5, 40)
+	tb.Write([]byte("first line\r\n\x1b[31msecond line\x1b[0m\r\n" + strings.Repeat("x", 40) + "\r\nlast"))
+	screen := tb.Render()
+
+	restored := NewTerminalBufferWithSize(5, 40)
+	restored.Write([]byte("stale output"))
+	restored.Load(screen)
+
+	if got := restored.Render(); got != screen {
+		t.Errorf("Render() after Load = %q, want %q", got, screen)
+	}
+}
+
+func TestTerminalBuffer_RenderKeepsStyles(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"true color", "\x1b[38;2;215;119;87mclaude\x1b[48;2;1;2;3mbg", "\x1b[0;38;2;215;119;87mclaude\x1b[0;38;2;215;119;87;48;2;1;2;3mbg"},
+		{"true color with colons", "\x1b[38:2::10:20:30mx", "\x1b[0;38;2;10;20;30mx"},
+		{"256 colors", "\x1b[38;5;208;48;5;236mx", "\x1b[0;38;5;208;48;5;236mx"},
+		{"bright colors", "\x1b[91;102mx", "\x1b[0;91;102mx"},
+		{"attributes", "\x1b[1;3;4;9mx\x1b[23;24mx", "\x1b[0;1;3;4;9mx\x1b[0;1;9mx"},
+		{"reset", "\x1b[3;31mx\x1b[my", "\x1b[0;3;31mx\x1b[0my"},
+		{"private sequence", "\x1b[>4;2mx", "\x1b[0mx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := NewTerminalBufferWithSize(1, 20)
+			tb.Write([]byte(tt.input))
+			want := tt.want + "\x1b[0m"
+			if got := tb.Render(); got != want {
+				t.Errorf("Render() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestTerminalBuffer_SequenceSplitAcrossWrites(t *testing.T) {
+	tb := NewTerminalBufferWithSize(1, 20)
+	for _, chunk := range []string{"a\x1b", "[38;2;1", ";2;3", "mb"} {
+		tb.Write([]byte(chunk))
+	}
+	if got, want := tb.Render(), "\x1b[0ma\x1b[0;38;2;1;2;3mb\x1b[0m"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestTerminalBuffer_LoadKeepsStyles(t *testing.T) {
+	tb := NewTerminalBufferWithSize(3, 20)
+	tb.Write([]byte("\x1b[3;38;2;215;119;87mitalic\r\n\x1b[0;4;38;5;120munderlined"))
+	screen := tb.Render()
+
+	restored := NewTerminalBufferWithSize(3, 20)
+	restored.Load(screen)
+	if got := restored.Render(); got != screen {
+		t.Errorf("Render() after Load = %q, want %q", got, screen)
+	}
+}
+
+func TestTerminalBuffer_ForgetsUnusedStyles(t *testing.T) {
+	tb := NewTerminalBufferWithSize(1, 20)
+	for i := 0; i < maxStyles+10; i++ {
+		tb.Write([]byte(fmt.Sprintf("\r\x1b[38;2;%d;%d;%dmx", i>>16, (i>>8)&255, i&255)))
+	}
+	tb.Write([]byte("\x1b[38;2;1;2;3my"))
+	if len(tb.styleIDs) > maxStyles {
+		t.Errorf("the buffer keeps %d styles, want at most %d", len(tb.styleIDs), maxStyles)
+	}
+	if got, want := tb.Render(), "\x1b[0;38;2;0;16;9mx\x1b[0;38;2;1;2;3my\x1b[0m"; got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestTerminalBuffer_WideRunes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		// The program moves the cursor in columns, so text after the move
+		// must stay in the column it was written to
+		{"CJK", "日本語\x1b[1;8Hx", "日本語 x"},
+		{"emoji", "🚀 done\x1b[1;9Hx", "🚀 done x"},
+		{"box drawing", "╭─╮\x1b[1;5Hx", "╭─╮ x"},
+		{"combining mark", "e\u0301t\x1b[1;3Hx", "e\u0301tx"},
+		{"wrapped at the end of the line", "abcdefghi日", "abcdefghi\n日"},
+		{"second half overwritten", "日本\x1b[1;2Hx", " x本"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := NewTerminalBufferWithSize(2, 10)
+			tb.Write([]byte(tt.input))
+			got := strings.ReplaceAll(tb.Render(), "\x1b[0m", "")
+			if got = strings.TrimRight(got, " \n"); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerminalBuffer_LoadKeepsWideRunes(t *testing.T) {
+	tb := NewTerminalBufferWithSize(2, 10)
+	tb.Write([]byte("日本\x1b[1;6Hx\r\ne\u0301🚀!"))
+	screen := tb.Render()
+
+	restored := NewTerminalBufferWithSize(2, 10)
+	restored.Load(screen)
+	if got := restored.Render(); got != screen {
+		t.Errorf("Render() after Load = %q, want %q", got, screen)
+	}
+}
+
 func TestTerminalBuffer_CachedRender(t *testing.T) {
 	tb := NewTerminalBuffer()
 
@@ -157,3 +273,32 @@ func TestTerminalBuffer_OSC8StrippingWithBEL(t *testing.T) {
 		t.Errorf("Rendered output should not contain '8;;' artifacts, got: %q", rendered)
 	}
 }
+
+func FuzzTerminalBuffer_Write(f *testing.F) {
+	f.Add([]byte("Hello World"))
+	f.Add([]byte("\x1b[31mRed\x1b[0m \x1b[1;4;38;2;10;20;30mtrue color\x1b[m"))
+	f.Add([]byte("\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\"))
+	f.Add([]byte("\x1b]8;;https://example.com\x07link\x1b]8;;\x07"))
+	f.Add([]byte("\x1b]0;title\x07\x1b[?1049h\x1b[2J\x1b[H"))
+	f.Add([]byte("\x1b[999;999H\x1b[-1A\x1b[;;;m\x1b["))
+	f.Add([]byte("\x1b]8;unterminated"))
+	f.Add([]byte("line\r\n\ttab\b\x00\xff\xfe"))
+	f.Add([]byte("日本語 🚀 e\u0301\x1b[1;79H日"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tb := NewTerminalBufferWithSize(5, 20)
+
+		n, err := tb.Write(data)
+		if err == nil && n != len(data) {
+			t.Errorf("Write returned %d, want %d", n, len(data))
+		}
+		tb.Render()
+
+		// The buffer must stay usable after malformed input
+		tb.Resize(3, 10)
+		if _, err := tb.Write([]byte("ok")); err != nil {
+			return
+		}
+		tb.Render()
+	})
+}
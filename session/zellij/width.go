@@ -0,0 +1,132 @@
+package zellij
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// maxCombiningBytes limits the zero-width runes kept for one cell.
+const maxCombiningBytes = 32
+
+// vt100 gives every rune one cell, but wide runes like CJK and most emoji take
+// up two columns in a terminal, and zero-width runes like combining marks none.
+// The program draws its screen in columns, so the buffer keeps the cells in
+// line with them: the cell after a wide rune holds a zero rune, and zero-width
+// runes are kept with the cell before them.
+
+// cellPos is the row and column of a cell.
+type cellPos struct {
+	y, x int
+}
+
+// combiningRunes are the zero-width runes written after the rune of a cell.
+// They only belong to the cell while it still holds the same rune.
+type combiningRunes struct {
+	base  rune
+	runes string
+}
+
+// writeText passes text without SGR sequences to vt100, putting wide and
+// zero-width runes in place. Must be called with mu held.
+func (tb *TerminalBuffer) writeText(text []byte) {
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRune(text[i:])
+		if r < utf8.RuneSelf || unicode.IsControl(r) || (r == utf8.RuneError && size == 1) {
+			i += size
+			continue
+		}
+		width := runewidth.RuneWidth(r)
+		if width == 1 {
+			i += size
+			continue
+		}
+		if start < i {
+			_, _ = tb.vt.Write(text[start:i])
+		}
+		if width == 2 {
+			tb.putWide(text[i : i+size])
+		} else {
+			tb.combine(r)
+		}
+		i += size
+		start = i
+	}
+	if start < len(text) {
+		_, _ = tb.vt.Write(text[start:])
+	}
+}
+
+// putWide writes a wide rune and fills the cell after it.
+// Must be called with mu held.
+func (tb *TerminalBuffer) putWide(r []byte) {
+	v := tb.vt
+	if v.Width < 2 {
+		_, _ = v.Write(r)
+		return
+	}
+	// Terminals wrap a wide rune that doesn't fit on the rest of the line
+	if v.Cursor.X == v.Width-1 {
+		_, _ = v.Write([]byte(" "))
+	}
+	_, _ = v.Write(r)
+	y, x := v.Cursor.Y, v.Cursor.X
+	if y < 0 || y >= v.Height || x < 1 || x >= v.Width {
+		return
+	}
+	v.Content[y][x] = 0
+	v.Format[y][x] = v.Cursor.F
+	v.Cursor.X++
+	if v.Cursor.X >= v.Width {
+		v.Cursor.X = 0
+		v.Cursor.Y++
+	}
+}
+
+// combine adds a zero-width rune to the cell last written.
+// Must be called with mu held.
+func (tb *TerminalBuffer) combine(r rune) {
+	v := tb.vt
+	y, x := v.Cursor.Y, v.Cursor.X-1
+	if x < 0 {
+		y, x = y-1, v.Width-1
+	}
+	if y < 0 || y >= v.Height || x < 0 || x >= v.Width {
+		return
+	}
+	if v.Content[y][x] == 0 && x > 0 {
+		x--
+	}
+	pos := cellPos{y, x}
+	combining := tb.combining[pos]
+	if combining.base != v.Content[y][x] {
+		combining = combiningRunes{base: v.Content[y][x]}
+	}
+	if len(combining.runes)+utf8.RuneLen(r) > maxCombiningBytes {
+		return
+	}
+	combining.runes += string(r)
+	tb.combining[pos] = combining
+}
+
+// cellText returns what a cell shows, which is nothing for the second half
+// of a wide rune. A wide rune whose second half was overwritten shows as a
+// space, like terminals do. Must be called with mu held.
+func (tb *TerminalBuffer) cellText(y, x int) string {
+	row := tb.vt.Content[y]
+	char := row[x]
+	switch {
+	case char == 0 && x > 0 && runewidth.RuneWidth(row[x-1]) == 2:
+		return ""
+	case char == 0:
+		return " "
+	case runewidth.RuneWidth(char) == 2 && (x+1 >= len(row) || row[x+1] != 0):
+		return " "
+	}
+	if combining, ok := tb.combining[cellPos{y, x}]; ok && combining.base == char {
+		return string(char) + combining.runes
+	}
+	return string(char)
+}
@@ -15,6 +15,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/creack/pty"
@@ -50,6 +51,8 @@ type ZellijSession struct {
 	sanitizedName string
 	program       string
 	cmdExec       cmd.Executor
+	// env holds the environment variables the program is started with
+	env map[string]string
 
 	// Initialized by Start or Restore
 	ptmx    *os.File
@@ -62,6 +65,15 @@ type ZellijSession struct {
 	termBuffer   *TerminalBuffer
 	ptyReaderCtx context.Context
 	ptyReaderCancel context.CancelFunc
+	// loadedScreen is shown by the terminal buffer when the PTY reader next starts
+	loadedScreen string
+	// disconnected is set when the PTY closed while the reader was running,
+	// e.g. because the zellij server restarted
+	disconnected atomic.Bool
+
+	// panes are the sessions running commands next to the program, by name
+	panes   map[string]*ZellijSession
+	panesMu sync.Mutex
 
 	// Initialized by Attach, deinitialized by Detach
 	attachCh chan struct{}
@@ -106,7 +118,7 @@ func (z *ZellijSession) Start(workDir string) error {
         args "-c" "%s"
     }
 }
-`, workDir, z.program)
+`, workDir, kdlEscape(z.command()))
 
 	layoutFile := filepath.Join(os.TempDir(), fmt.Sprintf("zellij_layout_%s.kdl", z.sanitizedName))
 	if err := os.WriteFile(layoutFile, []byte(layoutContent), 0644); err != nil {
@@ -240,9 +252,14 @@ func (z *ZellijSession) startPTYReader() {
 		z.ptyReaderCancel()
 	}
 
-	// Reset the terminal buffer for fresh capture
+	// Reset the terminal buffer for fresh capture, starting from a loaded screen
 	if z.termBuffer != nil {
 		z.termBuffer.Reset()
+		if z.loadedScreen != "" {
+			z.termBuffer.Load(z.loadedScreen)
+			z.loadedScreen = ""
+		}
+		z.contentCache.Invalidate()
 	}
 
 	z.ptyReaderCtx, z.ptyReaderCancel = context.WithCancel(context.Background())
@@ -262,7 +279,12 @@ func (z *ZellijSession) startPTYReader() {
 				// Read from PTY
 				n, err := ptmx.Read(buf)
 				if err != nil {
-					// PTY closed or error, stop reading
+					// PTY closed or error, stop reading. Unless the reader was
+					// stopped, the zellij client lost its server
+					if ctx.Err() == nil {
+						log.WarningLog.Printf("lost the connection to zellij session %s: %v", z.sanitizedName, err)
+						z.disconnected.Store(true)
+					}
 					return
 				}
 				if n > 0 {
@@ -283,6 +305,32 @@ func (z *ZellijSession) stopPTYReader() {
 	}
 }
 
+// Disconnected returns true if the connection to the session was lost, e.g.
+// because the zellij server restarted, and the preview no longer updates.
+func (z *ZellijSession) Disconnected() bool {
+	return z.disconnected.Load()
+}
+
+// Reconnect attaches to the session again after the connection was lost.
+func (z *ZellijSession) Reconnect() error {
+	z.stopPTYReader()
+	if z.ptmx != nil {
+		_ = z.ptmx.Close()
+		z.ptmx = nil
+	}
+	if err := z.Restore(); err != nil {
+		return err
+	}
+	z.disconnected.Store(false)
+	return nil
+}
+
+// LoadScreen shows the screen in the preview once the session is restored or
+// started, until new output replaces it.
+func (z *ZellijSession) LoadScreen(screen string) {
+	z.loadedScreen = screen
+}
+
 // Attach attaches to the session for interactive use.
 func (z *ZellijSession) Attach() (chan struct{}, error) {
 	// Stop PTY reader - we'll use direct I/O during attach
@@ -460,6 +508,10 @@ func (z *ZellijSession) DetachSafely() error {
 func (z *ZellijSession) Close() error {
 	var errs []error
 
+	if err := z.closePanes(); err != nil {
+		errs = append(errs, err)
+	}
+
 	// Stop PTY reader before closing PTY
 	z.stopPTYReader()
 
@@ -519,6 +571,12 @@ func (z *ZellijSession) TapDAndEnter() error {
 // readCaptureFileWithRetry attempts to read the capture file with retries.
 // It waits for the file to exist and have content before reading.
 func readCaptureFileWithRetry(filePath string) ([]byte, error) {
+	return readCaptureWithRetry(filePath, os.ReadFile)
+}
+
+// readCaptureWithRetry waits for the capture file like readCaptureFileWithRetry
+// and reads it with the given function.
+func readCaptureWithRetry(filePath string, read func(string) ([]byte, error)) ([]byte, error) {
 	var lastErr error
 	delay := captureFileInitialDelay
 
@@ -549,7 +607,7 @@ func readCaptureFileWithRetry(filePath string) ([]byte, error) {
 		}
 
 		// Read the file
-		content, err := os.ReadFile(filePath)
+		content, err := read(filePath)
 		if err != nil {
 			lastErr = fmt.Errorf("error reading capture file (attempt %d/%d): %w", attempt+1, captureFileMaxRetries+1, err)
 			continue
@@ -570,6 +628,11 @@ func readCaptureFileWithRetry(filePath string) ([]byte, error) {
 // CapturePaneContent captures the current pane content.
 // Uses the terminal buffer for colored output when available.
 func (z *ZellijSession) CapturePaneContent() (string, error) {
+	return z.capturePaneContent(context.Background())
+}
+
+// capturePaneContent is CapturePaneContent, stopping dump-screen once ctx is done.
+func (z *ZellijSession) capturePaneContent(ctx context.Context) (string, error) {
 	// Check cache first
 	if content, _, valid := z.contentCache.Get(); valid {
 		return content, nil
@@ -588,7 +651,7 @@ func (z *ZellijSession) CapturePaneContent() (string, error) {
 	tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("zellij_capture_%s_%d.txt", z.sanitizedName, time.Now().UnixNano()))
 	defer os.Remove(tmpFile)
 
-	cmd := exec.Command("zellij", "-s", z.sanitizedName, "action", "dump-screen", tmpFile)
+	cmd := exec.CommandContext(ctx, "zellij", "-s", z.sanitizedName, "action", "dump-screen", tmpFile)
 	if err := z.cmdExec.Run(cmd); err != nil {
 		return "", fmt.Errorf("error capturing pane content: %w", err)
 	}
@@ -628,22 +691,34 @@ func (z *ZellijSession) CapturePaneContentWithOptions(start, end string) (string
 	return string(content), nil
 }
 
+// HasPrompt reports whether the pane content of the program shows a permission
+// prompt waiting for an answer.
+func HasPrompt(program, content string) bool {
+	switch {
+	case program == ProgramClaude:
+		return strings.Contains(content, "No, and tell Claude what to do differently")
+	case strings.HasPrefix(program, ProgramAider):
+		return strings.Contains(content, "(Y)es/(N)o/(D)on't ask again")
+	case strings.HasPrefix(program, ProgramGemini):
+		return strings.Contains(content, "Yes, allow once")
+	}
+	return false
+}
+
 // HasUpdated checks if pane content has changed since the last check.
 func (z *ZellijSession) HasUpdated() (updated bool, hasPrompt bool) {
-	content, err := z.CapturePaneContent()
+	return z.HasUpdatedContext(context.Background())
+}
+
+// HasUpdatedContext is HasUpdated, stopping the capture once ctx is done.
+func (z *ZellijSession) HasUpdatedContext(ctx context.Context) (updated bool, hasPrompt bool) {
+	content, err := z.capturePaneContent(ctx)
 	if err != nil {
 		log.ErrorLog.Printf("error capturing pane content: %v", err)
 		return false, false
 	}
 
-	// Check for prompts based on program type
-	if z.program == ProgramClaude {
-		hasPrompt = strings.Contains(content, "No, and tell Claude what to do differently")
-	} else if strings.HasPrefix(z.program, ProgramAider) {
-		hasPrompt = strings.Contains(content, "(Y)es/(N)o/(D)on't ask again")
-	} else if strings.HasPrefix(z.program, ProgramGemini) {
-		hasPrompt = strings.Contains(content, "Yes, allow once")
-	}
+	hasPrompt = HasPrompt(z.program, content)
 
 	// If monitor is not initialized, initialize it now
 	if z.monitor == nil {
@@ -731,6 +806,8 @@ func (z *ZellijSession) resizeWithRetry(cols, rows int, maxRetries int) error {
 
 // SetDetachedSize sets the pane dimensions while detached.
 func (z *ZellijSession) SetDetachedSize(width, height int) error {
+	z.resizePanes(width, height)
+
 	// Always resize the terminal buffer first (even if PTY is nil)
 	// This ensures consistency when PTY is later restored
 	if z.termBuffer != nil {
@@ -770,7 +847,12 @@ func (z *ZellijSession) GetProgram() string {
 // Returns true if the program appears to be running, false if we see a shell prompt
 // or other indicators that the program has exited.
 func (z *ZellijSession) IsProgramRunning() (bool, error) {
-	content, err := z.CapturePaneContent()
+	return z.IsProgramRunningContext(context.Background())
+}
+
+// IsProgramRunningContext is IsProgramRunning, stopping the capture once ctx is done.
+func (z *ZellijSession) IsProgramRunningContext(ctx context.Context) (bool, error) {
+	content, err := z.capturePaneContent(ctx)
 	if err != nil {
 		return false, fmt.Errorf("failed to capture pane content: %w", err)
 	}
@@ -864,7 +946,7 @@ func detectProgramRunning(content, program string) bool {
 // This sends the program command to the terminal and executes it.
 func (z *ZellijSession) RestartProgram(args string) error {
 	// Build the command string
-	command := z.program
+	command := z.command()
 	if args != "" {
 		command = command + " " + args
 	}
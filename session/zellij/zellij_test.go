@@ -3,6 +3,7 @@ package zellij
 import (
 	"claude-squad/cmd/cmd_test"
 	"claude-squad/log"
+	"context"
 	"crypto/sha256"
 	"io"
 	"os"
@@ -190,6 +191,27 @@ func TestCapturePaneContent(t *testing.T) {
 	require.NotNil(t, session)
 }
 
+func TestHasUpdatedContextBindsCapture(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var bound bool
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error {
+			// exec.CommandContext sets Cancel to kill the command
+			bound = cmd.Cancel != nil
+			return ctx.Err()
+		},
+	}
+
+	session := NewZellijSessionWithDeps("test", "claude", cmdExec)
+	// Without the PTY's screen the pane is captured with dump-screen
+	session.termBuffer = nil
+	updated, hasPrompt := session.HasUpdatedContext(ctx)
+	require.True(t, bound, "dump-screen should be stopped with the context")
+	require.False(t, updated)
+	require.False(t, hasPrompt)
+}
+
 // Test the hash method on statusMonitor - uses io.WriteString for no allocation
 func TestStatusMonitorHash(t *testing.T) {
 	monitor := newStatusMonitor()
@@ -314,6 +336,44 @@ func TestClose(t *testing.T) {
 	require.Contains(t, executedCmd, "kill-session")
 }
 
+func TestCloseKillsPaneSessionsByName(t *testing.T) {
+	var executedCmds []string
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error {
+			executedCmds = append(executedCmds, cmd.String())
+			return nil
+		},
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			// The pane was started before claude-squad restarted
+			return []byte("claudesquad_test\nclaudesquad_test__pane_aux\nclaudesquad_test2__pane_aux\n"), nil
+		},
+	}
+
+	session := NewZellijSessionWithDeps("test", "claude", cmdExec)
+	require.NoError(t, session.Close())
+	require.Contains(t, executedCmds, "zellij kill-session claudesquad_test__pane_aux")
+	require.Contains(t, executedCmds, "zellij kill-session claudesquad_test")
+	require.NotContains(t, executedCmds, "zellij kill-session claudesquad_test2__pane_aux")
+}
+
+func TestListOrphanedPaneSessions(t *testing.T) {
+	cmdExec := cmd_test.MockCmdExec{
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			return []byte("claudesquad_tracked__pane_aux\n" +
+				"claudesquad_running\nclaudesquad_running__pane_aux\n" +
+				"claudesquad_gone__pane_aux\n"), nil
+		},
+	}
+
+	orphans, err := ListOrphanedSessions([]string{"tracked"}, cmdExec)
+	require.NoError(t, err)
+	// The panes of tracked and running sessions are left to them
+	require.Equal(t, []OrphanedSession{
+		{SessionName: "claudesquad_running", Title: "running"},
+		{SessionName: "claudesquad_gone__pane_aux", Title: "gone__pane_aux"},
+	}, orphans)
+}
+
 func TestCleanupSessions(t *testing.T) {
 	var executedCmds []string
 	cmdExec := cmd_test.MockCmdExec{
@@ -414,3 +474,36 @@ func TestReadCaptureFileWithRetry_DelayedWrite(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "delayed content", string(content))
 }
+
+func TestRename(t *testing.T) {
+	var executedCmd string
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error {
+			executedCmd = cmd.String()
+			return nil
+		},
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			return []byte("claudesquad_old\n"), nil
+		},
+	}
+
+	session := NewZellijSessionWithDeps("old", "claude", cmdExec)
+	require.NoError(t, session.Rename("new"))
+	require.Contains(t, executedCmd, "-s claudesquad_old action rename-session claudesquad_new")
+	require.Equal(t, "claudesquad_new", session.sanitizedName)
+
+	// A session that isn't running only changes the name it starts with
+	executedCmd = ""
+	require.NoError(t, session.Rename("newer"))
+	require.Empty(t, executedCmd)
+	require.Equal(t, "claudesquad_newer", session.sanitizedName)
+}
+
+func TestHasPrompt(t *testing.T) {
+	claudePrompt := "Do you want to make this edit?\n❯ 1. Yes\n  2. No, and tell Claude what to do differently (esc)"
+	require.True(t, HasPrompt(ProgramClaude, claudePrompt))
+	require.False(t, HasPrompt(ProgramClaude, "Run the tests? [Y/n]"), "generic prompts are not Claude's")
+	require.True(t, HasPrompt("aider --model sonnet", "Apply edit? (Y)es/(N)o/(D)on't ask again [Yes]:"))
+	require.True(t, HasPrompt(ProgramGemini, "● Yes, allow once"))
+	require.False(t, HasPrompt("bash", claudePrompt))
+}
@@ -3,6 +3,7 @@
 package snapshot
 
 import (
+	"flag"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -13,6 +14,11 @@ import (
 // GoldenDir is the default directory for golden files
 const GoldenDir = "testdata/golden"
 
+// update rewrites the golden files of the tests that import this package:
+//
+//	go test ./app -update
+var update = flag.Bool("update", false, "update golden files")
+
 // Snap provides snapshot testing functionality
 type Snap struct {
 	t         *testing.T
@@ -25,7 +31,7 @@ func New(t *testing.T) *Snap {
 	return &Snap{
 		t:         t,
 		goldenDir: GoldenDir,
-		update:    os.Getenv("UPDATE_GOLDEN") == "1",
+		update:    *update || os.Getenv("UPDATE_GOLDEN") == "1",
 	}
 }
 
@@ -36,7 +42,7 @@ func (s *Snap) WithDir(dir string) *Snap {
 }
 
 // Assert compares actual output against a golden file.
-// If UPDATE_GOLDEN=1, updates the golden file instead.
+// If UPDATE_GOLDEN=1 or -update is given, updates the golden file instead.
 func (s *Snap) Assert(name, actual string) {
 	s.t.Helper()
 
@@ -61,13 +67,13 @@ func (s *Snap) Assert(name, actual string) {
 	expected, err := os.ReadFile(goldenPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			s.t.Fatalf("Golden file not found: %s\nRun with UPDATE_GOLDEN=1 to create it.\nActual output:\n%s", goldenPath, normalized)
+			s.t.Fatalf("Golden file not found: %s\nRun with -update or UPDATE_GOLDEN=1 to create it.\nActual output:\n%s", goldenPath, normalized)
 		}
 		s.t.Fatalf("failed to read golden file: %v", err)
 	}
 
 	if string(expected) != normalized {
-		s.t.Errorf("Snapshot mismatch for %s\n\nExpected:\n%s\n\nActual:\n%s\n\nRun with UPDATE_GOLDEN=1 to update.",
+		s.t.Errorf("Snapshot mismatch for %s\n\nExpected:\n%s\n\nActual:\n%s\n\nRun with -update or UPDATE_GOLDEN=1 to update.",
 			name, string(expected), normalized)
 	}
 }
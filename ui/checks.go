@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	checkPassedStyle  = lipgloss.NewStyle().Foreground(StatusSuccess)
+	checkFailedStyle  = lipgloss.NewStyle().Foreground(StatusError)
+	checkRunningStyle = lipgloss.NewStyle().Foreground(StatusRunning)
+)
+
+// ChecksPane shows the result and output of the last check run on an instance.
+type ChecksPane struct {
+	viewport viewport.Model
+	width    int
+	height   int
+
+	// command is the configured check command, empty if none is configured
+	command string
+}
+
+func NewChecksPane() *ChecksPane {
+	return &ChecksPane{
+		viewport: viewport.New(0, 0),
+	}
+}
+
+// SetCommand sets the configured check command, which is shown before any check has run.
+func (c *ChecksPane) SetCommand(command string) {
+	c.command = command
+}
+
+func (c *ChecksPane) SetSize(width, height int) {
+	c.width = width
+	c.height = height
+	c.viewport.Width = width
+	c.viewport.Height = height
+}
+
+func (c *ChecksPane) SetChecks(instance *session.Instance) {
+	centered := func(message string) string {
+		return lipgloss.Place(c.width, c.height, lipgloss.Center, lipgloss.Center, message)
+	}
+
+	switch {
+	case c.command == "":
+		c.viewport.SetContent(centered("No check command configured.\nSet check_command in the config to run checks."))
+		return
+	case instance == nil || !instance.Started():
+		c.viewport.SetContent(centered("No checks"))
+		return
+	}
+
+	result := instance.LastCheck
+	if result == nil {
+		if instance.CheckRunning() {
+			c.viewport.SetContent(centered(checkRunningStyle.Render("Running " + c.command + "...")))
+			return
+		}
+		c.viewport.SetContent(centered(fmt.Sprintf("No checks run yet\nPress %s to run %s", checkKeyHint, c.command)))
+		return
+	}
+
+	header := checkPassedStyle.Render(fmt.Sprintf("%s passed in %s", result.Command, FormatCheckDuration(result.Duration)))
+	if !result.Passed {
+		header = checkFailedStyle.Render(fmt.Sprintf("%s failed after %s", result.Command, FormatCheckDuration(result.Duration)))
+	}
+	header += timerStyle.Render(" - finished " + FormatRelativeTime(result.FinishedAt))
+	if instance.CheckRunning() {
+		header = checkRunningStyle.Render("Running "+c.command+"...") + "\n" + header
+	}
+
+	atBottom := c.viewport.AtBottom()
+	c.viewport.SetContent(lipgloss.JoinVertical(lipgloss.Left, header, "", result.Output))
+	// Follow the end of the output, where failures are usually reported
+	if atBottom {
+		c.viewport.GotoBottom()
+	}
+}
+
+func (c *ChecksPane) String() string {
+	return c.viewport.View()
+}
+
+// ScrollUp scrolls the viewport up
+func (c *ChecksPane) ScrollUp() {
+	c.viewport.LineUp(1)
+}
+
+// ScrollDown scrolls the viewport down
+func (c *ChecksPane) ScrollDown() {
+	c.viewport.LineDown(1)
+}
+
+// checkKeyHint is the key that runs the check command
+const checkKeyHint = "C"
+
+// FormatCheckDuration formats how long a check took, with second precision
+// unless it took less than a second.
+func FormatCheckDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
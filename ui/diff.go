@@ -1,11 +1,13 @@
 package ui
 
 import (
+	"claude-squad/log"
 	"claude-squad/session"
 	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -21,6 +23,34 @@ type DiffPane struct {
 	stats    string
 	width    int
 	height   int
+
+	// renderer is the external diff renderer command, empty for the built-in one
+	renderer string
+	// rendered caches the output of the renderer for a diff and width
+	rendered diffRenderCache
+	// content is the diff shown, before rendering
+	content string
+	// pending is the diff to render in the background, if any
+	pending *DiffRenderedMsg
+	// rendering is the diff and width being rendered in the background
+	rendering diffRenderCache
+}
+
+// diffRenderCache is the rendering of a diff at a given width.
+type diffRenderCache struct {
+	content string
+	width   int
+	output  string
+}
+
+// DiffRenderedMsg carries a diff rendered by the external renderer in the background.
+type DiffRenderedMsg struct {
+	instance *session.Instance
+	ref      string
+	content  string
+	width    int
+	output   string
+	err      error
 }
 
 func NewDiffPane() *DiffPane {
@@ -29,6 +59,14 @@ func NewDiffPane() *DiffPane {
 	}
 }
 
+// SetRenderer sets the external command used to render diffs. An empty command
+// selects the built-in renderer.
+func (d *DiffPane) SetRenderer(renderer string) {
+	d.renderer = renderer
+	d.rendered = diffRenderCache{}
+	d.rendering = diffRenderCache{}
+}
+
 func (d *DiffPane) SetSize(width, height int) {
 	d.width = width
 	d.height = height
@@ -54,6 +92,17 @@ func (d *DiffPane) SetDiff(instance *session.Instance) {
 		return
 	}
 
+	if instance.Scratch {
+		d.viewport.SetContent(lipgloss.Place(
+			d.width,
+			d.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			"Scratch session - not a git repository",
+		))
+		return
+	}
+
 	stats := instance.GetDiffStats()
 	if stats == nil {
 		// Show loading message if worktree is not ready
@@ -84,12 +133,17 @@ func (d *DiffPane) SetDiff(instance *session.Instance) {
 	if stats.IsEmpty() {
 		d.stats = ""
 		d.diff = ""
+		d.content = ""
 		d.viewport.SetContent(centeredFallbackMessage)
 	} else {
 		additions := AdditionStyle.Render(fmt.Sprintf("%d additions(+)", stats.Added))
 		deletions := DeletionStyle.Render(fmt.Sprintf("%d deletions(-)", stats.Removed))
 		d.stats = lipgloss.JoinHorizontal(lipgloss.Center, additions, " ", deletions)
-		d.diff = colorizeDiff(stats.Content)
+		if ref := instance.DiffRef(); ref != "" {
+			d.stats = lipgloss.JoinHorizontal(lipgloss.Center, d.stats, " ", HunkStyle.Render("vs "+ref))
+		}
+		d.content = stats.Content
+		d.diff = d.renderDiff(instance, stats.Content)
 		d.viewport.SetContent(lipgloss.JoinVertical(lipgloss.Left, d.stats, d.diff))
 	}
 }
@@ -108,6 +162,57 @@ func (d *DiffPane) ScrollDown() {
 	d.viewport.LineDown(1)
 }
 
+// renderDiff renders the diff with the configured renderer. External renderers
+// only run when the diff or width changes, in the background: until RenderCmd's
+// result arrives, the diff is shown with the built-in renderer.
+func (d *DiffPane) renderDiff(instance *session.Instance, content string) string {
+	if d.renderer == "" {
+		return colorizeDiff(content)
+	}
+	if d.rendered.content == content && d.rendered.width == d.width && d.rendered.output != "" {
+		return d.rendered.output
+	}
+	if d.rendering.content != content || d.rendering.width != d.width {
+		d.pending = &DiffRenderedMsg{instance: instance, ref: instance.DiffRef(), content: content, width: d.width}
+	}
+	return colorizeDiff(content)
+}
+
+// RenderCmd returns the command rendering the diff shown with the external
+// renderer, or nil if there is nothing to render.
+func (d *DiffPane) RenderCmd() tea.Cmd {
+	if d.pending == nil {
+		return nil
+	}
+	msg, renderer := *d.pending, d.renderer
+	d.pending = nil
+	d.rendering = diffRenderCache{content: msg.content, width: msg.width}
+	return func() tea.Msg {
+		msg.output, msg.err = msg.instance.RenderDiff(renderer, msg.ref, msg.content, msg.width)
+		if msg.err == nil && strings.TrimSpace(msg.output) == "" {
+			msg.err = fmt.Errorf("diff renderer %s produced no output", renderer)
+		}
+		return msg
+	}
+}
+
+// HandleRendered shows the diff rendered in the background if it is still the
+// one shown, falling back to the built-in renderer if rendering failed.
+func (d *DiffPane) HandleRendered(msg DiffRenderedMsg) {
+	if d.rendering.content == msg.content && d.rendering.width == msg.width {
+		d.rendering = diffRenderCache{}
+	}
+	if msg.err != nil {
+		log.WarningLog.Printf("falling back to built-in diff rendering: %v", msg.err)
+		msg.output = colorizeDiff(msg.content)
+	}
+	d.rendered = diffRenderCache{content: msg.content, width: msg.width, output: msg.output}
+	if d.content == msg.content && d.width == msg.width {
+		d.diff = msg.output
+		d.viewport.SetContent(lipgloss.JoinVertical(lipgloss.Left, d.stats, d.diff))
+	}
+}
+
 func colorizeDiff(diff string) string {
 	var coloredOutput strings.Builder
 
@@ -8,6 +8,8 @@ import (
 type ErrBox struct {
 	height, width int
 	err           error
+	// info is a non-error notice (e.g. an undo prompt) shown when there is no error.
+	info string
 }
 
 var errStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{
@@ -15,6 +17,8 @@ var errStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{
 	Dark:  "#FF0000",
 })
 
+var infoStyle = lipgloss.NewStyle().Foreground(StatusWarning)
+
 func NewErrBox() *ErrBox {
 	return &ErrBox{}
 }
@@ -27,12 +31,28 @@ func (e *ErrBox) Clear() {
 	e.err = nil
 }
 
-// GetMessage returns the current error message, or empty string if none.
+// SetInfo sets a notice that stays visible until ClearInfo is called.
+func (e *ErrBox) SetInfo(info string) {
+	e.info = info
+}
+
+// ClearInfo removes the current notice.
+func (e *ErrBox) ClearInfo() {
+	e.info = ""
+}
+
+// Info returns the current notice.
+func (e *ErrBox) Info() string {
+	return e.info
+}
+
+// GetMessage returns the current error message (or notice if there is no error),
+// or empty string if none.
 func (e *ErrBox) GetMessage() string {
 	if e.err != nil {
 		return e.err.Error()
 	}
-	return ""
+	return e.info
 }
 
 func (e *ErrBox) SetSize(width, height int) {
@@ -42,13 +62,19 @@ func (e *ErrBox) SetSize(width, height int) {
 
 func (e *ErrBox) String() string {
 	var err string
+	style := errStyle
 	if e.err != nil {
 		err = e.err.Error()
+	} else if e.info != "" {
+		err = e.info
+		style = infoStyle
+	}
+	if err != "" {
 		lines := strings.Split(err, "\n")
 		err = strings.Join(lines, "//")
-		if len(err) > e.width-3 && e.width-3 >= 0 {
-			err = err[:e.width-3] + "..."
+		if e.width >= 3 {
+			err = truncateLine(err, e.width)
 		}
 	}
-	return lipgloss.Place(e.width, e.height, lipgloss.Center, lipgloss.Center, errStyle.Render(err))
+	return lipgloss.Place(e.width, e.height, lipgloss.Center, lipgloss.Center, style.Render(err))
 }
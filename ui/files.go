@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	fileDirStyle      = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#888888", Dark: "#888888"})
+	fileSelectedStyle = lipgloss.NewStyle().Background(highlightColor).Foreground(lipgloss.Color("#ffffff"))
+	fileStatusStyles  = map[string]lipgloss.Style{
+		"M":  lipgloss.NewStyle().Foreground(StatusWarning),
+		"A":  AdditionStyle,
+		"??": AdditionStyle,
+		"D":  DeletionStyle,
+		"R":  HunkStyle,
+	}
+)
+
+// FilesPane shows the files changed in an instance's worktree as a tree, and
+// the diff of the selected file.
+type FilesPane struct {
+	viewport viewport.Model
+	width    int
+	height   int
+
+	instance *session.Instance
+	// files are the changed files, sorted by path
+	files    []git.ChangedFile
+	selected int
+	// open is true while the diff of the selected file is shown
+	open bool
+}
+
+func NewFilesPane() *FilesPane {
+	return &FilesPane{
+		viewport: viewport.New(0, 0),
+	}
+}
+
+func (f *FilesPane) SetSize(width, height int) {
+	f.width = width
+	f.height = height
+	f.viewport.Width = width
+	f.viewport.Height = height
+	f.render()
+}
+
+// SetFiles updates the changed files from the diff of the instance. The
+// selection stays on the same file while it is still changed.
+func (f *FilesPane) SetFiles(instance *session.Instance) {
+	if instance != f.instance {
+		f.instance = instance
+		f.selected = 0
+		f.open = false
+	}
+
+	var files []git.ChangedFile
+	if instance != nil && instance.Started() && !instance.Scratch {
+		if stats := instance.GetDiffStats(); stats != nil && stats.Error == nil {
+			files = stats.ChangedFiles()
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	selectedPath := f.SelectedPath()
+	f.files = files
+	f.selected = 0
+	for i, file := range files {
+		if file.Path == selectedPath {
+			f.selected = i
+			break
+		}
+	}
+	if len(files) == 0 {
+		f.open = false
+	}
+	f.render()
+}
+
+// SelectedPath returns the path of the selected file, relative to the root of
+// the worktree, or an empty string if no file is changed.
+func (f *FilesPane) SelectedPath() string {
+	if f.selected < len(f.files) {
+		return f.files[f.selected].Path
+	}
+	return ""
+}
+
+// ToggleDiff shows the diff of the selected file, or goes back to the files.
+func (f *FilesPane) ToggleDiff() {
+	if len(f.files) == 0 {
+		return
+	}
+	f.open = !f.open
+	f.viewport.GotoTop()
+	f.render()
+}
+
+// Up selects the previous file, or scrolls the diff of the file up.
+func (f *FilesPane) Up() {
+	if f.open {
+		f.viewport.LineUp(1)
+		return
+	}
+	if f.selected > 0 {
+		f.selected--
+		f.render()
+	}
+}
+
+// Down selects the next file, or scrolls the diff of the file down.
+func (f *FilesPane) Down() {
+	if f.open {
+		f.viewport.LineDown(1)
+		return
+	}
+	if f.selected < len(f.files)-1 {
+		f.selected++
+		f.render()
+	}
+}
+
+func (f *FilesPane) String() string {
+	return f.viewport.View()
+}
+
+// render sets the content of the viewport to the tree of files or the diff of
+// the selected file.
+func (f *FilesPane) render() {
+	if len(f.files) == 0 {
+		f.viewport.SetContent(lipgloss.Place(f.width, f.height, lipgloss.Center, lipgloss.Center, "No changed files"))
+		return
+	}
+
+	if f.open {
+		file := f.files[f.selected]
+		header := fileStatusStyle(file.Status).Render(file.Status) + " " + file.Path
+		help := fileDirStyle.Render("enter: back to files • e: open in editor")
+		f.viewport.SetContent(lipgloss.JoinVertical(lipgloss.Left, header, help, "", colorizeDiff(file.Diff)))
+		return
+	}
+
+	header := fileDirStyle.Render(fmt.Sprintf("%d changed files • shift-↑/↓: select • enter: diff • e: open in editor", len(f.files)))
+	lines := []string{header, ""}
+	selectedLine := 0
+	for _, row := range fileTree(f.files) {
+		indent := strings.Repeat("  ", row.depth)
+		if row.file < 0 {
+			lines = append(lines, fileDirStyle.Render(indent+row.name+"/"))
+			continue
+		}
+		status := f.files[row.file].Status
+		line := indent + fmt.Sprintf("%-2s", status) + " " + row.name
+		if row.file == f.selected {
+			selectedLine = len(lines)
+			line = fileSelectedStyle.Render(line)
+		} else {
+			line = indent + fileStatusStyle(status).Render(fmt.Sprintf("%-2s", status)) + " " + row.name
+		}
+		lines = append(lines, line)
+	}
+	f.viewport.SetContent(strings.Join(lines, "\n"))
+
+	// Keep the selected file in view
+	if selectedLine < f.viewport.YOffset {
+		f.viewport.SetYOffset(selectedLine)
+	} else if f.viewport.Height > 0 && selectedLine >= f.viewport.YOffset+f.viewport.Height {
+		f.viewport.SetYOffset(selectedLine - f.viewport.Height + 1)
+	}
+}
+
+// fileStatusStyle returns the style of a file status letter.
+func fileStatusStyle(status string) lipgloss.Style {
+	if style, ok := fileStatusStyles[status]; ok {
+		return style
+	}
+	return lipgloss.NewStyle()
+}
+
+// fileTreeRow is a directory or file in the tree of changed files.
+type fileTreeRow struct {
+	name  string
+	depth int
+	// file is the index of the file, or -1 for a directory
+	file int
+}
+
+// fileTree lays out files sorted by path as a tree, with each directory shown
+// once above its files.
+func fileTree(files []git.ChangedFile) []fileTreeRow {
+	var rows []fileTreeRow
+	var previous []string
+	for i, file := range files {
+		var dirs []string
+		if dir := path.Dir(file.Path); dir != "." {
+			dirs = strings.Split(dir, "/")
+		}
+		common := 0
+		for common < len(dirs) && common < len(previous) && dirs[common] == previous[common] {
+			common++
+		}
+		for depth := common; depth < len(dirs); depth++ {
+			rows = append(rows, fileTreeRow{name: dirs[depth], depth: depth, file: -1})
+		}
+		rows = append(rows, fileTreeRow{name: path.Base(file.Path), depth: len(dirs), file: i})
+		previous = dirs
+	}
+	return rows
+}
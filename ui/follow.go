@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// followHighlightDuration is how long new lines stay highlighted in the preview.
+const followHighlightDuration = 1500 * time.Millisecond
+
+// ansiEscapePattern matches CSI and OSC 8 hyperlink escape sequences in captured pane content.
+var ansiEscapePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]|\x1b\]8;;[^\x1b]*\x1b\\`)
+
+var (
+	followIndicatorStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#16a34a", Dark: "#51bd73"})
+	followHighlightStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#ffffff"}).
+				Background(lipgloss.AdaptiveColor{Light: "#d9f99d", Dark: "#2f4a36"})
+)
+
+// followState tracks the lines of the last capture of the followed instance
+// and when each of them last changed.
+type followState struct {
+	// key identifies the followed instance; the state is reset when it changes
+	key any
+	// lines are the lines of the last capture, without escape sequences
+	lines []string
+	// changedAt is when each line was new or changed, zero for lines that
+	// were there when following started
+	changedAt []time.Time
+}
+
+// update diffs a new capture of the instance identified by key against the
+// previous one. The first capture of an instance highlights nothing.
+func (f *followState) update(key any, content string, now time.Time) {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(ansiEscapePattern.ReplaceAllString(line, ""), " ")
+	}
+	if f.key != key {
+		f.key, f.lines, f.changedAt = key, lines, make([]time.Time, len(lines))
+		return
+	}
+	f.changedAt = diffLines(f.lines, f.changedAt, lines, now)
+	f.lines = lines
+}
+
+// reset forgets the followed instance.
+func (f *followState) reset() {
+	*f = followState{}
+}
+
+// highlighted returns true if the line at index i changed recently.
+func (f *followState) highlighted(i int, now time.Time) bool {
+	return i < len(f.changedAt) && !f.changedAt[i].IsZero() && now.Sub(f.changedAt[i]) < followHighlightDuration
+}
+
+// diffLines returns when each of the current lines changed. The current
+// lines are aligned with the previous ones at the scroll offset where most
+// lines match, so output scrolling up the pane only marks the appended lines.
+// Lines that match keep when they changed before; blank lines are never marked.
+func diffLines(prev []string, prevChangedAt []time.Time, cur []string, now time.Time) []time.Time {
+	shift, best := 0, -1
+	for s := 0; s <= len(prev); s++ {
+		matches := 0
+		for i := 0; i < len(cur) && i+s < len(prev); i++ {
+			if cur[i] != "" && cur[i] == prev[i+s] {
+				matches++
+			}
+		}
+		if matches > best {
+			shift, best = s, matches
+		}
+	}
+
+	changedAt := make([]time.Time, len(cur))
+	for i, line := range cur {
+		switch {
+		case line == "":
+		case i+shift < len(prev) && prev[i+shift] == line:
+			if i+shift < len(prevChangedAt) {
+				changedAt[i] = prevChangedAt[i+shift]
+			}
+		default:
+			changedAt[i] = now
+		}
+	}
+	return changedAt
+}
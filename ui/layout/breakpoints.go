@@ -59,6 +59,10 @@ const (
 	// ErrBoxHeight is the fixed error box height.
 	ErrBoxHeight = 1
 
+	// ContentPaddingTop is the blank line above the list and the preview in
+	// the horizontal layout.
+	ContentPaddingTop = 1
+
 	// TitleAreaHeight is the list title area height in normal mode.
 	TitleAreaHeight = 5
 
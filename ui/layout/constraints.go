@@ -61,8 +61,8 @@ func ComputeConstraints(width, height int) Constraints {
 		// Horizontal layout with constrained list width
 		c.ListWidth = computeListWidth(width, c.Mode)
 		c.PreviewWidth = width - c.ListWidth
-		c.ListHeight = contentHeight
-		c.PreviewHeight = contentHeight
+		c.ListHeight = contentHeight - ContentPaddingTop
+		c.PreviewHeight = contentHeight - ContentPaddingTop
 	}
 
 	return c
@@ -40,7 +40,7 @@ func TestMinimumTerminal80x24(t *testing.T) {
 		assert.LessOrEqual(t, c.ListWidth+c.PreviewWidth, width, "total width should not exceed terminal")
 
 		// Verify heights fit
-		totalHeight := c.ListHeight + c.MenuHeight + c.ErrBoxHeight
+		totalHeight := c.ListHeight + ContentPaddingTop + c.MenuHeight + c.ErrBoxHeight
 		assert.LessOrEqual(t, totalHeight, height, "total height should not exceed terminal")
 	})
 
@@ -3,10 +3,13 @@ package ui
 import (
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"sort"
 	"strings"
 
 	"claude-squad/log"
 	"claude-squad/session"
+	"claude-squad/session/git"
 	"claude-squad/ui/layout"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -17,6 +20,7 @@ import (
 const (
 	readyIcon   = "● "  // Ready state
 	pausedIcon  = "⏸ " // Paused state
+	pendingIcon = "◌ "  // Pending state, waiting for a slot
 	runningIcon = "◐ "  // Running state (when no spinner available)
 )
 
@@ -39,6 +43,14 @@ var addedLinesStyle = lipgloss.NewStyle().
 var removedLinesStyle = lipgloss.NewStyle().
 	Foreground(StatusError)
 
+// unpushedStyle marks commits of a branch none of whose commits were pushed
+// yet; once some were, its unpushed and missing commits use commitCountStyle
+var unpushedStyle = lipgloss.NewStyle().
+	Foreground(StatusWarning)
+
+var commitCountStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.AdaptiveColor{Light: "#888888", Dark: "#666666"})
+
 var pausedStyle = lipgloss.NewStyle().
 	Foreground(StatusPaused)
 
@@ -72,6 +84,55 @@ var muxTagStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.AdaptiveColor{Light: "#888888", Dark: "#666666"}).
 	Italic(true)
 
+var ownerTagStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.AdaptiveColor{Light: "#9a6700", Dark: "#e0af68"})
+
+var todoProgressStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.AdaptiveColor{Light: "#2563eb", Dark: "#7aa2f7"})
+
+// reviewStatusStyles colors the review status shown after the title
+var reviewStatusStyles = map[session.ReviewStatus]lipgloss.Style{
+	session.ReviewPending:          lipgloss.NewStyle().Foreground(StatusWarning),
+	session.ReviewApproved:         lipgloss.NewStyle().Foreground(StatusSuccess),
+	session.ReviewChangesRequested: lipgloss.NewStyle().Foreground(StatusError),
+}
+
+// ciIcons and ciStyles show the state of the CI checks after the title
+var ciIcons = map[git.CIState]string{
+	git.CIPending: "◌",
+	git.CIPassed:  "✓",
+	git.CIFailed:  "✗",
+}
+
+var ciStyles = map[git.CIState]lipgloss.Style{
+	git.CIPending: lipgloss.NewStyle().Foreground(StatusRunning),
+	git.CIPassed:  lipgloss.NewStyle().Foreground(StatusSuccess),
+	git.CIFailed:  lipgloss.NewStyle().Foreground(StatusError),
+}
+
+// landStateStyles colors the merge queue state shown after the title
+var landStateStyles = map[session.LandState]lipgloss.Style{
+	session.LandQueued: lipgloss.NewStyle().Foreground(StatusRunning),
+	session.LandLanded: lipgloss.NewStyle().Foreground(StatusSuccess),
+	session.LandFailed: lipgloss.NewStyle().Foreground(StatusError),
+}
+
+// quarantineStyle is the badge of instances quarantined after suspicious activity
+var quarantineStyle = lipgloss.NewStyle().
+	Background(StatusError).
+	Foreground(lipgloss.Color("#ffffff")).
+	Bold(true)
+
+var rateLimitStyle = lipgloss.NewStyle().
+	Foreground(StatusWarning)
+
+var disconnectedStyle = lipgloss.NewStyle().
+	Foreground(StatusError)
+
+var promptTagStyle = lipgloss.NewStyle().
+	Foreground(StatusWarning).
+	Italic(true)
+
 var timerStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.AdaptiveColor{Light: "#888888", Dark: "#666666"}).
 	Italic(true)
@@ -108,6 +169,13 @@ var compactSelectedStyle = lipgloss.NewStyle().
 var scrollIndicatorStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.AdaptiveColor{Light: "#888888", Dark: "#666666"})
 
+// tagChipColors are the background colors used for tag chips. A tag always gets the same color.
+var tagChipColors = []lipgloss.Color{"#7aa2f7", "#9ece6a", "#e0af68", "#f7768e", "#bb9af7", "#7dcfff", "#ff9e64"}
+
+var tagChipStyle = lipgloss.NewStyle().
+	Foreground(lipgloss.Color("#1a1a1a")).
+	Padding(0, 1)
+
 // FilterMode represents the current filter for the list view
 type FilterMode int
 
@@ -115,6 +183,12 @@ const (
 	FilterAll FilterMode = iota
 	FilterNeedsAttention
 	FilterArchived
+	// FilterTag shows non-archived instances with the tag in filterTag.
+	FilterTag
+	// FilterMine shows non-archived instances created by the current identity.
+	FilterMine
+	// FilterOthers shows non-archived instances created by other identities.
+	FilterOthers
 )
 
 type List struct {
@@ -128,8 +202,14 @@ type List struct {
 	// multiple repos in play.
 	repos map[string]int
 
-	// filterMode controls the current filter view (ALL, NEEDS ATTENTION, or ARCHIVED)
+	// filterMode controls the current filter view (ALL, NEEDS ATTENTION, ARCHIVED, or a tag)
 	filterMode FilterMode
+	// filterTag is the tag shown when filterMode is FilterTag
+	filterTag string
+	// identity is the current user's identity, empty unless set on a shared machine
+	identity string
+	// trashRetentionDays is passed to the instances killed, see Instance.Kill
+	trashRetentionDays int
 
 	// scrollOffset is the index of the first visible item in the list
 	scrollOffset int
@@ -139,6 +219,20 @@ type List struct {
 
 	// degradation holds the current UI degradation flags
 	degradation layout.Degradation
+
+	// previewWidth and previewHeight are the size of the sessions' panes, and
+	// previewSizes the size each session was last given, see
+	// SetSessionPreviewSize.
+	previewWidth, previewHeight int
+	previewSizes                map[*session.Instance]previewSize
+}
+
+// previewSize is the size a session was given, and its size epoch at the time.
+// A session whose epoch changed since, e.g. because it was attached to or
+// restarted, is given the size again.
+type previewSize struct {
+	width, height int
+	epoch         uint64
 }
 
 func NewList(spinner *spinner.Model, autoYes bool) *List {
@@ -147,9 +241,24 @@ func NewList(spinner *spinner.Model, autoYes bool) *List {
 		renderer: &InstanceRenderer{spinner: spinner},
 		repos:    make(map[string]int),
 		autoyes:  autoYes,
+
+		previewSizes: make(map[*session.Instance]previewSize),
 	}
 }
 
+// SetIdentity sets the current user's identity, which adds the MINE and OTHERS
+// filters and marks instances created by others.
+func (l *List) SetIdentity(identity string) {
+	l.identity = identity
+	l.renderer.identity = identity
+}
+
+// SetTrashRetentionDays sets how long the work of killed instances is kept in
+// the trash, 0 to delete it right away.
+func (l *List) SetTrashRetentionDays(days int) {
+	l.trashRetentionDays = days
+}
+
 // SetSize sets the height and width of the list.
 func (l *List) SetSize(width, height int) {
 	l.width = width
@@ -238,6 +347,13 @@ func (l *List) calculateVisibleRange() (start, end int) {
 
 // adjustScroll ensures the selected item is visible by adjusting scrollOffset
 func (l *List) adjustScroll() {
+	// Instances scrolled into view get the size of the preview
+	defer func() {
+		if err := l.sizeVisiblePreviews(); err != nil {
+			log.ErrorLog.Print(err)
+		}
+	}()
+
 	visibleItems := l.GetVisibleInstances()
 	if len(visibleItems) == 0 {
 		l.scrollOffset = 0
@@ -285,7 +401,8 @@ func (l *List) adjustScroll() {
 }
 
 // SetSessionPreviewSize sets the height and width for the multiplexer sessions. This makes the stdout line have the correct
-// width and height.
+// width and height. Only the sessions of the instances in view are resized right away, the others once they are scrolled
+// into view, so that resizing stays fast with many instances.
 func (l *List) SetSessionPreviewSize(width, height int) (err error) {
 	// Reduce width slightly to account for ANSI escape codes
 	// When lipgloss renders content with width constraints, it counts ANSI codes as characters
@@ -295,15 +412,42 @@ func (l *List) SetSessionPreviewSize(width, height int) (err error) {
 		adjustedWidth = 1
 	}
 
-	for i, item := range l.items {
-		if !item.Started() || item.Paused() {
+	l.previewWidth, l.previewHeight = adjustedWidth, height
+	return l.sizeVisiblePreviews()
+}
+
+// SizeVisiblePreviews gives the sessions in view that lost their size, e.g. to
+// a restart or reconnect in the background, the size of the preview again.
+func (l *List) SizeVisiblePreviews() error {
+	return l.sizeVisiblePreviews()
+}
+
+// sizeVisiblePreviews gives the sessions of the instances in view the size of the preview, if they don't have it yet.
+func (l *List) sizeVisiblePreviews() (err error) {
+	if l.previewWidth == 0 {
+		return nil
+	}
+	// Paused sessions are started again with their default size
+	for _, item := range l.items {
+		if item.Paused() {
+			delete(l.previewSizes, item)
+		}
+	}
+
+	visibleItems := l.GetVisibleInstances()
+	start, end := l.calculateVisibleRange()
+	for _, item := range visibleItems[start:end] {
+		size := previewSize{width: l.previewWidth, height: l.previewHeight, epoch: item.SizeEpoch()}
+		if !item.Started() || item.Paused() || l.previewSizes[item] == size {
 			continue
 		}
 
-		if innerErr := item.SetPreviewSize(adjustedWidth, height); innerErr != nil {
+		if innerErr := item.SetPreviewSize(size.width, size.height); innerErr != nil {
 			err = errors.Join(
-				err, fmt.Errorf("could not set preview size for instance %d: %v", i, innerErr))
+				err, fmt.Errorf("could not set preview size for instance %s: %v", item.Title, innerErr))
+			continue
 		}
+		l.previewSizes[item] = size
 	}
 	return
 }
@@ -323,6 +467,8 @@ type InstanceRenderer struct {
 	width       int
 	compactMode bool
 	degradation layout.Degradation
+	// identity is the current user's identity; instances of others show their owner
+	identity string
 }
 
 func (r *InstanceRenderer) setWidth(width int) {
@@ -341,41 +487,39 @@ func (r *InstanceRenderer) RenderCompact(i *session.Instance, idx int, selected
 
 	// Status indicator
 	var statusIcon string
-	switch i.Status {
-	case session.Running:
+	switch {
+	case i.Quarantined():
+		statusIcon = quarantineStyle.Render("!")
+	case i.Status == session.Running:
 		statusIcon = r.spinner.View()
-	case session.Ready:
+	case i.Status == session.Ready:
 		statusIcon = readyStyle.Render("●")
-	case session.Paused:
+	case i.Status == session.Paused:
 		statusIcon = pausedStyle.Render("⏸")
+	case i.Status == session.Pending:
+		statusIcon = pausedStyle.Render("◌")
 	default:
 		statusIcon = " "
 	}
 
 	// Branch (short, truncated)
 	branch := i.Branch
-	maxBranchLen := 15
-	if len(branch) > maxBranchLen {
-		branch = branch[:maxBranchLen-3] + "..."
+	if i.Scratch {
+		branch = "scratch"
 	}
+	maxBranchLen := 15
+	branch = truncateLine(branch, maxBranchLen)
 
 	// Calculate available width for title
 	// Layout: prefix + space + statusIcon + space + title + space + [branch]
-	fixedWidth := len(prefix) + 1 + 2 + 1 + 1 + len(branch) + 2 + 4 // extra padding
+	fixedWidth := len(prefix) + 1 + 2 + 1 + 1 + textWidth(branch) + 2 + 4 // extra padding
 	maxTitleWidth := r.width - fixedWidth
 	if maxTitleWidth < 10 {
 		maxTitleWidth = 10
 	}
 
 	// Title (truncated)
-	title := i.Title
-	if len(title) > maxTitleWidth {
-		if maxTitleWidth > 3 {
-			title = title[:maxTitleWidth-3] + "..."
-		} else {
-			title = title[:maxTitleWidth]
-		}
-	}
+	title := truncateLine(i.Title, maxTitleWidth)
 
 	line := fmt.Sprintf("%s %s %s [%s]", prefix, statusIcon, title, branch)
 
@@ -410,6 +554,8 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 		join = readyStyle.Render(readyIcon)
 	case session.Paused:
 		join = pausedStyle.Render(pausedIcon)
+	case session.Pending:
+		join = pausedStyle.Render(pendingIcon)
 	default:
 	}
 
@@ -419,6 +565,76 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 		muxTag = fmt.Sprintf(" [%s]", mtype)
 	}
 
+	// Show the agent's todo progress after the title
+	todoTag := ""
+	if completed, total := session.TodoProgress(i.Todos()); total > 0 {
+		todoTag = fmt.Sprintf(" %d/%d tasks", completed, total)
+	}
+
+	// Show the verdict of the instance's reviewer
+	reviewTag := ""
+	if i.ReviewStatus != session.ReviewNone {
+		reviewTag = fmt.Sprintf(" review: %s", i.ReviewStatus)
+	}
+	// Show the instance's state in the merge queue
+	landTag := ""
+	if i.LandState != session.LandNone {
+		landTag = fmt.Sprintf(" land: %s", i.LandState)
+	}
+	// Show when a rate limited agent will be retried
+	rateLimitTag := ""
+	if i.RateLimited() {
+		rateLimitTag = fmt.Sprintf(" rate limited until %s", i.RateLimitedUntil.Format("15:04"))
+	}
+	// Show that the preview is frozen until the session is reconnected
+	disconnectTag := ""
+	if i.ReconnectError() != nil {
+		disconnectTag = " reconnect failed"
+	} else if i.Disconnected() {
+		disconnectTag = " reconnecting"
+	}
+	// Show the question the agent is waiting on, so it can be answered from the list
+	promptTag := ""
+	if i.PendingPrompt() != "" {
+		promptTag = " ? " + truncateLine(i.PendingPrompt(), 40)
+	}
+	// Show the result of the last check, or that one is running
+	checkTag, checkStyle := "", checkRunningStyle
+	switch {
+	case i.CheckRunning():
+		checkTag = " check: running"
+	case i.LastCheck != nil && i.LastCheck.Passed:
+		checkTag, checkStyle = fmt.Sprintf(" check: passed %s", FormatCheckDuration(i.LastCheck.Duration)), checkPassedStyle
+	case i.LastCheck != nil:
+		checkTag, checkStyle = fmt.Sprintf(" check: failed %s", FormatCheckDuration(i.LastCheck.Duration)), checkFailedStyle
+	}
+	// Show the state of the CI checks of the pushed branch
+	ciTag, ciStyle := "", checkRunningStyle
+	if status := i.CIStatus(); status != nil {
+		ciTag = " CI " + ciIcons[status.State]
+		ciStyle = ciStyles[status.State]
+	}
+	// Show whether the auxiliary command, like a dev server, still runs
+	auxTag, auxStyle := "", checkRunningStyle
+	if i.AuxCommand != "" && i.Started() && !i.Paused() {
+		auxTag = " aux: running"
+		if !i.AuxRunning() {
+			auxTag, auxStyle = " aux: exited", checkFailedStyle
+		}
+	}
+
+	// Flag quarantined instances before anything else
+	quarantineTag := ""
+	if i.Quarantined() {
+		quarantineTag = " QUARANTINED "
+	}
+
+	// Show who created the instance if it was someone else
+	ownerTag := ""
+	if r.identity != "" && i.Owner != "" && i.Owner != r.identity {
+		ownerTag = " @" + i.Owner
+	}
+
 	// Build timer info (age and last opened) - only if not degraded
 	var timerInfo string
 	var timerInfoLen int
@@ -430,24 +646,26 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 	}
 
 	// Cut the title if it's too long (account for mux tag and timer info)
-	// Layout: [prefix][space][title][muxTag][spaces][timerInfo][space][icon]
+	// Layout: [prefix][space][title][space][quarantineTag][muxTag][ownerTag][todoTag][reviewTag][landTag][rateLimitTag][disconnectTag][promptTag][checkTag][ciTag][auxTag][spaces][timerInfo][space][icon]
 	minSpacing := 2
 	iconWidth := 3 // status icon width
 	titleText := i.Title
-	widthAvail := r.width - len(prefix) - 1 - len(muxTag) - minSpacing - timerInfoLen - iconWidth
-	if widthAvail > 0 && widthAvail < len(titleText) {
-		if widthAvail > 3 {
-			titleText = titleText[:widthAvail-3] + "..."
-		} else if widthAvail > 0 {
-			titleText = titleText[:widthAvail]
-		}
+	widthAvail := r.width - len(prefix) - 1 - quarantineWidth(quarantineTag) - len(muxTag) - textWidth(ownerTag) - len(todoTag) - len(reviewTag) - len(landTag) - len(rateLimitTag) - len(disconnectTag) - textWidth(promptTag) - len(checkTag) - textWidth(ciTag) - len(auxTag) - minSpacing - timerInfoLen - iconWidth
+	if widthAvail > 0 {
+		titleText = truncateLine(titleText, widthAvail)
 	}
 
 	// Build title with multiplexer tag
-	titleWithMux := titleText + muxTagStyle.Render(muxTag)
+	titleWithMux := titleText
+	if quarantineTag != "" {
+		titleWithMux += " " + quarantineStyle.Render(quarantineTag)
+	}
+	titleWithMux += muxTagStyle.Render(muxTag) + ownerTagStyle.Render(ownerTag) + todoProgressStyle.Render(todoTag) +
+		reviewStatusStyles[i.ReviewStatus].Render(reviewTag) + landStateStyles[i.LandState].Render(landTag) +
+		rateLimitStyle.Render(rateLimitTag) + disconnectedStyle.Render(disconnectTag) + promptTagStyle.Render(promptTag) + checkStyle.Render(checkTag) + ciStyle.Render(ciTag) + auxStyle.Render(auxTag)
 
 	// Calculate spacing to right-align timer info before the status icon
-	leftContentLen := len(prefix) + 1 + len(titleText) + len(muxTag)
+	leftContentLen := len(prefix) + 1 + textWidth(titleText) + quarantineWidth(quarantineTag) + len(muxTag) + textWidth(ownerTag) + len(todoTag) + len(reviewTag) + len(landTag) + len(rateLimitTag) + len(disconnectTag) + textWidth(promptTag) + len(checkTag) + textWidth(ciTag) + len(auxTag)
 	rightContentLen := timerInfoLen + 1 + iconWidth
 	spacesNeeded := r.width - leftContentLen - rightContentLen
 	if spacesNeeded < minSpacing {
@@ -485,9 +703,34 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 		)
 	}
 
+	// Commits that exist only locally, and commits on the upstream the branch lacks
+	var commits string
+	var commitsWidth int
+	if stat != nil && stat.Error == nil {
+		var counts []string
+		if stat.Unpushed > 0 {
+			style := commitCountStyle
+			if !stat.Pushed {
+				style = unpushedStyle
+			}
+			counts = append(counts, style.Background(descS.GetBackground()).Render(fmt.Sprintf("↑%d", stat.Unpushed)))
+			commitsWidth += textWidth(fmt.Sprintf("↑%d", stat.Unpushed))
+		}
+		if stat.Behind > 0 {
+			counts = append(counts, commitCountStyle.Background(descS.GetBackground()).Render(fmt.Sprintf("↓%d", stat.Behind)))
+			commitsWidth += textWidth(fmt.Sprintf("↓%d", stat.Behind))
+		}
+		if len(counts) > 0 {
+			space := lipgloss.Style{}.Background(descS.GetBackground()).Render(" ")
+			commits = strings.Join(counts, space) + space
+			commitsWidth += len(counts)
+		}
+	}
+
 	remainingWidth := r.width
 	remainingWidth -= len(prefix)
-	remainingWidth -= len(branchIcon)
+	remainingWidth -= textWidth(branchIcon)
+	remainingWidth -= commitsWidth
 
 	diffWidth := len(addedDiff) + len(removedDiff)
 	if diffWidth > 0 {
@@ -497,7 +740,19 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 	// Use fixed width for diff stats to avoid layout issues
 	remainingWidth -= diffWidth
 
+	// Show tag chips after the branch, but only if the branch keeps some room
+	chips, chipsWidth := renderTagChips(i.Tags)
+	if chipsWidth > 0 && remainingWidth-chipsWidth >= minBranchWidthWithTags {
+		remainingWidth -= chipsWidth
+	} else {
+		chips = ""
+	}
+
 	branch := i.Branch
+	if i.Scratch {
+		// Scratch sessions have no branch
+		branch = "scratch"
+	}
 	if i.Started() && hasMultipleRepos {
 		repoName, err := i.RepoName()
 		if err != nil {
@@ -509,15 +764,14 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 	// Don't show branch if there's no space for it. Or show ellipsis if it's too long.
 	if remainingWidth < 0 {
 		branch = ""
-	} else if remainingWidth < len(branch) {
+	} else if remainingWidth < textWidth(branch) {
 		if remainingWidth < 3 {
 			branch = ""
 		} else {
-			// We know the remainingWidth is at least 4 and branch is longer than that, so this is safe.
-			branch = branch[:remainingWidth-3] + "..."
+			branch = truncateLine(branch, remainingWidth)
 		}
 	}
-	remainingWidth -= len(branch)
+	remainingWidth -= textWidth(branch)
 
 	// Add spaces to fill the remaining width.
 	spaces := ""
@@ -525,7 +779,7 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 		spaces = strings.Repeat(" ", remainingWidth)
 	}
 
-	branchLine := fmt.Sprintf("%s %s-%s%s%s", strings.Repeat(" ", len(prefix)), branchIcon, branch, spaces, diff)
+	branchLine := fmt.Sprintf("%s %s-%s%s%s%s%s", strings.Repeat(" ", len(prefix)), branchIcon, branch, chips, spaces, diff, commits)
 
 	// Build summary line if available and not degraded
 	var summaryLine string
@@ -533,9 +787,9 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 		summaryText := i.Summary
 		// Truncate summary if too long
 		maxSummaryWidth := r.width - len(prefix) - 2
-		if maxSummaryWidth > 0 && len(summaryText) > maxSummaryWidth {
+		if maxSummaryWidth > 0 && textWidth(summaryText) > maxSummaryWidth {
 			if maxSummaryWidth > 3 {
-				summaryText = summaryText[:maxSummaryWidth-3] + "..."
+				summaryText = truncateLine(summaryText, maxSummaryWidth)
 			} else {
 				summaryText = ""
 			}
@@ -576,6 +830,24 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 	return text
 }
 
+// minBranchWidthWithTags is the minimum space left for the branch name when showing tag chips.
+const minBranchWidthWithTags = 12
+
+// renderTagChips renders tags as colored chips and returns them with their printable width.
+func renderTagChips(tags []string) (string, int) {
+	var chips strings.Builder
+	width := 0
+	for _, tag := range tags {
+		h := fnv.New32a()
+		h.Write([]byte(tag))
+		color := tagChipColors[h.Sum32()%uint32(len(tagChipColors))]
+		chips.WriteString(" ")
+		chips.WriteString(tagChipStyle.Background(color).Render(tag))
+		width += textWidth(tag) + 3 // leading space and padding
+	}
+	return chips.String(), width
+}
+
 func (l *List) String() string {
 	titleText := " Instances "
 	const autoYesText = " auto-yes "
@@ -632,7 +904,9 @@ func (l *List) String() string {
 		b.WriteString(scrollIndicatorStyle.Render(scrollInfo))
 	}
 
-	return lipgloss.Place(l.width, l.height, lipgloss.Left, lipgloss.Top, b.String())
+	// Rows that don't fit are cut rather than pushing the preview aside
+	return lipgloss.NewStyle().MaxWidth(l.width).MaxHeight(l.height).Render(
+		lipgloss.Place(l.width, l.height, lipgloss.Left, lipgloss.Top, b.String()))
 }
 
 // Down selects the next item in the list. Wraps to the first item if at the end.
@@ -658,6 +932,32 @@ func (l *List) Kill() {
 	}
 	targetInstance := visibleItems[l.selectedIdx]
 
+	// If you delete the last one in the visible list, select the previous one.
+	if l.selectedIdx == len(visibleItems)-1 {
+		defer l.Up()
+	}
+
+	l.KillInstance(targetInstance)
+}
+
+// KillInstance removes the given instance from the list and kills it asynchronously.
+// Unlike Kill, it does not need to be selected or visible (e.g. tombstoned instances).
+func (l *List) KillInstance(targetInstance *session.Instance) {
+	if !l.RemoveInstance(targetInstance) {
+		return
+	}
+
+	// Kill the zellij session and git worktree asynchronously to avoid blocking the UI.
+	go func() {
+		if err := targetInstance.Kill(l.trashRetentionDays); err != nil {
+			log.ErrorLog.Printf("could not kill instance: %v", err)
+		}
+	}()
+}
+
+// RemoveInstance removes the given instance from the list without killing it.
+// Returns false if it isn't in the list.
+func (l *List) RemoveInstance(targetInstance *session.Instance) bool {
 	// Find the actual index in the full items list
 	actualIdx := -1
 	for i, item := range l.items {
@@ -669,7 +969,7 @@ func (l *List) Kill() {
 
 	if actualIdx == -1 {
 		log.ErrorLog.Printf("could not find instance in items list")
-		return
+		return false
 	}
 
 	// Unregister the reponame first (before removing from list).
@@ -680,20 +980,10 @@ func (l *List) Kill() {
 		l.rmRepo(repoName)
 	}
 
-	// If you delete the last one in the visible list, select the previous one.
-	if l.selectedIdx == len(visibleItems)-1 {
-		defer l.Up()
-	}
-
 	// Remove from the actual items list immediately.
 	l.items = append(l.items[:actualIdx], l.items[actualIdx+1:]...)
-
-	// Kill the zellij session and git worktree asynchronously to avoid blocking the UI.
-	go func() {
-		if err := targetInstance.Kill(); err != nil {
-			log.ErrorLog.Printf("could not kill instance: %v", err)
-		}
-	}()
+	delete(l.previewSizes, targetInstance)
+	return true
 }
 
 func (l *List) Attach() (chan struct{}, error) {
@@ -835,10 +1125,11 @@ func (l *List) GetSelectedInstance() *session.Instance {
 // SetSelectedInstance sets the selected index. Noop if the index is out of bounds.
 func (l *List) SetSelectedInstance(idx int) {
 	visibleItems := l.GetVisibleInstances()
-	if idx >= len(visibleItems) {
+	if idx < 0 || idx >= len(visibleItems) {
 		return
 	}
 	l.selectedIdx = idx
+	l.adjustScroll()
 }
 
 // RemoveSelectedFromView adjusts the selection after archiving/unarchiving
@@ -850,6 +1141,32 @@ func (l *List) RemoveSelectedFromView() {
 	}
 }
 
+// SelectInstance selects the instance, switching to the ALL or ARCHIVED filter
+// if the current filter hides it. Returns false if the instance isn't shown in
+// any filter, e.g. because it was killed.
+func (l *List) SelectInstance(instance *session.Instance) bool {
+	if l.selectVisible(instance) {
+		return true
+	}
+	if instance.Archived {
+		l.setFilterTab(filterTab{mode: FilterArchived})
+	} else {
+		l.setFilterTab(filterTab{mode: FilterAll})
+	}
+	return l.selectVisible(instance)
+}
+
+// selectVisible selects the instance if the current filter shows it.
+func (l *List) selectVisible(instance *session.Instance) bool {
+	for i, item := range l.GetVisibleInstances() {
+		if item == instance {
+			l.SetSelectedInstance(i)
+			return true
+		}
+	}
+	return false
+}
+
 // GetInstances returns all instances in the list
 func (l *List) GetInstances() []*session.Instance {
 	return l.items
@@ -859,40 +1176,122 @@ func (l *List) GetInstances() []*session.Instance {
 func (l *List) GetVisibleInstances() []*session.Instance {
 	var visible []*session.Instance
 	for _, item := range l.items {
+		// Tombstoned instances are pending deletion and never shown
+		if item.Tombstoned() {
+			continue
+		}
 		switch l.filterMode {
 		case FilterAll:
 			if !item.Archived {
 				visible = append(visible, item)
 			}
 		case FilterNeedsAttention:
-			if !item.Archived && item.Status == session.Ready {
+			if !item.Archived && item.NeedsAttention() {
 				visible = append(visible, item)
 			}
 		case FilterArchived:
 			if item.Archived {
 				visible = append(visible, item)
 			}
+		case FilterTag:
+			if !item.Archived && item.HasTag(l.filterTag) {
+				visible = append(visible, item)
+			}
+		case FilterMine:
+			if !item.Archived && l.isMine(item) {
+				visible = append(visible, item)
+			}
+		case FilterOthers:
+			if !item.Archived && l.isOthers(item) {
+				visible = append(visible, item)
+			}
 		}
 	}
 	return visible
 }
 
-// NextFilter advances to the next filter mode (cycles through ALL -> NEEDS ATTENTION -> ARCHIVED -> ALL)
-func (l *List) NextFilter() {
-	l.filterMode = (l.filterMode + 1) % 3
+// getTags returns the sorted tags of all non-archived instances. The tag being
+// filtered on is always included so its tab doesn't disappear while selected.
+func (l *List) getTags() []string {
+	seen := make(map[string]bool)
+	var tags []string
+	if l.filterMode == FilterTag {
+		seen[l.filterTag] = true
+		tags = append(tags, l.filterTag)
+	}
+	for _, item := range l.items {
+		if item.Tombstoned() || item.Archived {
+			continue
+		}
+		for _, tag := range item.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// isMine returns true if the instance was created by the current identity.
+func (l *List) isMine(item *session.Instance) bool {
+	return item.Owner == l.identity
+}
+
+// isOthers returns true if the instance was created by another identity.
+// Instances created before an identity was set belong to nobody.
+func (l *List) isOthers(item *session.Instance) bool {
+	return item.Owner != "" && item.Owner != l.identity
+}
+
+// filterTab is one tab of the filter bar.
+type filterTab struct {
+	mode FilterMode
+	tag  string
+}
+
+// filterTabs returns the tabs in order: ALL, ATTENTION, ARCHIVED, MINE and
+// OTHERS if an identity is set, then one tab per tag.
+func (l *List) filterTabs() []filterTab {
+	tabs := []filterTab{{mode: FilterAll}, {mode: FilterNeedsAttention}, {mode: FilterArchived}}
+	if l.identity != "" {
+		tabs = append(tabs, filterTab{mode: FilterMine}, filterTab{mode: FilterOthers})
+	}
+	for _, tag := range l.getTags() {
+		tabs = append(tabs, filterTab{mode: FilterTag, tag: tag})
+	}
+	return tabs
+}
+
+// filterIndex returns the position of the current filter in the tabs.
+func (l *List) filterIndex(tabs []filterTab) int {
+	for i, tab := range tabs {
+		if tab.mode == l.filterMode && tab.tag == l.filterTag {
+			return i
+		}
+	}
+	return 0
+}
+
+// setFilterTab selects the filter of the tab.
+func (l *List) setFilterTab(tab filterTab) {
+	l.filterMode = tab.mode
+	l.filterTag = tab.tag
 	l.selectedIdx = 0  // Reset selection when filter changes
 	l.scrollOffset = 0 // Reset scroll when filter changes
 }
 
+// NextFilter advances to the next filter mode (cycles through ALL -> NEEDS ATTENTION -> ARCHIVED -> MINE -> OTHERS -> tags -> ALL)
+func (l *List) NextFilter() {
+	tabs := l.filterTabs()
+	l.setFilterTab(tabs[(l.filterIndex(tabs)+1)%len(tabs)])
+}
+
 // PrevFilter goes to the previous filter mode (cycles backwards)
 func (l *List) PrevFilter() {
-	if l.filterMode == 0 {
-		l.filterMode = FilterArchived
-	} else {
-		l.filterMode--
-	}
-	l.selectedIdx = 0  // Reset selection when filter changes
-	l.scrollOffset = 0 // Reset scroll when filter changes
+	tabs := l.filterTabs()
+	l.setFilterTab(tabs[(l.filterIndex(tabs)+len(tabs)-1)%len(tabs)])
 }
 
 // GetFilterName returns a human-readable name for the current filter
@@ -902,6 +1301,12 @@ func (l *List) GetFilterName() string {
 		return "NEEDS ATTENTION"
 	case FilterArchived:
 		return "ARCHIVED"
+	case FilterTag:
+		return "#" + l.filterTag
+	case FilterMine:
+		return "MINE"
+	case FilterOthers:
+		return "OTHERS"
 	default:
 		return "ALL"
 	}
@@ -910,11 +1315,14 @@ func (l *List) GetFilterName() string {
 // getFilterCounts returns counts for all, needs attention, and archived
 func (l *List) getFilterCounts() (all, attention, archived int) {
 	for _, item := range l.items {
+		if item.Tombstoned() {
+			continue
+		}
 		if item.Archived {
 			archived++
 		} else {
 			all++
-			if item.Status == session.Ready {
+			if item.NeedsAttention() {
 				attention++
 			}
 		}
@@ -922,39 +1330,68 @@ func (l *List) getFilterCounts() (all, attention, archived int) {
 	return
 }
 
-// renderFilterTabs renders the filter tabs with counts
+// renderFilterTabs renders the filter tabs with counts. Tabs are left out at
+// the ends while they don't fit the width of the list, keeping the active one.
 func (l *List) renderFilterTabs() string {
 	allCount, attentionCount, archivedCount := l.getFilterCounts()
 
 	var tabs []string
-
-	// ALL tab
-	allLabel := fmt.Sprintf("ALL(%d)", allCount)
-	if l.filterMode == FilterAll {
-		tabs = append(tabs, filterActiveStyle.Render(allLabel))
-	} else {
-		tabs = append(tabs, filterInactiveStyle.Render(allLabel))
+	active := 0
+	addTab := func(label string, isActive bool) {
+		if isActive {
+			active = len(tabs)
+			tabs = append(tabs, filterActiveStyle.Render(label))
+		} else {
+			tabs = append(tabs, filterInactiveStyle.Render(label))
+		}
 	}
 
-	// ATTENTION tab
-	attentionLabel := fmt.Sprintf("ATTENTION(%d)", attentionCount)
-	if l.filterMode == FilterNeedsAttention {
-		tabs = append(tabs, filterActiveStyle.Render(attentionLabel))
-	} else {
-		tabs = append(tabs, filterInactiveStyle.Render(attentionLabel))
+	addTab(fmt.Sprintf("ALL(%d)", allCount), l.filterMode == FilterAll)
+	addTab(fmt.Sprintf("ATTENTION(%d)", attentionCount), l.filterMode == FilterNeedsAttention)
+	addTab(fmt.Sprintf("ARCHIVED(%d)", archivedCount), l.filterMode == FilterArchived)
+
+	// MINE and OTHERS tabs on shared machines
+	if l.identity != "" {
+		mineCount, othersCount := 0, 0
+		for _, item := range l.items {
+			if item.Tombstoned() || item.Archived {
+				continue
+			}
+			if l.isMine(item) {
+				mineCount++
+			} else if l.isOthers(item) {
+				othersCount++
+			}
+		}
+		addTab(fmt.Sprintf("MINE(%d)", mineCount), l.filterMode == FilterMine)
+		addTab(fmt.Sprintf("OTHERS(%d)", othersCount), l.filterMode == FilterOthers)
 	}
 
-	// ARCHIVED tab
-	archivedLabel := fmt.Sprintf("ARCHIVED(%d)", archivedCount)
-	if l.filterMode == FilterArchived {
-		tabs = append(tabs, filterActiveStyle.Render(archivedLabel))
-	} else {
-		tabs = append(tabs, filterInactiveStyle.Render(archivedLabel))
+	// One tab per tag
+	for _, tag := range l.getTags() {
+		count := 0
+		for _, item := range l.items {
+			if !item.Tombstoned() && !item.Archived && item.HasTag(tag) {
+				count++
+			}
+		}
+		addTab(fmt.Sprintf("#%s(%d)", tag, count), l.filterMode == FilterTag && l.filterTag == tag)
 	}
 
 	// Join with arrows
 	separator := filterStyle.Render(" ◀ ")
-	return " " + strings.Join(tabs, separator) + filterStyle.Render(" ▶")
+	first, last := 0, len(tabs)
+	render := func() string {
+		return " " + strings.Join(tabs[first:last], separator) + filterStyle.Render(" ▶")
+	}
+	for l.width > 0 && lipgloss.Width(render()) > l.width && last-first > 1 {
+		if first < active {
+			first++
+		} else {
+			last--
+		}
+	}
+	return render()
 }
 
 // ShowingArchived returns true if currently showing archived instances
@@ -965,6 +1402,7 @@ func (l *List) ShowingArchived() bool {
 // ResetFilter resets the filter mode to show all non-archived instances
 func (l *List) ResetFilter() {
 	l.filterMode = FilterAll
+	l.filterTag = ""
 	l.scrollOffset = 0
 }
 
@@ -1048,3 +1486,12 @@ func (l *List) MergeInstances(diskInstances []*session.Instance) bool {
 
 	return changed
 }
+
+// quarantineWidth returns the width of the quarantine badge and the space
+// before it, or 0 if there is no badge.
+func quarantineWidth(tag string) int {
+	if tag == "" {
+		return 0
+	}
+	return len(tag) + 1
+}
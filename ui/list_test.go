@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestTagFilterCycling(t *testing.T) {
+	s := spinner.New()
+	l := NewList(&s, false)
+	l.items = []*session.Instance{
+		{Title: "api", Tags: []string{"backend"}},
+		{Title: "web", Tags: []string{"frontend", "blocked"}},
+		{Title: "old", Tags: []string{"legacy"}, Archived: true},
+	}
+
+	// Tags of non-archived instances follow the built-in filters in sorted order
+	wantOrder := []string{"NEEDS ATTENTION", "ARCHIVED", "#backend", "#blocked", "#frontend", "ALL"}
+	for _, want := range wantOrder {
+		l.NextFilter()
+		if got := l.GetFilterName(); got != want {
+			t.Fatalf("after NextFilter() filter = %q, want %q", got, want)
+		}
+	}
+
+	l.PrevFilter()
+	if got := l.GetFilterName(); got != "#frontend" {
+		t.Fatalf("after PrevFilter() filter = %q, want %q", got, "#frontend")
+	}
+	visible := l.GetVisibleInstances()
+	if len(visible) != 1 || visible[0].Title != "web" {
+		t.Errorf("visible instances for #frontend = %v, want [web]", visible)
+	}
+}
+
+func TestFilterTabsFitWidth(t *testing.T) {
+	s := spinner.New()
+	l := NewList(&s, false)
+	l.SetSize(30, 20)
+	l.items = []*session.Instance{
+		{Title: "api", Tags: []string{"backend"}},
+		{Title: "web", Tags: []string{"frontend"}},
+	}
+
+	// The active tab stays in view while the others are left out
+	for l.GetFilterName() != "#frontend" {
+		l.NextFilter()
+	}
+	tabs := l.renderFilterTabs()
+	if width := lipgloss.Width(tabs); width > 30 {
+		t.Errorf("filter tabs are %d columns wide, want at most 30: %q", width, tabs)
+	}
+	if !strings.Contains(tabs, "#frontend(1)") {
+		t.Errorf("filter tabs %q don't show the active tab", tabs)
+	}
+	if width := lipgloss.Width(l.String()); width > 30 {
+		t.Errorf("list is %d columns wide, want at most 30", width)
+	}
+}
+
+func TestIdentityFilters(t *testing.T) {
+	s := spinner.New()
+	l := NewList(&s, false)
+	l.items = []*session.Instance{
+		{Title: "mine", Owner: "alice"},
+		{Title: "theirs", Owner: "bob"},
+		{Title: "legacy"},
+	}
+
+	// Without an identity there are no MINE and OTHERS tabs
+	l.NextFilter()
+	l.NextFilter()
+	l.NextFilter()
+	if got := l.GetFilterName(); got != "ALL" {
+		t.Fatalf("filter without identity = %q, want ALL", got)
+	}
+
+	l.SetIdentity("alice")
+	wantVisible := map[string]string{"MINE": "mine", "OTHERS": "theirs"}
+	for _, want := range []string{"NEEDS ATTENTION", "ARCHIVED", "MINE", "OTHERS", "ALL"} {
+		l.NextFilter()
+		if got := l.GetFilterName(); got != want {
+			t.Fatalf("after NextFilter() filter = %q, want %q", got, want)
+		}
+		if title, ok := wantVisible[want]; ok {
+			visible := l.GetVisibleInstances()
+			if len(visible) != 1 || visible[0].Title != title {
+				t.Errorf("visible instances for %s = %v, want [%s]", want, visible, title)
+			}
+		}
+	}
+}
+
+// sizedSession counts how often the session is given a size.
+type sizedSession struct {
+	session.Multiplexer
+	sized int
+}
+
+func (s *sizedSession) SetDetachedSize(width, height int) error {
+	s.sized++
+	return nil
+}
+
+func (s *sizedSession) Attach() (chan struct{}, error) {
+	detached := make(chan struct{})
+	close(detached)
+	return detached, nil
+}
+
+func TestPreviewSizeGivenAgainAfterAttach(t *testing.T) {
+	log.Initialize(false)
+	s := spinner.New()
+	l := NewList(&s, false)
+	l.SetSize(40, 40)
+	sess := &sizedSession{}
+	instance := &session.Instance{Title: "api"}
+	instance.SetSession(sess)
+	instance.MarkAsStartedForTesting()
+	l.items = []*session.Instance{instance}
+
+	if err := l.SetSessionPreviewSize(80, 24); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetSessionPreviewSize(80, 24); err != nil {
+		t.Fatal(err)
+	}
+	if sess.sized != 1 {
+		t.Fatalf("the session was sized %d times, want once for the same size", sess.sized)
+	}
+
+	// Attaching gives the session the size of the terminal
+	if _, err := instance.Attach(); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SizeVisiblePreviews(); err != nil {
+		t.Fatal(err)
+	}
+	if sess.sized != 2 {
+		t.Errorf("the session was sized %d times, want it sized again after attaching", sess.sized)
+	}
+
+	// A killed instance is forgotten
+	l.RemoveInstance(instance)
+	if _, ok := l.previewSizes[instance]; ok {
+		t.Error("the size of a removed instance is still kept")
+	}
+}
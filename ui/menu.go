@@ -49,6 +49,7 @@ type Menu struct {
 	state         MenuState
 	instance      *session.Instance
 	isInDiffTab   bool
+	isInFilesTab  bool
 
 	// keyDown is the key which is pressed. The default is -1.
 	keyDown keys.KeyName
@@ -58,6 +59,10 @@ type Menu struct {
 
 	// compactMode is true when the menu should render in a single line
 	compactMode bool
+
+	// groupSizes holds the number of options in each group (instance, action, system)
+	// when an instance is selected
+	groupSizes []int
 }
 
 var defaultMenuOptions = []keys.KeyName{keys.KeyNew, keys.KeyPrompt, keys.KeyHelp, keys.KeyQuit}
@@ -108,6 +113,12 @@ func (m *Menu) SetInDiffTab(inDiffTab bool) {
 	m.updateOptions()
 }
 
+// SetInFilesTab updates whether we're currently in the files tab
+func (m *Menu) SetInFilesTab(inFilesTab bool) {
+	m.isInFilesTab = inFilesTab
+	m.updateOptions()
+}
+
 // SetShowingArchived updates whether we're viewing archived instances
 func (m *Menu) SetShowingArchived(showingArchived bool) {
 	m.showingArchived = showingArchived
@@ -137,25 +148,49 @@ func (m *Menu) updateOptions() {
 }
 
 func (m *Menu) addInstanceOptions() {
+	// Scratch sessions have no git worktree, so git actions are hidden
+	scratch := m.instance.Scratch
+
 	// Instance management group
-	options := []keys.KeyName{keys.KeyNew, keys.KeyKill, keys.KeyRename, keys.KeyArchive, keys.KeyMoveUp, keys.KeyMoveDown}
-
-	// Action group
-	actionGroup := []keys.KeyName{keys.KeyEnter, keys.KeySubmit}
-	if m.instance.Status == session.Paused {
-		actionGroup = append(actionGroup, keys.KeyResume)
-	} else {
-		actionGroup = append(actionGroup, keys.KeyCheckout)
+	options := []keys.KeyName{keys.KeyNew, keys.KeyKill, keys.KeyRename}
+	if !scratch {
+		options = append(options, keys.KeyArchive)
+	}
+	options = append(options, keys.KeyMoveUp, keys.KeyMoveDown)
+
+	// Action group, led by the answers when the agent waits on a prompt
+	var actionGroup []keys.KeyName
+	if m.instance.PendingPrompt() != "" {
+		actionGroup = append(actionGroup, keys.KeyPromptAccept, keys.KeyPromptAlways, keys.KeyPromptReject, keys.KeyPromptReply)
+	}
+	actionGroup = append(actionGroup, keys.KeyEnter)
+	if !scratch {
+		actionGroup = append(actionGroup, keys.KeySubmit)
+		if m.instance.Status == session.Paused {
+			actionGroup = append(actionGroup, keys.KeyResume)
+		} else {
+			actionGroup = append(actionGroup, keys.KeyCheckout)
+		}
 	}
 
 	// Navigation group (when in diff tab)
 	if m.isInDiffTab {
 		actionGroup = append(actionGroup, keys.KeyShiftUp)
+		if !scratch {
+			actionGroup = append(actionGroup, keys.KeyDiffTarget)
+		}
+	}
+
+	// Navigation group (when in files tab)
+	if m.isInFilesTab && !scratch {
+		actionGroup = append(actionGroup, keys.KeyShiftUp, keys.KeyEditFile)
 	}
 
 	// System group
 	systemGroup := []keys.KeyName{keys.KeyFilterLeft, keys.KeyFilterRight, keys.KeyTab, keys.KeyHelp, keys.KeyQuit}
 
+	m.groupSizes = []int{len(options), len(actionGroup), len(systemGroup)}
+
 	// Combine all groups
 	options = append(options, actionGroup...)
 	options = append(options, systemGroup...)
@@ -234,8 +269,6 @@ func (m *Menu) String() string {
 		return m.renderCompact()
 	}
 
-	var s strings.Builder
-
 	// Define group boundaries based on current state
 	// Instance management group: n, D, R, A, K, J (6 items)
 	// Action group: enter, submit, checkout/resume, [shift+up] (3-4 items)
@@ -257,17 +290,14 @@ func (m *Menu) String() string {
 		}
 	case StateDefault:
 		// Default state with instance: n, D, R, A, K, J | enter, submit, checkout/resume | a, tab, ?, q
-		actionEnd := 9 // Base: 6 instance + 3 action
-		if m.isInDiffTab {
-			actionEnd = 10 // +1 for shift+up
-		}
-		groups = []struct {
-			start int
-			end   int
-		}{
-			{0, 6},          // Instance management group (n, D, R, A, K, J)
-			{6, actionEnd},  // Action group (enter, submit, checkout/resume, [shift+up])
-			{actionEnd, len(m.options)}, // System group (a, tab, ?, q)
+		// Group sizes are recorded by addInstanceOptions
+		start := 0
+		for _, size := range m.groupSizes {
+			groups = append(groups, struct {
+				start int
+				end   int
+			}{start, start + size})
+			start += size
 		}
 	default:
 		// NewInstance, Prompt, or Rename state
@@ -279,6 +309,7 @@ func (m *Menu) String() string {
 		}
 	}
 
+	var items, seps []string
 	for i, k := range m.options {
 		binding := keys.GlobalkeyBindings[k]
 
@@ -306,31 +337,48 @@ func (m *Menu) String() string {
 		}
 
 		if inActionGroup {
-			s.WriteString(localActionStyle.Render(binding.Help().Key))
-			s.WriteString(" ")
-			s.WriteString(localActionStyle.Render(binding.Help().Desc))
+			items = append(items, localActionStyle.Render(binding.Help().Key)+" "+localActionStyle.Render(binding.Help().Desc))
 		} else {
-			s.WriteString(localKeyStyle.Render(binding.Help().Key))
-			s.WriteString(" ")
-			s.WriteString(localDescStyle.Render(binding.Help().Desc))
+			items = append(items, localKeyStyle.Render(binding.Help().Key)+" "+localDescStyle.Render(binding.Help().Desc))
 		}
 
 		// Add appropriate separator
-		if i != len(m.options)-1 {
-			isGroupEnd := false
-			for _, group := range groups {
-				if i == group.end-1 {
-					s.WriteString(sepStyle.Render(verticalSeparator))
-					isGroupEnd = true
-					break
-				}
-			}
-			if !isGroupEnd {
-				s.WriteString(sepStyle.Render(separator))
+		sep := separator
+		for _, group := range groups {
+			if i == group.end-1 {
+				sep = verticalSeparator
+				break
 			}
 		}
+		seps = append(seps, sep)
 	}
 
-	centeredMenuText := menuStyle.Render(s.String())
+	centeredMenuText := menuStyle.Render(m.wrapItems(items, seps))
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, centeredMenuText)
 }
+
+// wrapItems joins the rendered items with the separators following them into
+// centered lines that fit the width of the menu. Items past its height are
+// left out.
+func (m *Menu) wrapItems(items, seps []string) string {
+	var lines []string
+	line := ""
+	for i, item := range items {
+		if line != "" {
+			sep := sepStyle.Render(seps[i-1])
+			if m.width <= 0 || lipgloss.Width(line+sep+item) <= m.width {
+				line += sep + item
+				continue
+			}
+			lines = append(lines, line)
+		}
+		line = item
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	if m.height > 0 && len(lines) > m.height {
+		lines = lines[:m.height]
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, lines...)
+}
@@ -0,0 +1,134 @@
+package overlay
+
+import (
+	"claude-squad/audit"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+// AuditOverlay shows the audit log, newest events first.
+type AuditOverlay struct {
+	Dismissed bool
+	// ExportRequested is set when the user asks to export the log to JSON.
+	ExportRequested bool
+	events          []audit.Event
+	offset          int
+	status          string
+	width           int
+	height          int
+}
+
+// NewAuditOverlay creates a new audit overlay with the given events, newest first.
+func NewAuditOverlay(events []audit.Event) *AuditOverlay {
+	return &AuditOverlay{
+		events: events,
+		width:  80,
+		height: 20,
+	}
+}
+
+// Events returns the events shown in the overlay.
+func (a *AuditOverlay) Events() []audit.Event {
+	return a.events
+}
+
+// rows is how many events are shown at once, leaving room for the border,
+// padding, title and footer.
+func (a *AuditOverlay) rows() int {
+	return max(a.height-8, 1)
+}
+
+// HandleKeyPress processes a key press and updates the state.
+// Returns true if the overlay should be closed.
+func (a *AuditOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	a.status = ""
+	switch msg.String() {
+	case "up", "k":
+		a.offset--
+	case "down", "j":
+		a.offset++
+	case "pgup":
+		a.offset -= a.rows()
+	case "pgdown", " ":
+		a.offset += a.rows()
+	case "home", "g":
+		a.offset = 0
+	case "end", "G":
+		a.offset = len(a.events)
+	case "x":
+		if len(a.events) > 0 {
+			a.ExportRequested = true
+		}
+	case "esc", "q":
+		a.Dismissed = true
+		return true
+	}
+	a.offset = max(min(a.offset, len(a.events)-a.rows()), 0)
+	return false
+}
+
+// SetStatus sets a short message shown in the footer until the next key press.
+func (a *AuditOverlay) SetStatus(status string) {
+	a.status = status
+}
+
+// Render renders the audit overlay
+func (a *AuditOverlay) Render(opts ...WhitespaceOption) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF"))
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA"))
+
+	statusStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7aa2f7")).
+		Bold(true)
+
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+
+	// Leave room for the border and padding
+	lineWidth := max(a.width-6, 10)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Audit log"))
+	content.WriteString("\n\n")
+
+	if len(a.events) == 0 {
+		content.WriteString(normalStyle.Render("Nothing recorded yet. Creating, prompting, pushing and killing sessions shows up here."))
+		content.WriteString("\n\n")
+		content.WriteString(hintStyle.Render("[Esc] Close"))
+	} else {
+		end := min(a.offset+a.rows(), len(a.events))
+		for _, event := range a.events[a.offset:end] {
+			content.WriteString(normalStyle.Render(truncate.StringWithTail(event.String(), uint(lineWidth), "...")))
+			content.WriteString("\n")
+		}
+		content.WriteString(hintStyle.Render(fmt.Sprintf("%d-%d of %d", a.offset+1, end, len(a.events))))
+		content.WriteString("\n\n")
+
+		if a.status != "" {
+			content.WriteString(statusStyle.Render(a.status))
+		} else {
+			content.WriteString(hintStyle.Render("[x] Export to JSON  [Esc] Close  [↑/↓/PgUp/PgDn] Scroll"))
+		}
+	}
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7aa2f7")).
+		Padding(1, 2).
+		Width(a.width)
+
+	return borderStyle.Render(content.String())
+}
+
+// SetSize sets the size of the overlay
+func (a *AuditOverlay) SetSize(width, height int) {
+	a.width = width
+	a.height = height
+}
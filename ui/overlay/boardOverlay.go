@@ -0,0 +1,214 @@
+package overlay
+
+import (
+	"claude-squad/session"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+// BoardOverlay shows the squad as a board with a column per stage and a card
+// per instance. Cards can be moved between columns.
+type BoardOverlay struct {
+	Dismissed bool
+	// Selected is the instance picked with enter, if any
+	Selected *session.Instance
+	// Moved is true once a card has been moved
+	Moved bool
+
+	instances []*session.Instance
+	columns   [][]*session.Instance
+	column    int
+	row       int
+	err       string
+	width     int
+	height    int
+}
+
+// NewBoardOverlay creates a board of the instances.
+func NewBoardOverlay(instances []*session.Instance) *BoardOverlay {
+	b := &BoardOverlay{
+		instances: instances,
+		width:     100,
+		height:    30,
+	}
+	b.layout()
+	return b
+}
+
+// layout sorts the cards into their columns, keeping the list order within a column.
+func (b *BoardOverlay) layout() {
+	b.columns = make([][]*session.Instance, len(session.BoardColumns))
+	for _, instance := range b.instances {
+		if instance == nil || instance.Tombstoned() {
+			continue
+		}
+		for i, column := range session.BoardColumns {
+			if instance.Column() == column {
+				b.columns[i] = append(b.columns[i], instance)
+				break
+			}
+		}
+	}
+	b.clampRow()
+}
+
+// clampRow keeps the cursor on a card of the current column.
+func (b *BoardOverlay) clampRow() {
+	b.row = max(0, min(b.row, len(b.columns[b.column])-1))
+}
+
+// current returns the card under the cursor, or nil if its column is empty.
+func (b *BoardOverlay) current() *session.Instance {
+	cards := b.columns[b.column]
+	if b.row >= len(cards) {
+		return nil
+	}
+	return cards[b.row]
+}
+
+// HandleKeyPress processes a key press.
+// Returns true if the overlay should be closed.
+func (b *BoardOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	b.err = ""
+	switch msg.String() {
+	case "left", "h":
+		if b.column > 0 {
+			b.column--
+			b.clampRow()
+		}
+	case "right", "l":
+		if b.column < len(b.columns)-1 {
+			b.column++
+			b.clampRow()
+		}
+	case "up", "k":
+		if b.row > 0 {
+			b.row--
+		}
+	case "down", "j":
+		if b.row < len(b.columns[b.column])-1 {
+			b.row++
+		}
+	case "shift+left", "H":
+		b.move(-1)
+	case "shift+right", "L":
+		b.move(1)
+	case "enter":
+		if b.current() == nil {
+			return false
+		}
+		b.Selected = b.current()
+		b.Dismissed = true
+		return true
+	case "esc", "q", "B":
+		b.Dismissed = true
+		return true
+	}
+	return false
+}
+
+// move moves the card under the cursor to the adjacent column and follows it.
+func (b *BoardOverlay) move(delta int) {
+	card := b.current()
+	target := b.column + delta
+	if card == nil || target < 0 || target >= len(session.BoardColumns) {
+		return
+	}
+	if !card.MoveToColumn(session.BoardColumns[target]) {
+		b.err = "Archived sessions are archived and restored from the list"
+		return
+	}
+	b.Moved = true
+	b.column = target
+	b.layout()
+	for i, instance := range b.columns[b.column] {
+		if instance == card {
+			b.row = i
+		}
+	}
+}
+
+// Render renders the board overlay
+func (b *BoardOverlay) Render(opts ...WhitespaceOption) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF"))
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#7aa2f7"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#1a1b26")).
+		Background(lipgloss.Color("#7aa2f7")).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA"))
+
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f7768e"))
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+
+	// Leave room for the border, padding and the gaps between columns
+	columnWidth := max((b.width-6-len(b.columns)+1)/len(b.columns), 8)
+	// Leave room for the title, headers, hints and border
+	maxCards := max(b.height-10, 1)
+
+	var columns []string
+	for i, cards := range b.columns {
+		lines := []string{
+			headerStyle.Render(truncate.StringWithTail(fmt.Sprintf("%s (%d)", session.BoardColumns[i].Title(), len(cards)), uint(columnWidth), "...")),
+			hintStyle.Render(strings.Repeat("─", columnWidth)),
+		}
+
+		// Scroll the column under the cursor to keep the cursor visible
+		start := 0
+		if i == b.column && b.row >= maxCards {
+			start = b.row - maxCards + 1
+		}
+		end := min(start+maxCards, len(cards))
+		for j := start; j < end; j++ {
+			style := normalStyle
+			if i == b.column && j == b.row {
+				style = selectedStyle
+			}
+			lines = append(lines, style.Render(truncate.StringWithTail(cards[j].Title, uint(columnWidth), "...")))
+		}
+		if hidden := len(cards) - end + start; hidden > 0 {
+			lines = append(lines, hintStyle.Render(fmt.Sprintf("+%d more", hidden)))
+		}
+		columns = append(columns, lipgloss.NewStyle().Width(columnWidth).Render(strings.Join(lines, "\n")))
+		if i < len(b.columns)-1 {
+			columns = append(columns, " ")
+		}
+	}
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Board"))
+	content.WriteString("\n\n")
+	content.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, columns...))
+	content.WriteString("\n\n")
+	if b.err != "" {
+		content.WriteString(errStyle.Render(b.err))
+		content.WriteString("\n")
+	}
+	content.WriteString(hintStyle.Render("[←/→] Column  [↑/↓] Card  [H/L] Move card  [Enter] Select  [Esc] Close"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7aa2f7")).
+		Padding(1, 2).
+		Width(b.width)
+
+	return borderStyle.Render(content.String())
+}
+
+// SetSize sets the size of the overlay
+func (b *BoardOverlay) SetSize(width, height int) {
+	b.width = width
+	b.height = height
+}
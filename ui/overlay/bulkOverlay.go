@@ -0,0 +1,172 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+// bulkResultRows is how many results are listed at once.
+const bulkResultRows = 12
+
+// bulkResult is the outcome of the operation for one instance.
+type bulkResult struct {
+	title string
+	err   error
+}
+
+// BulkOverlay shows the progress of an operation on many instances at once,
+// like pausing all of them, and the result for each instance.
+type BulkOverlay struct {
+	Dismissed bool
+
+	// action describes the operation, e.g. "Pausing"
+	action string
+	// note explains the operation below the title
+	note    string
+	total   int
+	results []bulkResult
+	width   int
+}
+
+// NewBulkOverlay creates an overlay for an operation, described by action like
+// "Pausing", on total instances.
+func NewBulkOverlay(action string, total int) *BulkOverlay {
+	return &BulkOverlay{action: action, total: total, width: 60}
+}
+
+// SetNote sets a note explaining the operation, shown below the title.
+func (b *BulkOverlay) SetNote(note string) {
+	b.note = note
+}
+
+// Finished records that the operation finished for the instance, with the
+// error if it failed.
+func (b *BulkOverlay) Finished(title string, err error) {
+	b.results = append(b.results, bulkResult{title: title, err: err})
+}
+
+// Done returns true once the operation has finished for every instance.
+func (b *BulkOverlay) Done() bool {
+	return len(b.results) >= b.total
+}
+
+// Failed returns for how many instances the operation failed.
+func (b *BulkOverlay) Failed() int {
+	failed := 0
+	for _, result := range b.results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// HandleKeyPress processes a key press and updates the state.
+// Returns true if the overlay should be closed.
+func (b *BulkOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "esc", "q", "enter":
+		b.Dismissed = true
+		return true
+	}
+	return false
+}
+
+// Render renders the bulk overlay
+func (b *BulkOverlay) Render(opts ...WhitespaceOption) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF"))
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA"))
+
+	progressStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7aa2f7"))
+
+	successStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#9ece6a"))
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#f7768e"))
+
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+
+	// Leave room for the border and padding
+	lineWidth := max(b.width-6, 10)
+
+	var content strings.Builder
+	if !b.Done() {
+		content.WriteString(titleStyle.Render(fmt.Sprintf("%s sessions", b.action)))
+		content.WriteString("\n\n")
+		barWidth := max(lineWidth-12, 10)
+		filled := barWidth * len(b.results) / max(b.total, 1)
+		content.WriteString(progressStyle.Render(strings.Repeat("█", filled)))
+		content.WriteString(hintStyle.Render(strings.Repeat("░", barWidth-filled)))
+		content.WriteString(normalStyle.Render(fmt.Sprintf(" %d/%d", len(b.results), b.total)))
+		content.WriteString("\n")
+	} else {
+		content.WriteString(titleStyle.Render(fmt.Sprintf("%s sessions: %d done, %d failed",
+			b.action, b.total-b.Failed(), b.Failed())))
+		content.WriteString("\n")
+	}
+	if b.note != "" {
+		content.WriteString(normalStyle.Width(lineWidth).Render(b.note))
+		content.WriteString("\n")
+	}
+
+	if len(b.results) > 0 {
+		content.WriteString("\n")
+		// Failures are listed first, so they aren't cut off
+		var results []bulkResult
+		for _, result := range b.results {
+			if result.err != nil {
+				results = append(results, result)
+			}
+		}
+		for _, result := range b.results {
+			if result.err == nil {
+				results = append(results, result)
+			}
+		}
+		for i, result := range results {
+			if i == bulkResultRows {
+				content.WriteString(normalStyle.Render(fmt.Sprintf("… %d more", len(results)-bulkResultRows)))
+				content.WriteString("\n")
+				break
+			}
+			if result.err != nil {
+				line := fmt.Sprintf("✗ %s: %v", result.title, result.err)
+				content.WriteString(errorStyle.Render(truncate.StringWithTail(line, uint(lineWidth), "...")))
+			} else {
+				line := fmt.Sprintf("✓ %s", result.title)
+				content.WriteString(successStyle.Render(truncate.StringWithTail(line, uint(lineWidth), "...")))
+			}
+			content.WriteString("\n")
+		}
+	}
+
+	content.WriteString("\n")
+	if b.Done() {
+		content.WriteString(hintStyle.Render("[Esc] Close"))
+	} else {
+		content.WriteString(hintStyle.Render("[Esc] Continue in the background"))
+	}
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7aa2f7")).
+		Padding(1, 2).
+		Width(b.width)
+
+	return borderStyle.Render(content.String())
+}
+
+// SetWidth sets the width of the overlay
+func (b *BulkOverlay) SetWidth(width int) {
+	b.width = width
+}
@@ -21,6 +21,11 @@ type ConfirmationOverlay struct {
 	ConfirmKey string
 	// Custom cancel key (defaults to 'n')
 	CancelKey string
+	// Optional key that closes the overlay to edit the action before running
+	// it. Unused if empty.
+	EditKey string
+	// Callback function to be called when the user presses the edit key
+	OnEdit func()
 	// Custom styling options
 	borderColor lipgloss.Color
 }
@@ -47,6 +52,15 @@ func (c *ConfirmationOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
 			c.OnConfirm()
 		}
 		return true
+	case c.EditKey:
+		if c.EditKey == "" {
+			return false
+		}
+		c.Dismissed = true
+		if c.OnEdit != nil {
+			c.OnEdit()
+		}
+		return true
 	case c.CancelKey, "esc":
 		c.Dismissed = true
 		if c.OnCancel != nil {
@@ -69,8 +83,11 @@ func (c *ConfirmationOverlay) Render(opts ...WhitespaceOption) string {
 
 	// Add the confirmation instructions
 	content := c.message + "\n\n" +
-		"Press " + lipgloss.NewStyle().Bold(true).Render(c.ConfirmKey) + " to confirm, " +
-		lipgloss.NewStyle().Bold(true).Render(c.CancelKey) + " or " +
+		"Press " + lipgloss.NewStyle().Bold(true).Render(c.ConfirmKey) + " to confirm, "
+	if c.EditKey != "" {
+		content += lipgloss.NewStyle().Bold(true).Render(c.EditKey) + " to edit, "
+	}
+	content += lipgloss.NewStyle().Bold(true).Render(c.CancelKey) + " or " +
 		lipgloss.NewStyle().Bold(true).Render("esc") + " to cancel"
 
 	// Apply the border style and return
@@ -0,0 +1,195 @@
+package overlay
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+// filePickerMatch is a file matching the query.
+type filePickerMatch struct {
+	path  string
+	score int
+}
+
+// FilePickerOverlay picks a file by fuzzy matching its path relative to a
+// directory. Typing the path of an existing file picks that file instead.
+type FilePickerOverlay struct {
+	Dismissed bool
+	// Selected is the absolute path of the file picked with enter, if any
+	Selected string
+
+	title   string
+	root    string
+	files   []string
+	input   textinput.Model
+	matches []filePickerMatch
+	cursor  int
+	offset  int
+	width   int
+}
+
+// NewFilePickerOverlay creates a picker of the files, given relative to root.
+func NewFilePickerOverlay(title, root string, files []string) *FilePickerOverlay {
+	ti := textinput.New()
+	ti.Placeholder = "file name or path"
+	ti.Prompt = "> "
+	ti.CharLimit = 0
+	ti.Focus()
+
+	p := &FilePickerOverlay{title: title, root: root, files: files, input: ti, width: 80}
+	p.filter()
+	return p
+}
+
+// HandleKeyPress processes a key press and updates the state.
+// Returns true if the overlay should be closed.
+func (p *FilePickerOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		p.Dismissed = true
+		return true
+	case "enter":
+		if path := p.typedPath(); path != "" {
+			p.Selected = path
+		} else if len(p.matches) > 0 {
+			p.Selected = filepath.Join(p.root, p.matches[p.cursor].path)
+		} else {
+			return false
+		}
+		p.Dismissed = true
+		return true
+	case "up", "ctrl+p", "ctrl+k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "ctrl+n", "ctrl+j":
+		if p.cursor < len(p.matches)-1 {
+			p.cursor++
+		}
+	default:
+		query := p.input.Value()
+		p.input, _ = p.input.Update(msg)
+		if p.input.Value() != query {
+			p.filter()
+		}
+	}
+
+	// Keep the cursor within the visible rows
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	} else if p.cursor >= p.offset+pickerRows {
+		p.offset = p.cursor - pickerRows + 1
+	}
+	return false
+}
+
+// typedPath returns the query as the path of an existing file, relative to the
+// root unless absolute, or "" if there is no such file.
+func (p *FilePickerOverlay) typedPath() string {
+	path := strings.TrimSpace(p.input.Value())
+	if path == "" {
+		return ""
+	}
+	if rest, ok := strings.CutPrefix(path, "~"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		path = home + rest
+	} else if !filepath.IsAbs(path) {
+		path = filepath.Join(p.root, path)
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return ""
+	}
+	return path
+}
+
+// filter matches the files against the query, best matches first. Without a
+// query all files are shown in order.
+func (p *FilePickerOverlay) filter() {
+	query := strings.TrimSpace(p.input.Value())
+	p.matches = p.matches[:0]
+	for _, file := range p.files {
+		if query == "" {
+			p.matches = append(p.matches, filePickerMatch{path: file})
+			continue
+		}
+		// File names are what people remember, so they weigh more than directories
+		best, matched := 0, false
+		for weight, text := range map[int]string{2: filepath.Base(file), 1: file} {
+			if score, ok := fuzzyScore(query, text); ok && (!matched || score*weight > best) {
+				best, matched = score*weight, true
+			}
+		}
+		if matched {
+			p.matches = append(p.matches, filePickerMatch{path: file, score: best})
+		}
+	}
+	if query != "" {
+		sort.SliceStable(p.matches, func(i, j int) bool { return p.matches[i].score > p.matches[j].score })
+	}
+	p.cursor, p.offset = 0, 0
+}
+
+// Render renders the file picker overlay
+func (p *FilePickerOverlay) Render(opts ...WhitespaceOption) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7aa2f7")).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA"))
+
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+
+	// Leave room for the border, padding and the cursor prefix
+	lineWidth := max(p.width-8, 10)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(p.title))
+	content.WriteString("\n\n")
+	content.WriteString(p.input.View())
+	content.WriteString("\n\n")
+
+	if len(p.matches) == 0 {
+		content.WriteString(normalStyle.Render("No matching files, enter a path to load it"))
+		content.WriteString("\n")
+	}
+	end := min(p.offset+pickerRows, len(p.matches))
+	for i := p.offset; i < end; i++ {
+		path := truncate.StringWithTail(p.matches[i].path, uint(lineWidth), "...")
+		if i == p.cursor {
+			content.WriteString("> " + selectedStyle.Render(path))
+		} else {
+			content.WriteString("  " + normalStyle.Render(path))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+	content.WriteString(hintStyle.Render("[Enter] Load  [Esc] Close  [↑/↓] Navigate"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7aa2f7")).
+		Padding(1, 2).
+		Width(p.width)
+
+	return borderStyle.Render(content.String())
+}
+
+// SetWidth sets the width of the overlay
+func (p *FilePickerOverlay) SetWidth(width int) {
+	p.width = width
+}
@@ -8,8 +8,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/truncate"
 )
 
 // FileEntry represents a file or directory in the file browser
@@ -24,6 +27,38 @@ type FileEntry struct {
 	Children    []*FileEntry
 	IsSpecial   bool   // For special entries like "." current directory
 	DisplayName string // Optional display name override
+	Section     string // Shortcut section of the entry, empty for the directory tree
+	Loading     bool   // Whether the children are being read
+	Limit       int    // How many children are shown, 0 for maxChildrenShown
+	More        bool   // Row that shows more of the parent's children
+}
+
+// Shortcut sections shown above the directory tree
+const (
+	sectionBookmarks = "Bookmarks"
+	sectionRecent    = "Recent repos"
+)
+
+// maxRecentRepos is how many recent repos are shown
+const maxRecentRepos = 5
+
+// maxChildrenShown is how many children of a directory are shown at first and
+// added by each "more" row, so huge directories don't flood the list
+const maxChildrenShown = 200
+
+// maxExpandDepth is the deepest a directory is expanded in the tree. Deeper
+// directories are browsed into instead.
+const maxExpandDepth = 8
+
+// DefaultFileBrowserIgnore are the directory name patterns hidden from the
+// file browser unless configured otherwise
+var DefaultFileBrowserIgnore = []string{"node_modules", ".cache", "vendor"}
+
+// FileBrowserLoadedMsg carries the children of a directory read in the background
+type FileBrowserLoadedMsg struct {
+	entry    *FileEntry
+	children []*FileEntry
+	err      error
 }
 
 // FileBrowserOverlay represents a file browser overlay for selecting directories
@@ -33,13 +68,21 @@ type FileBrowserOverlay struct {
 	selectedIdx   int
 	Submitted     bool
 	Canceled      bool
+	Scratch       bool // Whether the selection is for a scratch session (no git)
 	SelectedPath  string
 	width, height int
 	scrollOffset  int
-	message       string    // Feedback message to display
-	messageTime   time.Time // When the message was set
-	cwdIsGitRepo  bool      // Whether current working directory is a git repo
-	cwdPath       string    // The current working directory path
+	message       string         // Feedback message to display
+	messageTime   time.Time      // When the message was set
+	cwdIsGitRepo  bool           // Whether current working directory is a git repo
+	cwdPath       string         // The current working directory path
+	bookmarks     []string       // Pinned directories
+	recent        []string       // Repos of previously created instances, most recent first
+	ignore        []string       // Directory name patterns that aren't shown
+	pending       []*FileEntry   // Directories waiting to be read by LoadCmd
+	spinner       *spinner.Model // Spinner shown next to directories being read
+	// OnBookmarksChanged is called with the bookmarks after one was added or removed
+	OnBookmarksChanged func(bookmarks []string)
 }
 
 // NewFileBrowserOverlay creates a new file browser overlay starting at the given path
@@ -63,27 +106,54 @@ func NewFileBrowserOverlay(startPath string) (*FileBrowserOverlay, error) {
 		selectedIdx:  0,
 		cwdPath:      absPath,
 		cwdIsGitRepo: isGitRepo(absPath),
+		ignore:       DefaultFileBrowserIgnore,
+	}
+
+	// Create root entry; its children are read by LoadCmd
+	if err := fb.setRoot(absPath); err != nil {
+		return nil, err
+	}
+
+	return fb, nil
+}
+
+// setRoot makes the directory the root of the tree and queues reading its
+// children.
+func (fb *FileBrowserOverlay) setRoot(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
 	}
 
-	// Create root entry
 	fb.root = &FileEntry{
-		Name:     filepath.Base(absPath),
-		Path:     absPath,
+		Name:     filepath.Base(path),
+		Path:     path,
 		IsDir:    true,
-		IsGitDir: isGitRepo(absPath),
+		IsGitDir: isGitRepo(path),
 		Expanded: true,
 		Depth:    0,
 	}
-
-	// Load children of root
-	if err := fb.loadChildren(fb.root); err != nil {
-		return nil, err
-	}
+	fb.load(fb.root)
 
 	// Flatten entries for display
 	fb.flattenEntries()
+	fb.selectedIdx = 0
+	fb.scrollOffset = 0
+	return nil
+}
 
-	return fb, nil
+// SetIgnore sets the directory name patterns, as matched by filepath.Match,
+// that aren't shown. It applies to directories read afterwards.
+func (fb *FileBrowserOverlay) SetIgnore(patterns []string) {
+	fb.ignore = patterns
+}
+
+// SetSpinner sets the spinner shown next to directories being read
+func (fb *FileBrowserOverlay) SetSpinner(spinner *spinner.Model) {
+	fb.spinner = spinner
 }
 
 // isGitRepo checks if the given path is a git repository
@@ -97,18 +167,83 @@ func isGitRepo(path string) bool {
 	return info.IsDir() || !info.IsDir()
 }
 
-// loadChildren loads the children of a directory entry
-func (fb *FileBrowserOverlay) loadChildren(entry *FileEntry) error {
-	if !entry.IsDir {
+// isIgnored checks if the directory name matches one of the ignore patterns
+func isIgnored(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// load queues reading the children of a directory entry
+func (fb *FileBrowserOverlay) load(entry *FileEntry) {
+	if !entry.IsDir || entry.Loading {
+		return
+	}
+	entry.Loading = true
+	fb.pending = append(fb.pending, entry)
+}
+
+// LoadCmd returns a command that reads the queued directories in the
+// background, or nil if none are queued. It should be run after creating the
+// overlay and after each key press.
+func (fb *FileBrowserOverlay) LoadCmd() tea.Cmd {
+	if len(fb.pending) == 0 {
 		return nil
 	}
+	cmds := make([]tea.Cmd, 0, len(fb.pending))
+	for _, entry := range fb.pending {
+		entry, ignore := entry, fb.ignore
+		cmds = append(cmds, func() tea.Msg {
+			children, err := readChildren(entry, ignore)
+			return FileBrowserLoadedMsg{entry: entry, children: children, err: err}
+		})
+	}
+	fb.pending = nil
+	return tea.Batch(cmds...)
+}
+
+// HandleLoaded shows the children of a directory once they have been read
+func (fb *FileBrowserOverlay) HandleLoaded(msg FileBrowserLoadedMsg) {
+	entry := msg.entry
+	entry.Loading = false
+	if msg.err != nil {
+		// Collapse the directory so expanding it again retries
+		entry.Expanded = false
+		fb.setMessage(fmt.Sprintf("Can't read %s: %v", entry.Name, msg.err))
+	} else {
+		entry.Children = msg.children
+	}
 
+	// Keep the selection on the same entry as rows are added above it
+	var selected *FileEntry
+	if fb.selectedIdx < len(fb.entries) {
+		selected = fb.entries[fb.selectedIdx]
+	}
+	fb.flattenEntries()
+	for i, e := range fb.entries {
+		if e == selected {
+			fb.selectedIdx = i
+			break
+		}
+	}
+	if fb.selectedIdx >= len(fb.entries) {
+		fb.selectedIdx = len(fb.entries) - 1
+	}
+	fb.adjustScroll()
+}
+
+// readChildren reads the subdirectories of a directory entry. It runs in the
+// background, so it doesn't modify the entry.
+func readChildren(entry *FileEntry, ignore []string) ([]*FileEntry, error) {
 	dirEntries, err := os.ReadDir(entry.Path)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	entry.Children = make([]*FileEntry, 0)
+	children := make([]*FileEntry, 0)
 
 	for _, de := range dirEntries {
 		name := de.Name()
@@ -122,6 +257,10 @@ func (fb *FileBrowserOverlay) loadChildren(entry *FileEntry) error {
 			continue // Only show directories
 		}
 
+		if isIgnored(name, ignore) {
+			continue
+		}
+
 		childPath := filepath.Join(entry.Path, name)
 		child := &FileEntry{
 			Name:     name,
@@ -132,19 +271,19 @@ func (fb *FileBrowserOverlay) loadChildren(entry *FileEntry) error {
 			Depth:    entry.Depth + 1,
 			Parent:   entry,
 		}
-		entry.Children = append(entry.Children, child)
+		children = append(children, child)
 	}
 
 	// Sort children: git repos first, then alphabetically
-	sort.Slice(entry.Children, func(i, j int) bool {
+	sort.Slice(children, func(i, j int) bool {
 		// Git repos come first
-		if entry.Children[i].IsGitDir != entry.Children[j].IsGitDir {
-			return entry.Children[i].IsGitDir
+		if children[i].IsGitDir != children[j].IsGitDir {
+			return children[i].IsGitDir
 		}
-		return entry.Children[i].Name < entry.Children[j].Name
+		return children[i].Name < children[j].Name
 	})
 
-	return nil
+	return children, nil
 }
 
 // flattenEntries creates a flat list of entries for display
@@ -165,19 +304,179 @@ func (fb *FileBrowserOverlay) flattenEntries() {
 		fb.entries = append(fb.entries, currentDirEntry)
 	}
 
+	for _, path := range fb.bookmarks {
+		fb.entries = append(fb.entries, shortcutEntry(path, sectionBookmarks))
+	}
+	shown := 0
+	for _, path := range fb.recent {
+		if shown == maxRecentRepos {
+			break
+		}
+		if fb.isBookmarked(path) {
+			continue
+		}
+		fb.entries = append(fb.entries, shortcutEntry(path, sectionRecent))
+		shown++
+	}
+
 	fb.flattenEntry(fb.root)
 }
 
+// shortcutEntry returns the entry of a bookmark or recent repo.
+func shortcutEntry(path, section string) *FileEntry {
+	return &FileEntry{
+		Name:        filepath.Base(path),
+		DisplayName: fmt.Sprintf("%s  %s", filepath.Base(path), shortenHome(filepath.Dir(path))),
+		Path:        path,
+		IsDir:       true,
+		IsGitDir:    isGitRepo(path),
+		IsSpecial:   true,
+		Section:     section,
+	}
+}
+
+// shortenHome replaces the home directory at the start of path with ~.
+func shortenHome(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if path == home {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, home+string(filepath.Separator)); ok {
+		return filepath.Join("~", rest)
+	}
+	return path
+}
+
+// SetShortcuts sets the bookmarked directories and the recent repos, most
+// recent first, shown above the directory tree. Directories that no longer
+// exist are left out.
+func (fb *FileBrowserOverlay) SetShortcuts(bookmarks, recent []string) {
+	fb.bookmarks = existingDirs(bookmarks)
+	fb.recent = existingDirs(recent)
+	fb.flattenEntries()
+}
+
+// existingDirs returns the paths that are directories.
+func existingDirs(paths []string) []string {
+	var dirs []string
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dirs = append(dirs, path)
+		}
+	}
+	return dirs
+}
+
+// isBookmarked returns true if the directory is bookmarked.
+func (fb *FileBrowserOverlay) isBookmarked(path string) bool {
+	for _, bookmark := range fb.bookmarks {
+		if bookmark == path {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleBookmark bookmarks the selected directory, or removes its bookmark.
+func (fb *FileBrowserOverlay) toggleBookmark() {
+	if fb.selectedIdx >= len(fb.entries) || fb.entries[fb.selectedIdx].More {
+		return
+	}
+	path := fb.entries[fb.selectedIdx].Path
+	if fb.isBookmarked(path) {
+		bookmarks := make([]string, 0, len(fb.bookmarks))
+		for _, bookmark := range fb.bookmarks {
+			if bookmark != path {
+				bookmarks = append(bookmarks, bookmark)
+			}
+		}
+		fb.bookmarks = bookmarks
+		fb.setMessage("Removed bookmark " + shortenHome(path))
+	} else {
+		fb.bookmarks = append(fb.bookmarks, path)
+		fb.setMessage("Bookmarked " + shortenHome(path))
+	}
+	fb.flattenEntries()
+	if fb.selectedIdx >= len(fb.entries) {
+		fb.selectedIdx = len(fb.entries) - 1
+	}
+	fb.adjustScroll()
+	if fb.OnBookmarksChanged != nil {
+		fb.OnBookmarksChanged(fb.bookmarks)
+	}
+}
+
+// openShortcut browses the directory of the selected bookmark or recent repo.
+// Returns false if the selected entry isn't one.
+func (fb *FileBrowserOverlay) openShortcut() bool {
+	if fb.selectedIdx >= len(fb.entries) || fb.entries[fb.selectedIdx].Section == "" {
+		return false
+	}
+	if err := fb.NavigateToPath(fb.entries[fb.selectedIdx].Path); err != nil {
+		fb.setMessage(fmt.Sprintf("Can't open %s: %v", fb.entries[fb.selectedIdx].Name, err))
+	}
+	return true
+}
+
 func (fb *FileBrowserOverlay) flattenEntry(entry *FileEntry) {
 	fb.entries = append(fb.entries, entry)
 
 	if entry.Expanded && entry.Children != nil {
-		for _, child := range entry.Children {
+		limit := entry.Limit
+		if limit == 0 {
+			limit = maxChildrenShown
+		}
+		for i, child := range entry.Children {
+			if i == limit {
+				fb.entries = append(fb.entries, &FileEntry{
+					DisplayName: fmt.Sprintf("... %d more", len(entry.Children)-limit),
+					Path:        entry.Path,
+					Depth:       entry.Depth + 1,
+					Parent:      entry,
+					More:        true,
+				})
+				break
+			}
 			fb.flattenEntry(child)
 		}
 	}
 }
 
+// expand expands the directory entry, reading its children if they haven't
+// been yet. Directories past maxExpandDepth are browsed into instead.
+func (fb *FileBrowserOverlay) expand(entry *FileEntry) {
+	if entry.Depth >= maxExpandDepth {
+		if err := fb.NavigateToPath(entry.Path); err != nil {
+			fb.setMessage(fmt.Sprintf("Can't open %s: %v", entry.Name, err))
+		}
+		return
+	}
+	entry.Expanded = true
+	if entry.Children == nil {
+		fb.load(entry)
+	}
+	fb.flattenEntries()
+}
+
+// showMore shows more children of the directory if the selected entry is its
+// "more" row. Returns false if the selected entry isn't one.
+func (fb *FileBrowserOverlay) showMore() bool {
+	if fb.selectedIdx >= len(fb.entries) || !fb.entries[fb.selectedIdx].More {
+		return false
+	}
+	parent := fb.entries[fb.selectedIdx].Parent
+	if parent.Limit == 0 {
+		parent.Limit = maxChildrenShown
+	}
+	parent.Limit += maxChildrenShown
+	// The first of the added children takes the place of the row
+	fb.flattenEntries()
+	return true
+}
+
 // SetSize sets the size of the file browser
 func (fb *FileBrowserOverlay) SetSize(width, height int) {
 	fb.width = width
@@ -216,15 +515,14 @@ func (fb *FileBrowserOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
 		}
 		return false
 	case tea.KeyRight:
+		if fb.openShortcut() || fb.showMore() {
+			return false
+		}
 		// Expand directory
 		if fb.selectedIdx < len(fb.entries) {
 			entry := fb.entries[fb.selectedIdx]
 			if entry.IsDir && !entry.Expanded && !entry.IsSpecial {
-				entry.Expanded = true
-				if entry.Children == nil {
-					fb.loadChildren(entry)
-				}
-				fb.flattenEntries()
+				fb.expand(entry)
 			}
 		}
 		return false
@@ -259,14 +557,19 @@ func (fb *FileBrowserOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
 				fb.Submitted = true
 				return true
 			}
+			// Browse bookmarked directories that aren't repos
+			if fb.openShortcut() || fb.showMore() {
+				return false
+			}
 			// If not a git repo, show feedback and toggle expand
 			fb.setMessage("Not a git repository - expand to find repos inside")
 			if entry.IsDir && !entry.IsSpecial {
-				entry.Expanded = !entry.Expanded
-				if entry.Expanded && entry.Children == nil {
-					fb.loadChildren(entry)
+				if entry.Expanded {
+					entry.Expanded = false
+					fb.flattenEntries()
+				} else {
+					fb.expand(entry)
 				}
-				fb.flattenEntries()
 			}
 		}
 		return false
@@ -288,15 +591,14 @@ func (fb *FileBrowserOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
 			fb.adjustScroll()
 		}
 	case "l":
+		if fb.openShortcut() || fb.showMore() {
+			break
+		}
 		// Expand directory
 		if fb.selectedIdx < len(fb.entries) {
 			entry := fb.entries[fb.selectedIdx]
 			if entry.IsDir && !entry.Expanded && !entry.IsSpecial {
-				entry.Expanded = true
-				if entry.Children == nil {
-					fb.loadChildren(entry)
-				}
-				fb.flattenEntries()
+				fb.expand(entry)
 			}
 		}
 	case "h":
@@ -319,6 +621,19 @@ func (fb *FileBrowserOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
 				}
 			}
 		}
+	case "s":
+		// Select any directory for a scratch session without a git worktree
+		if fb.selectedIdx < len(fb.entries) {
+			entry := fb.entries[fb.selectedIdx]
+			if entry.IsDir {
+				fb.SelectedPath = entry.Path
+				fb.Scratch = true
+				fb.Submitted = true
+				return true
+			}
+		}
+	case "b":
+		fb.toggleBookmark()
 	case "~":
 		// Go to home directory
 		home, err := os.UserHomeDir()
@@ -358,7 +673,33 @@ func (fb *FileBrowserOverlay) adjustScroll() {
 // getVisibleRows returns the number of visible rows in the file browser
 func (fb *FileBrowserOverlay) getVisibleRows() int {
 	// Account for title, subtitle, path, border, padding, help text, message
-	return fb.height - 12
+	rows := fb.height - 12
+	// Leave room for the headers of the sections
+	if fb.hasShortcuts() {
+		rows -= 3
+	}
+	return rows
+}
+
+// hasShortcuts returns true if bookmarks or recent repos are shown.
+func (fb *FileBrowserOverlay) hasShortcuts() bool {
+	return len(fb.bookmarks) > 0 || len(fb.recent) > 0
+}
+
+// sectionHeader returns the header shown above the entry at i, if the entry
+// starts a section or is the first one shown.
+func (fb *FileBrowserOverlay) sectionHeader(i int, first bool) string {
+	entry := fb.entries[i]
+	if !first && i > 0 && fb.entries[i-1].Section == entry.Section {
+		return ""
+	}
+	if entry.Section != "" {
+		return entry.Section
+	}
+	if fb.hasShortcuts() && !entry.IsSpecial {
+		return "Browse"
+	}
+	return ""
 }
 
 // IsSubmitted returns whether the form was submitted
@@ -371,6 +712,11 @@ func (fb *FileBrowserOverlay) IsCanceled() bool {
 	return fb.Canceled
 }
 
+// IsScratch returns whether the selected directory should be used for a scratch session
+func (fb *FileBrowserOverlay) IsScratch() bool {
+	return fb.Scratch
+}
+
 // GetSelectedPath returns the selected path
 func (fb *FileBrowserOverlay) GetSelectedPath() string {
 	return fb.SelectedPath
@@ -431,6 +777,10 @@ func (fb *FileBrowserOverlay) Render() string {
 	separatorStyle := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#444444"))
 
+	sectionStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		Bold(true)
+
 	// Build the view
 	content := titleStyle.Render("Select a Git Repository") + "\n"
 	content += subtitleStyle.Render("Git repos shown in green - press Enter to select") + "\n"
@@ -452,9 +802,15 @@ func (fb *FileBrowserOverlay) Render() string {
 	for i := startIdx; i < endIdx; i++ {
 		entry := fb.entries[i]
 
-		// Build indent (special entries have no indent)
+		if header := fb.sectionHeader(i, i == startIdx); header != "" {
+			content += sectionStyle.Render(header) + "\n"
+		}
+
+		// Build indent (special entries have no indent, shortcuts sit below their header)
 		var indent string
-		if entry.IsSpecial {
+		if entry.Section != "" {
+			indent = "  "
+		} else if entry.IsSpecial {
 			indent = ""
 		} else {
 			indent = strings.Repeat("  ", entry.Depth)
@@ -462,7 +818,7 @@ func (fb *FileBrowserOverlay) Render() string {
 
 		// Build prefix (folder icon + expand indicator)
 		var prefix string
-		if entry.IsSpecial {
+		if entry.IsSpecial || entry.More {
 			prefix = ""
 		} else if entry.IsDir {
 			if entry.Expanded {
@@ -481,29 +837,38 @@ func (fb *FileBrowserOverlay) Render() string {
 		}
 
 		var icon string
-		if entry.IsGitDir {
+		if entry.More {
+			icon = ""
+		} else if entry.IsGitDir {
 			icon = "[git] "
 		} else {
 			icon = "[dir] "
 		}
 
 		line := indent + prefix + icon + displayName
+		if entry.Loading {
+			if fb.spinner != nil {
+				line += " " + fb.spinner.View()
+			} else {
+				line += " (loading...)"
+			}
+		}
 
 		// Truncate if too long
 		maxWidth := fb.width - 8
-		if maxWidth > 0 && len(line) > maxWidth {
-			line = line[:maxWidth-3] + "..."
+		if maxWidth > 0 {
+			line = truncate.StringWithTail(line, uint(maxWidth), "...")
 		}
 
 		// Apply styling
 		if i == fb.selectedIdx {
 			// Pad to full width for better selection visibility
 			padWidth := fb.width - 8
-			if len(line) < padWidth {
-				line = line + strings.Repeat(" ", padWidth-len(line))
+			if width := runewidth.StringWidth(line); width < padWidth {
+				line = line + strings.Repeat(" ", padWidth-width)
 			}
 			line = selectedStyle.Render(line)
-		} else if entry.IsSpecial {
+		} else if entry.IsSpecial && entry.Section == "" {
 			line = specialStyle.Render(line)
 		} else if entry.IsGitDir {
 			line = gitRepoStyle.Render(line)
@@ -538,6 +903,8 @@ func (fb *FileBrowserOverlay) Render() string {
 		{"↑/k ↓/j", "navigate"},
 		{"←/h →/l", "collapse/expand"},
 		{"Enter", "select repo"},
+		{"s", "scratch (no git)"},
+		{"b", "bookmark"},
 		{"-/u", "parent dir"},
 		{"~", "home"},
 		{"Esc", "cancel"},
@@ -559,24 +926,7 @@ func (fb *FileBrowserOverlay) NavigateToPath(path string) error {
 		return err
 	}
 
-	fb.root = &FileEntry{
-		Name:     filepath.Base(absPath),
-		Path:     absPath,
-		IsDir:    true,
-		IsGitDir: isGitRepo(absPath),
-		Expanded: true,
-		Depth:    0,
-	}
-
-	if err := fb.loadChildren(fb.root); err != nil {
-		return err
-	}
-
-	fb.flattenEntries()
-	fb.selectedIdx = 0
-	fb.scrollOffset = 0
-
-	return nil
+	return fb.setRoot(absPath)
 }
 
 // GoUp navigates to the parent directory
@@ -0,0 +1,254 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/truncate"
+)
+
+// HelpKey is a key and what it does.
+type HelpKey struct {
+	Key         string
+	Description string
+}
+
+// HelpSection is a group of keys that apply in the same place.
+type HelpSection struct {
+	Title string
+	Keys  []HelpKey
+}
+
+// helpLine is a line of the help, either a section title or a key.
+type helpLine struct {
+	title string
+	key   HelpKey
+}
+
+// HelpOverlay is a scrollable key reference that can be searched with /.
+type HelpOverlay struct {
+	// Whether the overlay has been dismissed
+	Dismissed bool
+	// Callback function to be called when the overlay is dismissed
+	OnDismiss func()
+
+	title    string
+	intro    string
+	sections []HelpSection
+	// lines are the sections as they are shown, filtered by the query
+	lines     []helpLine
+	query     string
+	searching bool
+	offset    int
+	width     int
+	height    int
+}
+
+// NewHelpOverlay creates a key reference of the sections, in the given order.
+// The intro is shown under the title, if not empty.
+func NewHelpOverlay(title, intro string, sections []HelpSection) *HelpOverlay {
+	h := &HelpOverlay{title: title, intro: intro, sections: sections, width: 80, height: 24}
+	h.filter()
+	return h
+}
+
+// HandleKeyPress processes a key press and updates the state.
+// Returns true if the overlay should be closed.
+func (h *HelpOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	if h.searching {
+		switch msg.Type {
+		case tea.KeyEsc:
+			h.searching = false
+			h.query = ""
+			h.filter()
+		case tea.KeyEnter:
+			h.searching = false
+		case tea.KeyBackspace:
+			if runes := []rune(h.query); len(runes) > 0 {
+				h.query = string(runes[:len(runes)-1])
+				h.filter()
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			h.query += string(msg.Runes)
+			h.filter()
+		}
+		return false
+	}
+
+	switch msg.String() {
+	case "/":
+		h.searching = true
+	case "up", "k":
+		h.scroll(-1)
+	case "down", "j":
+		h.scroll(1)
+	case "pgup", "ctrl+u":
+		h.scroll(-h.rows())
+	case "pgdown", "ctrl+d", " ":
+		h.scroll(h.rows())
+	case "g", "home":
+		h.offset = 0
+	case "G", "end":
+		h.scroll(len(h.lines))
+	case "esc":
+		// The first esc clears the search, the second one closes the help
+		if h.query != "" {
+			h.query = ""
+			h.filter()
+			return false
+		}
+		return h.dismiss()
+	case "q", "?", "ctrl+c":
+		return h.dismiss()
+	}
+	return false
+}
+
+func (h *HelpOverlay) dismiss() bool {
+	h.Dismissed = true
+	if h.OnDismiss != nil {
+		h.OnDismiss()
+	}
+	return true
+}
+
+// filter rebuilds the shown lines from the sections, keeping the keys whose
+// key or description contains the query, ignoring case. Sections without
+// matching keys are left out.
+func (h *HelpOverlay) filter() {
+	query := strings.ToLower(strings.TrimSpace(h.query))
+	h.lines = h.lines[:0]
+	for _, section := range h.sections {
+		var matches []helpLine
+		for _, key := range section.Keys {
+			if query == "" || strings.Contains(strings.ToLower(key.Key), query) ||
+				strings.Contains(strings.ToLower(key.Description), query) {
+				matches = append(matches, helpLine{key: key})
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		if len(h.lines) > 0 {
+			h.lines = append(h.lines, helpLine{})
+		}
+		h.lines = append(h.lines, helpLine{title: section.Title})
+		h.lines = append(h.lines, matches...)
+	}
+	h.offset = 0
+}
+
+// Matches returns the keys shown with the current query, in order.
+func (h *HelpOverlay) Matches() []HelpKey {
+	var keys []HelpKey
+	for _, line := range h.lines {
+		if line.key.Key != "" {
+			keys = append(keys, line.key)
+		}
+	}
+	return keys
+}
+
+// Searching returns whether a search query is being typed.
+func (h *HelpOverlay) Searching() bool {
+	return h.searching
+}
+
+// scroll moves the shown lines by delta, keeping the last page full.
+func (h *HelpOverlay) scroll(delta int) {
+	h.offset = max(0, min(h.offset+delta, len(h.lines)-h.rows()))
+}
+
+// rows returns how many lines of keys fit in the overlay, besides the
+// border, padding, title, intro and footer.
+func (h *HelpOverlay) rows() int {
+	chrome := 8
+	if h.intro != "" {
+		chrome += lipgloss.Height(h.intro) + 1
+	}
+	return max(h.height-chrome, 3)
+}
+
+// Render renders the help overlay
+func (h *HelpOverlay) Render(opts ...WhitespaceOption) string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Underline(true).Foreground(lipgloss.Color("#7D56F4"))
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#36CFC9"))
+	keyStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFCC00"))
+	descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+
+	// Leave room for the border and padding
+	lineWidth := max(h.width-6, 20)
+	keyWidth := 0
+	for _, line := range h.lines {
+		keyWidth = max(keyWidth, runewidth.StringWidth(line.key.Key))
+	}
+	keyWidth = min(keyWidth, lineWidth/3)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(h.title))
+	content.WriteString("\n\n")
+	if h.intro != "" {
+		content.WriteString(truncateLines(h.intro, lineWidth))
+		content.WriteString("\n\n")
+	}
+
+	rows := h.rows()
+	if len(h.lines) == 0 {
+		content.WriteString(descStyle.Render("No matching keys"))
+		content.WriteString("\n")
+	}
+	end := min(h.offset+rows, len(h.lines))
+	for _, line := range h.lines[h.offset:end] {
+		switch {
+		case line.title != "":
+			content.WriteString(headerStyle.Render(truncate.StringWithTail(line.title, uint(lineWidth), "…")))
+		case line.key.Key != "":
+			key := runewidth.FillRight(runewidth.Truncate(line.key.Key, keyWidth, "…"), keyWidth)
+			desc := truncate.StringWithTail(line.key.Description, uint(max(lineWidth-keyWidth-2, 1)), "…")
+			content.WriteString(keyStyle.Render(key) + "  " + descStyle.Render(desc))
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	var footer string
+	switch {
+	case h.searching:
+		footer = fmt.Sprintf("/%s█  enter keep · esc clear", h.query)
+	case h.query != "":
+		footer = fmt.Sprintf("/%s  esc clear · / search · q close", h.query)
+	default:
+		footer = "/ search · ↑/↓ scroll · esc close"
+	}
+	if len(h.lines) > rows {
+		footer += fmt.Sprintf(" · %d-%d of %d", h.offset+1, end, len(h.lines))
+	}
+	content.WriteString(hintStyle.Render(truncate.StringWithTail(footer, uint(lineWidth), "…")))
+
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Width(h.width)
+	return style.Render(content.String())
+}
+
+// truncateLines truncates each line of the text to the width.
+func truncateLines(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = truncate.StringWithTail(line, uint(width), "…")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetSize sets the size of the overlay, including its border.
+func (h *HelpOverlay) SetSize(width, height int) {
+	h.width = width
+	h.height = height
+	h.scroll(0)
+}
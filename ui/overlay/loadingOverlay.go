@@ -13,6 +13,8 @@ type LoadingOverlay struct {
 	status string
 	// Spinner for the loading animation
 	spinner *spinner.Model
+	// Error that stopped the operation, shown until the overlay is dismissed
+	err error
 
 	width int
 }
@@ -31,6 +33,16 @@ func (l *LoadingOverlay) SetStatus(status string) {
 	l.status = status
 }
 
+// SetError shows the error that stopped the operation in place of the status
+func (l *LoadingOverlay) SetError(err error) {
+	l.err = err
+}
+
+// HasError returns whether the overlay shows an error
+func (l *LoadingOverlay) HasError() bool {
+	return l.err != nil
+}
+
 // SetWidth sets the overlay width
 func (l *LoadingOverlay) SetWidth(width int) {
 	l.width = width
@@ -54,6 +66,13 @@ func (l *LoadingOverlay) Render(opts ...WhitespaceOption) string {
 
 	// Build content
 	content := titleStyle.Render(l.title) + "\n\n"
+	if l.err != nil {
+		errorStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#de613e"))
+		content += errorStyle.Render(l.err.Error()) + "\n\n"
+		content += statusStyle.Render("Press any key to close")
+		return boxStyle.Render(content)
+	}
 	if l.spinner != nil {
 		content += l.spinner.View() + " "
 	}
@@ -15,12 +15,14 @@ type ModeOption struct {
 	Name        string // Display name
 	Description string // Description of when to use
 	Available   bool   // Whether this option is available (e.g., Docker installed)
+	Reason      string // Why the option is unavailable, "not installed" if empty
 }
 
 // ModeSelectorOverlay represents a session mode selection dialog
 type ModeSelectorOverlay struct {
 	Dismissed bool
 	Selected  string // The selected session type
+	title     string
 	options   []ModeOption
 	cursor    int
 	width     int
@@ -37,6 +39,12 @@ func NewModeSelectorOverlay() *ModeSelectorOverlay {
 			Description: "Run Claude in a Zellij terminal session on your machine.\nBest for: Quick tasks, when you want direct file access.",
 			Available:   session.IsZellijAvailable(),
 		},
+		{
+			Type:        config.SessionTypeNative,
+			Name:        "Native (no multiplexer)",
+			Description: "Run Claude directly in a terminal managed by claude-squad.\nBest for: Windows, or when zellij isn't installed.",
+			Available:   session.IsNativeAvailable(),
+		},
 		{
 			Type:        config.SessionTypeDockerBind,
 			Name:        "Docker (bind-mount)",
@@ -51,11 +59,39 @@ func NewModeSelectorOverlay() *ModeSelectorOverlay {
 		},
 	}
 
-	return &ModeSelectorOverlay{
+	m := &ModeSelectorOverlay{
+		title:   "Select Session Mode",
 		options: options,
 		cursor:  0,
 		width:   60,
 	}
+	// Start on the first available option
+	if !options[0].Available {
+		m.moveCursor(1)
+	}
+	return m
+}
+
+// NewModeSwitchOverlay creates a mode selector for switching an existing
+// instance away from its current session type. Docker clone sessions don't use
+// the instance's worktree, so they can't be switched to.
+func NewModeSwitchOverlay(current string) *ModeSelectorOverlay {
+	m := NewModeSelectorOverlay()
+	m.title = "Switch Session Mode"
+	for i := range m.options {
+		switch m.options[i].Type {
+		case current:
+			m.options[i].Available = false
+			m.options[i].Reason = "current"
+		case config.SessionTypeDockerClone:
+			m.options[i].Available = false
+			m.options[i].Reason = "new sessions only"
+		}
+	}
+	if !m.options[m.cursor].Available {
+		m.moveCursor(1)
+	}
+	return m
 }
 
 // HandleKeyPress processes a key press and updates the state
@@ -134,7 +170,7 @@ func (m *ModeSelectorOverlay) Render(opts ...WhitespaceOption) string {
 		PaddingLeft(4)
 
 	var content strings.Builder
-	content.WriteString(titleStyle.Render("Select Session Mode"))
+	content.WriteString(titleStyle.Render(m.title))
 	content.WriteString("\n\n")
 
 	for i, opt := range m.options {
@@ -158,7 +194,11 @@ func (m *ModeSelectorOverlay) Render(opts ...WhitespaceOption) string {
 		content.WriteString(prefix)
 		content.WriteString(nameStyle.Render(opt.Name))
 		if !opt.Available {
-			content.WriteString(" (not installed)")
+			reason := opt.Reason
+			if reason == "" {
+				reason = "not installed"
+			}
+			content.WriteString(" (" + reason + ")")
 		}
 		content.WriteString("\n")
 
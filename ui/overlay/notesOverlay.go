@@ -0,0 +1,180 @@
+package overlay
+
+import (
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// NotesOverlay represents a form for editing the tags and notes of an instance.
+type NotesOverlay struct {
+	tagsInput  textinput.Model
+	notesArea  textarea.Model
+	Title      string
+	FocusIndex int // 0 for tags, 1 for notes, 2 for save button
+	Submitted  bool
+	Canceled   bool
+	// EditorRequested is set when the user asks to edit the notes in the
+	// external editor. The caller resets it.
+	EditorRequested bool
+	width, height   int
+}
+
+// NewNotesOverlay creates a new notes overlay with the given title, tags and notes.
+func NewNotesOverlay(title string, tags string, notes string) *NotesOverlay {
+	ti := textinput.New()
+	ti.Placeholder = "blocked, needs-review, backend"
+	ti.Prompt = ""
+	ti.CharLimit = 0
+	ti.SetValue(tags)
+	ti.Focus()
+
+	ta := textarea.New()
+	ta.SetValue(notes)
+	ta.ShowLineNumbers = false
+	ta.Prompt = ""
+	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
+	ta.Blur()
+
+	return &NotesOverlay{
+		tagsInput: ti,
+		notesArea: ta,
+		Title:     title,
+	}
+}
+
+// SetSize sets the size of the overlay. The notes area takes the available height.
+func (n *NotesOverlay) SetSize(width, height int) {
+	n.width = width
+	n.height = height
+	n.notesArea.SetHeight(max(height-8, 3))
+}
+
+// setFocus moves focus to the given field.
+func (n *NotesOverlay) setFocus(index int) {
+	n.FocusIndex = index
+	n.tagsInput.Blur()
+	n.notesArea.Blur()
+	switch index {
+	case 0:
+		n.tagsInput.Focus()
+	case 1:
+		n.notesArea.Focus()
+	}
+}
+
+// HandleKeyPress processes a key press and updates the state accordingly.
+// Returns true if the overlay should be closed.
+func (n *NotesOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyTab:
+		n.setFocus((n.FocusIndex + 1) % 3)
+		return false
+	case tea.KeyShiftTab:
+		n.setFocus((n.FocusIndex + 2) % 3)
+		return false
+	case tea.KeyEsc:
+		n.Canceled = true
+		return true
+	case tea.KeyCtrlO:
+		n.EditorRequested = true
+		return false
+	case tea.KeyEnter:
+		// Enter inserts a newline in the notes but saves from the other fields
+		if n.FocusIndex != 1 {
+			n.Submitted = true
+			return true
+		}
+		fallthrough
+	default:
+		switch n.FocusIndex {
+		case 0:
+			n.tagsInput, _ = n.tagsInput.Update(msg)
+		case 1:
+			n.notesArea, _ = n.notesArea.Update(msg)
+		}
+		return false
+	}
+}
+
+// GetTags returns the raw value of the tags field.
+func (n *NotesOverlay) GetTags() string {
+	return n.tagsInput.Value()
+}
+
+// GetNotes returns the value of the notes field.
+func (n *NotesOverlay) GetNotes() string {
+	return n.notesArea.Value()
+}
+
+// SetNotes replaces the value of the notes field.
+func (n *NotesOverlay) SetNotes(notes string) {
+	n.notesArea.SetValue(notes)
+}
+
+// IsSubmitted returns whether the form was submitted.
+func (n *NotesOverlay) IsSubmitted() bool {
+	return n.Submitted
+}
+
+// IsCanceled returns whether the form was canceled.
+func (n *NotesOverlay) IsCanceled() bool {
+	return n.Canceled
+}
+
+// Render renders the notes overlay.
+func (n *NotesOverlay) Render() string {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("62")).
+		Bold(true).
+		MarginBottom(1)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7"))
+
+	focusedLabelStyle := labelStyle.
+		Foreground(lipgloss.Color("62")).
+		Bold(true)
+
+	buttonStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("7"))
+
+	focusedButtonStyle := buttonStyle.
+		Background(lipgloss.Color("62")).
+		Foreground(lipgloss.Color("0"))
+
+	label := func(text string, index int) string {
+		if n.FocusIndex == index {
+			return focusedLabelStyle.Render(text)
+		}
+		return labelStyle.Render(text)
+	}
+
+	// Fit the inputs within the overlay, accounting for padding and borders
+	n.tagsInput.Width = n.width - 6
+	n.notesArea.SetWidth(n.width - 6)
+
+	content := titleStyle.Render(n.Title) + "\n"
+	content += label("Tags (comma separated)", 0) + "\n"
+	content += n.tagsInput.View() + "\n\n"
+	content += label("Notes", 1) + "\n"
+	content += n.notesArea.View() + "\n\n"
+
+	saveButton := " Save "
+	if n.FocusIndex == 2 {
+		saveButton = focusedButtonStyle.Render(saveButton)
+	} else {
+		saveButton = buttonStyle.Render(saveButton)
+	}
+	content += saveButton + "  " + labelStyle.Faint(true).Render("ctrl+o edit notes in $EDITOR")
+
+	return style.Render(content)
+}
@@ -0,0 +1,229 @@
+package overlay
+
+import (
+	"claude-squad/session"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/truncate"
+)
+
+// pickerRows is how many matches are shown at once.
+const pickerRows = 12
+
+// pickerEntry is an instance that can be picked, with the texts it is matched on.
+type pickerEntry struct {
+	instance *session.Instance
+	repo     string
+	score    int
+}
+
+// PickerOverlay finds an instance by fuzzy matching its title, branch and repo.
+type PickerOverlay struct {
+	Dismissed bool
+	// Selected is the instance picked with enter, if any
+	Selected *session.Instance
+	// AttachRequested is set when the instance was picked to attach to it
+	AttachRequested bool
+
+	input   textinput.Model
+	entries []pickerEntry
+	matches []pickerEntry
+	cursor  int
+	offset  int
+	width   int
+}
+
+// NewPickerOverlay creates a picker of the instances, including archived ones.
+func NewPickerOverlay(instances []*session.Instance) *PickerOverlay {
+	ti := textinput.New()
+	ti.Placeholder = "title, branch or repo"
+	ti.Prompt = "> "
+	ti.CharLimit = 0
+	ti.Focus()
+
+	p := &PickerOverlay{input: ti, width: 80}
+	for _, instance := range instances {
+		if instance == nil || instance.Tombstoned() {
+			continue
+		}
+		repo, err := instance.RepoName()
+		if err != nil {
+			repo = filepath.Base(instance.Path)
+		}
+		p.entries = append(p.entries, pickerEntry{instance: instance, repo: repo})
+	}
+	p.filter()
+	return p
+}
+
+// HandleKeyPress processes a key press and updates the state.
+// Returns true if the overlay should be closed.
+func (p *PickerOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		p.Dismissed = true
+		return true
+	case "enter", "ctrl+a":
+		if len(p.matches) == 0 {
+			return false
+		}
+		p.Selected = p.matches[p.cursor].instance
+		p.AttachRequested = msg.String() == "ctrl+a"
+		p.Dismissed = true
+		return true
+	case "up", "ctrl+p", "ctrl+k":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case "down", "ctrl+n", "ctrl+j":
+		if p.cursor < len(p.matches)-1 {
+			p.cursor++
+		}
+	default:
+		query := p.input.Value()
+		p.input, _ = p.input.Update(msg)
+		if p.input.Value() != query {
+			p.filter()
+		}
+	}
+
+	// Keep the cursor within the visible rows
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	} else if p.cursor >= p.offset+pickerRows {
+		p.offset = p.cursor - pickerRows + 1
+	}
+	return false
+}
+
+// filter matches the entries against the query, best matches first. Without
+// a query all entries are shown in list order.
+func (p *PickerOverlay) filter() {
+	query := strings.TrimSpace(p.input.Value())
+	p.matches = p.matches[:0]
+	for _, entry := range p.entries {
+		if query == "" {
+			p.matches = append(p.matches, entry)
+			continue
+		}
+		best, matched := 0, false
+		// Titles are what people remember, so they weigh the most
+		for weight, text := range map[int]string{3: entry.instance.Title, 2: entry.instance.Branch, 1: entry.repo} {
+			if score, ok := fuzzyScore(query, text); ok && (!matched || score*weight > best) {
+				best, matched = score*weight, true
+			}
+		}
+		if matched {
+			entry.score = best
+			p.matches = append(p.matches, entry)
+		}
+	}
+	if query != "" {
+		sort.SliceStable(p.matches, func(i, j int) bool { return p.matches[i].score > p.matches[j].score })
+	}
+	p.cursor, p.offset = 0, 0
+}
+
+// fuzzyScore matches the characters of the pattern in order against the text,
+// ignoring case. Consecutive characters and characters at the start of words
+// score higher.
+func fuzzyScore(pattern, text string) (int, bool) {
+	patternRunes := []rune(strings.ToLower(pattern))
+	textRunes := []rune(strings.ToLower(text))
+	score, next, previous := 0, 0, -2
+	for i, r := range textRunes {
+		if next == len(patternRunes) {
+			break
+		}
+		if r != patternRunes[next] {
+			continue
+		}
+		score++
+		if i == previous+1 {
+			score += 2
+		}
+		if i == 0 || !unicode.IsLetter(textRunes[i-1]) && !unicode.IsDigit(textRunes[i-1]) {
+			score += 3
+		}
+		previous = i
+		next++
+	}
+	if next < len(patternRunes) {
+		return 0, false
+	}
+	return score, true
+}
+
+// Render renders the picker overlay
+func (p *PickerOverlay) Render(opts ...WhitespaceOption) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7aa2f7")).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA"))
+
+	detailStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#666666"))
+
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+
+	// Leave room for the border, padding and the cursor prefix
+	lineWidth := max(p.width-8, 10)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Go to session"))
+	content.WriteString("\n\n")
+	content.WriteString(p.input.View())
+	content.WriteString("\n\n")
+
+	if len(p.matches) == 0 {
+		content.WriteString(normalStyle.Render("No matching sessions"))
+		content.WriteString("\n")
+	}
+	end := min(p.offset+pickerRows, len(p.matches))
+	for i := p.offset; i < end; i++ {
+		entry := p.matches[i]
+		detail := " " + entry.repo
+		if entry.instance.Branch != "" {
+			detail += " " + entry.instance.Branch
+		}
+		if entry.instance.Archived {
+			detail += " (archived)"
+		}
+		title := truncate.StringWithTail(entry.instance.Title, uint(lineWidth), "...")
+		detail = truncate.StringWithTail(detail, uint(max(lineWidth-runewidth.StringWidth(title), 0)), "...")
+		if i == p.cursor {
+			content.WriteString("> " + selectedStyle.Render(title) + detailStyle.Render(detail))
+		} else {
+			content.WriteString("  " + normalStyle.Render(title) + detailStyle.Render(detail))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+	content.WriteString(hintStyle.Render("[Enter] Go to  [Ctrl+A] Attach  [Esc] Close  [↑/↓] Navigate"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7aa2f7")).
+		Padding(1, 2).
+		Width(p.width)
+
+	return borderStyle.Render(content.String())
+}
+
+// SetWidth sets the width of the overlay
+func (p *PickerOverlay) SetWidth(width int) {
+	p.width = width
+}
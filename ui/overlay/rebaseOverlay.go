@@ -0,0 +1,169 @@
+package overlay
+
+import (
+	"claude-squad/session/git"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+// RebaseOverlay lets the user reorder, squash and drop the commits of a branch
+// before they are rewritten with an interactive rebase.
+type RebaseOverlay struct {
+	Dismissed bool
+	Submitted bool
+	title     string
+	commits   []git.RebaseCommit
+	cursor    int
+	err       string
+	width     int
+}
+
+// NewRebaseOverlay creates a new rebase overlay for the given commits, oldest first
+func NewRebaseOverlay(title string, commits []git.RebaseCommit) *RebaseOverlay {
+	return &RebaseOverlay{
+		title:   title,
+		commits: commits,
+		width:   80,
+	}
+}
+
+// HandleKeyPress processes a key press and updates the plan.
+// Returns true if the overlay should be closed.
+func (r *RebaseOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	r.err = ""
+	switch msg.String() {
+	case "up", "k":
+		if r.cursor > 0 {
+			r.cursor--
+		}
+	case "down", "j":
+		if r.cursor < len(r.commits)-1 {
+			r.cursor++
+		}
+	case "K", "shift+up":
+		if r.cursor > 0 {
+			r.commits[r.cursor], r.commits[r.cursor-1] = r.commits[r.cursor-1], r.commits[r.cursor]
+			r.cursor--
+		}
+	case "J", "shift+down":
+		if r.cursor < len(r.commits)-1 {
+			r.commits[r.cursor], r.commits[r.cursor+1] = r.commits[r.cursor+1], r.commits[r.cursor]
+			r.cursor++
+		}
+	case "p":
+		r.setAction(git.RebasePick)
+	case "s":
+		r.setAction(git.RebaseSquash)
+	case "f":
+		r.setAction(git.RebaseFixup)
+	case "d":
+		r.setAction(git.RebaseDrop)
+	case "enter":
+		if err := git.ValidateRebasePlan(r.commits); err != nil {
+			r.err = err.Error()
+			return false
+		}
+		r.Submitted = true
+		r.Dismissed = true
+		return true
+	case "esc", "q":
+		r.Dismissed = true
+		return true
+	}
+	return false
+}
+
+// setAction sets the action of the commit under the cursor
+func (r *RebaseOverlay) setAction(action git.RebaseAction) {
+	if len(r.commits) > 0 {
+		r.commits[r.cursor].Action = action
+	}
+}
+
+// GetPlan returns the commits in their new order with their actions
+func (r *RebaseOverlay) GetPlan() []git.RebaseCommit {
+	return r.commits
+}
+
+// Render renders the rebase overlay
+func (r *RebaseOverlay) Render(opts ...WhitespaceOption) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7aa2f7")).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA"))
+
+	droppedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#555555")).
+		Strikethrough(true)
+
+	actionStyles := map[git.RebaseAction]lipgloss.Style{
+		git.RebasePick:   lipgloss.NewStyle().Foreground(lipgloss.Color("#9ece6a")),
+		git.RebaseSquash: lipgloss.NewStyle().Foreground(lipgloss.Color("#e0af68")),
+		git.RebaseFixup:  lipgloss.NewStyle().Foreground(lipgloss.Color("#e0af68")),
+		git.RebaseDrop:   lipgloss.NewStyle().Foreground(lipgloss.Color("#f7768e")),
+	}
+
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#f7768e"))
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+
+	// Leave room for the border, padding, cursor, action and short SHA
+	maxSubjectWidth := max(r.width-26, 10)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(r.title))
+	content.WriteString("\n")
+	content.WriteString(hintStyle.Render("Oldest commit first"))
+	content.WriteString("\n\n")
+
+	for i, c := range r.commits {
+		subject := truncate.StringWithTail(c.Subject, uint(maxSubjectWidth), "...")
+
+		prefix := "  "
+		subjectStyle := normalStyle
+		if i == r.cursor {
+			prefix = "> "
+			subjectStyle = selectedStyle
+		}
+		if c.Action == git.RebaseDrop {
+			subjectStyle = droppedStyle
+		}
+
+		content.WriteString(prefix)
+		content.WriteString(actionStyles[c.Action].Render(fmt.Sprintf("%-6s", c.Action)))
+		content.WriteString(" ")
+		content.WriteString(hintStyle.Render(fmt.Sprintf("%.7s", c.SHA)))
+		content.WriteString(" ")
+		content.WriteString(subjectStyle.Render(subject))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	if r.err != "" {
+		content.WriteString(errStyle.Render(r.err))
+		content.WriteString("\n")
+	}
+	content.WriteString(hintStyle.Render("[p]ick [s]quash [f]ixup [d]rop  [J/K] Move  [Enter] Rebase  [Esc] Cancel"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7aa2f7")).
+		Padding(1, 2).
+		Width(r.width)
+
+	return borderStyle.Render(content.String())
+}
+
+// SetWidth sets the width of the overlay
+func (r *RebaseOverlay) SetWidth(width int) {
+	r.width = width
+}
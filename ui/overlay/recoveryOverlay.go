@@ -0,0 +1,154 @@
+package overlay
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// RecoveryOption is a way to recover from saved sessions that can't be loaded.
+type RecoveryOption int
+
+const (
+	// RecoveryRetry reads the saved sessions from disk again
+	RecoveryRetry RecoveryOption = iota
+	// RecoveryLoadParsable loads the sessions that can be parsed and drops the others
+	RecoveryLoadParsable
+	// RecoveryRestoreBackup replaces the saved sessions with the last ones that loaded
+	RecoveryRestoreBackup
+	// RecoveryStartEmpty starts without sessions
+	RecoveryStartEmpty
+	// RecoveryQuit quits without touching the saved sessions
+	RecoveryQuit
+)
+
+// recoveryItem is an option of the recovery overlay with its description.
+type recoveryItem struct {
+	option      RecoveryOption
+	label       string
+	description string
+}
+
+// RecoveryOverlay offers ways to recover when the saved sessions can't be loaded.
+type RecoveryOverlay struct {
+	err    error
+	items  []recoveryItem
+	cursor int
+	status string
+	width  int
+}
+
+// NewRecoveryOverlay creates a recovery overlay for the given load error. The
+// backup option is only offered if hasBackup is true.
+func NewRecoveryOverlay(err error, hasBackup bool) *RecoveryOverlay {
+	items := []recoveryItem{
+		{RecoveryRetry, "Retry", "Read the saved sessions again, e.g. after fixing state.json by hand"},
+		{RecoveryLoadParsable, "Load what parses", "Skip the sessions that can't be read; a copy of state.json is kept"},
+	}
+	if hasBackup {
+		items = append(items, recoveryItem{RecoveryRestoreBackup, "Restore from backup", "Go back to the sessions as they were last loaded"})
+	}
+	items = append(items,
+		recoveryItem{RecoveryStartEmpty, "Start empty", "Start without sessions; state.json is set aside first"},
+		recoveryItem{RecoveryQuit, "Quit", "Leave the saved sessions untouched"},
+	)
+	return &RecoveryOverlay{err: err, items: items, width: 70}
+}
+
+// HandleKeyPress processes a key press and updates the state.
+// Returns true if an option was chosen.
+func (r *RecoveryOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	r.status = ""
+	switch msg.String() {
+	case "up", "k":
+		r.cursor = (r.cursor - 1 + len(r.items)) % len(r.items)
+	case "down", "j":
+		r.cursor = (r.cursor + 1) % len(r.items)
+	case "enter":
+		return true
+	case "q", "ctrl+c":
+		r.cursor = len(r.items) - 1
+		return true
+	}
+	return false
+}
+
+// Selected returns the chosen option.
+func (r *RecoveryOverlay) Selected() RecoveryOption {
+	return r.items[r.cursor].option
+}
+
+// SetError updates the load error, e.g. after a retry failed again.
+func (r *RecoveryOverlay) SetError(err error) {
+	r.err = err
+}
+
+// SetStatus sets a short message shown until the next key press.
+func (r *RecoveryOverlay) SetStatus(status string) {
+	r.status = status
+}
+
+// Render renders the recovery overlay
+func (r *RecoveryOverlay) Render(opts ...WhitespaceOption) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF"))
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#f7768e"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7aa2f7")).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA"))
+
+	detailStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#666666"))
+
+	statusStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7aa2f7")).
+		Bold(true)
+
+	// Leave room for the border and padding
+	lineWidth := max(r.width-6, 10)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Your saved sessions could not be loaded"))
+	content.WriteString("\n\n")
+	content.WriteString(errorStyle.Width(lineWidth).Render(r.err.Error()))
+	content.WriteString("\n\n")
+
+	for i, item := range r.items {
+		if i == r.cursor {
+			content.WriteString("> " + selectedStyle.Render(item.label))
+		} else {
+			content.WriteString("  " + normalStyle.Render(item.label))
+		}
+		content.WriteString("\n")
+		content.WriteString(detailStyle.Width(lineWidth - 4).MarginLeft(4).Render(item.description))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	if r.status != "" {
+		content.WriteString(statusStyle.Render(r.status))
+	} else {
+		content.WriteString(detailStyle.Render("[Enter] Select  [↑/↓] Navigate  [q] Quit"))
+	}
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#f7768e")).
+		Padding(1, 2).
+		Width(r.width)
+
+	return borderStyle.Render(content.String())
+}
+
+// SetWidth sets the width of the overlay
+func (r *RecoveryOverlay) SetWidth(width int) {
+	r.width = width
+}
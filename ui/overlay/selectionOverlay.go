@@ -0,0 +1,110 @@
+package overlay
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SelectionOverlay represents a dialog for picking one item from a list
+type SelectionOverlay struct {
+	Dismissed bool
+	Submitted bool
+	title     string
+	items     []string
+	cursor    int
+	width     int
+}
+
+// NewSelectionOverlay creates a new selection overlay with the given title and items
+func NewSelectionOverlay(title string, items []string) *SelectionOverlay {
+	return &SelectionOverlay{
+		title: title,
+		items: items,
+		width: 60,
+	}
+}
+
+// HandleKeyPress processes a key press and updates the state.
+// Returns true if the overlay should be closed.
+func (s *SelectionOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "up", "k":
+		if len(s.items) > 0 {
+			s.cursor = (s.cursor - 1 + len(s.items)) % len(s.items)
+		}
+		return false
+	case "down", "j":
+		if len(s.items) > 0 {
+			s.cursor = (s.cursor + 1) % len(s.items)
+		}
+		return false
+	case "enter":
+		if len(s.items) == 0 {
+			return false
+		}
+		s.Submitted = true
+		s.Dismissed = true
+		return true
+	case "esc":
+		s.Dismissed = true
+		return true
+	default:
+		return false
+	}
+}
+
+// Render renders the selection overlay
+func (s *SelectionOverlay) Render(opts ...WhitespaceOption) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7aa2f7")).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA"))
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(s.title))
+	content.WriteString("\n\n")
+
+	for i, item := range s.items {
+		if i == s.cursor {
+			content.WriteString("> ")
+			content.WriteString(selectedStyle.Render(item))
+		} else {
+			content.WriteString("  ")
+			content.WriteString(normalStyle.Render(item))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#666666")).Render(
+		"[Enter] Select  [Esc] Cancel  [↑/↓] Navigate"))
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7aa2f7")).
+		Padding(1, 2).
+		Width(s.width)
+
+	return borderStyle.Render(content.String())
+}
+
+// SetWidth sets the width of the overlay
+func (s *SelectionOverlay) SetWidth(width int) {
+	s.width = width
+}
+
+// GetSelectedIndex returns the index of the selected item, or -1 if nothing was selected
+func (s *SelectionOverlay) GetSelectedIndex() int {
+	if !s.Submitted {
+		return -1
+	}
+	return s.cursor
+}
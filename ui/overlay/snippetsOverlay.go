@@ -0,0 +1,174 @@
+package overlay
+
+import (
+	"claude-squad/session"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+// snippetListRows is how many snippet titles are shown at once.
+const snippetListRows = 8
+
+// snippetPreviewRows is how many lines of the selected snippet are previewed.
+const snippetPreviewRows = 12
+
+// SnippetsOverlay lets the user browse the snippets captured from an instance.
+type SnippetsOverlay struct {
+	Dismissed bool
+	// CopyRequested is set when the user asks to copy the selected snippet.
+	CopyRequested bool
+	// SendRequested is set when the user asks to send the selected snippet to another session.
+	SendRequested bool
+	title         string
+	snippets      []session.Snippet
+	cursor        int
+	offset        int
+	status        string
+	width         int
+}
+
+// NewSnippetsOverlay creates a new snippets overlay with the given title and snippets
+func NewSnippetsOverlay(title string, snippets []session.Snippet) *SnippetsOverlay {
+	return &SnippetsOverlay{
+		title:    title,
+		snippets: snippets,
+		width:    80,
+	}
+}
+
+// HandleKeyPress processes a key press and updates the state.
+// Returns true if the overlay should be closed.
+func (s *SnippetsOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	s.status = ""
+	switch msg.String() {
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.snippets)-1 {
+			s.cursor++
+		}
+	case "y", "c":
+		if len(s.snippets) > 0 {
+			s.CopyRequested = true
+		}
+	case "s", "enter":
+		if len(s.snippets) > 0 {
+			s.SendRequested = true
+			s.Dismissed = true
+			return true
+		}
+	case "esc", "q":
+		s.Dismissed = true
+		return true
+	}
+
+	// Keep the cursor within the visible rows
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	} else if s.cursor >= s.offset+snippetListRows {
+		s.offset = s.cursor - snippetListRows + 1
+	}
+	return false
+}
+
+// GetSelected returns the snippet under the cursor, if any.
+func (s *SnippetsOverlay) GetSelected() (session.Snippet, bool) {
+	if len(s.snippets) == 0 {
+		return session.Snippet{}, false
+	}
+	return s.snippets[s.cursor], true
+}
+
+// SetStatus sets a short message shown in the footer until the next key press.
+func (s *SnippetsOverlay) SetStatus(status string) {
+	s.status = status
+}
+
+// Render renders the snippets overlay
+func (s *SnippetsOverlay) Render(opts ...WhitespaceOption) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7aa2f7")).
+		Bold(true)
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA"))
+
+	kindStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888"))
+
+	previewStyle := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("#444444")).
+		Foreground(lipgloss.Color("#CCCCCC")).
+		Padding(0, 1).
+		Width(max(s.width-8, 10))
+
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+
+	// Leave room for the border, padding and the cursor prefix
+	maxTitleWidth := max(s.width-18, 10)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(s.title))
+	content.WriteString("\n\n")
+
+	if len(s.snippets) == 0 {
+		content.WriteString(normalStyle.Render("No snippets captured yet. Code blocks and answers show up here."))
+		content.WriteString("\n\n")
+		content.WriteString(hintStyle.Render("[Esc] Close"))
+	} else {
+		end := min(s.offset+snippetListRows, len(s.snippets))
+		for i := s.offset; i < end; i++ {
+			snippet := s.snippets[i]
+			title := truncate.StringWithTail(snippet.Title(), uint(maxTitleWidth), "...")
+			kind := kindStyle.Render(fmt.Sprintf("%-6s ", snippet.Kind))
+			if i == s.cursor {
+				content.WriteString("> " + kind + selectedStyle.Render(title))
+			} else {
+				content.WriteString("  " + kind + normalStyle.Render(title))
+			}
+			content.WriteString("\n")
+		}
+		if len(s.snippets) > snippetListRows {
+			content.WriteString(hintStyle.Render(fmt.Sprintf("  %d/%d", s.cursor+1, len(s.snippets))))
+			content.WriteString("\n")
+		}
+
+		// Preview of the selected snippet
+		lines := strings.Split(s.snippets[s.cursor].Content, "\n")
+		if len(lines) > snippetPreviewRows {
+			lines = append(lines[:snippetPreviewRows], fmt.Sprintf("... %d more lines", len(lines)-snippetPreviewRows))
+		}
+		content.WriteString(previewStyle.Render(strings.Join(lines, "\n")))
+		content.WriteString("\n")
+
+		if s.status != "" {
+			content.WriteString(selectedStyle.Render(s.status))
+		} else {
+			content.WriteString(hintStyle.Render("[y] Copy  [s/Enter] Send to session  [Esc] Close  [↑/↓] Navigate"))
+		}
+	}
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7aa2f7")).
+		Padding(1, 2).
+		Width(s.width)
+
+	return borderStyle.Render(content.String())
+}
+
+// SetWidth sets the width of the overlay
+func (s *SnippetsOverlay) SetWidth(width int) {
+	s.width = width
+}
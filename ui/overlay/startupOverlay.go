@@ -0,0 +1,141 @@
+package overlay
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+// startupFailureRows is how many failures are listed at once.
+const startupFailureRows = 10
+
+// startupFailure is a session that could not be restored.
+type startupFailure struct {
+	title string
+	err   error
+}
+
+// StartupOverlay shows the progress of restoring the saved sessions at startup,
+// and once done, the sessions that could not be restored.
+type StartupOverlay struct {
+	Dismissed bool
+	// RetryRequested is set when the user asks to restore the failed sessions again
+	RetryRequested bool
+
+	total    int
+	restored int
+	failures []startupFailure
+	width    int
+}
+
+// NewStartupOverlay creates a startup overlay for restoring total sessions.
+func NewStartupOverlay(total int) *StartupOverlay {
+	return &StartupOverlay{total: total, width: 60}
+}
+
+// Restored records that restoring the session of the given instance finished,
+// with the error if it failed.
+func (s *StartupOverlay) Restored(title string, err error) {
+	s.restored++
+	if err != nil {
+		s.failures = append(s.failures, startupFailure{title: title, err: err})
+	}
+}
+
+// Done returns true once every session has been restored or has failed.
+func (s *StartupOverlay) Done() bool {
+	return s.restored >= s.total
+}
+
+// Failed returns how many sessions could not be restored.
+func (s *StartupOverlay) Failed() int {
+	return len(s.failures)
+}
+
+// HandleKeyPress processes a key press and updates the state.
+// Returns true if the overlay should be closed.
+func (s *StartupOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "esc", "q", "enter":
+		s.Dismissed = true
+		return true
+	case "r":
+		if s.Done() && len(s.failures) > 0 {
+			s.RetryRequested = true
+		}
+	}
+	return false
+}
+
+// Render renders the startup overlay
+func (s *StartupOverlay) Render(opts ...WhitespaceOption) string {
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#FFFFFF"))
+
+	normalStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#AAAAAA"))
+
+	progressStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7aa2f7"))
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#f7768e"))
+
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#666666"))
+
+	// Leave room for the border and padding
+	lineWidth := max(s.width-6, 10)
+
+	var content strings.Builder
+	if !s.Done() {
+		content.WriteString(titleStyle.Render("Restoring sessions"))
+		content.WriteString("\n\n")
+		barWidth := max(lineWidth-12, 10)
+		filled := barWidth * s.restored / max(s.total, 1)
+		content.WriteString(progressStyle.Render(strings.Repeat("█", filled)))
+		content.WriteString(hintStyle.Render(strings.Repeat("░", barWidth-filled)))
+		content.WriteString(normalStyle.Render(fmt.Sprintf(" %d/%d", s.restored, s.total)))
+		content.WriteString("\n")
+	} else {
+		content.WriteString(titleStyle.Render(fmt.Sprintf("%d of %d sessions could not be restored", len(s.failures), s.total)))
+		content.WriteString("\n")
+	}
+
+	if len(s.failures) > 0 {
+		content.WriteString("\n")
+		for i, failure := range s.failures {
+			if i == startupFailureRows {
+				content.WriteString(normalStyle.Render(fmt.Sprintf("… %d more", len(s.failures)-startupFailureRows)))
+				content.WriteString("\n")
+				break
+			}
+			line := fmt.Sprintf("%s: %v", failure.title, failure.err)
+			content.WriteString(errorStyle.Render(truncate.StringWithTail(line, uint(lineWidth), "...")))
+			content.WriteString("\n")
+		}
+	}
+
+	content.WriteString("\n")
+	if s.Done() {
+		content.WriteString(hintStyle.Render("[r] Retry  [Esc] Close"))
+	} else {
+		content.WriteString(hintStyle.Render("[Esc] Continue in the background"))
+	}
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7aa2f7")).
+		Padding(1, 2).
+		Width(s.width)
+
+	return borderStyle.Render(content.String())
+}
+
+// SetWidth sets the width of the overlay
+func (s *StartupOverlay) SetWidth(width int) {
+	s.width = width
+}
@@ -8,13 +8,20 @@ import (
 
 // TextInputOverlay represents a text input overlay with state management.
 type TextInputOverlay struct {
-	textarea      textarea.Model
-	Title         string
-	FocusIndex    int // 0 for text input, 1 for enter button
-	Submitted     bool
-	Canceled      bool
-	OnSubmit      func()
-	width, height int
+	textarea   textarea.Model
+	Title      string
+	FocusIndex int // 0 for text input, 1 for enter button
+	Submitted  bool
+	Canceled   bool
+	OnSubmit   func()
+	// EditorRequested is set when the user asks to edit the text in the
+	// external editor. The caller resets it.
+	EditorRequested bool
+	// FileLoadEnabled offers loading the text from a file with ctrl+l, which
+	// sets FileRequested. The caller resets it.
+	FileLoadEnabled bool
+	FileRequested   bool
+	width, height   int
 }
 
 // NewTextInputOverlay creates a new text input overlay with the given title and initial value.
@@ -81,6 +88,12 @@ func (t *TextInputOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
 	case tea.KeyEsc:
 		t.Canceled = true
 		return true
+	case tea.KeyCtrlO:
+		t.EditorRequested = true
+		return false
+	case tea.KeyCtrlL:
+		t.FileRequested = t.FileLoadEnabled
+		return false
 	case tea.KeyEnter:
 		if t.FocusIndex == 1 {
 			// Enter button is focused, so submit.
@@ -104,6 +117,11 @@ func (t *TextInputOverlay) GetValue() string {
 	return t.textarea.Value()
 }
 
+// SetValue replaces the text of the input.
+func (t *TextInputOverlay) SetValue(value string) {
+	t.textarea.SetValue(value)
+}
+
 // IsSubmitted returns whether the form was submitted.
 func (t *TextInputOverlay) IsSubmitted() bool {
 	return t.Submitted
@@ -154,7 +172,11 @@ func (t *TextInputOverlay) Render() string {
 	} else {
 		enterButton = buttonStyle.Render(enterButton)
 	}
-	content += enterButton
+	hint := "ctrl+o edit in $EDITOR"
+	if t.FileLoadEnabled {
+		hint += "  ctrl+l load from file"
+	}
+	content += enterButton + "  " + lipgloss.NewStyle().Foreground(lipgloss.Color("7")).Faint(true).Render(hint)
 
 	return style.Render(content)
 }
@@ -50,6 +50,11 @@ func (t *TextOverlay) Render(opts ...WhitespaceOption) string {
 	return style.Render(t.content)
 }
 
+// SetContent replaces the content of the overlay, e.g. to refresh it.
+func (t *TextOverlay) SetContent(content string) {
+	t.content = content
+}
+
 func (t *TextOverlay) SetWidth(width int) {
 	t.width = width
 }
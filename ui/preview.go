@@ -1,17 +1,38 @@
 package ui
 
 import (
+	"claude-squad/log"
 	"claude-squad/session"
+	"claude-squad/session/git"
 	"fmt"
+	"hash/fnv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
 var previewPaneStyle = lipgloss.NewStyle().
 	Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"})
 
+var (
+	todoHeaderStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.AdaptiveColor{Light: "#2563eb", Dark: "#7aa2f7"})
+	todoCompletedStyle  = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#888888", Dark: "#666666"}).Strikethrough(true)
+	todoInProgressStyle = lipgloss.NewStyle().Bold(true)
+	todoPendingStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#666666", Dark: "#888888"})
+	staleBaseStyle      = lipgloss.NewStyle().Bold(true).Foreground(StatusWarning)
+	auxTabActiveStyle   = lipgloss.NewStyle().Bold(true).Underline(true)
+	auxTabStyle         = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#666666", Dark: "#888888"})
+	// ciLineStyles color the state of the CI checks above the preview
+	ciLineStyles = map[git.CIState]lipgloss.Style{
+		git.CIPending: lipgloss.NewStyle().Foreground(StatusRunning),
+		git.CIPassed:  lipgloss.NewStyle().Foreground(StatusSuccess),
+		git.CIFailed:  lipgloss.NewStyle().Bold(true).Foreground(StatusError),
+	}
+)
+
 type PreviewPane struct {
 	width  int
 	height int
@@ -19,6 +40,83 @@ type PreviewPane struct {
 	previewState previewState
 	isScrolling  bool
 	viewport     viewport.Model
+
+	// ci is the state of the CI checks of the pushed branch, shown above the
+	// preview, and branch the name of the branch
+	ci     *git.CIStatus
+	branch string
+
+	// todos is the agent's todo list shown above the preview
+	todos []session.TranscriptTodo
+	// todosCollapsed shows only the todo progress instead of the full list
+	todosCollapsed bool
+
+	// staleBase is set when the branch lacks at least staleBaseCommits commits
+	// of the default branch, to warn above the preview
+	staleBase        *git.BaseStatus
+	staleBaseCommits int
+
+	// historyMaxLines and historyMaxBytes limit each step of scrollback history
+	// loaded in scroll mode
+	historyMaxLines int
+	historyMaxBytes int
+	// historyPages is how many steps of history are loaded
+	historyPages int
+	// historyTruncated is true if there is earlier history than what is loaded
+	historyTruncated bool
+	// historyLoading is true while history is captured in the background for
+	// historyInstance
+	historyLoading  bool
+	historyInstance *session.Instance
+	// historyLines is how many lines of history are loaded, and historyTop how
+	// many hint lines are shown above them
+	historyLines int
+	historyTop   int
+
+	// showAux shows the output of the auxiliary command instead of the agent,
+	// for instances that have one
+	showAux bool
+	// aux is the auxiliary command of the shown instance, empty if it has none,
+	// and auxRunning whether it runs
+	aux        string
+	auxRunning bool
+
+	// follow highlights the lines that changed since the previous capture
+	follow followState
+	// following is true while the preview follows a running instance
+	following bool
+
+	// capture identifies the capture of an instance shown in previewState,
+	// so an unchanged capture isn't processed again
+	capture captureKey
+	// rendered caches the output of String for the key it was rendered for
+	rendered    string
+	renderedKey renderKey
+}
+
+// captureKey identifies a capture of the pane of an instance.
+type captureKey struct {
+	instance *session.Instance
+	hash     uint64
+}
+
+// renderKey identifies what String renders in normal mode.
+type renderKey struct {
+	width, height int
+	// content is the hash of the preview text, the lines above it and the
+	// highlighted lines
+	content uint64
+}
+
+// contentHash returns a hash of the parts of a preview.
+func contentHash(parts ...string) uint64 {
+	h := fnv.New64a()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		// Keeps "ab", "c" apart from "a", "bc"
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
 }
 
 type previewState struct {
@@ -26,14 +124,101 @@ type previewState struct {
 	fallback bool
 	// text is the text displayed in the preview pane
 	text string
+	// message is the fallback text without the logo, shown when the logo
+	// doesn't fit the pane
+	message string
 }
 
 func NewPreviewPane() *PreviewPane {
 	return &PreviewPane{
-		viewport: viewport.New(0, 0),
+		viewport:        viewport.New(0, 0),
+		historyMaxLines: session.DefaultHistoryMaxLines,
+		historyMaxBytes: session.DefaultHistoryMaxBytes,
+	}
+}
+
+// SetHistoryLimits sets how much scrollback history is loaded at once in scroll
+// mode. Zero values keep the defaults.
+func (p *PreviewPane) SetHistoryLimits(maxLines, maxBytes int) {
+	if maxLines > 0 {
+		p.historyMaxLines = maxLines
+	}
+	if maxBytes > 0 {
+		p.historyMaxBytes = maxBytes
+	}
+}
+
+// SetStaleBaseCommits sets how many commits of the default branch a branch may
+// lack before the preview warns that its base is stale. 0 never warns.
+func (p *PreviewPane) SetStaleBaseCommits(commits int) {
+	p.staleBaseCommits = commits
+}
+
+// HistoryLoadedMsg carries the scrollback history captured in the background
+// for scroll mode.
+type HistoryLoadedMsg struct {
+	instance  *session.Instance
+	pages     int
+	content   string
+	truncated bool
+	err       error
+}
+
+// loadHistory captures historyPages steps of scrollback history in the
+// background, so a long dump doesn't block the key press that asked for it.
+func (p *PreviewPane) loadHistory(instance *session.Instance) tea.Cmd {
+	pages := max(p.historyPages, 1)
+	maxLines, maxBytes := p.historyMaxLines*pages, p.historyMaxBytes*pages
+	p.historyLoading = true
+	p.historyInstance = instance
+	return func() tea.Msg {
+		content, truncated, err := instance.PreviewHistory(maxLines, maxBytes)
+		return HistoryLoadedMsg{instance: instance, pages: pages, content: content, truncated: truncated, err: err}
 	}
 }
 
+// HandleHistoryLoaded shows history captured in the background. The first step
+// is shown from the bottom; earlier history keeps the lines in view in place.
+func (p *PreviewPane) HandleHistoryLoaded(msg HistoryLoadedMsg) error {
+	if !p.isScrolling || msg.instance != p.historyInstance || msg.pages != max(p.historyPages, 1) {
+		return nil
+	}
+	p.historyLoading = false
+	if msg.err != nil {
+		return msg.err
+	}
+
+	offset, lines, top := p.viewport.YOffset, p.historyLines, p.historyTop
+	p.historyTruncated = msg.truncated
+	p.historyLines = strings.Count(msg.content, "\n") + 1
+	p.historyTop = 0
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#808080", Dark: "#808080"})
+	content := msg.content
+	if msg.truncated {
+		content = lipgloss.JoinVertical(lipgloss.Left, hintStyle.Render("↑ Scroll up to load earlier history"), content)
+		p.historyTop = 1
+	}
+	p.viewport.SetContent(lipgloss.JoinVertical(lipgloss.Left, content, hintStyle.Render("ESC to exit scroll mode")))
+
+	if msg.pages == 1 {
+		p.viewport.GotoBottom()
+		return nil
+	}
+	// The earlier lines go above the loaded ones, and the hint above them
+	// disappears once all of the history is loaded
+	p.viewport.SetYOffset(offset - top + p.historyTop + p.historyLines - lines)
+	return nil
+}
+
+// enterScrollMode starts scroll mode, loading the end of the scrollback history.
+func (p *PreviewPane) enterScrollMode(instance *session.Instance) tea.Cmd {
+	p.isScrolling = true
+	p.historyPages = 1
+	p.historyLines, p.historyTop = 0, 0
+	p.viewport.SetContent("Loading history...")
+	return p.loadHistory(instance)
+}
+
 func (p *PreviewPane) SetSize(width, maxHeight int) {
 	p.width = width
 	p.height = maxHeight
@@ -43,18 +228,35 @@ func (p *PreviewPane) SetSize(width, maxHeight int) {
 
 // setFallbackState sets the preview state with fallback text and a message
 func (p *PreviewPane) setFallbackState(message string) {
+	p.follow.reset()
+	p.capture = captureKey{}
 	p.previewState = previewState{
 		fallback: true,
 		text:     lipgloss.JoinVertical(lipgloss.Center, FallBackText, "", message),
+		message:  message,
 	}
 }
 
 // Updates the preview pane content with the multiplexer pane content
 func (p *PreviewPane) UpdateContent(instance *session.Instance) error {
+	p.todos = nil
+	p.staleBase = nil
+	p.ci = nil
+	p.following = false
+	p.aux = ""
 	switch {
 	case instance == nil:
 		p.setFallbackState("No agents running yet. Spin up a new instance with 'n' to get started!")
 		return nil
+	case instance.RestorePending() && instance.RestoreError() != nil:
+		p.setFallbackState(fmt.Sprintf("Could not restore the session: %v", instance.RestoreError()))
+		return nil
+	case instance.RestorePending():
+		p.setFallbackState("Restoring session...")
+		return nil
+	case instance.Status == session.Pending:
+		p.setFallbackState("Waiting for a free slot. The session starts once fewer agents are working than max_running allows.")
+		return nil
 	case instance.Status == session.Paused:
 		p.setFallbackState(lipgloss.JoinVertical(lipgloss.Center,
 			"Session is paused. Press 'r' to resume.",
@@ -72,40 +274,49 @@ func (p *PreviewPane) UpdateContent(instance *session.Instance) error {
 		return nil
 	}
 
-	var content string
-	var err error
-
-	// If in scroll mode but haven't captured content yet, do it now
-	if p.isScrolling && p.viewport.Height > 0 && len(p.viewport.View()) == 0 {
-		// Capture full pane content including scrollback history using capture-pane -p -S -
-		content, err = instance.PreviewFullHistory()
-		if err != nil {
-			return err
+	if instance.AuxCommand != "" && instance.Started() {
+		p.aux, p.auxRunning = instance.AuxCommand, instance.AuxRunning()
+		if p.showAux {
+			return p.updateAuxContent(instance)
 		}
+	}
 
-		// Set content in the viewport
-		footer := lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#808080", Dark: "#808080"}).
-			Render("ESC to exit scroll mode")
+	var content string
+	var err error
 
-		p.viewport.SetContent(lipgloss.JoinVertical(lipgloss.Left, content, footer))
-	} else if !p.isScrolling {
+	// In scroll mode, the history is loaded in the background by HistoryLoadedMsg
+	if !p.isScrolling {
 		// In normal mode, use the usual preview
+		start := time.Now()
 		content, err = instance.Preview()
+		log.GetProfiler().RecordDuration("previewCapture", time.Since(start))
 		if err != nil {
 			return err
 		}
+		defer log.GetProfiler().StartRender("previewUpdate")()
 
 		// Always update the preview state with content, even if empty
 		// This ensures that newly created instances will display their content immediately
 		if len(content) == 0 && !instance.Started() {
 			p.setFallbackState("Please enter a name for the instance.")
 		} else {
-			// Update the preview state with the current content
-			p.previewState = previewState{
-				fallback: false,
-				text:     content,
+			// Most ticks capture the same screen as the last one, which is
+			// already shown and diffed
+			capture := captureKey{instance: instance, hash: contentHash(content)}
+			if capture != p.capture {
+				p.previewState = previewState{
+					fallback: false,
+					text:     content,
+				}
+				p.follow.update(instance, content, time.Now())
+				p.capture = capture
+			}
+			p.todos = instance.Todos()
+			if base := instance.BaseStatus(); base != nil && p.staleBaseCommits > 0 && base.Behind >= p.staleBaseCommits {
+				p.staleBase = base
 			}
+			p.ci, p.branch = instance.CIStatus(), instance.Branch
+			p.following = instance.Status == session.Running
 		}
 	}
 
@@ -122,8 +333,13 @@ func (p *PreviewPane) String() string {
 		// Calculate available height for fallback text
 		availableHeight := p.height - 3 - 4 // 2 for borders, 1 for margin, 1 for padding
 
+		text := p.previewState.text
+		if lipgloss.Width(text) > p.width || lipgloss.Height(text) > availableHeight {
+			text = p.previewState.message
+		}
+
 		// Count the number of lines in the fallback text
-		fallbackLines := len(strings.Split(p.previewState.text, "\n"))
+		fallbackLines := len(strings.Split(text, "\n"))
 
 		// Calculate padding needed above and below to center the content
 		totalPadding := availableHeight - fallbackLines
@@ -139,7 +355,7 @@ func (p *PreviewPane) String() string {
 		if topPadding > 0 {
 			lines = append(lines, strings.Repeat("\n", topPadding))
 		}
-		lines = append(lines, p.previewState.text)
+		lines = append(lines, text)
 		if bottomPadding > 0 {
 			lines = append(lines, strings.Repeat("\n", bottomPadding))
 		}
@@ -157,10 +373,38 @@ func (p *PreviewPane) String() string {
 	}
 
 	// Normal mode display
+	todoLines := append(p.renderAuxTabs(), p.renderStaleBase()...)
+	todoLines = append(todoLines, p.renderCI()...)
+	todoLines = append(todoLines, p.renderTodos()...)
+
 	// Calculate available height accounting for border and margin
-	availableHeight := p.height - 1 //  1 for ellipsis
+	availableHeight := p.height - 1 - len(todoLines) //  1 for ellipsis
 
 	lines := strings.Split(p.previewState.text, "\n")
+	now := time.Now()
+	var highlighted []bool
+	for i := range lines {
+		highlighted = append(highlighted, p.follow.highlighted(i, now))
+	}
+	// Styling the preview is the most expensive part of a frame, and most
+	// frames show what the previous one did
+	key := renderKey{
+		width:   p.width,
+		height:  p.height,
+		content: contentHash(p.previewState.text, strings.Join(todoLines, "\n"), fmt.Sprint(highlighted)),
+	}
+	if key == p.renderedKey && p.rendered != "" {
+		return p.rendered
+	}
+	defer log.GetProfiler().StartRender("previewStyle")()
+
+	for i := range lines {
+		if highlighted[i] {
+			lines[i] = followHighlightStyle.Render(p.follow.lines[i])
+		}
+		// Lines wider than the pane would wrap and push the screen out of line
+		lines[i] = clipStyledLine(lines[i], p.width)
+	}
 
 	// Truncate if we have more lines than available height
 	if availableHeight > 0 {
@@ -174,72 +418,177 @@ func (p *PreviewPane) String() string {
 		}
 	}
 
-	content := strings.Join(lines, "\n")
-	rendered := previewPaneStyle.Width(p.width).Render(content)
-	return rendered
+	content := strings.Join(append(todoLines, lines...), "\n")
+	p.rendered = previewPaneStyle.Width(p.width).Render(content)
+	p.renderedKey = key
+	return p.rendered
 }
 
-// ScrollUp scrolls up in the viewport
-func (p *PreviewPane) ScrollUp(instance *session.Instance) error {
-	if instance == nil || instance.Status == session.Paused {
+// Following returns true while the preview follows a running instance, as
+// opposed to showing scrollback history or a message.
+func (p *PreviewPane) Following() bool {
+	return p.following && !p.isScrolling && !p.previewState.fallback
+}
+
+// ToggleAux switches between the output of the agent and of the auxiliary
+// command.
+func (p *PreviewPane) ToggleAux() {
+	p.showAux = !p.showAux
+	p.isScrolling = false
+	p.follow.reset()
+}
+
+// ShowingAux returns true if the output of an auxiliary command is shown.
+func (p *PreviewPane) ShowingAux() bool {
+	return p.showAux && p.aux != ""
+}
+
+// updateAuxContent shows the output of the auxiliary command of the instance.
+func (p *PreviewPane) updateAuxContent(instance *session.Instance) error {
+	p.isScrolling = false
+	p.follow.reset()
+	p.capture = captureKey{}
+	content, err := instance.AuxPreview()
+	if err != nil {
+		content = fmt.Sprintf("Could not show the output of %s: %v", instance.AuxCommand, err)
+	}
+	p.previewState = previewState{text: content}
+	return nil
+}
+
+// renderAuxTabs renders the tabs for the agent and the auxiliary command
+// followed by a blank line, or nothing if there is no auxiliary command.
+func (p *PreviewPane) renderAuxTabs() []string {
+	if p.aux == "" {
 		return nil
 	}
+	status := "exited"
+	if p.auxRunning {
+		status = "running"
+	}
+	label, hint := p.aux+" · "+status, "   O switches"
+	avail := p.width - textWidth("Agent │ ")
+	if textWidth(label)+textWidth(hint) > avail {
+		hint = ""
+	}
+	label = truncateLine(label, max(avail, 0))
 
-	if !p.isScrolling {
-		// Entering scroll mode - capture entire pane content including scrollback history
-		content, err := instance.PreviewFullHistory()
-		if err != nil {
-			return err
-		}
+	agent, aux := auxTabActiveStyle.Render("Agent"), auxTabStyle.Render(label)
+	if p.showAux {
+		agent, aux = auxTabStyle.Render("Agent"), auxTabActiveStyle.Render(label)
+	}
+	return []string{agent + auxTabStyle.Render(" │ ") + aux + auxTabStyle.Render(hint), ""}
+}
 
-		// Set content in the viewport
-		footer := lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#808080", Dark: "#808080"}).
-			Render("ESC to exit scroll mode")
+// ToggleTodos collapses or expands the todo list above the preview
+func (p *PreviewPane) ToggleTodos() {
+	p.todosCollapsed = !p.todosCollapsed
+}
 
-		contentWithFooter := lipgloss.JoinVertical(lipgloss.Left, content, footer)
-		p.viewport.SetContent(contentWithFooter)
+// renderStaleBase renders the warning that the branch is far behind the
+// default branch followed by a blank line, or nothing if it isn't.
+func (p *PreviewPane) renderStaleBase() []string {
+	if p.staleBase == nil {
+		return nil
+	}
+	warning := fmt.Sprintf("⚠ Base is %d commits behind %s · press M to merge %s into the branch",
+		p.staleBase.Behind, p.staleBase.DefaultBranch, p.staleBase.DefaultBranch)
+	return []string{staleBaseStyle.Render(truncateLine(warning, p.width)), ""}
+}
 
-		// Position the viewport at the bottom initially
-		p.viewport.GotoBottom()
+// renderCI renders the state of the CI checks of the branch followed by a
+// blank line, or nothing if it wasn't pushed or has no checks.
+func (p *PreviewPane) renderCI() []string {
+	if p.ci == nil {
+		return nil
+	}
+	var line string
+	switch p.ci.State {
+	case git.CIPending:
+		line = fmt.Sprintf("◌ CI running on %s", p.branch)
+	case git.CIPassed:
+		line = fmt.Sprintf("✓ CI passed on %s", p.branch)
+	case git.CIFailed:
+		line = fmt.Sprintf("✗ CI failed on %s · press G to open the failed run", p.branch)
+	}
+	return []string{ciLineStyles[p.ci.State].Render(truncateLine(line, p.width)), ""}
+}
 
-		p.isScrolling = true
+// renderTodos renders the agent's todo list followed by a blank line, or
+// nothing if there is none. The full list takes at most half of the pane.
+func (p *PreviewPane) renderTodos() []string {
+	completed, total := session.TodoProgress(p.todos)
+	if total == 0 {
 		return nil
 	}
 
-	// Already in scroll mode, just scroll the viewport
-	p.viewport.LineUp(1)
-	return nil
+	maxItems := p.height/2 - 2
+	if p.todosCollapsed || maxItems < 1 {
+		header := fmt.Sprintf("▸ Tasks %d/%d", completed, total)
+		for _, todo := range p.todos {
+			if todo.Status == session.TodoInProgress {
+				header += " · " + todo.ActiveForm
+				break
+			}
+		}
+		return []string{todoHeaderStyle.Render(truncateLine(header, p.width)), ""}
+	}
+
+	lines := []string{todoHeaderStyle.Render(fmt.Sprintf("▾ Tasks %d/%d", completed, total))}
+	for idx, todo := range p.todos {
+		if idx == maxItems && len(p.todos) > maxItems {
+			lines = append(lines, todoPendingStyle.Render(fmt.Sprintf("  … %d more", len(p.todos)-maxItems)))
+			break
+		}
+		var line string
+		var style lipgloss.Style
+		switch todo.Status {
+		case session.TodoCompleted:
+			line, style = "  ✓ "+todo.Content, todoCompletedStyle
+		case session.TodoInProgress:
+			line, style = "  ▶ "+todo.Content, todoInProgressStyle
+		default:
+			line, style = "  ○ "+todo.Content, todoPendingStyle
+		}
+		lines = append(lines, style.Render(truncateLine(line, p.width)))
+	}
+	return append(lines, "")
 }
 
-// ScrollDown scrolls down in the viewport
-func (p *PreviewPane) ScrollDown(instance *session.Instance) error {
-	if instance == nil || instance.Status == session.Paused {
+// ScrollUp scrolls up in the viewport. The returned command loads the history
+// when entering scroll mode, or earlier history once the top is reached.
+func (p *PreviewPane) ScrollUp(instance *session.Instance) tea.Cmd {
+	if instance == nil || instance.Status == session.Paused || p.ShowingAux() {
 		return nil
 	}
 
 	if !p.isScrolling {
-		// Entering scroll mode - capture entire pane content including scrollback history
-		content, err := instance.PreviewFullHistory()
-		if err != nil {
-			return err
-		}
-
-		// Set content in the viewport
-		footer := lipgloss.NewStyle().
-			Foreground(lipgloss.AdaptiveColor{Light: "#808080", Dark: "#808080"}).
-			Render("ESC to exit scroll mode")
+		return p.enterScrollMode(instance)
+	}
 
-		contentWithFooter := lipgloss.JoinVertical(lipgloss.Left, content, footer)
-		p.viewport.SetContent(contentWithFooter)
+	// Load earlier history on demand once the top of what is loaded is reached
+	var cmd tea.Cmd
+	if p.viewport.AtTop() && p.historyTruncated && !p.historyLoading {
+		p.historyPages++
+		cmd = p.loadHistory(instance)
+	}
 
-		// Position the viewport at the bottom initially
-		p.viewport.GotoBottom()
+	// Already in scroll mode, just scroll the viewport
+	p.viewport.LineUp(1)
+	return cmd
+}
 
-		p.isScrolling = true
+// ScrollDown scrolls down in the viewport. The returned command loads the
+// history when entering scroll mode.
+func (p *PreviewPane) ScrollDown(instance *session.Instance) tea.Cmd {
+	if instance == nil || instance.Status == session.Paused || p.ShowingAux() {
 		return nil
 	}
 
+	if !p.isScrolling {
+		return p.enterScrollMode(instance)
+	}
+
 	// Already in copy mode, just scroll the viewport
 	p.viewport.LineDown(1)
 	return nil
@@ -253,6 +602,10 @@ func (p *PreviewPane) ResetToNormalMode(instance *session.Instance) error {
 
 	if p.isScrolling {
 		p.isScrolling = false
+		p.historyPages = 0
+		p.historyTruncated = false
+		p.historyLoading = false
+		p.historyInstance = nil
 		// Reset viewport
 		p.viewport.SetContent("")
 		p.viewport.GotoTop()
@@ -263,6 +616,7 @@ func (p *PreviewPane) ResetToNormalMode(instance *session.Instance) error {
 			return err
 		}
 		p.previewState.text = content
+		p.follow.update(instance, content, time.Now())
 	}
 
 	return nil
@@ -4,14 +4,18 @@ import (
 	"claude-squad/cmd/cmd_test"
 	"claude-squad/log"
 	"claude-squad/session"
+	"claude-squad/session/git"
 	"claude-squad/session/zellij"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/require"
 )
 
@@ -223,8 +227,7 @@ func TestPreviewScrolling(t *testing.T) {
 	require.Contains(t, fullHistory, "1", "Full history should contain earliest output")
 
 	// Step 3: Enter scroll mode
-	err = previewPane.ScrollUp(setup.instance)
-	require.NoError(t, err)
+	runHistoryCmd(t, previewPane, previewPane.ScrollUp(setup.instance))
 
 	// Verify we entered scrolling mode
 	require.True(t, previewPane.isScrolling, "Should be in scrolling mode after ScrollUp")
@@ -238,8 +241,7 @@ func TestPreviewScrolling(t *testing.T) {
 
 	// Step 5: Scroll up multiple times to get to the top
 	for range 50 {
-		err = previewPane.ScrollUp(setup.instance)
-		require.NoError(t, err)
+		runHistoryCmd(t, previewPane, previewPane.ScrollUp(setup.instance))
 	}
 
 	// Now get the viewport content after scrolling up
@@ -248,8 +250,7 @@ func TestPreviewScrolling(t *testing.T) {
 
 	// Step 6: Scroll down multiple times
 	for range 25 {
-		err = previewPane.ScrollDown(setup.instance)
-		require.NoError(t, err)
+		runHistoryCmd(t, previewPane, previewPane.ScrollDown(setup.instance))
 	}
 
 	// Get updated viewport content after scrolling down
@@ -357,3 +358,160 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+func TestRenderTodos(t *testing.T) {
+	p := NewPreviewPane()
+	p.SetSize(40, 20)
+	require.Empty(t, p.renderTodos(), "no todo section without todos")
+
+	p.todos = []session.TranscriptTodo{
+		{Content: "Find the flake", Status: session.TodoCompleted, ActiveForm: "Finding the flake"},
+		{Content: "Fix the race", Status: session.TodoInProgress, ActiveForm: "Fixing the race"},
+		{Content: "Run the tests", Status: session.TodoPending, ActiveForm: "Running the tests"},
+	}
+
+	expanded := p.renderTodos()
+	require.Len(t, expanded, 5, "header, one line per todo and a blank line")
+	require.Contains(t, expanded[0], "Tasks 1/3")
+	require.Contains(t, expanded[2], "▶ Fix the race")
+
+	p.ToggleTodos()
+	collapsed := p.renderTodos()
+	require.Len(t, collapsed, 2, "header and a blank line")
+	require.Contains(t, collapsed[0], "Tasks 1/3 · Fixing the race")
+
+	// The preview keeps its height with the todo section above it
+	p.previewState = previewState{text: "agent output"}
+	withTodos := len(strings.Split(p.String(), "\n"))
+	p.todos = nil
+	require.Equal(t, len(strings.Split(p.String(), "\n")), withTodos)
+}
+
+func TestRenderStaleBase(t *testing.T) {
+	p := NewPreviewPane()
+	p.SetSize(80, 20)
+	require.Empty(t, p.renderStaleBase(), "no warning while the base is current")
+
+	p.staleBase = &git.BaseStatus{DefaultBranch: "main", Behind: 214}
+	lines := p.renderStaleBase()
+	require.Len(t, lines, 2, "warning and a blank line")
+	require.Contains(t, lines[0], "Base is 214 commits behind main")
+
+	// The preview keeps its height with the warning above it
+	p.previewState = previewState{text: "agent output"}
+	withWarning := len(strings.Split(p.String(), "\n"))
+	p.staleBase = nil
+	require.Equal(t, len(strings.Split(p.String(), "\n")), withWarning)
+}
+
+func TestPreviewCachesRender(t *testing.T) {
+	p := NewPreviewPane()
+	p.SetSize(40, 10)
+	p.previewState = previewState{text: "\x1b[38;2;215;119;87magent output\x1b[0m"}
+	first := p.String()
+	require.Contains(t, first, "agent output")
+
+	// An unchanged preview isn't styled again
+	p.rendered = "cached"
+	require.Equal(t, "cached", p.String())
+
+	// A change of the content or the size renders it again
+	p.previewState = previewState{text: "more output"}
+	require.Contains(t, p.String(), "more output")
+	p.rendered = "cached"
+	p.SetSize(50, 10)
+	require.Contains(t, p.String(), "more output")
+	p.rendered = "cached"
+	p.todos = []session.TranscriptTodo{{Content: "Run the tests", Status: session.TodoPending}}
+	require.Contains(t, p.String(), "Run the tests")
+}
+
+func TestFollowHighlightsChangedLines(t *testing.T) {
+	start := time.Now()
+	var f followState
+	f.update("instance", "one\ntwo\nthree\n\n> prompt", start)
+	for i := range f.lines {
+		require.False(t, f.highlighted(i, start), "the first capture highlights nothing")
+	}
+
+	// Output scrolled up by one line and the prompt box changed
+	later := start.Add(time.Second)
+	f.update("instance", "two\nthree\n\x1b[32mfour\x1b[0m\n\n> prompt again", later)
+	var highlighted []string
+	for i, line := range f.lines {
+		if f.highlighted(i, later) {
+			highlighted = append(highlighted, line)
+		}
+	}
+	require.Equal(t, []string{"four", "> prompt again"}, highlighted)
+
+	// Highlights move with the output and fade after a while
+	f.update("instance", "three\nfour\n\n> prompt again", later.Add(time.Second))
+	require.True(t, f.highlighted(1, later.Add(time.Second)))
+	require.False(t, f.highlighted(1, later.Add(followHighlightDuration)))
+
+	// Switching instances starts over
+	f.update("other", "five", later)
+	require.False(t, f.highlighted(0, later))
+}
+
+func TestScrollLoadsEarlierHistory(t *testing.T) {
+	var history strings.Builder
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&history, "line %d\n", i)
+	}
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error {
+			if strings.Contains(cmd.String(), "dump-screen") {
+				return os.WriteFile(cmd.Args[len(cmd.Args)-1], []byte(history.String()), 0644)
+			}
+			return nil
+		},
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			return []byte(""), nil
+		},
+	}
+	setup := setupTestEnvironment(t, cmdExec, "test-history-limit")
+	defer setup.cleanupFn()
+
+	p := NewPreviewPane()
+	p.SetSize(40, 10)
+	p.SetHistoryLimits(20, 0)
+
+	// Entering scroll mode only loads the end of the history
+	runHistoryCmd(t, p, p.ScrollUp(setup.instance))
+	require.True(t, p.historyTruncated)
+	require.Less(t, p.viewport.TotalLineCount(), 30)
+	require.Contains(t, p.viewport.View(), "line 100")
+
+	// Loading earlier history keeps the lines in view in place
+	for !p.viewport.AtTop() {
+		runHistoryCmd(t, p, p.ScrollUp(setup.instance))
+	}
+	row := func(line string) int {
+		return slices.IndexFunc(strings.Split(p.viewport.View(), "\n"), func(shown string) bool {
+			return strings.TrimSpace(shown) == line
+		})
+	}
+	before := row("line 81")
+	require.NotEqual(t, -1, before)
+	runHistoryCmd(t, p, p.ScrollUp(setup.instance))
+	require.Equal(t, before, row("line 81"))
+
+	// Scrolling past the top loads earlier history until all of it is loaded
+	for range 200 {
+		runHistoryCmd(t, p, p.ScrollUp(setup.instance))
+	}
+	require.False(t, p.historyTruncated)
+	require.True(t, strings.HasPrefix(p.viewport.View(), "line 1 "), "the first line of the history is shown")
+	require.NotContains(t, p.viewport.View(), "Scroll up to load earlier history")
+}
+
+// runHistoryCmd runs the command loading the history of scroll mode, as the
+// app does, and shows the history it loaded.
+func runHistoryCmd(t *testing.T, p *PreviewPane, cmd tea.Cmd) {
+	t.Helper()
+	if cmd != nil {
+		require.NoError(t, p.HandleHistoryLoaded(cmd().(HistoryLoadedMsg)))
+	}
+}
@@ -3,6 +3,9 @@ package ui
 import (
 	"claude-squad/log"
 	"claude-squad/session"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -28,11 +31,14 @@ var (
 	windowStyle = lipgloss.NewStyle().
 			BorderForeground(highlightColor).
 			Border(lipgloss.NormalBorder(), false, true, true, true)
+	inlineAttachStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9a6700", Dark: "#e0af68"})
 )
 
 const (
 	PreviewTab int = iota
 	DiffTab
+	FilesTab
+	ChecksTab
 )
 
 type Tab struct {
@@ -51,20 +57,28 @@ type TabbedWindow struct {
 
 	preview  *PreviewPane
 	diff     *DiffPane
+	files    *FilesPane
+	checks   *ChecksPane
 	instance *session.Instance
 
 	// simplifiedMode uses minimal tab styling for narrow terminals
 	simplifiedMode bool
+	// inlineAttach is true while keys are forwarded to the instance in the preview
+	inlineAttach bool
 }
 
-func NewTabbedWindow(preview *PreviewPane, diff *DiffPane) *TabbedWindow {
+func NewTabbedWindow(preview *PreviewPane, diff *DiffPane, files *FilesPane, checks *ChecksPane) *TabbedWindow {
 	return &TabbedWindow{
 		tabs: []string{
 			"Preview",
 			"Diff",
+			"Files",
+			"Checks",
 		},
 		preview: preview,
 		diff:    diff,
+		files:   files,
+		checks:  checks,
 	}
 }
 
@@ -91,12 +105,31 @@ func (w *TabbedWindow) SetSize(width, height int) {
 
 	w.preview.SetSize(contentWidth, contentHeight)
 	w.diff.SetSize(contentWidth, contentHeight)
+	w.files.SetSize(contentWidth, contentHeight)
+	w.checks.SetSize(contentWidth, contentHeight)
 }
 
 func (w *TabbedWindow) GetPreviewSize() (width, height int) {
 	return w.preview.width, w.preview.height
 }
 
+// SelectTab makes the tab, like PreviewTab, active.
+func (w *TabbedWindow) SelectTab(tab int) {
+	w.activeTab = tab
+}
+
+// ToggleAux switches the preview tab between the output of the agent and of
+// the auxiliary command, and shows the preview tab.
+func (w *TabbedWindow) ToggleAux() {
+	w.preview.ToggleAux()
+	w.activeTab = PreviewTab
+}
+
+// SetInlineAttach marks the preview tab as receiving the typed keys.
+func (w *TabbedWindow) SetInlineAttach(inlineAttach bool) {
+	w.inlineAttach = inlineAttach
+}
+
 func (w *TabbedWindow) Toggle() {
 	w.activeTab = (w.activeTab + 1) % len(w.tabs)
 }
@@ -119,11 +152,46 @@ func (w *TabbedWindow) UpdatePreview(instance *session.Instance) error {
 	return w.preview.UpdateContent(instance)
 }
 
-func (w *TabbedWindow) UpdateDiff(instance *session.Instance) {
+// UpdateDiff updates the diff pane. The returned command renders the diff with
+// the external renderer, if one is configured and the diff changed.
+func (w *TabbedWindow) UpdateDiff(instance *session.Instance) tea.Cmd {
 	if w.activeTab != DiffTab {
-		return
+		return nil
 	}
 	w.diff.SetDiff(instance)
+	return w.diff.RenderCmd()
+}
+
+// HandleDiffRendered shows a diff rendered in the background.
+func (w *TabbedWindow) HandleDiffRendered(msg DiffRenderedMsg) {
+	w.diff.HandleRendered(msg)
+}
+
+// UpdateFiles updates the changed files in the files pane. instance may be nil.
+func (w *TabbedWindow) UpdateFiles(instance *session.Instance) {
+	if w.activeTab != FilesTab {
+		return
+	}
+	w.files.SetFiles(instance)
+}
+
+// ToggleFileDiff opens or closes the diff of the selected file.
+func (w *TabbedWindow) ToggleFileDiff() {
+	w.files.ToggleDiff()
+}
+
+// SelectedFile returns the path of the file selected in the files pane,
+// relative to the worktree, or an empty string if there is none.
+func (w *TabbedWindow) SelectedFile() string {
+	return w.files.SelectedPath()
+}
+
+// UpdateChecks updates the checks pane. instance may be nil.
+func (w *TabbedWindow) UpdateChecks(instance *session.Instance) {
+	if w.activeTab != ChecksTab {
+		return
+	}
+	w.checks.SetChecks(instance)
 }
 
 // ResetPreviewToNormalMode resets the preview pane to normal mode
@@ -131,32 +199,70 @@ func (w *TabbedWindow) ResetPreviewToNormalMode(instance *session.Instance) erro
 	return w.preview.ResetToNormalMode(instance)
 }
 
-// Add these new methods for handling scroll events
-func (w *TabbedWindow) ScrollUp() {
+// ScrollUp scrolls the active tab up. The returned command loads the history
+// of the preview in the background.
+func (w *TabbedWindow) ScrollUp() tea.Cmd {
 	if w.activeTab == PreviewTab {
-		err := w.preview.ScrollUp(w.instance)
-		if err != nil {
-			log.InfoLog.Printf("tabbed window failed to scroll up: %v", err)
-		}
+		return w.preview.ScrollUp(w.instance)
+	} else if w.activeTab == FilesTab {
+		w.files.Up()
+	} else if w.activeTab == ChecksTab {
+		w.checks.ScrollUp()
 	} else {
 		w.diff.ScrollUp()
 	}
+	return nil
 }
 
-func (w *TabbedWindow) ScrollDown() {
+// ScrollDown scrolls the active tab down. The returned command loads the
+// history of the preview in the background.
+func (w *TabbedWindow) ScrollDown() tea.Cmd {
 	if w.activeTab == PreviewTab {
-		err := w.preview.ScrollDown(w.instance)
-		if err != nil {
-			log.InfoLog.Printf("tabbed window failed to scroll down: %v", err)
-		}
+		return w.preview.ScrollDown(w.instance)
+	} else if w.activeTab == FilesTab {
+		w.files.Down()
+	} else if w.activeTab == ChecksTab {
+		w.checks.ScrollDown()
 	} else {
 		w.diff.ScrollDown()
 	}
+	return nil
+}
+
+// HandleHistoryLoaded shows the history loaded for the preview's scroll mode.
+func (w *TabbedWindow) HandleHistoryLoaded(msg HistoryLoadedMsg) {
+	if err := w.preview.HandleHistoryLoaded(msg); err != nil {
+		log.InfoLog.Printf("tabbed window failed to load the history: %v", err)
+	}
 }
 
 // IsInDiffTab returns true if the diff tab is currently active
 func (w *TabbedWindow) IsInDiffTab() bool {
-	return w.activeTab == 1
+	return w.activeTab == DiffTab
+}
+
+// IsInFilesTab returns true if the files tab is currently active
+func (w *TabbedWindow) IsInFilesTab() bool {
+	return w.activeTab == FilesTab
+}
+
+// IsInPreviewTab returns true if the preview tab is currently active
+func (w *TabbedWindow) IsInPreviewTab() bool {
+	return w.activeTab == PreviewTab
+}
+
+// activeContent renders the pane of the active tab
+func (w *TabbedWindow) activeContent() string {
+	switch w.activeTab {
+	case DiffTab:
+		return w.diff.String()
+	case FilesTab:
+		return w.files.String()
+	case ChecksTab:
+		return w.checks.String()
+	default:
+		return w.preview.String()
+	}
 }
 
 // GetActiveTabName returns the name of the currently active tab.
@@ -167,6 +273,11 @@ func (w *TabbedWindow) GetActiveTabName() string {
 	return ""
 }
 
+// ToggleTodos collapses or expands the todo list in the preview
+func (w *TabbedWindow) ToggleTodos() {
+	w.preview.ToggleTodos()
+}
+
 // IsPreviewInScrollMode returns true if the preview pane is in scroll mode
 func (w *TabbedWindow) IsPreviewInScrollMode() bool {
 	return w.preview.isScrolling
@@ -218,16 +329,16 @@ func (w *TabbedWindow) String() string {
 		}
 		style = style.Border(border)
 		style = style.Width(width - 1)
+		if i == PreviewTab && w.inlineAttach {
+			t += inlineAttachStyle.Render(" ⌨ typing (ctrl+q)")
+		} else if i == PreviewTab && w.preview.Following() {
+			t += followIndicatorStyle.Render(" ● follow")
+		}
 		renderedTabs = append(renderedTabs, style.Render(t))
 	}
 
 	row := lipgloss.JoinHorizontal(lipgloss.Top, renderedTabs...)
-	var content string
-	if w.activeTab == 0 {
-		content = w.preview.String()
-	} else {
-		content = w.diff.String()
-	}
+	content := w.activeContent()
 	window := windowStyle.Render(
 		lipgloss.Place(
 			w.width, w.height-2-windowStyle.GetVerticalFrameSize()-tabHeight,
@@ -259,20 +370,19 @@ func (w *TabbedWindow) renderSimplified() string {
 		} else {
 			tabText = simpleInactiveTabStyle.Render(t)
 		}
+		if i == PreviewTab && w.inlineAttach {
+			tabText += inlineAttachStyle.Render(" ⌨")
+		} else if i == PreviewTab && w.preview.Following() {
+			tabText += followIndicatorStyle.Render(" ●")
+		}
 		tabParts = append(tabParts, tabText)
 	}
 
 	// Join tabs with separator
-	tabRow := lipgloss.JoinHorizontal(lipgloss.Center, tabParts[0], " │ ", tabParts[1])
+	tabRow := strings.Join(tabParts, " │ ")
 	tabRow = lipgloss.PlaceHorizontal(w.width, lipgloss.Center, tabRow)
 
-	// Get content
-	var content string
-	if w.activeTab == 0 {
-		content = w.preview.String()
-	} else {
-		content = w.diff.String()
-	}
+	content := w.activeContent()
 
 	// Calculate content height (simpler calculation for simplified mode)
 	contentHeight := w.height - 3 // 1 for tab row + 2 for window border
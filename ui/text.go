@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/ansi"
+	"github.com/muesli/reflow/truncate"
+)
+
+// textWidth returns how many columns text takes up in the terminal. Wide
+// characters such as CJK take up two.
+func textWidth(text string) int {
+	return runewidth.StringWidth(text)
+}
+
+// truncateLine cuts a line to the given width in columns, ending it with an
+// ellipsis if there is room for one. Characters are never split.
+func truncateLine(line string, width int) string {
+	if textWidth(line) <= width {
+		return line
+	}
+	if width <= 3 {
+		return runewidth.Truncate(line, max(width, 0), "")
+	}
+	return runewidth.Truncate(line, width, "...")
+}
+
+// clipStyledLine cuts a line with ANSI escape codes, like a captured screen,
+// to the given width in columns, so it isn't wrapped onto the next row. Wide
+// characters are never split.
+func clipStyledLine(line string, width int) string {
+	if width <= 0 || ansi.PrintableRuneWidth(line) <= width {
+		return line
+	}
+	return truncate.String(line, uint(width))
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  string
+	}{
+		{"fits", "hello", 5, "hello"},
+		{"ascii", "hello world", 8, "hello..."},
+		{"cjk", "日本語のタイトル", 9, "日本語..."},
+		{"emoji", "🚀🚀🚀🚀🚀", 7, "🚀🚀..."},
+		{"no room for ellipsis", "日本語", 3, "日"},
+		{"zero width", "hello", 0, ""},
+	}
+	for _, tt := range tests {
+		got := truncateLine(tt.line, tt.width)
+		if got != tt.want {
+			t.Errorf("%s: truncateLine(%q, %d) = %q, want %q", tt.name, tt.line, tt.width, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("%s: truncateLine(%q, %d) split a character: %q", tt.name, tt.line, tt.width, got)
+		}
+		if textWidth(got) > tt.width {
+			t.Errorf("%s: truncateLine(%q, %d) is %d columns wide", tt.name, tt.line, tt.width, textWidth(got))
+		}
+	}
+}
+
+func TestClipStyledLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		width int
+		want  string
+	}{
+		{"fits", "\x1b[0;31m日本\x1b[0m", 4, "\x1b[0;31m日本\x1b[0m"},
+		{"styled", "\x1b[0;38;2;1;2;3mhello world\x1b[0m", 5, "\x1b[0;38;2;1;2;3mhello\x1b[0m"},
+		{"wide character at the edge", "日本語", 5, "日本"},
+		{"emoji", "🚀 launched", 4, "🚀 l"},
+	}
+	for _, tt := range tests {
+		got := clipStyledLine(tt.line, tt.width)
+		if got != tt.want {
+			t.Errorf("%s: clipStyledLine(%q, %d) = %q, want %q", tt.name, tt.line, tt.width, got, tt.want)
+		}
+	}
+}
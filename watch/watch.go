@@ -0,0 +1,219 @@
+package watch
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/session"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// Kinds of events.
+const (
+	// EventStatus reports the status of an instance when watching starts, and
+	// status changes that aren't covered by another event
+	EventStatus = "status"
+	// EventFinished is reported when an instance stops working
+	EventFinished = "finished"
+	// EventPrompt is reported when an instance starts waiting for input
+	EventPrompt = "prompt"
+	// EventAdded is reported when an instance is created
+	EventAdded = "added"
+	// EventRemoved is reported when an instance is killed or archived
+	EventRemoved = "removed"
+)
+
+// Event is a change of an instance.
+type Event struct {
+	Time     time.Time `json:"time"`
+	Kind     string    `json:"event"`
+	Instance string    `json:"instance"`
+	Branch   string    `json:"branch,omitempty"`
+	Status   string    `json:"status"`
+	Previous string    `json:"previous,omitempty"`
+}
+
+// String formats the event as a line of text.
+func (e Event) String() string {
+	var message string
+	switch e.Kind {
+	case EventFinished:
+		message = "finished"
+	case EventPrompt:
+		message = "waiting for input"
+	case EventAdded:
+		message = "added, " + e.Status
+	case EventRemoved:
+		message = "removed"
+	default:
+		message = e.Status
+		if e.Previous != "" {
+			message = e.Previous + " -> " + e.Status
+		}
+	}
+	return fmt.Sprintf("%s  %s  %s", e.Time.Format("2006-01-02 15:04:05"), e.Instance, message)
+}
+
+// instanceState is what the watcher last saw of an instance.
+type instanceState struct {
+	status session.Status
+	prompt bool
+}
+
+// Watcher reports the changes between observations of the instances.
+type Watcher struct {
+	states map[string]instanceState
+	// started is false until the first observation, whose instances are reported
+	// with their status rather than as added
+	started bool
+}
+
+// NewWatcher creates a watcher that has seen no instances yet.
+func NewWatcher() *Watcher {
+	return &Watcher{states: make(map[string]instanceState)}
+}
+
+// Observe records the current state of the instances and returns the events
+// for what changed since the previous observation. prompts holds the instances
+// that are waiting for input.
+func (w *Watcher) Observe(instances []*session.Instance, prompts map[*session.Instance]bool, now time.Time) []Event {
+	var events []Event
+	seen := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		if instance == nil || instance.Tombstoned() || instance.Archived {
+			continue
+		}
+		seen[instance.Title] = true
+		current := instanceState{status: instance.Status, prompt: prompts[instance]}
+		event := Event{Time: now, Instance: instance.Title, Branch: instance.Branch, Status: current.status.String()}
+
+		previous, known := w.states[instance.Title]
+		w.states[instance.Title] = current
+		switch {
+		case !known && w.started:
+			event.Kind = EventAdded
+		case !known:
+			event.Kind = EventStatus
+		case current.prompt && !previous.prompt:
+			event.Kind = EventPrompt
+		case current.status == previous.status:
+			continue
+		case previous.status == session.Running && current.status == session.Ready:
+			event.Kind = EventFinished
+			event.Previous = previous.status.String()
+		default:
+			event.Kind = EventStatus
+			event.Previous = previous.status.String()
+		}
+		events = append(events, event)
+	}
+
+	var removed []string
+	for title := range w.states {
+		if !seen[title] {
+			removed = append(removed, title)
+		}
+	}
+	sort.Strings(removed)
+	for _, title := range removed {
+		events = append(events, Event{Time: now, Kind: EventRemoved, Instance: title, Status: w.states[title].status.String()})
+		delete(w.states, title)
+	}
+	w.started = true
+	return events
+}
+
+// Run polls the stored instances without the TUI and writes a line to out for
+// every event, as JSON if jsonOutput is set, until interrupted.
+func Run(cfg *config.Config, out io.Writer, jsonOutput bool) error {
+	state := config.LoadState()
+	storage, err := session.NewStorage(state)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	instances, err := storage.LoadInstances()
+	if err != nil {
+		return fmt.Errorf("failed to load instances: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	write := func(event Event) error {
+		if !jsonOutput {
+			_, err := fmt.Fprintln(out, event.String())
+			return err
+		}
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(line))
+		return err
+	}
+
+	watcher := NewWatcher()
+	pollInterval := time.Duration(cfg.DaemonPollInterval) * time.Millisecond
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		// Pick up instances created, paused or removed by the TUI
+		if diskInstances, synced, err := storage.SyncFromDisk(); err != nil {
+			log.WarningLog.Printf("failed to sync instances from disk: %v", err)
+		} else if synced {
+			instances = merge(instances, diskInstances)
+		}
+
+		// Watching only observes; stopped programs are left to the TUI and daemon
+		prompts := make(map[*session.Instance]bool)
+		for _, result := range session.ParallelPoll(instances) {
+			if result.Instance == nil {
+				continue
+			}
+			// The same rules as the TUI, except that prompts are left for the user
+			if result.Updated {
+				result.Instance.SetStatus(session.Running)
+			} else if result.HasPrompt {
+				prompts[result.Instance] = true
+			} else {
+				result.Instance.SetStatus(session.Ready)
+			}
+		}
+
+		for _, event := range watcher.Observe(instances, prompts, time.Now()) {
+			if err := write(event); err != nil {
+				return fmt.Errorf("failed to write event: %w", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// merge returns the instances stored on disk, keeping the watched instance of
+// each title unless it was paused or resumed since.
+func merge(watched, disk []*session.Instance) []*session.Instance {
+	byTitle := make(map[string]*session.Instance, len(watched))
+	for _, instance := range watched {
+		byTitle[instance.Title] = instance
+	}
+	merged := make([]*session.Instance, 0, len(disk))
+	for _, instance := range disk {
+		if existing, ok := byTitle[instance.Title]; ok && existing.Paused() == instance.Paused() {
+			instance = existing
+		}
+		merged = append(merged, instance)
+	}
+	return merged
+}
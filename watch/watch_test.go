@@ -0,0 +1,76 @@
+package watch
+
+import (
+	"claude-squad/session"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatcherObserve(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	a := &session.Instance{Title: "a", Status: session.Running}
+	b := &session.Instance{Title: "b", Status: session.Ready}
+	w := NewWatcher()
+
+	kinds := func(events []Event) []string {
+		var result []string
+		for _, event := range events {
+			result = append(result, event.Instance+":"+event.Kind)
+		}
+		return result
+	}
+	check := func(step string, events []Event, expected ...string) {
+		t.Helper()
+		got := kinds(events)
+		if strings.Join(got, ",") != strings.Join(expected, ",") {
+			t.Errorf("%s: expected events %v, got %v", step, expected, got)
+		}
+	}
+
+	check("first observation", w.Observe([]*session.Instance{a, b}, nil, now), "a:status", "b:status")
+	check("no change", w.Observe([]*session.Instance{a, b}, nil, now))
+
+	a.Status = session.Ready
+	events := w.Observe([]*session.Instance{a, b}, nil, now)
+	check("running to ready", events, "a:finished")
+	if len(events) == 1 && events[0].Previous != "running" {
+		t.Errorf("expected the previous status to be running, got %q", events[0].Previous)
+	}
+
+	check("prompt", w.Observe([]*session.Instance{a, b}, map[*session.Instance]bool{b: true}, now), "b:prompt")
+	check("prompt still waiting", w.Observe([]*session.Instance{a, b}, map[*session.Instance]bool{b: true}, now))
+
+	b.Status = session.Paused
+	check("paused", w.Observe([]*session.Instance{a, b}, nil, now), "b:status")
+
+	c := &session.Instance{Title: "c", Status: session.Loading}
+	check("added and removed", w.Observe([]*session.Instance{a, c}, nil, now), "c:added", "b:removed")
+}
+
+func TestEventFormat(t *testing.T) {
+	event := Event{
+		Time:     time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC),
+		Kind:     EventStatus,
+		Instance: "fix-login",
+		Status:   "paused",
+		Previous: "ready",
+	}
+	if got, want := event.String(), "2026-10-15 09:30:00  fix-login  ready -> paused"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+	for _, field := range []string{`"event":"status"`, `"instance":"fix-login"`, `"status":"paused"`, `"previous":"ready"`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("expected %s in %s", field, data)
+		}
+	}
+	if strings.Contains(string(data), "branch") {
+		t.Errorf("expected no branch in %s", data)
+	}
+}